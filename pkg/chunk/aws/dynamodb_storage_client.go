@@ -604,7 +604,8 @@ func (a dynamoDBStorageClient) getDynamoDBChunks(ctx context.Context, chunks []c
 
 func processChunkResponse(response *dynamodb.BatchGetItemOutput, chunksByKey map[string]chunk.Chunk) ([]chunk.Chunk, error) {
 	result := []chunk.Chunk{}
-	decodeContext := chunk.NewDecodeContext()
+	decodeContext := chunk.GetDecodeContext()
+	defer chunk.PutDecodeContext(decodeContext)
 	for _, items := range response.Responses {
 		for _, item := range items {
 			key, ok := item[hashKey]
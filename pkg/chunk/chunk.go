@@ -5,6 +5,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"hash/crc32"
+	"io"
 	"strconv"
 	"strings"
 	"sync"
@@ -246,15 +247,35 @@ func (c *Chunk) Encoded() ([]byte, error) {
 // DecodeContext holds data that can be re-used between decodes of different chunks
 type DecodeContext struct {
 	reader *snappy.Reader
+	iter   *jsoniter.Iterator
 }
 
 // NewDecodeContext creates a new, blank, DecodeContext
 func NewDecodeContext() *DecodeContext {
 	return &DecodeContext{
 		reader: snappy.NewReader(nil),
+		iter:   jsoniter.Parse(jsoniter.ConfigFastest, nil, 512),
 	}
 }
 
+var decodeContextPool = sync.Pool{
+	New: func() interface{} { return NewDecodeContext() },
+}
+
+// GetDecodeContext returns a DecodeContext from a pool, to save allocating
+// the decompression buffers of short-lived decoders.  Return it with
+// PutDecodeContext once done.
+func GetDecodeContext() *DecodeContext {
+	return decodeContextPool.Get().(*DecodeContext)
+}
+
+// PutDecodeContext returns a DecodeContext to the pool.
+func PutDecodeContext(decodeContext *DecodeContext) {
+	decodeContext.reader.Reset(nil)
+	decodeContext.iter.Reset(nil)
+	decodeContextPool.Put(decodeContext)
+}
+
 // Decode the chunk from the given buffer, and confirm the chunk is the one we
 // expected.
 func (c *Chunk) Decode(decodeContext *DecodeContext, input []byte) error {
@@ -283,9 +304,10 @@ func (c *Chunk) Decode(decodeContext *DecodeContext, input []byte) error {
 	}
 	var tempMetadata Chunk
 	decodeContext.reader.Reset(r)
-	json := jsoniter.ConfigFastest
-	err := json.NewDecoder(decodeContext.reader).Decode(&tempMetadata)
-	if err != nil {
+	iter := decodeContext.iter.Reset(decodeContext.reader)
+	iter.Error = nil
+	iter.ReadVal(&tempMetadata)
+	if err := iter.Error; err != nil && err != io.EOF {
 		return errors.Wrap(err, "when decoding chunk metadata")
 	}
 	if len(input)-r.Len() != int(metadataLen) {
@@ -310,6 +332,7 @@ func (c *Chunk) Decode(decodeContext *DecodeContext, input []byte) error {
 	}
 
 	// Finally, unmarshal the actual chunk data.
+	var err error
 	c.Data, err = prom_chunk.NewForEncoding(c.Encoding)
 	if err != nil {
 		return errors.Wrap(err, "when creating new chunk")
@@ -336,7 +359,13 @@ func equalByKey(a, b Chunk) bool {
 
 // Samples returns all SamplePairs for the chunk.
 func (c *Chunk) Samples(from, through model.Time) ([]model.SamplePair, error) {
+	return c.AppendSamples([]model.SamplePair{}, from, through)
+}
+
+// AppendSamples is like Samples, appending the samples to buf, which lets
+// callers reuse it.
+func (c *Chunk) AppendSamples(buf []model.SamplePair, from, through model.Time) ([]model.SamplePair, error) {
 	it := c.Data.NewIterator()
 	interval := metric.Interval{OldestInclusive: from, NewestInclusive: through}
-	return prom_chunk.RangeValues(it, interval)
+	return prom_chunk.AppendRangeValues(buf, it, interval)
 }
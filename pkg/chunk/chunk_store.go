@@ -18,6 +18,7 @@ import (
 	"github.com/prometheus/prometheus/promql"
 
 	"github.com/cortexproject/cortex/pkg/chunk/cache"
+	"github.com/cortexproject/cortex/pkg/querier/sharding"
 	"github.com/cortexproject/cortex/pkg/util"
 	"github.com/cortexproject/cortex/pkg/util/extract"
 	"github.com/cortexproject/cortex/pkg/util/flagext"
@@ -50,6 +51,10 @@ var (
 	})
 )
 
+// ErrNotImplemented is returned by the stores of schemas which can't look up
+// chunk refs in their index.
+var ErrNotImplemented = errors.New("not implemented")
+
 func init() {
 	prometheus.MustRegister(rowWrites)
 }
@@ -176,6 +181,11 @@ func (c *store) Get(ctx context.Context, from, through model.Time, allMatchers .
 	defer log.Span.Finish()
 	level.Debug(log).Log("from", from, "through", through, "matchers", len(allMatchers))
 
+	shard, allMatchers, err := shardFromMatchers(allMatchers)
+	if err != nil {
+		return nil, err
+	}
+
 	// Validate the query is within reasonable bounds.
 	metricName, matchers, shortcut, err := c.validateQuery(ctx, &from, &through, allMatchers)
 	if err != nil {
@@ -185,11 +195,11 @@ func (c *store) Get(ctx context.Context, from, through model.Time, allMatchers .
 	}
 
 	log.Span.SetTag("metric", metricName)
-	return c.getMetricNameChunks(ctx, from, through, matchers, metricName)
+	return c.getMetricNameChunks(ctx, from, through, matchers, metricName, shard)
 }
 
 func (c *store) GetChunkRefs(ctx context.Context, from, through model.Time, allMatchers ...*labels.Matcher) ([][]Chunk, []*Fetcher, error) {
-	return nil, nil, errors.New("not implemented")
+	return nil, nil, ErrNotImplemented
 }
 
 // LabelValuesForMetricName retrieves all label values for a single label name and metric name.
@@ -234,6 +244,107 @@ func (c *store) LabelValuesForMetricName(ctx context.Context, from, through mode
 	return result, nil
 }
 
+// LabelNamesForMetricName retrieves the label names of a metric's series.
+// The index doesn't have them, so they are read from one chunk of each series.
+func (c *store) LabelNamesForMetricName(ctx context.Context, from, through model.Time, metricName string) ([]string, error) {
+	log, ctx := spanlogger.New(ctx, "ChunkStore.LabelNames")
+	defer log.Span.Finish()
+	level.Debug(log).Log("from", from, "through", through, "metricName", metricName)
+
+	shortcut, err := c.validateQueryTimeRange(ctx, &from, &through)
+	if err != nil {
+		return nil, err
+	} else if shortcut {
+		return nil, nil
+	}
+
+	chunks, err := c.lookupChunksByMetricName(ctx, from, through, nil, metricName)
+	if err != nil {
+		return nil, err
+	}
+	chunks = oneChunkPerSeries(filterChunksByTime(from, through, chunks))
+
+	allChunks, err := c.FetchChunks(ctx, chunks, keysFromChunks(chunks))
+	if err != nil {
+		level.Error(log).Log("msg", "FetchChunks", "err", err)
+		return nil, promql.ErrStorage{Err: err}
+	}
+	return labelNamesFromChunks(allChunks), nil
+}
+
+// MetricNames retrieves the names of the metrics with series in the time
+// range.  Only the v11 schema indexes them, the names of older schemas'
+// metrics aren't returned.
+func (c *store) MetricNames(ctx context.Context, from, through model.Time) ([]string, error) {
+	log, ctx := spanlogger.New(ctx, "ChunkStore.MetricNames")
+	defer log.Span.Finish()
+	level.Debug(log).Log("from", from, "through", through)
+
+	userID, err := user.ExtractOrgID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	shortcut, err := c.validateQueryTimeRange(ctx, &from, &through)
+	if err != nil {
+		return nil, err
+	} else if shortcut {
+		return nil, nil
+	}
+
+	queries, err := c.schema.GetReadQueriesForMetricNames(from, through, userID)
+	if err == ErrNotSupported {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	entries, err := c.lookupEntriesByQueries(ctx, queries)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		metricName, err := parseMetricNameRangeValue(entry.RangeValue, entry.Value)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, string(metricName))
+	}
+
+	sort.Strings(result)
+	return uniqueStrings(result), nil
+}
+
+// oneChunkPerSeries returns the first of the chunks of each series, as all
+// of a series' chunks have the same labels.
+func oneChunkPerSeries(chunks []Chunk) []Chunk {
+	seen := map[model.Fingerprint]struct{}{}
+	result := make([]Chunk, 0, len(chunks))
+	for _, chunk := range chunks {
+		if _, ok := seen[chunk.Fingerprint]; ok {
+			continue
+		}
+		seen[chunk.Fingerprint] = struct{}{}
+		result = append(result, chunk)
+	}
+	return result
+}
+
+// labelNamesFromChunks returns the sorted, distinct label names of the
+// chunks' series.
+func labelNamesFromChunks(chunks []Chunk) []string {
+	var result []string
+	for _, c := range chunks {
+		for _, l := range c.Metric {
+			result = append(result, l.Name)
+		}
+	}
+	sort.Strings(result)
+	return uniqueStrings(result)
+}
+
 func (c *store) validateQueryTimeRange(ctx context.Context, from *model.Time, through *model.Time) (bool, error) {
 	log, ctx := spanlogger.New(ctx, "store.validateQueryTimeRange")
 	defer log.Span.Finish()
@@ -302,7 +413,7 @@ func (c *store) validateQuery(ctx context.Context, from *model.Time, through *mo
 	return metricNameMatcher.Value, matchers, false, nil
 }
 
-func (c *store) getMetricNameChunks(ctx context.Context, from, through model.Time, allMatchers []*labels.Matcher, metricName string) ([]Chunk, error) {
+func (c *store) getMetricNameChunks(ctx context.Context, from, through model.Time, allMatchers []*labels.Matcher, metricName string, shard *sharding.Shard) ([]Chunk, error) {
 	log, ctx := spanlogger.New(ctx, "ChunkStore.getMetricNameChunks")
 	defer log.Finish()
 	level.Debug(log).Log("from", from, "through", through, "metricName", metricName, "matchers", len(allMatchers))
@@ -319,8 +430,8 @@ func (c *store) getMetricNameChunks(ctx context.Context, from, through model.Tim
 	}
 	level.Debug(log).Log("Chunks in index", len(chunks))
 
-	// Filter out chunks that are not in the selected time range.
-	filtered := filterChunksByTime(from, through, chunks)
+	// Filter out chunks that are not in the selected time range or shard.
+	filtered := filterChunksByShard(shard, filterChunksByTime(from, through, chunks))
 	level.Debug(log).Log("Chunks post filtering", len(chunks))
 
 	maxChunksPerQuery := c.limits.MaxChunksPerQuery(userID)
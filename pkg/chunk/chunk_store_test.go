@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math/rand"
 	"reflect"
+	"strconv"
 	"testing"
 	"time"
 
@@ -16,6 +17,8 @@ import (
 
 	"github.com/cortexproject/cortex/pkg/chunk/cache"
 	"github.com/cortexproject/cortex/pkg/chunk/encoding"
+	"github.com/cortexproject/cortex/pkg/ingester/client"
+	"github.com/cortexproject/cortex/pkg/querier/sharding"
 	"github.com/cortexproject/cortex/pkg/util/extract"
 	"github.com/cortexproject/cortex/pkg/util/flagext"
 	"github.com/cortexproject/cortex/pkg/util/validation"
@@ -37,6 +40,7 @@ var schemas = []struct {
 	{"v6", true},
 	{"v9", true},
 	{"v10", true},
+	{"v11", true},
 }
 
 var stores = []struct {
@@ -370,6 +374,109 @@ func TestChunkStore_LabelValuesForMetricName(t *testing.T) {
 
 }
 
+func TestChunkStore_LabelNamesForMetricName(t *testing.T) {
+	ctx := user.InjectOrgID(context.Background(), userID)
+	now := model.Now()
+
+	fooMetric1 := labels.Labels{
+		{Name: labels.MetricName, Value: "foo"},
+		{Name: "bar", Value: "baz"},
+		{Name: "flip", Value: "flop"},
+	}
+	fooMetric2 := labels.Labels{
+		{Name: labels.MetricName, Value: "foo"},
+		{Name: "bar", Value: "beep"},
+		{Name: "toms", Value: "code"},
+	}
+	barMetric1 := labels.Labels{
+		{Name: labels.MetricName, Value: "bar"},
+		{Name: "bar", Value: "baz"},
+	}
+
+	for _, tc := range []struct {
+		metricName string
+		expect     []string
+	}{
+		{
+			`foo`,
+			[]string{labels.MetricName, "bar", "flip", "toms"},
+		},
+		{
+			`bar`,
+			[]string{labels.MetricName, "bar"},
+		},
+	} {
+		for _, schema := range schemas {
+			for _, storeCase := range stores {
+				t.Run(fmt.Sprintf("%s / %s / %s", tc.metricName, schema.name, storeCase.name), func(t *testing.T) {
+					storeCfg := storeCase.configFn()
+					store := newTestChunkStoreConfig(t, schema.name, storeCfg)
+					defer store.Stop()
+
+					if err := store.Put(ctx, []Chunk{
+						dummyChunkFor(now, fooMetric1),
+						dummyChunkFor(now, fooMetric2),
+						dummyChunkFor(now, barMetric1),
+					}); err != nil {
+						t.Fatal(err)
+					}
+
+					labelNames, err := store.LabelNamesForMetricName(ctx, now.Add(-time.Hour), now, tc.metricName)
+					require.NoError(t, err)
+					require.Equal(t, tc.expect, labelNames)
+
+					// Query with both begin & end of time-range in future should yield empty resultset
+					labelNames, err = store.LabelNamesForMetricName(ctx, now.Add(time.Hour), now.Add(time.Hour*2), tc.metricName)
+					require.NoError(t, err)
+					require.Empty(t, labelNames)
+				})
+			}
+		}
+	}
+}
+
+func TestChunkStore_MetricNames(t *testing.T) {
+	ctx := user.InjectOrgID(context.Background(), userID)
+	now := model.Now()
+
+	fooMetric := labels.Labels{
+		{Name: labels.MetricName, Value: "foo"},
+		{Name: "bar", Value: "baz"},
+	}
+	barMetric := labels.Labels{
+		{Name: labels.MetricName, Value: "bar"},
+		{Name: "bar", Value: "baz"},
+	}
+
+	for _, schema := range schemas {
+		for _, storeCase := range stores {
+			t.Run(fmt.Sprintf("%s / %s", schema.name, storeCase.name), func(t *testing.T) {
+				store := newTestChunkStoreConfig(t, schema.name, storeCase.configFn())
+				defer store.Stop()
+
+				require.NoError(t, store.Put(ctx, []Chunk{
+					dummyChunkFor(now, fooMetric),
+					dummyChunkFor(now.Add(-time.Minute), fooMetric),
+					dummyChunkFor(now, barMetric),
+				}))
+
+				// Only the v11 schema indexes metric names.
+				metricNames, err := store.MetricNames(ctx, now.Add(-time.Hour), now)
+				require.NoError(t, err)
+				if schema.name == "v11" {
+					require.Equal(t, []string{"bar", "foo"}, metricNames)
+				} else {
+					require.Empty(t, metricNames)
+				}
+
+				metricNames, err = store.MetricNames(ctx, now.Add(time.Hour), now.Add(2*time.Hour))
+				require.NoError(t, err)
+				require.Empty(t, metricNames)
+			})
+		}
+	}
+}
+
 // TestChunkStore_getMetricNameChunks tests if chunks are fetched correctly when we have the metric name
 func TestChunkStore_getMetricNameChunks(t *testing.T) {
 	ctx := user.InjectOrgID(context.Background(), userID)
@@ -742,3 +849,91 @@ func TestStoreMaxLookBack(t *testing.T) {
 	require.Equal(t, 1, len(chunks))
 	chunks[0].Through.Equal(now)
 }
+
+// TestChunkStore_GetMixedEncodings tests chunks are read with the encoding
+// they were written with, so -ingester.chunk-encoding can be changed.
+func TestChunkStore_GetMixedEncodings(t *testing.T) {
+	ctx := user.InjectOrgID(context.Background(), userID)
+	now := model.Now()
+
+	// Delta chunks aren't stored: they are read as double-delta ones, which
+	// chunks written before the encoding was recorded are.
+	var chunks []Chunk
+	for _, enc := range []encoding.Encoding{encoding.DoubleDelta, encoding.Varbit, encoding.Bigchunk} {
+		metric := labels.Labels{
+			{Name: labels.MetricName, Value: "foo"},
+			{Name: "encoding", Value: enc.String()},
+		}
+		chunks = append(chunks, dummyChunkForEncoding(now, metric, enc, 10))
+	}
+
+	for _, schema := range schemas {
+		t.Run(schema.name, func(t *testing.T) {
+			store := newTestChunkStore(t, schema.name)
+			defer store.Stop()
+			require.NoError(t, store.Put(ctx, chunks))
+
+			matchers, err := promql.ParseMetricSelector(`foo`)
+			require.NoError(t, err)
+			got, err := store.Get(ctx, now.Add(-time.Hour), now.Add(time.Hour), matchers...)
+			require.NoError(t, err)
+			require.Len(t, got, len(chunks))
+			for _, c := range got {
+				require.Equal(t, c.Metric.Get("encoding"), c.Data.Encoding().String())
+				samples, err := c.Samples(c.From, now.Add(time.Hour))
+				require.NoError(t, err)
+				require.Len(t, samples, 10)
+			}
+		})
+	}
+}
+
+func TestChunkStore_GetShard(t *testing.T) {
+	ctx := user.InjectOrgID(context.Background(), userID)
+	now := model.Now()
+
+	// The chunks are fingerprinted the way the ingesters do it.
+	var chunks []Chunk
+	for i := 0; i < 10; i++ {
+		metric := labels.Labels{
+			{Name: labels.MetricName, Value: "foo"},
+			{Name: "bar", Value: strconv.Itoa(i)},
+		}
+		c, _ := encoding.NewForEncoding(encoding.Varbit)
+		cs, err := c.Add(model.SamplePair{Timestamp: now, Value: 0})
+		require.NoError(t, err)
+		fp := client.FastFingerprint(client.FromLabelsToLabelAdapaters(metric))
+		chunk := NewChunk(userID, fp, metric, cs[0], now.Add(-time.Hour), now)
+		require.NoError(t, chunk.Encode())
+		chunks = append(chunks, chunk)
+	}
+
+	for _, schema := range schemas {
+		for _, storeCase := range stores {
+			t.Run(fmt.Sprintf("%s / %s", schema.name, storeCase.name), func(t *testing.T) {
+				store := newTestChunkStoreConfig(t, schema.name, storeCase.configFn())
+				defer store.Stop()
+				require.NoError(t, store.Put(ctx, chunks))
+
+				// Each chunk is returned for exactly one of the shards.
+				seen := map[string]int{}
+				for i := 0; i < 3; i++ {
+					shard := sharding.Shard{Index: i, Of: 3}
+					matchers, err := promql.ParseMetricSelector(`foo`)
+					require.NoError(t, err)
+					matchers = append(matchers, shard.Matcher())
+					got, err := store.Get(ctx, now.Add(-time.Hour), now, matchers...)
+					require.NoError(t, err)
+					for _, c := range got {
+						require.True(t, shard.Contains(c.Metric), c.Metric.String())
+						seen[c.Metric.String()]++
+					}
+				}
+				require.Len(t, seen, len(chunks))
+				for metric, n := range seen {
+					require.Equal(t, 1, n, metric)
+				}
+			})
+		}
+	}
+}
@@ -2,14 +2,17 @@ package chunk
 
 import (
 	"context"
+	"net/http"
 	"sync"
 
 	"github.com/go-kit/kit/log/level"
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/pkg/labels"
 	"github.com/prometheus/prometheus/promql"
+	"github.com/weaveworks/common/httpgrpc"
 
 	"github.com/cortexproject/cortex/pkg/chunk/cache"
+	"github.com/cortexproject/cortex/pkg/querier/sharding"
 	"github.com/cortexproject/cortex/pkg/util"
 	"github.com/cortexproject/cortex/pkg/util/spanlogger"
 )
@@ -27,6 +30,31 @@ func filterChunksByTime(from, through model.Time, chunks []Chunk) []Chunk {
 	return filtered
 }
 
+// shardFromMatchers returns the shard selected by the matchers, if any, and
+// the other matchers.
+func shardFromMatchers(matchers []*labels.Matcher) (*sharding.Shard, []*labels.Matcher, error) {
+	shard, matchers, err := sharding.FromMatchers(matchers)
+	if err != nil {
+		return nil, nil, httpgrpc.Errorf(http.StatusBadRequest, err.Error())
+	}
+	return shard, matchers, nil
+}
+
+// filterChunksByShard returns the chunks which may be of series in the shard,
+// or all of them if there is no shard, so only those are fetched.
+func filterChunksByShard(shard *sharding.Shard, chunks []Chunk) []Chunk {
+	if shard == nil {
+		return chunks
+	}
+	filtered := make([]Chunk, 0, len(chunks))
+	for _, chunk := range chunks {
+		if shard.MayContain(chunk.Fingerprint) {
+			filtered = append(filtered, chunk)
+		}
+	}
+	return filtered
+}
+
 func keysFromChunks(chunks []Chunk) []string {
 	keys := make([]string, 0, len(chunks))
 	for _, chk := range chunks {
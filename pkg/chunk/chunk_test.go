@@ -270,7 +270,7 @@ func benchmarkDecode(b *testing.B, batchSize int) {
 	b.ResetTimer()
 
 	for i := 0; i < b.N; i++ {
-		decodeContext := NewDecodeContext()
+		decodeContext := GetDecodeContext()
 		b.StopTimer()
 		chunks := make([]Chunk, batchSize)
 		// Copy across the metadata so the check works out ok
@@ -284,5 +284,6 @@ func benchmarkDecode(b *testing.B, batchSize int) {
 			err := chunks[j].Decode(decodeContext, buf)
 			require.NoError(b, err)
 		}
+		PutDecodeContext(decodeContext)
 	}
 }
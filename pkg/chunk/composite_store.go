@@ -19,6 +19,8 @@ type Store interface {
 	// using the corresponding Fetcher (fetchers[i].FetchChunks(ctx, chunks[i], ...)
 	GetChunkRefs(ctx context.Context, from, through model.Time, matchers ...*labels.Matcher) ([][]Chunk, []*Fetcher, error)
 	LabelValuesForMetricName(ctx context.Context, from, through model.Time, metricName string, labelName string) ([]string, error)
+	LabelNamesForMetricName(ctx context.Context, from, through model.Time, metricName string) ([]string, error)
+	MetricNames(ctx context.Context, from, through model.Time) ([]string, error)
 	Stop()
 }
 
@@ -49,7 +51,7 @@ func (c *CompositeStore) AddPeriod(storeCfg StoreConfig, cfg PeriodConfig, index
 	var store Store
 	var err error
 	switch cfg.Schema {
-	case "v9", "v10":
+	case "v9", "v10", "v11":
 		store, err = newSeriesStore(storeCfg, schema, index, chunks, limits)
 	default:
 		store, err = newStore(storeCfg, schema, index, chunks, limits)
@@ -106,6 +108,37 @@ func (c compositeStore) LabelValuesForMetricName(ctx context.Context, from, thro
 	return result, err
 }
 
+// LabelNamesForMetricName retrieves the label names of a metric's series.
+func (c compositeStore) LabelNamesForMetricName(ctx context.Context, from, through model.Time, metricName string) ([]string, error) {
+	var result []string
+	err := c.forStores(from, through, func(from, through model.Time, store Store) error {
+		labelNames, err := store.LabelNamesForMetricName(ctx, from, through, metricName)
+		if err != nil {
+			return err
+		}
+		result = append(result, labelNames...)
+		return nil
+	})
+	sort.Strings(result)
+	return uniqueStrings(result), err
+}
+
+// MetricNames retrieves the names of the metrics with series in the time
+// range, in the periods of the v11 schema.
+func (c compositeStore) MetricNames(ctx context.Context, from, through model.Time) ([]string, error) {
+	var result []string
+	err := c.forStores(from, through, func(from, through model.Time, store Store) error {
+		metricNames, err := store.MetricNames(ctx, from, through)
+		if err != nil {
+			return err
+		}
+		result = append(result, metricNames...)
+		return nil
+	})
+	sort.Strings(result)
+	return uniqueStrings(result), err
+}
+
 func (c compositeStore) GetChunkRefs(ctx context.Context, from, through model.Time, matchers ...*labels.Matcher) ([][]Chunk, []*Fetcher, error) {
 	chunkIDs := [][]Chunk{}
 	fetchers := []*Fetcher{}
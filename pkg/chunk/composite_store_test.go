@@ -28,6 +28,14 @@ func (m mockStore) LabelValuesForMetricName(ctx context.Context, from, through m
 	return nil, nil
 }
 
+func (m mockStore) LabelNamesForMetricName(ctx context.Context, from, through model.Time, metricName string) ([]string, error) {
+	return nil, nil
+}
+
+func (m mockStore) MetricNames(ctx context.Context, from, through model.Time) ([]string, error) {
+	return nil, nil
+}
+
 func (m mockStore) GetChunkRefs(tx context.Context, from, through model.Time, matchers ...*labels.Matcher) ([][]Chunk, []*Fetcher, error) {
 	return nil, nil, nil
 }
@@ -104,7 +104,11 @@ type Batch struct {
 // RangeValues is a utility function that retrieves all values within the given
 // range from an Iterator.
 func RangeValues(it Iterator, in metric.Interval) ([]model.SamplePair, error) {
-	result := []model.SamplePair{}
+	return AppendRangeValues([]model.SamplePair{}, it, in)
+}
+
+// AppendRangeValues is like RangeValues, appending the values to result.
+func AppendRangeValues(result []model.SamplePair, it Iterator, in metric.Interval) ([]model.SamplePair, error) {
 	if !it.FindAtOrAfter(in.OldestInclusive) {
 		return result, it.Err()
 	}
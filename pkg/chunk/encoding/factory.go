@@ -4,6 +4,7 @@ import (
 	"flag"
 	"fmt"
 	"strconv"
+	"strings"
 )
 
 // Encoding defines which encoding we are using, delta, doubledelta, or varbit
@@ -21,7 +22,7 @@ var (
 
 // RegisterFlags registers configuration settings.
 func (Config) RegisterFlags(f *flag.FlagSet) {
-	f.Var(&DefaultEncoding, "ingester.chunk-encoding", "Encoding version to use for chunks.")
+	f.Var(&DefaultEncoding, "ingester.chunk-encoding", "Encoding version to use for chunks: 0 or delta, 1 or doubledelta, 2 or varbit, 3 or bigchunk.")
 	flag.BoolVar(&alwaysMarshalFullsizeChunks, "store.fullsize-chunks", alwaysMarshalFullsizeChunks, "When saving varbit chunks, pad to 1024 bytes")
 	flag.IntVar(&bigchunkSizeCapBytes, "store.bigchunk-size-cap-bytes", bigchunkSizeCapBytes, "When using bigchunk encoding, start a new bigchunk if over this size (0 = unlimited)")
 }
@@ -74,11 +75,19 @@ var encodings = map[Encoding]encoding{
 	},
 }
 
-// Set implements flag.Value.
+// Set implements flag.Value.  It takes the encoding's number or,
+// case-insensitively, its name.
 func (e *Encoding) Set(s string) error {
+	for enc, v := range encodings {
+		if strings.EqualFold(s, v.Name) {
+			*e = enc
+			return nil
+		}
+	}
+
 	i, err := strconv.Atoi(s)
 	if err != nil {
-		return err
+		return fmt.Errorf("invalid chunk encoding: %s", s)
 	}
 
 	_, ok := encodings[Encoding(i)]
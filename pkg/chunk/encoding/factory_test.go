@@ -0,0 +1,28 @@
+package encoding
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodingSet(t *testing.T) {
+	for _, tc := range []struct {
+		value    string
+		expected Encoding
+	}{
+		{"0", Delta},
+		{"3", Bigchunk},
+		{"doubledelta", DoubleDelta},
+		{"Varbit", Varbit},
+		{"bigchunk", Bigchunk},
+	} {
+		var e Encoding
+		require.NoError(t, e.Set(tc.value), tc.value)
+		require.Equal(t, tc.expected, e, tc.value)
+	}
+
+	var e Encoding
+	require.Error(t, e.Set("42"))
+	require.Error(t, e.Set("gorilla"))
+}
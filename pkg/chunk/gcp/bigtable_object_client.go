@@ -111,7 +111,8 @@ func (s *bigtableObjectClient) GetChunks(ctx context.Context, input []chunk.Chun
 		for i := 0; i < len(keys); i += maxRowReads {
 			page := keys[i:util.Min(i+maxRowReads, len(keys))]
 			go func(page bigtable.RowList) {
-				decodeContext := chunk.NewDecodeContext()
+				decodeContext := chunk.GetDecodeContext()
+				defer chunk.PutDecodeContext(decodeContext)
 
 				var processingErr error
 				var receivedChunks = 0
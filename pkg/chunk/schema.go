@@ -23,6 +23,9 @@ var (
 	seriesRangeKeyV1      = []byte{'7'}
 	labelSeriesRangeKeyV1 = []byte{'8'}
 
+	// For v11 schema
+	labelNamesSeparator = ","
+
 	// ErrNotSupported when a schema doesn't support that particular lookup.
 	ErrNotSupported = errors.New("not supported")
 )
@@ -45,6 +48,9 @@ type Schema interface {
 
 	// If the query resulted in series IDs, use this method to find chunks.
 	GetChunksForSeries(from, through model.Time, userID string, seriesID []byte) ([]IndexQuery, error)
+
+	// Returns the queries for the names of the metrics with series in the time range.
+	GetReadQueriesForMetricNames(from, through model.Time, userID string) ([]IndexQuery, error)
 }
 
 // IndexQuery describes a query for entries
@@ -194,6 +200,20 @@ func (s schema) GetChunksForSeries(from, through model.Time, userID string, seri
 	return result, nil
 }
 
+func (s schema) GetReadQueriesForMetricNames(from, through model.Time, userID string) ([]IndexQuery, error) {
+	var result []IndexQuery
+
+	buckets := s.buckets(from, through, userID)
+	for _, bucket := range buckets {
+		entries, err := s.entries.GetReadMetricNamesQueries(bucket)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, entries...)
+	}
+	return result, nil
+}
+
 type entries interface {
 	GetWriteEntries(bucket Bucket, metricName string, labels labels.Labels, chunkID string) ([]IndexEntry, error)
 	GetLabelWriteEntries(bucket Bucket, metricName string, labels labels.Labels, chunkID string) ([]IndexEntry, error)
@@ -203,6 +223,7 @@ type entries interface {
 	GetReadMetricLabelQueries(bucket Bucket, metricName string, labelName string) ([]IndexQuery, error)
 	GetReadMetricLabelValueQueries(bucket Bucket, metricName string, labelName string, labelValue string) ([]IndexQuery, error)
 	GetChunksForSeries(bucket Bucket, seriesID []byte) ([]IndexQuery, error)
+	GetReadMetricNamesQueries(bucket Bucket) ([]IndexQuery, error)
 }
 
 // original entries:
@@ -274,6 +295,10 @@ func (originalEntries) GetChunksForSeries(_ Bucket, _ []byte) ([]IndexQuery, err
 	return nil, ErrNotSupported
 }
 
+func (originalEntries) GetReadMetricNamesQueries(_ Bucket) ([]IndexQuery, error) {
+	return nil, ErrNotSupported
+}
+
 // v3Schema went to base64 encoded label values & a version ID
 // - range key: <label name>\0<base64(label value)>\0<chunk name>\0<version 1>
 
@@ -389,6 +414,10 @@ func (labelNameInHashKeyEntries) GetChunksForSeries(_ Bucket, _ []byte) ([]Index
 	return nil, ErrNotSupported
 }
 
+func (labelNameInHashKeyEntries) GetReadMetricNamesQueries(_ Bucket) ([]IndexQuery, error) {
+	return nil, ErrNotSupported
+}
+
 // v5 schema is an extension of v4, with the chunk end time in the
 // range key to improve query latency.  However, it did it wrong
 // so the chunk end times are ignored.
@@ -459,6 +488,10 @@ func (v5Entries) GetChunksForSeries(_ Bucket, _ []byte) ([]IndexQuery, error) {
 	return nil, ErrNotSupported
 }
 
+func (v5Entries) GetReadMetricNamesQueries(_ Bucket) ([]IndexQuery, error) {
+	return nil, ErrNotSupported
+}
+
 // v6Entries fixes issues with v5 time encoding being wrong (see #337), and
 // moves label value out of range key (see #199).
 type v6Entries struct{}
@@ -535,6 +568,10 @@ func (v6Entries) GetChunksForSeries(_ Bucket, _ []byte) ([]IndexQuery, error) {
 	return nil, ErrNotSupported
 }
 
+func (v6Entries) GetReadMetricNamesQueries(_ Bucket) ([]IndexQuery, error) {
+	return nil, ErrNotSupported
+}
+
 // v9Entries adds a layer of indirection between labels -> series -> chunks.
 type v9Entries struct {
 }
@@ -630,6 +667,10 @@ func (v9Entries) GetChunksForSeries(bucket Bucket, seriesID []byte) ([]IndexQuer
 	}, nil
 }
 
+func (v9Entries) GetReadMetricNamesQueries(_ Bucket) ([]IndexQuery, error) {
+	return nil, ErrNotSupported
+}
+
 // v10Entries builds on v9 by sharding index rows to reduce their size.
 type v10Entries struct {
 	rowShards uint32
@@ -734,3 +775,48 @@ func (v10Entries) GetChunksForSeries(bucket Bucket, seriesID []byte) ([]IndexQue
 		},
 	}, nil
 }
+
+func (v10Entries) GetReadMetricNamesQueries(_ Bucket) ([]IndexQuery, error) {
+	return nil, ErrNotSupported
+}
+
+// v11Entries builds on v10 by indexing the names of each tenant's metrics,
+// and adding the label names of each series to its metric name entry, so that
+// they can be listed without fetching chunks.
+// - hash key: <userid>:<bucket>
+// - range key: <sha256(metric name)>\0\0\0<version 6>
+// - value: <metric name>
+type v11Entries struct {
+	v10Entries
+}
+
+func (s v11Entries) GetLabelWriteEntries(bucket Bucket, metricName string, labels labels.Labels, chunkID string) ([]IndexEntry, error) {
+	entries, err := s.v10Entries.GetLabelWriteEntries(bucket, metricName, labels, chunkID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The first entry is the one for metricName -> seriesID.
+	names := make([]string, 0, len(labels))
+	for _, l := range labels {
+		names = append(names, l.Name)
+	}
+	entries[0].Value = []byte(strings.Join(names, labelNamesSeparator))
+
+	// Entry for userID -> metricName
+	return append(entries, IndexEntry{
+		TableName:  bucket.tableName,
+		HashValue:  bucket.hashKey,
+		RangeValue: encodeRangeKey(sha256bytes(metricName), nil, nil, metricNameRangeKeyV1),
+		Value:      []byte(metricName),
+	}), nil
+}
+
+func (v11Entries) GetReadMetricNamesQueries(bucket Bucket) ([]IndexQuery, error) {
+	return []IndexQuery{
+		{
+			TableName: bucket.tableName,
+			HashValue: bucket.hashKey,
+		},
+	}, nil
+}
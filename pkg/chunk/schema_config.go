@@ -214,6 +214,15 @@ func (cfg PeriodConfig) createSchema() Schema {
 		s = schema{cfg.dailyBuckets, v10Entries{
 			rowShards: rowShards,
 		}}
+	case "v11":
+		rowShards := uint32(16)
+		if cfg.RowShards > 0 {
+			rowShards = cfg.RowShards
+		}
+
+		s = schema{cfg.dailyBuckets, v11Entries{v10Entries{
+			rowShards: rowShards,
+		}}}
 	}
 	return s
 }
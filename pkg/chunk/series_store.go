@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"sort"
+	"strings"
 
 	"github.com/go-kit/kit/log/level"
 	"github.com/prometheus/client_golang/prometheus"
@@ -14,6 +16,7 @@ import (
 	"github.com/weaveworks/common/user"
 
 	"github.com/cortexproject/cortex/pkg/chunk/cache"
+	"github.com/cortexproject/cortex/pkg/querier/sharding"
 	"github.com/cortexproject/cortex/pkg/util"
 	"github.com/cortexproject/cortex/pkg/util/spanlogger"
 	"github.com/cortexproject/cortex/pkg/util/validation"
@@ -109,7 +112,11 @@ func (c *seriesStore) Get(ctx context.Context, from, through model.Time, allMatc
 		return nil, err
 	}
 
-	chks, fetchers, err := c.GetChunkRefs(ctx, from, through, allMatchers...)
+	shard, allMatchers, err := shardFromMatchers(allMatchers)
+	if err != nil {
+		return nil, err
+	}
+	chks, fetchers, err := c.getChunkRefs(ctx, from, through, shard, allMatchers)
 	if err != nil {
 		return nil, err
 	}
@@ -143,6 +150,16 @@ func (c *seriesStore) Get(ctx context.Context, from, through model.Time, allMatc
 }
 
 func (c *seriesStore) GetChunkRefs(ctx context.Context, from, through model.Time, allMatchers ...*labels.Matcher) ([][]Chunk, []*Fetcher, error) {
+	shard, allMatchers, err := shardFromMatchers(allMatchers)
+	if err != nil {
+		return nil, nil, err
+	}
+	return c.getChunkRefs(ctx, from, through, shard, allMatchers)
+}
+
+// getChunkRefs returns the chunks of the series matching the matchers, in the
+// shard if there is one.
+func (c *seriesStore) getChunkRefs(ctx context.Context, from, through model.Time, shard *sharding.Shard, allMatchers []*labels.Matcher) ([][]Chunk, []*Fetcher, error) {
 	log, ctx := spanlogger.New(ctx, "SeriesStore.GetChunkRefs")
 	defer log.Span.Finish()
 
@@ -184,13 +201,82 @@ func (c *seriesStore) GetChunkRefs(ctx context.Context, from, through model.Time
 		return nil, nil, err
 	}
 
-	chunks = filterChunksByTime(from, through, chunks)
+	chunks = filterChunksByShard(shard, filterChunksByTime(from, through, chunks))
 	level.Debug(log).Log("chunks-post-filtering", len(chunks))
 	chunksPerQuery.Observe(float64(len(chunks)))
 
 	return [][]Chunk{chunks}, []*Fetcher{c.store.Fetcher}, nil
 }
 
+// LabelNamesForMetricName retrieves the label names of a metric's series.
+// They are read from the series' metric name entries in the v11 schema, and
+// from one chunk of each series in older schemas, whose index doesn't have
+// them.
+func (c *seriesStore) LabelNamesForMetricName(ctx context.Context, from, through model.Time, metricName string) ([]string, error) {
+	log, ctx := spanlogger.New(ctx, "SeriesStore.LabelNamesForMetricName")
+	defer log.Span.Finish()
+	level.Debug(log).Log("from", from, "through", through, "metricName", metricName)
+
+	userID, err := user.ExtractOrgID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	shortcut, err := c.validateQueryTimeRange(ctx, &from, &through)
+	if err != nil {
+		return nil, err
+	} else if shortcut {
+		return nil, nil
+	}
+
+	queries, err := c.schema.GetReadQueriesForMetric(from, through, userID, metricName)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := c.lookupEntriesByQueries(ctx, queries)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		result        []string
+		withoutLabels []IndexEntry
+	)
+	for _, entry := range entries {
+		if len(entry.Value) > 0 {
+			result = append(result, strings.Split(string(entry.Value), labelNamesSeparator)...)
+			continue
+		}
+		withoutLabels = append(withoutLabels, entry)
+	}
+
+	if len(withoutLabels) > 0 {
+		seriesIDs, err := c.parseIndexEntries(ctx, withoutLabels, nil)
+		if err != nil {
+			return nil, err
+		}
+		chunkIDs, err := c.lookupChunksBySeries(ctx, from, through, userID, seriesIDs)
+		if err != nil {
+			return nil, err
+		}
+		chunks, err := c.convertChunkIDsToChunks(ctx, userID, chunkIDs)
+		if err != nil {
+			return nil, err
+		}
+		chunks = oneChunkPerSeries(filterChunksByTime(from, through, chunks))
+
+		allChunks, err := c.store.Fetcher.FetchChunks(ctx, chunks, keysFromChunks(chunks))
+		if err != nil {
+			level.Error(log).Log("msg", "FetchChunks", "err", err)
+			return nil, err
+		}
+		result = append(result, labelNamesFromChunks(allChunks)...)
+	}
+
+	sort.Strings(result)
+	return uniqueStrings(result), nil
+}
+
 func (c *seriesStore) lookupSeriesByMetricNameMatchers(ctx context.Context, from, through model.Time, userID, metricName string, matchers []*labels.Matcher) ([]string, error) {
 	log, ctx := spanlogger.New(ctx, "SeriesStore.lookupSeriesByMetricNameMatchers", "metricName", metricName, "matchers", len(matchers))
 	defer log.Span.Finish()
@@ -19,8 +19,16 @@ import (
 	"github.com/pkg/errors"
 )
 
+// Storage engines, for -store.engine.
+const (
+	StorageEngineChunks = "chunks"
+	StorageEngineBlocks = "blocks"
+)
+
 // Config chooses which storage client to use.
 type Config struct {
+	Engine string `yaml:"engine"`
+
 	AWSStorageConfig       aws.StorageConfig  `yaml:"aws"`
 	GCPStorageConfig       gcp.Config         `yaml:"bigtable"`
 	GCSConfig              gcp.GCSConfig      `yaml:"gcs"`
@@ -35,6 +43,7 @@ type Config struct {
 
 // RegisterFlags adds the flags required to configure this flag set.
 func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
+	f.StringVar(&cfg.Engine, "store.engine", StorageEngineChunks, "The storage engine to use: chunks or blocks. With the experimental blocks engine the ingesters keep each user's samples in a Prometheus TSDB, and ship its blocks to -experimental.tsdb.bucket-url.")
 	cfg.AWSStorageConfig.RegisterFlags(f)
 	cfg.GCPStorageConfig.RegisterFlags(f)
 	cfg.GCSConfig.RegisterFlags(f)
@@ -31,7 +31,8 @@ func GetParallelChunks(ctx context.Context, chunks []chunk.Chunk, f func(context
 
 	for i := 0; i < min(maxParallel, len(chunks)); i++ {
 		go func() {
-			decodeContext := chunk.NewDecodeContext()
+			decodeContext := chunk.GetDecodeContext()
+			defer chunk.PutDecodeContext(decodeContext)
 			for c := range queuedChunks {
 				c, err := f(ctx, decodeContext, c)
 				if err != nil {
@@ -28,6 +28,7 @@ import (
 	"github.com/cortexproject/cortex/pkg/ingester/client"
 	"github.com/cortexproject/cortex/pkg/querier"
 	"github.com/cortexproject/cortex/pkg/querier/frontend"
+	"github.com/cortexproject/cortex/pkg/querier/stats"
 	"github.com/cortexproject/cortex/pkg/ring"
 	"github.com/cortexproject/cortex/pkg/ruler"
 	"github.com/cortexproject/cortex/pkg/util"
@@ -143,7 +144,7 @@ func (t *Cortex) stopServer() (err error) {
 }
 
 func (t *Cortex) initRing(cfg *Config) (err error) {
-	t.ring, err = ring.New(cfg.Ingester.LifecyclerConfig.RingConfig, "ingester")
+	t.ring, err = ring.New(cfg.Ingester.LifecyclerConfig.RingConfig, "ingester", ring.ConsulKey)
 	if err != nil {
 		return
 	}
@@ -163,6 +164,7 @@ func (t *Cortex) stopOverrides() error {
 }
 
 func (t *Cortex) initDistributor(cfg *Config) (err error) {
+	cfg.Distributor.DistributorRing.ListenPort = &cfg.Server.GRPCListenPort
 	t.distributor, err = distributor.New(cfg.Distributor, cfg.IngesterClient, t.overrides, t.ring)
 	if err != nil {
 		return
@@ -170,6 +172,11 @@ func (t *Cortex) initDistributor(cfg *Config) (err error) {
 
 	t.server.HTTP.HandleFunc("/all_user_stats", t.distributor.AllUserStatsHandler)
 	t.server.HTTP.Handle("/api/prom/push", t.httpAuthMiddleware.Wrap(http.HandlerFunc(t.distributor.PushHandler)))
+	t.server.HTTP.Handle("/otlp/v1/metrics", t.httpAuthMiddleware.Wrap(http.HandlerFunc(t.distributor.OTLPHandler)))
+	t.server.HTTP.Handle("/api/v1/push/influx", t.httpAuthMiddleware.Wrap(http.HandlerFunc(t.distributor.InfluxHandler)))
+	t.server.HTTP.Handle("/datadog/api/v1/series", t.httpAuthMiddleware.Wrap(http.HandlerFunc(t.distributor.DatadogSeriesV1Handler)))
+	t.server.HTTP.Handle("/datadog/api/v2/series", t.httpAuthMiddleware.Wrap(http.HandlerFunc(t.distributor.DatadogSeriesV2Handler)))
+	t.server.HTTP.Handle("/datadog/api/v1/validate", t.httpAuthMiddleware.Wrap(http.HandlerFunc(distributor.DatadogValidateHandler)))
 	return
 }
 
@@ -179,12 +186,13 @@ func (t *Cortex) stopDistributor() (err error) {
 }
 
 func (t *Cortex) initQuerier(cfg *Config) (err error) {
+	cfg.Worker.MaxConcurrentRequests = cfg.Querier.MaxConcurrent
 	t.worker, err = frontend.NewWorker(cfg.Worker, httpgrpc_server.NewServer(t.server.HTTPServer.Handler), util.Logger)
 	if err != nil {
 		return
 	}
 
-	queryable, engine := querier.New(cfg.Querier, t.distributor, t.store)
+	queryable, engine := querier.New(cfg.Querier, t.distributor, t.store, t.overrides)
 	api := v1.NewAPI(
 		engine,
 		queryable,
@@ -203,11 +211,32 @@ func (t *Cortex) initQuerier(cfg *Config) (err error) {
 	promRouter := route.New().WithPrefix("/api/prom/api/v1")
 	api.Register(promRouter)
 
+	// Remote read is served at the Prometheus API's path too, ahead of the
+	// Prometheus API's own handler, so Prometheus and Thanos can read from
+	// Cortex as from any other Prometheus.
+	//
+	// All query requests share the querier's concurrency limit, whether they
+	// come from the frontend or not.
+	limit := querier.ConcurrencyLimitMiddleware(cfg.Querier.MaxConcurrent, cfg.Querier.MaxQueuedRequests, cfg.Querier.QueueTimeout)
+	tracker, err := querier.ActiveQueryTrackerMiddleware(cfg.Querier.ActiveQueryTrackerDir, cfg.Querier.EngineMaxConcurrent(), util.Logger)
+	if err != nil {
+		return
+	}
+	queryLog := stats.NewQueryLog(cfg.Querier.QueryStatsHistory)
+	if queryLog != nil {
+		t.server.HTTP.Handle("/query_stats", t.httpAuthMiddleware.Wrap(queryLog))
+	}
+	remoteRead := t.httpAuthMiddleware.Wrap(limit.Wrap(querier.RemoteReadHandler(queryable)))
 	subrouter := t.server.HTTP.PathPrefix("/api/prom").Subrouter()
-	subrouter.PathPrefix("/api/v1").Handler(t.httpAuthMiddleware.Wrap(promRouter))
-	subrouter.Path("/read").Handler(t.httpAuthMiddleware.Wrap(querier.RemoteReadHandler(queryable)))
+	subrouter.Path("/api/v1/read").Handler(remoteRead)
+	subrouter.Path("/api/v1/metadata").Handler(t.httpAuthMiddleware.Wrap(limit.Wrap(querier.MetadataHandler(t.distributor))))
+	subrouter.Path("/api/v1/status/buildinfo").Handler(t.httpAuthMiddleware.Wrap(querier.BuildInfoHandler(querier.NewBuildInfo())))
+	subrouter.Path("/api/v1/status/runtimeinfo").Handler(t.httpAuthMiddleware.Wrap(querier.RuntimeInfoHandler(cfg.TableManager.RetentionPeriod, t.overrides)))
+	subrouter.Path("/api/v1/query_exemplars").Handler(t.httpAuthMiddleware.Wrap(limit.Wrap(querier.ExemplarsHandler(t.distributor))))
+	subrouter.PathPrefix("/api/v1").Handler(t.httpAuthMiddleware.Wrap(limit.Wrap(tracker.Wrap(queryLog.Wrap(querier.LabelNamesHandler(t.distributor, t.store, querier.LabelValuesHandler(queryable, querier.ProtobufHandler(engine, queryable, promRouter))))))))
+	subrouter.Path("/read").Handler(remoteRead)
 	subrouter.Path("/validate_expr").Handler(t.httpAuthMiddleware.Wrap(http.HandlerFunc(t.distributor.ValidateExprHandler)))
-	subrouter.Path("/chunks").Handler(t.httpAuthMiddleware.Wrap(querier.ChunksHandler(queryable)))
+	subrouter.Path("/chunks").Handler(t.httpAuthMiddleware.Wrap(limit.Wrap(querier.ChunksHandler(queryable))))
 	subrouter.Path("/user_stats").Handler(middleware.AuthenticateUser.Wrap(http.HandlerFunc(t.distributor.UserStatsHandler)))
 	return
 }
@@ -219,6 +248,14 @@ func (t *Cortex) stopQuerier() error {
 
 func (t *Cortex) initIngester(cfg *Config) (err error) {
 	cfg.Ingester.LifecyclerConfig.ListenPort = &cfg.Server.GRPCListenPort
+	cfg.Ingester.QueryStoreAfter = cfg.Querier.QueryStoreAfter
+	switch cfg.Storage.Engine {
+	case storage.StorageEngineChunks:
+	case storage.StorageEngineBlocks:
+		cfg.Ingester.TSDBEnabled = true
+	default:
+		return fmt.Errorf("unknown storage engine %q", cfg.Storage.Engine)
+	}
 	t.ingester, err = ingester.New(cfg.Ingester, cfg.IngesterClient, t.overrides, t.store)
 	if err != nil {
 		return
@@ -228,6 +265,10 @@ func (t *Cortex) initIngester(cfg *Config) (err error) {
 	grpc_health_v1.RegisterHealthServer(t.server.GRPC, t.ingester)
 	t.server.HTTP.Path("/ready").Handler(http.HandlerFunc(t.ingester.ReadinessHandler))
 	t.server.HTTP.Path("/flush").Handler(http.HandlerFunc(t.ingester.FlushHandler))
+	t.server.HTTP.Path("/flush_series").Handler(http.HandlerFunc(t.ingester.FlushSeriesHandler))
+	t.server.HTTP.Path("/memory_series").Handler(http.HandlerFunc(t.ingester.MemorySeriesHandler))
+	t.server.HTTP.Path("/shutdown").Handler(http.HandlerFunc(t.ingester.ShutdownHandler))
+	t.server.HTTP.Path("/readonly").Handler(http.HandlerFunc(t.ingester.ReadOnlyHandler))
 	return
 }
 
@@ -252,12 +293,16 @@ func (t *Cortex) stopStore() error {
 }
 
 func (t *Cortex) initQueryFrontend(cfg *Config) (err error) {
+	cfg.Frontend.EngineMaxConcurrent = cfg.Querier.EngineMaxConcurrent()
+	cfg.Frontend.EngineMaxSamples = cfg.Querier.MaxSamples
+	cfg.Frontend.EngineTimeout = cfg.Querier.Timeout
 	t.frontend, err = frontend.New(cfg.Frontend, util.Logger, t.overrides)
 	if err != nil {
 		return
 	}
 
 	frontend.RegisterFrontendServer(t.server.GRPC, t.frontend)
+	t.server.HTTP.Handle("/frontend/queues", t.httpAuthMiddleware.Wrap(http.HandlerFunc(t.frontend.QueueStatusHandler)))
 	t.server.HTTP.PathPrefix("/api/prom").Handler(
 		t.httpAuthMiddleware.Wrap(
 			t.frontend.Handler(),
@@ -318,7 +363,7 @@ func (t *Cortex) initRuler(cfg *Config) (err error) {
 	cfg.Querier.MaxConcurrent = cfg.Ruler.NumWorkers
 	cfg.Querier.Timeout = cfg.Ruler.GroupTimeout
 	cfg.Ruler.LifecyclerConfig.ListenPort = &cfg.Server.GRPCListenPort
-	queryable, engine := querier.New(cfg.Querier, t.distributor, t.store)
+	queryable, engine := querier.New(cfg.Querier, t.distributor, t.store, t.overrides)
 
 	rulesAPI, err := config_client.New(cfg.ConfigStore)
 	if err != nil {
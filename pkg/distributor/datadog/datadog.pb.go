@@ -0,0 +1,1586 @@
+// Code generated by protoc-gen-gogo. DO NOT EDIT.
+// source: datadog.proto
+
+package datadog
+
+import (
+	encoding_binary "encoding/binary"
+	fmt "fmt"
+	_ "github.com/gogo/protobuf/gogoproto"
+	proto "github.com/gogo/protobuf/proto"
+	io "io"
+	math "math"
+	reflect "reflect"
+	strconv "strconv"
+	strings "strings"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.GoGoProtoPackageIsVersion2 // please upgrade the proto package
+
+type MetricPayload_MetricType int32
+
+const (
+	UNSPECIFIED MetricPayload_MetricType = 0
+	COUNT       MetricPayload_MetricType = 1
+	RATE        MetricPayload_MetricType = 2
+	GAUGE       MetricPayload_MetricType = 3
+)
+
+var MetricPayload_MetricType_name = map[int32]string{
+	0: "UNSPECIFIED",
+	1: "COUNT",
+	2: "RATE",
+	3: "GAUGE",
+}
+
+var MetricPayload_MetricType_value = map[string]int32{
+	"UNSPECIFIED": 0,
+	"COUNT":       1,
+	"RATE":        2,
+	"GAUGE":       3,
+}
+
+func (MetricPayload_MetricType) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_295e4211ee1f5deb, []int{0, 0}
+}
+
+type MetricPayload struct {
+	Series []*MetricPayload_MetricSeries `protobuf:"bytes,1,rep,name=series,proto3" json:"series,omitempty"`
+}
+
+func (m *MetricPayload) Reset()      { *m = MetricPayload{} }
+func (*MetricPayload) ProtoMessage() {}
+func (*MetricPayload) Descriptor() ([]byte, []int) {
+	return fileDescriptor_295e4211ee1f5deb, []int{0}
+}
+func (m *MetricPayload) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *MetricPayload) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_MetricPayload.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *MetricPayload) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MetricPayload.Merge(m, src)
+}
+func (m *MetricPayload) XXX_Size() int {
+	return m.Size()
+}
+func (m *MetricPayload) XXX_DiscardUnknown() {
+	xxx_messageInfo_MetricPayload.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MetricPayload proto.InternalMessageInfo
+
+func (m *MetricPayload) GetSeries() []*MetricPayload_MetricSeries {
+	if m != nil {
+		return m.Series
+	}
+	return nil
+}
+
+type MetricPayload_MetricPoint struct {
+	Value float64 `protobuf:"fixed64,1,opt,name=value,proto3" json:"value,omitempty"`
+	// Seconds since the epoch.
+	Timestamp int64 `protobuf:"varint,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+}
+
+func (m *MetricPayload_MetricPoint) Reset()      { *m = MetricPayload_MetricPoint{} }
+func (*MetricPayload_MetricPoint) ProtoMessage() {}
+func (*MetricPayload_MetricPoint) Descriptor() ([]byte, []int) {
+	return fileDescriptor_295e4211ee1f5deb, []int{0, 0}
+}
+func (m *MetricPayload_MetricPoint) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *MetricPayload_MetricPoint) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_MetricPayload_MetricPoint.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *MetricPayload_MetricPoint) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MetricPayload_MetricPoint.Merge(m, src)
+}
+func (m *MetricPayload_MetricPoint) XXX_Size() int {
+	return m.Size()
+}
+func (m *MetricPayload_MetricPoint) XXX_DiscardUnknown() {
+	xxx_messageInfo_MetricPayload_MetricPoint.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MetricPayload_MetricPoint proto.InternalMessageInfo
+
+func (m *MetricPayload_MetricPoint) GetValue() float64 {
+	if m != nil {
+		return m.Value
+	}
+	return 0
+}
+
+func (m *MetricPayload_MetricPoint) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+type MetricPayload_Resource struct {
+	Type string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Name string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+}
+
+func (m *MetricPayload_Resource) Reset()      { *m = MetricPayload_Resource{} }
+func (*MetricPayload_Resource) ProtoMessage() {}
+func (*MetricPayload_Resource) Descriptor() ([]byte, []int) {
+	return fileDescriptor_295e4211ee1f5deb, []int{0, 1}
+}
+func (m *MetricPayload_Resource) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *MetricPayload_Resource) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_MetricPayload_Resource.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *MetricPayload_Resource) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MetricPayload_Resource.Merge(m, src)
+}
+func (m *MetricPayload_Resource) XXX_Size() int {
+	return m.Size()
+}
+func (m *MetricPayload_Resource) XXX_DiscardUnknown() {
+	xxx_messageInfo_MetricPayload_Resource.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MetricPayload_Resource proto.InternalMessageInfo
+
+func (m *MetricPayload_Resource) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *MetricPayload_Resource) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+type MetricPayload_MetricSeries struct {
+	Resources []*MetricPayload_Resource `protobuf:"bytes,1,rep,name=resources,proto3" json:"resources,omitempty"`
+	Metric    string                    `protobuf:"bytes,2,opt,name=metric,proto3" json:"metric,omitempty"`
+	// As "name:value".
+	Tags           []string                     `protobuf:"bytes,3,rep,name=tags,proto3" json:"tags,omitempty"`
+	Points         []*MetricPayload_MetricPoint `protobuf:"bytes,4,rep,name=points,proto3" json:"points,omitempty"`
+	Type           MetricPayload_MetricType     `protobuf:"varint,5,opt,name=type,proto3,enum=datadog.MetricPayload_MetricType" json:"type,omitempty"`
+	Unit           string                       `protobuf:"bytes,6,opt,name=unit,proto3" json:"unit,omitempty"`
+	SourceTypeName string                       `protobuf:"bytes,7,opt,name=source_type_name,json=sourceTypeName,proto3" json:"source_type_name,omitempty"`
+	// Seconds, of counts and rates.
+	Interval int64 `protobuf:"varint,8,opt,name=interval,proto3" json:"interval,omitempty"`
+}
+
+func (m *MetricPayload_MetricSeries) Reset()      { *m = MetricPayload_MetricSeries{} }
+func (*MetricPayload_MetricSeries) ProtoMessage() {}
+func (*MetricPayload_MetricSeries) Descriptor() ([]byte, []int) {
+	return fileDescriptor_295e4211ee1f5deb, []int{0, 2}
+}
+func (m *MetricPayload_MetricSeries) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *MetricPayload_MetricSeries) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_MetricPayload_MetricSeries.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *MetricPayload_MetricSeries) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MetricPayload_MetricSeries.Merge(m, src)
+}
+func (m *MetricPayload_MetricSeries) XXX_Size() int {
+	return m.Size()
+}
+func (m *MetricPayload_MetricSeries) XXX_DiscardUnknown() {
+	xxx_messageInfo_MetricPayload_MetricSeries.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MetricPayload_MetricSeries proto.InternalMessageInfo
+
+func (m *MetricPayload_MetricSeries) GetResources() []*MetricPayload_Resource {
+	if m != nil {
+		return m.Resources
+	}
+	return nil
+}
+
+func (m *MetricPayload_MetricSeries) GetMetric() string {
+	if m != nil {
+		return m.Metric
+	}
+	return ""
+}
+
+func (m *MetricPayload_MetricSeries) GetTags() []string {
+	if m != nil {
+		return m.Tags
+	}
+	return nil
+}
+
+func (m *MetricPayload_MetricSeries) GetPoints() []*MetricPayload_MetricPoint {
+	if m != nil {
+		return m.Points
+	}
+	return nil
+}
+
+func (m *MetricPayload_MetricSeries) GetType() MetricPayload_MetricType {
+	if m != nil {
+		return m.Type
+	}
+	return UNSPECIFIED
+}
+
+func (m *MetricPayload_MetricSeries) GetUnit() string {
+	if m != nil {
+		return m.Unit
+	}
+	return ""
+}
+
+func (m *MetricPayload_MetricSeries) GetSourceTypeName() string {
+	if m != nil {
+		return m.SourceTypeName
+	}
+	return ""
+}
+
+func (m *MetricPayload_MetricSeries) GetInterval() int64 {
+	if m != nil {
+		return m.Interval
+	}
+	return 0
+}
+
+func init() {
+	proto.RegisterEnum("datadog.MetricPayload_MetricType", MetricPayload_MetricType_name, MetricPayload_MetricType_value)
+	proto.RegisterType((*MetricPayload)(nil), "datadog.MetricPayload")
+	proto.RegisterType((*MetricPayload_MetricPoint)(nil), "datadog.MetricPayload.MetricPoint")
+	proto.RegisterType((*MetricPayload_Resource)(nil), "datadog.MetricPayload.Resource")
+	proto.RegisterType((*MetricPayload_MetricSeries)(nil), "datadog.MetricPayload.MetricSeries")
+}
+
+func init() { proto.RegisterFile("datadog.proto", fileDescriptor_295e4211ee1f5deb) }
+
+var fileDescriptor_295e4211ee1f5deb = []byte{
+	// 447 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x52, 0xc1, 0x6e, 0xd3, 0x40,
+	0x14, 0xf4, 0xc6, 0x89, 0x1b, 0xbf, 0xd0, 0x62, 0xad, 0x10, 0xb2, 0x2c, 0xb4, 0x98, 0x70, 0xf1,
+	0x85, 0x54, 0x0a, 0x70, 0x01, 0xf5, 0x10, 0x4a, 0xa8, 0x7a, 0x20, 0x54, 0x6e, 0x72, 0xae, 0x36,
+	0xc9, 0x62, 0x2c, 0xc5, 0x59, 0xcb, 0x5e, 0x57, 0xca, 0x8d, 0x4f, 0xe0, 0x33, 0xf8, 0x12, 0x84,
+	0xc4, 0x25, 0xc7, 0x1e, 0x89, 0x73, 0xe1, 0xd8, 0x4f, 0x40, 0x7e, 0xde, 0x10, 0x38, 0xd0, 0xdb,
+	0x9b, 0xb7, 0x33, 0x9e, 0x37, 0x23, 0xc3, 0xe1, 0x9c, 0x2b, 0x3e, 0x97, 0x51, 0x2f, 0xcd, 0xa4,
+	0x92, 0xf4, 0x40, 0x43, 0xef, 0x59, 0x14, 0xab, 0x4f, 0xc5, 0xb4, 0x37, 0x93, 0xc9, 0x71, 0x24,
+	0x23, 0x79, 0x8c, 0xef, 0xd3, 0xe2, 0x23, 0x22, 0x04, 0x38, 0xd5, 0xba, 0xee, 0x8f, 0x26, 0x1c,
+	0xbe, 0x17, 0x2a, 0x8b, 0x67, 0x17, 0x7c, 0xb5, 0x90, 0x7c, 0x4e, 0x5f, 0x83, 0x95, 0x8b, 0x2c,
+	0x16, 0xb9, 0x4b, 0x7c, 0x33, 0xe8, 0xf4, 0x9f, 0xf6, 0x76, 0x4e, 0xff, 0xf0, 0x34, 0xba, 0x44,
+	0x6a, 0xa8, 0x25, 0xde, 0x00, 0x3a, 0x9a, 0x25, 0xe3, 0xa5, 0xa2, 0x0f, 0xa0, 0x75, 0xcd, 0x17,
+	0x85, 0x70, 0x89, 0x4f, 0x02, 0x12, 0xd6, 0x80, 0x3e, 0x02, 0x5b, 0xc5, 0x89, 0xc8, 0x15, 0x4f,
+	0x52, 0xb7, 0xe1, 0x93, 0xc0, 0x0c, 0xf7, 0x0b, 0xaf, 0x0f, 0xed, 0x50, 0xe4, 0xb2, 0xc8, 0x66,
+	0x82, 0x52, 0x68, 0xaa, 0x55, 0x5a, 0xcb, 0xed, 0x10, 0xe7, 0x6a, 0xb7, 0xe4, 0x89, 0x40, 0xa1,
+	0x1d, 0xe2, 0xec, 0x7d, 0x6b, 0xc0, 0xbd, 0xbf, 0xef, 0xa1, 0x27, 0x60, 0x67, 0xfa, 0x23, 0xbb,
+	0x1c, 0x8f, 0xff, 0x93, 0x63, 0x67, 0x16, 0xee, 0x15, 0xf4, 0x21, 0x58, 0x09, 0x92, 0xb4, 0x8b,
+	0x46, 0x78, 0x0f, 0x8f, 0x72, 0xd7, 0xf4, 0x4d, 0xbc, 0x87, 0x47, 0x39, 0x7d, 0x05, 0x56, 0x5a,
+	0x85, 0xcd, 0xdd, 0x26, 0xfa, 0x74, 0xef, 0xec, 0x0b, 0x7b, 0x09, 0xb5, 0x82, 0xbe, 0xd4, 0xf9,
+	0x5a, 0x3e, 0x09, 0x8e, 0xfa, 0x4f, 0xee, 0x54, 0x8e, 0x57, 0xa9, 0xd8, 0x57, 0x50, 0x2c, 0x63,
+	0xe5, 0x5a, 0x75, 0x05, 0xd5, 0x4c, 0x03, 0x70, 0xea, 0xeb, 0xaf, 0x2a, 0xca, 0x15, 0x56, 0x74,
+	0x80, 0xef, 0x47, 0xf5, 0xbe, 0xd2, 0x8f, 0x78, 0x22, 0xa8, 0x07, 0xed, 0x78, 0xa9, 0x44, 0x76,
+	0xcd, 0x17, 0x6e, 0x1b, 0xdb, 0xff, 0x83, 0xbb, 0x27, 0x00, 0x7b, 0x37, 0x7a, 0x1f, 0x3a, 0x93,
+	0xd1, 0xe5, 0xc5, 0xf0, 0xf4, 0xfc, 0xdd, 0xf9, 0xf0, 0xad, 0x63, 0x50, 0x1b, 0x5a, 0xa7, 0x1f,
+	0x26, 0xa3, 0xb1, 0x43, 0x68, 0x1b, 0x9a, 0xe1, 0x60, 0x3c, 0x74, 0x1a, 0xd5, 0xf2, 0x6c, 0x30,
+	0x39, 0x1b, 0x3a, 0xe6, 0x9b, 0x17, 0xeb, 0x0d, 0x33, 0x6e, 0x36, 0xcc, 0xb8, 0xdd, 0x30, 0xf2,
+	0xb9, 0x64, 0xe4, 0x6b, 0xc9, 0xc8, 0xf7, 0x92, 0x91, 0x75, 0xc9, 0xc8, 0xcf, 0x92, 0x91, 0x5f,
+	0x25, 0x33, 0x6e, 0x4b, 0x46, 0xbe, 0x6c, 0x99, 0xb1, 0xde, 0x32, 0xe3, 0x66, 0xcb, 0x8c, 0xa9,
+	0x85, 0xbf, 0xe2, 0xf3, 0xdf, 0x03, 0x00, 0xb5, 0x27, 0x94, 0xc2, 0xd3, 0x02, 0x00, 0x00,
+}
+
+func (x MetricPayload_MetricType) String() string {
+	s, ok := MetricPayload_MetricType_name[int32(x)]
+	if ok {
+		return s
+	}
+	return strconv.Itoa(int(x))
+}
+func (this *MetricPayload) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*MetricPayload)
+	if !ok {
+		that2, ok := that.(MetricPayload)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if len(this.Series) != len(that1.Series) {
+		return false
+	}
+	for i := range this.Series {
+		if !this.Series[i].Equal(that1.Series[i]) {
+			return false
+		}
+	}
+	return true
+}
+func (this *MetricPayload_MetricPoint) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*MetricPayload_MetricPoint)
+	if !ok {
+		that2, ok := that.(MetricPayload_MetricPoint)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.Value != that1.Value {
+		return false
+	}
+	if this.Timestamp != that1.Timestamp {
+		return false
+	}
+	return true
+}
+func (this *MetricPayload_Resource) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*MetricPayload_Resource)
+	if !ok {
+		that2, ok := that.(MetricPayload_Resource)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.Type != that1.Type {
+		return false
+	}
+	if this.Name != that1.Name {
+		return false
+	}
+	return true
+}
+func (this *MetricPayload_MetricSeries) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*MetricPayload_MetricSeries)
+	if !ok {
+		that2, ok := that.(MetricPayload_MetricSeries)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if len(this.Resources) != len(that1.Resources) {
+		return false
+	}
+	for i := range this.Resources {
+		if !this.Resources[i].Equal(that1.Resources[i]) {
+			return false
+		}
+	}
+	if this.Metric != that1.Metric {
+		return false
+	}
+	if len(this.Tags) != len(that1.Tags) {
+		return false
+	}
+	for i := range this.Tags {
+		if this.Tags[i] != that1.Tags[i] {
+			return false
+		}
+	}
+	if len(this.Points) != len(that1.Points) {
+		return false
+	}
+	for i := range this.Points {
+		if !this.Points[i].Equal(that1.Points[i]) {
+			return false
+		}
+	}
+	if this.Type != that1.Type {
+		return false
+	}
+	if this.Unit != that1.Unit {
+		return false
+	}
+	if this.SourceTypeName != that1.SourceTypeName {
+		return false
+	}
+	if this.Interval != that1.Interval {
+		return false
+	}
+	return true
+}
+func (this *MetricPayload) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 5)
+	s = append(s, "&datadog.MetricPayload{")
+	if this.Series != nil {
+		s = append(s, "Series: "+fmt.Sprintf("%#v", this.Series)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *MetricPayload_MetricPoint) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 6)
+	s = append(s, "&datadog.MetricPayload_MetricPoint{")
+	s = append(s, "Value: "+fmt.Sprintf("%#v", this.Value)+",\n")
+	s = append(s, "Timestamp: "+fmt.Sprintf("%#v", this.Timestamp)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *MetricPayload_Resource) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 6)
+	s = append(s, "&datadog.MetricPayload_Resource{")
+	s = append(s, "Type: "+fmt.Sprintf("%#v", this.Type)+",\n")
+	s = append(s, "Name: "+fmt.Sprintf("%#v", this.Name)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *MetricPayload_MetricSeries) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 12)
+	s = append(s, "&datadog.MetricPayload_MetricSeries{")
+	if this.Resources != nil {
+		s = append(s, "Resources: "+fmt.Sprintf("%#v", this.Resources)+",\n")
+	}
+	s = append(s, "Metric: "+fmt.Sprintf("%#v", this.Metric)+",\n")
+	s = append(s, "Tags: "+fmt.Sprintf("%#v", this.Tags)+",\n")
+	if this.Points != nil {
+		s = append(s, "Points: "+fmt.Sprintf("%#v", this.Points)+",\n")
+	}
+	s = append(s, "Type: "+fmt.Sprintf("%#v", this.Type)+",\n")
+	s = append(s, "Unit: "+fmt.Sprintf("%#v", this.Unit)+",\n")
+	s = append(s, "SourceTypeName: "+fmt.Sprintf("%#v", this.SourceTypeName)+",\n")
+	s = append(s, "Interval: "+fmt.Sprintf("%#v", this.Interval)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func valueToGoStringDatadog(v interface{}, typ string) string {
+	rv := reflect.ValueOf(v)
+	if rv.IsNil() {
+		return "nil"
+	}
+	pv := reflect.Indirect(rv).Interface()
+	return fmt.Sprintf("func(v %v) *%v { return &v } ( %#v )", typ, typ, pv)
+}
+func (m *MetricPayload) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *MetricPayload) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Series) > 0 {
+		for _, msg := range m.Series {
+			dAtA[i] = 0xa
+			i++
+			i = encodeVarintDatadog(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	return i, nil
+}
+
+func (m *MetricPayload_MetricPoint) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *MetricPayload_MetricPoint) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Value != 0 {
+		dAtA[i] = 0x9
+		i++
+		encoding_binary.LittleEndian.PutUint64(dAtA[i:], uint64(math.Float64bits(float64(m.Value))))
+		i += 8
+	}
+	if m.Timestamp != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintDatadog(dAtA, i, uint64(m.Timestamp))
+	}
+	return i, nil
+}
+
+func (m *MetricPayload_Resource) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *MetricPayload_Resource) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Type) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintDatadog(dAtA, i, uint64(len(m.Type)))
+		i += copy(dAtA[i:], m.Type)
+	}
+	if len(m.Name) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintDatadog(dAtA, i, uint64(len(m.Name)))
+		i += copy(dAtA[i:], m.Name)
+	}
+	return i, nil
+}
+
+func (m *MetricPayload_MetricSeries) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *MetricPayload_MetricSeries) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Resources) > 0 {
+		for _, msg := range m.Resources {
+			dAtA[i] = 0xa
+			i++
+			i = encodeVarintDatadog(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if len(m.Metric) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintDatadog(dAtA, i, uint64(len(m.Metric)))
+		i += copy(dAtA[i:], m.Metric)
+	}
+	if len(m.Tags) > 0 {
+		for _, s := range m.Tags {
+			dAtA[i] = 0x1a
+			i++
+			l = len(s)
+			for l >= 1<<7 {
+				dAtA[i] = uint8(uint64(l)&0x7f | 0x80)
+				l >>= 7
+				i++
+			}
+			dAtA[i] = uint8(l)
+			i++
+			i += copy(dAtA[i:], s)
+		}
+	}
+	if len(m.Points) > 0 {
+		for _, msg := range m.Points {
+			dAtA[i] = 0x22
+			i++
+			i = encodeVarintDatadog(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if m.Type != 0 {
+		dAtA[i] = 0x28
+		i++
+		i = encodeVarintDatadog(dAtA, i, uint64(m.Type))
+	}
+	if len(m.Unit) > 0 {
+		dAtA[i] = 0x32
+		i++
+		i = encodeVarintDatadog(dAtA, i, uint64(len(m.Unit)))
+		i += copy(dAtA[i:], m.Unit)
+	}
+	if len(m.SourceTypeName) > 0 {
+		dAtA[i] = 0x3a
+		i++
+		i = encodeVarintDatadog(dAtA, i, uint64(len(m.SourceTypeName)))
+		i += copy(dAtA[i:], m.SourceTypeName)
+	}
+	if m.Interval != 0 {
+		dAtA[i] = 0x40
+		i++
+		i = encodeVarintDatadog(dAtA, i, uint64(m.Interval))
+	}
+	return i, nil
+}
+
+func encodeVarintDatadog(dAtA []byte, offset int, v uint64) int {
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return offset + 1
+}
+func (m *MetricPayload) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Series) > 0 {
+		for _, e := range m.Series {
+			l = e.Size()
+			n += 1 + l + sovDatadog(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *MetricPayload_MetricPoint) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Value != 0 {
+		n += 9
+	}
+	if m.Timestamp != 0 {
+		n += 1 + sovDatadog(uint64(m.Timestamp))
+	}
+	return n
+}
+
+func (m *MetricPayload_Resource) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Type)
+	if l > 0 {
+		n += 1 + l + sovDatadog(uint64(l))
+	}
+	l = len(m.Name)
+	if l > 0 {
+		n += 1 + l + sovDatadog(uint64(l))
+	}
+	return n
+}
+
+func (m *MetricPayload_MetricSeries) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Resources) > 0 {
+		for _, e := range m.Resources {
+			l = e.Size()
+			n += 1 + l + sovDatadog(uint64(l))
+		}
+	}
+	l = len(m.Metric)
+	if l > 0 {
+		n += 1 + l + sovDatadog(uint64(l))
+	}
+	if len(m.Tags) > 0 {
+		for _, s := range m.Tags {
+			l = len(s)
+			n += 1 + l + sovDatadog(uint64(l))
+		}
+	}
+	if len(m.Points) > 0 {
+		for _, e := range m.Points {
+			l = e.Size()
+			n += 1 + l + sovDatadog(uint64(l))
+		}
+	}
+	if m.Type != 0 {
+		n += 1 + sovDatadog(uint64(m.Type))
+	}
+	l = len(m.Unit)
+	if l > 0 {
+		n += 1 + l + sovDatadog(uint64(l))
+	}
+	l = len(m.SourceTypeName)
+	if l > 0 {
+		n += 1 + l + sovDatadog(uint64(l))
+	}
+	if m.Interval != 0 {
+		n += 1 + sovDatadog(uint64(m.Interval))
+	}
+	return n
+}
+
+func sovDatadog(x uint64) (n int) {
+	for {
+		n++
+		x >>= 7
+		if x == 0 {
+			break
+		}
+	}
+	return n
+}
+func sozDatadog(x uint64) (n int) {
+	return sovDatadog(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (this *MetricPayload) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&MetricPayload{`,
+		`Series:` + strings.Replace(fmt.Sprintf("%v", this.Series), "MetricPayload_MetricSeries", "MetricPayload_MetricSeries", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *MetricPayload_MetricPoint) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&MetricPayload_MetricPoint{`,
+		`Value:` + fmt.Sprintf("%v", this.Value) + `,`,
+		`Timestamp:` + fmt.Sprintf("%v", this.Timestamp) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *MetricPayload_Resource) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&MetricPayload_Resource{`,
+		`Type:` + fmt.Sprintf("%v", this.Type) + `,`,
+		`Name:` + fmt.Sprintf("%v", this.Name) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *MetricPayload_MetricSeries) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&MetricPayload_MetricSeries{`,
+		`Resources:` + strings.Replace(fmt.Sprintf("%v", this.Resources), "MetricPayload_Resource", "MetricPayload_Resource", 1) + `,`,
+		`Metric:` + fmt.Sprintf("%v", this.Metric) + `,`,
+		`Tags:` + fmt.Sprintf("%v", this.Tags) + `,`,
+		`Points:` + strings.Replace(fmt.Sprintf("%v", this.Points), "MetricPayload_MetricPoint", "MetricPayload_MetricPoint", 1) + `,`,
+		`Type:` + fmt.Sprintf("%v", this.Type) + `,`,
+		`Unit:` + fmt.Sprintf("%v", this.Unit) + `,`,
+		`SourceTypeName:` + fmt.Sprintf("%v", this.SourceTypeName) + `,`,
+		`Interval:` + fmt.Sprintf("%v", this.Interval) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func valueToStringDatadog(v interface{}) string {
+	rv := reflect.ValueOf(v)
+	if rv.IsNil() {
+		return "nil"
+	}
+	pv := reflect.Indirect(rv).Interface()
+	return fmt.Sprintf("*%v", pv)
+}
+func (m *MetricPayload) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowDatadog
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: MetricPayload: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: MetricPayload: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Series", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDatadog
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthDatadog
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthDatadog
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Series = append(m.Series, &MetricPayload_MetricSeries{})
+			if err := m.Series[len(m.Series)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipDatadog(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthDatadog
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthDatadog
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *MetricPayload_MetricPoint) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowDatadog
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: MetricPoint: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: MetricPoint: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 1 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Value", wireType)
+			}
+			var v uint64
+			if (iNdEx + 8) > l {
+				return io.ErrUnexpectedEOF
+			}
+			v = uint64(encoding_binary.LittleEndian.Uint64(dAtA[iNdEx:]))
+			iNdEx += 8
+			m.Value = float64(math.Float64frombits(v))
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Timestamp", wireType)
+			}
+			m.Timestamp = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDatadog
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Timestamp |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipDatadog(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthDatadog
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthDatadog
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *MetricPayload_Resource) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowDatadog
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Resource: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Resource: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Type", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDatadog
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthDatadog
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthDatadog
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Type = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDatadog
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthDatadog
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthDatadog
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Name = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipDatadog(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthDatadog
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthDatadog
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *MetricPayload_MetricSeries) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowDatadog
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: MetricSeries: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: MetricSeries: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Resources", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDatadog
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthDatadog
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthDatadog
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Resources = append(m.Resources, &MetricPayload_Resource{})
+			if err := m.Resources[len(m.Resources)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Metric", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDatadog
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthDatadog
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthDatadog
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Metric = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Tags", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDatadog
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthDatadog
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthDatadog
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Tags = append(m.Tags, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Points", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDatadog
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthDatadog
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthDatadog
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Points = append(m.Points, &MetricPayload_MetricPoint{})
+			if err := m.Points[len(m.Points)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Type", wireType)
+			}
+			m.Type = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDatadog
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Type |= MetricPayload_MetricType(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Unit", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDatadog
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthDatadog
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthDatadog
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Unit = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SourceTypeName", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDatadog
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthDatadog
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthDatadog
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.SourceTypeName = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 8:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Interval", wireType)
+			}
+			m.Interval = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDatadog
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Interval |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipDatadog(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthDatadog
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthDatadog
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func skipDatadog(dAtA []byte) (n int, err error) {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return 0, ErrIntOverflowDatadog
+			}
+			if iNdEx >= l {
+				return 0, io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		wireType := int(wire & 0x7)
+		switch wireType {
+		case 0:
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowDatadog
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				iNdEx++
+				if dAtA[iNdEx-1] < 0x80 {
+					break
+				}
+			}
+			return iNdEx, nil
+		case 1:
+			iNdEx += 8
+			return iNdEx, nil
+		case 2:
+			var length int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowDatadog
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				length |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if length < 0 {
+				return 0, ErrInvalidLengthDatadog
+			}
+			iNdEx += length
+			if iNdEx < 0 {
+				return 0, ErrInvalidLengthDatadog
+			}
+			return iNdEx, nil
+		case 3:
+			for {
+				var innerWire uint64
+				var start int = iNdEx
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return 0, ErrIntOverflowDatadog
+					}
+					if iNdEx >= l {
+						return 0, io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					innerWire |= (uint64(b) & 0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				innerWireType := int(innerWire & 0x7)
+				if innerWireType == 4 {
+					break
+				}
+				next, err := skipDatadog(dAtA[start:])
+				if err != nil {
+					return 0, err
+				}
+				iNdEx = start + next
+				if iNdEx < 0 {
+					return 0, ErrInvalidLengthDatadog
+				}
+			}
+			return iNdEx, nil
+		case 4:
+			return iNdEx, nil
+		case 5:
+			iNdEx += 4
+			return iNdEx, nil
+		default:
+			return 0, fmt.Errorf("proto: illegal wireType %d", wireType)
+		}
+	}
+	panic("unreachable")
+}
+
+var (
+	ErrInvalidLengthDatadog = fmt.Errorf("proto: negative length found during unmarshaling")
+	ErrIntOverflowDatadog   = fmt.Errorf("proto: integer overflow")
+)
@@ -0,0 +1,152 @@
+// Package datadog converts series submitted with the Datadog agent's series
+// v1 and v2 APIs into Cortex series.
+package datadog
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/pkg/labels"
+
+	"github.com/cortexproject/cortex/pkg/ingester/client"
+	"github.com/cortexproject/cortex/pkg/util"
+)
+
+// hostLabel is the label of the host a v1 series is from, like the name of
+// a v2 series' host resource.
+const hostLabel = "host"
+
+// SeriesV1 is the JSON payload of the series v1 API.
+type SeriesV1 struct {
+	Series []SeriesV1Series `json:"series"`
+}
+
+// SeriesV1Series is a series of the series v1 API, whose points are
+// [timestamp, value] pairs, with timestamps in seconds.
+type SeriesV1Series struct {
+	Metric   string       `json:"metric"`
+	Points   [][]*float64 `json:"points"`
+	Tags     []string     `json:"tags"`
+	Host     string       `json:"host"`
+	Device   string       `json:"device"`
+	Type     string       `json:"type"`
+	Interval int64        `json:"interval"`
+}
+
+// FromV1 converts the series of a series v1 payload, one sample per point.
+// The series' tags become labels, as do their host and device.  Points
+// without a value are skipped.  The series of the points which can be
+// converted are returned along with an error for the last point which
+// couldn't.
+func FromV1(req *SeriesV1) ([]client.PreallocTimeseries, error) {
+	c := converter{index: map[string]int{}}
+	for _, s := range req.Series {
+		ls, err := seriesLabels(s.Metric, s.Tags, func(b map[string]string) {
+			if s.Host != "" {
+				b[hostLabel] = s.Host
+			}
+			if s.Device != "" {
+				b["device"] = s.Device
+			}
+		})
+		if err != nil {
+			c.err = err
+			continue
+		}
+		for _, p := range s.Points {
+			if len(p) != 2 || p[0] == nil {
+				c.err = fmt.Errorf("series %q has a point which isn't a [timestamp, value] pair", s.Metric)
+				continue
+			}
+			if p[1] == nil {
+				continue
+			}
+			c.addSample(ls, int64(*p[0]*1000), *p[1])
+		}
+	}
+	return c.series, c.err
+}
+
+// FromV2 converts the series of a series v2 payload, one sample per point.
+// The series' tags become labels, as do their resources, named after their
+// type.  The series of the points which can be converted are returned along
+// with an error for the last series which couldn't.
+func FromV2(req *MetricPayload) ([]client.PreallocTimeseries, error) {
+	c := converter{index: map[string]int{}}
+	for _, s := range req.Series {
+		ls, err := seriesLabels(s.Metric, s.Tags, func(b map[string]string) {
+			for _, r := range s.Resources {
+				if r.Type != "" && r.Name != "" {
+					b[util.SanitizeLabelName(r.Type)] = r.Name
+				}
+			}
+		})
+		if err != nil {
+			c.err = err
+			continue
+		}
+		for _, p := range s.Points {
+			c.addSample(ls, p.Timestamp*1000, p.Value)
+		}
+	}
+	return c.series, c.err
+}
+
+// seriesLabels returns the labels of the series' name, its "name:value"
+// tags, and those set by extra, which override the tags.  Tags without a
+// value are skipped, and the values of the tags whose names are the same
+// once sanitized are joined with ";".
+func seriesLabels(metric string, tags []string, extra func(map[string]string)) (labels.Labels, error) {
+	if metric == "" {
+		return nil, fmt.Errorf("series missing metric name")
+	}
+
+	sanitized := make(map[string][]string, len(tags))
+	for _, tag := range tags {
+		i := strings.Index(tag, ":")
+		if i <= 0 || i == len(tag)-1 {
+			continue
+		}
+		name := util.SanitizeLabelName(tag[:i])
+		sanitized[name] = append(sanitized[name], tag[i+1:])
+	}
+	b := make(map[string]string, len(sanitized)+2)
+	for name, values := range sanitized {
+		sort.Strings(values)
+		b[name] = strings.Join(values, ";")
+	}
+	extra(b)
+	b[model.MetricNameLabel] = util.SanitizeMetricName(metric)
+
+	ls := make(labels.Labels, 0, len(b))
+	for name, value := range b {
+		ls = append(ls, labels.Label{Name: name, Value: value})
+	}
+	sort.Sort(ls)
+	return ls, nil
+}
+
+type converter struct {
+	series []client.PreallocTimeseries
+	// The index in series of each series' labels.
+	index map[string]int
+	err   error
+}
+
+func (c *converter) addSample(ls labels.Labels, timestampMs int64, v float64) {
+	sample := client.Sample{TimestampMs: timestampMs, Value: v}
+	key := ls.String()
+	if i, ok := c.index[key]; ok {
+		c.series[i].Samples = append(c.series[i].Samples, sample)
+		return
+	}
+	c.index[key] = len(c.series)
+	c.series = append(c.series, client.PreallocTimeseries{
+		TimeSeries: client.TimeSeries{
+			Labels:  client.FromLabelsToLabelAdapaters(ls),
+			Samples: []client.Sample{sample},
+		},
+	})
+}
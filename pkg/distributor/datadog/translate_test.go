@@ -0,0 +1,69 @@
+package datadog
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cortexproject/cortex/pkg/ingester/client"
+)
+
+type series struct {
+	labels  string
+	samples []client.Sample
+}
+
+func toSeries(timeseries []client.PreallocTimeseries) []series {
+	result := make([]series, 0, len(timeseries))
+	for _, ts := range timeseries {
+		result = append(result, series{client.FromLabelAdaptersToMetric(ts.Labels).String(), ts.Samples})
+	}
+	return result
+}
+
+func TestFromV1(t *testing.T) {
+	var req SeriesV1
+	require.NoError(t, json.Unmarshal([]byte(`{"series": [
+		{"metric": "system.load.1", "points": [[10, 0.5], [20.5, null], [30, 1]], "tags": ["env:prod", "role:db", "role:api", "2xx:yes", "bare", "host:ignored"], "host": "web-1", "type": "gauge"},
+		{"metric": "system.disk.free", "points": [[10, 7]], "device": "/dev/sda1", "type": "gauge"},
+		{"metric": "system.disk.free", "points": [[20, 8]], "device": "/dev/sda1", "type": "gauge"},
+		{"metric": "broken", "points": [[10]]},
+		{"metric": "", "points": [[10, 1]]}
+	]}`), &req))
+
+	timeseries, err := FromV1(&req)
+	assert.EqualError(t, err, "series missing metric name")
+	assert.Equal(t, []series{
+		{`system_load_1{env="prod", host="web-1", key_2xx="yes", role="api;db"}`, []client.Sample{{TimestampMs: 10000, Value: 0.5}, {TimestampMs: 30000, Value: 1}}},
+		{`system_disk_free{device="/dev/sda1"}`, []client.Sample{{TimestampMs: 10000, Value: 7}, {TimestampMs: 20000, Value: 8}}},
+	}, toSeries(timeseries))
+
+	req.Series = req.Series[:4]
+	_, err = FromV1(&req)
+	assert.EqualError(t, err, `series "broken" has a point which isn't a [timestamp, value] pair`)
+}
+
+func TestFromV2(t *testing.T) {
+	req := &MetricPayload{Series: []*MetricPayload_MetricSeries{{
+		Metric:    "datadog.agent.running",
+		Tags:      []string{"version:7"},
+		Resources: []*MetricPayload_Resource{{Type: "host", Name: "web-1"}, {Type: "cluster.name", Name: "prod"}},
+		Points:    []*MetricPayload_MetricPoint{{Timestamp: 10, Value: 1}, {Timestamp: 20, Value: 1}},
+		Type:      GAUGE,
+	}}}
+
+	timeseries, err := FromV2(req)
+	require.NoError(t, err)
+	assert.Equal(t, []series{
+		{`datadog_agent_running{cluster_name="prod", host="web-1", version="7"}`, []client.Sample{{TimestampMs: 10000, Value: 1}, {TimestampMs: 20000, Value: 1}}},
+	}, toSeries(timeseries))
+
+	// The JSON of the v2 API's payloads has the same fields.
+	var fromJSON MetricPayload
+	require.NoError(t, json.Unmarshal([]byte(`{"series": [{"metric": "datadog.agent.running", "type": 3, "tags": ["version:7"],
+		"resources": [{"type": "host", "name": "web-1"}, {"type": "cluster.name", "name": "prod"}],
+		"points": [{"timestamp": 10, "value": 1}, {"timestamp": 20, "value": 1}]}]}`), &fromJSON))
+	assert.Equal(t, req, &fromJSON)
+}
@@ -11,7 +11,9 @@ import (
 	"time"
 
 	"golang.org/x/time/rate"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/health/grpc_health_v1"
+	grpc_status "google.golang.org/grpc/status"
 
 	"github.com/go-kit/kit/log/level"
 	opentracing "github.com/opentracing/opentracing-go"
@@ -19,7 +21,10 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/pkg/relabel"
 
+	"github.com/cortexproject/cortex/pkg/distributor/graphite"
+	"github.com/cortexproject/cortex/pkg/distributor/otlp"
 	"github.com/cortexproject/cortex/pkg/ingester/client"
 	ingester_client "github.com/cortexproject/cortex/pkg/ingester/client"
 	"github.com/cortexproject/cortex/pkg/prom1/storage/metric"
@@ -45,6 +50,11 @@ var (
 		Name:      "distributor_received_samples_total",
 		Help:      "The total number of received samples.",
 	}, []string{"user"})
+	receivedMetadata = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "cortex",
+		Name:      "distributor_received_metadata_total",
+		Help:      "The total number of received metadata entries.",
+	}, []string{"user"})
 	sendDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
 		Namespace: "cortex",
 		Name:      "distributor_send_duration_seconds",
@@ -61,6 +71,11 @@ var (
 		Name:      "distributor_ingester_append_failures_total",
 		Help:      "The total number of failed batch appends sent to ingesters.",
 	}, []string{"ingester"})
+	underReplicatedSamples = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "cortex",
+		Name:      "distributor_under_replicated_samples_total",
+		Help:      "The total number of samples which couldn't be sent to one of their ingesters, by the class of its error. Pushes still succeed unless it happens to more than a minority of a sample's ingesters.",
+	}, []string{"user", "class"})
 	ingesterQueries = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: "cortex",
 		Name:      "distributor_ingester_queries_total",
@@ -71,6 +86,11 @@ var (
 		Name:      "distributor_ingester_query_failures_total",
 		Help:      "The total number of failed queries sent to ingesters.",
 	}, []string{"ingester"})
+	dedupedSamples = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "cortex",
+		Name:      "distributor_deduped_samples_total",
+		Help:      "The total number of deduplicated samples, received from HA replicas other than the elected one.",
+	}, []string{"user", "cluster"})
 	replicationFactor = promauto.NewGauge(prometheus.GaugeOpts{
 		Namespace: "cortex",
 		Name:      "distributor_replication_factor",
@@ -94,6 +114,16 @@ type Distributor struct {
 	ingestLimitersMtx sync.RWMutex
 	ingestLimiters    map[string]*rate.Limiter
 	quit              chan struct{}
+
+	// The distributors' ring, for the global ingestion rate limit strategy.
+	distributorsLifecycler *ring.Lifecycler
+	distributorsRing       *ring.Ring
+
+	// The running totals of OTLP delta temporality series, if converted.
+	otlpDeltas *otlp.Deltas
+
+	// The Graphite listener, if enabled.
+	graphiteListener *graphite.Listener
 }
 
 // Config contains the configuration require to
@@ -108,10 +138,18 @@ type Config struct {
 
 	RemoteTimeout       time.Duration `yaml:"remote_timeout,omitempty"`
 	ExtraQueryDelay     time.Duration `yaml:"extra_queue_delay,omitempty"`
+	ConsistentReads     bool          `yaml:"consistent_reads,omitempty"`
 	LimiterReloadPeriod time.Duration `yaml:"limiter_reload_period,omitempty"`
 
 	ShardByAllLabels bool `yaml:"shard_by_all_labels,omitempty"`
 
+	IngestionRateStrategy string                `yaml:"ingestion_rate_strategy,omitempty"`
+	DistributorRing       ring.LifecyclerConfig `yaml:"ring,omitempty"`
+
+	OTLPConvertDeltas bool `yaml:"otlp_convert_delta_to_cumulative,omitempty"`
+
+	Graphite graphite.Config `yaml:"graphite,omitempty"`
+
 	// for testing
 	ingesterClientFactory client.Factory
 }
@@ -121,17 +159,26 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	cfg.BillingConfig.RegisterFlags(f)
 	cfg.PoolConfig.RegisterFlags(f)
 	cfg.HATrackerConfig.RegisterFlags(f)
+	// Distributors have nothing to flush, so needn't wait long before exiting.
+	cfg.DistributorRing.RegisterFlagsWithPrefixAndFinalSleep("distributor.", time.Second, f)
+	cfg.Graphite.RegisterFlags(f)
 
 	f.BoolVar(&cfg.EnableBilling, "distributor.enable-billing", false, "Report number of ingested samples to billing system.")
 	f.BoolVar(&cfg.EnableHAReplicas, "distributor.accept-ha-labels", false, "Accept samples from Prometheus HA replicas gracefully (requires labels).")
 	f.DurationVar(&cfg.RemoteTimeout, "distributor.remote-timeout", 2*time.Second, "Timeout for downstream ingesters.")
 	f.DurationVar(&cfg.ExtraQueryDelay, "distributor.extra-query-delay", 0, "Time to wait before sending more than the minimum successful query requests.")
+	f.BoolVar(&cfg.ConsistentReads, "distributor.consistent-reads", false, "Wait for all the live ingesters a query is sent to to respond, rather than just enough of them for a quorum, so that samples just pushed are visible to queries even while the ring changes. Queries take as long as their slowest ingester.")
 	f.DurationVar(&cfg.LimiterReloadPeriod, "distributor.limiter-reload-period", 5*time.Minute, "Period at which to reload user ingestion limits.")
 	f.BoolVar(&cfg.ShardByAllLabels, "distributor.shard-by-all-labels", false, "Distribute samples based on all labels, as opposed to solely by user and metric name.")
+	f.BoolVar(&cfg.OTLPConvertDeltas, "distributor.otlp.convert-delta-to-cumulative", false, "Convert the points of OTLP delta temporality sums and histograms into cumulative ones, by accumulating them in each distributor, rather than rejecting them. A series' pushes must then all go to the same distributor.")
+	f.StringVar(&cfg.IngestionRateStrategy, "distributor.ingestion-rate-limit-strategy", LocalIngestionRateStrategy, "Whether the ingestion rate limit is enforced by each distributor (local), shared between the healthy distributors of the distributors' ring (global), or not enforced (infinite).")
 }
 
 // New constructs a new Distributor
 func New(cfg Config, clientConfig ingester_client.Config, limits *validation.Overrides, ring ring.ReadRing) (*Distributor, error) {
+	if err := validateIngestionRateStrategy(cfg.IngestionRateStrategy); err != nil {
+		return nil, err
+	}
 	if cfg.ingesterClientFactory == nil {
 		cfg.ingesterClientFactory = func(addr string) (grpc_health_v1.HealthClient, error) {
 			return ingester_client.MakeIngesterClient(addr, clientConfig)
@@ -168,11 +215,50 @@ func New(cfg Config, clientConfig ingester_client.Config, limits *validation.Ove
 		d.replicas = replicas
 	}
 
+	if cfg.IngestionRateStrategy == GlobalIngestionRateStrategy {
+		var err error
+		d.distributorsLifecycler, d.distributorsRing, err = newDistributorsRing(cfg.DistributorRing, d)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.OTLPConvertDeltas {
+		d.otlpDeltas = otlp.NewDeltas()
+		go d.expireOTLPDeltas()
+	}
+
+	if cfg.Graphite.ListenAddress != "" {
+		var err error
+		d.graphiteListener, err = graphite.NewListener(cfg.Graphite, d.Push)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	go d.loop()
 
 	return d, nil
 }
 
+// otlpDeltasTimeout is how long the running totals of OTLP delta
+// temporality series without new points are kept.
+const otlpDeltasTimeout = 15 * time.Minute
+
+func (d *Distributor) expireOTLPDeltas() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			d.otlpDeltas.Expire(now.Add(-otlpDeltasTimeout))
+		case <-d.quit:
+			return
+		}
+	}
+}
+
 func (d *Distributor) loop() {
 	if d.cfg.LimiterReloadPeriod == 0 {
 		return
@@ -196,11 +282,18 @@ func (d *Distributor) loop() {
 
 // Stop stops the distributor's maintenance loop.
 func (d *Distributor) Stop() {
+	if d.graphiteListener != nil {
+		d.graphiteListener.Stop()
+	}
 	close(d.quit)
 	d.ingesterPool.Stop()
 	if d.cfg.EnableHAReplicas {
 		d.replicas.stop()
 	}
+	if d.distributorsLifecycler != nil {
+		d.distributorsLifecycler.Shutdown()
+		d.distributorsRing.Stop()
+	}
 }
 
 func (d *Distributor) tokenForLabels(userID string, labels []client.LabelAdapter) (uint32, error) {
@@ -222,6 +315,19 @@ func shardByMetricName(userID string, metricName string) uint32 {
 	return h
 }
 
+// ringForUser returns the ring of the ingesters the user's series are sharded
+// over, and so the ingesters its queries are sent to.
+func (d *Distributor) ringForUser(userID string) ring.ReadRing {
+	size := d.limits.IngestionTenantShardSize(userID)
+	if size <= 0 {
+		return d.ring
+	}
+	if rf := d.ring.ReplicationFactor(); size < rf {
+		size = rf
+	}
+	return d.ring.Subring(client.HashAdd32(client.HashNew32(), userID), size)
+}
+
 func shardByAllLabels(userID string, labels []client.LabelAdapter) (uint32, error) {
 	h := client.HashNew32()
 	h = client.HashAdd32(h, userID)
@@ -236,23 +342,46 @@ func shardByAllLabels(userID string, labels []client.LabelAdapter) (uint32, erro
 	return h, nil
 }
 
-// Remove the replica label from a slice of LabelPairs if it exists.
-func removeReplicaLabel(replica string, labels *[]client.LabelAdapter) {
+// Remove the named label from a slice of LabelPairs if it exists.
+func removeLabel(name string, labels *[]client.LabelAdapter) {
 	for i := 0; i < len(*labels); i++ {
 		pair := (*labels)[i]
-		if pair.Name == replica {
+		if pair.Name == name {
 			*labels = append((*labels)[:i], (*labels)[i+1:]...)
 			return
 		}
 	}
 }
 
+// withoutLabels returns the labels without the named ones, in a new slice if
+// any of them are there, leaving the request's own untouched.
+func withoutLabels(labels []client.LabelAdapter, names []string) []client.LabelAdapter {
+	var result []client.LabelAdapter
+	for i, l := range labels {
+		drop := false
+		for _, name := range names {
+			if l.Name == name {
+				drop = true
+				break
+			}
+		}
+		switch {
+		case drop && result == nil:
+			result = append(make([]client.LabelAdapter, 0, len(labels)-1), labels[:i]...)
+		case !drop && result != nil:
+			result = append(result, l)
+		}
+	}
+	if result == nil {
+		return labels
+	}
+	return result
+}
+
 // Returns a boolean that indicates whether or not we want to remove the replica label going forward,
 // and an error that indicates whether we want to accept samples based on the cluster/replica found in ts.
 // nil for the error means accept the sample.
-func (d *Distributor) checkSample(ctx context.Context, userID string, ts client.PreallocTimeseries) (bool, error) {
-	cluster, replica := findHALabels(d.limits.HAReplicaLabel(userID), d.limits.HAClusterLabel(userID), ts.Labels)
-
+func (d *Distributor) checkSample(ctx context.Context, userID, cluster, replica string) (bool, error) {
 	// If the sample doesn't have either HA label, accept it.
 	// At the moment we want to accept these samples by default.
 	if cluster == "" || replica == "" {
@@ -276,17 +405,42 @@ func (d *Distributor) checkSample(ctx context.Context, userID string, ts client.
 
 // Push implements client.IngesterServer
 func (d *Distributor) Push(ctx context.Context, req *client.WriteRequest) (*client.WriteResponse, error) {
+	return d.push(ctx, req, func() {})
+}
+
+// push pushes the request, calling cleanup once nothing references it any
+// more, which may be after push has returned as samples are still being
+// sent to the ingesters beyond the quorum.
+func (d *Distributor) push(ctx context.Context, req *client.WriteRequest, cleanup func()) (*client.WriteResponse, error) {
+	cleanupInDefer := true
+	defer func() {
+		if cleanupInDefer {
+			cleanup()
+		}
+	}()
+
 	userID, err := user.ExtractOrgID(ctx)
 	if err != nil {
 		return nil, err
 	}
+	if err := validation.ValidateTenantID(userID); err != nil {
+		return nil, err
+	}
 
 	var lastPartialErr error
 	removeReplica := false
 
 	if d.cfg.EnableHAReplicas && d.limits.AcceptHASamples(userID) && len(req.Timeseries) > 0 {
-		removeReplica, err = d.checkSample(ctx, userID, req.Timeseries[0])
+		cluster, replica := findHALabels(d.limits.HAReplicaLabel(userID), d.limits.HAClusterLabel(userID), req.Timeseries[0].Labels)
+		removeReplica, err = d.checkSample(ctx, userID, cluster, replica)
 		if err != nil {
+			if resp, ok := httpgrpc.HTTPResponseFromError(err); ok && resp.GetCode() == 202 {
+				numSamples := 0
+				for _, ts := range req.Timeseries {
+					numSamples += len(ts.Samples)
+				}
+				dedupedSamples.WithLabelValues(userID, cluster).Add(float64(numSamples))
+			}
 			return nil, err
 		}
 	}
@@ -295,12 +449,29 @@ func (d *Distributor) Push(ctx context.Context, req *client.WriteRequest) (*clie
 	validatedTimeseries := make([]client.PreallocTimeseries, 0, len(req.Timeseries))
 	keys := make([]uint32, 0, len(req.Timeseries))
 	numSamples := 0
+	relabelConfigs := d.limits.MetricRelabelConfigs(userID)
+	dropLabels := d.limits.DropLabels(userID)
 	for _, ts := range req.Timeseries {
+		// Relabeling returns new labels, leaving the request's own untouched.
+		if len(relabelConfigs) > 0 {
+			ls := relabel.Process(client.FromLabelAdaptersToLabels(ts.Labels), relabelConfigs...)
+			if ls == nil {
+				validation.DiscardedSamples.WithLabelValues(validation.RelabelDropped, userID).Add(float64(len(ts.Samples)))
+				continue
+			}
+			ts.Labels = client.FromLabelsToLabelAdapaters(ls)
+		}
+
 		// If we found both the cluster and replica labels, we only want to include the cluster label when
 		// storing series in Cortex. If we kept the replica label we would end up with another series for the same
 		// series we're trying to dedupe when HA tracking moves over to a different replica.
 		if removeReplica {
-			removeReplicaLabel(d.limits.HAReplicaLabel(userID), &ts.Labels)
+			removeLabel(d.limits.HAReplicaLabel(userID), &ts.Labels)
+		}
+		// Dropped labels are removed before hashing, so the series which
+		// only differ by them are stored as one.
+		if len(dropLabels) > 0 {
+			ts.Labels = withoutLabels(ts.Labels, dropLabels)
 		}
 		key, err := d.tokenForLabels(userID, ts.Labels)
 		if err != nil {
@@ -321,12 +492,23 @@ func (d *Distributor) Push(ctx context.Context, req *client.WriteRequest) (*clie
 			}
 			samples = append(samples, s)
 		}
+		d.limits.DiscardHistograms(userID, ts.Histograms)
+
+		var exemplars []client.Exemplar
+		for _, e := range ts.Exemplars {
+			if err := d.limits.ValidateExemplar(userID, metricName, e); err != nil {
+				lastPartialErr = err
+				continue
+			}
+			exemplars = append(exemplars, e)
+		}
 
 		keys = append(keys, key)
 		validatedTimeseries = append(validatedTimeseries, client.PreallocTimeseries{
 			TimeSeries: client.TimeSeries{
-				Labels:  ts.Labels,
-				Samples: samples,
+				Labels:    ts.Labels,
+				Samples:   samples,
+				Exemplars: exemplars,
 			},
 		})
 
@@ -334,22 +516,45 @@ func (d *Distributor) Push(ctx context.Context, req *client.WriteRequest) (*clie
 	}
 	receivedSamples.WithLabelValues(userID).Add(float64(numSamples))
 
-	if len(keys) == 0 {
+	// Metadata goes to the ingesters of its metric, whatever the sharding of
+	// series, so each metric's metadata is in one replication set.
+	validatedMetadata := make([]*client.MetricMetadata, 0, len(req.Metadata))
+	metadataKeys := make([]uint32, 0, len(req.Metadata))
+	for _, m := range req.Metadata {
+		if err := d.limits.ValidateMetadata(userID, m); err != nil {
+			lastPartialErr = err
+			continue
+		}
+		metadataKeys = append(metadataKeys, shardByMetricName(userID, m.MetricFamilyName))
+		validatedMetadata = append(validatedMetadata, m)
+	}
+	receivedMetadata.WithLabelValues(userID).Add(float64(len(validatedMetadata)))
+
+	if len(keys) == 0 && len(metadataKeys) == 0 {
 		return &client.WriteResponse{}, lastPartialErr
 	}
 
+	// Metadata entries count towards the limit like samples, so a client
+	// can't flood the ingesters with metadata alone.
 	limiter := d.getOrCreateIngestLimiter(userID)
-	if !limiter.AllowN(time.Now(), numSamples) {
+	if !limiter.AllowN(time.Now(), numSamples+len(validatedMetadata)) {
 		// Return a 4xx here to have the client discard the data and not retry. If a client
 		// is sending too much data consistently we will unlikely ever catch up otherwise.
 		validation.DiscardedSamples.WithLabelValues(validation.RateLimited, userID).Add(float64(numSamples))
-		return nil, httpgrpc.Errorf(http.StatusTooManyRequests, "ingestion rate limit (%v) exceeded while adding %d samples", limiter.Limit(), numSamples)
+		validation.DiscardedMetadata.WithLabelValues(validation.RateLimited, userID).Add(float64(len(validatedMetadata)))
+		return nil, httpgrpc.Errorf(http.StatusTooManyRequests, "ingestion rate limit (%v) exceeded while adding %d samples and %d metadata", limiter.Limit(), numSamples, len(validatedMetadata))
 	}
 
-	err = ring.DoBatch(ctx, d.ring, keys, func(ingester ring.IngesterDesc, indexes []int) error {
+	cleanupInDefer = false
+	err = ring.DoBatch(ctx, d.ringForUser(userID), append(keys, metadataKeys...), func(ingester ring.IngesterDesc, indexes []int) error {
 		timeseries := make([]client.PreallocTimeseries, 0, len(indexes))
+		var metadata []*client.MetricMetadata
 		for _, i := range indexes {
-			timeseries = append(timeseries, validatedTimeseries[i])
+			if i >= len(validatedTimeseries) {
+				metadata = append(metadata, validatedMetadata[i-len(validatedTimeseries)])
+			} else {
+				timeseries = append(timeseries, validatedTimeseries[i])
+			}
 		}
 
 		// Use a background context to make sure all ingesters get samples even if we return early
@@ -359,8 +564,19 @@ func (d *Distributor) Push(ctx context.Context, req *client.WriteRequest) (*clie
 		if sp := opentracing.SpanFromContext(ctx); sp != nil {
 			localCtx = opentracing.ContextWithSpan(localCtx, sp)
 		}
-		return d.sendSamples(localCtx, ingester, timeseries)
-	})
+		err := d.sendSamples(localCtx, ingester, timeseries, metadata)
+		if err != nil {
+			var samples int
+			for _, ts := range timeseries {
+				samples += len(ts.Samples)
+			}
+			class := pushErrorClass(err)
+			failed := failedSamples(err, samples)
+			underReplicatedSamples.WithLabelValues(userID, class).Add(float64(failed))
+			level.Debug(util.Logger).Log("msg", "samples not sent to ingester", "user", userID, "ingester", ingester.Addr, "series", len(timeseries), "samples", samples, "failed", failed, "class", class, "err", err)
+		}
+		return err
+	}, cleanup)
 	if err != nil {
 		return nil, err
 	}
@@ -368,15 +584,22 @@ func (d *Distributor) Push(ctx context.Context, req *client.WriteRequest) (*clie
 }
 
 func (d *Distributor) getOrCreateIngestLimiter(userID string) *rate.Limiter {
+	limit := d.ingestionRateLimit(userID)
+
 	d.ingestLimitersMtx.RLock()
 	limiter, ok := d.ingestLimiters[userID]
 	d.ingestLimitersMtx.RUnlock()
 
 	if ok {
+		// The global strategy's limit changes with the number of healthy
+		// distributors, without waiting for the limiters to be reloaded.
+		if limiter.Limit() != limit {
+			limiter.SetLimit(limit)
+		}
 		return limiter
 	}
 
-	limiter = rate.NewLimiter(rate.Limit(d.limits.IngestionRate(userID)), d.limits.IngestionBurstSize(userID))
+	limiter = rate.NewLimiter(limit, d.limits.IngestionBurstSize(userID))
 
 	d.ingestLimitersMtx.Lock()
 	d.ingestLimiters[userID] = limiter
@@ -385,7 +608,7 @@ func (d *Distributor) getOrCreateIngestLimiter(userID string) *rate.Limiter {
 	return limiter
 }
 
-func (d *Distributor) sendSamples(ctx context.Context, ingester ring.IngesterDesc, timeseries []client.PreallocTimeseries) error {
+func (d *Distributor) sendSamples(ctx context.Context, ingester ring.IngesterDesc, timeseries []client.PreallocTimeseries, metadata []*client.MetricMetadata) error {
 	h, err := d.ingesterPool.GetClientFor(ingester.Addr)
 	if err != nil {
 		return err
@@ -394,6 +617,7 @@ func (d *Distributor) sendSamples(ctx context.Context, ingester ring.IngesterDes
 
 	req := client.WriteRequest{
 		Timeseries: timeseries,
+		Metadata:   metadata,
 	}
 	_, err = c.Push(ctx, &req)
 
@@ -404,9 +628,53 @@ func (d *Distributor) sendSamples(ctx context.Context, ingester ring.IngesterDes
 	return err
 }
 
-// forAllIngesters runs f, in parallel, for all ingesters
+// The classes of the errors of pushes to ingesters.
+const (
+	pushErrorClient      = "4xx"
+	pushErrorServer      = "5xx"
+	pushErrorTimeout     = "timeout"
+	pushErrorUnavailable = "unavailable"
+)
+
+// failedSamples returns how many of the samples sent to an ingester it
+// didn't store: only those it rejected for a 4xx listing them, but all of
+// them otherwise, as they might not have reached it.
+func failedSamples(err error, samples int) int {
+	resp, ok := httpgrpc.HTTPResponseFromError(err)
+	if !ok || resp.Code/100 != 4 {
+		return samples
+	}
+	errs, decodeErr := client.PushErrorsFromHTTPResponse(resp)
+	if decodeErr != nil || errs == nil {
+		return samples
+	}
+	return int(errs.RejectedSamples)
+}
+
+// pushErrorClass returns the class of the error of a push to an ingester:
+// the class of the status the ingester replied with, or whether it didn't
+// reply in time or at all.
+func pushErrorClass(err error) string {
+	if resp, ok := httpgrpc.HTTPResponseFromError(err); ok {
+		if resp.Code/100 == 4 {
+			return pushErrorClient
+		}
+		return pushErrorServer
+	}
+	if err == context.DeadlineExceeded || grpc_status.Code(err) == codes.DeadlineExceeded {
+		return pushErrorTimeout
+	}
+	return pushErrorUnavailable
+}
+
+// forAllIngesters runs f, in parallel, for all the ingesters of the user's
+// shard.
 func (d *Distributor) forAllIngesters(ctx context.Context, reallyAll bool, f func(client.IngesterClient) (interface{}, error)) ([]interface{}, error) {
-	replicationSet, err := d.ring.GetAll()
+	userID, err := user.ExtractOrgID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	replicationSet, err := d.ringForUser(userID).GetAll()
 	if err != nil {
 		return nil, err
 	}
@@ -414,7 +682,7 @@ func (d *Distributor) forAllIngesters(ctx context.Context, reallyAll bool, f fun
 		replicationSet.MaxErrors = 0
 	}
 
-	return replicationSet.Do(ctx, d.cfg.ExtraQueryDelay, func(ing *ring.IngesterDesc) (interface{}, error) {
+	return d.doQuery(ctx, replicationSet, func(ing *ring.IngesterDesc) (interface{}, error) {
 		client, err := d.ingesterPool.GetClientFor(ing.Addr)
 		if err != nil {
 			return nil, err
@@ -424,6 +692,19 @@ func (d *Distributor) forAllIngesters(ctx context.Context, reallyAll bool, f fun
 	})
 }
 
+// doQuery runs the read f for the ingesters of the replication set.  With
+// -distributor.consistent-reads it waits for all of them; otherwise it returns
+// once enough have answered for a quorum of each series' replicas.  As the
+// querier's and distributors' views of the ring can differ while ingesters
+// join or leave, that quorum need not include one of the ingesters a just
+// acknowledged push was written to.
+func (d *Distributor) doQuery(ctx context.Context, replicationSet ring.ReplicationSet, f func(*ring.IngesterDesc) (interface{}, error)) ([]interface{}, error) {
+	if d.cfg.ConsistentReads {
+		return replicationSet.DoAll(ctx, f)
+	}
+	return replicationSet.Do(ctx, d.cfg.ExtraQueryDelay, f)
+}
+
 // LabelValuesForLabelName returns all of the label values that are associated with a given label name.
 func (d *Distributor) LabelValuesForLabelName(ctx context.Context, labelName model.LabelName) ([]string, error) {
 	req := &client.LabelValuesRequest{
@@ -509,6 +790,88 @@ func (d *Distributor) MetricsForLabelMatchers(ctx context.Context, from, through
 	return result, nil
 }
 
+// QueryExemplars returns the exemplars of the series matching any of the sets
+// of matchers, merging those of the replicas of each series.
+func (d *Distributor) QueryExemplars(ctx context.Context, from, through model.Time, matchersSet ...[]*labels.Matcher) (*client.ExemplarQueryResponse, error) {
+	req, err := ingester_client.ToExemplarQueryRequest(from, through, matchersSet)
+	if err != nil {
+		return nil, err
+	}
+
+	resps, err := d.forAllIngesters(ctx, false, func(client client.IngesterClient) (interface{}, error) {
+		return client.QueryExemplars(ctx, req)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	series := map[model.Fingerprint]*client.TimeSeries{}
+	seen := map[model.Fingerprint]map[int64]struct{}{}
+	for _, resp := range resps {
+		for _, ts := range resp.(*client.ExemplarQueryResponse).Timeseries {
+			fp := client.FastFingerprint(ts.Labels)
+			result, ok := series[fp]
+			if !ok {
+				result = &client.TimeSeries{Labels: ts.Labels}
+				series[fp] = result
+				seen[fp] = map[int64]struct{}{}
+			}
+			for _, e := range ts.Exemplars {
+				if _, ok := seen[fp][e.TimestampMs]; ok {
+					continue
+				}
+				seen[fp][e.TimestampMs] = struct{}{}
+				result.Exemplars = append(result.Exemplars, e)
+			}
+		}
+	}
+
+	result := &client.ExemplarQueryResponse{
+		Timeseries: make([]client.TimeSeries, 0, len(series)),
+	}
+	for _, ts := range series {
+		sort.Slice(ts.Exemplars, func(i, j int) bool {
+			return ts.Exemplars[i].TimestampMs < ts.Exemplars[j].TimestampMs
+		})
+		result.Timeseries = append(result.Timeseries, *ts)
+	}
+	sort.Slice(result.Timeseries, func(i, j int) bool {
+		return labels.Compare(client.FromLabelAdaptersToLabels(result.Timeseries[i].Labels), client.FromLabelAdaptersToLabels(result.Timeseries[j].Labels)) < 0
+	})
+	return result, nil
+}
+
+// MetricsMetadata returns the metadata of the user's metrics held by the
+// ingesters, deduplicated.
+func (d *Distributor) MetricsMetadata(ctx context.Context) ([]*client.MetricMetadata, error) {
+	req := &client.MetricsMetadataRequest{}
+	resps, err := d.forAllIngesters(ctx, false, func(client client.IngesterClient) (interface{}, error) {
+		return client.MetricsMetadata(ctx, req)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[client.MetricMetadata]struct{}{}
+	result := []*client.MetricMetadata{}
+	for _, resp := range resps {
+		for _, m := range resp.(*client.MetricsMetadataResponse).Metadata {
+			if _, ok := seen[*m]; ok {
+				continue
+			}
+			seen[*m] = struct{}{}
+			result = append(result, m)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].MetricFamilyName != result[j].MetricFamilyName {
+			return result[i].MetricFamilyName < result[j].MetricFamilyName
+		}
+		return result[i].String() < result[j].String()
+	})
+	return result, nil
+}
+
 // UserStats returns statistics about the current user.
 func (d *Distributor) UserStats(ctx context.Context) (*UserStats, error) {
 	req := &client.UserStatsRequest{}
@@ -526,10 +889,12 @@ func (d *Distributor) UserStats(ctx context.Context) (*UserStats, error) {
 		totalStats.APIIngestionRate += r.ApiIngestionRate
 		totalStats.RuleIngestionRate += r.RuleIngestionRate
 		totalStats.NumSeries += r.NumSeries
+		totalStats.ActiveSeries += r.ActiveSeries
 	}
 
 	totalStats.IngestionRate /= float64(d.ring.ReplicationFactor())
 	totalStats.NumSeries /= uint64(d.ring.ReplicationFactor())
+	totalStats.ActiveSeries /= uint64(d.ring.ReplicationFactor())
 
 	return totalStats, nil
 }
@@ -568,6 +933,7 @@ func (d *Distributor) AllUserStats(ctx context.Context) ([]UserIDStats, error) {
 			s.APIIngestionRate += u.Data.ApiIngestionRate
 			s.RuleIngestionRate += u.Data.RuleIngestionRate
 			s.NumSeries += u.Data.NumSeries
+			s.ActiveSeries += u.Data.ActiveSeries
 			perUserTotals[u.UserId] = s
 		}
 	}
@@ -582,6 +948,7 @@ func (d *Distributor) AllUserStats(ctx context.Context) ([]UserIDStats, error) {
 				APIIngestionRate:  stats.APIIngestionRate,
 				RuleIngestionRate: stats.RuleIngestionRate,
 				NumSeries:         stats.NumSeries,
+				ActiveSeries:      stats.ActiveSeries,
 			},
 		})
 	}
@@ -2,30 +2,45 @@ package distributor
 
 import (
 	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"flag"
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"sort"
 	"strconv"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/golang/snappy"
 	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/pkg/relabel"
 	"github.com/prometheus/prometheus/promql"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/net/context"
+	"golang.org/x/time/rate"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/health/grpc_health_v1"
+	grpc_status "google.golang.org/grpc/status"
 
 	"github.com/cortexproject/cortex/pkg/chunk/encoding"
+	"github.com/cortexproject/cortex/pkg/distributor/datadog"
+	"github.com/cortexproject/cortex/pkg/distributor/otlp"
+	"github.com/cortexproject/cortex/pkg/distributor/writev2"
 	"github.com/cortexproject/cortex/pkg/ingester/client"
 	"github.com/cortexproject/cortex/pkg/ring"
 	"github.com/cortexproject/cortex/pkg/util/chunkcompat"
 	"github.com/cortexproject/cortex/pkg/util/flagext"
+	"github.com/cortexproject/cortex/pkg/util/test"
 	"github.com/cortexproject/cortex/pkg/util/validation"
 	"github.com/weaveworks/common/httpgrpc"
 	"github.com/weaveworks/common/user"
@@ -42,6 +57,7 @@ func TestDistributorPush(t *testing.T) {
 		numIngesters     int
 		happyIngesters   int
 		samples          int
+		metadata         int
 		expectedResponse *client.WriteResponse
 		expectedError    error
 	}{
@@ -89,7 +105,16 @@ func TestDistributorPush(t *testing.T) {
 			numIngesters:   3,
 			happyIngesters: 3,
 			samples:        30,
-			expectedError:  httpgrpc.Errorf(http.StatusTooManyRequests, "ingestion rate limit (20) exceeded while adding 30 samples"),
+			expectedError:  httpgrpc.Errorf(http.StatusTooManyRequests, "ingestion rate limit (20) exceeded while adding 30 samples and 0 metadata"),
+		},
+
+		// Metadata counts towards the ingestion rate limit too
+		{
+			numIngesters:   3,
+			happyIngesters: 3,
+			samples:        10,
+			metadata:       15,
+			expectedError:  httpgrpc.Errorf(http.StatusTooManyRequests, "ingestion rate limit (20) exceeded while adding 10 samples and 15 metadata"),
 		},
 	} {
 		for _, shardByAllLabels := range []bool{true, false} {
@@ -98,6 +123,9 @@ func TestDistributorPush(t *testing.T) {
 				defer d.Stop()
 
 				request := makeWriteRequest(tc.samples)
+				for j := 0; j < tc.metadata; j++ {
+					request.Metadata = append(request.Metadata, &client.MetricMetadata{MetricFamilyName: fmt.Sprintf("metric_%d", j), Type: client.COUNTER})
+				}
 				response, err := d.Push(ctx, request)
 				assert.Equal(t, tc.expectedResponse, response)
 				assert.Equal(t, tc.expectedError, err)
@@ -106,6 +134,17 @@ func TestDistributorPush(t *testing.T) {
 	}
 }
 
+func TestDistributorPushInvalidTenantID(t *testing.T) {
+	d := prepare(t, 3, 3, 0, true)
+	defer d.Stop()
+
+	// Queriers would take it for the tenants a and b.
+	_, err := d.Push(user.InjectOrgID(context.Background(), "a|b"), makeWriteRequest(1))
+	resp, ok := httpgrpc.HTTPResponseFromError(err)
+	require.True(t, ok)
+	require.Equal(t, int32(http.StatusBadRequest), resp.Code)
+}
+
 func TestDistributorPushHAInstances(t *testing.T) {
 	ctx = user.InjectOrgID(context.Background(), "user")
 
@@ -279,6 +318,79 @@ func TestDistributorPushQuery(t *testing.T) {
 	}
 }
 
+func TestConfigRegisterFlags(t *testing.T) {
+	// The distributors' ring flags don't clash with the ingesters' ring.
+	f := flag.NewFlagSet("test", flag.PanicOnError)
+	var (
+		cfg     Config
+		ingRing ring.Config
+	)
+	cfg.RegisterFlags(f)
+	ingRing.RegisterFlags(f)
+
+	require.NotNil(t, f.Lookup("distributor.ring.replication-factor"))
+	require.Equal(t, "1s", f.Lookup("distributor.final-sleep").DefValue)
+	require.Equal(t, time.Second, cfg.DistributorRing.FinalSleep)
+}
+
+func TestDistributorIngestionRateStrategy(t *testing.T) {
+	codec := ring.ProtoCodec{Factory: ring.ProtoDescFactory}
+	kv := ring.NewInMemoryKVClient(codec)
+	newDistributor := func(id string, strategy string) *Distributor {
+		var cfg Config
+		var limits validation.Limits
+		var clientConfig client.Config
+		flagext.DefaultValues(&cfg, &limits, &clientConfig)
+		limits.IngestionRate = 10
+		limits.IngestionBurstSize = 10
+		cfg.IngestionRateStrategy = strategy
+		cfg.DistributorRing.RingConfig.KVStore.Mock = kv
+		cfg.DistributorRing.NumTokens = 1
+		cfg.DistributorRing.FinalSleep = 0
+		cfg.DistributorRing.ListenPort = func(i int) *int { return &i }(0)
+		cfg.DistributorRing.Addr = id
+		cfg.DistributorRing.ID = id
+
+		overrides, err := validation.NewOverrides(limits)
+		require.NoError(t, err)
+		d, err := New(cfg, clientConfig, overrides, mockRing{
+			Counter:           prometheus.NewCounter(prometheus.CounterOpts{Name: "foo"}),
+			replicationFactor: 3,
+		})
+		require.NoError(t, err)
+		return d
+	}
+	limit := func(d *Distributor) func() interface{} {
+		return func() interface{} { return d.ingestionRateLimit("user") }
+	}
+
+	local := newDistributor("local", LocalIngestionRateStrategy)
+	defer local.Stop()
+	assert.Equal(t, rate.Limit(10), local.ingestionRateLimit("user"))
+
+	infinite := newDistributor("infinite", InfiniteIngestionRateStrategy)
+	defer infinite.Stop()
+	assert.Equal(t, rate.Inf, infinite.ingestionRateLimit("user"))
+
+	// The global limit is shared between the distributors in the ring.
+	d1 := newDistributor("distributor-1", GlobalIngestionRateStrategy)
+	defer d1.Stop()
+	test.Poll(t, time.Second, rate.Limit(10), limit(d1))
+	d2 := newDistributor("distributor-2", GlobalIngestionRateStrategy)
+	test.Poll(t, time.Second, rate.Limit(5), limit(d1))
+	test.Poll(t, time.Second, rate.Limit(5), limit(d2))
+
+	// Limiters already created follow the number of distributors.
+	limiter := d1.getOrCreateIngestLimiter("user")
+	d2.Stop()
+	test.Poll(t, time.Second, rate.Limit(10), limit(d1))
+	assert.Equal(t, rate.Limit(10), d1.getOrCreateIngestLimiter("user").Limit())
+	assert.Equal(t, limiter, d1.getOrCreateIngestLimiter("user"))
+
+	_, err := New(Config{IngestionRateStrategy: "foo"}, client.Config{}, nil, nil)
+	assert.Error(t, err)
+}
+
 func TestSlowQueries(t *testing.T) {
 	nameMatcher := mustEqualMatcher(model.MetricNameLabel, "foo")
 	nIngesters := 3
@@ -474,6 +586,17 @@ func (r mockRing) ReplicationFactor() int {
 	return int(r.replicationFactor)
 }
 
+func (r mockRing) Subring(key uint32, n int) ring.ReadRing {
+	if n <= 0 || n >= len(r.ingesters) {
+		return r
+	}
+	result := mockRing{replicationFactor: r.replicationFactor}
+	for i := 0; i < n; i++ {
+		result.ingesters = append(result.ingesters, r.ingesters[(int(key)+i)%len(r.ingesters)])
+	}
+	return result
+}
+
 type mockIngester struct {
 	sync.Mutex
 	client.IngesterClient
@@ -482,6 +605,7 @@ type mockIngester struct {
 	stats      client.UsersStatsResponse
 	timeseries map[uint32]*client.PreallocTimeseries
 	queryDelay time.Duration
+	metadata   []*client.MetricMetadata
 }
 
 func (i *mockIngester) Push(ctx context.Context, req *client.WriteRequest, opts ...grpc.CallOption) (*client.WriteResponse, error) {
@@ -510,6 +634,7 @@ func (i *mockIngester) Push(ctx context.Context, req *client.WriteRequest, opts
 			existing.Samples = append(existing.Samples, req.Timeseries[j].Samples...)
 		}
 	}
+	i.metadata = append(i.metadata, req.Metadata...)
 
 	return &client.WriteResponse{}, nil
 }
@@ -615,6 +740,41 @@ func (i *mockIngester) AllUserStats(ctx context.Context, in *client.UserStatsReq
 	return &i.stats, nil
 }
 
+func (i *mockIngester) QueryExemplars(ctx context.Context, req *client.ExemplarQueryRequest, opts ...grpc.CallOption) (*client.ExemplarQueryResponse, error) {
+	i.Lock()
+	defer i.Unlock()
+
+	if !i.happy {
+		return nil, errFail
+	}
+
+	_, _, matchersSet, err := client.FromExemplarQueryRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	response := client.ExemplarQueryResponse{}
+	for _, ts := range i.timeseries {
+		for _, matchers := range matchersSet {
+			if len(ts.Exemplars) > 0 && match(ts.Labels, matchers) {
+				response.Timeseries = append(response.Timeseries, client.TimeSeries{Labels: ts.Labels, Exemplars: ts.Exemplars})
+				break
+			}
+		}
+	}
+	return &response, nil
+}
+
+func (i *mockIngester) MetricsMetadata(ctx context.Context, req *client.MetricsMetadataRequest, opts ...grpc.CallOption) (*client.MetricsMetadataResponse, error) {
+	i.Lock()
+	defer i.Unlock()
+
+	if !i.happy {
+		return nil, errFail
+	}
+	return &client.MetricsMetadataResponse{Metadata: i.metadata}, nil
+}
+
 func match(labels []client.LabelAdapter, matchers []*labels.Matcher) bool {
 outer:
 	for _, matcher := range matchers {
@@ -653,7 +813,7 @@ func TestDistributorValidation(t *testing.T) {
 				Timestamp: past,
 				Value:     2,
 			}},
-			err: httpgrpc.Errorf(http.StatusBadRequest, "sample for 'testmetric' has timestamp too old: %d", past),
+			err: httpgrpc.Errorf(http.StatusBadRequest, "sample for 'testmetric' has timestamp too old: %d, older than the reject_old_samples_max_age of 24h0m0s", past),
 		},
 
 		// Test validation fails for samples from the future.
@@ -663,7 +823,7 @@ func TestDistributorValidation(t *testing.T) {
 				Timestamp: future,
 				Value:     4,
 			}},
-			err: httpgrpc.Errorf(http.StatusBadRequest, "sample for 'testmetric' has timestamp too new: %d", future),
+			err: httpgrpc.Errorf(http.StatusBadRequest, "sample for 'testmetric' has timestamp too new: %d, further in the future than the creation_grace_period of 2h0m0s", future),
 		},
 
 		// Test maximum labels names per series.
@@ -691,6 +851,398 @@ func TestDistributorValidation(t *testing.T) {
 	}
 }
 
+func TestDistributorExemplars(t *testing.T) {
+	d := prepare(t, 3, 3, 0, true)
+	defer d.Stop()
+
+	ls := []client.LabelAdapter{{Name: model.MetricNameLabel, Value: "foo"}}
+	exemplar := client.Exemplar{
+		Labels:      []client.LabelAdapter{{Name: "traceID", Value: "abc"}},
+		Value:       1,
+		TimestampMs: int64(model.Now()),
+	}
+	_, err := d.Push(ctx, &client.WriteRequest{
+		Timeseries: []client.PreallocTimeseries{{
+			TimeSeries: client.TimeSeries{
+				Labels:    ls,
+				Samples:   []client.Sample{{TimestampMs: exemplar.TimestampMs, Value: 1}},
+				Exemplars: []client.Exemplar{exemplar, {Value: 2, TimestampMs: exemplar.TimestampMs}},
+			},
+		}},
+	})
+	require.Equal(t, httpgrpc.Errorf(http.StatusBadRequest, "exemplar for 'foo' has no labels"), err)
+
+	// Each replica's exemplars are only returned once.
+	resp, err := d.QueryExemplars(ctx, 0, model.Now(), []*labels.Matcher{mustEqualMatcher(model.MetricNameLabel, "foo")})
+	require.NoError(t, err)
+	require.Equal(t, []client.TimeSeries{{Labels: ls, Exemplars: []client.Exemplar{exemplar}}}, resp.Timeseries)
+}
+
+func TestDistributorNativeHistograms(t *testing.T) {
+	d := prepare(t, 3, 3, 0, true)
+	defer d.Stop()
+
+	discarded := func() float64 {
+		var m dto.Metric
+		require.NoError(t, validation.DiscardedSamples.WithLabelValues("native_histogram", "user").Write(&m))
+		return m.GetCounter().GetValue()
+	}
+	before := discarded()
+
+	ls := []client.LabelAdapter{{Name: model.MetricNameLabel, Value: "foo"}}
+	_, err := d.Push(ctx, &client.WriteRequest{
+		Timeseries: []client.PreallocTimeseries{{
+			TimeSeries: client.TimeSeries{
+				Labels:     ls,
+				Samples:    []client.Sample{{TimestampMs: 1, Value: 1}},
+				Histograms: []client.Histogram{{Count: &client.Histogram_CountInt{CountInt: 1}, Sum: 1, Timestamp: 2}},
+			},
+		}},
+	})
+	require.NoError(t, err)
+
+	// The histogram samples are discarded and counted.
+	require.Equal(t, float64(1), discarded()-before)
+
+	// The series' float samples are still ingested.
+	matrix, err := d.Query(ctx, 0, 10, mustEqualMatcher(model.MetricNameLabel, "foo"))
+	require.NoError(t, err)
+	require.Equal(t, model.Matrix{{
+		Metric: model.Metric{model.MetricNameLabel: "foo"},
+		Values: []model.SamplePair{{Timestamp: 1, Value: 1}},
+	}}, matrix)
+}
+
+func TestDistributorMetricsMetadata(t *testing.T) {
+	d := prepare(t, 3, 3, 0, true)
+	defer d.Stop()
+
+	foo := &client.MetricMetadata{MetricFamilyName: "foo", Type: client.COUNTER, Help: "Foo."}
+	bar := &client.MetricMetadata{MetricFamilyName: "bar", Type: client.GAUGE, Help: "Bar."}
+	_, err := d.Push(ctx, &client.WriteRequest{
+		Metadata: []*client.MetricMetadata{foo, bar, {Help: "Nameless."}},
+	})
+	require.Equal(t, httpgrpc.Errorf(http.StatusBadRequest, "metadata missing metric name"), err)
+
+	// Each replica's metadata is only returned once.
+	metadata, err := d.MetricsMetadata(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []*client.MetricMetadata{bar, foo}, metadata)
+}
+
+func TestDistributorMetricRelabeling(t *testing.T) {
+	d := prepare(t, 3, 3, 0, true)
+	defer d.Stop()
+	d.limits.Defaults.MetricRelabelConfigs = []*relabel.Config{
+		{SourceLabels: model.LabelNames{"sample"}, Separator: ";", Regex: relabel.MustNewRegexp("[0-4]"), Action: relabel.Drop},
+		{Regex: relabel.MustNewRegexp("bar"), Action: relabel.LabelDrop},
+	}
+
+	discarded := func() float64 {
+		var m dto.Metric
+		require.NoError(t, validation.DiscardedSamples.WithLabelValues("relabel_dropped", "user").Write(&m))
+		return m.GetCounter().GetValue()
+	}
+	before := discarded()
+
+	req := makeWriteRequest(10)
+	_, err := d.Push(ctx, req)
+	require.NoError(t, err)
+	// The dropped series' samples are counted as discarded.
+	assert.Equal(t, float64(5), discarded()-before)
+	// The request itself isn't relabeled.
+	assert.Equal(t, "baz", client.FromLabelAdaptersToLabels(req.Timeseries[0].Labels).Get("bar"))
+
+	matrix, err := d.Query(ctx, 0, 10, mustEqualMatcher(model.MetricNameLabel, "foo"))
+	require.NoError(t, err)
+	require.Len(t, matrix, 5)
+	for _, series := range matrix {
+		assert.NotContains(t, series.Metric, model.LabelName("bar"))
+		assert.NotContains(t, []model.LabelValue{"0", "1", "2", "3", "4"}, series.Metric["sample"])
+	}
+}
+
+func TestDistributorDropLabels(t *testing.T) {
+	d := prepare(t, 3, 3, 0, true)
+	defer d.Stop()
+	d.limits.Defaults.DropLabels = []string{"sample", "missing"}
+
+	req := makeWriteRequest(10)
+	_, err := d.Push(ctx, req)
+	require.NoError(t, err)
+	// The request itself keeps its labels.
+	assert.Equal(t, "0", client.FromLabelAdaptersToLabels(req.Timeseries[0].Labels).Get("sample"))
+
+	// The series only differing by the dropped label are one.
+	matrix, err := d.Query(ctx, 0, 10, mustEqualMatcher(model.MetricNameLabel, "foo"))
+	require.NoError(t, err)
+	require.Len(t, matrix, 1)
+	assert.Equal(t, model.Metric{model.MetricNameLabel: "foo", "bar": "baz"}, matrix[0].Metric)
+	assert.Len(t, matrix[0].Values, 10)
+}
+
+func TestDistributorUnderReplicatedSamples(t *testing.T) {
+	d := prepare(t, 3, 2, 0, true)
+	defer d.Stop()
+
+	before := underReplicatedSamplesValue(t, "user", pushErrorUnavailable)
+	response, err := d.Push(ctx, makeWriteRequest(10))
+	require.NoError(t, err)
+	assert.Equal(t, success, response)
+
+	// The failed ingester may only be recorded once the push has returned.
+	test.Poll(t, time.Second, before+10, func() interface{} {
+		return underReplicatedSamplesValue(t, "user", pushErrorUnavailable)
+	})
+}
+
+func underReplicatedSamplesValue(t *testing.T, userID, class string) float64 {
+	var m dto.Metric
+	require.NoError(t, underReplicatedSamples.WithLabelValues(userID, class).Write(&m))
+	return m.GetCounter().GetValue()
+}
+
+func TestPushErrorClass(t *testing.T) {
+	for _, tc := range []struct {
+		err      error
+		expected string
+	}{
+		{httpgrpc.Errorf(http.StatusBadRequest, "out of order sample"), pushErrorClient},
+		{httpgrpc.Errorf(http.StatusTooManyRequests, "too many series"), pushErrorClient},
+		{httpgrpc.Errorf(http.StatusInternalServerError, "store failed"), pushErrorServer},
+		{context.DeadlineExceeded, pushErrorTimeout},
+		{grpc_status.Error(codes.DeadlineExceeded, "deadline exceeded"), pushErrorTimeout},
+		{grpc_status.Error(codes.Unavailable, "connection refused"), pushErrorUnavailable},
+		{errFail, pushErrorUnavailable},
+	} {
+		assert.Equal(t, tc.expected, pushErrorClass(tc.err), tc.err.Error())
+	}
+}
+
+func TestFailedSamples(t *testing.T) {
+	for _, tc := range []struct {
+		err      error
+		expected int
+	}{
+		{client.ErrorFromPushErrors(http.StatusBadRequest, "out of order sample", &client.PushErrors{RejectedSamples: 3}), 3},
+		{httpgrpc.Errorf(http.StatusTooManyRequests, "too many inflight push requests"), 10},
+		{httpgrpc.Errorf(http.StatusInternalServerError, "store failed"), 10},
+		{grpc_status.Error(codes.Unavailable, "connection refused"), 10},
+	} {
+		assert.Equal(t, tc.expected, failedSamples(tc.err, 10), tc.err.Error())
+	}
+}
+
+func TestDistributorPushHandlerRemoteWrite2(t *testing.T) {
+	d := prepare(t, 3, 3, 0, true)
+	defer d.Stop()
+
+	now := model.Now()
+	buf, err := (&writev2.Request{
+		Symbols: []string{"", "__name__", "up", "job", "api", "Whether the target is up."},
+		Timeseries: []writev2.TimeSeries{{
+			LabelsRefs: []uint32{1, 2, 3, 4},
+			Samples:    []writev2.Sample{{Value: 1, Timestamp: int64(now)}},
+			Metadata:   writev2.Metadata{Type: writev2.METRIC_TYPE_GAUGE, HelpRef: 5},
+		}},
+	}).Marshal()
+	require.NoError(t, err)
+
+	push := func(contentType string, body []byte) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("POST", "/api/prom/push", bytes.NewReader(snappy.Encode(nil, body))).WithContext(ctx)
+		r.Header.Set("Content-Type", contentType)
+		r.Header.Set("Content-Encoding", "snappy")
+		r.Header.Set("X-Prometheus-Remote-Write-Version", "2.0.0")
+		w := httptest.NewRecorder()
+		d.PushHandler(w, r)
+		return w
+	}
+	w := push("application/x-protobuf;proto=io.prometheus.write.v2.Request", buf)
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+	assert.Equal(t, "1", w.Header().Get("X-Prometheus-Remote-Write-Samples-Written"))
+	assert.Equal(t, "0", w.Header().Get("X-Prometheus-Remote-Write-Histograms-Written"))
+	assert.Equal(t, "0", w.Header().Get("X-Prometheus-Remote-Write-Exemplars-Written"))
+
+	matrix, err := d.Query(ctx, 0, now+1, mustEqualMatcher(model.MetricNameLabel, "up"))
+	require.NoError(t, err)
+	require.Len(t, matrix, 1)
+	assert.Equal(t, model.Metric{model.MetricNameLabel: "up", "job": "api"}, matrix[0].Metric)
+	assert.Equal(t, []model.SamplePair{{Timestamp: now, Value: 1}}, matrix[0].Values)
+
+	metadata, err := d.MetricsMetadata(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []*client.MetricMetadata{{MetricFamilyName: "up", Type: client.GAUGE, Help: "Whether the target is up."}}, metadata)
+
+	// Requests without a proto, or with that of 1.0, are 1.0 WriteRequests.
+	buf, err = makeWriteRequest(1).Marshal()
+	require.NoError(t, err)
+	for _, contentType := range []string{"", "application/x-protobuf", "application/x-protobuf;proto=prometheus.WriteRequest"} {
+		w = push(contentType, buf)
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	}
+
+	w = push("application/x-protobuf;proto=io.prometheus.write.v3.Request", buf)
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+}
+
+func TestDistributorOTLPHandler(t *testing.T) {
+	d := prepare(t, 3, 3, 0, true)
+	defer d.Stop()
+
+	now := time.Now()
+	req := &otlp.ExportMetricsServiceRequest{ResourceMetrics: []*otlp.ResourceMetrics{{
+		Resource: &otlp.Resource{Attributes: []*otlp.KeyValue{
+			{Key: "service.name", Value: &otlp.AnyValue{Value: &otlp.AnyValue_StringValue{StringValue: "api"}}},
+		}},
+		ScopeMetrics: []*otlp.ScopeMetrics{{Metrics: []*otlp.Metric{{
+			Name: "queue.length",
+			Data: &otlp.Metric_Gauge{Gauge: &otlp.Gauge{DataPoints: []*otlp.NumberDataPoint{{
+				TimeUnixNano: uint64(now.UnixNano()),
+				Value:        &otlp.NumberDataPoint_AsInt{AsInt: 3},
+			}}}},
+		}}}},
+	}}}
+	buf, err := req.Marshal()
+	require.NoError(t, err)
+	var body bytes.Buffer
+	gz := gzip.NewWriter(&body)
+	_, err = gz.Write(buf)
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+
+	r := httptest.NewRequest("POST", "/otlp/v1/metrics", &body).WithContext(ctx)
+	r.Header.Set("Content-Type", "application/x-protobuf")
+	r.Header.Set("Content-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	d.OTLPHandler(w, r)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	matrix, err := d.Query(ctx, 0, model.TimeFromUnixNano(now.UnixNano())+1, mustEqualMatcher(model.MetricNameLabel, "queue_length"))
+	require.NoError(t, err)
+	require.Len(t, matrix, 1)
+	assert.Equal(t, model.LabelValue("api"), matrix[0].Metric[model.JobLabel])
+	assert.Equal(t, []model.SamplePair{{Timestamp: model.TimeFromUnixNano(now.UnixNano()), Value: 3}}, matrix[0].Values)
+
+	// Only protobuf is accepted.
+	r = httptest.NewRequest("POST", "/otlp/v1/metrics", strings.NewReader("{}")).WithContext(ctx)
+	r.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	d.OTLPHandler(w, r)
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+}
+
+func TestDistributorInfluxHandler(t *testing.T) {
+	d := prepare(t, 3, 3, 0, true)
+	defer d.Stop()
+
+	now := model.Now()
+	body := fmt.Sprintf("cpu,host=a usage=0.5,state=\"ok\" %d\ncpu,host=b usage=", now.Unix())
+	r := httptest.NewRequest("POST", "/api/v1/push/influx?precision=s", strings.NewReader(body)).WithContext(ctx)
+	w := httptest.NewRecorder()
+	d.InfluxHandler(w, r)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "line 2: invalid field \"usage=\"\n", w.Body.String())
+
+	// The valid lines are still ingested.
+	matrix, err := d.Query(ctx, 0, now+1, mustEqualMatcher(model.MetricNameLabel, "cpu_usage"))
+	require.NoError(t, err)
+	require.Len(t, matrix, 1)
+	assert.Equal(t, model.LabelValue("a"), matrix[0].Metric["host"])
+	assert.Equal(t, []model.SamplePair{{Timestamp: model.TimeFromUnix(now.Unix()), Value: 0.5}}, matrix[0].Values)
+
+	// Lines sent form-encoded, as by curl --data-binary, aren't consumed as a
+	// form.
+	body = fmt.Sprintf("mem,host=a free=7 %d", now.Unix())
+	r = httptest.NewRequest("POST", "/api/v1/push/influx?precision=s", strings.NewReader(body)).WithContext(ctx)
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w = httptest.NewRecorder()
+	d.InfluxHandler(w, r)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	matrix, err = d.Query(ctx, 0, now+1, mustEqualMatcher(model.MetricNameLabel, "mem_free"))
+	require.NoError(t, err)
+	require.Len(t, matrix, 1)
+	assert.Equal(t, []model.SamplePair{{Timestamp: model.TimeFromUnix(now.Unix()), Value: 7}}, matrix[0].Values)
+}
+
+func TestDistributorDatadogHandlers(t *testing.T) {
+	d := prepare(t, 3, 3, 0, true)
+	defer d.Stop()
+
+	now := model.Now()
+	body := fmt.Sprintf(`{"series": [{"metric": "system.load.1", "points": [[%d, 0.5]], "tags": ["env:prod"], "host": "a", "type": "gauge"}]}`, now.Unix())
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	_, err := zw.Write([]byte(body))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	r := httptest.NewRequest("POST", "/datadog/api/v1/series", &buf).WithContext(ctx)
+	r.Header.Set("Content-Encoding", "deflate")
+	w := httptest.NewRecorder()
+	d.DatadogSeriesV1Handler(w, r)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, "{\"status\":\"ok\"}\n", w.Body.String())
+
+	payload, err := (&datadog.MetricPayload{Series: []*datadog.MetricPayload_MetricSeries{{
+		Metric:    "system.load.1",
+		Tags:      []string{"env:prod"},
+		Resources: []*datadog.MetricPayload_Resource{{Type: "host", Name: "b"}},
+		Points:    []*datadog.MetricPayload_MetricPoint{{Timestamp: now.Unix(), Value: 1.5}},
+		Type:      datadog.GAUGE,
+	}}}).Marshal()
+	require.NoError(t, err)
+	r = httptest.NewRequest("POST", "/datadog/api/v2/series", bytes.NewReader(payload)).WithContext(ctx)
+	r.Header.Set("Content-Type", "application/x-protobuf")
+	w = httptest.NewRecorder()
+	d.DatadogSeriesV2Handler(w, r)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, "{\"errors\":[]}\n", w.Body.String())
+
+	matrix, err := d.Query(ctx, 0, now+1, mustEqualMatcher(model.MetricNameLabel, "system_load_1"))
+	require.NoError(t, err)
+	require.Len(t, matrix, 2)
+	sort.Slice(matrix, func(i, j int) bool { return matrix[i].Metric["host"] < matrix[j].Metric["host"] })
+	for i, host := range []model.LabelValue{"a", "b"} {
+		assert.Equal(t, model.Metric{model.MetricNameLabel: "system_load_1", "env": "prod", "host": host}, matrix[i].Metric)
+	}
+	assert.Equal(t, []model.SamplePair{{Timestamp: model.TimeFromUnix(now.Unix()), Value: 0.5}}, matrix[0].Values)
+	assert.Equal(t, []model.SamplePair{{Timestamp: model.TimeFromUnix(now.Unix()), Value: 1.5}}, matrix[1].Values)
+}
+
+func TestDistributorShuffleSharding(t *testing.T) {
+	d := prepare(t, 10, 10, 0, true)
+	defer d.Stop()
+	d.limits.Defaults.IngestionTenantShardSize = 4
+
+	_, err := d.Push(ctx, makeWriteRequest(10))
+	require.NoError(t, err)
+
+	// The user's series are only written to its shard's ingesters.
+	shard, err := d.ringForUser("user").GetAll()
+	require.NoError(t, err)
+	require.Len(t, shard.Ingesters, 4)
+	inShard := map[string]bool{}
+	for _, ing := range shard.Ingesters {
+		inShard[ing.Addr] = true
+	}
+	for i := 0; i < 10; i++ {
+		addr := fmt.Sprintf("%d", i)
+		if inShard[addr] {
+			continue
+		}
+		c, err := d.ingesterPool.GetClientFor(addr)
+		require.NoError(t, err)
+		ing := c.(*mockIngester)
+		ing.Lock()
+		require.Empty(t, ing.timeseries)
+		ing.Unlock()
+	}
+
+	// The shard's ingesters are all queried.
+	matrix, err := d.Query(ctx, 0, 10, mustEqualMatcher(model.MetricNameLabel, "foo"))
+	require.NoError(t, err)
+	require.Len(t, matrix, 10)
+}
+
 func TestRemoveReplicaLabel(t *testing.T) {
 	replicaLabel := "replica"
 	clusterLabel := "cluster"
@@ -730,7 +1282,7 @@ func TestRemoveReplicaLabel(t *testing.T) {
 	}
 
 	for _, c := range cases {
-		removeReplicaLabel(replicaLabel, &c.labelsIn)
+		removeLabel(replicaLabel, &c.labelsIn)
 		assert.Equal(t, c.labelsOut, c.labelsIn)
 	}
 }
@@ -0,0 +1,226 @@
+// Package graphite converts lines of the Graphite plaintext protocol into
+// Cortex series, turning the nodes of their dotted paths into metric names
+// and labels with templates.
+package graphite
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/pkg/labels"
+
+	"github.com/cortexproject/cortex/pkg/ingester/client"
+	"github.com/cortexproject/cortex/pkg/util"
+	"github.com/cortexproject/cortex/pkg/util/flagext"
+)
+
+// Config configures the Graphite listener.
+type Config struct {
+	ListenAddress string          `yaml:"listen_address,omitempty"`
+	TenantID      string          `yaml:"tenant_id,omitempty"`
+	Templates     flagext.Strings `yaml:"templates,omitempty"`
+	BatchSize     int             `yaml:"batch_size,omitempty"`
+	FlushPeriod   time.Duration   `yaml:"flush_period,omitempty"`
+}
+
+// RegisterFlags adds the flags required to config this to the given FlagSet
+func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
+	f.StringVar(&cfg.ListenAddress, "distributor.graphite.listen-address", "", "TCP address to accept Graphite plaintext protocol connections on, e.g. :2003. Empty disables the listener.")
+	f.StringVar(&cfg.TenantID, "distributor.graphite.tenant-id", "fake", "Tenant the samples received by the Graphite listener are pushed as.")
+	f.Var(&cfg.Templates, "distributor.graphite.template", "Template mapping the nodes of Graphite paths to the metric name and labels, as \"[filter] template [label=value,...]\", e.g. \"servers.* .host.measurement*\". Can be repeated; the first template whose filter matches a path is used.")
+	f.IntVar(&cfg.BatchSize, "distributor.graphite.batch-size", 1000, "Number of samples received by the Graphite listener pushed at once.")
+	f.DurationVar(&cfg.FlushPeriod, "distributor.graphite.flush-period", time.Second, "Period at which the samples received by the Graphite listener are pushed, if fewer than the batch size.")
+}
+
+// Validate the config.
+func (cfg *Config) Validate() error {
+	if cfg.FlushPeriod <= 0 {
+		return fmt.Errorf("invalid Graphite flush period %s, must be positive", cfg.FlushPeriod)
+	}
+	return nil
+}
+
+// The names of template parts which aren't labels.
+const (
+	measurementPart     = "measurement"
+	measurementRestPart = "measurement*"
+)
+
+// template maps the nodes of the paths matching its filter to the metric
+// name, when their part is measurement, or the label named by their part.
+type template struct {
+	filter []string
+	parts  []string
+	labels labels.Labels
+}
+
+func parseTemplate(s string) (template, error) {
+	fields := strings.Fields(s)
+	var t template
+	switch len(fields) {
+	case 1:
+		t.parts = strings.Split(fields[0], ".")
+	case 2:
+		if strings.Contains(fields[1], "=") {
+			t.parts = strings.Split(fields[0], ".")
+			t.labels = parseTemplateLabels(fields[1])
+		} else {
+			t.filter = strings.Split(fields[0], ".")
+			t.parts = strings.Split(fields[1], ".")
+		}
+	case 3:
+		t.filter = strings.Split(fields[0], ".")
+		t.parts = strings.Split(fields[1], ".")
+		t.labels = parseTemplateLabels(fields[2])
+	default:
+		return t, fmt.Errorf("invalid template %q", s)
+	}
+
+	for _, l := range t.labels {
+		if l.Name == "" {
+			return t, fmt.Errorf("invalid template %q: invalid label %q", s, l.Value)
+		}
+	}
+	for _, node := range t.filter {
+		if _, err := path.Match(node, ""); err != nil {
+			return t, fmt.Errorf("invalid template %q: invalid filter: %v", s, err)
+		}
+	}
+	hasMeasurement := false
+	for i, part := range t.parts {
+		if part == measurementRestPart && i != len(t.parts)-1 {
+			return t, fmt.Errorf("invalid template %q: %s must be the last part", s, measurementRestPart)
+		}
+		hasMeasurement = hasMeasurement || part == measurementPart || part == measurementRestPart
+	}
+	if !hasMeasurement {
+		return t, fmt.Errorf("invalid template %q: no measurement part", s)
+	}
+	return t, nil
+}
+
+// parseTemplateLabels parses the "name=value,..." labels of a template.  The
+// labels which can't be parsed have no name, and the whole label as value.
+func parseTemplateLabels(s string) labels.Labels {
+	var ls labels.Labels
+	for _, kv := range strings.Split(s, ",") {
+		i := strings.Index(kv, "=")
+		if i <= 0 {
+			ls = append(ls, labels.Label{Value: kv})
+			continue
+		}
+		ls = append(ls, labels.Label{Name: util.SanitizeLabelName(kv[:i]), Value: kv[i+1:]})
+	}
+	return ls
+}
+
+// parseTemplates parses the templates of the -distributor.graphite.template
+// flags.
+func parseTemplates(ss []string) ([]template, error) {
+	templates := make([]template, 0, len(ss))
+	for _, s := range ss {
+		t, err := parseTemplate(s)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, t)
+	}
+	return templates, nil
+}
+
+// matches returns whether the path's first nodes match the template's
+// filter, if it has one.
+func (t template) matches(nodes []string) bool {
+	if len(nodes) < len(t.filter) {
+		return false
+	}
+	for i, pattern := range t.filter {
+		if ok, _ := path.Match(pattern, nodes[i]); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// apply returns the metric name and labels of the path's nodes.
+func (t template) apply(nodes []string, b map[string]string) string {
+	var name []string
+	for i, part := range t.parts {
+		if i >= len(nodes) {
+			break
+		}
+		switch part {
+		case "":
+		case measurementPart:
+			name = append(name, nodes[i])
+		case measurementRestPart:
+			name = append(name, nodes[i:]...)
+		default:
+			b[util.SanitizeLabelName(part)] = nodes[i]
+		}
+	}
+	for _, l := range t.labels {
+		b[l.Name] = l.Value
+	}
+	return strings.Join(name, "_")
+}
+
+// parseLine parses a line of the plaintext protocol, "path value
+// [timestamp]", where the path can be followed by tags as in
+// "path;name=value;...".  The path's nodes are mapped with the first
+// template whose filter matches; without any the whole path is the metric
+// name.  Lines without a timestamp, or with -1, are at now.
+func parseLine(templates []template, line string, now time.Time) (labels.Labels, client.Sample, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 2 || len(fields) > 3 {
+		return nil, client.Sample{}, fmt.Errorf("expected a path, value and optional timestamp: %.200q", line)
+	}
+	v, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil {
+		return nil, client.Sample{}, fmt.Errorf("invalid value %.200q", fields[1])
+	}
+	timestampMs := now.UnixNano() / int64(time.Millisecond)
+	if len(fields) == 3 && fields[2] != "-1" {
+		ts, err := strconv.ParseFloat(fields[2], 64)
+		if err != nil || math.IsNaN(ts) || math.IsInf(ts, 0) {
+			return nil, client.Sample{}, fmt.Errorf("invalid timestamp %.200q", fields[2])
+		}
+		timestampMs = int64(ts * 1000)
+	}
+
+	tags := strings.Split(fields[0], ";")
+	nodes := strings.Split(tags[0], ".")
+	b := map[string]string{}
+	name := ""
+	for _, t := range templates {
+		if t.matches(nodes) {
+			name = t.apply(nodes, b)
+			break
+		}
+	}
+	if name == "" {
+		name = tags[0]
+	}
+	for _, tag := range tags[1:] {
+		i := strings.Index(tag, "=")
+		if i <= 0 {
+			return nil, client.Sample{}, fmt.Errorf("invalid tag %.200q", tag)
+		}
+		b[util.SanitizeLabelName(tag[:i])] = tag[i+1:]
+	}
+	b[model.MetricNameLabel] = util.SanitizeMetricName(name)
+
+	ls := make(labels.Labels, 0, len(b))
+	for name, value := range b {
+		if value != "" {
+			ls = append(ls, labels.Label{Name: name, Value: value})
+		}
+	}
+	return labels.New(ls...), client.Sample{TimestampMs: timestampMs, Value: v}, nil
+}
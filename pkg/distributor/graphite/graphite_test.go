@@ -0,0 +1,212 @@
+package graphite
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+
+	"github.com/cortexproject/cortex/pkg/ingester/client"
+	"github.com/cortexproject/cortex/pkg/util/test"
+)
+
+func TestParseLine(t *testing.T) {
+	templates, err := parseTemplates([]string{
+		"servers.* .host.measurement*",
+		"stats.*.*.latency .service.region.measurement.quantile env=prod",
+		"measurement.measurement.instance",
+	})
+	require.NoError(t, err)
+
+	now := time.Unix(100, 0)
+	for _, tc := range []struct {
+		line     string
+		labels   string
+		sample   client.Sample
+		expected string
+	}{
+		{
+			line:   "servers.web-1.cpu.load 1.5 1000",
+			labels: `cpu_load{host="web-1"}`,
+			sample: client.Sample{TimestampMs: 1000000, Value: 1.5},
+		},
+		{
+			line:   "stats.api.eu-west.latency.p99 0.2",
+			labels: `latency{env="prod", quantile="p99", region="eu-west", service="api"}`,
+			sample: client.Sample{TimestampMs: 100000, Value: 0.2},
+		},
+		{
+			line:   "disk.free.host-2 7 -1",
+			labels: `disk_free{instance="host-2"}`,
+			sample: client.Sample{TimestampMs: 100000, Value: 7},
+		},
+		{
+			// Without a matching template the whole path is the name.
+			line:   "a 1 1.5",
+			labels: `a`,
+			sample: client.Sample{TimestampMs: 1500, Value: 1},
+		},
+		{
+			line:   "servers.web-1.cpu;dc=east;2xx=yes 3 1000",
+			labels: `cpu{dc="east", host="web-1", key_2xx="yes"}`,
+			sample: client.Sample{TimestampMs: 1000000, Value: 3},
+		},
+		{line: "servers.web-1.cpu", expected: `expected a path, value and optional timestamp: "servers.web-1.cpu"`},
+		{line: "servers.web-1.cpu x", expected: `invalid value "x"`},
+		{line: "servers.web-1.cpu 1 x", expected: `invalid timestamp "x"`},
+		{line: "servers.web-1.cpu;dc 1", expected: `invalid tag "dc"`},
+	} {
+		t.Run(tc.line, func(t *testing.T) {
+			ls, sample, err := parseLine(templates, tc.line, now)
+			if tc.expected != "" {
+				assert.EqualError(t, err, tc.expected)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.labels, client.FromLabelAdaptersToMetric(client.FromLabelsToLabelAdapaters(ls)).String())
+			assert.Equal(t, tc.sample, sample)
+		})
+	}
+}
+
+func TestParseTemplates(t *testing.T) {
+	for _, tc := range []struct {
+		template string
+		expected string
+	}{
+		{"servers.* host.measurement* dc=east", ""},
+		{".host.measurement env=prod", ""},
+		{"host.region", `invalid template "host.region": no measurement part`},
+		{"measurement*.host", `invalid template "measurement*.host": measurement* must be the last part`},
+		{"a b c d", `invalid template "a b c d"`},
+		{"servers.* measurement env", `invalid template "servers.* measurement env": invalid label "env"`},
+		{"servers.[ measurement", `invalid template "servers.[ measurement": invalid filter: syntax error in pattern`},
+	} {
+		_, err := parseTemplates([]string{tc.template})
+		if tc.expected == "" {
+			assert.NoError(t, err, tc.template)
+		} else {
+			assert.EqualError(t, err, tc.expected)
+		}
+	}
+}
+
+func TestListener(t *testing.T) {
+	var (
+		mtx      sync.Mutex
+		received []string
+	)
+	push := func(ctx context.Context, req *client.WriteRequest) (*client.WriteResponse, error) {
+		userID, err := user.ExtractOrgID(ctx)
+		if err != nil {
+			return nil, err
+		}
+		mtx.Lock()
+		defer mtx.Unlock()
+		for _, ts := range req.Timeseries {
+			for _, s := range ts.Samples {
+				received = append(received, fmt.Sprintf("%s %s %g@%d", userID, client.FromLabelAdaptersToMetric(ts.Labels), s.Value, s.TimestampMs))
+			}
+		}
+		return &client.WriteResponse{}, nil
+	}
+
+	_, err := NewListener(Config{ListenAddress: "127.0.0.1:0", BatchSize: 2}, push)
+	require.EqualError(t, err, "invalid Graphite flush period 0s, must be positive")
+
+	l, err := NewListener(Config{
+		ListenAddress: "127.0.0.1:0",
+		TenantID:      "user",
+		Templates:     []string{"servers.* .host.measurement*"},
+		BatchSize:     2,
+		FlushPeriod:   time.Hour,
+	}, push)
+	require.NoError(t, err)
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("servers.a.cpu 1 10\nnope\n\nservers.b.cpu 2 10\nservers.c.cpu 3 10\n"))
+	require.NoError(t, err)
+
+	// The first two samples are pushed as a full batch.
+	test.Poll(t, time.Second, 2, func() interface{} {
+		mtx.Lock()
+		defer mtx.Unlock()
+		return len(received)
+	})
+
+	// The rest are pushed when stopping, once the connection is closed.
+	require.NoError(t, conn.Close())
+	test.Poll(t, time.Second, 0, func() interface{} {
+		l.mtx.Lock()
+		defer l.mtx.Unlock()
+		return len(l.conns)
+	})
+	l.Stop()
+	assert.Equal(t, []string{
+		`user cpu{host="a"} 1@10000`,
+		`user cpu{host="b"} 2@10000`,
+		`user cpu{host="c"} 3@10000`,
+	}, received)
+}
+
+func TestListenerPushesInOrder(t *testing.T) {
+	var (
+		mtx        sync.Mutex
+		pushing    bool
+		concurrent bool
+		received   []int64
+	)
+	push := func(ctx context.Context, req *client.WriteRequest) (*client.WriteResponse, error) {
+		mtx.Lock()
+		concurrent = concurrent || pushing
+		pushing = true
+		mtx.Unlock()
+
+		time.Sleep(time.Millisecond)
+
+		mtx.Lock()
+		defer mtx.Unlock()
+		pushing = false
+		for _, ts := range req.Timeseries {
+			for _, s := range ts.Samples {
+				received = append(received, s.TimestampMs)
+			}
+		}
+		return &client.WriteResponse{}, nil
+	}
+
+	l, err := NewListener(Config{
+		ListenAddress: "127.0.0.1:0",
+		TenantID:      "user",
+		BatchSize:     3,
+		FlushPeriod:   time.Millisecond,
+	}, push)
+	require.NoError(t, err)
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	require.NoError(t, err)
+	for i := 1; i <= 100; i++ {
+		_, err = fmt.Fprintf(conn, "cpu %d %d\n", i, i)
+		require.NoError(t, err)
+	}
+	require.NoError(t, conn.Close())
+	test.Poll(t, time.Second, 0, func() interface{} {
+		l.mtx.Lock()
+		defer l.mtx.Unlock()
+		return len(l.conns)
+	})
+	l.Stop()
+
+	require.False(t, concurrent)
+	require.Len(t, received, 100)
+	for i, ts := range received {
+		require.Equal(t, int64(i+1)*1000, ts)
+	}
+}
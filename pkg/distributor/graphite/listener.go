@@ -0,0 +1,251 @@
+package graphite
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/weaveworks/common/user"
+
+	"github.com/cortexproject/cortex/pkg/ingester/client"
+	"github.com/cortexproject/cortex/pkg/util"
+)
+
+var (
+	receivedLines = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "cortex",
+		Name:      "distributor_graphite_received_lines_total",
+		Help:      "The total number of lines received by the Graphite listener.",
+	})
+	invalidLines = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "cortex",
+		Name:      "distributor_graphite_invalid_lines_total",
+		Help:      "The total number of lines received by the Graphite listener which couldn't be parsed.",
+	})
+	pushFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "cortex",
+		Name:      "distributor_graphite_push_failures_total",
+		Help:      "The total number of batches of samples received by the Graphite listener which couldn't be pushed.",
+	})
+)
+
+// maxLineLength is the maximum length of a line.
+const maxLineLength = 64 * 1024
+
+// PushFunc pushes the samples received by the listener.
+type PushFunc func(context.Context, *client.WriteRequest) (*client.WriteResponse, error)
+
+// Listener accepts connections speaking the Graphite plaintext protocol,
+// and pushes the samples of their lines in batches.  The batches are only
+// pushed by the flush loop, one at a time, so that the samples of a series
+// reach the ingesters in the order they were received.
+type Listener struct {
+	cfg       Config
+	templates []template
+	push      PushFunc
+	listener  net.Listener
+
+	mtx   sync.Mutex
+	batch []client.PreallocTimeseries
+	conns map[net.Conn]struct{}
+
+	full chan struct{}
+
+	quit     chan struct{}
+	wg       sync.WaitGroup
+	flushed  chan struct{}
+	loopDone chan struct{}
+}
+
+// NewListener makes a new Listener, listening on the configured address.
+func NewListener(cfg Config, push PushFunc) (*Listener, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	templates, err := parseTemplates(cfg.Templates)
+	if err != nil {
+		return nil, err
+	}
+	listener, err := net.Listen("tcp", cfg.ListenAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &Listener{
+		cfg:       cfg,
+		templates: templates,
+		push:      push,
+		listener:  listener,
+		conns:     map[net.Conn]struct{}{},
+		full:      make(chan struct{}, 1),
+		quit:      make(chan struct{}),
+		flushed:   make(chan struct{}),
+		loopDone:  make(chan struct{}),
+	}
+	l.wg.Add(1)
+	go l.accept()
+	go l.loop()
+	return l, nil
+}
+
+// Addr returns the address the listener listens on.
+func (l *Listener) Addr() net.Addr {
+	return l.listener.Addr()
+}
+
+// Stop closes the listener and its connections, and pushes the samples
+// received so far.
+func (l *Listener) Stop() {
+	close(l.quit)
+	l.listener.Close()
+	l.mtx.Lock()
+	for conn := range l.conns {
+		conn.Close()
+	}
+	l.mtx.Unlock()
+	l.wg.Wait()
+	close(l.flushed)
+	<-l.loopDone
+}
+
+func (l *Listener) accept() {
+	defer l.wg.Done()
+	for {
+		conn, err := l.listener.Accept()
+		if err != nil {
+			select {
+			case <-l.quit:
+				return
+			default:
+			}
+			level.Warn(util.Logger).Log("msg", "error accepting Graphite connection", "err", err)
+			if ne, ok := err.(net.Error); ok && ne.Temporary() {
+				continue
+			}
+			return
+		}
+
+		// Stop closes the connections under the lock after closing quit, so
+		// one accepted meanwhile must be closed here.
+		l.mtx.Lock()
+		select {
+		case <-l.quit:
+			l.mtx.Unlock()
+			conn.Close()
+			return
+		default:
+		}
+		l.conns[conn] = struct{}{}
+		l.wg.Add(1)
+		l.mtx.Unlock()
+		go l.handle(conn)
+	}
+}
+
+func (l *Listener) handle(conn net.Conn) {
+	defer l.wg.Done()
+	defer func() {
+		conn.Close()
+		l.mtx.Lock()
+		delete(l.conns, conn)
+		l.mtx.Unlock()
+	}()
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 4096), maxLineLength)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		receivedLines.Inc()
+		ls, sample, err := parseLine(l.templates, line, time.Now())
+		if err != nil {
+			invalidLines.Inc()
+			level.Debug(util.Logger).Log("msg", "invalid Graphite line", "remote", conn.RemoteAddr(), "err", err)
+			continue
+		}
+		l.add(client.PreallocTimeseries{
+			TimeSeries: client.TimeSeries{
+				Labels:  client.FromLabelsToLabelAdapaters(ls),
+				Samples: []client.Sample{sample},
+			},
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		select {
+		case <-l.quit:
+		default:
+			level.Warn(util.Logger).Log("msg", "error reading Graphite connection", "remote", conn.RemoteAddr(), "err", err)
+		}
+	}
+}
+
+// add adds the series to the batch, waking the flush loop once it is full.
+func (l *Listener) add(ts client.PreallocTimeseries) {
+	l.mtx.Lock()
+	l.batch = append(l.batch, ts)
+	full := len(l.batch) >= l.cfg.BatchSize
+	l.mtx.Unlock()
+	if full {
+		select {
+		case l.full <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// loop flushes the batch every flush period, and as soon as it is full,
+// until the listener is stopped and its connections have been handled.
+func (l *Listener) loop() {
+	defer close(l.loopDone)
+	ticker := time.NewTicker(l.cfg.FlushPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			l.flush(false)
+		case <-l.full:
+			l.flush(true)
+		case <-l.flushed:
+			l.flush(false)
+			return
+		}
+	}
+}
+
+// flush pushes the samples received so far, as the configured tenant, in
+// batches of up to the batch size: more can have been received while the
+// last batch was pushed.  With onlyFull, the samples which don't make up a
+// full batch are left for the next flush.
+func (l *Listener) flush(onlyFull bool) {
+	l.mtx.Lock()
+	samples := l.batch
+	l.batch = nil
+	if onlyFull {
+		n := len(samples) - len(samples)%l.cfg.BatchSize
+		samples, l.batch = samples[:n], append(l.batch, samples[n:]...)
+	}
+	l.mtx.Unlock()
+
+	ctx := user.InjectOrgID(context.Background(), l.cfg.TenantID)
+	for len(samples) > 0 {
+		batch := samples
+		if len(batch) > l.cfg.BatchSize {
+			batch = batch[:l.cfg.BatchSize]
+		}
+		samples = samples[len(batch):]
+
+		if _, err := l.push(ctx, &client.WriteRequest{Timeseries: batch, Source: client.API}); err != nil {
+			pushFailures.Inc()
+			level.Warn(util.Logger).Log("msg", "error pushing Graphite samples", "samples", len(batch), "err", err)
+		}
+	}
+}
@@ -12,6 +12,8 @@ import (
 
 	"github.com/go-kit/kit/log"
 	"github.com/golang/protobuf/proto"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/prometheus/prometheus/pkg/timestamp"
 	"github.com/weaveworks/common/mtime"
 
@@ -34,6 +36,12 @@ const (
 	longPollDuration = 10 * time.Second
 )
 
+var electedReplicaChanges = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "cortex",
+	Name:      "ha_tracker_elected_replica_changes_total",
+	Help:      "The total number of times the elected replica of an HA cluster changed, failing over to another replica.",
+}, []string{"user", "cluster"})
+
 // Track the replica we're accepting samples from
 // for each HA cluster we know about.
 type haTracker struct {
@@ -141,11 +149,14 @@ func (c *haTracker) checkReplica(ctx context.Context, userID, cluster, replica s
 	if ok && entry.Replica == replica && now.Sub(timestamp.Time(entry.ReceivedAt)) < c.cfg.UpdateTimeout {
 		return nil
 	}
-	return c.checkKVStore(ctx, key, replica, now)
+	return c.checkKVStore(ctx, userID, cluster, replica, now)
 }
 
-func (c *haTracker) checkKVStore(ctx context.Context, key, replica string, now time.Time) error {
-	return c.client.CAS(ctx, key, func(in interface{}) (out interface{}, retry bool, err error) {
+func (c *haTracker) checkKVStore(ctx context.Context, userID, cluster, replica string, now time.Time) error {
+	key := fmt.Sprintf("%s/%s", userID, cluster)
+	failover := false
+	err := c.client.CAS(ctx, key, func(in interface{}) (out interface{}, retry bool, err error) {
+		failover = false
 		if desc, ok := in.(*ReplicaDesc); ok {
 
 			// We don't need to CAS and update the timestamp in the KV store if the timestamp we've received
@@ -160,6 +171,7 @@ func (c *haTracker) checkKVStore(ctx context.Context, key, replica string, now t
 				// Return a 202.
 				return nil, false, httpgrpc.Errorf(http.StatusAccepted, "replicas did not match, rejecting sample: %s != %s", replica, desc.Replica)
 			}
+			failover = desc.Replica != replica
 		}
 
 		// There was either invalid or no data for the key, so we now accept samples
@@ -169,6 +181,10 @@ func (c *haTracker) checkKVStore(ctx context.Context, key, replica string, now t
 			Replica: replica, ReceivedAt: timestamp.FromTime(now),
 		}, true, nil
 	})
+	if err == nil && failover {
+		electedReplicaChanges.WithLabelValues(userID, cluster).Inc()
+	}
+	return err
 }
 
 // Modifies the labels parameter in place, removing labels that match
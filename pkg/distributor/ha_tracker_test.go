@@ -6,8 +6,10 @@ import (
 	"testing"
 	"time"
 
+	dto "github.com/prometheus/client_model/go"
 	"github.com/prometheus/prometheus/pkg/timestamp"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/weaveworks/common/mtime"
 	"github.com/weaveworks/common/user"
 
@@ -146,14 +148,22 @@ func TestCheckReplicaOverwriteTimeout(t *testing.T) {
 	mtime.NowForce(start.Add(1100 * time.Millisecond))
 
 	// Accept from replica 2, this should overwrite the saved replica of replica 1.
+	changes := electedReplicaChangesValue(t, "user", "test")
 	err = c.checkReplica(context.Background(), "user", "test", replica2)
 	assert.NoError(t, err)
+	assert.Equal(t, changes+1, electedReplicaChangesValue(t, "user", "test"))
 
 	// We timed out accepting samples from replica 1 and should now reject them.
 	err = c.checkReplica(context.Background(), "user", "test", replica1)
 	assert.Error(t, err)
 }
 
+func electedReplicaChangesValue(t *testing.T, userID, cluster string) float64 {
+	var m dto.Metric
+	require.NoError(t, electedReplicaChanges.WithLabelValues(userID, cluster).Write(&m))
+	return m.GetCounter().GetValue()
+}
+
 func TestCheckReplicaMultiCluster(t *testing.T) {
 	replica1 := "replica1"
 	replica2 := "replica2"
@@ -1,20 +1,50 @@
 package distributor
 
 import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
 	"fmt"
+	"io"
+	"mime"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 	"github.com/prometheus/prometheus/promql"
 
+	"github.com/cortexproject/cortex/pkg/distributor/datadog"
+	"github.com/cortexproject/cortex/pkg/distributor/influx"
+	"github.com/cortexproject/cortex/pkg/distributor/otlp"
+	"github.com/cortexproject/cortex/pkg/distributor/writev2"
 	"github.com/cortexproject/cortex/pkg/ingester/client"
 	"github.com/cortexproject/cortex/pkg/util"
 	"github.com/weaveworks/common/httpgrpc"
+	"github.com/weaveworks/common/user"
 )
 
-// PushHandler is a http.Handler which accepts WriteRequests.
+// The proto parameter of the Content-Type of remote write 1.0 requests,
+// which may also have none.
+const remoteWrite1Proto = "prometheus.WriteRequest"
+
+// PushHandler is a http.Handler which accepts WriteRequests, or remote write
+// 2.0 Requests if their Content-Type says so.
 func (d *Distributor) PushHandler(w http.ResponseWriter, r *http.Request) {
 	compressionType := util.CompressionTypeFor(r.Header.Get("X-Prometheus-Remote-Write-Version"))
+	switch proto := remoteWriteProto(r.Header.Get("Content-Type")); proto {
+	case "", remoteWrite1Proto:
+	case writev2.RequestProto:
+		d.pushV2(w, r, compressionType)
+		return
+	default:
+		http.Error(w, fmt.Sprintf("unsupported remote write protobuf message %q, only %s and %s are accepted", proto, remoteWrite1Proto, writev2.RequestProto), http.StatusUnsupportedMediaType)
+		return
+	}
+
 	var req client.PreallocWriteRequest
 	req.Source = client.API
 	buf, err := util.ParseProtoReader(r.Context(), r.Body, &req, compressionType)
@@ -35,23 +65,326 @@ func (d *Distributor) PushHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	if _, err := d.Push(r.Context(), &req.WriteRequest); err != nil {
-		resp, ok := httpgrpc.HTTPResponseFromError(err)
-		if !ok {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+	// The request's slices came from the pools, and are returned once sent.
+	if _, err := d.push(r.Context(), &req.WriteRequest, func() { client.ReuseSlice(req.Timeseries) }); err != nil {
+		writePushError(w, logger, err)
+	}
+}
+
+// remoteWriteProto returns the proto parameter of a remote write request's
+// Content-Type, empty if it has none.
+func remoteWriteProto(contentType string) string {
+	if contentType == "" {
+		return ""
+	}
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	return params["proto"]
+}
+
+// pushV2 pushes a remote write 2.0 Request, answering with the numbers of
+// samples, histograms and exemplars written, as the protocol requires.
+func (d *Distributor) pushV2(w http.ResponseWriter, r *http.Request, compressionType util.CompressionType) {
+	logger := util.WithContext(r.Context(), util.Logger)
+	var reqV2 writev2.Request
+	buf, err := util.ParseProtoReader(r.Context(), r.Body, &reqV2, compressionType)
+	if err != nil {
+		level.Error(logger).Log("err", err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req, err := writev2.ToWriteRequest(&reqV2)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var samples, histograms, exemplars int
+	for _, ts := range req.Timeseries {
+		samples += len(ts.Samples)
+		histograms += len(ts.Histograms)
+		exemplars += len(ts.Exemplars)
+	}
+	if d.cfg.EnableBilling {
+		if err := d.emitBillingRecord(r.Context(), buf, int64(samples)); err != nil {
+			level.Error(logger).Log("msg", "error emitting billing record", "err", err)
+		}
+	}
+
+	if _, err := d.Push(r.Context(), req); err != nil {
+		writePushError(w, logger, err)
+		return
+	}
+	w.Header().Set("X-Prometheus-Remote-Write-Samples-Written", strconv.Itoa(samples))
+	w.Header().Set("X-Prometheus-Remote-Write-Histograms-Written", strconv.Itoa(histograms))
+	w.Header().Set("X-Prometheus-Remote-Write-Exemplars-Written", strconv.Itoa(exemplars))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// OTLPHandler is a http.Handler which accepts OpenTelemetry protocol metrics,
+// as protobuf ExportMetricsServiceRequests, optionally gzipped.
+func (d *Distributor) OTLPHandler(w http.ResponseWriter, r *http.Request) {
+	logger := util.WithContext(r.Context(), util.Logger)
+	userID, err := user.ExtractOrgID(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if contentType := r.Header.Get("Content-Type"); !strings.HasPrefix(contentType, "application/x-protobuf") {
+		http.Error(w, fmt.Sprintf("unsupported content type %q, only application/x-protobuf is accepted", contentType), http.StatusUnsupportedMediaType)
+		return
+	}
+
+	reader, err := decompressedBody(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer reader.Close()
+	var req otlp.ExportMetricsServiceRequest
+	buf, err := util.ParseProtoReader(r.Context(), reader, &req, util.NoCompression)
+	if err != nil {
+		level.Error(logger).Log("err", err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// The points which can be converted are pushed even if others can't.
+	timeseries, convertErr := otlp.ToTimeseries(userID, &req, d.otlpDeltas)
+	if len(timeseries) > 0 {
+		if d.cfg.EnableBilling {
+			var samples int64
+			for _, ts := range timeseries {
+				samples += int64(len(ts.Samples))
+			}
+			if err := d.emitBillingRecord(r.Context(), buf, samples); err != nil {
+				level.Error(logger).Log("msg", "error emitting billing record", "err", err)
+			}
+		}
+
+		if _, err := d.Push(r.Context(), &client.WriteRequest{Timeseries: timeseries, Source: client.API}); err != nil {
+			writePushError(w, logger, err)
+			return
+		}
+	}
+	if convertErr != nil {
+		http.Error(w, convertErr.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	if err := util.SerializeProtoResponse(w, &otlp.ExportMetricsServiceResponse{}, util.NoCompression); err != nil {
+		level.Error(logger).Log("err", err.Error())
+	}
+}
+
+// InfluxHandler is a http.Handler which accepts points in the InfluxDB line
+// protocol, optionally gzipped, with the precision of their timestamps in the
+// precision parameter.
+func (d *Distributor) InfluxHandler(w http.ResponseWriter, r *http.Request) {
+	logger := util.WithContext(r.Context(), util.Logger)
+	reader, err := decompressedBody(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer reader.Close()
+	var (
+		body bytes.Buffer
+		in   io.Reader = reader
+	)
+	if d.cfg.EnableBilling {
+		in = io.TeeReader(reader, &body)
+	}
+
+	// The lines which can be parsed are pushed even if others can't.  The
+	// precision is only read from the URL: clients like curl send the lines
+	// form-encoded, which parsing the form would consume.
+	timeseries, parseErr := influx.Parse(in, r.URL.Query().Get("precision"), time.Now())
+	if len(timeseries) > 0 {
+		if d.cfg.EnableBilling {
+			var samples int64
+			for _, ts := range timeseries {
+				samples += int64(len(ts.Samples))
+			}
+			if err := d.emitBillingRecord(r.Context(), body.Bytes(), samples); err != nil {
+				level.Error(logger).Log("msg", "error emitting billing record", "err", err)
+			}
+		}
+
+		if _, err := d.Push(r.Context(), &client.WriteRequest{Timeseries: timeseries, Source: client.API}); err != nil {
+			writePushError(w, logger, err)
+			return
+		}
+	}
+	if parseErr != nil {
+		http.Error(w, parseErr.Error(), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DatadogSeriesV1Handler is a http.Handler which accepts the JSON series
+// payloads of the Datadog agent's series v1 API, optionally gzipped or
+// deflated.
+func (d *Distributor) DatadogSeriesV1Handler(w http.ResponseWriter, r *http.Request) {
+	reader, err := decompressedBody(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer reader.Close()
+	var (
+		body bytes.Buffer
+		in   io.Reader = reader
+	)
+	if d.cfg.EnableBilling {
+		in = io.TeeReader(reader, &body)
+	}
+	var req datadog.SeriesV1
+	if err := json.NewDecoder(in).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	timeseries, convertErr := datadog.FromV1(&req)
+	d.pushDatadog(w, r, body.Bytes(), timeseries, convertErr, map[string]string{"status": "ok"})
+}
+
+// DatadogSeriesV2Handler is a http.Handler which accepts the series payloads
+// of the Datadog agent's series v2 API, as protobuf MetricPayloads or their
+// JSON, optionally gzipped or deflated.
+func (d *Distributor) DatadogSeriesV2Handler(w http.ResponseWriter, r *http.Request) {
+	logger := util.WithContext(r.Context(), util.Logger)
+	reader, err := decompressedBody(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer reader.Close()
+	var (
+		body bytes.Buffer
+		in   io.Reader = reader
+	)
+	if d.cfg.EnableBilling {
+		in = io.TeeReader(reader, &body)
+	}
+	var req datadog.MetricPayload
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		err = json.NewDecoder(in).Decode(&req)
+	} else {
+		_, err = util.ParseProtoReader(r.Context(), in, &req, util.NoCompression)
+	}
+	if err != nil {
+		level.Error(logger).Log("err", err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	timeseries, convertErr := datadog.FromV2(&req)
+	d.pushDatadog(w, r, body.Bytes(), timeseries, convertErr, map[string][]string{"errors": {}})
+}
+
+// DatadogValidateHandler answers the Datadog agent's checks of its API key,
+// which is not used, as valid.
+func DatadogValidateHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]bool{"valid": true})
+}
+
+// pushDatadog pushes the series converted from a Datadog payload, even if
+// others couldn't be, and answers with a 202 and the response body, as does
+// Datadog.  The payload is only kept, for its billing record, with billing
+// enabled.
+func (d *Distributor) pushDatadog(w http.ResponseWriter, r *http.Request, payload []byte, timeseries []client.PreallocTimeseries, convertErr error, response interface{}) {
+	logger := util.WithContext(r.Context(), util.Logger)
+	if len(timeseries) > 0 {
+		if d.cfg.EnableBilling {
+			var samples int64
+			for _, ts := range timeseries {
+				samples += int64(len(ts.Samples))
+			}
+			if err := d.emitBillingRecord(r.Context(), payload, samples); err != nil {
+				level.Error(logger).Log("msg", "error emitting billing record", "err", err)
+			}
+		}
+
+		if _, err := d.Push(r.Context(), &client.WriteRequest{Timeseries: timeseries, Source: client.API}); err != nil {
+			writePushError(w, logger, err)
 			return
 		}
-		if resp.GetCode() != 202 {
-			level.Error(logger).Log("msg", "push error", "err", err)
+	}
+	if convertErr != nil {
+		http.Error(w, convertErr.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		level.Error(logger).Log("err", err.Error())
+	}
+}
+
+// decompressedBody returns the request's body, gunzipped if its
+// Content-Encoding is gzip or inflated if it is deflate.
+func decompressedBody(r *http.Request) (io.ReadCloser, error) {
+	switch r.Header.Get("Content-Encoding") {
+	case "gzip":
+		return gzip.NewReader(r.Body)
+	case "deflate":
+		return zlib.NewReader(r.Body)
+	}
+	return r.Body, nil
+}
+
+// writePushError writes the error of a push as the HTTP response.
+func writePushError(w http.ResponseWriter, logger log.Logger, err error) {
+	resp, ok := httpgrpc.HTTPResponseFromError(err)
+	if !ok {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if resp.GetCode() != 202 {
+		level.Error(logger).Log("msg", "push error", "err", err)
+	}
+
+	// List all the samples the ingesters rejected, not just the last.
+	body := string(resp.Body)
+	if errs, err := client.PushErrorsFromHTTPResponse(resp); err != nil {
+		level.Warn(logger).Log("msg", "error decoding push errors", "err", err)
+	} else if errs != nil {
+		for _, h := range resp.Headers {
+			if h.Key == client.PushErrorsHeader {
+				w.Header()[h.Key] = h.Values
+			}
+		}
+		if errs.RejectedSamples > 1 {
+			body = pushErrorsBody(errs)
 		}
-		http.Error(w, string(resp.Body), int(resp.Code))
 	}
+	http.Error(w, body, int(resp.Code))
+}
+
+// pushErrorsBody lists the rejected samples' errors, one per line.
+func pushErrorsBody(errs *client.PushErrors) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d samples rejected:", errs.RejectedSamples)
+	for _, e := range errs.Errors {
+		fmt.Fprintf(&b, "\n%s", e.Message)
+	}
+	if n := errs.RejectedSamples - int64(len(errs.Errors)); n > 0 {
+		fmt.Fprintf(&b, "\nand %d more", n)
+	}
+	return b.String()
 }
 
 // UserStats models ingestion statistics for one user.
 type UserStats struct {
 	IngestionRate     float64 `json:"ingestionRate"`
 	NumSeries         uint64  `json:"numSeries"`
+	ActiveSeries      uint64  `json:"activeSeries"`
 	APIIngestionRate  float64 `json:"APIIngestionRate"`
 	RuleIngestionRate float64 `json:"RuleIngestionRate"`
 }
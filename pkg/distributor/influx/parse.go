@@ -0,0 +1,231 @@
+// Package influx converts InfluxDB line protocol into Cortex series.
+package influx
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/pkg/labels"
+
+	"github.com/cortexproject/cortex/pkg/ingester/client"
+	"github.com/cortexproject/cortex/pkg/util"
+)
+
+// valueField is the name of the field which becomes the series of the
+// measurement itself, rather than of measurement_field.
+const valueField = "value"
+
+// maxLineLength is the maximum length of a line.
+const maxLineLength = 1024 * 1024
+
+// Parse converts the lines of points read into series, one sample per
+// numeric field of each point.  A measurement's fields become the series
+// named measurement_field, or measurement for the value field, and the
+// point's tags their labels.  String fields are skipped.  Timestamps are in
+// precision, nanoseconds if empty, and points without one are at now.  The
+// series of the lines which can be parsed are returned along with an error
+// for the last line which couldn't.
+func Parse(r io.Reader, precision string, now time.Time) ([]client.PreallocTimeseries, error) {
+	unit, err := precisionUnit(precision)
+	if err != nil {
+		return nil, err
+	}
+
+	p := parser{unit: unit, nowMs: now.UnixNano() / int64(time.Millisecond), index: map[string]int{}}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineLength)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		if err := p.parseLine(line); err != nil {
+			p.err = fmt.Errorf("line %d: %v", n, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return p.series, err
+	}
+	return p.series, p.err
+}
+
+// precisionUnit returns the duration of the precision's unit.
+func precisionUnit(precision string) (time.Duration, error) {
+	switch precision {
+	case "", "n", "ns":
+		return time.Nanosecond, nil
+	case "u", "us":
+		return time.Microsecond, nil
+	case "ms":
+		return time.Millisecond, nil
+	case "s":
+		return time.Second, nil
+	}
+	return 0, fmt.Errorf("invalid precision %q", precision)
+}
+
+type parser struct {
+	unit  time.Duration
+	nowMs int64
+
+	series []client.PreallocTimeseries
+	// The index in series of each series' labels.
+	index map[string]int
+	err   error
+}
+
+func (p *parser) parseLine(line string) error {
+	// Double quotes are only special in field values, which follow the
+	// measurement and tags.
+	keySection := split(line, ' ', false)[0]
+	var sections []string
+	if len(keySection) < len(line) {
+		sections = split(line[len(keySection)+1:], ' ', true)
+	}
+	if len(sections) < 1 || len(sections) > 2 {
+		return fmt.Errorf("expected a measurement, fields and an optional timestamp: %.200q", line)
+	}
+
+	timestampMs := p.nowMs
+	if len(sections) == 2 {
+		ts, err := strconv.ParseInt(sections[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid timestamp %.200q", sections[1])
+		}
+		timestampMs = ts * int64(p.unit) / int64(time.Millisecond)
+	}
+
+	key := split(keySection, ',', false)
+	measurement := unescape(key[0])
+	if measurement == "" {
+		return fmt.Errorf("missing measurement: %.200q", line)
+	}
+	tags := make(labels.Labels, 0, len(key))
+	for _, tag := range key[1:] {
+		kv := split(tag, '=', false)
+		if len(kv) != 2 || kv[0] == "" {
+			return fmt.Errorf("invalid tag %.200q", tag)
+		}
+		tags = append(tags, labels.Label{Name: util.SanitizeLabelName(unescape(kv[0])), Value: unescape(kv[1])})
+	}
+
+	type sample struct {
+		name  string
+		value float64
+	}
+	var samples []sample
+	for _, field := range split(sections[0], ',', true) {
+		kv := split(field, '=', true)
+		if len(kv) != 2 || kv[0] == "" || kv[1] == "" {
+			return fmt.Errorf("invalid field %.200q", field)
+		}
+		if kv[1][0] == '"' {
+			continue
+		}
+		v, err := parseFieldValue(kv[1])
+		if err != nil {
+			return fmt.Errorf("invalid value of field %.200q: %v", field, err)
+		}
+		name := measurement
+		if fieldName := unescape(kv[0]); fieldName != valueField {
+			name += "_" + fieldName
+		}
+		samples = append(samples, sample{util.SanitizeMetricName(name), v})
+	}
+
+	// The whole line is parsed before adding its samples, so that invalid
+	// lines don't add any.
+	for _, s := range samples {
+		p.addSample(tags, s.name, timestampMs, s.value)
+	}
+	return nil
+}
+
+func (p *parser) addSample(tags labels.Labels, name string, timestampMs int64, v float64) {
+	ls := make(labels.Labels, 0, len(tags)+1)
+	ls = append(ls, tags...)
+	ls = append(ls, labels.Label{Name: model.MetricNameLabel, Value: name})
+	sort.Stable(ls)
+	// Of tags with the same name once sanitized, the last one wins.
+	for i := len(ls) - 1; i > 0; i-- {
+		if ls[i].Name == ls[i-1].Name {
+			ls = append(ls[:i-1], ls[i:]...)
+		}
+	}
+
+	sample := client.Sample{TimestampMs: timestampMs, Value: v}
+	key := ls.String()
+	if i, ok := p.index[key]; ok {
+		p.series[i].Samples = append(p.series[i].Samples, sample)
+		return
+	}
+	p.index[key] = len(p.series)
+	p.series = append(p.series, client.PreallocTimeseries{
+		TimeSeries: client.TimeSeries{
+			Labels:  client.FromLabelsToLabelAdapaters(ls),
+			Samples: []client.Sample{sample},
+		},
+	})
+}
+
+// parseFieldValue parses a field's float, integer, unsigned integer or
+// boolean value.
+func parseFieldValue(s string) (float64, error) {
+	switch s {
+	case "t", "T", "true", "True", "TRUE":
+		return 1, nil
+	case "f", "F", "false", "False", "FALSE":
+		return 0, nil
+	}
+	switch s[len(s)-1] {
+	case 'i':
+		i, err := strconv.ParseInt(s[:len(s)-1], 10, 64)
+		return float64(i), err
+	case 'u':
+		u, err := strconv.ParseUint(s[:len(s)-1], 10, 64)
+		return float64(u), err
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+// split splits s on the separators which aren't escaped with a backslash,
+// nor, if quoted is set, in a double quoted string.
+func split(s string, sep byte, quoted bool) []string {
+	var parts []string
+	inQuotes := false
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\':
+			i++
+		case s[i] == '"' && quoted:
+			inQuotes = !inQuotes
+		case s[i] == sep && !inQuotes:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// unescape removes the backslashes escaping commas, equal signs, spaces,
+// double quotes and backslashes.
+func unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && strings.IndexByte(`, ="\`, s[i+1]) >= 0 {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,96 @@
+package influx
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cortexproject/cortex/pkg/ingester/client"
+)
+
+type series struct {
+	labels  string
+	samples []client.Sample
+}
+
+func toSeries(timeseries []client.PreallocTimeseries) []series {
+	result := make([]series, 0, len(timeseries))
+	for _, ts := range timeseries {
+		result = append(result, series{client.FromLabelAdaptersToMetric(ts.Labels).String(), ts.Samples})
+	}
+	return result
+}
+
+func TestParse(t *testing.T) {
+	now := time.Unix(100, 0)
+	for _, tc := range []struct {
+		name      string
+		lines     string
+		precision string
+		expected  []series
+		err       string
+	}{
+		{
+			name:  "fields and tags",
+			lines: `cpu,host=a,region=eu-west usage_user=1.5,usage_system=2i,online=true 1000000000`,
+			expected: []series{
+				{`cpu_usage_user{host="a", region="eu-west"}`, []client.Sample{{TimestampMs: 1000, Value: 1.5}}},
+				{`cpu_usage_system{host="a", region="eu-west"}`, []client.Sample{{TimestampMs: 1000, Value: 2}}},
+				{`cpu_online{host="a", region="eu-west"}`, []client.Sample{{TimestampMs: 1000, Value: 1}}},
+			},
+		},
+		{
+			name:  "value field, no timestamp, comments and blank lines",
+			lines: "# temperatures\n\ntemperature,room=kitchen value=21u\n",
+			expected: []series{
+				{`temperature{room="kitchen"}`, []client.Sample{{TimestampMs: 100000, Value: 21}}},
+			},
+		},
+		{
+			name:  "samples of the same series",
+			lines: "mem free=1 1000000000\nmem free=2 2000000000",
+			expected: []series{
+				{`mem_free`, []client.Sample{{TimestampMs: 1000, Value: 1}, {TimestampMs: 2000, Value: 2}}},
+			},
+		},
+		{
+			name:      "precision",
+			lines:     `mem free=1 5`,
+			precision: "s",
+			expected: []series{
+				{`mem_free`, []client.Sample{{TimestampMs: 5000, Value: 1}}},
+			},
+		},
+		{
+			name:  "escapes, quotes and sanitized names",
+			lines: `disk\ io,mount\ point=/var\,log,dev.name="sda" msg="a, b=c d",read.bytes=3 1000000000`,
+			expected: []series{
+				{`disk_io_read_bytes{dev_name="\"sda\"", mount_point="/var,log"}`, []client.Sample{{TimestampMs: 1000, Value: 3}}},
+			},
+		},
+		{
+			name:  "invalid lines are skipped",
+			lines: "mem\nmem free=x\nmem free=1 1000000000\nmem free=1.5u\nmem free=1 nope",
+			expected: []series{
+				{`mem_free`, []client.Sample{{TimestampMs: 1000, Value: 1}}},
+			},
+			err: `line 5: invalid timestamp "nope"`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			timeseries, err := Parse(strings.NewReader(tc.lines), tc.precision, now)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tc.expected, toSeries(timeseries))
+		})
+	}
+
+	_, err := Parse(strings.NewReader(""), "h", now)
+	assert.EqualError(t, err, `invalid precision "h"`)
+}
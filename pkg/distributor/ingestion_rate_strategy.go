@@ -0,0 +1,79 @@
+package distributor
+
+import (
+	"context"
+	"fmt"
+
+	"golang.org/x/time/rate"
+
+	"github.com/cortexproject/cortex/pkg/ring"
+)
+
+// Values for -distributor.ingestion-rate-limit-strategy.
+const (
+	// LocalIngestionRateStrategy enforces the ingestion rate limit on each
+	// distributor, so that a user's effective limit grows with the number
+	// of distributors.
+	LocalIngestionRateStrategy = "local"
+
+	// GlobalIngestionRateStrategy shares the ingestion rate limit between
+	// the healthy distributors of the distributors' ring.
+	GlobalIngestionRateStrategy = "global"
+
+	// InfiniteIngestionRateStrategy doesn't enforce any ingestion rate limit.
+	InfiniteIngestionRateStrategy = "infinite"
+)
+
+func validateIngestionRateStrategy(strategy string) error {
+	switch strategy {
+	case LocalIngestionRateStrategy, GlobalIngestionRateStrategy, InfiniteIngestionRateStrategy:
+		return nil
+	}
+	return fmt.Errorf("invalid ingestion rate limit strategy %q", strategy)
+}
+
+// distributorsRingKey is the key the distributors' ring is stored under, so
+// that it is kept apart from the ingesters' ring even in the same KV store.
+const distributorsRingKey = "distributor"
+
+// newDistributorsRing joins the distributors' ring, which the global
+// strategy counts the healthy distributors in.
+func newDistributorsRing(cfg ring.LifecyclerConfig, flushTransferer ring.FlushTransferer) (*ring.Lifecycler, *ring.Ring, error) {
+	lifecycler, err := ring.NewLifecycler(cfg, flushTransferer, "distributor", distributorsRingKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	r, err := ring.New(cfg.RingConfig, "distributor", distributorsRingKey)
+	if err != nil {
+		lifecycler.Shutdown()
+		return nil, nil, err
+	}
+	return lifecycler, r, nil
+}
+
+// ingestionRateLimit returns the user's ingestion rate limit on this
+// distributor.  The global strategy falls back to the local one until the
+// distributors' ring has a healthy distributor, e.g. while this one joins it.
+func (d *Distributor) ingestionRateLimit(userID string) rate.Limit {
+	switch d.cfg.IngestionRateStrategy {
+	case InfiniteIngestionRateStrategy:
+		return rate.Inf
+	case GlobalIngestionRateStrategy:
+		if n := d.distributorsRing.HealthyInstancesCount(); n > 0 {
+			return rate.Limit(d.limits.IngestionRate(userID) / float64(n))
+		}
+	}
+	return rate.Limit(d.limits.IngestionRate(userID))
+}
+
+// TransferOut is a noop for the distributor, which has no state to hand over
+// when leaving the distributors' ring.
+func (d *Distributor) TransferOut(ctx context.Context) error {
+	return nil
+}
+
+// StopIncomingRequests is a noop for the distributor.
+func (d *Distributor) StopIncomingRequests() {}
+
+// Flush is a noop for the distributor.
+func (d *Distributor) Flush() {}
@@ -0,0 +1,312 @@
+// Package otlp converts metrics pushed with the OpenTelemetry protocol into
+// Cortex series, following the conventions of Prometheus' own OTLP receiver.
+package otlp
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/pkg/value"
+
+	"github.com/cortexproject/cortex/pkg/ingester/client"
+	"github.com/cortexproject/cortex/pkg/util"
+)
+
+// The resource attributes the job and instance labels are derived from.
+const (
+	serviceNameAttribute       = "service.name"
+	serviceNamespaceAttribute  = "service.namespace"
+	serviceInstanceIDAttribute = "service.instance.id"
+)
+
+// ToTimeseries converts the request's metrics into series, one sample per
+// data point.  Resource attributes become labels of all their metrics'
+// series, overridden by the data points' own attributes, with the job and
+// instance labels derived from the service's name and instance ID.  Delta
+// temporality points are accumulated into cumulative ones with deltas, or
+// rejected if it is nil.  The series of the points which can be converted
+// are returned along with an error for the last point which couldn't.
+func ToTimeseries(userID string, req *ExportMetricsServiceRequest, deltas *Deltas) ([]client.PreallocTimeseries, error) {
+	c := converter{userID: userID, deltas: deltas, index: map[string]int{}}
+	for _, rm := range req.ResourceMetrics {
+		resourceLabels := resourceLabels(rm.Resource)
+		for _, sm := range rm.ScopeMetrics {
+			for _, m := range sm.Metrics {
+				c.addMetric(resourceLabels, m)
+			}
+		}
+	}
+	return c.series, c.err
+}
+
+// Deltas accumulates the points of delta temporality series into cumulative
+// values.  Each distributor accumulates the points it receives, so a series'
+// pushes must all go to the same distributor.
+type Deltas struct {
+	mtx    sync.Mutex
+	series map[string]*deltaSeries
+}
+
+type deltaSeries struct {
+	lastTimestampMs int64
+	total           float64
+	updated         time.Time
+}
+
+// NewDeltas makes a new Deltas.
+func NewDeltas() *Deltas {
+	return &Deltas{series: map[string]*deltaSeries{}}
+}
+
+// add adds the point's delta to the series' total, which it returns.  Points
+// no newer than the series' last one, e.g. from retried pushes, aren't added
+// again.
+func (d *Deltas) add(key string, timestampMs int64, delta float64) float64 {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	s, ok := d.series[key]
+	if !ok {
+		s = &deltaSeries{lastTimestampMs: math.MinInt64}
+		d.series[key] = s
+	}
+	s.updated = time.Now()
+	if timestampMs > s.lastTimestampMs {
+		s.lastTimestampMs = timestampMs
+		s.total += delta
+	}
+	return s.total
+}
+
+// Expire forgets the totals of the series without points since before,
+// which start again from zero if they get new points.
+func (d *Deltas) Expire(before time.Time) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	for key, s := range d.series {
+		if s.updated.Before(before) {
+			delete(d.series, key)
+		}
+	}
+}
+
+type converter struct {
+	userID string
+	deltas *Deltas
+
+	series []client.PreallocTimeseries
+	// The index in series of each series' labels.
+	index map[string]int
+	err   error
+}
+
+func (c *converter) addMetric(resourceLabels labels.Labels, m *Metric) {
+	name := util.SanitizeMetricName(m.Name)
+	switch data := m.Data.(type) {
+	case *Metric_Gauge:
+		for _, p := range data.Gauge.DataPoints {
+			c.addSample(withAttributes(resourceLabels, p.Attributes, name), p.TimeUnixNano, p.Flags, numberValue(p), false)
+		}
+
+	case *Metric_Sum:
+		delta, err := c.isDelta(m.Name, data.Sum.AggregationTemporality)
+		if err != nil {
+			c.err = err
+			return
+		}
+		for _, p := range data.Sum.DataPoints {
+			c.addSample(withAttributes(resourceLabels, p.Attributes, name), p.TimeUnixNano, p.Flags, numberValue(p), delta)
+		}
+
+	case *Metric_Histogram:
+		delta, err := c.isDelta(m.Name, data.Histogram.AggregationTemporality)
+		if err != nil {
+			c.err = err
+			return
+		}
+		for _, p := range data.Histogram.DataPoints {
+			if len(p.BucketCounts) != 0 && len(p.BucketCounts) != len(p.ExplicitBounds)+1 {
+				c.err = fmt.Errorf("histogram %q has %d bucket counts for %d bounds", m.Name, len(p.BucketCounts), len(p.ExplicitBounds))
+				continue
+			}
+			var cumulative uint64
+			for i, bound := range p.ExplicitBounds {
+				if len(p.BucketCounts) > 0 {
+					cumulative += p.BucketCounts[i]
+				}
+				ls := withAttributes(resourceLabels, p.Attributes, name+"_bucket", labels.Label{Name: model.BucketLabel, Value: formatFloat(bound)})
+				c.addSample(ls, p.TimeUnixNano, p.Flags, float64(cumulative), delta)
+			}
+			ls := withAttributes(resourceLabels, p.Attributes, name+"_bucket", labels.Label{Name: model.BucketLabel, Value: "+Inf"})
+			c.addSample(ls, p.TimeUnixNano, p.Flags, float64(p.Count), delta)
+			c.addSample(withAttributes(resourceLabels, p.Attributes, name+"_sum"), p.TimeUnixNano, p.Flags, p.Sum, delta)
+			c.addSample(withAttributes(resourceLabels, p.Attributes, name+"_count"), p.TimeUnixNano, p.Flags, float64(p.Count), delta)
+		}
+
+	case *Metric_Summary:
+		for _, p := range data.Summary.DataPoints {
+			for _, q := range p.QuantileValues {
+				ls := withAttributes(resourceLabels, p.Attributes, name, labels.Label{Name: model.QuantileLabel, Value: formatFloat(q.Quantile)})
+				c.addSample(ls, p.TimeUnixNano, p.Flags, q.Value, false)
+			}
+			c.addSample(withAttributes(resourceLabels, p.Attributes, name+"_sum"), p.TimeUnixNano, p.Flags, p.Sum, false)
+			c.addSample(withAttributes(resourceLabels, p.Attributes, name+"_count"), p.TimeUnixNano, p.Flags, float64(p.Count), false)
+		}
+
+	case *Metric_ExponentialHistogram:
+		c.err = fmt.Errorf("exponential histogram %q is not supported", m.Name)
+
+	default:
+		c.err = fmt.Errorf("metric %q has no data", m.Name)
+	}
+}
+
+// isDelta returns whether the metric's points have delta temporality, or an
+// error if they can't be converted.
+func (c *converter) isDelta(name string, temporality AggregationTemporality) (bool, error) {
+	switch temporality {
+	case AGGREGATION_TEMPORALITY_CUMULATIVE:
+		return false, nil
+	case AGGREGATION_TEMPORALITY_DELTA:
+		if c.deltas == nil {
+			return false, fmt.Errorf("metric %q has delta temporality, which is not accepted", name)
+		}
+		return true, nil
+	}
+	return false, fmt.Errorf("metric %q has an unspecified temporality", name)
+}
+
+func (c *converter) addSample(ls labels.Labels, timeUnixNano uint64, flags uint32, v float64, delta bool) {
+	timestampMs := int64(timeUnixNano / uint64(time.Millisecond))
+	key := ls.String()
+	if flags&uint32(DATA_POINT_FLAGS_NO_RECORDED_VALUE_MASK) != 0 {
+		v = math.Float64frombits(value.StaleNaN)
+	} else if delta {
+		v = c.deltas.add(c.userID+"\xff"+key, timestampMs, v)
+	}
+
+	sample := client.Sample{TimestampMs: timestampMs, Value: v}
+	if i, ok := c.index[key]; ok {
+		c.series[i].Samples = append(c.series[i].Samples, sample)
+		return
+	}
+	c.index[key] = len(c.series)
+	c.series = append(c.series, client.PreallocTimeseries{
+		TimeSeries: client.TimeSeries{
+			Labels:  client.FromLabelsToLabelAdapaters(ls),
+			Samples: []client.Sample{sample},
+		},
+	})
+}
+
+func numberValue(p *NumberDataPoint) float64 {
+	if v, ok := p.Value.(*NumberDataPoint_AsInt); ok {
+		return float64(v.AsInt)
+	}
+	return p.GetAsDouble()
+}
+
+// resourceLabels returns the labels of the resource's attributes, and the
+// job and instance labels derived from them.
+func resourceLabels(r *Resource) labels.Labels {
+	if r == nil {
+		return nil
+	}
+	b := labelsBuilder{}
+	b.addAttributes(r.Attributes)
+
+	var name, namespace, instance string
+	for _, kv := range r.Attributes {
+		switch kv.Key {
+		case serviceNameAttribute:
+			name = attributeValue(kv.Value)
+		case serviceNamespaceAttribute:
+			namespace = attributeValue(kv.Value)
+		case serviceInstanceIDAttribute:
+			instance = attributeValue(kv.Value)
+		}
+	}
+	if name != "" {
+		if namespace != "" {
+			name = namespace + "/" + name
+		}
+		b[model.JobLabel] = name
+	}
+	if instance != "" {
+		b[model.InstanceLabel] = instance
+	}
+	return b.labels()
+}
+
+// withAttributes returns the resource's labels, overridden by the data
+// point's attributes and the extra labels, with the metric name.
+func withAttributes(resourceLabels labels.Labels, attributes []*KeyValue, name string, extra ...labels.Label) labels.Labels {
+	b := make(labelsBuilder, len(resourceLabels)+len(attributes)+len(extra)+1)
+	for _, l := range resourceLabels {
+		b[l.Name] = l.Value
+	}
+	b.addAttributes(attributes)
+	for _, l := range extra {
+		b[l.Name] = l.Value
+	}
+	b[model.MetricNameLabel] = name
+	return b.labels()
+}
+
+// labelsBuilder maps label names to their values.
+type labelsBuilder map[string]string
+
+// addAttributes adds the attributes as labels, with sanitized names.
+// Attributes whose names are the same once sanitized have their values
+// joined with ";".
+func (b labelsBuilder) addAttributes(attributes []*KeyValue) {
+	sanitized := make(map[string][]string, len(attributes))
+	var names []string
+	for _, kv := range attributes {
+		name := util.SanitizeLabelName(kv.Key)
+		if _, ok := sanitized[name]; !ok {
+			names = append(names, name)
+		}
+		sanitized[name] = append(sanitized[name], attributeValue(kv.Value))
+	}
+	for _, name := range names {
+		values := sanitized[name]
+		sort.Strings(values)
+		b[name] = strings.Join(values, ";")
+	}
+}
+
+func (b labelsBuilder) labels() labels.Labels {
+	ls := make(labels.Labels, 0, len(b))
+	for name, value := range b {
+		if value != "" {
+			ls = append(ls, labels.Label{Name: name, Value: value})
+		}
+	}
+	sort.Sort(ls)
+	return ls
+}
+
+func attributeValue(v *AnyValue) string {
+	switch v := v.GetValue().(type) {
+	case *AnyValue_StringValue:
+		return v.StringValue
+	case *AnyValue_BoolValue:
+		return strconv.FormatBool(v.BoolValue)
+	case *AnyValue_IntValue:
+		return strconv.FormatInt(v.IntValue, 10)
+	case *AnyValue_DoubleValue:
+		return formatFloat(v.DoubleValue)
+	}
+	return ""
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,172 @@
+package otlp
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/pkg/value"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cortexproject/cortex/pkg/ingester/client"
+)
+
+func stringAttribute(key, value string) *KeyValue {
+	return &KeyValue{Key: key, Value: &AnyValue{Value: &AnyValue_StringValue{StringValue: value}}}
+}
+
+func request(resource *Resource, metrics ...*Metric) *ExportMetricsServiceRequest {
+	return &ExportMetricsServiceRequest{
+		ResourceMetrics: []*ResourceMetrics{{
+			Resource:     resource,
+			ScopeMetrics: []*ScopeMetrics{{Metrics: metrics}},
+		}},
+	}
+}
+
+func nanos(ms int64) uint64 {
+	return uint64(ms * int64(time.Millisecond))
+}
+
+type series struct {
+	labels  string
+	samples []client.Sample
+}
+
+func toSeries(timeseries []client.PreallocTimeseries) []series {
+	result := make([]series, 0, len(timeseries))
+	for _, ts := range timeseries {
+		result = append(result, series{client.FromLabelAdaptersToMetric(ts.Labels).String(), ts.Samples})
+	}
+	return result
+}
+
+func TestToTimeseries(t *testing.T) {
+	resource := &Resource{Attributes: []*KeyValue{
+		stringAttribute("service.name", "api"),
+		stringAttribute("service.namespace", "shop"),
+		stringAttribute("service.instance.id", "host:8080"),
+		stringAttribute("k8s.pod.name", "api-1"),
+	}}
+	attributes := []*KeyValue{
+		stringAttribute("http.method", "GET"),
+		{Key: "2xx", Value: &AnyValue{Value: &AnyValue_BoolValue{BoolValue: true}}},
+	}
+
+	timeseries, err := ToTimeseries("user", request(resource,
+		&Metric{Name: "process.memory", Data: &Metric_Gauge{Gauge: &Gauge{DataPoints: []*NumberDataPoint{
+			{TimeUnixNano: nanos(1000), Value: &NumberDataPoint_AsInt{AsInt: 7}},
+			{TimeUnixNano: nanos(2000), Value: &NumberDataPoint_AsDouble{AsDouble: 8.5}},
+		}}}},
+		&Metric{Name: "http.requests", Data: &Metric_Sum{Sum: &Sum{
+			AggregationTemporality: AGGREGATION_TEMPORALITY_CUMULATIVE,
+			IsMonotonic:            true,
+			DataPoints:             []*NumberDataPoint{{Attributes: attributes, TimeUnixNano: nanos(1000), Value: &NumberDataPoint_AsDouble{AsDouble: 3}}},
+		}}},
+	), nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, []series{
+		{`process_memory{instance="host:8080", job="shop/api", k8s_pod_name="api-1", service_instance_id="host:8080", service_name="api", service_namespace="shop"}`, []client.Sample{{TimestampMs: 1000, Value: 7}, {TimestampMs: 2000, Value: 8.5}}},
+		{`http_requests{http_method="GET", instance="host:8080", job="shop/api", k8s_pod_name="api-1", key_2xx="true", service_instance_id="host:8080", service_name="api", service_namespace="shop"}`, []client.Sample{{TimestampMs: 1000, Value: 3}}},
+	}, toSeries(timeseries))
+}
+
+func TestToTimeseriesHistogramsAndSummaries(t *testing.T) {
+	timeseries, err := ToTimeseries("user", request(nil,
+		&Metric{Name: "latency", Data: &Metric_Histogram{Histogram: &Histogram{
+			AggregationTemporality: AGGREGATION_TEMPORALITY_CUMULATIVE,
+			DataPoints: []*HistogramDataPoint{{
+				TimeUnixNano:   nanos(1000),
+				Count:          6,
+				Sum:            4.5,
+				BucketCounts:   []uint64{1, 2, 3},
+				ExplicitBounds: []float64{0.5, 1},
+			}},
+		}}},
+		&Metric{Name: "size", Data: &Metric_Summary{Summary: &Summary{DataPoints: []*SummaryDataPoint{{
+			TimeUnixNano:   nanos(1000),
+			Count:          2,
+			Sum:            10,
+			QuantileValues: []*ValueAtQuantile{{Quantile: 0.5, Value: 4}},
+		}}}}},
+	), nil)
+	require.NoError(t, err)
+
+	sample := func(v float64) []client.Sample { return []client.Sample{{TimestampMs: 1000, Value: v}} }
+	assert.Equal(t, []series{
+		{`latency_bucket{le="0.5"}`, sample(1)},
+		{`latency_bucket{le="1"}`, sample(3)},
+		{`latency_bucket{le="+Inf"}`, sample(6)},
+		{`latency_sum`, sample(4.5)},
+		{`latency_count`, sample(6)},
+		{`size{quantile="0.5"}`, sample(4)},
+		{`size_sum`, sample(10)},
+		{`size_count`, sample(2)},
+	}, toSeries(timeseries))
+}
+
+func TestToTimeseriesDeltas(t *testing.T) {
+	delta := func(ms int64, v float64) *Metric {
+		return &Metric{Name: "requests", Data: &Metric_Sum{Sum: &Sum{
+			AggregationTemporality: AGGREGATION_TEMPORALITY_DELTA,
+			DataPoints:             []*NumberDataPoint{{TimeUnixNano: nanos(ms), Value: &NumberDataPoint_AsDouble{AsDouble: v}}},
+		}}}
+	}
+	gauge := &Metric{Name: "up", Data: &Metric_Gauge{Gauge: &Gauge{DataPoints: []*NumberDataPoint{
+		{TimeUnixNano: nanos(1000), Value: &NumberDataPoint_AsDouble{AsDouble: 1}},
+	}}}}
+
+	// Without accumulating them, delta points are rejected, but the other
+	// points are still converted.
+	timeseries, err := ToTimeseries("user", request(nil, delta(1000, 1), gauge), nil)
+	require.Error(t, err)
+	assert.Equal(t, []series{{"up", []client.Sample{{TimestampMs: 1000, Value: 1}}}}, toSeries(timeseries))
+
+	deltas := NewDeltas()
+	for _, tc := range []struct {
+		ms       int64
+		delta    float64
+		user     string
+		expected float64
+	}{
+		{1000, 2, "user", 2},
+		{2000, 3, "user", 5},
+		// Retried points aren't added again.
+		{2000, 3, "user", 5},
+		// Each user has its own totals.
+		{2000, 1, "other", 1},
+	} {
+		timeseries, err := ToTimeseries(tc.user, request(nil, delta(tc.ms, tc.delta)), deltas)
+		require.NoError(t, err)
+		assert.Equal(t, []series{{"requests", []client.Sample{{TimestampMs: tc.ms, Value: tc.expected}}}}, toSeries(timeseries))
+	}
+
+	// Expired series start again from zero.
+	deltas.Expire(time.Now().Add(time.Minute))
+	timeseries, err = ToTimeseries("user", request(nil, delta(3000, 4)), deltas)
+	require.NoError(t, err)
+	assert.Equal(t, []series{{"requests", []client.Sample{{TimestampMs: 3000, Value: 4}}}}, toSeries(timeseries))
+}
+
+func TestToTimeseriesStaleness(t *testing.T) {
+	timeseries, err := ToTimeseries("user", request(nil,
+		&Metric{Name: "up", Data: &Metric_Gauge{Gauge: &Gauge{DataPoints: []*NumberDataPoint{
+			{TimeUnixNano: nanos(1000), Flags: uint32(DATA_POINT_FLAGS_NO_RECORDED_VALUE_MASK)},
+		}}}},
+	), nil)
+	require.NoError(t, err)
+	require.Len(t, timeseries, 1)
+	assert.Equal(t, labels.Labels{{Name: labels.MetricName, Value: "up"}}, client.FromLabelAdaptersToLabels(timeseries[0].Labels))
+	assert.True(t, value.IsStaleNaN(timeseries[0].Samples[0].Value))
+	assert.True(t, math.IsNaN(timeseries[0].Samples[0].Value))
+}
+
+func TestToTimeseriesUnsupported(t *testing.T) {
+	_, err := ToTimeseries("user", request(nil,
+		&Metric{Name: "exponential", Data: &Metric_ExponentialHistogram{ExponentialHistogram: &ExponentialHistogram{}}},
+	), nil)
+	assert.EqualError(t, err, `exponential histogram "exponential" is not supported`)
+}
@@ -65,12 +65,14 @@ func (d *Distributor) queryPrep(ctx context.Context, from, to model.Time, matche
 		return replicationSet, nil, err
 	}
 
-	// Get ingesters by metricName if one exists, otherwise get all ingesters
+	// Get ingesters by metricName if one exists, otherwise get all the
+	// ingesters of the user's shard.
+	r := d.ringForUser(userID)
 	metricNameMatcher, _, ok := extract.MetricNameMatcherFromMatchers(matchers)
 	if !d.cfg.ShardByAllLabels && ok && metricNameMatcher.Type == labels.MatchEqual {
-		replicationSet, err = d.ring.Get(shardByMetricName(userID, metricNameMatcher.Value), ring.Read)
+		replicationSet, err = r.Get(shardByMetricName(userID, metricNameMatcher.Value), ring.Read)
 	} else {
-		replicationSet, err = d.ring.GetAll()
+		replicationSet, err = r.GetAll()
 	}
 	return replicationSet, req, err
 }
@@ -79,7 +81,7 @@ func (d *Distributor) queryPrep(ctx context.Context, from, to model.Time, matche
 func (d *Distributor) queryIngesters(ctx context.Context, replicationSet ring.ReplicationSet, req *client.QueryRequest) (model.Matrix, error) {
 	// Fetch samples from multiple ingesters in parallel, using the replicationSet
 	// to deal with consistency.
-	results, err := replicationSet.Do(ctx, d.cfg.ExtraQueryDelay, func(ing *ring.IngesterDesc) (interface{}, error) {
+	results, err := d.doQuery(ctx, replicationSet, func(ing *ring.IngesterDesc) (interface{}, error) {
 		client, err := d.ingesterPool.GetClientFor(ing.Addr)
 		if err != nil {
 			return nil, err
@@ -124,7 +126,7 @@ func (d *Distributor) queryIngesters(ctx context.Context, replicationSet ring.Re
 // queryIngesterStream queries the ingesters using the new streaming API.
 func (d *Distributor) queryIngesterStream(ctx context.Context, replicationSet ring.ReplicationSet, req *client.QueryRequest) ([]client.TimeSeriesChunk, error) {
 	// Fetch samples from multiple ingesters
-	results, err := replicationSet.Do(ctx, d.cfg.ExtraQueryDelay, func(ing *ring.IngesterDesc) (interface{}, error) {
+	results, err := d.doQuery(ctx, replicationSet, func(ing *ring.IngesterDesc) (interface{}, error) {
 		client, err := d.ingesterPool.GetClientFor(ing.Addr)
 		if err != nil {
 			return nil, err
@@ -0,0 +1,141 @@
+// Package writev2 converts requests of the Prometheus remote write 2.0
+// protocol into Cortex write requests.
+package writev2
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/cortexproject/cortex/pkg/ingester/client"
+)
+
+// RequestProto is the proto parameter of the Content-Type of remote write
+// 2.0 requests.
+const RequestProto = "io.prometheus.write.v2.Request"
+
+// The suffixes of the series of classic histograms and summaries, stripped
+// from their names to get the name of their metric family.
+var familySuffixes = []string{"_bucket", "_sum", "_count"}
+
+// ToWriteRequest converts the request's series into a WriteRequest, resolving
+// the references to its symbols into their labels, and their metadata into
+// the metadata of their metric families.  Created timestamps are dropped.  A
+// request with invalid references is rejected whole.
+func ToWriteRequest(req *Request) (*client.WriteRequest, error) {
+	if len(req.Symbols) > 0 && req.Symbols[0] != "" {
+		return nil, fmt.Errorf("the first symbol must be the empty string, got %.200q", req.Symbols[0])
+	}
+
+	result := &client.WriteRequest{
+		Timeseries: make([]client.PreallocTimeseries, 0, len(req.Timeseries)),
+		Source:     client.API,
+	}
+	seen := map[client.MetricMetadata]struct{}{}
+	for _, ts := range req.Timeseries {
+		ls, err := labels(req.Symbols, ts.LabelsRefs)
+		if err != nil {
+			return nil, err
+		}
+
+		samples := make([]client.Sample, 0, len(ts.Samples))
+		for _, s := range ts.Samples {
+			samples = append(samples, client.Sample{TimestampMs: s.Timestamp, Value: s.Value})
+		}
+		exemplars := make([]client.Exemplar, 0, len(ts.Exemplars))
+		for _, e := range ts.Exemplars {
+			els, err := labels(req.Symbols, e.LabelsRefs)
+			if err != nil {
+				return nil, err
+			}
+			exemplars = append(exemplars, client.Exemplar{Labels: els, Value: e.Value, TimestampMs: e.Timestamp})
+		}
+		result.Timeseries = append(result.Timeseries, client.PreallocTimeseries{
+			TimeSeries: client.TimeSeries{
+				Labels:     ls,
+				Samples:    samples,
+				Exemplars:  exemplars,
+				Histograms: ts.Histograms,
+			},
+		})
+
+		m, err := metadata(req.Symbols, ls, ts.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		if m == nil {
+			continue
+		}
+		// Series of the same metric family share its metadata.
+		if _, ok := seen[*m]; !ok {
+			seen[*m] = struct{}{}
+			result.Metadata = append(result.Metadata, m)
+		}
+	}
+	return result, nil
+}
+
+func symbol(symbols []string, ref uint32) (string, error) {
+	if int(ref) >= len(symbols) {
+		return "", fmt.Errorf("symbol reference %d out of range of the %d symbols", ref, len(symbols))
+	}
+	return symbols[ref], nil
+}
+
+func labels(symbols []string, refs []uint32) ([]client.LabelAdapter, error) {
+	if len(refs)%2 != 0 {
+		return nil, fmt.Errorf("odd number of label references: %d", len(refs))
+	}
+	ls := make([]client.LabelAdapter, 0, len(refs)/2)
+	for i := 0; i < len(refs); i += 2 {
+		name, err := symbol(symbols, refs[i])
+		if err != nil {
+			return nil, err
+		}
+		value, err := symbol(symbols, refs[i+1])
+		if err != nil {
+			return nil, err
+		}
+		ls = append(ls, client.LabelAdapter{Name: name, Value: value})
+	}
+	return ls, nil
+}
+
+// metadata returns the metadata of the series' metric family, or nil if it
+// has none.  The metadata types have the same values in both protocols.
+func metadata(symbols []string, ls []client.LabelAdapter, m Metadata) (*client.MetricMetadata, error) {
+	if m.Type == METRIC_TYPE_UNSPECIFIED && m.HelpRef == 0 && m.UnitRef == 0 {
+		return nil, nil
+	}
+	help, err := symbol(symbols, m.HelpRef)
+	if err != nil {
+		return nil, err
+	}
+	unit, err := symbol(symbols, m.UnitRef)
+	if err != nil {
+		return nil, err
+	}
+
+	var family string
+	for _, l := range ls {
+		if l.Name == model.MetricNameLabel {
+			family = l.Value
+		}
+	}
+	switch m.Type {
+	case METRIC_TYPE_HISTOGRAM, METRIC_TYPE_GAUGEHISTOGRAM, METRIC_TYPE_SUMMARY:
+		for _, suffix := range familySuffixes {
+			if strings.HasSuffix(family, suffix) {
+				family = strings.TrimSuffix(family, suffix)
+				break
+			}
+		}
+	}
+	return &client.MetricMetadata{
+		MetricFamilyName: family,
+		Type:             client.MetricMetadata_MetricType(m.Type),
+		Help:             help,
+		Unit:             unit,
+	}, nil
+}
@@ -0,0 +1,78 @@
+package writev2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cortexproject/cortex/pkg/ingester/client"
+)
+
+func TestToWriteRequest(t *testing.T) {
+	symbols := []string{"", "__name__", "requests_total", "job", "api", "Requests.", "trace_id", "abc", "latency_bucket", "le", "0.5", "+Inf", "seconds"}
+	req, err := ToWriteRequest(&Request{
+		Symbols: symbols,
+		Timeseries: []TimeSeries{
+			{
+				LabelsRefs:       []uint32{1, 2, 3, 4},
+				Samples:          []Sample{{Value: 1, Timestamp: 1000}, {Value: 2, Timestamp: 2000}},
+				Exemplars:        []Exemplar{{LabelsRefs: []uint32{6, 7}, Value: 1, Timestamp: 1500}},
+				Metadata:         Metadata{Type: METRIC_TYPE_COUNTER, HelpRef: 5},
+				CreatedTimestamp: 500,
+			},
+			{
+				LabelsRefs: []uint32{1, 8, 9, 10},
+				Samples:    []Sample{{Value: 3, Timestamp: 1000}},
+				Metadata:   Metadata{Type: METRIC_TYPE_HISTOGRAM, UnitRef: 12},
+			},
+			{
+				LabelsRefs: []uint32{1, 8, 9, 11},
+				Samples:    []Sample{{Value: 4, Timestamp: 1000}},
+				Metadata:   Metadata{Type: METRIC_TYPE_HISTOGRAM, UnitRef: 12},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, &client.WriteRequest{
+		Source: client.API,
+		Timeseries: []client.PreallocTimeseries{
+			{TimeSeries: client.TimeSeries{
+				Labels:    []client.LabelAdapter{{Name: "__name__", Value: "requests_total"}, {Name: "job", Value: "api"}},
+				Samples:   []client.Sample{{Value: 1, TimestampMs: 1000}, {Value: 2, TimestampMs: 2000}},
+				Exemplars: []client.Exemplar{{Labels: []client.LabelAdapter{{Name: "trace_id", Value: "abc"}}, Value: 1, TimestampMs: 1500}},
+			}},
+			{TimeSeries: client.TimeSeries{
+				Labels:    []client.LabelAdapter{{Name: "__name__", Value: "latency_bucket"}, {Name: "le", Value: "0.5"}},
+				Samples:   []client.Sample{{Value: 3, TimestampMs: 1000}},
+				Exemplars: []client.Exemplar{},
+			}},
+			{TimeSeries: client.TimeSeries{
+				Labels:    []client.LabelAdapter{{Name: "__name__", Value: "latency_bucket"}, {Name: "le", Value: "+Inf"}},
+				Samples:   []client.Sample{{Value: 4, TimestampMs: 1000}},
+				Exemplars: []client.Exemplar{},
+			}},
+		},
+		// The histogram's series share the metadata of their family.
+		Metadata: []*client.MetricMetadata{
+			{MetricFamilyName: "requests_total", Type: client.COUNTER, Help: "Requests."},
+			{MetricFamilyName: "latency", Type: client.HISTOGRAM, Unit: "seconds"},
+		},
+	}, req)
+}
+
+func TestToWriteRequestInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		req      Request
+		expected string
+	}{
+		{Request{Symbols: []string{"__name__"}}, `the first symbol must be the empty string, got "__name__"`},
+		{Request{Symbols: []string{"", "__name__"}, Timeseries: []TimeSeries{{LabelsRefs: []uint32{1}}}}, "odd number of label references: 1"},
+		{Request{Symbols: []string{"", "__name__"}, Timeseries: []TimeSeries{{LabelsRefs: []uint32{1, 2}}}}, "symbol reference 2 out of range of the 2 symbols"},
+		{Request{Symbols: []string{"", "__name__"}, Timeseries: []TimeSeries{{LabelsRefs: []uint32{1, 0}, Metadata: Metadata{HelpRef: 3}}}}, "symbol reference 3 out of range of the 2 symbols"},
+	} {
+		_, err := ToWriteRequest(&tc.req)
+		assert.EqualError(t, err, tc.expected)
+	}
+}
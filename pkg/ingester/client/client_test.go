@@ -37,3 +37,35 @@ func TestMarshall(t *testing.T) {
 		require.NoError(t, err)
 	}
 }
+
+func TestPreallocWriteRequestReuse(t *testing.T) {
+	marshal := func(n int) []byte {
+		req := WriteRequest{}
+		for i := 0; i < n; i++ {
+			req.Timeseries = append(req.Timeseries, PreallocTimeseries{
+				TimeSeries{
+					Labels: []LabelAdapter{
+						{"foo", strconv.Itoa(n)},
+						{"bar", strconv.Itoa(i)},
+					},
+					Samples: []Sample{
+						{TimestampMs: int64(i), Value: float64(n)},
+					},
+				},
+			})
+		}
+		buf, err := req.Marshal()
+		require.NoError(t, err)
+		return buf
+	}
+
+	// Requests decoded into reused slices hold only their own timeseries.
+	for _, n := range []int{10, 3, 20} {
+		var req PreallocWriteRequest
+		require.NoError(t, req.Unmarshal(marshal(n)))
+		var expected WriteRequest
+		require.NoError(t, expected.Unmarshal(marshal(n)))
+		require.Equal(t, expected.Timeseries, req.Timeseries)
+		ReuseSlice(req.Timeseries)
+	}
+}
@@ -144,6 +144,36 @@ func FromMetricsForLabelMatchersResponse(resp *MetricsForLabelMatchersResponse)
 	return metrics
 }
 
+// ToExemplarQueryRequest builds an ExemplarQueryRequest proto.
+func ToExemplarQueryRequest(from, to model.Time, matchersSet [][]*labels.Matcher) (*ExemplarQueryRequest, error) {
+	req := &ExemplarQueryRequest{
+		StartTimestampMs: int64(from),
+		EndTimestampMs:   int64(to),
+		MatchersSet:      make([]*LabelMatchers, 0, len(matchersSet)),
+	}
+	for _, matchers := range matchersSet {
+		ms, err := toLabelMatchers(matchers)
+		if err != nil {
+			return nil, err
+		}
+		req.MatchersSet = append(req.MatchersSet, &LabelMatchers{Matchers: ms})
+	}
+	return req, nil
+}
+
+// FromExemplarQueryRequest unpacks an ExemplarQueryRequest proto.
+func FromExemplarQueryRequest(req *ExemplarQueryRequest) (model.Time, model.Time, [][]*labels.Matcher, error) {
+	matchersSet := make([][]*labels.Matcher, 0, len(req.MatchersSet))
+	for _, matchers := range req.MatchersSet {
+		matchers, err := fromLabelMatchers(matchers.Matchers)
+		if err != nil {
+			return 0, 0, nil, err
+		}
+		matchersSet = append(matchersSet, matchers)
+	}
+	return model.Time(req.StartTimestampMs), model.Time(req.EndTimestampMs), matchersSet, nil
+}
+
 func toLabelMatchers(matchers []*labels.Matcher) ([]*LabelMatcher, error) {
 	result := make([]*LabelMatcher, 0, len(matchers))
 	for _, matcher := range matchers {
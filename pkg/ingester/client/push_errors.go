@@ -0,0 +1,61 @@
+package client
+
+import (
+	"encoding/base64"
+
+	"github.com/weaveworks/common/httpgrpc"
+)
+
+// PushErrorsHeader is the header of a push's HTTP gRPC error holding the
+// base64 encoded PushErrors, listing the samples which were rejected.  The
+// error's code and body are those of the last rejected sample, so that
+// clients unaware of the header still get the same error.
+const PushErrorsHeader = "X-Cortex-Push-Errors"
+
+// MaxPushErrorsHeaderSize is the most bytes the PushErrorsHeader's value
+// takes, well under the 8KiB many HTTP servers and proxies limit all of a
+// response's headers to.
+const MaxPushErrorsHeaderSize = 4096
+
+// ErrorFromPushErrors returns an HTTP gRPC error with the code and body,
+// and errs in its PushErrorsHeader.  Only as many of the errors as fit in
+// MaxPushErrorsHeaderSize are listed; the header still counts all of the
+// rejected samples.
+func ErrorFromPushErrors(code int32, body string, errs *PushErrors) error {
+	truncated := *errs
+	buf, err := truncated.Marshal()
+	for err == nil && base64.StdEncoding.EncodedLen(len(buf)) > MaxPushErrorsHeaderSize && len(truncated.Errors) > 0 {
+		truncated.Errors = truncated.Errors[:len(truncated.Errors)-1]
+		buf, err = truncated.Marshal()
+	}
+	if err != nil {
+		return err
+	}
+	return httpgrpc.ErrorFromHTTPResponse(&httpgrpc.HTTPResponse{
+		Code: code,
+		Body: []byte(body),
+		Headers: []*httpgrpc.Header{
+			{Key: PushErrorsHeader, Values: []string{base64.StdEncoding.EncodeToString(buf)}},
+		},
+	})
+}
+
+// PushErrorsFromHTTPResponse returns the PushErrors in the response's
+// PushErrorsHeader, or nil if it has none.
+func PushErrorsFromHTTPResponse(resp *httpgrpc.HTTPResponse) (*PushErrors, error) {
+	for _, h := range resp.Headers {
+		if h.Key != PushErrorsHeader || len(h.Values) == 0 {
+			continue
+		}
+		buf, err := base64.StdEncoding.DecodeString(h.Values[0])
+		if err != nil {
+			return nil, err
+		}
+		var errs PushErrors
+		if err := errs.Unmarshal(buf); err != nil {
+			return nil, err
+		}
+		return &errs, nil
+	}
+	return nil, nil
+}
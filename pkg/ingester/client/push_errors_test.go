@@ -0,0 +1,47 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/httpgrpc"
+)
+
+func TestErrorFromPushErrors(t *testing.T) {
+	errs := &PushErrors{RejectedSamples: 150}
+	for i := 0; i < 100; i++ {
+		errs.Errors = append(errs.Errors, SampleError{
+			Labels:      []LabelAdapter{{Name: "__name__", Value: "foo"}, {Name: "label", Value: strings.Repeat("x", 100)}},
+			TimestampMs: int64(i),
+			Code:        http.StatusBadRequest,
+			Message:     fmt.Sprintf("sample %d out of order", i),
+		})
+	}
+
+	err := ErrorFromPushErrors(http.StatusBadRequest, "sample 99 out of order", errs)
+	resp, ok := httpgrpc.HTTPResponseFromError(err)
+	require.True(t, ok)
+	require.Equal(t, int32(http.StatusBadRequest), resp.Code)
+	require.Len(t, resp.Headers, 1)
+	require.True(t, len(resp.Headers[0].Values[0]) <= MaxPushErrorsHeaderSize)
+
+	// As many errors as fit are listed, with the count of all of the
+	// rejected samples, and the errors passed in are left alone.
+	decoded, err := PushErrorsFromHTTPResponse(resp)
+	require.NoError(t, err)
+	require.Equal(t, int64(150), decoded.RejectedSamples)
+	require.NotEmpty(t, decoded.Errors)
+	require.True(t, len(decoded.Errors) < 100)
+	require.Equal(t, errs.Errors[:len(decoded.Errors)], decoded.Errors)
+	require.Len(t, errs.Errors, 100)
+
+	// Errors which fit are all listed.
+	err = ErrorFromPushErrors(http.StatusBadRequest, "sample 0 out of order", &PushErrors{RejectedSamples: 1, Errors: errs.Errors[:1]})
+	resp, _ = httpgrpc.HTTPResponseFromError(err)
+	decoded, err = PushErrorsFromHTTPResponse(resp)
+	require.NoError(t, err)
+	require.Equal(t, errs.Errors[:1], decoded.Errors)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"sync"
 	"unsafe"
 
 	"github.com/prometheus/prometheus/pkg/labels"
@@ -27,6 +28,25 @@ func (PreallocConfig) RegisterFlags(f *flag.FlagSet) {
 	f.IntVar(&expectedSamplesPerSeries, "ingester-client.expected-samples-per-series", expectedSamplesPerSeries, "Expected number of samples per timeseries, used for preallocations.")
 }
 
+// The slices unmarshalled pushes are decoded into, returned by ReuseSlice.
+var (
+	timeseriesPool = sync.Pool{
+		New: func() interface{} {
+			return make([]PreallocTimeseries, 0, expectedTimeseries)
+		},
+	}
+	labelsPool = sync.Pool{
+		New: func() interface{} {
+			return make([]LabelAdapter, 0, expectedLabels)
+		},
+	}
+	samplesPool = sync.Pool{
+		New: func() interface{} {
+			return make([]Sample, 0, expectedSamplesPerSeries)
+		},
+	}
+)
+
 // PreallocWriteRequest is a WriteRequest which preallocs slices on Unmarshall.
 type PreallocWriteRequest struct {
 	WriteRequest
@@ -34,7 +54,7 @@ type PreallocWriteRequest struct {
 
 // Unmarshal implements proto.Message.
 func (p *PreallocWriteRequest) Unmarshal(dAtA []byte) error {
-	p.Timeseries = make([]PreallocTimeseries, 0, expectedTimeseries)
+	p.Timeseries = timeseriesPool.Get().([]PreallocTimeseries)
 	return p.WriteRequest.Unmarshal(dAtA)
 }
 
@@ -45,11 +65,23 @@ type PreallocTimeseries struct {
 
 // Unmarshal implements proto.Message.
 func (p *PreallocTimeseries) Unmarshal(dAtA []byte) error {
-	p.Labels = make([]LabelAdapter, 0, expectedLabels)
-	p.Samples = make([]Sample, 0, expectedSamplesPerSeries)
+	p.Labels = labelsPool.Get().([]LabelAdapter)
+	p.Samples = samplesPool.Get().([]Sample)
 	return p.TimeSeries.Unmarshal(dAtA)
 }
 
+// ReuseSlice returns the timeseries, and their labels and samples, to the
+// pools unmarshalling allocates them from.  It must only be called with
+// unmarshalled timeseries, once nothing references them, as other requests
+// are then decoded into them.
+func ReuseSlice(ts []PreallocTimeseries) {
+	for i := range ts {
+		labelsPool.Put(ts[i].Labels[:0])
+		samplesPool.Put(ts[i].Samples[:0])
+	}
+	timeseriesPool.Put(ts[:0])
+}
+
 // LabelAdapter is a labels.Label that can be marshalled to/from protos.
 type LabelAdapter labels.Label
 
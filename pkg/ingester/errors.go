@@ -0,0 +1,67 @@
+package ingester
+
+import (
+	"github.com/weaveworks/common/httpgrpc"
+	grpc_status "google.golang.org/grpc/status"
+
+	"github.com/cortexproject/cortex/pkg/ingester/client"
+)
+
+// maxPushErrors is the number of rejected samples listed in a push's error.
+const maxPushErrors = 100
+
+// sampleError is the HTTP gRPC error a sample is rejected with, and the
+// reason it is counted under in the discarded samples metric.
+type sampleError struct {
+	error
+	reason string
+}
+
+func newSampleError(code int, reason, format string, args ...interface{}) error {
+	return sampleError{
+		error:  httpgrpc.Errorf(code, format, args...),
+		reason: reason,
+	}
+}
+
+// GRPCStatus returns the status of the HTTP gRPC error, so the sampleError
+// still converts to an HTTP response.
+func (e sampleError) GRPCStatus() *grpc_status.Status {
+	return e.error.(interface{ GRPCStatus() *grpc_status.Status }).GRPCStatus()
+}
+
+// pushErrors collects the samples rejected from a push.
+type pushErrors struct {
+	last *httpgrpc.HTTPResponse
+	errs client.PushErrors
+}
+
+// add records the sample's error, which must be an HTTP gRPC error.
+func (p *pushErrors) add(labels []client.LabelAdapter, timestampMs int64, err error) {
+	resp, _ := httpgrpc.HTTPResponseFromError(err)
+	p.last = resp
+	p.errs.RejectedSamples++
+	if len(p.errs.Errors) < maxPushErrors {
+		var reason string
+		if se, ok := err.(sampleError); ok {
+			reason = se.reason
+		}
+		p.errs.Errors = append(p.errs.Errors, client.SampleError{
+			Labels:      labels,
+			TimestampMs: timestampMs,
+			Code:        resp.Code,
+			Reason:      reason,
+			Message:     string(resp.Body),
+		})
+	}
+}
+
+// err returns the error of the last rejected sample, listing all the
+// rejected samples, or nil if there were none.  It must be called before the
+// push's request is reused, as the labels aren't copied.
+func (p *pushErrors) err() error {
+	if p.last == nil {
+		return nil
+	}
+	return client.ErrorFromPushErrors(p.last.Code, string(p.last.Body), &p.errs)
+}
@@ -0,0 +1,233 @@
+package ingester
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/pkg/labels"
+
+	"github.com/cortexproject/cortex/pkg/ingester/client"
+)
+
+var (
+	ingestedExemplars = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "cortex_ingester_ingested_exemplars_total",
+		Help: "The total number of exemplars stored.",
+	})
+	discardedExemplars = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "cortex_ingester_discarded_exemplars_total",
+		Help: "The total number of exemplars dropped as their series was rejected, they were no newer than the latest one of their series, or exemplar storage is disabled for the tenant.",
+	})
+)
+
+// exemplarStores holds each tenant's exemplars.
+type exemplarStores struct {
+	mtx    sync.Mutex
+	stores map[string]*exemplarStore
+}
+
+func newExemplarStores() *exemplarStores {
+	return &exemplarStores{
+		stores: map[string]*exemplarStore{},
+	}
+}
+
+func (s *exemplarStores) get(userID string, create bool) *exemplarStore {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	store, ok := s.stores[userID]
+	if !ok && create {
+		store = &exemplarStore{series: map[model.Fingerprint]*exemplarSeries{}}
+		s.stores[userID] = store
+	}
+	return store
+}
+
+// appendExemplars stores the exemplars pushed for a series, once its samples
+// have been validated: they are dropped unless the ingester has the series,
+// whether it stored some of the samples pushed with them or earlier ones.
+func (i *Ingester) appendExemplars(userID string, ls labelPairs, exemplars []client.Exemplar, hasSeries bool) {
+	if len(exemplars) == 0 {
+		return
+	}
+	if !hasSeries {
+		discardedExemplars.Add(float64(len(exemplars)))
+		return
+	}
+	i.exemplars.get(userID, true).append(ls, exemplars, i.limits.MaxExemplars(userID))
+}
+
+// exemplarStore is a circular buffer of a tenant's most recent exemplars,
+// across all of its series.  Once full, each exemplar added replaces the
+// oldest one.
+type exemplarStore struct {
+	mtx     sync.RWMutex
+	entries []exemplarEntry
+	next    int
+	series  map[model.Fingerprint]*exemplarSeries
+}
+
+type exemplarEntry struct {
+	series   *exemplarSeries // nil for unused entries.
+	exemplar client.Exemplar
+}
+
+type exemplarSeries struct {
+	fp     model.Fingerprint
+	labels labels.Labels
+	latest int64
+	refs   int
+}
+
+// append adds the series' exemplars to the store, resizing it to size first
+// if need be.  Exemplars no newer than the series' latest, like those resent
+// by replicas, are dropped.
+func (s *exemplarStore) append(ls []client.LabelAdapter, exemplars []client.Exemplar, size int) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if size != len(s.entries) {
+		s.resize(size)
+	}
+	if size <= 0 {
+		discardedExemplars.Add(float64(len(exemplars)))
+		return
+	}
+
+	fp := client.FastFingerprint(ls)
+	series, ok := s.series[fp]
+	if ok && !labelPairs(ls).equal(series.labels) {
+		// Fingerprint collision; the exemplars of the series stored first win.
+		discardedExemplars.Add(float64(len(exemplars)))
+		return
+	}
+	if !ok {
+		series = &exemplarSeries{fp: fp, labels: copyLabels(ls), latest: int64(model.Earliest)}
+	}
+
+	for _, e := range exemplars {
+		if e.TimestampMs <= series.latest {
+			discardedExemplars.Inc()
+			continue
+		}
+		s.evict(s.next)
+		s.entries[s.next] = exemplarEntry{
+			series: series,
+			exemplar: client.Exemplar{
+				Labels:      client.FromLabelsToLabelAdapaters(copyLabels(e.Labels)),
+				Value:       e.Value,
+				TimestampMs: e.TimestampMs,
+			},
+		}
+		series.latest = e.TimestampMs
+		series.refs++
+		// Evicting the oldest entry may have just forgotten the series.
+		s.series[fp] = series
+		s.next = (s.next + 1) % len(s.entries)
+		ingestedExemplars.Inc()
+	}
+}
+
+// evict empties the i'th entry, forgetting its series if it has no other
+// exemplars.
+func (s *exemplarStore) evict(i int) {
+	series := s.entries[i].series
+	if series == nil {
+		return
+	}
+	s.entries[i] = exemplarEntry{}
+	series.refs--
+	if series.refs == 0 {
+		delete(s.series, series.fp)
+	}
+}
+
+// resize changes the number of exemplars stored, keeping the newest ones.
+func (s *exemplarStore) resize(size int) {
+	if size < 0 {
+		size = 0
+	}
+
+	var used []int
+	for i := range s.entries {
+		if j := (s.next + i) % len(s.entries); s.entries[j].series != nil {
+			used = append(used, j)
+		}
+	}
+	for len(used) > size {
+		s.evict(used[0])
+		used = used[1:]
+	}
+
+	entries := make([]exemplarEntry, size)
+	for i, j := range used {
+		entries[i] = s.entries[j]
+	}
+	s.entries = entries
+	s.next = 0
+	if size > 0 {
+		s.next = len(used) % size
+	}
+}
+
+// query returns the exemplars between from and through of the series matching
+// any of the sets of matchers, oldest first.
+func (s *exemplarStore) query(from, through model.Time, matchersSet [][]*labels.Matcher) []client.TimeSeries {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	bySeries := map[*exemplarSeries]*client.TimeSeries{}
+	for i := range s.entries {
+		e := s.entries[(s.next+i)%len(s.entries)]
+		if e.series == nil || e.exemplar.TimestampMs < int64(from) || e.exemplar.TimestampMs > int64(through) {
+			continue
+		}
+		ts, ok := bySeries[e.series]
+		if !ok {
+			if !matchesAny(e.series.labels, matchersSet) {
+				continue
+			}
+			ts = &client.TimeSeries{Labels: client.FromLabelsToLabelAdapaters(e.series.labels)}
+			bySeries[e.series] = ts
+		}
+		ts.Exemplars = append(ts.Exemplars, e.exemplar)
+	}
+
+	result := make([]client.TimeSeries, 0, len(bySeries))
+	for _, ts := range bySeries {
+		result = append(result, *ts)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return labels.Compare(client.FromLabelAdaptersToLabels(result[i].Labels), client.FromLabelAdaptersToLabels(result[j].Labels)) < 0
+	})
+	return result
+}
+
+func matchesAny(ls labels.Labels, matchersSet [][]*labels.Matcher) bool {
+outer:
+	for _, matchers := range matchersSet {
+		for _, m := range matchers {
+			if !m.Matches(ls.Get(m.Name)) {
+				continue outer
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// copyLabels copies the labels out of the request buffer they were
+// unmarshalled into.
+func copyLabels(ls []client.LabelAdapter) labels.Labels {
+	result := make(labels.Labels, 0, len(ls))
+	for _, l := range ls {
+		result = append(result, labels.Label{
+			Name:  string(append([]byte(nil), l.Name...)),
+			Value: string(append([]byte(nil), l.Value...)),
+		})
+	}
+	return result
+}
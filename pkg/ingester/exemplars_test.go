@@ -0,0 +1,157 @@
+package ingester
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+
+	"github.com/cortexproject/cortex/pkg/ingester/client"
+)
+
+func exemplarsAt(ts ...int64) []client.Exemplar {
+	var exemplars []client.Exemplar
+	for _, t := range ts {
+		exemplars = append(exemplars, client.Exemplar{
+			Labels:      []client.LabelAdapter{{Name: "traceID", Value: "abc"}},
+			Value:       float64(t),
+			TimestampMs: t,
+		})
+	}
+	return exemplars
+}
+
+func exemplarTimestamps(series []client.TimeSeries) map[string][]int64 {
+	result := map[string][]int64{}
+	for _, ts := range series {
+		for _, e := range ts.Exemplars {
+			key := client.FromLabelAdaptersToLabels(ts.Labels).String()
+			result[key] = append(result[key], e.TimestampMs)
+		}
+	}
+	return result
+}
+
+func TestExemplarStore(t *testing.T) {
+	foo := []client.LabelAdapter{{Name: model.MetricNameLabel, Value: "foo"}}
+	bar := []client.LabelAdapter{{Name: model.MetricNameLabel, Value: "bar"}}
+	matchAll, err := labels.NewMatcher(labels.MatchRegexp, model.MetricNameLabel, ".+")
+	require.NoError(t, err)
+	all := [][]*labels.Matcher{{matchAll}}
+
+	store := &exemplarStore{series: map[model.Fingerprint]*exemplarSeries{}}
+	store.append(foo, exemplarsAt(1, 2, 3), 4)
+	// Duplicates and out of order exemplars are dropped.
+	store.append(foo, exemplarsAt(3, 2), 4)
+	store.append(bar, exemplarsAt(1, 2), 4)
+	require.Equal(t, map[string][]int64{
+		`{__name__="bar"}`: {1, 2},
+		`{__name__="foo"}`: {2, 3},
+	}, exemplarTimestamps(store.query(0, 100, all)))
+	require.Len(t, store.series, 2)
+
+	// Only the matching series within the time range are returned.
+	fooOnly := [][]*labels.Matcher{{{Type: labels.MatchEqual, Name: model.MetricNameLabel, Value: "foo"}}}
+	require.Equal(t, map[string][]int64{
+		`{__name__="foo"}`: {3},
+	}, exemplarTimestamps(store.query(3, 100, fooOnly)))
+
+	// Once the last exemplar of a series is gone, so is it.
+	store.append(bar, exemplarsAt(3), 4)
+	require.Equal(t, map[string][]int64{
+		`{__name__="bar"}`: {1, 2, 3},
+		`{__name__="foo"}`: {3},
+	}, exemplarTimestamps(store.query(0, 100, all)))
+	store.append(bar, exemplarsAt(4), 4)
+	require.Equal(t, map[string][]int64{
+		`{__name__="bar"}`: {1, 2, 3, 4},
+	}, exemplarTimestamps(store.query(0, 100, all)))
+	require.Len(t, store.series, 1)
+
+	// Shrinking keeps the newest exemplars, growing all of them.
+	store.append(foo, exemplarsAt(6), 2)
+	require.Equal(t, map[string][]int64{
+		`{__name__="bar"}`: {4},
+		`{__name__="foo"}`: {6},
+	}, exemplarTimestamps(store.query(0, 100, all)))
+	store.append(foo, exemplarsAt(7), 3)
+	require.Equal(t, map[string][]int64{
+		`{__name__="bar"}`: {4},
+		`{__name__="foo"}`: {6, 7},
+	}, exemplarTimestamps(store.query(0, 100, all)))
+
+	// Disabling storage drops them all.
+	store.append(foo, exemplarsAt(8), 0)
+	require.Empty(t, store.query(0, 100, all))
+	require.Empty(t, store.series)
+}
+
+func TestIngesterQueryExemplars(t *testing.T) {
+	for _, tsdb := range []bool{false, true} {
+		t.Run(fmt.Sprintf("tsdb=%v", tsdb), func(t *testing.T) {
+			testIngesterQueryExemplars(t, tsdb)
+		})
+	}
+}
+
+func testIngesterQueryExemplars(t *testing.T, tsdb bool) {
+	cfg := defaultIngesterTestConfig()
+	if tsdb {
+		dir, err := ioutil.TempDir("", "tsdb")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+		cfg.TSDBEnabled = true
+		cfg.TSDBConfig.Dir = dir
+	}
+	limits := defaultLimitsTestConfig()
+	limits.MaxExemplars = 10
+	_, ing := newTestStore(t, cfg, defaultClientTestConfig(), limits)
+	defer ing.Shutdown()
+
+	ctx := user.InjectOrgID(context.Background(), "1")
+	ls := []client.LabelAdapter{{Name: model.MetricNameLabel, Value: "foo"}}
+	_, err := ing.Push(ctx, &client.WriteRequest{
+		Timeseries: []client.PreallocTimeseries{{
+			TimeSeries: client.TimeSeries{
+				Labels:    ls,
+				Samples:   []client.Sample{{TimestampMs: 1, Value: 1}},
+				Exemplars: exemplarsAt(1),
+			},
+		}},
+	})
+	require.NoError(t, err)
+
+	req, err := client.ToExemplarQueryRequest(0, 10, [][]*labels.Matcher{
+		{{Type: labels.MatchEqual, Name: model.MetricNameLabel, Value: "foo"}},
+	})
+	require.NoError(t, err)
+	resp, err := ing.QueryExemplars(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, []client.TimeSeries{{Labels: ls, Exemplars: exemplarsAt(1)}}, resp.Timeseries)
+
+	// Exemplars are stored without samples for the series the ingester has,
+	// but not for those it doesn't have or rejected.
+	invalid := []client.LabelAdapter{{Name: model.MetricNameLabel, Value: "foo"}, {Name: "-invalid", Value: "1"}}
+	_, err = ing.Push(ctx, &client.WriteRequest{
+		Timeseries: []client.PreallocTimeseries{
+			{TimeSeries: client.TimeSeries{Labels: ls, Exemplars: exemplarsAt(2)}},
+			{TimeSeries: client.TimeSeries{Labels: []client.LabelAdapter{{Name: model.MetricNameLabel, Value: "foo"}, {Name: "job", Value: "bar"}}, Exemplars: exemplarsAt(2)}},
+			{TimeSeries: client.TimeSeries{Labels: invalid, Samples: []client.Sample{{TimestampMs: 2, Value: 1}}, Exemplars: exemplarsAt(2)}},
+		},
+	})
+	require.Error(t, err)
+	resp, err = ing.QueryExemplars(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, []client.TimeSeries{{Labels: ls, Exemplars: exemplarsAt(1, 2)}}, resp.Timeseries)
+
+	// Other tenants have none.
+	resp, err = ing.QueryExemplars(user.InjectOrgID(context.Background(), "2"), req)
+	require.NoError(t, err)
+	require.Empty(t, resp.Timeseries)
+}
@@ -3,6 +3,10 @@ package ingester
 import (
 	"fmt"
 	"net/http"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync/atomic"
 	"time"
 
 	"golang.org/x/net/context"
@@ -23,6 +27,14 @@ const (
 	// Backoff for retrying 'immediate' flushes. Only counts for queue
 	// position, not wallclock time.
 	flushBackoff = 1 * time.Second
+
+	// With -ingester.max-memory-bytes, the heap in use is checked every
+	// memoryCheckPeriod.  Once over the high watermark, the oldest chunks are
+	// flushed early, and removed from memory, until the heap should be under
+	// the low watermark.
+	memoryCheckPeriod   = 10 * time.Second
+	memoryHighWatermark = 0.9
+	memoryLowWatermark  = 0.8
 )
 
 var (
@@ -31,16 +43,18 @@ var (
 		Help:    "Distribution of stored chunk utilization (when stored).",
 		Buckets: prometheus.LinearBuckets(0, 0.2, 6),
 	})
-	chunkLength = promauto.NewHistogram(prometheus.HistogramOpts{
+	// The length, size and age of the chunks stored are labelled with the
+	// reason their series was flushed, as in cortex_ingester_flush_reasons.
+	chunkLength = promauto.NewHistogramVec(prometheus.HistogramOpts{
 		Name:    "cortex_ingester_chunk_length",
 		Help:    "Distribution of stored chunk lengths (when stored).",
 		Buckets: prometheus.ExponentialBuckets(5, 2, 11), // biggest bucket is 5*2^(11-1) = 5120
-	})
-	chunkSize = promauto.NewHistogram(prometheus.HistogramOpts{
+	}, []string{"reason"})
+	chunkSize = promauto.NewHistogramVec(prometheus.HistogramOpts{
 		Name:    "cortex_ingester_chunk_size_bytes",
 		Help:    "Distribution of stored chunk sizes (when stored).",
 		Buckets: prometheus.ExponentialBuckets(500, 2, 5), // biggest bucket is 500*2^(5-1) = 8000
-	})
+	}, []string{"reason"})
 	chunksPerUser = promauto.NewCounterVec(prometheus.CounterOpts{
 		Name: "cortex_ingester_chunks_stored_total",
 		Help: "Total stored chunks per user.",
@@ -49,13 +63,13 @@ var (
 		Name: "cortex_ingester_chunk_stored_bytes_total",
 		Help: "Total bytes stored in chunks per user.",
 	}, []string{"user"})
-	chunkAge = promauto.NewHistogram(prometheus.HistogramOpts{
+	chunkAge = promauto.NewHistogramVec(prometheus.HistogramOpts{
 		Name: "cortex_ingester_chunk_age_seconds",
 		Help: "Distribution of chunk ages (when stored).",
 		// with default settings chunks should flush between 5 min and 12 hours
 		// so buckets at 1min, 5min, 10min, 30min, 1hr, 2hr, 4hr, 10hr, 12hr, 16hr
 		Buckets: []float64{60, 300, 600, 1800, 3600, 7200, 14400, 36000, 43200, 57600},
-	})
+	}, []string{"reason"})
 	memoryChunks = promauto.NewGauge(prometheus.GaugeOpts{
 		Name: "cortex_ingester_memory_chunks",
 		Help: "The total number of chunks in memory.",
@@ -69,6 +83,13 @@ var (
 // Flush triggers a flush of all the chunks and closes the flush queues.
 // Called from the Lifecycler as part of the ingester shutdown.
 func (i *Ingester) Flush() {
+	if i.cfg.TSDBEnabled {
+		// The TSDBs' heads are kept in their WALs, for the ingester to
+		// carry on with them once restarted with the same disk.
+		i.tsdbs.ship()
+		i.tsdbs.close()
+	}
+
 	i.sweepUsers(true)
 
 	// Close the flush queues, to unblock waiting workers.
@@ -79,44 +100,204 @@ func (i *Ingester) Flush() {
 	i.flushQueuesDone.Wait()
 }
 
-// FlushHandler triggers a flush of all in memory chunks.  Mainly used for
-// local testing.
+// FlushHandler triggers a flush of all in memory chunks, e.g. before an
+// ingester is deleted without a replacement.  It responds once the chunks
+// are queued for flushing, not when they have been flushed.
 func (i *Ingester) FlushHandler(w http.ResponseWriter, r *http.Request) {
 	i.sweepUsers(true)
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// FlushSeriesHandler flushes the series of the request's user matching its
+// match[] selectors, or with drop=true removes them from memory without
+// flushing them, e.g. to evict series pushed by mistake.  It responds once
+// the series are queued for flushing, or dropped.
+func (i *Ingester) FlushSeriesHandler(w http.ResponseWriter, r *http.Request) {
+	userID, _, err := user.ExtractOrgIDFromHTTPRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(r.Form["match[]"]) == 0 {
+		http.Error(w, "no match[] parameter provided", http.StatusBadRequest)
+		return
+	}
+	matchersSet, err := parseMatchersSet(r.Form["match[]"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	drop := false
+	if s := r.FormValue("drop"); s != "" {
+		if drop, err = strconv.ParseBool(s); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	i.userStatesMtx.RLock()
+	defer i.userStatesMtx.RUnlock()
+	state, ok := i.userStates.get(userID)
+	if !ok {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	n := 0
+	for _, fp := range matchingFingerprints(state, matchersSet) {
+		state.fpLocker.Lock(fp)
+		series, ok := state.fpToSeries.get(fp)
+		if !ok {
+			state.fpLocker.Unlock(fp)
+			continue
+		}
+
+		if drop {
+			memoryChunks.Sub(float64(len(series.chunkDescs)))
+			state.removeSeries(fp, series.metric)
+		} else {
+			i.sweepSeries(userID, fp, series, true)
+		}
+		state.fpLocker.Unlock(fp)
+		n++
+	}
+
+	level.Info(util.Logger).Log("msg", "flushed series on request", "user", userID, "series", n, "dropped", drop)
+	w.WriteHeader(http.StatusNoContent)
+}
+
 type flushOp struct {
 	from      model.Time
 	userID    string
 	fp        model.Fingerprint
 	immediate bool
+	early     bool // Flushed under memory pressure.
+	reason    flushReason
 }
 
 func (o *flushOp) Key() string {
-	return fmt.Sprintf("%s-%d-%v", o.userID, o.fp, o.immediate)
+	return fmt.Sprintf("%s-%d-%v-%v", o.userID, o.fp, o.immediate, o.early)
 }
 
+// Priority orders the ops by the priority of their reason, then oldest
+// first, so that e.g. a series flushed on shutdown isn't queued behind
+// thousands of idle series.
 func (o *flushOp) Priority() int64 {
-	return -int64(o.from)
+	return o.reason.priority()<<48 - int64(o.from)
 }
 
-// sweepUsers periodically schedules series for flushing and garbage collects users with no series
+// sweepUsers periodically schedules series for flushing and garbage collects users with no series.
+// As it visits every series, it also counts the users' active series.
 func (i *Ingester) sweepUsers(immediate bool) {
 	if i.chunkStore == nil {
 		return
 	}
 
+	activeSince := model.Now().Add(-i.cfg.ActiveSeriesIdleTimeout)
 	for id, state := range i.userStates.cp() {
+		active := 0
 		for pair := range state.fpToSeries.iter() {
 			state.fpLocker.Lock(pair.fp)
+			if pair.series.lastAppend >= activeSince {
+				active++
+			}
 			i.sweepSeries(id, pair.fp, pair.series, immediate)
-			i.removeFlushedChunks(state, pair.fp, pair.series)
+			i.removeFlushedChunks(state, pair.fp, pair.series, i.cfg.RetainPeriod, model.Latest)
 			state.fpLocker.Unlock(pair.fp)
 		}
+		state.setActiveSeries(active)
 	}
 }
 
+// heapInuse returns the bytes in the heap's in-use spans, including garbage
+// not collected yet.
+func heapInuse() uint64 {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return stats.HeapInuse
+}
+
+// storeQueriedBefore returns the time before which queries also read the
+// chunk store, per -querier.query-store-after: chunks flushed early with
+// later samples are kept in memory, as queries only read them from the
+// ingesters.
+func (i *Ingester) storeQueriedBefore() model.Time {
+	if i.cfg.QueryStoreAfter == 0 {
+		return model.Latest
+	}
+	return model.Now().Add(-i.cfg.QueryStoreAfter)
+}
+
+// checkMemoryPressure queues the series with the oldest chunks to be
+// flushed early, until the sizes of their chunks add up to the heap in use
+// over the low watermark, if the heap in use is over the high watermark.  It
+// does nothing while earlier early flushes are pending.
+func (i *Ingester) checkMemoryPressure() {
+	if i.chunkStore == nil || atomic.LoadInt64(&i.earlyFlushesPending) > 0 {
+		return
+	}
+	heap := i.heapInuse()
+	if heap < uint64(float64(i.cfg.MaxMemoryBytes)*memoryHighWatermark) {
+		return
+	}
+	// Chunks removed by earlier flushes may not have been collected yet.
+	runtime.GC()
+	heap = i.heapInuse()
+	if heap < uint64(float64(i.cfg.MaxMemoryBytes)*memoryHighWatermark) {
+		return
+	}
+	excess := int64(heap - uint64(float64(i.cfg.MaxMemoryBytes)*memoryLowWatermark))
+	keepAfter := i.storeQueriedBefore()
+
+	type candidate struct {
+		op    flushOp
+		bytes int64
+	}
+	var candidates []candidate
+	for userID, state := range i.userStates.cp() {
+		for pair := range state.fpToSeries.iter() {
+			state.fpLocker.Lock(pair.fp)
+			// Only the chunks which can be removed once flushed count.
+			var bytes int64
+			for _, c := range pair.series.chunkDescs {
+				if c.LastTime.After(keepAfter) {
+					break
+				}
+				bytes += int64(c.C.Size())
+			}
+			if bytes > 0 {
+				candidates = append(candidates, candidate{
+					op:    flushOp{pair.series.firstTime(), userID, pair.fp, false, true, reasonMemoryPressure},
+					bytes: bytes,
+				})
+			}
+			state.fpLocker.Unlock(pair.fp)
+		}
+	}
+	sort.Slice(candidates, func(a, b int) bool {
+		return candidates[a].op.from < candidates[b].op.from
+	})
+
+	var queued int
+	for j := 0; j < len(candidates) && excess > 0; j++ {
+		op := candidates[j].op
+		flushQueueIndex := int(uint64(op.fp) % uint64(i.cfg.ConcurrentFlushes))
+		atomic.AddInt64(&i.earlyFlushesPending, 1)
+		if !i.flushQueues[flushQueueIndex].Enqueue(&op) {
+			atomic.AddInt64(&i.earlyFlushesPending, -1)
+			continue
+		}
+		flushReasons.WithLabelValues(flushReason(reasonMemoryPressure).String()).Inc()
+		excess -= candidates[j].bytes
+		queued++
+	}
+	level.Warn(util.Logger).Log("msg", "heap in use approaching -ingester.max-memory-bytes, flushing chunks early", "heap_inuse_bytes", heap, "series", queued)
+}
+
 type flushReason int
 
 const (
@@ -125,6 +306,7 @@ const (
 	reasonMultipleChunksInSeries
 	reasonAged
 	reasonIdle
+	reasonMemoryPressure
 )
 
 func (f flushReason) String() string {
@@ -139,11 +321,29 @@ func (f flushReason) String() string {
 		return "Aged"
 	case reasonIdle:
 		return "Idle"
+	case reasonMemoryPressure:
+		return "MemoryPressure"
 	default:
 		panic("unrecognised flushReason")
 	}
 }
 
+// priority ranks the reasons series are flushed for: series flushed on
+// shutdown or on request go first, then those flushed to relieve memory
+// pressure, then those with full or aged chunks, and idle series last.
+func (f flushReason) priority() int64 {
+	switch f {
+	case reasonImmediate:
+		return 3
+	case reasonMemoryPressure:
+		return 2
+	case reasonMultipleChunksInSeries, reasonAged:
+		return 1
+	default:
+		return 0
+	}
+}
+
 // sweepSeries schedules a series for flushing based on a set of criteria
 //
 // NB we don't close the head chunk here, as the series could wait in the queue
@@ -160,7 +360,7 @@ func (i *Ingester) sweepSeries(userID string, fp model.Fingerprint, series *memo
 	}
 
 	flushQueueIndex := int(uint64(fp) % uint64(i.cfg.ConcurrentFlushes))
-	if i.flushQueues[flushQueueIndex].Enqueue(&flushOp{firstTime, userID, fp, immediate}) {
+	if i.flushQueues[flushQueueIndex].Enqueue(&flushOp{firstTime, userID, fp, immediate, false, flush}) {
 		flushReasons.WithLabelValues(flush.String()).Inc()
 		util.Event().Log("msg", "add to flush queue", "userID", userID, "reason", flush, "firstTime", firstTime, "fp", fp, "series", series.metric, "queue", flushQueueIndex)
 	}
@@ -229,10 +429,13 @@ func (i *Ingester) flushLoop(j int) {
 		}
 		op := o.(*flushOp)
 
-		err := i.flushUserSeries(j, op.userID, op.fp, op.immediate)
+		err := i.flushUserSeries(j, op.userID, op.fp, op.immediate, op.early)
 		if err != nil {
 			level.Error(util.WithUserID(op.userID, util.Logger)).Log("msg", "failed to flush user", "err", err)
 		}
+		if op.early {
+			atomic.AddInt64(&i.earlyFlushesPending, -1)
+		}
 
 		// If we're exiting & we failed to flush, put the failed operation
 		// back in the queue at a later point.
@@ -243,7 +446,10 @@ func (i *Ingester) flushLoop(j int) {
 	}
 }
 
-func (i *Ingester) flushUserSeries(flushQueueIndex int, userID string, fp model.Fingerprint, immediate bool) error {
+// flushUserSeries flushes the series' chunks which should be flushed, or
+// with early all of them, removing them from memory once flushed rather than
+// after the retain period.
+func (i *Ingester) flushUserSeries(flushQueueIndex int, userID string, fp model.Fingerprint, immediate, early bool) error {
 	if i.preFlushUserSeries != nil {
 		i.preFlushUserSeries()
 	}
@@ -260,6 +466,9 @@ func (i *Ingester) flushUserSeries(flushQueueIndex int, userID string, fp model.
 
 	userState.fpLocker.Lock(fp)
 	reason := i.shouldFlushSeries(series, fp, immediate)
+	if reason == noFlush && early {
+		reason = reasonMemoryPressure
+	}
 	if reason == noFlush {
 		userState.fpLocker.Unlock(fp)
 		return nil
@@ -267,11 +476,18 @@ func (i *Ingester) flushUserSeries(flushQueueIndex int, userID string, fp model.
 
 	// Assume we're going to flush everything, and maybe don't flush the head chunk if it doesn't need it.
 	chunks := series.chunkDescs
-	if immediate || (len(chunks) > 0 && i.shouldFlushChunk(series.head(), fp) != noFlush) {
+	if immediate || early || (len(chunks) > 0 && i.shouldFlushChunk(series.head(), fp) != noFlush) {
 		series.closeHead()
 	} else {
 		chunks = chunks[:len(chunks)-1]
 	}
+	// Chunks flushed already are only kept in memory for the retain period.
+	for len(chunks) > 0 && chunks[0].flushed {
+		chunks = chunks[1:]
+	}
+	if len(chunks) == 0 && early {
+		i.removeFlushedChunks(userState, fp, series, 0, i.storeQueriedBefore())
+	}
 	userState.fpLocker.Unlock(fp)
 
 	if len(chunks) == 0 {
@@ -288,7 +504,7 @@ func (i *Ingester) flushUserSeries(flushQueueIndex int, userID string, fp model.
 	sp.SetTag("organization", userID)
 
 	util.Event().Log("msg", "flush chunks", "userID", userID, "reason", reason, "numChunks", len(chunks), "firstTime", chunks[0].FirstTime, "fp", fp, "series", series.metric, "queue", flushQueueIndex)
-	err := i.flushChunks(ctx, fp, series.metric, chunks)
+	err := i.flushChunks(ctx, fp, series.metric, chunks, reason)
 	if err != nil {
 		return err
 	}
@@ -300,19 +516,25 @@ func (i *Ingester) flushUserSeries(flushQueueIndex int, userID string, fp model.
 	} else {
 		for i := 0; i < len(chunks); i++ {
 			// mark the chunks as flushed, so we can remove them after the retention period
-			series.chunkDescs[i].flushed = true
-			series.chunkDescs[i].LastUpdate = model.Now()
+			chunks[i].flushed = true
+			chunks[i].LastUpdate = model.Now()
+		}
+		if early {
+			i.removeFlushedChunks(userState, fp, series, 0, i.storeQueriedBefore())
 		}
 	}
 	userState.fpLocker.Unlock(fp)
 	return nil
 }
 
+// removeFlushedChunks removes the chunks flushed more than retain ago, but
+// for those with samples after keepAfter, and the series if that leaves it
+// without chunks.
 // must be called under fpLocker lock
-func (i *Ingester) removeFlushedChunks(userState *userState, fp model.Fingerprint, series *memorySeries) {
+func (i *Ingester) removeFlushedChunks(userState *userState, fp model.Fingerprint, series *memorySeries, retain time.Duration, keepAfter model.Time) {
 	now := model.Now()
 	for len(series.chunkDescs) > 0 {
-		if series.chunkDescs[0].flushed && now.Sub(series.chunkDescs[0].LastUpdate) > i.cfg.RetainPeriod {
+		if series.chunkDescs[0].flushed && now.Sub(series.chunkDescs[0].LastUpdate) >= retain && !series.chunkDescs[0].LastTime.After(keepAfter) {
 			series.chunkDescs[0] = nil // erase reference so the chunk can be garbage-collected
 			series.chunkDescs = series.chunkDescs[1:]
 			memoryChunks.Dec()
@@ -325,7 +547,7 @@ func (i *Ingester) removeFlushedChunks(userState *userState, fp model.Fingerprin
 	}
 }
 
-func (i *Ingester) flushChunks(ctx context.Context, fp model.Fingerprint, metric labels.Labels, chunkDescs []*desc) error {
+func (i *Ingester) flushChunks(ctx context.Context, fp model.Fingerprint, metric labels.Labels, chunkDescs []*desc, reason flushReason) error {
 	userID, err := user.ExtractOrgID(ctx)
 	if err != nil {
 		return err
@@ -346,16 +568,19 @@ func (i *Ingester) flushChunks(ctx context.Context, fp model.Fingerprint, metric
 
 	sizePerUser := chunkSizePerUser.WithLabelValues(userID)
 	countPerUser := chunksPerUser.WithLabelValues(userID)
+	lengthForReason := chunkLength.WithLabelValues(reason.String())
+	sizeForReason := chunkSize.WithLabelValues(reason.String())
+	ageForReason := chunkAge.WithLabelValues(reason.String())
 	// Record statistsics only when actual put request did not return error.
 	for _, chunkDesc := range chunkDescs {
 		utilization, length, size := chunkDesc.C.Utilization(), chunkDesc.C.Len(), chunkDesc.C.Size()
 		util.Event().Log("msg", "chunk flushed", "userID", userID, "fp", fp, "series", metric, "utilization", utilization, "length", length, "size", size, "firstTime", chunkDesc.FirstTime, "lastTime", chunkDesc.LastTime)
 		chunkUtilization.Observe(utilization)
-		chunkLength.Observe(float64(length))
-		chunkSize.Observe(float64(size))
+		lengthForReason.Observe(float64(length))
+		sizeForReason.Observe(float64(size))
 		sizePerUser.Add(float64(size))
 		countPerUser.Inc()
-		chunkAge.Observe(model.Now().Sub(chunkDesc.FirstTime).Seconds())
+		ageForReason.Observe(model.Now().Sub(chunkDesc.FirstTime).Seconds())
 	}
 
 	return nil
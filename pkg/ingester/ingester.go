@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	// Needed for gRPC compatibility.
@@ -92,9 +93,24 @@ type Config struct {
 	ChunkAgeJitter    time.Duration
 	ConcurrentFlushes int
 	SpreadFlushes     bool
+	MaxMemoryBytes    uint64
 
 	RateUpdatePeriod time.Duration
 
+	MetadataRetainPeriod time.Duration
+
+	ActiveSeriesIdleTimeout time.Duration
+
+	InstanceLimits InstanceLimits `yaml:"instance_limits"`
+
+	// Set from -querier.query-store-after: chunks with samples more recent
+	// than it are kept in memory when flushed early.
+	QueryStoreAfter time.Duration `yaml:"-"`
+
+	// Set from -store.engine=blocks.
+	TSDBEnabled bool       `yaml:"-"`
+	TSDBConfig  TSDBConfig `yaml:"tsdb"`
+
 	// For testing, you can override the address and ID of this ingester.
 	ingesterClientFactory func(addr string, cfg client.Config) (client.HealthAndIngesterClient, error)
 }
@@ -112,7 +128,27 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	f.DurationVar(&cfg.ChunkAgeJitter, "ingester.chunk-age-jitter", 20*time.Minute, "Range of time to subtract from MaxChunkAge to spread out flushes")
 	f.BoolVar(&cfg.SpreadFlushes, "ingester.spread-flushes", false, "If true, spread series flushes across the whole period of MaxChunkAge")
 	f.IntVar(&cfg.ConcurrentFlushes, "ingester.concurrent-flushes", 50, "Number of concurrent goroutines flushing to dynamodb.")
+	f.Uint64Var(&cfg.MaxMemoryBytes, "ingester.max-memory-bytes", 0, "Heap size the ingester should stay under, flushing the oldest chunks early once the heap in use is over 90% of it. 0 to disable.")
 	f.DurationVar(&cfg.RateUpdatePeriod, "ingester.rate-update-period", 15*time.Second, "Period with which to update the per-user ingestion rates.")
+	f.DurationVar(&cfg.MetadataRetainPeriod, "ingester.metadata-retain-period", 10*time.Minute, "Period metric metadata is kept in memory for after it was last received.")
+	cfg.TSDBConfig.RegisterFlags(f)
+	cfg.InstanceLimits.RegisterFlags(f)
+	f.DurationVar(&cfg.ActiveSeriesIdleTimeout, "ingester.active-series-idle-timeout", 10*time.Minute, "Series which have received a sample within this period are counted as active, in cortex_ingester_active_series and the user stats. Counted every -ingester.flush-period.")
+}
+
+// InstanceLimits are limits on the whole ingester, whatever the users,
+// protecting it from overload, e.g. when the ring is unbalanced.
+type InstanceLimits struct {
+	MaxIngestionRate        float64 `yaml:"max_ingestion_rate"`
+	MaxSeries               int     `yaml:"max_series"`
+	MaxInflightPushRequests int     `yaml:"max_inflight_push_requests"`
+}
+
+// RegisterFlags adds the flags required to config this to the given FlagSet
+func (l *InstanceLimits) RegisterFlags(f *flag.FlagSet) {
+	f.Float64Var(&l.MaxIngestionRate, "ingester.instance-limits.max-ingestion-rate", 0, "Max samples per second the ingester accepts, of all users, averaged like the users' ingestion rates. Pushes are rejected with a 429 while over it. 0 to disable.")
+	f.IntVar(&l.MaxSeries, "ingester.instance-limits.max-series", 0, "Max series the ingester holds in memory, of all users. Samples for new series are rejected with a 429 once it is reached. 0 to disable.")
+	f.IntVar(&l.MaxInflightPushRequests, "ingester.instance-limits.max-inflight-push-requests", 0, "Max push requests the ingester handles at once. Pushes over it are rejected with a 429. 0 to disable.")
 }
 
 // Ingester deals with "in flight" chunks.  Based on Prometheus 1.x
@@ -125,19 +161,39 @@ type Ingester struct {
 	lifecycler *ring.Lifecycler
 	limits     *validation.Overrides
 
-	stopLock sync.RWMutex
-	stopped  bool
-	quit     chan struct{}
-	done     sync.WaitGroup
+	stopLock     sync.RWMutex
+	stopped      bool
+	quit         chan struct{}
+	done         sync.WaitGroup
+	shutdownOnce sync.Once
 
 	userStatesMtx sync.RWMutex
 	userStates    *userStates
 
+	// For the instance limits.
+	ingestionRate        *ewmaRate
+	inflightPushRequests int64
+
+	// For the users' -ingester.ingestion-rate-limit.
+	ingestLimiters ingestLimiters
+
+	exemplars *exemplarStores
+	metadata  *metadataStores
+
+	// The users' TSDBs, with -store.engine=blocks.
+	tsdbs *userTSDBs
+
 	// One queue per flush thread.  Fingerprint is used to
 	// pick a queue.
 	flushQueues     []*util.PriorityQueue
 	flushQueuesDone sync.WaitGroup
 
+	// The number of series queued for flushing under memory pressure.
+	earlyFlushesPending int64
+
+	// Returns the heap in use, for -ingester.max-memory-bytes.
+	heapInuse func() uint64
+
 	// Hook for injecting behaviour from tests.
 	preFlushUserSeries func()
 }
@@ -160,13 +216,24 @@ func New(cfg Config, clientConfig client.Config, limits *validation.Overrides, c
 		limits:     limits,
 		chunkStore: chunkStore,
 		userStates: newUserStates(limits, cfg),
+		exemplars:  newExemplarStores(),
+		metadata:   newMetadataStores(),
+
+		ingestionRate: newEWMARate(0.2, cfg.RateUpdatePeriod),
 
 		quit:        make(chan struct{}),
 		flushQueues: make([]*util.PriorityQueue, cfg.ConcurrentFlushes, cfg.ConcurrentFlushes),
+		heapInuse:   heapInuse,
 	}
 
 	var err error
-	i.lifecycler, err = ring.NewLifecycler(cfg.LifecyclerConfig, i, "ingester")
+	if cfg.TSDBEnabled {
+		if i.tsdbs, err = openUserTSDBs(cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	i.lifecycler, err = ring.NewLifecycler(cfg.LifecyclerConfig, i, "ingester", ring.ConsulKey)
 	if err != nil {
 		return nil, err
 	}
@@ -180,6 +247,11 @@ func New(cfg Config, clientConfig client.Config, limits *validation.Overrides, c
 	i.done.Add(1)
 	go i.loop()
 
+	if cfg.TSDBEnabled {
+		i.done.Add(1)
+		go i.shipLoop()
+	}
+
 	return i, nil
 }
 
@@ -192,13 +264,49 @@ func (i *Ingester) loop() {
 	rateUpdateTicker := time.NewTicker(i.cfg.RateUpdatePeriod)
 	defer rateUpdateTicker.Stop()
 
+	var memoryC <-chan time.Time
+	if i.cfg.MaxMemoryBytes > 0 {
+		memoryTicker := time.NewTicker(memoryCheckPeriod)
+		defer memoryTicker.Stop()
+		memoryC = memoryTicker.C
+	}
+
 	for {
 		select {
 		case <-flushTicker.C:
 			i.sweepUsers(false)
+			i.metadata.purge(time.Now().Add(-i.cfg.MetadataRetainPeriod))
+			i.ingestLimiters.purge(time.Now().Add(-ingestLimiterIdlePeriod))
 
 		case <-rateUpdateTicker.C:
+			i.ingestionRate.tick()
 			i.userStates.updateRates()
+			if i.cfg.TSDBEnabled {
+				i.tsdbs.updateRates()
+			}
+
+		case <-memoryC:
+			i.checkMemoryPressure()
+
+		case <-i.quit:
+			return
+		}
+	}
+}
+
+// shipLoop ships the TSDBs' blocks on its own goroutine, so that slow uploads
+// don't hold up the ingester's other periodic work.
+func (i *Ingester) shipLoop() {
+	defer i.done.Done()
+
+	shipTicker := time.NewTicker(i.cfg.TSDBConfig.ShipInterval)
+	defer shipTicker.Stop()
+
+	for {
+		select {
+		case <-shipTicker.C:
+			i.tsdbs.ship()
+			i.tsdbs.recountSeries()
 
 		case <-i.quit:
 			return
@@ -206,14 +314,49 @@ func (i *Ingester) loop() {
 	}
 }
 
-// Shutdown beings the process to stop this ingester.
+// Shutdown beings the process to stop this ingester.  It can be called more
+// than once, e.g. by the /shutdown endpoint and then on exit; later calls
+// wait for the first to finish.
 func (i *Ingester) Shutdown() {
-	// First wait for our flush loop to stop.
-	close(i.quit)
-	i.done.Wait()
+	i.shutdownOnce.Do(func() {
+		// First wait for our flush loop to stop.
+		close(i.quit)
+		i.done.Wait()
+
+		// Next initiate our graceful exit from the ring.
+		i.lifecycler.Shutdown()
+	})
+}
+
+// ShutdownHandler shuts the ingester down, handing its chunks over or
+// flushing them and leaving the ring, so an operator can drain an ingester
+// before stopping it.  It responds once the ingester has left the ring.
+func (i *Ingester) ShutdownHandler(w http.ResponseWriter, r *http.Request) {
+	i.Shutdown()
+	w.WriteHeader(http.StatusNoContent)
+}
 
-	// Next initiate our graceful exit from the ring.
-	i.lifecycler.Shutdown()
+// ReadOnlyHandler switches the ingester to the READONLY state on POST, in
+// which distributors stop writing to it while it still answers queries and
+// flushes its chunks, e.g. to decommission it once its chunks are flushed, and
+// back to ACTIVE on DELETE.  It responds with the ingester's state.
+func (i *Ingester) ReadOnlyHandler(w http.ResponseWriter, r *http.Request) {
+	var err error
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost, http.MethodPut:
+		err = i.lifecycler.ChangeState(r.Context(), ring.READONLY)
+	case http.MethodDelete:
+		err = i.lifecycler.ChangeState(r.Context(), ring.ACTIVE)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	fmt.Fprintln(w, i.lifecycler.GetState())
 }
 
 // StopIncomingRequests is called during the shutdown process.
@@ -224,12 +367,48 @@ func (i *Ingester) StopIncomingRequests() {
 }
 
 // Push implements client.IngesterServer
+//
+// Read-only ingesters still accept pushes, from the distributors which haven't
+// seen their state yet: the distributors which have write the samples' extra
+// replica to the next ingester in the ring instead.
 func (i *Ingester) Push(ctx old_ctx.Context, req *client.WriteRequest) (*client.WriteResponse, error) {
-	var lastPartialErr error
+	// The series' labels are copied when they are created, so nothing
+	// references the request once it has been pushed.
+	defer client.ReuseSlice(req.Timeseries)
+
+	inflight := atomic.AddInt64(&i.inflightPushRequests, 1)
+	defer atomic.AddInt64(&i.inflightPushRequests, -1)
+	if err := i.checkInstanceLimits(inflight); err != nil {
+		return nil, err
+	}
+	if err := i.checkIngestionRate(ctx, req); err != nil {
+		return nil, err
+	}
+
+	if i.cfg.TSDBEnabled {
+		return i.v2Push(ctx, req)
+	}
+
+	userID, err := user.ExtractOrgID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("no user id")
+	}
+
+	var pushErrs pushErrors
+
+	if len(req.Metadata) > 0 {
+		i.appendMetadata(userID, req.Metadata)
+	}
 
 	for _, ts := range req.Timeseries {
+		// The distributors don't forward native histograms, but pushes sent to
+		// ingesters directly may have them.
+		i.limits.DiscardHistograms(userID, ts.Histograms)
+
+		ls := labelPairs(ts.Labels)
+		ls.removeBlanks()
 		for _, s := range ts.Samples {
-			err := i.append(ctx, ts.Labels, model.Time(s.TimestampMs), model.SampleValue(s.Value), req.Source)
+			err := i.append(ctx, ls, model.Time(s.TimestampMs), model.SampleValue(s.Value), req.Source)
 			if err == nil {
 				continue
 			}
@@ -238,16 +417,34 @@ func (i *Ingester) Push(ctx old_ctx.Context, req *client.WriteRequest) (*client.
 			if httpResp, ok := httpgrpc.HTTPResponseFromError(err); ok {
 				switch httpResp.Code {
 				case http.StatusBadRequest, http.StatusTooManyRequests:
-					lastPartialErr = err
+					pushErrs.add(ls, s.TimestampMs, err)
 					continue
 				}
 			}
 
 			return nil, err
 		}
+
+		if len(ts.Exemplars) > 0 {
+			state, ok := i.userStates.get(userID)
+			i.appendExemplars(userID, ls, ts.Exemplars, ok && state.hasSeries(ls))
+		}
 	}
 
-	return &client.WriteResponse{}, lastPartialErr
+	return &client.WriteResponse{}, pushErrs.err()
+}
+
+// checkInstanceLimits returns an error if a push, with inflight pushes
+// being handled, would exceed the instance limits.
+func (i *Ingester) checkInstanceLimits(inflight int64) error {
+	limits := i.cfg.InstanceLimits
+	if limits.MaxInflightPushRequests > 0 && inflight > int64(limits.MaxInflightPushRequests) {
+		return httpgrpc.Errorf(http.StatusTooManyRequests, "ingester's limit of inflight push requests (%d) exceeded", limits.MaxInflightPushRequests)
+	}
+	if limits.MaxIngestionRate > 0 && i.ingestionRate.rate() > limits.MaxIngestionRate {
+		return httpgrpc.Errorf(http.StatusTooManyRequests, "ingester's ingestion rate limit (%v samples/s) exceeded", limits.MaxIngestionRate)
+	}
+	return nil
 }
 
 func (i *Ingester) append(ctx context.Context, labels labelPairs, timestamp model.Time, value model.SampleValue, source client.WriteRequest_SourceEnum) error {
@@ -273,17 +470,18 @@ func (i *Ingester) append(ctx context.Context, labels labelPairs, timestamp mode
 	if err := series.add(model.SamplePair{
 		Value:     value,
 		Timestamp: timestamp,
-	}); err != nil {
+	}, i.limits.DuplicateSamplePolicy(state.userID)); err != nil {
 		if mse, ok := err.(*memorySeriesError); ok {
 			validation.DiscardedSamples.WithLabelValues(mse.errorType, state.userID).Inc()
 			// Use a dumb string template to avoid the message being parsed as a template
-			err = httpgrpc.Errorf(http.StatusBadRequest, "%s", mse.message)
+			err = newSampleError(http.StatusBadRequest, mse.errorType, "%s", mse.message)
 		}
 		return err
 	}
 
 	memoryChunks.Add(float64(len(series.chunkDescs) - prevNumChunks))
 	ingestedSamples.Inc()
+	i.ingestionRate.inc()
 	switch source {
 	case client.RULE:
 		state.ingestedRuleSamples.inc()
@@ -296,8 +494,53 @@ func (i *Ingester) append(ctx context.Context, labels labelPairs, timestamp mode
 	return err
 }
 
+// checkIngestionRate returns an error if the push would exceed this
+// ingester's share of its user's ingestion rate limit.  The distributors
+// enforce the users' ingestion rates too; this guards against misconfigured
+// distributors and pushes sent to the ingester directly.  It isn't enforced
+// until the ingester has seen a healthy ingester in the ring.
+func (i *Ingester) checkIngestionRate(ctx context.Context, req *client.WriteRequest) error {
+	userID, err := user.ExtractOrgID(ctx)
+	if err != nil {
+		return fmt.Errorf("no user id")
+	}
+	factor := i.limits.IngesterIngestionRateFactor(userID)
+	ingesters := i.lifecycler.HealthyInstancesCount()
+	if factor <= 0 || ingesters == 0 {
+		return nil
+	}
+	limit := factor * ingestionRateShare(i.limits.IngestionRate(userID), i.cfg.LifecyclerConfig.RingConfig.ReplicationFactor, i.limits.IngestionTenantShardSize(userID), ingesters)
+
+	numSamples := 0
+	for _, ts := range req.Timeseries {
+		numSamples += len(ts.Samples)
+	}
+	if !i.ingestLimiters.allowN(userID, limit, i.limits.IngestionBurstSize(userID), numSamples) {
+		validation.DiscardedSamples.WithLabelValues(validation.RateLimited, userID).Add(float64(numSamples))
+		return httpgrpc.Errorf(http.StatusTooManyRequests, "ingester's per-user ingestion rate limit (%v samples/s) exceeded while adding %d samples", limit, numSamples)
+	}
+	return nil
+}
+
+// ingestionRateShare returns the share of a user's ingestion rate limit an
+// ingester receives: each sample is sent to replicationFactor of the user's
+// ingesters, all of the healthy ones unless the user is sharded over fewer.
+func ingestionRateShare(limit float64, replicationFactor, shardSize, ingesters int) float64 {
+	if shardSize > 0 && shardSize < ingesters {
+		ingesters = shardSize
+	}
+	if ingesters < replicationFactor {
+		ingesters = replicationFactor
+	}
+	return limit * float64(replicationFactor) / float64(ingesters)
+}
+
 // Query implements service.IngesterServer
 func (i *Ingester) Query(ctx old_ctx.Context, req *client.QueryRequest) (*client.QueryResponse, error) {
+	if i.cfg.TSDBEnabled {
+		return i.v2Query(ctx, req)
+	}
+
 	userID, err := user.ExtractOrgID(ctx)
 	if err != nil {
 		return nil, err
@@ -357,6 +600,10 @@ func (i *Ingester) Query(ctx old_ctx.Context, req *client.QueryRequest) (*client
 
 // QueryStream implements service.IngesterServer
 func (i *Ingester) QueryStream(req *client.QueryRequest, stream client.Ingester_QueryStreamServer) error {
+	if i.cfg.TSDBEnabled {
+		return i.v2QueryStream(req, stream)
+	}
+
 	log, ctx := spanlogger.New(stream.Context(), "QueryStream")
 
 	from, through, matchers, err := client.FromQueryRequest(req)
@@ -376,6 +623,7 @@ func (i *Ingester) QueryStream(req *client.QueryRequest, stream client.Ingester_
 	}
 
 	numSeries, numChunks := 0, 0
+	maxChunksPerQuery := i.limits.MaxChunksPerQuery(state.userID)
 	batch := make([]client.TimeSeriesChunk, 0, queryStreamBatchSize)
 	// We'd really like to have series in label order, not FP order, so we
 	// can iteratively merge them with entries coming from the chunk store.  But
@@ -393,13 +641,19 @@ func (i *Ingester) QueryStream(req *client.QueryRequest, stream client.Ingester_
 			return nil
 		}
 
+		// Check the limit before encoding the chunks, for a query over it to
+		// be aborted without copying any more of them.
+		numChunks += len(chunks)
+		if maxChunksPerQuery > 0 && numChunks > maxChunksPerQuery {
+			return httpgrpc.Errorf(http.StatusRequestEntityTooLarge, "exceeded maximum number of chunks in a query (%d)", maxChunksPerQuery)
+		}
+
 		numSeries++
 		wireChunks, err := toWireChunks(chunks)
 		if err != nil {
 			return err
 		}
 
-		numChunks += len(wireChunks)
 		batch = append(batch, client.TimeSeriesChunk{
 			Labels: client.FromLabelsToLabelAdapaters(series.metric),
 			Chunks: wireChunks,
@@ -429,6 +683,10 @@ func (i *Ingester) QueryStream(req *client.QueryRequest, stream client.Ingester_
 
 // LabelValues returns all label values that are associated with a given label name.
 func (i *Ingester) LabelValues(ctx old_ctx.Context, req *client.LabelValuesRequest) (*client.LabelValuesResponse, error) {
+	if i.cfg.TSDBEnabled {
+		return i.v2LabelValues(ctx, req)
+	}
+
 	i.userStatesMtx.RLock()
 	defer i.userStatesMtx.RUnlock()
 	state, ok, err := i.userStates.getViaContext(ctx)
@@ -448,6 +706,10 @@ func (i *Ingester) LabelValues(ctx old_ctx.Context, req *client.LabelValuesReque
 
 // LabelNames return all the label names.
 func (i *Ingester) LabelNames(ctx old_ctx.Context, req *client.LabelNamesRequest) (*client.LabelNamesResponse, error) {
+	if i.cfg.TSDBEnabled {
+		return i.v2LabelNames(ctx, req)
+	}
+
 	i.userStatesMtx.RLock()
 	defer i.userStatesMtx.RUnlock()
 	state, ok, err := i.userStates.getViaContext(ctx)
@@ -465,8 +727,66 @@ func (i *Ingester) LabelNames(ctx old_ctx.Context, req *client.LabelNamesRequest
 	return resp, nil
 }
 
+func (i *Ingester) appendMetadata(userID string, metadata []*client.MetricMetadata) {
+	maxPerUser := i.limits.MaxMetadataPerUser(userID)
+	if maxPerUser <= 0 {
+		discardedMetadata.Add(float64(len(metadata)))
+		return
+	}
+	maxPerMetric := i.limits.MaxMetadataPerMetric(userID)
+
+	store := i.metadata.get(userID, true)
+	now := time.Now()
+	for _, m := range metadata {
+		if m != nil {
+			store.add(*m, now, maxPerUser, maxPerMetric)
+		}
+	}
+}
+
+// MetricsMetadata returns the metadata of the user's metrics received within
+// the retain period.
+func (i *Ingester) MetricsMetadata(ctx old_ctx.Context, req *client.MetricsMetadataRequest) (*client.MetricsMetadataResponse, error) {
+	userID, err := user.ExtractOrgID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	store := i.metadata.get(userID, false)
+	if store == nil {
+		return &client.MetricsMetadataResponse{}, nil
+	}
+	return &client.MetricsMetadataResponse{Metadata: store.list()}, nil
+}
+
+// QueryExemplars returns the exemplars of the series matching any of the sets
+// of matchers in the request's time range.
+func (i *Ingester) QueryExemplars(ctx old_ctx.Context, req *client.ExemplarQueryRequest) (*client.ExemplarQueryResponse, error) {
+	userID, err := user.ExtractOrgID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	from, through, matchersSet, err := client.FromExemplarQueryRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	store := i.exemplars.get(userID, false)
+	if store == nil {
+		return &client.ExemplarQueryResponse{}, nil
+	}
+	return &client.ExemplarQueryResponse{
+		Timeseries: store.query(from, through, matchersSet),
+	}, nil
+}
+
 // MetricsForLabelMatchers returns all the metrics which match a set of matchers.
 func (i *Ingester) MetricsForLabelMatchers(ctx old_ctx.Context, req *client.MetricsForLabelMatchersRequest) (*client.MetricsForLabelMatchersResponse, error) {
+	if i.cfg.TSDBEnabled {
+		return i.v2MetricsForLabelMatchers(ctx, req)
+	}
+
 	i.userStatesMtx.RLock()
 	defer i.userStatesMtx.RUnlock()
 	state, ok, err := i.userStates.getViaContext(ctx)
@@ -506,6 +826,10 @@ func (i *Ingester) MetricsForLabelMatchers(ctx old_ctx.Context, req *client.Metr
 
 // UserStats returns ingestion statistics for the current user.
 func (i *Ingester) UserStats(ctx old_ctx.Context, req *client.UserStatsRequest) (*client.UserStatsResponse, error) {
+	if i.cfg.TSDBEnabled {
+		return i.v2UserStats(ctx, req)
+	}
+
 	i.userStatesMtx.RLock()
 	defer i.userStatesMtx.RUnlock()
 	state, ok, err := i.userStates.getViaContext(ctx)
@@ -522,11 +846,16 @@ func (i *Ingester) UserStats(ctx old_ctx.Context, req *client.UserStatsRequest)
 		ApiIngestionRate:  apiRate,
 		RuleIngestionRate: ruleRate,
 		NumSeries:         uint64(state.fpToSeries.length()),
+		ActiveSeries:      state.getActiveSeries(),
 	}, nil
 }
 
 // AllUserStats returns ingestion statistics for all users known to this ingester.
 func (i *Ingester) AllUserStats(ctx old_ctx.Context, req *client.UserStatsRequest) (*client.UsersStatsResponse, error) {
+	if i.cfg.TSDBEnabled {
+		return i.v2AllUserStats(ctx, req)
+	}
+
 	i.userStatesMtx.RLock()
 	defer i.userStatesMtx.RUnlock()
 	users := i.userStates.cp()
@@ -544,6 +873,7 @@ func (i *Ingester) AllUserStats(ctx old_ctx.Context, req *client.UserStatsReques
 				ApiIngestionRate:  apiRate,
 				RuleIngestionRate: ruleRate,
 				NumSeries:         uint64(state.fpToSeries.length()),
+				ActiveSeries:      state.getActiveSeries(),
 			},
 		})
 	}
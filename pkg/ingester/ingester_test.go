@@ -3,11 +3,14 @@ package ingester
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
 	"math"
 	"net/http"
+	"os"
 	"sort"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -16,13 +19,18 @@ import (
 	net_context "golang.org/x/net/context"
 	"google.golang.org/grpc"
 
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/pkg/labels"
 
 	"github.com/cortexproject/cortex/pkg/chunk"
+	"github.com/cortexproject/cortex/pkg/chunk/encoding"
 	"github.com/cortexproject/cortex/pkg/ingester/client"
+	"github.com/cortexproject/cortex/pkg/querier/sharding"
 	"github.com/cortexproject/cortex/pkg/util"
 	"github.com/cortexproject/cortex/pkg/util/chunkcompat"
+	"github.com/cortexproject/cortex/pkg/util/test"
 	"github.com/cortexproject/cortex/pkg/util/validation"
 	"github.com/weaveworks/common/httpgrpc"
 	"github.com/weaveworks/common/user"
@@ -216,6 +224,66 @@ func TestIngesterSendsOnlySeriesWithData(t *testing.T) {
 	ing.Shutdown()
 }
 
+func TestIngesterQueryShard(t *testing.T) {
+	_, ing := newDefaultTestStore(t)
+	userIDs, testData := pushTestSamples(t, ing, 10, 10)
+	ctx := user.InjectOrgID(context.Background(), userIDs[0])
+
+	// Each series is returned for exactly one of the shards.
+	seen := map[string]int{}
+	for i := 0; i < 3; i++ {
+		shard := sharding.Shard{Index: i, Of: 3}
+		matcher, err := labels.NewMatcher(labels.MatchRegexp, model.JobLabel, ".+")
+		require.NoError(t, err)
+		req, err := client.ToQueryRequest(model.Earliest, model.Latest, []*labels.Matcher{matcher, shard.Matcher()})
+		require.NoError(t, err)
+		resp, err := ing.Query(ctx, req)
+		require.NoError(t, err)
+
+		for _, ss := range client.FromQueryResponse(resp) {
+			ls := client.FromLabelAdaptersToLabels(client.FromMetricsToLabelAdapters(ss.Metric))
+			require.True(t, shard.Contains(ls), ls.String())
+			seen[ss.Metric.String()]++
+		}
+	}
+	require.Len(t, seen, len(testData[userIDs[0]]))
+	for metric, n := range seen {
+		require.Equal(t, 1, n, metric)
+	}
+
+	// Malformed shard matchers are rejected.
+	matcher, err := labels.NewMatcher(labels.MatchRegexp, sharding.ShardLabel, ".+")
+	require.NoError(t, err)
+	req, err := client.ToQueryRequest(model.Earliest, model.Latest, []*labels.Matcher{matcher})
+	require.NoError(t, err)
+	_, err = ing.Query(ctx, req)
+	resp, ok := httpgrpc.HTTPResponseFromError(err)
+	require.True(t, ok, err)
+	require.Equal(t, int32(http.StatusBadRequest), resp.Code)
+}
+
+func TestIngesterChunksPerQueryLimitExceeded(t *testing.T) {
+	limits := defaultLimitsTestConfig()
+	limits.MaxChunksPerQuery = 5
+	_, ing := newTestStore(t, defaultIngesterTestConfig(), defaultClientTestConfig(), limits)
+	defer ing.Shutdown()
+
+	userIDs, _ := pushTestSamples(t, ing, 10, 10)
+
+	for _, userID := range userIDs {
+		ctx := user.InjectOrgID(context.Background(), userID)
+		_, req, err := runTestQuery(ctx, t, ing, labels.MatchRegexp, model.JobLabel, ".+")
+		require.NoError(t, err)
+
+		s := stream{ctx: ctx}
+		err = ing.QueryStream(req, &s)
+		resp, ok := httpgrpc.HTTPResponseFromError(err)
+		require.True(t, ok, "expected an HTTP error, got %v", err)
+		require.Equal(t, int32(http.StatusRequestEntityTooLarge), resp.Code)
+		require.Equal(t, "exceeded maximum number of chunks in a query (5)", string(resp.Body))
+	}
+}
+
 func TestIngesterIdleFlush(t *testing.T) {
 	// Create test ingester with short flush cycle
 	cfg := defaultIngesterTestConfig()
@@ -251,6 +319,94 @@ func TestIngesterIdleFlush(t *testing.T) {
 	}
 }
 
+func TestIngesterMemoryPressureFlush(t *testing.T) {
+	cfg := defaultIngesterTestConfig()
+	cfg.MaxMemoryBytes = 100
+	store, ing := newTestStore(t, cfg, defaultClientTestConfig(), defaultLimitsTestConfig())
+	defer ing.Shutdown()
+
+	ctx := user.InjectOrgID(context.Background(), userID)
+	var samples []model.Sample
+	for j, name := range []string{"oldest", "older", "newest"} {
+		samples = append(samples, model.Sample{Metric: model.Metric{model.MetricNameLabel: model.LabelValue(name)}, Timestamp: model.Time(j), Value: 1})
+	}
+	_, err := ing.Push(ctx, client.ToWriteRequest(samples, client.API))
+	require.NoError(t, err)
+
+	// Nothing is flushed under the high watermark.
+	ing.heapInuse = func() uint64 { return 80 }
+	ing.checkMemoryPressure()
+	require.Equal(t, int64(0), atomic.LoadInt64(&ing.earlyFlushesPending))
+
+	// Over it, flushing the oldest series' chunk should free enough memory.
+	ing.heapInuse = func() uint64 { return 90 }
+	ing.checkMemoryPressure()
+	test.Poll(t, time.Second, int64(0), func() interface{} {
+		return atomic.LoadInt64(&ing.earlyFlushesPending)
+	})
+
+	store.mtx.Lock()
+	require.Len(t, store.chunks[userID], 1)
+	require.Equal(t, "oldest", store.chunks[userID][0].Metric.Get(model.MetricNameLabel))
+	store.mtx.Unlock()
+
+	// The chunk is observed with the reason its series was flushed.
+	var m dto.Metric
+	require.NoError(t, chunkSize.WithLabelValues("MemoryPressure").(prometheus.Histogram).Write(&m))
+	require.Equal(t, uint64(1), m.GetHistogram().GetSampleCount())
+
+	res, _, err := runTestQuery(ctx, t, ing, labels.MatchRegexp, model.MetricNameLabel, ".+")
+	require.NoError(t, err)
+	require.Len(t, res, 2)
+	for _, ss := range res {
+		require.NotEqual(t, model.LabelValue("oldest"), ss.Metric[model.MetricNameLabel])
+	}
+}
+
+func TestIngesterMemoryPressureFlushQueryStoreAfter(t *testing.T) {
+	cfg := defaultIngesterTestConfig()
+	cfg.MaxMemoryBytes = 100
+	cfg.QueryStoreAfter = time.Hour
+	store, ing := newTestStore(t, cfg, defaultClientTestConfig(), defaultLimitsTestConfig())
+	defer ing.Shutdown()
+
+	// Queries only read the ingesters for the chunks' samples, so they can't
+	// be removed from memory, and aren't flushed early.
+	ctx := user.InjectOrgID(context.Background(), userID)
+	_, err := ing.Push(ctx, client.ToWriteRequest([]model.Sample{
+		{Metric: model.Metric{model.MetricNameLabel: "recent"}, Timestamp: model.Now(), Value: 1},
+	}, client.API))
+	require.NoError(t, err)
+
+	ing.heapInuse = func() uint64 { return 90 }
+	ing.checkMemoryPressure()
+	require.Equal(t, int64(0), atomic.LoadInt64(&ing.earlyFlushesPending))
+
+	store.mtx.Lock()
+	require.Empty(t, store.chunks[userID])
+	store.mtx.Unlock()
+
+	res, _, err := runTestQuery(ctx, t, ing, labels.MatchEqual, model.MetricNameLabel, "recent")
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+}
+
+func TestFlushOpPriority(t *testing.T) {
+	q := util.NewPriorityQueue(nil)
+	for j := 0; j < 100; j++ {
+		q.Enqueue(&flushOp{from: model.Time(j), userID: userID, fp: model.Fingerprint(j), reason: reasonIdle})
+	}
+	q.Enqueue(&flushOp{from: 1000, userID: userID, fp: 1000, reason: reasonAged})
+	q.Enqueue(&flushOp{from: 2000, userID: userID, fp: 2000, reason: reasonMemoryPressure})
+	q.Enqueue(&flushOp{from: 3000, userID: userID, fp: 3000, immediate: true, reason: reasonImmediate})
+	q.Enqueue(&flushOp{from: 4000, userID: userID, fp: 4000, immediate: true, reason: reasonImmediate})
+
+	// By reason, then oldest first.
+	for _, expected := range []model.Fingerprint{3000, 4000, 2000, 1000, 0, 1} {
+		require.Equal(t, expected, q.Dequeue().(*flushOp).fp)
+	}
+}
+
 type stream struct {
 	grpc.ServerStream
 	ctx       context.Context
@@ -296,6 +452,166 @@ func TestIngesterAppendOutOfOrderAndDuplicate(t *testing.T) {
 	require.Equal(t, errResp.Code, int32(400))
 }
 
+func TestIngesterAppendDuplicatePolicy(t *testing.T) {
+	for _, tc := range []struct {
+		policy string
+		value  model.SampleValue
+	}{
+		{validation.DuplicateSampleKeepFirst, 0},
+		{validation.DuplicateSampleKeepLast, 2},
+	} {
+		t.Run(tc.policy, func(t *testing.T) {
+			limits := defaultLimitsTestConfig()
+			limits.DuplicateSamplePolicy = tc.policy
+			_, ing := newTestStore(t, defaultIngesterTestConfig(), defaultClientTestConfig(), limits)
+			defer ing.Shutdown()
+
+			m := labelPairs{
+				{Name: model.MetricNameLabel, Value: "testmetric"},
+			}
+			ctx := user.InjectOrgID(context.Background(), userID)
+			require.NoError(t, ing.append(ctx, m, 1, 0, client.API))
+			require.NoError(t, ing.append(ctx, m, 2, 0, client.API))
+			require.NoError(t, ing.append(ctx, m, 2, 1, client.API))
+			require.NoError(t, ing.append(ctx, m, 2, 2, client.API))
+
+			// Out of order samples are still rejected.
+			err := ing.append(ctx, m, 0, 0, client.API)
+			require.Contains(t, err.Error(), "sample timestamp out of order")
+
+			res, _, err := runTestQuery(ctx, t, ing, labels.MatchEqual, model.MetricNameLabel, "testmetric")
+			require.NoError(t, err)
+			require.Len(t, res, 1)
+			assert.Equal(t, []model.SamplePair{{Timestamp: 1, Value: 0}, {Timestamp: 2, Value: tc.value}}, res[0].Values)
+		})
+	}
+
+	// The last sample can't be replaced once its chunk is closed.
+	limits := defaultLimitsTestConfig()
+	limits.DuplicateSamplePolicy = validation.DuplicateSampleKeepLast
+	_, ing := newTestStore(t, defaultIngesterTestConfig(), defaultClientTestConfig(), limits)
+	defer ing.Shutdown()
+
+	m := labelPairs{
+		{Name: model.MetricNameLabel, Value: "testmetric"},
+	}
+	ctx := user.InjectOrgID(context.Background(), userID)
+	require.NoError(t, ing.append(ctx, m, 1, 0, client.API))
+	state, _, _ := ing.userStates.getViaContext(ctx)
+	for pair := range state.fpToSeries.iter() {
+		state.fpLocker.Lock(pair.fp)
+		pair.series.closeHead()
+		state.fpLocker.Unlock(pair.fp)
+	}
+	err := ing.append(ctx, m, 1, 1, client.API)
+	require.Contains(t, err.Error(), "sample with repeated timestamp but different value")
+}
+
+func TestMemorySeriesReplaceLastKeepsEncoding(t *testing.T) {
+	defer func(enc encoding.Encoding) { encoding.DefaultEncoding = enc }(encoding.DefaultEncoding)
+	encoding.DefaultEncoding = encoding.Varbit
+
+	s := newMemorySeries(labels.Labels{{Name: model.MetricNameLabel, Value: "testmetric"}})
+	require.NoError(t, s.add(model.SamplePair{Timestamp: 1, Value: 0}, validation.DuplicateSampleKeepLast))
+	require.NoError(t, s.add(model.SamplePair{Timestamp: 2, Value: 0}, validation.DuplicateSampleKeepLast))
+
+	// The chunk encoding may have changed since the head chunk was created.
+	encoding.DefaultEncoding = encoding.Bigchunk
+	require.NoError(t, s.add(model.SamplePair{Timestamp: 2, Value: 1}, validation.DuplicateSampleKeepLast))
+	require.Len(t, s.chunkDescs, 1)
+	require.Equal(t, encoding.Varbit, s.head().C.Encoding())
+
+	var values []model.SamplePair
+	iter := s.head().C.NewIterator()
+	for iter.Scan() {
+		values = append(values, iter.Value())
+	}
+	require.NoError(t, iter.Err())
+	require.Equal(t, []model.SamplePair{{Timestamp: 1, Value: 0}, {Timestamp: 2, Value: 1}}, values)
+}
+
+func TestIngesterValidateLabels(t *testing.T) {
+	for _, tsdb := range []bool{false, true} {
+		t.Run(fmt.Sprintf("tsdb=%v", tsdb), func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "tsdb")
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+
+			cfg := defaultIngesterTestConfig()
+			cfg.TSDBEnabled = tsdb
+			cfg.TSDBConfig.Dir = dir
+			limits := defaultLimitsTestConfig()
+			limits.MaxLabelValueLength = 10
+			limits.MaxLabelNamesPerSeries = 2
+			_, ing := newTestStore(t, cfg, defaultClientTestConfig(), limits)
+			defer ing.Shutdown()
+
+			ctx := user.InjectOrgID(context.Background(), userID)
+			for _, metric := range []model.Metric{
+				{model.MetricNameLabel: "testmetric", "foo": "a value longer than the limit"},
+				{model.MetricNameLabel: "testmetric", "foo": "bar", "baz": "qux"},
+			} {
+				_, err := ing.Push(ctx, client.ToWriteRequest([]model.Sample{{Metric: metric, Timestamp: 1, Value: 0}}, client.API))
+				errResp, ok := httpgrpc.HTTPResponseFromError(err)
+				require.True(t, ok)
+				assert.Equal(t, int32(http.StatusBadRequest), errResp.Code)
+			}
+
+			_, err = ing.Push(ctx, client.ToWriteRequest([]model.Sample{{Metric: model.Metric{model.MetricNameLabel: "testmetric", "foo": "bar"}, Timestamp: 1, Value: 0}}, client.API))
+			require.NoError(t, err)
+			stats, err := ing.UserStats(ctx, &client.UserStatsRequest{})
+			require.NoError(t, err)
+			assert.Equal(t, uint64(1), stats.NumSeries)
+		})
+	}
+}
+
+func TestIngesterPushErrors(t *testing.T) {
+	limits := defaultLimitsTestConfig()
+	limits.MaxSeriesPerUser = 1
+	_, ing := newTestStore(t, defaultIngesterTestConfig(), defaultClientTestConfig(), limits)
+	defer ing.Shutdown()
+
+	metric := model.Metric{model.MetricNameLabel: "testmetric"}
+	ctx := user.InjectOrgID(context.Background(), userID)
+	_, err := ing.Push(ctx, client.ToWriteRequest([]model.Sample{{Metric: metric, Timestamp: 2, Value: 0}}, client.API))
+	require.NoError(t, err)
+
+	// An out of order sample, a duplicate sample and a new series over the
+	// series limit are all listed, with the last's error returned.
+	_, err = ing.Push(ctx, client.ToWriteRequest([]model.Sample{
+		{Metric: metric, Timestamp: 1, Value: 0},
+		{Metric: metric, Timestamp: 2, Value: 1},
+		{Metric: metric, Timestamp: 3, Value: 0},
+		{Metric: model.Metric{model.MetricNameLabel: "testmetric", "foo": "bar"}, Timestamp: 3, Value: 0},
+	}, client.API))
+	resp, ok := httpgrpc.HTTPResponseFromError(err)
+	require.True(t, ok)
+	require.Equal(t, int32(http.StatusTooManyRequests), resp.Code)
+	require.Equal(t, `per-user series limit (1) exceeded for testmetric: testmetric{foo="bar"}`, string(resp.Body))
+
+	errs, err := client.PushErrorsFromHTTPResponse(resp)
+	require.NoError(t, err)
+	require.NotNil(t, errs)
+	require.Equal(t, int64(3), errs.RejectedSamples)
+	require.Len(t, errs.Errors, 3)
+	for i, expected := range []struct {
+		timestampMs int64
+		code        int32
+		reason      string
+	}{
+		{1, http.StatusBadRequest, "sample-out-of-order"},
+		{2, http.StatusBadRequest, "new-value-for-timestamp"},
+		{3, http.StatusTooManyRequests, perUserSeriesLimit},
+	} {
+		require.Equal(t, expected.timestampMs, errs.Errors[i].TimestampMs)
+		require.Equal(t, expected.code, errs.Errors[i].Code)
+		require.Equal(t, expected.reason, errs.Errors[i].Reason)
+	}
+	require.Equal(t, "testmetric", client.FromLabelAdaptersToLabels(errs.Errors[2].Labels).Get(model.MetricNameLabel))
+	require.Equal(t, "bar", client.FromLabelAdaptersToLabels(errs.Errors[2].Labels).Get("foo"))
+}
+
 // Test that blank labels are removed by the ingester
 func TestIngesterAppendBlankLabel(t *testing.T) {
 	_, ing := newDefaultTestStore(t)
@@ -356,9 +672,11 @@ func TestIngesterUserSeriesLimitExceeded(t *testing.T) {
 
 	// Append to two series, expect series-exceeded error.
 	_, err = ing.Push(ctx, client.ToWriteRequest([]model.Sample{sample2, sample3}, client.API))
-	if resp, ok := httpgrpc.HTTPResponseFromError(err); !ok || resp.Code != http.StatusTooManyRequests {
+	resp, ok := httpgrpc.HTTPResponseFromError(err)
+	if !ok || resp.Code != http.StatusTooManyRequests {
 		t.Fatalf("expected error about exceeding metrics per user, got %v", err)
 	}
+	require.Equal(t, `per-user series limit (1) exceeded for testmetric: testmetric{foo="biz"}`, string(resp.Body))
 
 	// Read samples back via ingester queries.
 	res, _, err := runTestQuery(ctx, t, ing, labels.MatchEqual, model.MetricNameLabel, "testmetric")
@@ -414,9 +732,11 @@ func TestIngesterMetricSeriesLimitExceeded(t *testing.T) {
 
 	// Append to two series, expect series-exceeded error.
 	_, err = ing.Push(ctx, client.ToWriteRequest([]model.Sample{sample2, sample3}, client.API))
-	if resp, ok := httpgrpc.HTTPResponseFromError(err); !ok || resp.Code != http.StatusTooManyRequests {
+	resp, ok := httpgrpc.HTTPResponseFromError(err)
+	if !ok || resp.Code != http.StatusTooManyRequests {
 		t.Fatalf("expected error about exceeding series per metric, got %v", err)
 	}
+	require.Equal(t, `per-metric series limit (1) exceeded for testmetric: testmetric{foo="biz"}`, string(resp.Body))
 
 	// Read samples back via ingester queries.
 	res, _, err := runTestQuery(ctx, t, ing, labels.MatchEqual, model.MetricNameLabel, "testmetric")
@@ -441,6 +761,136 @@ func TestIngesterMetricSeriesLimitExceeded(t *testing.T) {
 	assert.Equal(t, expected, res)
 }
 
+func TestIngesterActiveSeries(t *testing.T) {
+	cfg := defaultIngesterTestConfig()
+	cfg.ActiveSeriesIdleTimeout = 100 * time.Millisecond
+	_, ing := newTestStore(t, cfg, defaultClientTestConfig(), defaultLimitsTestConfig())
+	defer ing.Shutdown()
+
+	ctx := user.InjectOrgID(context.Background(), userID)
+	_, err := ing.Push(ctx, client.ToWriteRequest([]model.Sample{
+		{Metric: model.Metric{model.MetricNameLabel: "testmetric", "foo": "bar"}, Timestamp: 0, Value: 1},
+		{Metric: model.Metric{model.MetricNameLabel: "testmetric", "foo": "biz"}, Timestamp: 0, Value: 1},
+	}, client.API))
+	require.NoError(t, err)
+
+	// Active series are counted by the sweeps of the series.
+	ing.sweepUsers(false)
+	stats, err := ing.UserStats(ctx, &client.UserStatsRequest{})
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), stats.NumSeries)
+	require.Equal(t, uint64(2), stats.ActiveSeries)
+
+	// Series idle for longer than the timeout are still in memory, but aren't
+	// active anymore.
+	time.Sleep(2 * cfg.ActiveSeriesIdleTimeout)
+	_, err = ing.Push(ctx, client.ToWriteRequest([]model.Sample{
+		{Metric: model.Metric{model.MetricNameLabel: "testmetric", "foo": "bar"}, Timestamp: 1, Value: 2},
+	}, client.API))
+	require.NoError(t, err)
+	ing.sweepUsers(false)
+	stats, err = ing.UserStats(ctx, &client.UserStatsRequest{})
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), stats.NumSeries)
+	require.Equal(t, uint64(1), stats.ActiveSeries)
+}
+
+func TestIngesterIngestionRateLimit(t *testing.T) {
+	limits := defaultLimitsTestConfig()
+	limits.IngestionRate = 1
+	limits.IngestionBurstSize = 2
+	limits.IngesterIngestionRateFactor = 1
+	_, ing := newTestStore(t, defaultIngesterTestConfig(), defaultClientTestConfig(), limits)
+	defer ing.Shutdown()
+
+	// The limit is enforced once the ingester has joined the ring.
+	test.Poll(t, time.Second, 1, func() interface{} {
+		return ing.lifecycler.HealthyInstancesCount()
+	})
+
+	metric := model.Metric{model.MetricNameLabel: "testmetric"}
+	ctx := user.InjectOrgID(context.Background(), userID)
+	_, err := ing.Push(ctx, client.ToWriteRequest([]model.Sample{
+		{Metric: metric, Timestamp: 1, Value: 0},
+		{Metric: metric, Timestamp: 2, Value: 0},
+	}, client.API))
+	require.NoError(t, err)
+
+	_, err = ing.Push(ctx, client.ToWriteRequest([]model.Sample{{Metric: metric, Timestamp: 3, Value: 0}}, client.API))
+	errResp, ok := httpgrpc.HTTPResponseFromError(err)
+	require.True(t, ok)
+	assert.Equal(t, int32(http.StatusTooManyRequests), errResp.Code)
+
+	// Other users have their own limit.
+	_, err = ing.Push(user.InjectOrgID(context.Background(), "2"), client.ToWriteRequest([]model.Sample{{Metric: metric, Timestamp: 3, Value: 0}}, client.API))
+	require.NoError(t, err)
+}
+
+func TestIngestionRateShare(t *testing.T) {
+	for _, tc := range []struct {
+		shardSize, ingesters int
+		expected             float64
+	}{
+		{0, 30, 100},
+		{0, 2, 1000},
+		{10, 30, 300},
+		{10, 5, 600},
+	} {
+		assert.Equal(t, tc.expected, ingestionRateShare(1000, 3, tc.shardSize, tc.ingesters), "%+v", tc)
+	}
+}
+
+func TestIngestLimitersPurge(t *testing.T) {
+	var l ingestLimiters
+	require.True(t, l.allowN("1", 1, 1, 1))
+	require.False(t, l.allowN("1", 1, 1, 1))
+
+	l.purge(time.Now().Add(-time.Minute))
+	require.Len(t, l.limiters, 1)
+
+	// Purged limiters start again with a full bucket.
+	l.purge(time.Now().Add(time.Minute))
+	require.Empty(t, l.limiters)
+	require.True(t, l.allowN("1", 1, 1, 1))
+}
+
+func TestIngesterInstanceLimits(t *testing.T) {
+	cfg := defaultIngesterTestConfig()
+	cfg.InstanceLimits = InstanceLimits{
+		MaxIngestionRate:        10,
+		MaxSeries:               1,
+		MaxInflightPushRequests: 1,
+	}
+	_, ing := newTestStore(t, cfg, defaultClientTestConfig(), defaultLimitsTestConfig())
+	defer ing.Shutdown()
+
+	// The series limit is shared by all users.
+	_, err := ing.Push(user.InjectOrgID(context.Background(), "1"), client.ToWriteRequest([]model.Sample{
+		{Metric: model.Metric{model.MetricNameLabel: "testmetric", "foo": "bar"}, Timestamp: 0, Value: 1},
+	}, client.API))
+	require.NoError(t, err)
+	_, err = ing.Push(user.InjectOrgID(context.Background(), "2"), client.ToWriteRequest([]model.Sample{
+		{Metric: model.Metric{model.MetricNameLabel: "testmetric", "foo": "biz"}, Timestamp: 0, Value: 1},
+	}, client.API))
+	resp, ok := httpgrpc.HTTPResponseFromError(err)
+	require.True(t, ok)
+	require.Equal(t, int32(http.StatusTooManyRequests), resp.Code)
+	require.Equal(t, `ingester's series limit (1) exceeded for testmetric: testmetric{foo="biz"}`, string(resp.Body))
+
+	require.Error(t, ing.checkInstanceLimits(2))
+	require.NoError(t, ing.checkInstanceLimits(1))
+
+	// Pushes are rejected while the ingestion rate is over the limit.
+	for j := 0; j < 20*int(cfg.RateUpdatePeriod.Seconds()); j++ {
+		ing.ingestionRate.inc()
+	}
+	ing.ingestionRate.tick()
+	_, err = ing.Push(user.InjectOrgID(context.Background(), "1"), client.ToWriteRequest([]model.Sample{
+		{Metric: model.Metric{model.MetricNameLabel: "testmetric", "foo": "bar"}, Timestamp: 1, Value: 1},
+	}, client.API))
+	require.Equal(t, httpgrpc.Errorf(http.StatusTooManyRequests, "ingester's ingestion rate limit (10 samples/s) exceeded"), err)
+}
+
 func BenchmarkIngesterSeriesCreationLocking(b *testing.B) {
 	for i := 1; i <= 32; i++ {
 		b.Run(strconv.Itoa(i), func(b *testing.B) {
@@ -532,3 +982,48 @@ func BenchmarkIngesterPush(b *testing.B) {
 		ing.Shutdown()
 	}
 }
+
+func TestIngesterDiscardsNativeHistograms(t *testing.T) {
+	for _, tsdb := range []bool{false, true} {
+		t.Run(fmt.Sprintf("tsdb=%v", tsdb), func(t *testing.T) {
+			cfg := defaultIngesterTestConfig()
+			if tsdb {
+				dir, err := ioutil.TempDir("", "tsdb")
+				require.NoError(t, err)
+				defer os.RemoveAll(dir)
+				cfg.TSDBEnabled = true
+				cfg.TSDBConfig.Dir = dir
+			}
+			_, ing := newTestStore(t, cfg, defaultClientTestConfig(), defaultLimitsTestConfig())
+			defer ing.Shutdown()
+
+			discarded := func() float64 {
+				var m dto.Metric
+				require.NoError(t, validation.DiscardedSamples.WithLabelValues("native_histogram", userID).Write(&m))
+				return m.GetCounter().GetValue()
+			}
+			before := discarded()
+
+			ctx := user.InjectOrgID(context.Background(), userID)
+			_, err := ing.Push(ctx, &client.WriteRequest{
+				Timeseries: []client.PreallocTimeseries{{
+					TimeSeries: client.TimeSeries{
+						Labels:     []client.LabelAdapter{{Name: model.MetricNameLabel, Value: "foo"}},
+						Samples:    []client.Sample{{TimestampMs: 1, Value: 1}},
+						Histograms: []client.Histogram{{Count: &client.Histogram_CountInt{CountInt: 1}, Sum: 1, Timestamp: 2}},
+					},
+				}},
+			})
+			require.NoError(t, err)
+
+			// The histogram samples are discarded and counted.
+			require.Equal(t, float64(1), discarded()-before)
+
+			// The series' float samples are still ingested.
+			res, _, err := runTestQuery(ctx, t, ing, labels.MatchEqual, model.MetricNameLabel, "foo")
+			require.NoError(t, err)
+			require.Len(t, res, 1)
+			require.Equal(t, []model.SamplePair{{Timestamp: 1, Value: 1}}, res[0].Values)
+		})
+	}
+}
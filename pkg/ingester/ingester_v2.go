@@ -0,0 +1,439 @@
+package ingester
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+
+	// Needed for gRPC compatibility.
+	old_ctx "golang.org/x/net/context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/storage"
+
+	"github.com/cortexproject/cortex/pkg/chunk/encoding"
+	"github.com/cortexproject/cortex/pkg/ingester/client"
+	"github.com/cortexproject/cortex/pkg/querier/sharding"
+	"github.com/cortexproject/cortex/pkg/util/validation"
+	"github.com/weaveworks/common/httpgrpc"
+	"github.com/weaveworks/common/user"
+)
+
+// Reason to discard samples too old for the head of the TSDB.
+const sampleOutOfBounds = "sample_out_of_bounds"
+
+var blocksShipped = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "cortex_ingester_tsdb_blocks_shipped_total",
+	Help: "The total number of TSDB blocks shipped to the bucket.",
+})
+
+// The methods below implement the ingester with -store.engine=blocks,
+// keeping each user's samples in a TSDB rather than in memory chunks.
+
+func (i *Ingester) v2Push(ctx old_ctx.Context, req *client.WriteRequest) (*client.WriteResponse, error) {
+	userID, err := user.ExtractOrgID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("no user id")
+	}
+
+	i.stopLock.RLock()
+	defer i.stopLock.RUnlock()
+	if i.stopped {
+		return nil, fmt.Errorf("ingester stopping")
+	}
+
+	db, err := i.tsdbs.getOrCreate(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Metadata isn't stored in the TSDB, but alongside it as with the
+	// chunks storage.
+	if len(req.Metadata) > 0 {
+		i.appendMetadata(userID, req.Metadata)
+	}
+
+	var pushErrs pushErrors
+	app, err := db.adapter.Appender()
+	if err != nil {
+		return nil, err
+	}
+	for _, ts := range req.Timeseries {
+		i.limits.DiscardHistograms(userID, ts.Histograms)
+
+		// The TSDB keeps new series' labels, so they are copied out of the
+		// request, and sorted as it expects them.
+		ls := labelPairs(ts.Labels)
+		ls.removeBlanks()
+		// As with the chunks storage, the labels are validated for series
+		// pushed to ingesters directly.
+		if err := i.limits.ValidateLabels(userID, ls); err != nil {
+			ingestedSamplesFail.Add(float64(len(ts.Samples)))
+			for _, s := range ts.Samples {
+				pushErrs.add(ls, s.TimestampMs, err)
+			}
+			i.appendExemplars(userID, ls, ts.Exemplars, false)
+			continue
+		}
+		lset := copyLabels(ls)
+		sort.Sort(lset)
+		if len(ts.Samples) > 0 {
+			if err := db.addSeries(userID, lset, i.limits); err != nil {
+				ingestedSamplesFail.Add(float64(len(ts.Samples)))
+				for _, s := range ts.Samples {
+					pushErrs.add(ls, s.TimestampMs, err)
+				}
+				i.appendExemplars(userID, ls, ts.Exemplars, false)
+				continue
+			}
+		}
+		for _, s := range ts.Samples {
+			_, err := app.Add(lset, s.TimestampMs, s.Value)
+			// The TSDB can't replace samples, so only keep-first is honoured.
+			if err == storage.ErrDuplicateSampleForTimestamp && i.limits.DuplicateSamplePolicy(userID) == validation.DuplicateSampleKeepFirst {
+				continue
+			}
+			if err == nil {
+				ingestedSamples.Inc()
+				i.ingestionRate.inc()
+				switch req.Source {
+				case client.RULE:
+					db.ingestedRuleSamples.inc()
+				default:
+					db.ingestedAPISamples.inc()
+				}
+				continue
+			}
+
+			ingestedSamplesFail.Inc()
+			var reason string
+			switch err {
+			case storage.ErrOutOfOrderSample:
+				reason = outOfOrderTimestamp
+			case storage.ErrDuplicateSampleForTimestamp:
+				reason = duplicateSample
+			case storage.ErrOutOfBounds:
+				reason = sampleOutOfBounds
+			default:
+				app.Rollback()
+				return nil, err
+			}
+			validation.DiscardedSamples.WithLabelValues(reason, userID).Inc()
+			pushErrs.add(ls, s.TimestampMs, newSampleError(http.StatusBadRequest, reason, "%s for series %s, timestamp %s", err, lset, model.Time(s.TimestampMs).Time().UTC()))
+		}
+
+		// Exemplars aren't stored in the TSDB either.
+		i.appendExemplars(userID, ls, ts.Exemplars, db.hasSeries(lset))
+	}
+	if err := app.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &client.WriteResponse{}, pushErrs.err()
+}
+
+// v2Select calls f with each series of the user's TSDB between from and
+// through matching the matchers.  It does nothing if the user has no TSDB.
+func (i *Ingester) v2Select(ctx context.Context, from, through model.Time, matchers []*labels.Matcher, f func(storage.Series) error) error {
+	userID, err := user.ExtractOrgID(ctx)
+	if err != nil {
+		return err
+	}
+	db, ok := i.tsdbs.get(userID)
+	if !ok {
+		return nil
+	}
+
+	shard, matchers, err := sharding.FromMatchers(matchers)
+	if err != nil {
+		return httpgrpc.Errorf(http.StatusBadRequest, err.Error())
+	}
+	q, err := db.adapter.Querier(ctx, int64(from), int64(through))
+	if err != nil {
+		return err
+	}
+	defer q.Close()
+
+	ss, _, err := q.Select(nil, matchers...)
+	if err != nil {
+		return err
+	}
+	numSeries, maxSeriesPerQuery := 0, i.limits.MaxSeriesPerQuery(userID)
+	for ss.Next() {
+		if shard != nil && !shard.Contains(ss.At().Labels()) {
+			continue
+		}
+		numSeries++
+		if numSeries > maxSeriesPerQuery {
+			return httpgrpc.Errorf(http.StatusRequestEntityTooLarge, "exceeded maximum number of series in a query")
+		}
+		if err := f(ss.At()); err != nil {
+			return err
+		}
+	}
+	return ss.Err()
+}
+
+func (i *Ingester) v2Query(ctx old_ctx.Context, req *client.QueryRequest) (*client.QueryResponse, error) {
+	userID, err := user.ExtractOrgID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	from, through, matchers, err := client.FromQueryRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	queries.Inc()
+
+	result := &client.QueryResponse{}
+	numSeries, numSamples := 0, 0
+	maxSamplesPerQuery := i.limits.MaxSamplesPerQuery(userID)
+	err = i.v2Select(ctx, from, through, matchers, func(series storage.Series) error {
+		ts := client.TimeSeries{
+			Labels: client.FromLabelsToLabelAdapaters(series.Labels()),
+		}
+		it := series.Iterator()
+		for it.Next() {
+			numSamples++
+			if numSamples > maxSamplesPerQuery {
+				return httpgrpc.Errorf(http.StatusRequestEntityTooLarge, "exceeded maximum number of samples in a query (%d)", maxSamplesPerQuery)
+			}
+			t, v := it.At()
+			ts.Samples = append(ts.Samples, client.Sample{
+				Value:       v,
+				TimestampMs: t,
+			})
+		}
+		if err := it.Err(); err != nil {
+			return err
+		}
+		if len(ts.Samples) == 0 {
+			return nil
+		}
+		numSeries++
+		result.Timeseries = append(result.Timeseries, ts)
+		return nil
+	})
+	queriedSeries.Observe(float64(numSeries))
+	queriedSamples.Observe(float64(numSamples))
+	return result, err
+}
+
+func (i *Ingester) v2QueryStream(req *client.QueryRequest, stream client.Ingester_QueryStreamServer) error {
+	ctx := stream.Context()
+	userID, err := user.ExtractOrgID(ctx)
+	if err != nil {
+		return err
+	}
+
+	from, through, matchers, err := client.FromQueryRequest(req)
+	if err != nil {
+		return err
+	}
+
+	queries.Inc()
+
+	numSeries, numChunks := 0, 0
+	maxChunksPerQuery := i.limits.MaxChunksPerQuery(userID)
+	batch := make([]client.TimeSeriesChunk, 0, queryStreamBatchSize)
+	err = i.v2Select(ctx, from, through, matchers, func(series storage.Series) error {
+		// The TSDB's chunks are re-encoded as Cortex chunks, which is all
+		// the queriers can read.
+		chunks, err := toChunks(series.Iterator())
+		if err != nil {
+			return err
+		}
+		if len(chunks) == 0 {
+			return nil
+		}
+
+		numChunks += len(chunks)
+		if maxChunksPerQuery > 0 && numChunks > maxChunksPerQuery {
+			return httpgrpc.Errorf(http.StatusRequestEntityTooLarge, "exceeded maximum number of chunks in a query (%d)", maxChunksPerQuery)
+		}
+
+		numSeries++
+		wireChunks, err := toWireChunks(chunks)
+		if err != nil {
+			return err
+		}
+		batch = append(batch, client.TimeSeriesChunk{
+			Labels: client.FromLabelsToLabelAdapaters(series.Labels()),
+			Chunks: wireChunks,
+		})
+
+		if len(batch) < queryStreamBatchSize {
+			return nil
+		}
+		err = stream.Send(&client.QueryStreamResponse{
+			Timeseries: batch,
+		})
+		batch = batch[:0]
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if len(batch) > 0 {
+		if err := stream.Send(&client.QueryStreamResponse{
+			Timeseries: batch,
+		}); err != nil {
+			return err
+		}
+	}
+
+	queriedSeries.Observe(float64(numSeries))
+	queriedChunks.Observe(float64(numChunks))
+	return nil
+}
+
+// toChunks encodes a series' samples in chunks of the default encoding.
+func toChunks(it storage.SeriesIterator) ([]*desc, error) {
+	var descs []*desc
+	for it.Next() {
+		t, v := it.At()
+		sample := model.SamplePair{Timestamp: model.Time(t), Value: model.SampleValue(v)}
+		if len(descs) == 0 {
+			descs = append(descs, newDesc(encoding.New(), sample.Timestamp, sample.Timestamp))
+		}
+
+		head := descs[len(descs)-1]
+		chunks, err := head.add(sample)
+		if err != nil {
+			return nil, err
+		}
+		if len(chunks) == 1 {
+			head.C = chunks[0]
+			continue
+		}
+		descs = descs[:len(descs)-1]
+		for _, c := range chunks {
+			first, last, err := firstAndLastTimes(c)
+			if err != nil {
+				return nil, err
+			}
+			descs = append(descs, newDesc(c, first, last))
+		}
+	}
+	return descs, it.Err()
+}
+
+// v2Querier returns a querier over all of the user's TSDB, or nil if the
+// user has none.
+func (i *Ingester) v2Querier(ctx context.Context) (storage.Querier, error) {
+	userID, err := user.ExtractOrgID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	db, ok := i.tsdbs.get(userID)
+	if !ok {
+		return nil, nil
+	}
+	return db.adapter.Querier(ctx, math.MinInt64, math.MaxInt64)
+}
+
+func (i *Ingester) v2LabelValues(ctx old_ctx.Context, req *client.LabelValuesRequest) (*client.LabelValuesResponse, error) {
+	q, err := i.v2Querier(ctx)
+	if err != nil || q == nil {
+		return &client.LabelValuesResponse{}, err
+	}
+	defer q.Close()
+
+	values, err := q.LabelValues(req.LabelName)
+	if err != nil {
+		return nil, err
+	}
+	return &client.LabelValuesResponse{LabelValues: values}, nil
+}
+
+func (i *Ingester) v2LabelNames(ctx old_ctx.Context, req *client.LabelNamesRequest) (*client.LabelNamesResponse, error) {
+	q, err := i.v2Querier(ctx)
+	if err != nil || q == nil {
+		return &client.LabelNamesResponse{}, err
+	}
+	defer q.Close()
+
+	names, err := q.LabelNames()
+	if err != nil {
+		return nil, err
+	}
+	return &client.LabelNamesResponse{LabelNames: names}, nil
+}
+
+func (i *Ingester) v2MetricsForLabelMatchers(ctx old_ctx.Context, req *client.MetricsForLabelMatchersRequest) (*client.MetricsForLabelMatchersResponse, error) {
+	from, through, matchersSet, err := client.FromMetricsForLabelMatchersRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	lss := map[model.Fingerprint]labels.Labels{}
+	for _, matchers := range matchersSet {
+		if err := i.v2Select(ctx, from, through, matchers, func(series storage.Series) error {
+			ls := series.Labels()
+			lss[client.Fingerprint(ls)] = ls
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &client.MetricsForLabelMatchersResponse{
+		Metric: make([]*client.Metric, 0, len(lss)),
+	}
+	for _, ls := range lss {
+		result.Metric = append(result.Metric, &client.Metric{Labels: client.FromLabelsToLabelAdapaters(ls)})
+	}
+	return result, nil
+}
+
+func v2UserStats(db *userTSDB) (*client.UserStatsResponse, error) {
+	numSeries, err := db.numSeries()
+	if err != nil {
+		return nil, err
+	}
+	apiRate := db.ingestedAPISamples.rate()
+	ruleRate := db.ingestedRuleSamples.rate()
+	return &client.UserStatsResponse{
+		IngestionRate:     apiRate + ruleRate,
+		ApiIngestionRate:  apiRate,
+		RuleIngestionRate: ruleRate,
+		NumSeries:         numSeries,
+	}, nil
+}
+
+func (i *Ingester) v2UserStats(ctx old_ctx.Context, req *client.UserStatsRequest) (*client.UserStatsResponse, error) {
+	userID, err := user.ExtractOrgID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	db, ok := i.tsdbs.get(userID)
+	if !ok {
+		return &client.UserStatsResponse{}, nil
+	}
+	return v2UserStats(db)
+}
+
+func (i *Ingester) v2AllUserStats(ctx old_ctx.Context, req *client.UserStatsRequest) (*client.UsersStatsResponse, error) {
+	dbs := i.tsdbs.cp()
+	response := &client.UsersStatsResponse{
+		Stats: make([]*client.UserIDStatsResponse, 0, len(dbs)),
+	}
+	for userID, db := range dbs {
+		stats, err := v2UserStats(db)
+		if err != nil {
+			return nil, err
+		}
+		response.Stats = append(response.Stats, &client.UserIDStatsResponse{
+			UserId: userID,
+			Data:   stats,
+		})
+	}
+	return response, nil
+}
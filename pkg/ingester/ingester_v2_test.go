@@ -0,0 +1,258 @@
+package ingester
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/httpgrpc"
+	"github.com/weaveworks/common/user"
+
+	"github.com/cortexproject/cortex/pkg/ingester/client"
+	"github.com/cortexproject/cortex/pkg/util/chunkcompat"
+	"github.com/cortexproject/cortex/pkg/util/validation"
+)
+
+func newTestV2Store(t *testing.T, dir string) *Ingester {
+	cfg := defaultIngesterTestConfig()
+	cfg.TSDBEnabled = true
+	cfg.TSDBConfig.Dir = dir
+	_, ing := newTestStore(t, cfg, defaultClientTestConfig(), defaultLimitsTestConfig())
+	return ing
+}
+
+func TestIngesterV2Append(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tsdb")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ing := newTestV2Store(t, dir)
+	userIDs, testData := pushTestSamples(t, ing, 10, 1000)
+
+	for _, userID := range userIDs {
+		ctx := user.InjectOrgID(context.Background(), userID)
+		res, req, err := runTestQuery(ctx, t, ing, labels.MatchRegexp, model.JobLabel, ".+")
+		require.NoError(t, err)
+		assert.Equal(t, testData[userID], res)
+
+		s := stream{
+			ctx: ctx,
+		}
+		err = ing.QueryStream(req, &s)
+		require.NoError(t, err)
+		res, err = chunkcompat.StreamsToMatrix(model.Earliest, model.Latest, s.responses)
+		require.NoError(t, err)
+		assert.Equal(t, testData[userID].String(), res.String())
+
+		values, err := ing.LabelValues(ctx, &client.LabelValuesRequest{LabelName: model.JobLabel})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"testjob0", "testjob1"}, values.LabelValues)
+
+		stats, err := ing.UserStats(ctx, &client.UserStatsRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, uint64(10), stats.NumSeries)
+	}
+
+	// The samples are read back from the WALs once restarted.
+	ing.Shutdown()
+	ing = newTestV2Store(t, dir)
+	defer ing.Shutdown()
+	for _, userID := range userIDs {
+		ctx := user.InjectOrgID(context.Background(), userID)
+		res, _, err := runTestQuery(ctx, t, ing, labels.MatchRegexp, model.JobLabel, ".+")
+		require.NoError(t, err)
+		assert.Equal(t, testData[userID], res)
+	}
+}
+
+func TestIngesterV2AppendOutOfOrder(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tsdb")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ing := newTestV2Store(t, dir)
+	defer ing.Shutdown()
+
+	ctx := user.InjectOrgID(context.Background(), userID)
+	metric := model.Metric{model.MetricNameLabel: "testmetric"}
+	_, err = ing.Push(ctx, client.ToWriteRequest([]model.Sample{{Metric: metric, Timestamp: 2, Value: 0}}, client.API))
+	require.NoError(t, err)
+
+	_, err = ing.Push(ctx, client.ToWriteRequest([]model.Sample{{Metric: metric, Timestamp: 1, Value: 0}}, client.API))
+	errResp, ok := httpgrpc.HTTPResponseFromError(err)
+	require.True(t, ok)
+	assert.Equal(t, int32(400), errResp.Code)
+	assert.Contains(t, string(errResp.Body), "out of order sample")
+}
+
+func TestIngesterV2AppendDuplicateKeepFirst(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tsdb")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	cfg := defaultIngesterTestConfig()
+	cfg.TSDBEnabled = true
+	cfg.TSDBConfig.Dir = dir
+	limits := defaultLimitsTestConfig()
+	limits.DuplicateSamplePolicy = validation.DuplicateSampleKeepFirst
+	_, ing := newTestStore(t, cfg, defaultClientTestConfig(), limits)
+	defer ing.Shutdown()
+
+	ctx := user.InjectOrgID(context.Background(), userID)
+	metric := model.Metric{model.MetricNameLabel: "testmetric"}
+	_, err = ing.Push(ctx, client.ToWriteRequest([]model.Sample{{Metric: metric, Timestamp: 1, Value: 0}}, client.API))
+	require.NoError(t, err)
+	_, err = ing.Push(ctx, client.ToWriteRequest([]model.Sample{{Metric: metric, Timestamp: 1, Value: 1}}, client.API))
+	require.NoError(t, err)
+
+	res, _, err := runTestQuery(ctx, t, ing, labels.MatchEqual, model.MetricNameLabel, "testmetric")
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	assert.Equal(t, []model.SamplePair{{Timestamp: 1, Value: 0}}, res[0].Values)
+}
+
+func TestIngesterV2InvalidUserID(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tsdb")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ing := newTestV2Store(t, dir)
+	defer ing.Shutdown()
+
+	metric := model.Metric{model.MetricNameLabel: "testmetric"}
+	for _, userID := range []string{".", "..", "../user", "a/b", `a\b`} {
+		ctx := user.InjectOrgID(context.Background(), userID)
+		_, err := ing.Push(ctx, client.ToWriteRequest([]model.Sample{{Metric: metric, Timestamp: 1, Value: 0}}, client.API))
+		errResp, ok := httpgrpc.HTTPResponseFromError(err)
+		require.True(t, ok, userID)
+		assert.Equal(t, int32(400), errResp.Code, userID)
+	}
+	infos, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, infos)
+}
+
+func TestIngesterV2SeriesLimits(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tsdb")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	cfg := defaultIngesterTestConfig()
+	cfg.TSDBEnabled = true
+	cfg.TSDBConfig.Dir = dir
+	limits := defaultLimitsTestConfig()
+	limits.MaxSeriesPerUser = 2
+	limits.MaxSeriesPerMetric = 1
+	_, ing := newTestStore(t, cfg, defaultClientTestConfig(), limits)
+
+	ctx := user.InjectOrgID(context.Background(), userID)
+	push := func(metric model.Metric) error {
+		_, err := ing.Push(ctx, client.ToWriteRequest([]model.Sample{{Metric: metric, Timestamp: 1, Value: 0}}, client.API))
+		return err
+	}
+	require.NoError(t, push(model.Metric{model.MetricNameLabel: "foo", "a": "1"}))
+	// Samples of series already in the head are accepted.
+	require.NoError(t, push(model.Metric{model.MetricNameLabel: "foo", "a": "1"}))
+
+	err = push(model.Metric{model.MetricNameLabel: "foo", "a": "2"})
+	errResp, ok := httpgrpc.HTTPResponseFromError(err)
+	require.True(t, ok)
+	assert.Equal(t, int32(429), errResp.Code)
+	assert.Contains(t, string(errResp.Body), "per-metric series limit (1) exceeded")
+
+	require.NoError(t, push(model.Metric{model.MetricNameLabel: "bar"}))
+	err = push(model.Metric{model.MetricNameLabel: "baz"})
+	errResp, ok = httpgrpc.HTTPResponseFromError(err)
+	require.True(t, ok)
+	assert.Equal(t, int32(429), errResp.Code)
+	assert.Contains(t, string(errResp.Body), "per-user series limit (2) exceeded")
+
+	// The series replayed from the WAL count against the limits too.
+	ing.Shutdown()
+	_, ing = newTestStore(t, cfg, defaultClientTestConfig(), limits)
+	defer ing.Shutdown()
+	err = push(model.Metric{model.MetricNameLabel: "baz"})
+	errResp, ok = httpgrpc.HTTPResponseFromError(err)
+	require.True(t, ok)
+	assert.Equal(t, int32(429), errResp.Code)
+}
+
+// recordingBucket records the names of the files uploaded, in order, with
+// their contents.
+type recordingBucket struct {
+	names []string
+	files map[string]string
+}
+
+func (b *recordingBucket) upload(_ context.Context, name string, r io.ReadSeeker) error {
+	buf, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	b.names = append(b.names, name)
+	b.files[name] = string(buf)
+	return nil
+}
+
+func TestShipBlocks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tsdb")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for _, file := range []string{
+		"block1/meta.json",
+		"block1/index",
+		"block1/chunks/000001",
+		"block2.tmp/meta.json",
+		"block3/index",
+		"wal/000000",
+	} {
+		path := filepath.Join(dir, file)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, ioutil.WriteFile(path, []byte(file), 0644))
+	}
+
+	bucket := &recordingBucket{files: map[string]string{}}
+	require.NoError(t, shipBlocks(context.Background(), dir, "user", bucket))
+	assert.Equal(t, map[string]string{
+		"user/block1/meta.json":     "block1/meta.json",
+		"user/block1/index":         "block1/index",
+		"user/block1/chunks/000001": "block1/chunks/000001",
+	}, bucket.files)
+	// The meta file is uploaded last.
+	require.Len(t, bucket.names, 3)
+	assert.Equal(t, "user/block1/meta.json", bucket.names[2])
+
+	// Blocks are only shipped once.
+	bucket = &recordingBucket{files: map[string]string{}}
+	require.NoError(t, shipBlocks(context.Background(), dir, "user", bucket))
+	assert.Empty(t, bucket.names)
+}
+
+func TestDirBucket(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bucket")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	u, err := url.Parse("file://" + dir)
+	require.NoError(t, err)
+	bucket, err := newBucketClient(u)
+	require.NoError(t, err)
+	require.NoError(t, bucket.upload(context.Background(), "user/block1/chunks/000001", strings.NewReader("chunks")))
+
+	buf, err := ioutil.ReadFile(filepath.Join(dir, "user", "block1", "chunks", "000001"))
+	require.NoError(t, err)
+	assert.Equal(t, "chunks", string(buf))
+	infos, err := ioutil.ReadDir(filepath.Join(dir, "user", "block1", "chunks"))
+	require.NoError(t, err)
+	assert.Len(t, infos, 1)
+}
@@ -1,8 +1,11 @@
 package ingester
 
 import (
+	"encoding/json"
 	"io"
 	"math"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -339,3 +342,162 @@ func TestIngesterFlush(t *testing.T) {
 		},
 	}, res)
 }
+
+func TestIngesterShutdownHandler(t *testing.T) {
+	store, ing := newDefaultTestStore(t)
+
+	test.Poll(t, 100*time.Millisecond, ring.ACTIVE, func() interface{} {
+		return ing.lifecycler.GetState()
+	})
+
+	ctx := user.InjectOrgID(context.Background(), userID)
+	_, err := ing.Push(ctx, client.ToWriteRequest([]model.Sample{
+		{Metric: model.Metric{model.MetricNameLabel: "foo"}, Timestamp: model.TimeFromUnix(123), Value: 456},
+	}, client.API))
+	require.NoError(t, err)
+
+	// The handler responds once the chunks are flushed and the ingester has
+	// left the ring.
+	recorder := httptest.NewRecorder()
+	ing.ShutdownHandler(recorder, httptest.NewRequest("POST", "/shutdown", nil))
+	require.Equal(t, http.StatusNoContent, recorder.Code)
+
+	r, err := ing.lifecycler.KVStore.Get(context.Background(), ring.ConsulKey)
+	require.NoError(t, err)
+	require.Empty(t, r.(*ring.Desc).Ingesters)
+	require.Len(t, store.chunks[userID], 1)
+
+	// Shutting down again on exit is a no-op.
+	ing.Shutdown()
+}
+
+func TestIngesterMemorySeriesHandler(t *testing.T) {
+	_, ing := newDefaultTestStore(t)
+	defer ing.Shutdown()
+
+	ctx := user.InjectOrgID(context.Background(), userID)
+	var samples []model.Sample
+	for _, job := range []string{"a", "b", "c", "d", "e"} {
+		samples = append(samples, model.Sample{Metric: model.Metric{model.MetricNameLabel: "foo", "job": model.LabelValue(job)}, Timestamp: 1, Value: 1})
+	}
+	samples = append(samples, model.Sample{Metric: model.Metric{model.MetricNameLabel: "bar"}, Timestamp: 1, Value: 1})
+	_, err := ing.Push(ctx, client.ToWriteRequest(samples, client.API))
+	require.NoError(t, err)
+
+	listSeries := func(query string) memorySeriesResponse {
+		req := httptest.NewRequest("GET", "/memory_series?"+query, nil)
+		req.Header.Set(user.OrgIDHeaderName, userID)
+		recorder := httptest.NewRecorder()
+		ing.MemorySeriesHandler(recorder, req)
+		require.Equal(t, http.StatusOK, recorder.Code)
+		var resp memorySeriesResponse
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+		return resp
+	}
+
+	resp := listSeries("")
+	require.Equal(t, 6, resp.Total)
+	require.Len(t, resp.Series, 6)
+	require.Empty(t, resp.Next)
+
+	// Page through the matching series.
+	jobs := map[string]struct{}{}
+	query := "match[]=foo&limit=2"
+	for pages := 1; ; pages++ {
+		resp := listSeries(query)
+		require.Equal(t, 5, resp.Total)
+		for _, ls := range resp.Series {
+			require.Equal(t, "foo", ls.Get(model.MetricNameLabel))
+			jobs[ls.Get("job")] = struct{}{}
+		}
+		if resp.Next == "" {
+			require.Equal(t, 3, pages)
+			break
+		}
+		require.Len(t, resp.Series, 2)
+		query = "match[]=foo&limit=2&after=" + resp.Next
+	}
+	require.Len(t, jobs, 5)
+}
+
+func TestIngesterReadOnlyHandler(t *testing.T) {
+	_, ing := newDefaultTestStore(t)
+	defer ing.Shutdown()
+
+	test.Poll(t, 100*time.Millisecond, ring.ACTIVE, func() interface{} {
+		return ing.lifecycler.GetState()
+	})
+
+	ctx := user.InjectOrgID(context.Background(), userID)
+	push := func(ts model.Time) error {
+		_, err := ing.Push(ctx, client.ToWriteRequest([]model.Sample{
+			{Metric: model.Metric{model.MetricNameLabel: "foo"}, Timestamp: ts, Value: 1},
+		}, client.API))
+		return err
+	}
+	require.NoError(t, push(1))
+
+	recorder := httptest.NewRecorder()
+	ing.ReadOnlyHandler(recorder, httptest.NewRequest("POST", "/readonly", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, "READONLY\n", recorder.Body.String())
+
+	// The state is in the ring, for the distributors to write elsewhere.
+	r, err := ing.lifecycler.KVStore.Get(context.Background(), ring.ConsulKey)
+	require.NoError(t, err)
+	require.Equal(t, ring.READONLY, r.(*ring.Desc).Ingesters[ing.lifecycler.ID].State)
+
+	// Pushes from distributors yet to see its state are still stored, and
+	// queries answered.
+	require.NoError(t, push(2))
+	res, _, err := runTestQuery(ctx, t, ing, labels.MatchEqual, model.MetricNameLabel, "foo")
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	require.Len(t, res[0].Values, 2)
+
+	recorder = httptest.NewRecorder()
+	ing.ReadOnlyHandler(recorder, httptest.NewRequest("DELETE", "/readonly", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, "ACTIVE\n", recorder.Body.String())
+	require.NoError(t, push(3))
+}
+
+func TestIngesterFlushSeriesHandler(t *testing.T) {
+	store, ing := newDefaultTestStore(t)
+	defer ing.Shutdown()
+
+	ctx := user.InjectOrgID(context.Background(), userID)
+	_, err := ing.Push(ctx, client.ToWriteRequest([]model.Sample{
+		{Metric: model.Metric{model.MetricNameLabel: "foo", "job": "a"}, Timestamp: 1, Value: 1},
+		{Metric: model.Metric{model.MetricNameLabel: "foo", "job": "b"}, Timestamp: 1, Value: 1},
+		{Metric: model.Metric{model.MetricNameLabel: "bar"}, Timestamp: 1, Value: 1},
+	}, client.API))
+	require.NoError(t, err)
+
+	flushSeries := func(query string) int {
+		req := httptest.NewRequest("POST", "/flush_series?"+query, nil)
+		req.Header.Set(user.OrgIDHeaderName, userID)
+		recorder := httptest.NewRecorder()
+		ing.FlushSeriesHandler(recorder, req)
+		return recorder.Code
+	}
+	require.Equal(t, http.StatusBadRequest, flushSeries(""))
+
+	// Only the matching series are flushed.
+	require.Equal(t, http.StatusNoContent, flushSeries(`match[]=foo{job="a"}`))
+	test.Poll(t, 100*time.Millisecond, 1, func() interface{} {
+		store.mtx.Lock()
+		defer store.mtx.Unlock()
+		return len(store.chunks[userID])
+	})
+
+	// Dropped series are removed from memory without being flushed.
+	require.Equal(t, http.StatusNoContent, flushSeries(`match[]=foo&drop=true`))
+	res, _, err := runTestQuery(ctx, t, ing, labels.MatchRegexp, model.MetricNameLabel, ".+")
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	assert.Equal(t, model.LabelValue("bar"), res[0].Metric[model.MetricNameLabel])
+	store.mtx.Lock()
+	defer store.mtx.Unlock()
+	require.Len(t, store.chunks[userID], 1)
+}
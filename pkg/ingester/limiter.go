@@ -0,0 +1,54 @@
+package ingester
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// ingestLimiterIdlePeriod is how long a user's ingestion rate limiter is
+// kept without pushes.  By then its bucket has refilled to the burst size,
+// which is how a new limiter starts.
+const ingestLimiterIdlePeriod = 10 * time.Minute
+
+// ingestLimiters are the users' ingestion rate limiters, recreated when their
+// limits change.
+type ingestLimiters struct {
+	mtx      sync.Mutex
+	limiters map[string]*ingestLimiter
+}
+
+type ingestLimiter struct {
+	*rate.Limiter
+	lastUsed time.Time
+}
+
+// allowN reports whether the user can ingest n samples now, at a rate of
+// limit samples per second with bursts of up to burst samples.
+func (l *ingestLimiters) allowN(userID string, limit float64, burst, n int) bool {
+	now := time.Now()
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	limiter, ok := l.limiters[userID]
+	if !ok || limiter.Limit() != rate.Limit(limit) || limiter.Burst() != burst {
+		if l.limiters == nil {
+			l.limiters = map[string]*ingestLimiter{}
+		}
+		limiter = &ingestLimiter{Limiter: rate.NewLimiter(rate.Limit(limit), burst)}
+		l.limiters[userID] = limiter
+	}
+	limiter.lastUsed = now
+	return limiter.AllowN(now, n)
+}
+
+// purge removes the limiters of users which haven't pushed since before.
+func (l *ingestLimiters) purge(before time.Time) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	for userID, limiter := range l.limiters {
+		if limiter.lastUsed.Before(before) {
+			delete(l.limiters, userID)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package ingester
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/promql"
+
+	"github.com/cortexproject/cortex/pkg/util"
+	"github.com/weaveworks/common/user"
+)
+
+// defaultMemorySeriesLimit is the number of series listed per page by the
+// MemorySeriesHandler, unless a limit is requested.
+const defaultMemorySeriesLimit = 100
+
+type memorySeriesResponse struct {
+	Series []labels.Labels `json:"series"`
+	// The number of series matching, on all pages.
+	Total int `json:"total"`
+	// The after parameter for the next page, empty on the last page.
+	Next string `json:"next,omitempty"`
+}
+
+// MemorySeriesHandler pages through the label sets of the in-memory series of
+// the request's user matching any of its match[] selectors, or all of them
+// without any, e.g. to find the series of a cardinality explosion.  Series
+// are listed in fingerprint order, limit of them per page, starting after the
+// fingerprint in the after parameter.  Unlike queries, it isn't limited by
+// -ingester.max-series-per-query.
+func (i *Ingester) MemorySeriesHandler(w http.ResponseWriter, r *http.Request) {
+	userID, _, err := user.ExtractOrgIDFromHTTPRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	matchersSet, err := parseMatchersSet(r.Form["match[]"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	limit := defaultMemorySeriesLimit
+	if s := r.FormValue("limit"); s != "" {
+		if limit, err = strconv.Atoi(s); err != nil || limit <= 0 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+	}
+	var after model.Fingerprint
+	if s := r.FormValue("after"); s != "" {
+		if after, err = model.FingerprintFromString(s); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	resp := memorySeriesResponse{Series: []labels.Labels{}}
+	i.userStatesMtx.RLock()
+	defer i.userStatesMtx.RUnlock()
+	state, ok := i.userStates.get(userID)
+	if !ok {
+		util.WriteJSONResponse(w, resp)
+		return
+	}
+
+	fps := matchingFingerprints(state, matchersSet)
+	resp.Total = len(fps)
+	start := 0
+	if after != 0 {
+		start = sort.Search(len(fps), func(j int) bool { return fps[j] > after })
+	}
+	var last model.Fingerprint
+	for _, fp := range fps[start:] {
+		if len(resp.Series) == limit {
+			resp.Next = last.String()
+			break
+		}
+		state.fpLocker.Lock(fp)
+		if series, ok := state.fpToSeries.get(fp); ok {
+			resp.Series = append(resp.Series, series.metric)
+			last = fp
+		}
+		state.fpLocker.Unlock(fp)
+	}
+	util.WriteJSONResponse(w, resp)
+}
+
+// parseMatchersSet parses match[] selectors.
+func parseMatchersSet(selectors []string) ([][]*labels.Matcher, error) {
+	matchersSet := make([][]*labels.Matcher, 0, len(selectors))
+	for _, s := range selectors {
+		matchers, err := promql.ParseMetricSelector(s)
+		if err != nil {
+			return nil, err
+		}
+		matchersSet = append(matchersSet, matchers)
+	}
+	return matchersSet, nil
+}
+
+// matchingFingerprints returns the sorted fingerprints of the user's series
+// matching any of matchersSet, or of all its series if matchersSet is empty.
+func matchingFingerprints(state *userState, matchersSet [][]*labels.Matcher) []model.Fingerprint {
+	var fps []model.Fingerprint
+	if len(matchersSet) == 0 {
+		for pair := range state.fpToSeries.iter() {
+			fps = append(fps, pair.fp)
+		}
+	}
+
+	seen := map[model.Fingerprint]struct{}{}
+	for _, matchers := range matchersSet {
+		filters, matchers := util.SplitFiltersAndMatchers(matchers)
+	outer:
+		for _, fp := range state.index.Lookup(matchers) {
+			if _, ok := seen[fp]; ok {
+				continue
+			}
+			state.fpLocker.Lock(fp)
+			series, ok := state.fpToSeries.get(fp)
+			if !ok {
+				state.fpLocker.Unlock(fp)
+				continue
+			}
+			for _, filter := range filters {
+				if !filter.Matches(series.metric.Get(filter.Name)) {
+					state.fpLocker.Unlock(fp)
+					continue outer
+				}
+			}
+			state.fpLocker.Unlock(fp)
+			seen[fp] = struct{}{}
+			fps = append(fps, fp)
+		}
+	}
+
+	sort.Sort(model.Fingerprints(fps))
+	return fps
+}
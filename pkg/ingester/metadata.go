@@ -0,0 +1,134 @@
+package ingester
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/cortexproject/cortex/pkg/ingester/client"
+)
+
+var (
+	ingestedMetadata = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "cortex_ingester_ingested_metadata_total",
+		Help: "The total number of metadata entries ingested.",
+	})
+	discardedMetadata = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "cortex_ingester_discarded_metadata_total",
+		Help: "The total number of metadata entries dropped as the user or metric was over its limit, or metadata storage is disabled for the user.",
+	})
+	memoryMetadata = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "cortex_ingester_memory_metadata",
+		Help: "The current number of metadata entries in memory.",
+	})
+)
+
+// metadataStores holds each tenant's metric metadata.
+type metadataStores struct {
+	mtx    sync.Mutex
+	stores map[string]*metadataStore
+}
+
+func newMetadataStores() *metadataStores {
+	return &metadataStores{
+		stores: map[string]*metadataStore{},
+	}
+}
+
+func (s *metadataStores) get(userID string, create bool) *metadataStore {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	store, ok := s.stores[userID]
+	if !ok && create {
+		store = &metadataStore{metrics: map[string]map[client.MetricMetadata]time.Time{}}
+		s.stores[userID] = store
+	}
+	return store
+}
+
+// purge forgets the metadata last received before deadline, and the tenants
+// left without any.
+func (s *metadataStores) purge(deadline time.Time) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for userID, store := range s.stores {
+		if store.purge(deadline) == 0 {
+			delete(s.stores, userID)
+		}
+	}
+}
+
+// metadataStore holds the distinct metadata entries of a tenant's metrics,
+// each with the time it was last received.
+type metadataStore struct {
+	mtx     sync.RWMutex
+	metrics map[string]map[client.MetricMetadata]time.Time
+	entries int
+}
+
+// add records the entry, unless it is new and the tenant or its metric
+// already has as many as allowed.  Metadata is best effort, so entries over
+// the limits are only counted, rather than failing the push.
+func (s *metadataStore) add(m client.MetricMetadata, now time.Time, maxPerUser, maxPerMetric int) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	entries, ok := s.metrics[m.MetricFamilyName]
+	if _, seen := entries[m]; !seen {
+		if s.entries >= maxPerUser || (maxPerMetric > 0 && len(entries) >= maxPerMetric) {
+			discardedMetadata.Inc()
+			return
+		}
+		if !ok {
+			entries = map[client.MetricMetadata]time.Time{}
+			s.metrics[m.MetricFamilyName] = entries
+		}
+		s.entries++
+		memoryMetadata.Inc()
+	}
+	entries[m] = now
+	ingestedMetadata.Inc()
+}
+
+// purge forgets the entries last received before deadline, returning how
+// many are left.
+func (s *metadataStore) purge(deadline time.Time) int {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for name, entries := range s.metrics {
+		for m, t := range entries {
+			if t.Before(deadline) {
+				delete(entries, m)
+				s.entries--
+				memoryMetadata.Dec()
+			}
+		}
+		if len(entries) == 0 {
+			delete(s.metrics, name)
+		}
+	}
+	return s.entries
+}
+
+// list returns the entries, sorted by metric name.
+func (s *metadataStore) list() []*client.MetricMetadata {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	result := make([]*client.MetricMetadata, 0, s.entries)
+	for _, entries := range s.metrics {
+		for m := range entries {
+			m := m
+			result = append(result, &m)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].MetricFamilyName != result[j].MetricFamilyName {
+			return result[i].MetricFamilyName < result[j].MetricFamilyName
+		}
+		return result[i].String() < result[j].String()
+	})
+	return result
+}
@@ -0,0 +1,74 @@
+package ingester
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+
+	"github.com/cortexproject/cortex/pkg/ingester/client"
+)
+
+func TestMetadataStore(t *testing.T) {
+	foo := client.MetricMetadata{MetricFamilyName: "foo", Type: client.COUNTER, Help: "Foo."}
+	foo2 := client.MetricMetadata{MetricFamilyName: "foo", Type: client.COUNTER, Help: "Foo, renamed."}
+	foo3 := client.MetricMetadata{MetricFamilyName: "foo", Type: client.GAUGE, Help: "Foo."}
+	bar := client.MetricMetadata{MetricFamilyName: "bar", Type: client.GAUGE, Help: "Bar."}
+	baz := client.MetricMetadata{MetricFamilyName: "baz", Type: client.GAUGE, Help: "Baz."}
+
+	stores := newMetadataStores()
+	store := stores.get("1", true)
+	now := time.Now()
+	store.add(foo, now, 3, 2)
+	store.add(foo, now, 3, 2)
+	store.add(foo2, now, 3, 2)
+	// Over the per-metric limit.
+	store.add(foo3, now, 3, 2)
+	store.add(bar, now.Add(time.Minute), 3, 2)
+	// Over the per-user limit.
+	store.add(baz, now, 3, 2)
+	require.Equal(t, []*client.MetricMetadata{&bar, &foo2, &foo}, store.list())
+
+	// Entries last received before the deadline are forgotten, and so are
+	// the users left without any.
+	store.add(foo, now.Add(time.Minute), 3, 2)
+	stores.purge(now.Add(time.Second))
+	require.Equal(t, []*client.MetricMetadata{&bar, &foo}, store.list())
+	stores.purge(now.Add(2 * time.Minute))
+	require.Nil(t, stores.get("1", false))
+}
+
+func TestIngesterMetricsMetadata(t *testing.T) {
+	for _, tsdb := range []bool{false, true} {
+		t.Run(fmt.Sprintf("tsdb=%v", tsdb), func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "tsdb")
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+
+			cfg := defaultIngesterTestConfig()
+			cfg.TSDBEnabled = tsdb
+			cfg.TSDBConfig.Dir = dir
+			_, ing := newTestStore(t, cfg, defaultClientTestConfig(), defaultLimitsTestConfig())
+			defer ing.Shutdown()
+
+			ctx := user.InjectOrgID(context.Background(), "1")
+			foo := &client.MetricMetadata{MetricFamilyName: "foo", Type: client.COUNTER, Help: "Foo."}
+			_, err = ing.Push(ctx, &client.WriteRequest{Metadata: []*client.MetricMetadata{foo}})
+			require.NoError(t, err)
+
+			resp, err := ing.MetricsMetadata(ctx, &client.MetricsMetadataRequest{})
+			require.NoError(t, err)
+			require.Equal(t, []*client.MetricMetadata{foo}, resp.Metadata)
+
+			// Other tenants have none.
+			resp, err = ing.MetricsMetadata(user.InjectOrgID(context.Background(), "2"), &client.MetricsMetadataRequest{})
+			require.NoError(t, err)
+			require.Empty(t, resp.Metadata)
+		})
+	}
+}
@@ -17,6 +17,7 @@ import (
 
 	"github.com/cortexproject/cortex/pkg/chunk/encoding"
 	"github.com/cortexproject/cortex/pkg/ingester/client"
+	"github.com/cortexproject/cortex/pkg/util/validation"
 )
 
 func BenchmarkQueryStream(b *testing.B) {
@@ -62,7 +63,7 @@ func BenchmarkQueryStream(b *testing.B) {
 			err = series.add(model.SamplePair{
 				Value:     model.SampleValue(float64(i)),
 				Timestamp: model.Time(int64(i)),
-			})
+			}, validation.DuplicateSampleReject)
 			require.NoError(b, err)
 		}
 
@@ -10,6 +10,7 @@ import (
 
 	"github.com/cortexproject/cortex/pkg/chunk/encoding"
 	"github.com/cortexproject/cortex/pkg/prom1/storage/metric"
+	"github.com/cortexproject/cortex/pkg/util/validation"
 )
 
 var (
@@ -38,6 +39,10 @@ type memorySeries struct {
 	lastSampleValueSet bool
 	lastTime           model.Time
 	lastSampleValue    model.SampleValue
+
+	// This server's local time when a sample was last appended, including
+	// no-op appends, for the series to be counted as active.
+	lastAppend model.Time
 }
 
 type memorySeriesError struct {
@@ -59,10 +64,12 @@ func newMemorySeries(m labels.Labels) *memorySeries {
 }
 
 // add adds a sample pair to the series. It returns the number of newly
-// completed chunks (which are now eligible for persistence).
+// completed chunks (which are now eligible for persistence).  A sample with
+// the same timestamp as, but a different value from, the last one is handled
+// according to the validation.DuplicateSample* policy.
 //
 // The caller must have locked the fingerprint of the series.
-func (s *memorySeries) add(v model.SamplePair) error {
+func (s *memorySeries) add(v model.SamplePair, duplicatePolicy string) error {
 	// Don't report "no-op appends", i.e. where timestamp and sample
 	// value are the same as for the last append, as they are a
 	// common occurrence when using client-side timestamps
@@ -70,9 +77,20 @@ func (s *memorySeries) add(v model.SamplePair) error {
 	if s.lastSampleValueSet &&
 		v.Timestamp == s.lastTime &&
 		v.Value.Equal(s.lastSampleValue) {
+		s.lastAppend = model.Now()
 		return nil
 	}
 	if v.Timestamp == s.lastTime {
+		switch duplicatePolicy {
+		case validation.DuplicateSampleKeepFirst:
+			s.lastAppend = model.Now()
+			return nil
+		case validation.DuplicateSampleKeepLast:
+			// The last sample can only be replaced while its chunk is open.
+			if s.lastSampleValueSet && len(s.chunkDescs) > 0 && !s.headChunkClosed {
+				return s.replaceLast(v)
+			}
+		}
 		return &memorySeriesError{
 			message:   fmt.Sprintf("sample with repeated timestamp but different value for series %v; last value: %v, incoming value: %v", s.metric, s.lastSampleValue, v.Value),
 			errorType: "new-value-for-timestamp",
@@ -117,10 +135,65 @@ func (s *memorySeries) add(v model.SamplePair) error {
 	s.lastTime = v.Timestamp
 	s.lastSampleValue = v.Value
 	s.lastSampleValueSet = true
+	s.lastAppend = model.Now()
 
 	return nil
 }
 
+// replaceLast replaces the head chunk's last sample, which must have v's
+// timestamp, by v.  Chunks are append only, so the head chunk is rebuilt.
+func (s *memorySeries) replaceLast(v model.SamplePair) error {
+	var samples []model.SamplePair
+	iter := s.head().C.NewIterator()
+	for iter.Scan() {
+		samples = append(samples, iter.Value())
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	if len(samples) == 0 || samples[len(samples)-1].Timestamp != v.Timestamp {
+		return fmt.Errorf("last sample of series %v not in its head chunk", s.metric)
+	}
+	samples[len(samples)-1] = v
+
+	// A different value may not fit in the chunk, in which case the samples
+	// overflow into new chunks, as in add.  The head chunk keeps its
+	// encoding, which may not be the one new chunks get.
+	head, err := encoding.NewForEncoding(s.head().C.Encoding())
+	if err != nil {
+		return err
+	}
+	chunks := []encoding.Chunk{head}
+	for _, sample := range samples {
+		cs, err := chunks[len(chunks)-1].Add(sample)
+		if err != nil {
+			return err
+		}
+		chunks = append(chunks[:len(chunks)-1], cs...)
+	}
+
+	if len(chunks) == 1 {
+		s.head().C = chunks[0]
+		s.head().LastUpdate = model.Now()
+	} else {
+		s.chunkDescs = s.chunkDescs[:len(s.chunkDescs)-1]
+		for i, c := range chunks {
+			first, last, err := firstAndLastTimes(c)
+			if err != nil {
+				return err
+			}
+			s.chunkDescs = append(s.chunkDescs, newDesc(c, first, last))
+			if i > 0 {
+				createdChunks.Inc()
+			}
+		}
+	}
+
+	s.lastSampleValue = v.Value
+	s.lastAppend = model.Now()
+	return nil
+}
+
 func firstAndLastTimes(c encoding.Chunk) (model.Time, model.Time, error) {
 	var (
 		first    model.Time
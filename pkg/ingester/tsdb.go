@@ -0,0 +1,396 @@
+package ingester
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/storage"
+	promtsdb "github.com/prometheus/prometheus/storage/tsdb"
+	"github.com/prometheus/tsdb"
+	"github.com/prometheus/tsdb/chunks"
+	"github.com/prometheus/tsdb/index"
+	tsdb_labels "github.com/prometheus/tsdb/labels"
+	"github.com/weaveworks/common/httpgrpc"
+
+	"github.com/cortexproject/cortex/pkg/util"
+	"github.com/cortexproject/cortex/pkg/util/flagext"
+	"github.com/cortexproject/cortex/pkg/util/validation"
+)
+
+// shipperFile is the file, in each user's TSDB directory, listing the blocks
+// which have been shipped to the bucket.
+const shipperFile = "shipper.json"
+
+// metaFile is the file, in each block's directory, describing the block.
+const metaFile = "meta.json"
+
+// TSDBConfig configures the ingesters' TSDBs, used with -store.engine=blocks.
+type TSDBConfig struct {
+	Dir          string           `yaml:"dir"`
+	BlockRange   time.Duration    `yaml:"block_range_period"`
+	Retention    time.Duration    `yaml:"retention_period"`
+	ShipInterval time.Duration    `yaml:"ship_interval"`
+	BucketURL    flagext.URLValue `yaml:"bucket_url"`
+}
+
+// RegisterFlags adds the flags required to config this to the given FlagSet
+func (cfg *TSDBConfig) RegisterFlags(f *flag.FlagSet) {
+	f.StringVar(&cfg.Dir, "experimental.tsdb.dir", "tsdb", "Directory the ingesters keep each user's TSDB in, which must persist across restarts.")
+	f.DurationVar(&cfg.BlockRange, "experimental.tsdb.block-range-period", 2*time.Hour, "Period of time each TSDB block covers.")
+	f.DurationVar(&cfg.Retention, "experimental.tsdb.retention-period", 6*time.Hour, "Period blocks are kept in the ingesters for, to be queried, after having been cut.")
+	f.DurationVar(&cfg.ShipInterval, "experimental.tsdb.ship-interval", 1*time.Minute, "Period with which new blocks are shipped to the bucket.")
+	f.Var(&cfg.BucketURL, "experimental.tsdb.bucket-url", "URL of the bucket blocks are shipped to, in a directory per user: s3://<key>:<secret>@<region>/<bucket> (as for -s3.url), gs://<bucket>, or file:///<path> for a local directory. Blocks aren't shipped if empty.")
+}
+
+// userTSDB is a user's TSDB, and the rates of the samples appended to it.
+type userTSDB struct {
+	*tsdb.DB
+	adapter storage.Storage
+
+	ingestedAPISamples  *ewmaRate
+	ingestedRuleSamples *ewmaRate
+
+	// The hashes of the labels of the series in the head, with their metric
+	// names, to enforce the series limits.  Series are added as they are
+	// appended, and the head recounted periodically, as it drops series when
+	// it is truncated.
+	seriesMtx      sync.Mutex
+	series         map[uint64]string
+	seriesInMetric map[string]int
+}
+
+// numSeries returns the number of series in the TSDB's head.
+func (db *userTSDB) numSeries() (uint64, error) {
+	ir, err := db.Head().Index()
+	if err != nil {
+		return 0, err
+	}
+	defer ir.Close()
+
+	p, err := ir.Postings(index.AllPostingsKey())
+	if err != nil {
+		return 0, err
+	}
+	var n uint64
+	for p.Next() {
+		n++
+	}
+	return n, p.Err()
+}
+
+// addSeries records the series with the labels, if the head doesn't have it
+// yet, or returns an error if it would exceed the user's series limits.
+func (db *userTSDB) addSeries(userID string, lset labels.Labels, limits *validation.Overrides) error {
+	hash := lset.Hash()
+	db.seriesMtx.Lock()
+	defer db.seriesMtx.Unlock()
+	if _, ok := db.series[hash]; ok {
+		return nil
+	}
+
+	// The limits' errors name the series rejected, as for the chunks storage.
+	metricName := lset.Get(model.MetricNameLabel)
+	if len(db.series) >= limits.MaxSeriesPerUser(userID) {
+		validation.DiscardedSamples.WithLabelValues(perUserSeriesLimit, userID).Inc()
+		return newSampleError(http.StatusTooManyRequests, perUserSeriesLimit, "per-user series limit (%d) exceeded for %s: %s", limits.MaxSeriesPerUser(userID), metricName, lset)
+	}
+	if db.seriesInMetric[metricName] >= limits.MaxSeriesPerMetric(userID) {
+		validation.DiscardedSamples.WithLabelValues(perMetricSeriesLimit, userID).Inc()
+		return newSampleError(http.StatusTooManyRequests, perMetricSeriesLimit, "per-metric series limit (%d) exceeded for %s: %s", limits.MaxSeriesPerMetric(userID), metricName, lset)
+	}
+	db.series[hash] = metricName
+	db.seriesInMetric[metricName]++
+	return nil
+}
+
+// hasSeries reports whether the series with the labels is recorded.
+func (db *userTSDB) hasSeries(lset labels.Labels) bool {
+	db.seriesMtx.Lock()
+	defer db.seriesMtx.Unlock()
+	_, ok := db.series[lset.Hash()]
+	return ok
+}
+
+// recountSeries replaces the series recorded with those in the head.  The
+// series appended meanwhile are recorded again when next appended.
+func (db *userTSDB) recountSeries() error {
+	ir, err := db.Head().Index()
+	if err != nil {
+		return err
+	}
+	defer ir.Close()
+
+	p, err := ir.Postings(index.AllPostingsKey())
+	if err != nil {
+		return err
+	}
+	series, seriesInMetric := map[uint64]string{}, map[string]int{}
+	var (
+		lset tsdb_labels.Labels
+		chks []chunks.Meta
+	)
+	for p.Next() {
+		if err := ir.Series(p.At(), &lset, &chks); err != nil {
+			return err
+		}
+		metricName := lset.Get(model.MetricNameLabel)
+		series[lset.Hash()] = metricName
+		seriesInMetric[metricName]++
+	}
+	if err := p.Err(); err != nil {
+		return err
+	}
+
+	db.seriesMtx.Lock()
+	db.series, db.seriesInMetric = series, seriesInMetric
+	db.seriesMtx.Unlock()
+	return nil
+}
+
+// userTSDBs are the ingester's TSDBs, one per user.
+type userTSDBs struct {
+	cfg    Config
+	bucket bucketClient
+
+	mtx sync.RWMutex
+	dbs map[string]*userTSDB
+}
+
+// openUserTSDBs opens the TSDBs already in cfg.TSDBConfig.Dir, for the
+// samples in their WALs and their blocks to be queried after a restart.
+func openUserTSDBs(cfg Config) (*userTSDBs, error) {
+	u := &userTSDBs{
+		cfg: cfg,
+		dbs: map[string]*userTSDB{},
+	}
+	if cfg.TSDBConfig.BucketURL.URL != nil {
+		bucket, err := newBucketClient(cfg.TSDBConfig.BucketURL.URL)
+		if err != nil {
+			return nil, err
+		}
+		u.bucket = bucket
+	}
+
+	infos, err := ioutil.ReadDir(cfg.TSDBConfig.Dir)
+	if os.IsNotExist(err) {
+		return u, nil
+	} else if err != nil {
+		return nil, err
+	}
+	for _, info := range infos {
+		if !info.IsDir() {
+			continue
+		}
+		if _, err := u.getOrCreate(info.Name()); err != nil {
+			u.close()
+			return nil, err
+		}
+	}
+	return u, nil
+}
+
+func (u *userTSDBs) get(userID string) (*userTSDB, bool) {
+	u.mtx.RLock()
+	defer u.mtx.RUnlock()
+	db, ok := u.dbs[userID]
+	return db, ok
+}
+
+func (u *userTSDBs) getOrCreate(userID string) (*userTSDB, error) {
+	if db, ok := u.get(userID); ok {
+		return db, nil
+	}
+
+	u.mtx.Lock()
+	defer u.mtx.Unlock()
+	if db, ok := u.dbs[userID]; ok {
+		return db, nil
+	}
+	if err := validateTSDBUserID(userID); err != nil {
+		return nil, err
+	}
+
+	// A single block range, as the blocks are compacted further once shipped.
+	blockRange := int64(u.cfg.TSDBConfig.BlockRange / time.Millisecond)
+	db, err := tsdb.Open(filepath.Join(u.cfg.TSDBConfig.Dir, userID), util.Logger, nil, &tsdb.Options{
+		RetentionDuration: uint64(u.cfg.TSDBConfig.Retention / time.Millisecond),
+		BlockRanges:       []int64{blockRange},
+		NoLockfile:        true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error opening TSDB of user %s: %v", userID, err)
+	}
+
+	userDB := &userTSDB{
+		DB:                  db,
+		adapter:             promtsdb.Adapter(db, 0),
+		ingestedAPISamples:  newEWMARate(0.2, u.cfg.RateUpdatePeriod),
+		ingestedRuleSamples: newEWMARate(0.2, u.cfg.RateUpdatePeriod),
+		series:              map[uint64]string{},
+		seriesInMetric:      map[string]int{},
+	}
+	// The head has the series replayed from the WAL.
+	if err := userDB.recountSeries(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error counting series of user %s: %v", userID, err)
+	}
+	u.dbs[userID] = userDB
+	return userDB, nil
+}
+
+// validateTSDBUserID returns an error if the user ID can't name its TSDB's
+// directory, and its directory in the bucket, without reaching outside of
+// them.
+func validateTSDBUserID(userID string) error {
+	if userID == "" || userID == "." || userID == ".." || strings.ContainsAny(userID, `/\`) {
+		return httpgrpc.Errorf(http.StatusBadRequest, "invalid user ID %.200q for the blocks storage", userID)
+	}
+	return nil
+}
+
+func (u *userTSDBs) cp() map[string]*userTSDB {
+	u.mtx.RLock()
+	defer u.mtx.RUnlock()
+	dbs := make(map[string]*userTSDB, len(u.dbs))
+	for userID, db := range u.dbs {
+		dbs[userID] = db
+	}
+	return dbs
+}
+
+// recountSeries recounts the series in each TSDB's head.
+func (u *userTSDBs) recountSeries() {
+	for userID, db := range u.cp() {
+		if err := db.recountSeries(); err != nil {
+			level.Error(util.Logger).Log("msg", "error counting series", "user", userID, "err", err)
+		}
+	}
+}
+
+func (u *userTSDBs) updateRates() {
+	for _, db := range u.cp() {
+		db.ingestedAPISamples.tick()
+		db.ingestedRuleSamples.tick()
+	}
+}
+
+// close closes the TSDBs, whose heads are replayed from their WALs when
+// they are opened again.
+func (u *userTSDBs) close() {
+	u.mtx.Lock()
+	defer u.mtx.Unlock()
+	for userID, db := range u.dbs {
+		if err := db.Close(); err != nil {
+			level.Error(util.Logger).Log("msg", "error closing TSDB", "user", userID, "err", err)
+		}
+		delete(u.dbs, userID)
+	}
+}
+
+// ship uploads the blocks of each TSDB which haven't been shipped yet to the
+// user's directory in the bucket, giving up after the ship interval, by when
+// the next cycle is due.
+func (u *userTSDBs) ship() {
+	if u.bucket == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), u.cfg.TSDBConfig.ShipInterval)
+	defer cancel()
+	for userID := range u.cp() {
+		if ctx.Err() != nil {
+			level.Error(util.Logger).Log("msg", "timed out shipping blocks", "user", userID)
+			return
+		}
+		if err := shipBlocks(ctx, filepath.Join(u.cfg.TSDBConfig.Dir, userID), userID, u.bucket); err != nil {
+			level.Error(util.Logger).Log("msg", "error shipping blocks", "user", userID, "err", err)
+		}
+	}
+}
+
+// shipBlocks uploads the blocks in dir which aren't listed in its shipper
+// file yet to prefix in the bucket, listing them once uploaded.  Each
+// block's meta file is uploaded last, so that blocks without one, whose
+// upload didn't complete, can be told apart.
+func shipBlocks(ctx context.Context, dir, prefix string, bucket bucketClient) error {
+	shipped := map[string]bool{}
+	if buf, err := ioutil.ReadFile(filepath.Join(dir, shipperFile)); err == nil {
+		if err := json.Unmarshal(buf, &shipped); err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, info := range infos {
+		block := info.Name()
+		if !info.IsDir() || shipped[block] || strings.HasSuffix(block, ".tmp") {
+			continue
+		}
+		// Only complete blocks have a meta file, so this skips the WAL too.
+		if _, err := os.Stat(filepath.Join(dir, block, metaFile)); os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		if err := uploadBlock(ctx, filepath.Join(dir, block), path.Join(prefix, block), bucket); err != nil {
+			return err
+		}
+		shipped[block] = true
+
+		buf, err := json.Marshal(shipped)
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, shipperFile), buf, 0644); err != nil {
+			return err
+		}
+		blocksShipped.Inc()
+	}
+	return nil
+}
+
+// uploadBlock uploads the files of the block in dir to prefix in the
+// bucket, its meta file last.
+func uploadBlock(ctx context.Context, dir, prefix string, bucket bucketClient) error {
+	err := filepath.Walk(dir, func(file string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, file)
+		if err != nil || rel == metaFile {
+			return err
+		}
+		return uploadFile(ctx, file, path.Join(prefix, filepath.ToSlash(rel)), bucket)
+	})
+	if err != nil {
+		return err
+	}
+	return uploadFile(ctx, filepath.Join(dir, metaFile), path.Join(prefix, metaFile), bucket)
+}
+
+func uploadFile(ctx context.Context, file, name string, bucket bucketClient) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return bucket.upload(ctx, name, f)
+}
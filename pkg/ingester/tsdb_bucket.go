@@ -0,0 +1,105 @@
+package ingester
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"cloud.google.com/go/storage"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	awscommon "github.com/weaveworks/common/aws"
+)
+
+// bucketClient uploads the files of the blocks shipped to a bucket.  Names
+// are slash-separated, relative to the bucket's root.
+type bucketClient interface {
+	upload(ctx context.Context, name string, r io.ReadSeeker) error
+}
+
+// newBucketClient returns the client of the bucket at u: s3:// URLs, as
+// for -s3.url, are S3 buckets, gs://<bucket> URLs GCS buckets, and file://
+// URLs local directories, e.g. where a bucket is mounted.
+func newBucketClient(u *url.URL) (bucketClient, error) {
+	switch u.Scheme {
+	case "s3":
+		cfg, err := awscommon.ConfigFromURL(u)
+		if err != nil {
+			return nil, err
+		}
+		return s3Bucket{
+			s3:     s3.New(session.New(cfg)),
+			bucket: strings.TrimPrefix(u.Path, "/"),
+		}, nil
+	case "gs":
+		client, err := storage.NewClient(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		return gcsBucket{bucket: client.Bucket(u.Host)}, nil
+	case "file":
+		return dirBucket(u.Path), nil
+	}
+	return nil, fmt.Errorf("unsupported bucket URL scheme %q, use s3, gs or file", u.Scheme)
+}
+
+type s3Bucket struct {
+	s3     s3iface.S3API
+	bucket string
+}
+
+func (b s3Bucket) upload(ctx context.Context, name string, r io.ReadSeeker) error {
+	_, err := b.s3.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(name),
+		Body:   r,
+	})
+	return err
+}
+
+type gcsBucket struct {
+	bucket *storage.BucketHandle
+}
+
+func (b gcsBucket) upload(ctx context.Context, name string, r io.ReadSeeker) error {
+	w := b.bucket.Object(name).NewWriter(ctx)
+	if _, err := io.Copy(w, r); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// dirBucket is a local directory.  Files are written to a temporary file
+// first, so they are never seen partially written.
+type dirBucket string
+
+func (b dirBucket) upload(_ context.Context, name string, r io.ReadSeeker) error {
+	path := filepath.Join(string(b), filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, r); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
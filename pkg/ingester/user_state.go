@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"sync"
+	"sync/atomic"
 
 	"github.com/go-kit/kit/log/level"
 	"github.com/prometheus/client_golang/prometheus"
@@ -15,6 +16,7 @@ import (
 
 	"github.com/cortexproject/cortex/pkg/ingester/client"
 	"github.com/cortexproject/cortex/pkg/ingester/index"
+	"github.com/cortexproject/cortex/pkg/querier/sharding"
 	"github.com/cortexproject/cortex/pkg/util"
 	"github.com/cortexproject/cortex/pkg/util/extract"
 	"github.com/cortexproject/cortex/pkg/util/spanlogger"
@@ -40,12 +42,19 @@ var (
 		Name: "cortex_ingester_memory_series_removed_total",
 		Help: "The total number of series that were removed per user.",
 	}, []string{"user"})
+	activeSeries = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "cortex_ingester_active_series",
+		Help: "The number of series per user which have received a sample within -ingester.active-series-idle-timeout.",
+	}, []string{"user"})
 )
 
 type userStates struct {
 	states sync.Map
 	limits *validation.Overrides
 	cfg    Config
+
+	// The number of series in memory, of all users.
+	numSeries int64
 }
 
 type userState struct {
@@ -60,8 +69,16 @@ type userState struct {
 
 	seriesInMetric []metricCounterShard
 
+	// The number of series in memory, of all users, and its limit.
+	instanceSeries    *int64
+	maxInstanceSeries int
+
+	// Counted by each sweep of the user's series.
+	activeSeries int64
+
 	memSeriesCreatedTotal prometheus.Counter
 	memSeriesRemovedTotal prometheus.Counter
+	activeSeriesGauge     prometheus.Gauge
 }
 
 const metricCounterShards = 128
@@ -70,6 +87,7 @@ const metricCounterShards = 128
 const (
 	perUserSeriesLimit   = "per_user_series_limit"
 	perMetricSeriesLimit = "per_metric_series_limit"
+	instanceSeriesLimit  = "instance_series_limit"
 )
 
 type metricCounterShard struct {
@@ -157,9 +175,12 @@ func (us *userStates) getOrCreateSeries(ctx context.Context, labels []client.Lab
 			ingestedAPISamples:  newEWMARate(0.2, us.cfg.RateUpdatePeriod),
 			ingestedRuleSamples: newEWMARate(0.2, us.cfg.RateUpdatePeriod),
 			seriesInMetric:      seriesInMetric,
+			instanceSeries:      &us.numSeries,
+			maxInstanceSeries:   us.cfg.InstanceLimits.MaxSeries,
 
 			memSeriesCreatedTotal: memSeriesCreatedTotal.WithLabelValues(userID),
 			memSeriesRemovedTotal: memSeriesRemovedTotal.WithLabelValues(userID),
+			activeSeriesGauge:     activeSeries.WithLabelValues(userID),
 		}
 		state.mapper = newFPMapper(state.fpToSeries)
 		stored, ok := us.states.LoadOrStore(userID, state)
@@ -192,26 +213,43 @@ func (u *userState) getSeries(metric labelPairs) (model.Fingerprint, *memorySeri
 	// all proceed to add a new series. This is likely not worth addressing,
 	// as this should happen rarely (all samples from one push are added
 	// serially), and the overshoot in allowed series would be minimal.
+	metricName, err := extract.MetricNameFromLabelAdapters(metric)
+	if err != nil {
+		u.fpLocker.Unlock(fp)
+		return fp, nil, err
+	}
+
+	// The distributor validates the labels, but series can also be pushed
+	// to ingesters directly, so they are validated again before they are
+	// added to the index.
+	if err := u.limits.ValidateLabels(u.userID, metric); err != nil {
+		u.fpLocker.Unlock(fp)
+		return fp, nil, err
+	}
+
+	// The limits' errors name the series rejected, for the remote write
+	// client to log what was dropped.
 	if u.fpToSeries.length() >= u.limits.MaxSeriesPerUser(u.userID) {
 		u.fpLocker.Unlock(fp)
 		validation.DiscardedSamples.WithLabelValues(perUserSeriesLimit, u.userID).Inc()
-		return fp, nil, httpgrpc.Errorf(http.StatusTooManyRequests, "per-user series limit (%d) exceeded", u.limits.MaxSeriesPerUser(u.userID))
+		return fp, nil, newSampleError(http.StatusTooManyRequests, perUserSeriesLimit, "per-user series limit (%d) exceeded for %s: %s", u.limits.MaxSeriesPerUser(u.userID), metricName, metric)
 	}
 
-	metricName, err := extract.MetricNameFromLabelAdapters(metric)
-	if err != nil {
+	if u.maxInstanceSeries > 0 && atomic.LoadInt64(u.instanceSeries) >= int64(u.maxInstanceSeries) {
 		u.fpLocker.Unlock(fp)
-		return fp, nil, err
+		validation.DiscardedSamples.WithLabelValues(instanceSeriesLimit, u.userID).Inc()
+		return fp, nil, newSampleError(http.StatusTooManyRequests, instanceSeriesLimit, "ingester's series limit (%d) exceeded for %s: %s", u.maxInstanceSeries, metricName, metric)
 	}
 
 	if !u.canAddSeriesFor(string(metricName)) {
 		u.fpLocker.Unlock(fp)
 		validation.DiscardedSamples.WithLabelValues(perMetricSeriesLimit, u.userID).Inc()
-		return fp, nil, httpgrpc.Errorf(http.StatusTooManyRequests, "per-metric series limit (%d) exceeded for %s: %s", u.limits.MaxSeriesPerMetric(u.userID), metricName, metric)
+		return fp, nil, newSampleError(http.StatusTooManyRequests, perMetricSeriesLimit, "per-metric series limit (%d) exceeded for %s: %s", u.limits.MaxSeriesPerMetric(u.userID), metricName, metric)
 	}
 
 	u.memSeriesCreatedTotal.Inc()
 	memSeries.Inc()
+	atomic.AddInt64(u.instanceSeries, 1)
 
 	labels := u.index.Add(metric, fp)
 	series = newMemorySeries(labels)
@@ -220,6 +258,21 @@ func (u *userState) getSeries(metric labelPairs) (model.Fingerprint, *memorySeri
 	return fp, series, nil
 }
 
+// hasSeries reports whether the series with the labels is in memory.
+func (u *userState) hasSeries(metric labelPairs) bool {
+	rawFP := client.FastFingerprint(metric)
+	u.fpLocker.Lock(rawFP)
+	fp := u.mapper.mapFP(rawFP, metric)
+	if fp != rawFP {
+		u.fpLocker.Unlock(rawFP)
+		u.fpLocker.Lock(fp)
+	}
+	defer u.fpLocker.Unlock(fp)
+
+	_, ok := u.fpToSeries.get(fp)
+	return ok
+}
+
 func (u *userState) canAddSeriesFor(metric string) bool {
 	shard := &u.seriesInMetric[util.HashFP(model.Fingerprint(fnv1a.HashString64(string(metric))))%metricCounterShards]
 	shard.mtx.Lock()
@@ -232,6 +285,15 @@ func (u *userState) canAddSeriesFor(metric string) bool {
 	return true
 }
 
+func (u *userState) setActiveSeries(n int) {
+	atomic.StoreInt64(&u.activeSeries, int64(n))
+	u.activeSeriesGauge.Set(float64(n))
+}
+
+func (u *userState) getActiveSeries() uint64 {
+	return uint64(atomic.LoadInt64(&u.activeSeries))
+}
+
 func (u *userState) removeSeries(fp model.Fingerprint, metric labels.Labels) {
 	u.fpToSeries.del(fp)
 	u.index.Delete(labels.Labels(metric), fp)
@@ -254,6 +316,7 @@ func (u *userState) removeSeries(fp model.Fingerprint, metric labels.Labels) {
 
 	u.memSeriesRemovedTotal.Inc()
 	memSeries.Dec()
+	atomic.AddInt64(u.instanceSeries, -1)
 }
 
 // forSeriesMatching passes all series matching the given matchers to the
@@ -271,6 +334,10 @@ func (u *userState) forSeriesMatching(ctx context.Context, allMatchers []*labels
 	log, ctx := spanlogger.New(ctx, "forSeriesMatching")
 	defer log.Finish()
 
+	shard, allMatchers, err := sharding.FromMatchers(allMatchers)
+	if err != nil {
+		return httpgrpc.Errorf(http.StatusBadRequest, err.Error())
+	}
 	filters, matchers := util.SplitFiltersAndMatchers(allMatchers)
 	fps := u.index.Lookup(matchers)
 	if len(fps) > u.limits.MaxSeriesPerQuery(u.userID) {
@@ -300,6 +367,10 @@ outer:
 				continue outer
 			}
 		}
+		if shard != nil && !shard.Contains(series.metric) {
+			u.fpLocker.Unlock(fp)
+			continue
+		}
 
 		err := add(ctx, fp, series)
 		u.fpLocker.Unlock(fp)
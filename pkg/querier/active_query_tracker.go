@@ -0,0 +1,182 @@
+package querier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/weaveworks/common/middleware"
+	"github.com/weaveworks/common/user"
+)
+
+const (
+	activeQueriesFilename = "queries.active"
+	activeQueryEntrySize  = 1000
+)
+
+type activeQueryEntry struct {
+	Query     string `json:"query"`
+	User      string `json:"user,omitempty"`
+	Timestamp int64  `json:"timestamp_sec"`
+}
+
+// activeQueryTracker records the queries being executed in a file, in one
+// fixed-size slot per query the engine runs at once, each either blank or
+// holding a JSON entry.  As slots are overwritten in place, the queries which
+// were running when the querier crashed can be logged when it restarts.
+type activeQueryTracker struct {
+	mtx    sync.Mutex
+	file   *os.File
+	slots  chan int
+	logger log.Logger
+}
+
+// ActiveQueryTrackerMiddleware records the PromQL queries in flight in
+// dir/queries.active, and logs those left over from a previous run, which
+// are likely to be the ones which made it crash.  Each request takes one of
+// maxConcurrent slots in the file, waiting for one if need be.  An empty dir
+// disables tracking.
+func ActiveQueryTrackerMiddleware(dir string, maxConcurrent int, logger log.Logger) (middleware.Interface, error) {
+	if dir == "" {
+		return middleware.Func(func(next http.Handler) http.Handler { return next }), nil
+	}
+	if maxConcurrent <= 0 {
+		return nil, fmt.Errorf("the active query tracker needs a positive number of concurrent queries, got %d", maxConcurrent)
+	}
+
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return nil, fmt.Errorf("error creating the active query tracker's directory: %v", err)
+	}
+	filename := filepath.Join(dir, activeQueriesFilename)
+	logUnfinishedQueries(filename, logger)
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return nil, fmt.Errorf("error creating the active query tracker's file: %v", err)
+	}
+	if _, err := file.Write(bytes.Repeat([]byte{' '}, maxConcurrent*activeQueryEntrySize)); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("error initialising the active query tracker's file: %v", err)
+	}
+
+	t := &activeQueryTracker{
+		file:   file,
+		slots:  make(chan int, maxConcurrent),
+		logger: logger,
+	}
+	for i := 0; i < maxConcurrent; i++ {
+		t.slots <- i
+	}
+	return t, nil
+}
+
+// logUnfinishedQueries logs the queries recorded in the file by a previous
+// run, if any.
+func logUnfinishedQueries(filename string, logger log.Logger) {
+	buf, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return
+	} else if err != nil {
+		level.Error(logger).Log("msg", "error reading the active query tracker's file", "file", filename, "err", err)
+		return
+	}
+
+	logged := false
+	for len(buf) > 0 {
+		n := activeQueryEntrySize
+		if n > len(buf) {
+			n = len(buf)
+		}
+		slot := bytes.TrimSpace(buf[:n])
+		buf = buf[n:]
+		if len(slot) == 0 {
+			continue
+		}
+
+		var e activeQueryEntry
+		if err := json.Unmarshal(slot, &e); err != nil {
+			level.Error(logger).Log("msg", "error parsing an entry of the active query tracker's file", "file", filename, "err", err)
+			continue
+		}
+		if !logged {
+			level.Info(logger).Log("msg", "these queries didn't finish in the querier's last run")
+			logged = true
+		}
+		level.Info(logger).Log("query", e.Query, "user", e.User, "started", time.Unix(e.Timestamp, 0))
+	}
+}
+
+// Wrap implements middleware.Interface.  Only requests with a query, i.e.
+// instant and range queries, are recorded.
+func (t *activeQueryTracker) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.FormValue("query")
+		if query == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		var slot int
+		select {
+		case slot = <-t.slots:
+		case <-r.Context().Done():
+			http.Error(w, r.Context().Err().Error(), http.StatusServiceUnavailable)
+			return
+		}
+		defer func() {
+			t.write(slot, nil)
+			t.slots <- slot
+		}()
+
+		userID, _ := user.ExtractOrgID(r.Context())
+		t.write(slot, encodeActiveQuery(activeQueryEntry{
+			Query:     query,
+			User:      userID,
+			Timestamp: time.Now().Unix(),
+		}))
+		next.ServeHTTP(w, r)
+	})
+}
+
+// encodeActiveQuery returns the entry's JSON, truncating the query to fit in
+// a slot if need be.
+func encodeActiveQuery(e activeQueryEntry) []byte {
+	for {
+		buf, err := json.Marshal(e)
+		if err != nil {
+			return nil
+		}
+		excess := len(buf) - activeQueryEntrySize
+		switch {
+		case excess <= 0:
+			return buf
+		case e.Query == "":
+			return nil
+		case excess > len(e.Query):
+			// Mostly escaped characters.
+			e.Query = e.Query[:len(e.Query)/2]
+		default:
+			e.Query = e.Query[:len(e.Query)-excess]
+		}
+	}
+}
+
+// write overwrites the slot with the entry, empty slots being blank.
+func (t *activeQueryTracker) write(slot int, entry []byte) {
+	buf := bytes.Repeat([]byte{' '}, activeQueryEntrySize)
+	copy(buf, entry)
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	if _, err := t.file.WriteAt(buf, int64(slot*activeQueryEntrySize)); err != nil {
+		level.Error(t.logger).Log("msg", "error writing to the active query tracker's file", "err", err)
+	}
+}
@@ -0,0 +1,64 @@
+package querier
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActiveQueryTracker(t *testing.T) {
+	dir, err := ioutil.TempDir("", "active-query-tracker")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	var logs bytes.Buffer
+	tracker, err := ActiveQueryTrackerMiddleware(dir, 2, log.NewLogfmtLogger(&logs))
+	require.NoError(t, err)
+	require.Empty(t, logs.String())
+
+	// A query which never finishes, as if the querier crashed while running it.
+	first := true
+	inflight := make(chan struct{})
+	block := make(chan struct{})
+	defer close(block)
+	handler := tracker.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if first {
+			first = false
+			close(inflight)
+			<-block
+		}
+	}))
+	go handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/v1/query?query=up", nil))
+	<-inflight
+
+	// Finished queries are cleared from the file, and overly long ones truncated.
+	long := strings.Repeat("a", 2*activeQueryEntrySize)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/v1/query?query="+long, nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/v1/query?query=rate(foo[5m])", nil))
+
+	buf, err := ioutil.ReadFile(filepath.Join(dir, activeQueriesFilename))
+	require.NoError(t, err)
+	require.Len(t, buf, 2*activeQueryEntrySize)
+
+	// On restart, the unfinished query is logged.
+	_, err = ActiveQueryTrackerMiddleware(dir, 2, log.NewLogfmtLogger(&logs))
+	require.NoError(t, err)
+	require.Contains(t, logs.String(), "query=up")
+	require.NotContains(t, logs.String(), "rate(foo[5m])")
+	require.NotContains(t, logs.String(), "aaa")
+}
+
+func TestEncodeActiveQuery(t *testing.T) {
+	// Escaped characters take more room in the slot than in the query.
+	buf := encodeActiveQuery(activeQueryEntry{Query: strings.Repeat("\"", activeQueryEntrySize), User: "1"})
+	require.True(t, len(buf) <= activeQueryEntrySize)
+	require.Contains(t, string(buf), `"query":"\"`)
+}
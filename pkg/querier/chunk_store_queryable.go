@@ -2,8 +2,11 @@ package querier
 
 import (
 	"context"
+	"hash/crc32"
 
 	"github.com/cortexproject/cortex/pkg/ingester/client"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/pkg/labels"
 	"github.com/prometheus/prometheus/promql"
@@ -12,12 +15,27 @@ import (
 	"github.com/cortexproject/cortex/pkg/chunk"
 )
 
+var (
+	queriedChunks = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "cortex",
+		Name:      "querier_chunks_total",
+		Help:      "Number of chunks fetched from the ingesters and the chunk store by queries.",
+	})
+	duplicateChunks = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "cortex",
+		Name:      "querier_duplicate_chunks_total",
+		Help:      "Number of chunks fetched by queries discarded as duplicates of others of the same series.",
+	})
+
+	castagnoliTable = crc32.MakeTable(crc32.Castagnoli)
+)
+
 type chunkIteratorFunc func(chunks []chunk.Chunk, from, through model.Time) storage.SeriesIterator
 
 func newChunkStoreQueryable(store ChunkStore, chunkIteratorFunc chunkIteratorFunc) storage.Queryable {
 	return storage.QueryableFunc(func(ctx context.Context, mint, maxt int64) (storage.Querier, error) {
 		return &chunkStoreQuerier{
-			store:             store,
+			store:             storeStatsChunkStore{store},
 			chunkIteratorFunc: chunkIteratorFunc,
 			ctx:               ctx,
 			mint:              mint,
@@ -53,7 +71,7 @@ func (q *chunkStoreQuerier) partitionChunks(chunks []chunk.Chunk) storage.Series
 	for i := range chunksBySeries {
 		series = append(series, &chunkSeries{
 			labels:            chunksBySeries[i][0].Metric,
-			chunks:            chunksBySeries[i],
+			chunks:            dedupeChunks(chunksBySeries[i]),
 			chunkIteratorFunc: q.chunkIteratorFunc,
 			mint:              q.mint,
 			maxt:              q.maxt,
@@ -63,6 +81,43 @@ func (q *chunkStoreQuerier) partitionChunks(chunks []chunk.Chunk) storage.Series
 	return newConcreteSeriesSet(series)
 }
 
+type chunkKey struct {
+	from, through model.Time
+	checksum      uint32
+}
+
+// dedupeChunks removes the duplicates from the chunks of a series: each of
+// the ingesters a series is replicated to returns the same chunks for it, as
+// can the chunk store for the chunks they flushed, so they needn't be
+// iterated over and merged more than once.  Chunks are considered the same if
+// they cover the same time range and their data has the same checksum.
+func dedupeChunks(chunks []chunk.Chunk) []chunk.Chunk {
+	queriedChunks.Add(float64(len(chunks)))
+	if len(chunks) < 2 {
+		return chunks
+	}
+
+	seen := make(map[chunkKey]struct{}, len(chunks))
+	result := chunks[:0]
+	for _, c := range chunks {
+		h := crc32.New(castagnoliTable)
+		if err := c.Data.Marshal(h); err != nil {
+			// Keep what can't be checksummed; iterators merge duplicates anyway.
+			result = append(result, c)
+			continue
+		}
+
+		key := chunkKey{from: c.From, through: c.Through, checksum: h.Sum32()}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		result = append(result, c)
+	}
+	duplicateChunks.Add(float64(len(chunks) - len(result)))
+	return result
+}
+
 func (q *chunkStoreQuerier) LabelValues(name string) ([]string, error) {
 	return nil, nil
 }
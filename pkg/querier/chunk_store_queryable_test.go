@@ -66,3 +66,14 @@ func mkChunk(t require.TestingT, mint, maxt model.Time, step time.Duration, enco
 	}
 	return chunk.NewChunk(userID, fp, metric, pc, mint, maxt)
 }
+
+func TestDedupeChunks(t *testing.T) {
+	a := mkChunk(t, 0, 100, time.Millisecond, promchunk.Bigchunk)
+	b := mkChunk(t, 100, 200, time.Millisecond, promchunk.Bigchunk)
+	// Same time range, different samples.
+	c := mkChunk(t, 0, 100, 2*time.Millisecond, promchunk.Bigchunk)
+
+	chunks := dedupeChunks([]chunk.Chunk{a, b, a, c, b, a})
+	require.Len(t, chunks, 3)
+	require.Equal(t, []chunk.Chunk{a, b, c}, chunks)
+}
@@ -0,0 +1,118 @@
+package querier
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/weaveworks/common/middleware"
+)
+
+var (
+	inflightRequests = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "cortex",
+		Name:      "querier_inflight_requests",
+		Help:      "Number of query requests being executed by the querier.",
+	})
+	queuedRequests = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "cortex",
+		Name:      "querier_queued_requests",
+		Help:      "Number of query requests waiting for one of the querier's -querier.max-concurrent slots.",
+	})
+	rejectedRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "cortex",
+		Name:      "querier_rejected_requests_total",
+		Help:      "Number of query requests rejected by the querier as its queue was full or they waited too long.",
+	}, []string{"reason"})
+)
+
+// ConcurrencyLimitMiddleware limits the number of requests executed at once
+// to maxConcurrent.  Requests over the limit queue for a slot, up to
+// maxQueued of them for at most timeout each; requests which don't get a slot
+// are rejected with a 503, rather than piling up until the querier runs out
+// of memory.  The frontend retries them, on this or another querier.
+//
+// Unlike the PromQL engine's own limit this applies to all of the query API's
+// requests, including remote read and the series and label endpoints.  A
+// maxConcurrent of 0 disables the limit.
+func ConcurrencyLimitMiddleware(maxConcurrent, maxQueued int, timeout time.Duration) middleware.Interface {
+	if maxConcurrent <= 0 {
+		return middleware.Func(func(next http.Handler) http.Handler { return next })
+	}
+	l := &concurrencyLimiter{
+		slots:     make(chan struct{}, maxConcurrent),
+		maxQueued: maxQueued,
+		timeout:   timeout,
+	}
+	return middleware.Func(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if reason := l.acquire(r); reason != "" {
+				rejectedRequests.WithLabelValues(reason).Inc()
+				http.Error(w, "too many outstanding requests ("+reason+")", http.StatusServiceUnavailable)
+				return
+			}
+			defer l.release()
+			next.ServeHTTP(w, r)
+		})
+	})
+}
+
+type concurrencyLimiter struct {
+	slots     chan struct{}
+	maxQueued int
+	timeout   time.Duration
+
+	mtx    sync.Mutex
+	queued int
+}
+
+// acquire takes a slot, waiting for one if need be, and returns the reason
+// if it couldn't.
+func (l *concurrencyLimiter) acquire(r *http.Request) string {
+	select {
+	case l.slots <- struct{}{}:
+		inflightRequests.Inc()
+		return ""
+	default:
+	}
+
+	l.mtx.Lock()
+	if l.queued >= l.maxQueued {
+		l.mtx.Unlock()
+		return "queue_full"
+	}
+	l.queued++
+	l.mtx.Unlock()
+	queuedRequests.Inc()
+
+	defer func() {
+		l.mtx.Lock()
+		l.queued--
+		l.mtx.Unlock()
+		queuedRequests.Dec()
+	}()
+
+	var timeoutC <-chan time.Time
+	if l.timeout > 0 {
+		timer := time.NewTimer(l.timeout)
+		defer timer.Stop()
+		timeoutC = timer.C
+	}
+
+	select {
+	case l.slots <- struct{}{}:
+		inflightRequests.Inc()
+		return ""
+	case <-timeoutC:
+		return "timeout"
+	case <-r.Context().Done():
+		return "canceled"
+	}
+}
+
+func (l *concurrencyLimiter) release() {
+	inflightRequests.Dec()
+	<-l.slots
+}
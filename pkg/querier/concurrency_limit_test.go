@@ -0,0 +1,56 @@
+package querier
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConcurrencyLimitMiddleware(t *testing.T) {
+	var (
+		started = make(chan struct{})
+		unblock = make(chan struct{})
+	)
+	handler := ConcurrencyLimitMiddleware(1, 1, 50*time.Millisecond).Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/block" {
+			started <- struct{}{}
+			<-unblock
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	do := func(path string) int {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest("GET", path, nil))
+		return recorder.Code
+	}
+
+	// Take the only slot.
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		require.Equal(t, http.StatusOK, do("/block"))
+	}()
+	<-started
+
+	// Queued requests time out.
+	require.Equal(t, http.StatusServiceUnavailable, do("/"))
+
+	// Requests over the queue limit are rejected straight away, while queued
+	// ones get the slot once it is free.
+	queued := make(chan int)
+	go func() {
+		queued <- do("/")
+	}()
+	time.Sleep(10 * time.Millisecond)
+	require.Equal(t, http.StatusServiceUnavailable, do("/"))
+	close(unblock)
+	require.Equal(t, http.StatusOK, <-queued)
+	wg.Wait()
+
+	require.Equal(t, http.StatusOK, do("/"))
+}
@@ -2,6 +2,7 @@ package querier
 
 import (
 	"context"
+	"time"
 
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/pkg/labels"
@@ -10,6 +11,7 @@ import (
 
 	"github.com/cortexproject/cortex/pkg/ingester/client"
 	"github.com/cortexproject/cortex/pkg/prom1/storage/metric"
+	"github.com/cortexproject/cortex/pkg/querier/stats"
 )
 
 // Distributor is the read interface to the distributor, made an interface here
@@ -20,6 +22,8 @@ type Distributor interface {
 	LabelValuesForLabelName(context.Context, model.LabelName) ([]string, error)
 	LabelNames(context.Context) ([]string, error)
 	MetricsForLabelMatchers(ctx context.Context, from, through model.Time, matchers ...*labels.Matcher) ([]metric.Metric, error)
+	QueryExemplars(ctx context.Context, from, through model.Time, matchersSet ...[]*labels.Matcher) (*client.ExemplarQueryResponse, error)
+	MetricsMetadata(ctx context.Context) ([]*client.MetricMetadata, error)
 }
 
 func newDistributorQueryable(distributor Distributor) storage.Queryable {
@@ -46,10 +50,14 @@ func (q *distributorQuerier) Select(sp *storage.SelectParams, matchers ...*label
 		maxt = sp.End
 	}
 
+	start := time.Now()
 	matrix, err := q.distributor.Query(q.ctx, model.Time(mint), model.Time(maxt), matchers...)
 	if err != nil {
 		return nil, nil, promql.ErrStorage{Err: err}
 	}
+	s := stats.FromContext(q.ctx)
+	s.AddIngesterTime(time.Since(start))
+	s.AddIngesterSeries(len(matrix))
 
 	return matrixToSeriesSet(matrix), nil, nil
 }
@@ -51,8 +51,11 @@ func TestDistributorQuerier(t *testing.T) {
 }
 
 type mockDistributor struct {
-	m model.Matrix
-	r []client.TimeSeriesChunk
+	m         model.Matrix
+	r         []client.TimeSeriesChunk
+	metrics   []metric.Metric
+	exemplars []client.TimeSeries
+	metadata  []*client.MetricMetadata
 }
 
 func (m *mockDistributor) Query(ctx context.Context, from, to model.Time, matchers ...*labels.Matcher) (model.Matrix, error) {
@@ -68,5 +71,21 @@ func (m *mockDistributor) LabelNames(context.Context) ([]string, error) {
 	return nil, nil
 }
 func (m *mockDistributor) MetricsForLabelMatchers(ctx context.Context, from, through model.Time, matchers ...*labels.Matcher) ([]metric.Metric, error) {
-	return nil, nil
+	var result []metric.Metric
+outer:
+	for _, metric := range m.metrics {
+		for _, matcher := range matchers {
+			if !matcher.Matches(string(metric.Metric[model.LabelName(matcher.Name)])) {
+				continue outer
+			}
+		}
+		result = append(result, metric)
+	}
+	return result, nil
+}
+func (m *mockDistributor) QueryExemplars(ctx context.Context, from, through model.Time, matchersSet ...[]*labels.Matcher) (*client.ExemplarQueryResponse, error) {
+	return &client.ExemplarQueryResponse{Timeseries: m.exemplars}, nil
+}
+func (m *mockDistributor) MetricsMetadata(ctx context.Context) ([]*client.MetricMetadata, error) {
+	return m.metadata, nil
 }
@@ -0,0 +1,96 @@
+package querier
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/pkg/timestamp"
+	"github.com/prometheus/prometheus/promql"
+
+	"github.com/cortexproject/cortex/pkg/ingester/client"
+)
+
+type exemplarSeries struct {
+	SeriesLabels model.Metric `json:"seriesLabels"`
+	Exemplars    []exemplar   `json:"exemplars"`
+}
+
+type exemplar struct {
+	Labels    model.Metric `json:"labels"`
+	Value     string       `json:"value"`
+	Timestamp float64      `json:"timestamp"`
+}
+
+// ExemplarsHandler serves the Prometheus API's /api/v1/query_exemplars, with
+// the exemplars the ingesters hold for the series selected by the query.
+// Exemplars are only kept in memory, so older ones than the ingesters' buffers
+// hold aren't returned.
+func ExemplarsHandler(distributor Distributor) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		expr, err := promql.ParseExpr(r.FormValue("query"))
+		if err != nil {
+			writeError(w, "bad_data", http.StatusBadRequest, err)
+			return
+		}
+
+		from, through := minTime, maxTime
+		if s := r.FormValue("start"); s != "" {
+			t, err := parseQueryTime(s)
+			if err != nil {
+				writeError(w, "bad_data", http.StatusBadRequest, err)
+				return
+			}
+			from = timestamp.FromTime(t)
+		}
+		if s := r.FormValue("end"); s != "" {
+			t, err := parseQueryTime(s)
+			if err != nil {
+				writeError(w, "bad_data", http.StatusBadRequest, err)
+				return
+			}
+			through = timestamp.FromTime(t)
+		}
+
+		result := []exemplarSeries{}
+		if matchersSet := selectors(expr); len(matchersSet) > 0 {
+			resp, err := distributor.QueryExemplars(r.Context(), model.Time(from), model.Time(through), matchersSet...)
+			if err != nil {
+				writeQueryError(w, promql.ErrStorage{Err: err})
+				return
+			}
+			for _, ts := range resp.Timeseries {
+				series := exemplarSeries{
+					SeriesLabels: client.FromLabelAdaptersToMetric(ts.Labels),
+					Exemplars:    make([]exemplar, 0, len(ts.Exemplars)),
+				}
+				for _, e := range ts.Exemplars {
+					series.Exemplars = append(series.Exemplars, exemplar{
+						Labels:    client.FromLabelAdaptersToMetric(e.Labels),
+						Value:     strconv.FormatFloat(e.Value, 'f', -1, 64),
+						Timestamp: float64(e.TimestampMs) / 1e3,
+					})
+				}
+				result = append(result, series)
+			}
+		}
+
+		writeSuccess(w, r, result)
+	})
+}
+
+// selectors returns the label matchers of each of the expression's selectors.
+func selectors(expr promql.Expr) [][]*labels.Matcher {
+	var matchersSet [][]*labels.Matcher
+	promql.Inspect(expr, func(node promql.Node, _ []promql.Node) error {
+		switch n := node.(type) {
+		case *promql.VectorSelector:
+			matchersSet = append(matchersSet, n.LabelMatchers)
+		case *promql.MatrixSelector:
+			matchersSet = append(matchersSet, n.LabelMatchers)
+		}
+		return nil
+	})
+	return matchersSet
+}
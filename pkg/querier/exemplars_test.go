@@ -0,0 +1,58 @@
+package querier
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cortexproject/cortex/pkg/ingester/client"
+)
+
+func TestExemplarsHandler(t *testing.T) {
+	handler := ExemplarsHandler(&mockDistributor{
+		exemplars: []client.TimeSeries{{
+			Labels: []client.LabelAdapter{{Name: "__name__", Value: "foo"}},
+			Exemplars: []client.Exemplar{{
+				Labels:      []client.LabelAdapter{{Name: "traceID", Value: "abc"}},
+				Value:       1.5,
+				TimestampMs: 1500,
+			}},
+		}},
+	})
+
+	for _, tc := range []struct {
+		url      string
+		code     int
+		expected string
+	}{
+		{
+			url:      "/api/v1/query_exemplars?query=rate(foo[5m])&start=0&end=10",
+			code:     http.StatusOK,
+			expected: `{"data":[{"seriesLabels":{"__name__":"foo"},"exemplars":[{"labels":{"traceID":"abc"},"value":"1.5","timestamp":1.5}]}],"status":"success"}`,
+		},
+		{
+			url:      "/api/v1/query_exemplars?query=1",
+			code:     http.StatusOK,
+			expected: `{"data":[],"status":"success"}`,
+		},
+		{
+			url:  "/api/v1/query_exemplars?query=rate(foo",
+			code: http.StatusBadRequest,
+		},
+		{
+			url:  "/api/v1/query_exemplars?query=foo&start=bar",
+			code: http.StatusBadRequest,
+		},
+	} {
+		t.Run(tc.url, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest("GET", tc.url, nil))
+			require.Equal(t, tc.code, recorder.Code)
+			if tc.expected != "" {
+				require.Equal(t, tc.expected, recorder.Body.String())
+			}
+		})
+	}
+}
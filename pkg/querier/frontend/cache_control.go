@@ -0,0 +1,80 @@
+package frontend
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync/atomic"
+)
+
+const (
+	cacheControlHeader = "Cache-Control"
+	noStoreValue       = "no-store"
+)
+
+// hasNoStore returns whether the headers have a Cache-Control no-store directive.
+func hasNoStore(h http.Header) bool {
+	for _, value := range h[cacheControlHeader] {
+		for _, directive := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(directive), noStoreValue) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// cacheControl carries the Cache-Control directives of a query through the
+// middlewares: whether the client asked for it not to be served from or stored
+// in the caches, and whether any of the responses from downstream asked not to
+// be stored.  All methods are safe to call on a nil *cacheControl.
+type cacheControl struct {
+	parent  *cacheControl
+	noStore bool
+	// Set atomically, as sub-queries are sent downstream concurrently.
+	downstreamNoStore int32
+}
+
+// withCacheControl returns a context carrying the Cache-Control directives of
+// the client's request.
+func withCacheControl(ctx context.Context, h http.Header) (*cacheControl, context.Context) {
+	c := &cacheControl{noStore: hasNoStore(h)}
+	return c, context.WithValue(ctx, cacheControlKey, c)
+}
+
+// withDownstreamCacheControl returns a context tracking the responses from
+// downstream separately from those of the rest of the query, so a cache can
+// tell whether the results it fetched itself can be stored.  Downstream
+// no-store directives still propagate to the query's cacheControl.
+func withDownstreamCacheControl(ctx context.Context) (*cacheControl, context.Context) {
+	parent := cacheControlFromContext(ctx)
+	c := &cacheControl{parent: parent, noStore: parent.requestNoStore()}
+	return c, context.WithValue(ctx, cacheControlKey, c)
+}
+
+func cacheControlFromContext(ctx context.Context) *cacheControl {
+	c, _ := ctx.Value(cacheControlKey).(*cacheControl)
+	return c
+}
+
+// observeCacheControl records a no-store directive in a downstream response.
+func observeCacheControl(ctx context.Context, resp *http.Response) {
+	if !hasNoStore(resp.Header) {
+		return
+	}
+	for c := cacheControlFromContext(ctx); c != nil; c = c.parent {
+		atomic.StoreInt32(&c.downstreamNoStore, 1)
+	}
+}
+
+// requestNoStore returns whether the client asked for the query not to be
+// served from or stored in the caches.
+func (c *cacheControl) requestNoStore() bool {
+	return c != nil && c.noStore
+}
+
+// responseNoStore returns whether any response from downstream asked not to
+// be stored.
+func (c *cacheControl) responseNoStore() bool {
+	return c != nil && atomic.LoadInt32(&c.downstreamNoStore) != 0
+}
@@ -0,0 +1,121 @@
+package frontend
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+
+	"github.com/cortexproject/cortex/pkg/chunk/cache"
+)
+
+func TestHasNoStore(t *testing.T) {
+	for _, tc := range []struct {
+		values   []string
+		expected bool
+	}{
+		{nil, false},
+		{[]string{"no-cache"}, false},
+		{[]string{"no-store"}, true},
+		{[]string{"max-age=0, No-Store"}, true},
+		{[]string{"private", "no-store"}, true},
+	} {
+		h := http.Header{}
+		for _, v := range tc.values {
+			h.Add(cacheControlHeader, v)
+		}
+		require.Equal(t, tc.expected, hasNoStore(h), "%v", tc.values)
+	}
+}
+
+func TestResultsCacheRequestNoStore(t *testing.T) {
+	rcm, _, err := newResultsCacheMiddleware(ResultsCacheConfig{
+		CacheConfig: cache.Config{
+			Cache: cache.NewMockCache(),
+		},
+	}, day, defaultOverrides(t))
+	require.NoError(t, err)
+
+	calls := 0
+	rc := rcm.Wrap(QueryRangeHandlerFunc(func(_ context.Context, req *QueryRangeRequest) (*APIResponse, error) {
+		calls++
+		return parsedResponse, nil
+	}))
+	ctx := user.InjectOrgID(context.Background(), "1")
+	_, noStoreCtx := withCacheControl(ctx, http.Header{cacheControlHeader: []string{noStoreValue}})
+
+	// Requests with no-store neither store their results...
+	_, err = rc.Do(noStoreCtx, parsedRequest)
+	require.NoError(t, err)
+	_, err = rc.Do(ctx, parsedRequest)
+	require.NoError(t, err)
+	require.Equal(t, 2, calls)
+
+	// ...nor read the results stored for others.
+	_, err = rc.Do(noStoreCtx, parsedRequest)
+	require.NoError(t, err)
+	require.Equal(t, 3, calls)
+}
+
+func TestResultsCacheResponseNoStore(t *testing.T) {
+	rcm, _, err := newResultsCacheMiddleware(ResultsCacheConfig{
+		CacheConfig: cache.Config{
+			Cache: cache.NewMockCache(),
+		},
+	}, day, defaultOverrides(t))
+	require.NoError(t, err)
+
+	calls := 0
+	rc := rcm.Wrap(queryRangeTerminator{
+		next: RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			calls++
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{cacheControlHeader: []string{noStoreValue}},
+				Body:       ioutil.NopCloser(strings.NewReader(responseBody)),
+			}, nil
+		}),
+	})
+
+	cc, ctx := withCacheControl(user.InjectOrgID(context.Background(), "1"), nil)
+	for i := 0; i < 2; i++ {
+		_, err = rc.Do(ctx, parsedRequest)
+		require.NoError(t, err)
+	}
+	require.Equal(t, 2, calls)
+
+	// The directive is passed on to the query's response.
+	require.True(t, cc.responseNoStore())
+}
+
+func TestSetCacheControl(t *testing.T) {
+	q := queryRangeRoundTripper{maxCacheFreshness: 10 * time.Minute}
+	now := model.Now()
+
+	for _, tc := range []struct {
+		name     string
+		end      model.Time
+		noStore  bool
+		expected string
+	}{
+		{"old data", now.Add(-time.Hour), false, ""},
+		{"recent data", now.Add(-time.Minute), false, noStoreValue},
+		{"downstream no-store", now.Add(-time.Hour), true, noStoreValue},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cc, _ := withCacheControl(context.Background(), nil)
+			if tc.noStore {
+				cc.downstreamNoStore = 1
+			}
+			resp := &http.Response{Header: http.Header{}}
+			q.setCacheControl(resp, cc, int64(tc.end))
+			require.Equal(t, tc.expected, resp.Header.Get(cacheControlHeader))
+		})
+	}
+}
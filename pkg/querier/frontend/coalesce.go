@@ -0,0 +1,106 @@
+package frontend
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/weaveworks/common/user"
+	"google.golang.org/grpc/codes"
+	grpc_status "google.golang.org/grpc/status"
+)
+
+var coalescedQueries = promauto.NewCounter(prometheus.CounterOpts{
+	Namespace: "cortex",
+	Name:      "frontend_coalesced_queries_total",
+	Help:      "Total number of query range requests answered by an identical request already in flight.",
+})
+
+// coalesceMiddleware attaches requests to any identical request (same
+// tenant, query, range and step) already being executed, instead of
+// executing them again.
+var coalesceMiddleware = QueryRangeMiddlewareFunc(func(next QueryRangeHandler) QueryRangeHandler {
+	return &coalescer{
+		next:     next,
+		inflight: map[string]*inflightQuery{},
+	}
+})
+
+type coalescer struct {
+	next QueryRangeHandler
+
+	mtx      sync.Mutex
+	inflight map[string]*inflightQuery
+}
+
+type inflightQuery struct {
+	done chan struct{}
+	resp *APIResponse
+	err  error
+
+	// Whether the request executing the query was cancelled or timed out,
+	// whatever error that was returned as.
+	cancelled bool
+}
+
+func (c *coalescer) Do(ctx context.Context, r *QueryRangeRequest) (*APIResponse, error) {
+	userID, err := user.ExtractOrgID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	key := fmt.Sprintf("%s:%s:%d:%d:%d", userID, r.Query, r.Start, r.End, r.Step)
+
+	c.mtx.Lock()
+	if query, ok := c.inflight[key]; ok {
+		c.mtx.Unlock()
+		return c.wait(ctx, r, query)
+	}
+	query := &inflightQuery{done: make(chan struct{})}
+	c.inflight[key] = query
+	c.mtx.Unlock()
+
+	query.resp, query.err = c.next.Do(ctx, r)
+	query.cancelled = ctx.Err() != nil || isCancellation(query.err)
+
+	c.mtx.Lock()
+	delete(c.inflight, key)
+	c.mtx.Unlock()
+	close(query.done)
+
+	return query.resp, query.err
+}
+
+// wait for the in flight query to finish.  It runs with the context of the
+// request which started it, so if that was cancelled by its client the
+// request is executed again in its own context.
+func (c *coalescer) wait(ctx context.Context, r *QueryRangeRequest, query *inflightQuery) (*APIResponse, error) {
+	select {
+	case <-query.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	if query.cancelled && ctx.Err() == nil {
+		return c.Do(ctx, r)
+	}
+	coalescedQueries.Inc()
+	return query.resp, query.err
+}
+
+// isCancellation returns whether the error is that of a cancelled or timed
+// out request, as returned by the context, by gRPC, or by the frontend.
+func isCancellation(err error) bool {
+	if err == nil {
+		return false
+	}
+	if err == context.Canceled || err == context.DeadlineExceeded || err == errCanceled {
+		return true
+	}
+	switch grpc_status.Code(err) {
+	case codes.Canceled, codes.DeadlineExceeded:
+		return true
+	}
+	return false
+}
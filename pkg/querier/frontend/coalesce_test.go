@@ -0,0 +1,121 @@
+package frontend
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+	"google.golang.org/grpc/codes"
+	grpc_status "google.golang.org/grpc/status"
+)
+
+func TestCoalesce(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	handler := coalesceMiddleware.Wrap(QueryRangeHandlerFunc(func(ctx context.Context, req *QueryRangeRequest) (*APIResponse, error) {
+		atomic.AddInt32(&calls, 1)
+		select {
+		case <-release:
+			return parsedResponse, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}))
+	ctx := user.InjectOrgID(context.Background(), "1")
+
+	// Identical requests share a single downstream request.
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := handler.Do(ctx, parsedRequest)
+			require.NoError(t, err)
+			require.Equal(t, parsedResponse, resp)
+		}()
+	}
+	// Those for another tenant or range don't.
+	other := parsedRequest.copy()
+	other.End += other.Step
+	for _, req := range []struct {
+		ctx context.Context
+		req *QueryRangeRequest
+	}{
+		{user.InjectOrgID(context.Background(), "2"), parsedRequest},
+		{ctx, &other},
+	} {
+		wg.Add(1)
+		go func(ctx context.Context, req *QueryRangeRequest) {
+			defer wg.Done()
+			_, err := handler.Do(ctx, req)
+			require.NoError(t, err)
+		}(req.ctx, req.req)
+	}
+
+	for atomic.LoadInt32(&calls) < 3 {
+		time.Sleep(time.Millisecond)
+	}
+	// Give the identical requests time to attach to the first.
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	require.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestCoalesceFirstRequestCancelled(t *testing.T) {
+	for name, cancelErr := range map[string]func(ctx context.Context) error{
+		"context":  func(ctx context.Context) error { return ctx.Err() },
+		"frontend": func(context.Context) error { return errCanceled },
+		"grpc":     func(context.Context) error { return grpc_status.Error(codes.Canceled, "context canceled") },
+	} {
+		t.Run(name, func(t *testing.T) {
+			testCoalesceFirstRequestCancelled(t, cancelErr)
+		})
+	}
+}
+
+func testCoalesceFirstRequestCancelled(t *testing.T, cancelErr func(context.Context) error) {
+	var calls int32
+	release := make(chan struct{})
+	handler := coalesceMiddleware.Wrap(QueryRangeHandlerFunc(func(ctx context.Context, req *QueryRangeRequest) (*APIResponse, error) {
+		atomic.AddInt32(&calls, 1)
+		select {
+		case <-release:
+			return parsedResponse, nil
+		case <-ctx.Done():
+			return nil, cancelErr(ctx)
+		}
+	}))
+
+	firstCtx, cancel := context.WithCancel(user.InjectOrgID(context.Background(), "1"))
+	firstErr := make(chan error)
+	go func() {
+		_, err := handler.Do(firstCtx, parsedRequest)
+		firstErr <- err
+	}()
+	for atomic.LoadInt32(&calls) < 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	second := make(chan *APIResponse)
+	go func() {
+		resp, err := handler.Do(user.InjectOrgID(context.Background(), "1"), parsedRequest)
+		require.NoError(t, err)
+		second <- resp
+	}()
+
+	// The first request's client going away doesn't fail the second, which is
+	// executed again on its behalf.
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	require.Error(t, <-firstErr)
+	for atomic.LoadInt32(&calls) < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	require.Equal(t, parsedResponse, <-second)
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"flag"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"math/rand"
@@ -12,16 +13,20 @@ import (
 	"time"
 
 	"github.com/NYTimes/gziphandler"
+	"github.com/cortexproject/cortex/pkg/chunk/cache"
 	"github.com/cortexproject/cortex/pkg/util/validation"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 	opentracing "github.com/opentracing/opentracing-go"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/prometheus/promql"
 
 	"github.com/weaveworks/common/httpgrpc"
 	"github.com/weaveworks/common/httpgrpc/server"
 	"github.com/weaveworks/common/user"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 )
 
 var (
@@ -42,31 +47,80 @@ var (
 		Name:      "query_frontend_queue_length",
 		Help:      "Number of queries in the queue.",
 	})
+	queueShare = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "cortex",
+		Name:      "query_frontend_queue_share",
+		Help:      "Share of querier capacity given to each tenant with queued queries.",
+	}, []string{"user"})
+	discardedRequests = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "cortex",
+		Name:      "query_frontend_discarded_requests_total",
+		Help:      "Number of queued requests discarded because their client went away.",
+	})
 
 	errServerClosing  = httpgrpc.Errorf(http.StatusTeapot, "server closing down")
 	errTooManyRequest = httpgrpc.Errorf(http.StatusTooManyRequests, "too many outstanding requests")
 	errCanceled       = httpgrpc.Errorf(http.StatusInternalServerError, "context cancelled")
+	errWorkerStalled  = httpgrpc.Errorf(http.StatusInternalServerError, "querier stopped reporting progress")
 )
 
 // Config for a Frontend.
 type Config struct {
-	MaxOutstandingPerTenant int  `yaml:"max_outstanding_per_tenant"`
-	MaxRetries              int  `yaml:"max_retries"`
-	SplitQueriesByDay       bool `yaml:"split_queries_by_day"`
-	AlignQueriesWithStep    bool `yaml:"align_queries_with_step"`
-	CacheResults            bool `yaml:"cache_results"`
-	CompressResponses       bool `yaml:"compress_responses"`
+	MaxOutstandingPerTenant int           `yaml:"max_outstanding_per_tenant"`
+	MaxRetries              int           `yaml:"max_retries"`
+	MinRetryBackoff         time.Duration `yaml:"min_retry_backoff"`
+	MaxRetryBackoff         time.Duration `yaml:"max_retry_backoff"`
+	RetryBudgetPerTenant    float64       `yaml:"retry_budget_per_tenant"`
+	SplitQueriesByDay       bool          `yaml:"split_queries_by_day"`
+	SplitQueriesByInterval  time.Duration `yaml:"split_queries_by_interval"`
+	AlignQueriesWithStep    bool          `yaml:"align_queries_with_step"`
+	CacheResults            bool          `yaml:"cache_results"`
+	CacheInstantQueries     bool          `yaml:"cache_instant_queries"`
+	CoalesceQueries         bool          `yaml:"coalesce_queries"`
+	QueryShards             int           `yaml:"query_shards"`
+	VerticalShardInterval   time.Duration `yaml:"vertical_shard_interval"`
+	VerticalShardMaxSlices  int           `yaml:"vertical_shard_max_slices"`
+	CompressResponses       bool          `yaml:"compress_responses"`
+	LogQueriesLongerThan    time.Duration `yaml:"log_queries_longer_than"`
+	MetadataCacheTTL        time.Duration `yaml:"metadata_cache_ttl"`
+	LowPriorityShare        float64       `yaml:"low_priority_share"`
+	WorkerStallTimeout      time.Duration `yaml:"worker_stall_timeout"`
 	ResultsCacheConfig      `yaml:"results_cache"`
+
+	// The querier's -querier.max-concurrent-engine-queries, -querier.max-samples
+	// and -querier.timeout, set by the frontend's module, for evaluating the
+	// rest of vertically sharded queries.
+	EngineMaxConcurrent int           `yaml:"-"`
+	EngineMaxSamples    int           `yaml:"-"`
+	EngineTimeout       time.Duration `yaml:"-"`
+
+	// QueryRangeMiddlewares are run for each query range request after it is
+	// checked against the tenant's limits, and before it is split or cached,
+	// for users of this package to mutate, audit or route requests.
+	QueryRangeMiddlewares []QueryRangeMiddleware `yaml:"-"`
 }
 
 // RegisterFlags adds the flags required to config this to the given FlagSet.
 func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	f.IntVar(&cfg.MaxOutstandingPerTenant, "querier.max-outstanding-requests-per-tenant", 100, "Maximum number of outstanding requests per tenant per frontend; requests beyond this error with HTTP 429.")
 	f.IntVar(&cfg.MaxRetries, "querier.max-retries-per-request", 5, "Maximum number of retries for a single request; beyond this, the downstream error is returned.")
-	f.BoolVar(&cfg.SplitQueriesByDay, "querier.split-queries-by-day", false, "Split queries by day and execute in parallel.")
+	f.DurationVar(&cfg.MinRetryBackoff, "querier.min-retry-backoff", 100*time.Millisecond, "Minimum delay before retrying a failed query range request.")
+	f.DurationVar(&cfg.MaxRetryBackoff, "querier.max-retry-backoff", 2*time.Second, "Maximum delay before retrying a failed query range request.")
+	f.Float64Var(&cfg.RetryBudgetPerTenant, "querier.retry-budget-per-tenant", 0, "Maximum number of query range request retries per second per tenant, 0 to disable the budget.")
+	f.BoolVar(&cfg.SplitQueriesByDay, "querier.split-queries-by-day", false, "Deprecated: Split queries by day and execute in parallel. Use -querier.split-queries-by-interval instead.")
+	f.DurationVar(&cfg.SplitQueriesByInterval, "querier.split-queries-by-interval", 0, "Split queries by an interval and execute in parallel, 0 disables it. You should use a multiple of 24 hours (same as the storage bucketing scheme), to avoid queriers downloading and processing the same chunks. This also determines how cache keys are chosen when result caching is enabled.")
 	f.BoolVar(&cfg.AlignQueriesWithStep, "querier.align-querier-with-step", false, "Mutate incoming queries to align their start and end with their step.")
 	f.BoolVar(&cfg.CacheResults, "querier.cache-results", false, "Cache query results.")
+	f.BoolVar(&cfg.CacheInstantQueries, "querier.cache-instant-queries", false, "Cache results of instant queries evaluated before the max cache freshness.")
+	f.BoolVar(&cfg.CoalesceQueries, "frontend.coalesce-queries", false, "Answer query range requests identical to one already being executed with its results, instead of executing them again.")
+	f.IntVar(&cfg.QueryShards, "querier.query-shards", 0, "Split shardable aggregations (sum, min, max and count of series-wise expressions) into this many partial queries over disjoint sets of series, executed in parallel. 0 or 1 disables it.")
+	f.DurationVar(&cfg.VerticalShardInterval, "querier.vertical-shard-interval", 0, "Split the range vectors of rate() and increase() calls longer than this into slices of this length, evaluated in parallel, in queries which can't be sharded by series. The results can differ slightly from evaluating the query in one piece. 0 disables it.")
+	f.IntVar(&cfg.VerticalShardMaxSlices, "querier.vertical-shard-max-slices", 8, "Maximum number of slices a range vector is split into by -querier.vertical-shard-interval; longer ranges get longer slices.")
 	f.BoolVar(&cfg.CompressResponses, "querier.compress-http-responses", false, "Compress HTTP responses.")
+	f.DurationVar(&cfg.MetadataCacheTTL, "frontend.metadata-cache-ttl", 0, "How long to cache responses to series and label requests, in the results cache. 0 disables caching them.")
+	f.Float64Var(&cfg.LowPriorityShare, "frontend.low-priority-share", 0.1, "Share of the requests sent to queriers given to low priority requests, while there are both high and low priority requests queued. Low priority requests are those with the X-Cortex-Query-Priority: low header.")
+	f.DurationVar(&cfg.WorkerStallTimeout, "frontend.worker-stall-timeout", 0, "Fail requests, to be retried, when the querier processing them hasn't reported progress for this long. Only queriers which pull requests report progress. 0 to disable.")
+	f.DurationVar(&cfg.LogQueriesLongerThan, "frontend.log-queries-longer-than", 0, "Log queries that take longer than this, with how long each stage of the frontend took. 0 to disable.")
 	cfg.ResultsCacheConfig.RegisterFlags(f)
 }
 
@@ -75,11 +129,18 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 type Frontend struct {
 	cfg          Config
 	log          log.Logger
+	limits       *validation.Overrides
 	roundTripper http.RoundTripper
+	resultsCache cache.Cache
 
 	mtx    sync.Mutex
 	cond   *sync.Cond
 	queues map[string]chan *request
+	// Queues of low priority requests, only served ahead of those in queues
+	// for a share of the requests.
+	lowPriorityQueues map[string]chan *request
+	// Number of Process streams open, by querier address.
+	workers map[string]int
 }
 
 type request struct {
@@ -97,36 +158,101 @@ func New(cfg Config, log log.Logger, limits *validation.Overrides) (*Frontend, e
 	f := &Frontend{
 		cfg:    cfg,
 		log:    log,
+		limits: limits,
 		queues: map[string]chan *request{},
+
+		lowPriorityQueues: map[string]chan *request{},
+		workers:           map[string]int{},
+	}
+
+	// For backwards compatibility, -querier.split-queries-by-day is the same as
+	// splitting by a 24h interval.
+	if cfg.SplitQueriesByDay && cfg.SplitQueriesByInterval == 0 {
+		cfg.SplitQueriesByInterval = 24 * time.Hour
 	}
 
-	// Stack up the pipeline of various query range middlewares.
-	queryRangeMiddleware := []queryRangeMiddleware{}
+	// Stack up the pipeline of various query range middlewares, starting with
+	// rejecting requests over the tenant's limits.
+	queryRangeMiddleware := []QueryRangeMiddleware{limitsMiddleware(limits)}
+	queryRangeMiddleware = append(queryRangeMiddleware, cfg.QueryRangeMiddlewares...)
 	if cfg.AlignQueriesWithStep {
 		queryRangeMiddleware = append(queryRangeMiddleware, stepAlignMiddleware)
 	}
-	if cfg.SplitQueriesByDay {
-		queryRangeMiddleware = append(queryRangeMiddleware, splitByDayMiddleware(limits))
+	if cfg.CoalesceQueries {
+		queryRangeMiddleware = append(queryRangeMiddleware, coalesceMiddleware)
+	}
+	if cfg.SplitQueriesByInterval != 0 {
+		queryRangeMiddleware = append(queryRangeMiddleware, splitByIntervalMiddleware(cfg.SplitQueriesByInterval, limits))
 	}
 	if cfg.CacheResults {
-		queryCacheMiddleware, err := newResultsCacheMiddleware(cfg.ResultsCacheConfig, limits)
+		// Cached results are bucketed by day, unless queries are split by a
+		// different interval.
+		cacheInterval := 24 * time.Hour
+		if cfg.SplitQueriesByInterval != 0 {
+			cacheInterval = cfg.SplitQueriesByInterval
+		}
+		queryCacheMiddleware, resultsCache, err := newResultsCacheMiddleware(cfg.ResultsCacheConfig, cacheInterval, limits)
 		if err != nil {
 			return nil, err
 		}
+		f.resultsCache = resultsCache
 		queryRangeMiddleware = append(queryRangeMiddleware, instrument("results_cache"), queryCacheMiddleware)
 	}
+	if cfg.QueryShards > 1 {
+		queryRangeMiddleware = append(queryRangeMiddleware, queryShardingMiddleware(cfg.QueryShards, limits))
+	}
+	if cfg.VerticalShardInterval != 0 {
+		if cfg.EngineMaxConcurrent <= 0 || cfg.EngineMaxSamples <= 0 || cfg.EngineTimeout <= 0 {
+			return nil, fmt.Errorf("vertical sharding needs the querier's engine limits")
+		}
+		queryRangeMiddleware = append(queryRangeMiddleware, verticalShardingMiddleware(cfg.VerticalShardInterval, cfg.VerticalShardMaxSlices, cfg.QueryShards, promql.EngineOpts{
+			Logger:        log,
+			MaxConcurrent: cfg.EngineMaxConcurrent,
+			MaxSamples:    cfg.EngineMaxSamples,
+			Timeout:       cfg.EngineTimeout,
+		}, limits))
+	}
 
-	// Finally, if the user selected any query range middleware, stitch it in.
-	var roundTripper http.RoundTripper = f
-	if len(queryRangeMiddleware) > 0 {
-		roundTripper = &queryRangeRoundTripper{
-			next: roundTripper,
-			queryRangeMiddleware: merge(queryRangeMiddleware...).Wrap(&queryRangeTerminator{
-				next: roundTripper,
-			}),
-			limits: limits,
+	// Query range requests are retried by the retry middleware, which backs off
+	// and applies the tenant's retry budget, rather than by re-queueing them.
+	var queryRangeDownstream http.RoundTripper = f
+	if cfg.MaxRetries > 1 {
+		queryRangeMiddleware = append(queryRangeMiddleware, retryMiddleware(retryConfig{
+			MaxRetries: cfg.MaxRetries,
+			MinBackoff: cfg.MinRetryBackoff,
+			MaxBackoff: cfg.MaxRetryBackoff,
+			Budget:     cfg.RetryBudgetPerTenant,
+		}, log))
+		queryRangeDownstream = singleTryRoundTripper{f}
+	}
+	queryRangeMiddleware = append(queryRangeMiddleware, limitParallelism, limitResponseSize, instrument("downstream"))
+
+	// Finally, stitch the query range middleware, and instant query caching if
+	// selected, in front of the queue.
+	roundTripper := &queryRangeRoundTripper{
+		next:                 f,
+		limits:               limits,
+		log:                  log,
+		logQueriesLongerThan: cfg.LogQueriesLongerThan,
+		maxCacheFreshness:    cfg.ResultsCacheConfig.MaxCacheFreshness,
+		queryRangeMiddleware: merge(queryRangeMiddleware...).Wrap(&queryRangeTerminator{
+			next: queryRangeDownstream,
+		}),
+	}
+	if cfg.CacheInstantQueries {
+		instantQueryHandler, err := newInstantQueryCache(cfg.ResultsCacheConfig, &instantQueryTerminator{
+			next: f,
+		})
+		if err != nil {
+			return nil, err
 		}
+		roundTripper.instantQueryHandler = instantQueryHandler
+	}
+	metadata, err := newMetadataRoundTripper(cfg, limits, f)
+	if err != nil {
+		return nil, err
 	}
+	roundTripper.metadata = metadata
 	f.roundTripper = roundTripper
 	f.cond = sync.NewCond(&f.mtx)
 	return f, nil
@@ -136,9 +262,12 @@ func New(cfg Config, log log.Logger, limits *validation.Overrides) (*Frontend, e
 func (f *Frontend) Close() {
 	f.mtx.Lock()
 	defer f.mtx.Unlock()
-	for len(f.queues) > 0 {
+	for len(f.queues) > 0 || len(f.lowPriorityQueues) > 0 {
 		f.cond.Wait()
 	}
+	if f.resultsCache != nil {
+		f.resultsCache.Stop()
+	}
 }
 
 // Handler for HTTP requests.
@@ -150,6 +279,7 @@ func (f *Frontend) Handler() http.Handler {
 }
 
 func (f *Frontend) handle(w http.ResponseWriter, r *http.Request) {
+	r = r.WithContext(withPriority(r.Context(), r.Header))
 	resp, err := f.roundTripper.RoundTrip(r)
 	if err != nil {
 		server.WriteError(w, err)
@@ -166,14 +296,28 @@ func (f *Frontend) handle(w http.ResponseWriter, r *http.Request) {
 
 // RoundTrip implement http.Transport.
 func (f *Frontend) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f.roundTrip(r, f.cfg.MaxRetries)
+}
+
+// singleTryRoundTripper round trips requests through the frontend's queue
+// without retrying them.
+type singleTryRoundTripper struct {
+	f *Frontend
+}
+
+func (s singleTryRoundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
+	return s.f.roundTrip(r, 1)
+}
+
+func (f *Frontend) roundTrip(r *http.Request, maxTries int) (*http.Response, error) {
 	req, err := server.HTTPRequest(r)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := f.RoundTripGRPC(r.Context(), &ProcessRequest{
+	resp, err := f.roundTripGRPC(r.Context(), &ProcessRequest{
 		HttpRequest: req,
-	})
+	}, maxTries)
 	if err != nil {
 		return nil, err
 	}
@@ -200,6 +344,10 @@ func (c *httpgrpcHeadersCarrier) Set(key, val string) {
 
 // RoundTripGRPC round trips a proto (instread of a HTTP request).
 func (f *Frontend) RoundTripGRPC(ctx context.Context, req *ProcessRequest) (*ProcessResponse, error) {
+	return f.roundTripGRPC(ctx, req, f.cfg.MaxRetries)
+}
+
+func (f *Frontend) roundTripGRPC(ctx context.Context, req *ProcessRequest, maxTries int) (*ProcessResponse, error) {
 	// Propagate trace context in gRPC too - this will be ignored if using HTTP.
 	tracer, span := opentracing.GlobalTracer(), opentracing.SpanFromContext(ctx)
 	if tracer != nil && span != nil {
@@ -216,7 +364,7 @@ func (f *Frontend) RoundTripGRPC(ctx context.Context, req *ProcessRequest) (*Pro
 	}
 
 	var lastErr error
-	for tries := 0; tries < f.cfg.MaxRetries; tries++ {
+	for tries := 0; tries < maxTries; tries++ {
 		if err := f.queueRequest(ctx, request); err != nil {
 			return nil, err
 		}
@@ -257,7 +405,7 @@ func (f *Frontend) RoundTripGRPC(ctx context.Context, req *ProcessRequest) (*Pro
 		return nil, lastErr
 	}
 
-	return nil, httpgrpc.Errorf(http.StatusInternalServerError, "Query failed after %d retries.", f.cfg.MaxRetries)
+	return nil, httpgrpc.Errorf(http.StatusInternalServerError, "Query failed after %d retries.", maxTries)
 }
 
 // Process allows backends to pull requests from the frontend.
@@ -271,6 +419,24 @@ func (f *Frontend) Process(server Frontend_ProcessServer) error {
 		errChan = make(chan error, 2)
 	)
 
+	querier := "unknown"
+	if p, ok := peer.FromContext(server.Context()); ok {
+		querier = p.Addr.String()
+	}
+	f.registerWorker(querier, 1)
+	defer f.registerWorker(querier, -1)
+
+	// Workers which pull requests say so when opening the stream, and are
+	// only sent a request once they are ready for it.
+	pull := pullsRequests(metadata.FromIncomingContext(server.Context()))
+	stallTimeout := time.Duration(0)
+	if pull {
+		if err := server.SendHeader(metadata.Pairs(pullRequestsKey, "true")); err != nil {
+			return err
+		}
+		stallTimeout = f.cfg.WorkerStallTimeout
+	}
+
 	// If the stream from the querier is canceled, ping the condition to unblock.
 	// This is done once, here (instead of in getNextRequest) as we expect calls
 	// to Process to process many requests.
@@ -312,6 +478,12 @@ func (f *Frontend) Process(server Frontend_ProcessServer) error {
 	}()
 
 	for {
+		if pull {
+			if err := waitReady(server.Context(), recvChan, errChan); err != nil {
+				return err
+			}
+		}
+
 		request, err := f.getNextRequest(server.Context())
 		if err != nil {
 			return err
@@ -319,6 +491,10 @@ func (f *Frontend) Process(server Frontend_ProcessServer) error {
 
 		originalCtx := request.originalCtx
 
+		// Workers which don't know about chunking ignore this and send
+		// the whole response at once.
+		request.request.AcceptChunkedResponse = true
+
 		select {
 		case sendChan <- request.request:
 		case err := <-errChan:
@@ -328,14 +504,97 @@ func (f *Frontend) Process(server Frontend_ProcessServer) error {
 			return originalCtx.Err()
 		}
 
-		select {
-		case resp := <-recvChan:
-			request.response <- resp
-		case err := <-errChan:
+		resp, err := receiveResponse(originalCtx, recvChan, errChan, stallTimeout)
+		if err != nil {
 			request.err <- err
 			return err
-		case <-originalCtx.Done():
-			return originalCtx.Err()
+		}
+		request.response <- resp
+	}
+}
+
+func (f *Frontend) registerWorker(querier string, delta int) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	f.workers[querier] += delta
+	if f.workers[querier] <= 0 {
+		delete(f.workers, querier)
+	}
+}
+
+// pullRequestsKey is the stream metadata key workers which pull requests, and
+// frontends which support them, set.
+const pullRequestsKey = "cortex-pull-requests"
+
+func pullsRequests(md metadata.MD, ok bool) bool {
+	return ok && len(md.Get(pullRequestsKey)) > 0
+}
+
+// waitReady waits for a worker which pulls requests to be ready for the next.
+func waitReady(ctx context.Context, recvChan <-chan *ProcessResponse, errChan <-chan error) error {
+	select {
+	case msg := <-recvChan:
+		if !msg.Ready {
+			return fmt.Errorf("unexpected message from querier waiting for it to be ready")
+		}
+		return nil
+	case err := <-errChan:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// receiveResponse waits for the response to the request last sent to the
+// worker, reassembling the body if the worker sends it in chunks.  With a
+// stall timeout, it gives up if the worker sends nothing, not even progress,
+// for that long.
+func receiveResponse(ctx context.Context, recvChan <-chan *ProcessResponse, errChan <-chan error, stallTimeout time.Duration) (*ProcessResponse, error) {
+	var (
+		timer   *time.Timer
+		stalled <-chan time.Time
+	)
+	if stallTimeout > 0 {
+		timer = time.NewTimer(stallTimeout)
+		defer timer.Stop()
+		stalled = timer.C
+	}
+
+	var resp *ProcessResponse
+	for {
+		var chunk *ProcessResponse
+		select {
+		case chunk = <-recvChan:
+		case err := <-errChan:
+			return nil, err
+		case <-stalled:
+			return nil, errWorkerStalled
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		if timer != nil {
+			if !timer.Stop() {
+				<-timer.C
+			}
+			timer.Reset(stallTimeout)
+		}
+		if chunk.Progress {
+			continue
+		}
+		if resp == nil {
+			resp = chunk
+		} else if chunk.HttpResponse != nil {
+			if resp.HttpResponse == nil {
+				resp.HttpResponse = &httpgrpc.HTTPResponse{}
+			}
+			resp.HttpResponse.Body = append(resp.HttpResponse.Body, chunk.HttpResponse.Body...)
+		}
+
+		if !chunk.More {
+			resp.More = false
+			return resp, nil
 		}
 	}
 }
@@ -352,10 +611,15 @@ func (f *Frontend) queueRequest(ctx context.Context, req *request) error {
 	f.mtx.Lock()
 	defer f.mtx.Unlock()
 
-	queue, ok := f.queues[userID]
+	queues := f.queues
+	if priorityFromContext(ctx) == lowPriority {
+		queues = f.lowPriorityQueues
+	}
+
+	queue, ok := queues[userID]
 	if !ok {
 		queue = make(chan *request, f.cfg.MaxOutstandingPerTenant)
-		f.queues[userID] = queue
+		queues[userID] = queue
 	}
 
 	select {
@@ -370,11 +634,13 @@ func (f *Frontend) queueRequest(ctx context.Context, req *request) error {
 
 // getQueue picks a random queue and takes the next request off of it, so we
 // fairly process users queries.  Will block if there are no requests.
+// Requests whose client has gone away are discarded.
 func (f *Frontend) getNextRequest(ctx context.Context) (*request, error) {
 	f.mtx.Lock()
 	defer f.mtx.Unlock()
 
-	for len(f.queues) == 0 && ctx.Err() == nil {
+FindQueue:
+	for len(f.queues) == 0 && len(f.lowPriorityQueues) == 0 && ctx.Err() == nil {
 		f.cond.Wait()
 	}
 
@@ -382,27 +648,82 @@ func (f *Frontend) getNextRequest(ctx context.Context) (*request, error) {
 		return nil, err
 	}
 
-	i, n := 0, rand.Intn(len(f.queues))
-	for userID, queue := range f.queues {
-		if i < n {
-			i++
-			continue
+	queues := f.pickPriority()
+	userID := f.pickQueue(queues)
+	queue := queues[userID]
+	request := <-queue
+	if len(queue) == 0 {
+		delete(queues, userID)
+		_, high := f.queues[userID]
+		_, low := f.lowPriorityQueues[userID]
+		if !high && !low {
+			queueShare.DeleteLabelValues(userID)
 		}
+	}
 
-		request := <-queue
-		if len(queue) == 0 {
-			delete(f.queues, userID)
-		}
+	// Tell close() we've processed a request.
+	f.cond.Broadcast()
 
-		// Tell close() we've processed a request.
-		f.cond.Broadcast()
+	queueDuration.Observe(time.Now().Sub(request.enqueueTime).Seconds())
+	queueLength.Add(-1)
+	request.queueSpan.Finish()
 
-		queueDuration.Observe(time.Now().Sub(request.enqueueTime).Seconds())
-		queueLength.Add(-1)
-		request.queueSpan.Finish()
+	// There is no point sending a querier a request nobody is waiting for.
+	if request.originalCtx.Err() != nil {
+		discardedRequests.Inc()
+		goto FindQueue
+	}
 
-		return request, nil
+	return request, nil
+}
+
+// pickPriority picks the queues of high or low priority requests to take the
+// next request from.  High priority requests are served first, but while
+// there are requests of both priorities queued, low priority requests are
+// picked at random for their configured share, so they are never starved.
+// Must be called with the lock held, and with at least one queue.
+func (f *Frontend) pickPriority() map[string]chan *request {
+	switch {
+	case len(f.lowPriorityQueues) == 0:
+		return f.queues
+	case len(f.queues) == 0:
+		return f.lowPriorityQueues
+	case rand.Float64() < f.cfg.LowPriorityShare:
+		return f.lowPriorityQueues
+	default:
+		return f.queues
+	}
+}
+
+// pickQueue picks a tenant with queued requests at random, in proportion to
+// their weight. Weights are always positive, so no tenant is ever starved.
+// Must be called with the lock held, and with at least one of the queues.
+func (f *Frontend) pickQueue(queues map[string]chan *request) string {
+	userIDs := make([]string, 0, len(queues))
+	weights := make([]float64, 0, len(queues))
+	total := 0.0
+	for userID := range queues {
+		weight := 1.0
+		if f.limits != nil {
+			if w := f.limits.QueryFrontendWeight(userID); w > 0 {
+				weight = w
+			}
+		}
+		userIDs = append(userIDs, userID)
+		weights = append(weights, weight)
+		total += weight
+	}
+
+	for i, userID := range userIDs {
+		queueShare.WithLabelValues(userID).Set(weights[i] / total)
 	}
 
-	panic("should never happen")
+	n := rand.Float64() * total
+	for i, weight := range weights {
+		if n < weight {
+			return userIDs[i]
+		}
+		n -= weight
+	}
+	return userIDs[len(userIDs)-1]
 }
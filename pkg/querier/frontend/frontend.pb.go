@@ -36,6 +36,8 @@ const _ = proto.GoGoProtoPackageIsVersion2 // please upgrade the proto package
 type ProcessRequest struct {
 	HttpRequest       *httpgrpc.HTTPRequest `protobuf:"bytes,1,opt,name=httpRequest,proto3" json:"httpRequest,omitempty"`
 	QueryRangeRequest *QueryRangeRequest    `protobuf:"bytes,2,opt,name=queryRangeRequest,proto3" json:"queryRangeRequest,omitempty"`
+	// Set by frontends which accept responses split over multiple ProcessResponses.
+	AcceptChunkedResponse bool `protobuf:"varint,3,opt,name=acceptChunkedResponse,proto3" json:"acceptChunkedResponse,omitempty"`
 }
 
 func (m *ProcessRequest) Reset()      { *m = ProcessRequest{} }
@@ -84,9 +86,26 @@ func (m *ProcessRequest) GetQueryRangeRequest() *QueryRangeRequest {
 	return nil
 }
 
+func (m *ProcessRequest) GetAcceptChunkedResponse() bool {
+	if m != nil {
+		return m.AcceptChunkedResponse
+	}
+	return false
+}
+
 type ProcessResponse struct {
 	HttpResponse *httpgrpc.HTTPResponse `protobuf:"bytes,1,opt,name=httpResponse,proto3" json:"httpResponse,omitempty"`
 	ApiResponse  *APIResponse           `protobuf:"bytes,2,opt,name=apiResponse,proto3" json:"apiResponse,omitempty"`
+	// Set on all but the last ProcessResponse of a chunked response. Only the
+	// first carries the status code and headers; the bodies of all of them make
+	// up the response body.
+	More bool `protobuf:"varint,3,opt,name=more,proto3" json:"more,omitempty"`
+	// Sent, without a response, by workers which pull requests when they are
+	// ready for the next one.
+	Ready bool `protobuf:"varint,4,opt,name=ready,proto3" json:"ready,omitempty"`
+	// Sent, without a response, by workers which pull requests while they are
+	// still processing the last one.
+	Progress bool `protobuf:"varint,5,opt,name=progress,proto3" json:"progress,omitempty"`
 }
 
 func (m *ProcessResponse) Reset()      { *m = ProcessResponse{} }
@@ -135,6 +154,27 @@ func (m *ProcessResponse) GetApiResponse() *APIResponse {
 	return nil
 }
 
+func (m *ProcessResponse) GetMore() bool {
+	if m != nil {
+		return m.More
+	}
+	return false
+}
+
+func (m *ProcessResponse) GetReady() bool {
+	if m != nil {
+		return m.Ready
+	}
+	return false
+}
+
+func (m *ProcessResponse) GetProgress() bool {
+	if m != nil {
+		return m.Progress
+	}
+	return false
+}
+
 type QueryRangeRequest struct {
 	Path    string        `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
 	Start   int64         `protobuf:"varint,2,opt,name=start,proto3" json:"start,omitempty"`
@@ -223,6 +263,7 @@ type APIResponse struct {
 	Data      QueryRangeResponse `protobuf:"bytes,2,opt,name=Data,json=data,proto3" json:"data,omitempty"`
 	ErrorType string             `protobuf:"bytes,3,opt,name=ErrorType,json=errorType,proto3" json:"errorType,omitempty"`
 	Error     string             `protobuf:"bytes,4,opt,name=Error,json=error,proto3" json:"error,omitempty"`
+	Warnings  []string           `protobuf:"bytes,5,rep,name=Warnings,json=warnings,proto3" json:"warnings,omitempty"`
 }
 
 func (m *APIResponse) Reset()      { *m = APIResponse{} }
@@ -285,6 +326,13 @@ func (m *APIResponse) GetError() string {
 	return ""
 }
 
+func (m *APIResponse) GetWarnings() []string {
+	if m != nil {
+		return m.Warnings
+	}
+	return nil
+}
+
 type QueryRangeResponse struct {
 	ResultType string         `protobuf:"bytes,1,opt,name=ResultType,json=resultType,proto3" json:"resultType"`
 	Result     []SampleStream `protobuf:"bytes,2,rep,name=Result,json=result,proto3" json:"result"`
@@ -491,6 +539,126 @@ func (m *Extent) GetResponse() *APIResponse {
 	return nil
 }
 
+type CachedError struct {
+	Key      string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key"`
+	Response *httpgrpc.HTTPResponse `protobuf:"bytes,2,opt,name=response,proto3" json:"response"`
+	// Time after which the error shouldn't be returned anymore, in milliseconds since epoch.
+	Expiry int64 `protobuf:"varint,3,opt,name=expiry,proto3" json:"expiry"`
+}
+
+func (m *CachedError) Reset()      { *m = CachedError{} }
+func (*CachedError) ProtoMessage() {}
+func (*CachedError) Descriptor() ([]byte, []int) {
+	return fileDescriptor_eca3873955a29cfe, []int{8}
+}
+func (m *CachedError) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *CachedError) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_CachedError.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *CachedError) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CachedError.Merge(m, src)
+}
+func (m *CachedError) XXX_Size() int {
+	return m.Size()
+}
+func (m *CachedError) XXX_DiscardUnknown() {
+	xxx_messageInfo_CachedError.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CachedError proto.InternalMessageInfo
+
+func (m *CachedError) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *CachedError) GetResponse() *httpgrpc.HTTPResponse {
+	if m != nil {
+		return m.Response
+	}
+	return nil
+}
+
+func (m *CachedError) GetExpiry() int64 {
+	if m != nil {
+		return m.Expiry
+	}
+	return 0
+}
+
+type CachedHTTPResponse struct {
+	Key      string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key"`
+	Response *httpgrpc.HTTPResponse `protobuf:"bytes,2,opt,name=response,proto3" json:"response"`
+	// Time after which the response shouldn't be returned anymore, in milliseconds since epoch.
+	Expiry int64 `protobuf:"varint,3,opt,name=expiry,proto3" json:"expiry"`
+}
+
+func (m *CachedHTTPResponse) Reset()      { *m = CachedHTTPResponse{} }
+func (*CachedHTTPResponse) ProtoMessage() {}
+func (*CachedHTTPResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_eca3873955a29cfe, []int{9}
+}
+func (m *CachedHTTPResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *CachedHTTPResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_CachedHTTPResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *CachedHTTPResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CachedHTTPResponse.Merge(m, src)
+}
+func (m *CachedHTTPResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *CachedHTTPResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_CachedHTTPResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CachedHTTPResponse proto.InternalMessageInfo
+
+func (m *CachedHTTPResponse) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *CachedHTTPResponse) GetResponse() *httpgrpc.HTTPResponse {
+	if m != nil {
+		return m.Response
+	}
+	return nil
+}
+
+func (m *CachedHTTPResponse) GetExpiry() int64 {
+	if m != nil {
+		return m.Expiry
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*ProcessRequest)(nil), "frontend.ProcessRequest")
 	proto.RegisterType((*ProcessResponse)(nil), "frontend.ProcessResponse")
@@ -500,65 +668,75 @@ func init() {
 	proto.RegisterType((*SampleStream)(nil), "frontend.SampleStream")
 	proto.RegisterType((*CachedResponse)(nil), "frontend.CachedResponse")
 	proto.RegisterType((*Extent)(nil), "frontend.Extent")
+	proto.RegisterType((*CachedError)(nil), "frontend.CachedError")
+	proto.RegisterType((*CachedHTTPResponse)(nil), "frontend.CachedHTTPResponse")
 }
 
 func init() { proto.RegisterFile("frontend.proto", fileDescriptor_eca3873955a29cfe) }
 
 var fileDescriptor_eca3873955a29cfe = []byte{
-	// 833 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x55, 0x4f, 0x6f, 0xdc, 0x44,
-	0x14, 0xdf, 0xc9, 0x26, 0xbb, 0xd9, 0x97, 0x68, 0xd3, 0x4c, 0xa1, 0x6c, 0x02, 0xb2, 0x2b, 0x9f,
-	0x8a, 0x04, 0x5e, 0x54, 0x40, 0x20, 0x10, 0x85, 0x9a, 0x14, 0xb5, 0x12, 0x87, 0x65, 0x92, 0x13,
-	0xb7, 0x89, 0xf7, 0xd5, 0x6b, 0xb2, 0xf6, 0xb8, 0xe3, 0x71, 0xdb, 0x1c, 0x90, 0x38, 0x00, 0x67,
-	0x0e, 0x1c, 0xf8, 0x08, 0x7c, 0x04, 0xbe, 0x01, 0x3d, 0xe6, 0x58, 0x21, 0x61, 0xc8, 0xe6, 0x82,
-	0xf6, 0xd4, 0x8f, 0x80, 0x3c, 0x33, 0x76, 0x4c, 0x12, 0x71, 0xe8, 0x25, 0x7e, 0x7f, 0x7e, 0xbf,
-	0xf7, 0x7e, 0xef, 0xc5, 0xcf, 0x0b, 0xc3, 0x87, 0x52, 0xa4, 0x0a, 0xd3, 0xa9, 0x9f, 0x49, 0xa1,
-	0x04, 0x5d, 0xaf, 0xfd, 0xdd, 0xb7, 0xa3, 0x58, 0xcd, 0x8a, 0x43, 0x3f, 0x14, 0xc9, 0x38, 0x12,
-	0x91, 0x18, 0x6b, 0xc0, 0x61, 0xf1, 0x50, 0x7b, 0xda, 0xd1, 0x96, 0x21, 0xee, 0x3a, 0x91, 0x10,
-	0xd1, 0x1c, 0xcf, 0x51, 0xd3, 0x42, 0x72, 0x15, 0x8b, 0xd4, 0xe6, 0xdf, 0x6b, 0x95, 0x7b, 0x82,
-	0xfc, 0x31, 0x3e, 0x11, 0xf2, 0x28, 0x1f, 0x87, 0x22, 0x49, 0x44, 0x3a, 0x9e, 0x29, 0x95, 0x45,
-	0x32, 0x0b, 0x1b, 0xc3, 0xb2, 0x3e, 0x6b, 0xb1, 0x42, 0x21, 0x15, 0x3e, 0xcd, 0xa4, 0xf8, 0x06,
-	0x43, 0x65, 0xbd, 0x71, 0x76, 0x14, 0x8d, 0xe3, 0x34, 0xc2, 0x5c, 0xa1, 0x1c, 0x87, 0xf3, 0x18,
-	0xd3, 0x3a, 0x65, 0x2a, 0x78, 0x3f, 0x13, 0x18, 0x4e, 0xa4, 0x08, 0x31, 0xcf, 0x19, 0x3e, 0x2a,
-	0x30, 0x57, 0xf4, 0x03, 0xd8, 0xa8, 0xda, 0x58, 0x77, 0x44, 0x6e, 0x92, 0x5b, 0x1b, 0xb7, 0x5f,
-	0xf5, 0x9b, 0xd6, 0xf7, 0x0f, 0x0e, 0x26, 0x36, 0xc9, 0xda, 0x48, 0xfa, 0x00, 0xb6, 0x1f, 0x15,
-	0x28, 0x8f, 0x19, 0x4f, 0x23, 0xac, 0xe9, 0x2b, 0x9a, 0xfe, 0xba, 0xdf, 0x2c, 0xf2, 0xab, 0x8b,
-	0x10, 0x76, 0x99, 0xe5, 0xfd, 0x48, 0x60, 0xab, 0x91, 0x95, 0x67, 0x22, 0xcd, 0x91, 0x7e, 0x04,
-	0x9b, 0xa6, 0x9b, 0xf1, 0xad, 0xb0, 0x1b, 0x17, 0x85, 0x99, 0x2c, 0xfb, 0x0f, 0xb6, 0x9a, 0x89,
-	0x67, 0x71, 0x43, 0x5d, 0xb1, 0x33, 0x35, 0xa2, 0xee, 0x4e, 0x1e, 0x34, 0xcc, 0x36, 0xd2, 0xfb,
-	0x8d, 0xc0, 0xf6, 0x25, 0xc5, 0x94, 0xc2, 0x6a, 0xc6, 0xd5, 0x4c, 0x4b, 0x18, 0x30, 0x6d, 0xd3,
-	0x57, 0x60, 0x2d, 0x57, 0x5c, 0x9a, 0x89, 0xbb, 0xcc, 0x38, 0xf4, 0x1a, 0x74, 0x31, 0x9d, 0x8e,
-	0xba, 0x3a, 0x56, 0x99, 0x15, 0x37, 0x57, 0x98, 0x8d, 0x56, 0x75, 0x48, 0xdb, 0xf4, 0x13, 0xe8,
-	0xab, 0x38, 0x41, 0x51, 0xa8, 0xd1, 0x9a, 0x96, 0xb6, 0xe3, 0x9b, 0xf7, 0xc5, 0xaf, 0xdf, 0x17,
-	0x7f, 0xcf, 0xbe, 0x2f, 0xc1, 0xfa, 0xb3, 0xd2, 0xed, 0xfc, 0xf2, 0x97, 0x4b, 0x58, 0xcd, 0xa9,
-	0x5a, 0xeb, 0x15, 0x8e, 0x7a, 0x5a, 0x8f, 0x71, 0xbc, 0x3f, 0x09, 0x6c, 0xb4, 0xe6, 0xa2, 0x1e,
-	0xf4, 0xf6, 0x15, 0x57, 0x45, 0x6e, 0x64, 0x07, 0xb0, 0x2c, 0xdd, 0x5e, 0xae, 0x23, 0xcc, 0x3e,
-	0xe9, 0x7d, 0x58, 0xdd, 0xe3, 0x8a, 0xdb, 0x05, 0xbd, 0x71, 0xf5, 0x7f, 0xcd, 0xd4, 0x0b, 0x6e,
-	0x54, 0x42, 0x96, 0xa5, 0x3b, 0x9c, 0x72, 0xc5, 0xdf, 0x12, 0x49, 0xac, 0x30, 0xc9, 0xd4, 0x31,
-	0x5b, 0xad, 0x7c, 0xfa, 0x3e, 0x0c, 0xee, 0x49, 0x29, 0xe4, 0xc1, 0x71, 0x86, 0x7a, 0xfc, 0x41,
-	0xf0, 0xda, 0xb2, 0x74, 0xaf, 0x63, 0x1d, 0x6c, 0x31, 0x06, 0x4d, 0x90, 0xbe, 0x09, 0x6b, 0x9a,
-	0xa6, 0xd7, 0x33, 0x08, 0xae, 0x2f, 0x4b, 0x77, 0x4b, 0x67, 0x5b, 0xf0, 0x35, 0x1d, 0xf0, 0xbe,
-	0x27, 0x40, 0x2f, 0xcb, 0xa2, 0x3e, 0x00, 0xc3, 0xbc, 0x98, 0x2b, 0xdd, 0xd9, 0x8c, 0x3a, 0x5c,
-	0x96, 0x2e, 0xc8, 0x26, 0xca, 0x5a, 0x36, 0xbd, 0x03, 0x3d, 0x83, 0x1f, 0xad, 0xdc, 0xec, 0xea,
-	0x17, 0xaa, 0x19, 0x7a, 0x9f, 0x27, 0xd9, 0x1c, 0xf7, 0x95, 0x44, 0x9e, 0x04, 0x43, 0x3b, 0x6e,
-	0xcf, 0x70, 0x99, 0x7d, 0x7a, 0xbf, 0x13, 0xd8, 0x6c, 0x03, 0xe9, 0xb7, 0xd0, 0x9b, 0xf3, 0x43,
-	0x9c, 0x57, 0x7b, 0xae, 0x0a, 0x6e, 0xfb, 0xf6, 0xe2, 0xbe, 0xac, 0xa2, 0x13, 0x1e, 0xcb, 0x80,
-	0x55, 0xb5, 0xfe, 0x28, 0xdd, 0x97, 0xb9, 0x5f, 0x53, 0xe6, 0xee, 0x94, 0x67, 0x0a, 0x65, 0xa5,
-	0x27, 0x41, 0x25, 0xe3, 0x90, 0xd9, 0xa6, 0xf4, 0x43, 0xe8, 0xe7, 0x5a, 0x4e, 0x6e, 0x07, 0x1a,
-	0xd6, 0xfd, 0x8d, 0xca, 0xf3, 0x41, 0x1e, 0xf3, 0x79, 0x81, 0x39, 0xab, 0xe1, 0xde, 0x0c, 0x86,
-	0x9f, 0xf3, 0x70, 0x86, 0xd3, 0x66, 0x97, 0x3b, 0xd0, 0x3d, 0xc2, 0x63, 0xbb, 0xc4, 0xfe, 0xb2,
-	0x74, 0x2b, 0x97, 0x55, 0x7f, 0xe8, 0xc7, 0xd0, 0xc7, 0xa7, 0x0a, 0x53, 0x55, 0xb7, 0xb9, 0x76,
-	0xbe, 0xb7, 0x7b, 0x3a, 0x11, 0x6c, 0xd9, 0x46, 0x35, 0x90, 0xd5, 0x86, 0xf7, 0x03, 0x81, 0x9e,
-	0x01, 0x51, 0xb7, 0x3e, 0x9b, 0xaa, 0x49, 0x37, 0x18, 0x2c, 0x4b, 0xd7, 0x04, 0xea, 0x0b, 0xda,
-	0x31, 0x17, 0xa4, 0xaf, 0xca, 0x68, 0xc0, 0x74, 0x6a, 0x4e, 0xe9, 0x53, 0x58, 0x97, 0xf5, 0x49,
-	0x77, 0xff, 0xe7, 0xa4, 0x83, 0xcd, 0x65, 0xe9, 0x36, 0x50, 0xd6, 0x58, 0xb7, 0x27, 0xb0, 0xfe,
-	0x85, 0xc5, 0xd3, 0x3d, 0xe8, 0xdb, 0x2f, 0x0e, 0xdd, 0x39, 0xaf, 0x72, 0xe1, 0x23, 0xb4, 0x3b,
-	0xba, 0x22, 0x65, 0x3e, 0x59, 0x9d, 0x5b, 0xe4, 0x1d, 0x12, 0xdc, 0x39, 0x39, 0x75, 0x3a, 0xcf,
-	0x4f, 0x9d, 0xce, 0x8b, 0x53, 0x87, 0x7c, 0xb7, 0x70, 0xc8, 0xaf, 0x0b, 0x87, 0x3c, 0x5b, 0x38,
-	0xe4, 0x64, 0xe1, 0x90, 0xbf, 0x17, 0x0e, 0xf9, 0x67, 0xe1, 0x74, 0x5e, 0x2c, 0x1c, 0xf2, 0xd3,
-	0x99, 0xd3, 0x39, 0x39, 0x73, 0x3a, 0xcf, 0xcf, 0x9c, 0xce, 0xd7, 0xcd, 0xcf, 0xca, 0x61, 0x4f,
-	0x1f, 0xfc, 0xbb, 0xff, 0x06, 0x00, 0x00, 0xff, 0xff, 0x4f, 0x6c, 0x8d, 0xb8, 0x79, 0x06, 0x00,
-	0x00,
+	// 965 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xc4, 0x56, 0x4f, 0x6f, 0x1b, 0x45,
+	0x14, 0xf7, 0xd8, 0x89, 0xff, 0x8c, 0x2b, 0xa7, 0x99, 0xd2, 0xe0, 0x04, 0xb4, 0x1b, 0xed, 0x29,
+	0x48, 0x60, 0xa3, 0x50, 0x04, 0x02, 0x51, 0xda, 0x6d, 0x8a, 0x5a, 0x89, 0x83, 0x99, 0x44, 0x42,
+	0xe2, 0x36, 0x59, 0xbf, 0xae, 0x97, 0x78, 0x77, 0xb6, 0xb3, 0xb3, 0x4d, 0x7c, 0x40, 0xe2, 0x00,
+	0x57, 0x04, 0x07, 0x24, 0x3e, 0x02, 0x1f, 0x81, 0x6f, 0x40, 0x85, 0x38, 0xe4, 0x58, 0x71, 0x58,
+	0x88, 0x73, 0x41, 0x3e, 0xf5, 0x23, 0xa0, 0x9d, 0x99, 0x5d, 0x2f, 0x49, 0xda, 0x03, 0x17, 0x2e,
+	0xd9, 0xf7, 0xff, 0xfd, 0xde, 0x6f, 0x66, 0x9e, 0x83, 0x7b, 0x8f, 0x04, 0x8f, 0x24, 0x44, 0xe3,
+	0x41, 0x2c, 0xb8, 0xe4, 0xa4, 0x5d, 0xe8, 0x5b, 0x6f, 0xf9, 0x81, 0x9c, 0xa4, 0x87, 0x03, 0x8f,
+	0x87, 0x43, 0x9f, 0xfb, 0x7c, 0xa8, 0x02, 0x0e, 0xd3, 0x47, 0x4a, 0x53, 0x8a, 0x92, 0x74, 0xe2,
+	0x96, 0xe5, 0x73, 0xee, 0x4f, 0x61, 0x19, 0x35, 0x4e, 0x05, 0x93, 0x01, 0x8f, 0x8c, 0xff, 0x56,
+	0xa5, 0xdc, 0x31, 0xb0, 0x27, 0x70, 0xcc, 0xc5, 0x51, 0x32, 0xf4, 0x78, 0x18, 0xf2, 0x68, 0x38,
+	0x91, 0x32, 0xf6, 0x45, 0xec, 0x95, 0x82, 0xc9, 0xba, 0x53, 0xc9, 0xf2, 0xb8, 0x90, 0x70, 0x12,
+	0x0b, 0xfe, 0x25, 0x78, 0xd2, 0x68, 0xc3, 0xf8, 0xc8, 0x1f, 0x06, 0x91, 0x0f, 0x89, 0x04, 0x31,
+	0xf4, 0xa6, 0x01, 0x44, 0x85, 0x4b, 0x57, 0x70, 0x7e, 0x43, 0xb8, 0x37, 0x12, 0xdc, 0x83, 0x24,
+	0xa1, 0xf0, 0x38, 0x85, 0x44, 0x92, 0xf7, 0x70, 0x37, 0x6f, 0x63, 0xd4, 0x3e, 0xda, 0x46, 0x3b,
+	0xdd, 0xdd, 0x9b, 0x83, 0xb2, 0xf5, 0x83, 0x83, 0x83, 0x91, 0x71, 0xd2, 0x6a, 0x24, 0x79, 0x88,
+	0xd7, 0x1f, 0xa7, 0x20, 0x66, 0x94, 0x45, 0x3e, 0x14, 0xe9, 0x75, 0x95, 0xfe, 0xda, 0xa0, 0x24,
+	0xf2, 0xb3, 0x8b, 0x21, 0xf4, 0x72, 0x16, 0xb9, 0x85, 0x6f, 0x32, 0xcf, 0x83, 0x58, 0xde, 0x9b,
+	0xa4, 0xd1, 0x11, 0x8c, 0x29, 0x24, 0x31, 0x8f, 0x12, 0xe8, 0x37, 0xb6, 0xd1, 0x4e, 0x9b, 0x5e,
+	0xed, 0x74, 0x7e, 0x47, 0x78, 0xad, 0x1c, 0x46, 0xdb, 0xc8, 0x07, 0xf8, 0x9a, 0xc6, 0x68, 0x0a,
+	0xe8, 0x71, 0x36, 0x2e, 0x8e, 0xa3, 0xbd, 0xf4, 0x5f, 0xb1, 0x39, 0x13, 0x2c, 0x0e, 0xca, 0xd4,
+	0xba, 0x61, 0xa2, 0x1c, 0xe5, 0xee, 0xe8, 0x61, 0x99, 0x59, 0x8d, 0x24, 0x04, 0xaf, 0x84, 0x5c,
+	0x14, 0x68, 0x95, 0x4c, 0x5e, 0xc1, 0xab, 0x02, 0xd8, 0x78, 0xd6, 0x5f, 0x51, 0x46, 0xad, 0x90,
+	0x2d, 0xdc, 0x8e, 0x05, 0xf7, 0x05, 0x24, 0x49, 0x7f, 0x55, 0x39, 0x4a, 0xdd, 0xf9, 0x05, 0xe1,
+	0xf5, 0x4b, 0x6c, 0xe5, 0xb5, 0x63, 0x26, 0x27, 0x6a, 0x90, 0x0e, 0x55, 0x72, 0x5e, 0x3b, 0x91,
+	0x4c, 0x68, 0xb6, 0x1b, 0x54, 0x2b, 0xe4, 0x3a, 0x6e, 0x40, 0x34, 0x56, 0x20, 0x1a, 0x34, 0x17,
+	0xf3, 0xdc, 0x44, 0x42, 0xac, 0x20, 0x34, 0xa8, 0x92, 0xc9, 0x47, 0xb8, 0x25, 0x83, 0x10, 0x78,
+	0x2a, 0x15, 0x80, 0xee, 0xee, 0xe6, 0x40, 0xdf, 0xd5, 0x41, 0x71, 0x57, 0x07, 0x7b, 0xe6, 0xae,
+	0xba, 0xed, 0xa7, 0x99, 0x5d, 0xfb, 0xe9, 0x4f, 0x1b, 0xd1, 0x22, 0x27, 0x6f, 0xad, 0x8e, 0xaf,
+	0xdf, 0x54, 0x78, 0xb4, 0xe2, 0xfc, 0x50, 0xc7, 0xdd, 0x0a, 0x3b, 0xc4, 0xc1, 0xcd, 0x7d, 0xc9,
+	0x64, 0x9a, 0x68, 0xd8, 0x2e, 0x5e, 0x64, 0x76, 0x33, 0x51, 0x16, 0x6a, 0xbe, 0xe4, 0x01, 0x5e,
+	0xd9, 0x63, 0x92, 0x19, 0x9a, 0x5f, 0xbf, 0xfa, 0xc6, 0xe8, 0x7a, 0xee, 0x46, 0x0e, 0x64, 0x91,
+	0xd9, 0xbd, 0x31, 0x93, 0xec, 0x4d, 0x1e, 0x06, 0x12, 0xc2, 0x58, 0xce, 0xe8, 0x4a, 0xae, 0x93,
+	0x77, 0x71, 0xe7, 0xbe, 0x10, 0x5c, 0x1c, 0xcc, 0x62, 0x7d, 0x06, 0x1d, 0xf7, 0xd5, 0x45, 0x66,
+	0xdf, 0x80, 0xc2, 0x58, 0xc9, 0xe8, 0x94, 0x46, 0xf2, 0x06, 0x5e, 0x55, 0x69, 0x8a, 0x9e, 0x8e,
+	0x7b, 0x63, 0x91, 0xd9, 0x6b, 0xca, 0x5b, 0x09, 0x5f, 0x55, 0x06, 0xb2, 0x8b, 0xdb, 0x9f, 0x33,
+	0x11, 0x05, 0x91, 0x9f, 0x1f, 0x5b, 0x63, 0xa7, 0xe3, 0x6e, 0x2c, 0x32, 0x9b, 0x1c, 0x1b, 0x5b,
+	0x25, 0xa1, 0x5d, 0xd8, 0x9c, 0x6f, 0x10, 0x26, 0x97, 0x47, 0x21, 0x03, 0x8c, 0x29, 0x24, 0xe9,
+	0x54, 0x2a, 0xb4, 0x9a, 0x9e, 0xde, 0x22, 0xb3, 0xb1, 0x28, 0xad, 0xb4, 0x22, 0x93, 0xdb, 0xb8,
+	0xa9, 0xe3, 0xfb, 0xf5, 0xed, 0x86, 0xba, 0xca, 0x25, 0x51, 0xfb, 0x2c, 0x8c, 0xa7, 0xb0, 0x2f,
+	0x05, 0xb0, 0xd0, 0xed, 0x19, 0x8a, 0x9a, 0x3a, 0x97, 0x9a, 0xaf, 0xf3, 0x2b, 0xc2, 0xd7, 0xaa,
+	0x81, 0xe4, 0x2b, 0xdc, 0x9c, 0xb2, 0x43, 0x98, 0xe6, 0x67, 0x93, 0x17, 0x5c, 0x1f, 0x98, 0x0d,
+	0xf1, 0x69, 0x6e, 0x1d, 0xb1, 0x40, 0xb8, 0x34, 0xaf, 0xf5, 0x47, 0x66, 0xff, 0x97, 0x7d, 0xa3,
+	0xcb, 0xdc, 0x1d, 0xb3, 0x58, 0x82, 0xc8, 0xf1, 0x84, 0x20, 0x45, 0xe0, 0x51, 0xd3, 0x94, 0xbc,
+	0x8f, 0x5b, 0x89, 0x82, 0x93, 0x98, 0x81, 0x7a, 0x45, 0x7f, 0x8d, 0x72, 0x39, 0xc8, 0x13, 0x36,
+	0x4d, 0x21, 0xa1, 0x45, 0xb8, 0x33, 0xc1, 0xbd, 0x7b, 0xcc, 0x9b, 0x2c, 0x17, 0x00, 0xd9, 0xc4,
+	0x8d, 0x23, 0x98, 0x19, 0x12, 0x5b, 0x8b, 0xcc, 0xce, 0x55, 0x9a, 0xff, 0x21, 0x1f, 0xe2, 0x16,
+	0x9c, 0x48, 0x88, 0x64, 0xd1, 0xe6, 0xfa, 0x92, 0xb7, 0xfb, 0xca, 0xe1, 0xae, 0x99, 0x46, 0x45,
+	0x20, 0x2d, 0x04, 0xe7, 0x5b, 0x84, 0x9b, 0x3a, 0x88, 0xd8, 0xc5, 0x53, 0xcb, 0x9b, 0x34, 0xdc,
+	0xce, 0x22, 0xb3, 0xb5, 0xa1, 0x78, 0x75, 0x9b, 0xfa, 0xd5, 0xa9, 0x97, 0xa8, 0x31, 0x40, 0x34,
+	0xd6, 0xcf, 0xef, 0x63, 0xdc, 0x16, 0xd5, 0x45, 0xf6, 0xa2, 0x65, 0xe2, 0x5e, 0x5b, 0x64, 0x76,
+	0x19, 0x4a, 0x4b, 0xc9, 0xf9, 0x0e, 0xe1, 0xae, 0x1e, 0x59, 0x5d, 0xd4, 0x97, 0xcd, 0x7b, 0xa7,
+	0xd2, 0xab, 0xfe, 0xb2, 0x9d, 0xf7, 0xa2, 0x66, 0xf9, 0x9b, 0x85, 0x93, 0x38, 0x10, 0x33, 0xbd,
+	0x41, 0xf4, 0x9b, 0xd5, 0x16, 0x6a, 0xbe, 0xce, 0x8f, 0x08, 0x13, 0x0d, 0xa8, 0x5a, 0xf2, 0x7f,
+	0xc7, 0xb5, 0x3b, 0xc2, 0xed, 0x4f, 0x0c, 0xb1, 0x64, 0x0f, 0xb7, 0xcc, 0x8f, 0x02, 0xd9, 0x5c,
+	0xd2, 0x7d, 0xe1, 0x77, 0x62, 0xab, 0x7f, 0x85, 0x4b, 0x2d, 0x5c, 0xa7, 0xb6, 0x83, 0xde, 0x46,
+	0xee, 0xed, 0xd3, 0x33, 0xab, 0xf6, 0xec, 0xcc, 0xaa, 0x3d, 0x3f, 0xb3, 0xd0, 0xd7, 0x73, 0x0b,
+	0xfd, 0x3c, 0xb7, 0xd0, 0xd3, 0xb9, 0x85, 0x4e, 0xe7, 0x16, 0xfa, 0x6b, 0x6e, 0xa1, 0xbf, 0xe7,
+	0x56, 0xed, 0xf9, 0xdc, 0x42, 0xdf, 0x9f, 0x5b, 0xb5, 0xd3, 0x73, 0xab, 0xf6, 0xec, 0xdc, 0xaa,
+	0x7d, 0x51, 0xfe, 0xbf, 0x70, 0xd8, 0x54, 0xdb, 0xf4, 0x9d, 0x7f, 0x06, 0x00, 0x84, 0xab, 0x62,
+	0xc3, 0x52, 0x08, 0x00, 0x00,
 }
 
 func (this *ProcessRequest) Equal(that interface{}) bool {
@@ -586,6 +764,9 @@ func (this *ProcessRequest) Equal(that interface{}) bool {
 	if !this.QueryRangeRequest.Equal(that1.QueryRangeRequest) {
 		return false
 	}
+	if this.AcceptChunkedResponse != that1.AcceptChunkedResponse {
+		return false
+	}
 	return true
 }
 func (this *ProcessResponse) Equal(that interface{}) bool {
@@ -613,6 +794,15 @@ func (this *ProcessResponse) Equal(that interface{}) bool {
 	if !this.ApiResponse.Equal(that1.ApiResponse) {
 		return false
 	}
+	if this.More != that1.More {
+		return false
+	}
+	if this.Ready != that1.Ready {
+		return false
+	}
+	if this.Progress != that1.Progress {
+		return false
+	}
 	return true
 }
 func (this *QueryRangeRequest) Equal(that interface{}) bool {
@@ -685,6 +875,14 @@ func (this *APIResponse) Equal(that interface{}) bool {
 	if this.Error != that1.Error {
 		return false
 	}
+	if len(this.Warnings) != len(that1.Warnings) {
+		return false
+	}
+	for i := range this.Warnings {
+		if this.Warnings[i] != that1.Warnings[i] {
+			return false
+		}
+	}
 	return true
 }
 func (this *QueryRangeResponse) Equal(that interface{}) bool {
@@ -818,11 +1016,71 @@ func (this *Extent) Equal(that interface{}) bool {
 	}
 	return true
 }
+func (this *CachedError) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*CachedError)
+	if !ok {
+		that2, ok := that.(CachedError)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.Key != that1.Key {
+		return false
+	}
+	if !this.Response.Equal(that1.Response) {
+		return false
+	}
+	if this.Expiry != that1.Expiry {
+		return false
+	}
+	return true
+}
+func (this *CachedHTTPResponse) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*CachedHTTPResponse)
+	if !ok {
+		that2, ok := that.(CachedHTTPResponse)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.Key != that1.Key {
+		return false
+	}
+	if !this.Response.Equal(that1.Response) {
+		return false
+	}
+	if this.Expiry != that1.Expiry {
+		return false
+	}
+	return true
+}
 func (this *ProcessRequest) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 6)
+	s := make([]string, 0, 7)
 	s = append(s, "&frontend.ProcessRequest{")
 	if this.HttpRequest != nil {
 		s = append(s, "HttpRequest: "+fmt.Sprintf("%#v", this.HttpRequest)+",\n")
@@ -830,6 +1088,7 @@ func (this *ProcessRequest) GoString() string {
 	if this.QueryRangeRequest != nil {
 		s = append(s, "QueryRangeRequest: "+fmt.Sprintf("%#v", this.QueryRangeRequest)+",\n")
 	}
+	s = append(s, "AcceptChunkedResponse: "+fmt.Sprintf("%#v", this.AcceptChunkedResponse)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -837,7 +1096,7 @@ func (this *ProcessResponse) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 6)
+	s := make([]string, 0, 9)
 	s = append(s, "&frontend.ProcessResponse{")
 	if this.HttpResponse != nil {
 		s = append(s, "HttpResponse: "+fmt.Sprintf("%#v", this.HttpResponse)+",\n")
@@ -845,6 +1104,9 @@ func (this *ProcessResponse) GoString() string {
 	if this.ApiResponse != nil {
 		s = append(s, "ApiResponse: "+fmt.Sprintf("%#v", this.ApiResponse)+",\n")
 	}
+	s = append(s, "More: "+fmt.Sprintf("%#v", this.More)+",\n")
+	s = append(s, "Ready: "+fmt.Sprintf("%#v", this.Ready)+",\n")
+	s = append(s, "Progress: "+fmt.Sprintf("%#v", this.Progress)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -867,12 +1129,13 @@ func (this *APIResponse) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 8)
+	s := make([]string, 0, 9)
 	s = append(s, "&frontend.APIResponse{")
 	s = append(s, "Status: "+fmt.Sprintf("%#v", this.Status)+",\n")
 	s = append(s, "Data: "+strings.Replace(this.Data.GoString(), `&`, ``, 1)+",\n")
 	s = append(s, "ErrorType: "+fmt.Sprintf("%#v", this.ErrorType)+",\n")
 	s = append(s, "Error: "+fmt.Sprintf("%#v", this.Error)+",\n")
+	s = append(s, "Warnings: "+fmt.Sprintf("%#v", this.Warnings)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -941,6 +1204,34 @@ func (this *Extent) GoString() string {
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
+func (this *CachedError) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 7)
+	s = append(s, "&frontend.CachedError{")
+	s = append(s, "Key: "+fmt.Sprintf("%#v", this.Key)+",\n")
+	if this.Response != nil {
+		s = append(s, "Response: "+fmt.Sprintf("%#v", this.Response)+",\n")
+	}
+	s = append(s, "Expiry: "+fmt.Sprintf("%#v", this.Expiry)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *CachedHTTPResponse) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 7)
+	s = append(s, "&frontend.CachedHTTPResponse{")
+	s = append(s, "Key: "+fmt.Sprintf("%#v", this.Key)+",\n")
+	if this.Response != nil {
+		s = append(s, "Response: "+fmt.Sprintf("%#v", this.Response)+",\n")
+	}
+	s = append(s, "Expiry: "+fmt.Sprintf("%#v", this.Expiry)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
 func valueToGoStringFrontend(v interface{}, typ string) string {
 	rv := reflect.ValueOf(v)
 	if rv.IsNil() {
@@ -1089,6 +1380,16 @@ func (m *ProcessRequest) MarshalTo(dAtA []byte) (int, error) {
 		}
 		i += n2
 	}
+	if m.AcceptChunkedResponse {
+		dAtA[i] = 0x18
+		i++
+		if m.AcceptChunkedResponse {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
 	return i, nil
 }
 
@@ -1127,6 +1428,36 @@ func (m *ProcessResponse) MarshalTo(dAtA []byte) (int, error) {
 		}
 		i += n4
 	}
+	if m.More {
+		dAtA[i] = 0x18
+		i++
+		if m.More {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if m.Ready {
+		dAtA[i] = 0x20
+		i++
+		if m.Ready {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if m.Progress {
+		dAtA[i] = 0x28
+		i++
+		if m.Progress {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
 	return i, nil
 }
 
@@ -1224,6 +1555,21 @@ func (m *APIResponse) MarshalTo(dAtA []byte) (int, error) {
 		i = encodeVarintFrontend(dAtA, i, uint64(len(m.Error)))
 		i += copy(dAtA[i:], m.Error)
 	}
+	if len(m.Warnings) > 0 {
+		for _, s := range m.Warnings {
+			dAtA[i] = 0x2a
+			i++
+			l = len(s)
+			for l >= 1<<7 {
+				dAtA[i] = uint8(uint64(l)&0x7f | 0x80)
+				l >>= 7
+				i++
+			}
+			dAtA[i] = uint8(l)
+			i++
+			i += copy(dAtA[i:], s)
+		}
+	}
 	return i, nil
 }
 
@@ -1379,32 +1725,113 @@ func (m *Extent) MarshalTo(dAtA []byte) (int, error) {
 	return i, nil
 }
 
-func encodeVarintFrontend(dAtA []byte, offset int, v uint64) int {
-	for v >= 1<<7 {
-		dAtA[offset] = uint8(v&0x7f | 0x80)
-		v >>= 7
-		offset++
+func (m *CachedError) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
-	dAtA[offset] = uint8(v)
-	return offset + 1
+	return dAtA[:n], nil
 }
-func (m *ProcessRequest) Size() (n int) {
-	if m == nil {
-		return 0
-	}
+
+func (m *CachedError) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
 	var l int
 	_ = l
-	if m.HttpRequest != nil {
-		l = m.HttpRequest.Size()
-		n += 1 + l + sovFrontend(uint64(l))
+	if len(m.Key) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintFrontend(dAtA, i, uint64(len(m.Key)))
+		i += copy(dAtA[i:], m.Key)
 	}
-	if m.QueryRangeRequest != nil {
-		l = m.QueryRangeRequest.Size()
-		n += 1 + l + sovFrontend(uint64(l))
+	if m.Response != nil {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintFrontend(dAtA, i, uint64(m.Response.Size()))
+		n8, err := m.Response.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n8
 	}
-	return n
-}
-
+	if m.Expiry != 0 {
+		dAtA[i] = 0x18
+		i++
+		i = encodeVarintFrontend(dAtA, i, uint64(m.Expiry))
+	}
+	return i, nil
+}
+
+func (m *CachedHTTPResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *CachedHTTPResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Key) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintFrontend(dAtA, i, uint64(len(m.Key)))
+		i += copy(dAtA[i:], m.Key)
+	}
+	if m.Response != nil {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintFrontend(dAtA, i, uint64(m.Response.Size()))
+		n9, err := m.Response.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n9
+	}
+	if m.Expiry != 0 {
+		dAtA[i] = 0x18
+		i++
+		i = encodeVarintFrontend(dAtA, i, uint64(m.Expiry))
+	}
+	return i, nil
+}
+
+func encodeVarintFrontend(dAtA []byte, offset int, v uint64) int {
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return offset + 1
+}
+func (m *ProcessRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.HttpRequest != nil {
+		l = m.HttpRequest.Size()
+		n += 1 + l + sovFrontend(uint64(l))
+	}
+	if m.QueryRangeRequest != nil {
+		l = m.QueryRangeRequest.Size()
+		n += 1 + l + sovFrontend(uint64(l))
+	}
+	if m.AcceptChunkedResponse {
+		n += 2
+	}
+	return n
+}
+
 func (m *ProcessResponse) Size() (n int) {
 	if m == nil {
 		return 0
@@ -1419,6 +1846,15 @@ func (m *ProcessResponse) Size() (n int) {
 		l = m.ApiResponse.Size()
 		n += 1 + l + sovFrontend(uint64(l))
 	}
+	if m.More {
+		n += 2
+	}
+	if m.Ready {
+		n += 2
+	}
+	if m.Progress {
+		n += 2
+	}
 	return n
 }
 
@@ -1470,6 +1906,12 @@ func (m *APIResponse) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovFrontend(uint64(l))
 	}
+	if len(m.Warnings) > 0 {
+		for _, s := range m.Warnings {
+			l = len(s)
+			n += 1 + l + sovFrontend(uint64(l))
+		}
+	}
 	return n
 }
 
@@ -1551,6 +1993,46 @@ func (m *Extent) Size() (n int) {
 	return n
 }
 
+func (m *CachedError) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Key)
+	if l > 0 {
+		n += 1 + l + sovFrontend(uint64(l))
+	}
+	if m.Response != nil {
+		l = m.Response.Size()
+		n += 1 + l + sovFrontend(uint64(l))
+	}
+	if m.Expiry != 0 {
+		n += 1 + sovFrontend(uint64(m.Expiry))
+	}
+	return n
+}
+
+func (m *CachedHTTPResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Key)
+	if l > 0 {
+		n += 1 + l + sovFrontend(uint64(l))
+	}
+	if m.Response != nil {
+		l = m.Response.Size()
+		n += 1 + l + sovFrontend(uint64(l))
+	}
+	if m.Expiry != 0 {
+		n += 1 + sovFrontend(uint64(m.Expiry))
+	}
+	return n
+}
+
 func sovFrontend(x uint64) (n int) {
 	for {
 		n++
@@ -1571,6 +2053,7 @@ func (this *ProcessRequest) String() string {
 	s := strings.Join([]string{`&ProcessRequest{`,
 		`HttpRequest:` + strings.Replace(fmt.Sprintf("%v", this.HttpRequest), "HTTPRequest", "httpgrpc.HTTPRequest", 1) + `,`,
 		`QueryRangeRequest:` + strings.Replace(fmt.Sprintf("%v", this.QueryRangeRequest), "QueryRangeRequest", "QueryRangeRequest", 1) + `,`,
+		`AcceptChunkedResponse:` + fmt.Sprintf("%v", this.AcceptChunkedResponse) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -1582,6 +2065,9 @@ func (this *ProcessResponse) String() string {
 	s := strings.Join([]string{`&ProcessResponse{`,
 		`HttpResponse:` + strings.Replace(fmt.Sprintf("%v", this.HttpResponse), "HTTPResponse", "httpgrpc.HTTPResponse", 1) + `,`,
 		`ApiResponse:` + strings.Replace(fmt.Sprintf("%v", this.ApiResponse), "APIResponse", "APIResponse", 1) + `,`,
+		`More:` + fmt.Sprintf("%v", this.More) + `,`,
+		`Ready:` + fmt.Sprintf("%v", this.Ready) + `,`,
+		`Progress:` + fmt.Sprintf("%v", this.Progress) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -1610,6 +2096,7 @@ func (this *APIResponse) String() string {
 		`Data:` + strings.Replace(strings.Replace(this.Data.String(), "QueryRangeResponse", "QueryRangeResponse", 1), `&`, ``, 1) + `,`,
 		`ErrorType:` + fmt.Sprintf("%v", this.ErrorType) + `,`,
 		`Error:` + fmt.Sprintf("%v", this.Error) + `,`,
+		`Warnings:` + fmt.Sprintf("%v", this.Warnings) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -1659,6 +2146,30 @@ func (this *Extent) String() string {
 	}, "")
 	return s
 }
+func (this *CachedError) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&CachedError{`,
+		`Key:` + fmt.Sprintf("%v", this.Key) + `,`,
+		`Response:` + strings.Replace(fmt.Sprintf("%v", this.Response), "HTTPResponse", "httpgrpc.HTTPResponse", 1) + `,`,
+		`Expiry:` + fmt.Sprintf("%v", this.Expiry) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *CachedHTTPResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&CachedHTTPResponse{`,
+		`Key:` + fmt.Sprintf("%v", this.Key) + `,`,
+		`Response:` + strings.Replace(fmt.Sprintf("%v", this.Response), "HTTPResponse", "httpgrpc.HTTPResponse", 1) + `,`,
+		`Expiry:` + fmt.Sprintf("%v", this.Expiry) + `,`,
+		`}`,
+	}, "")
+	return s
+}
 func valueToStringFrontend(v interface{}) string {
 	rv := reflect.ValueOf(v)
 	if rv.IsNil() {
@@ -1768,6 +2279,26 @@ func (m *ProcessRequest) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AcceptChunkedResponse", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFrontend
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.AcceptChunkedResponse = bool(v != 0)
 		default:
 			iNdEx = preIndex
 			skippy, err := skipFrontend(dAtA[iNdEx:])
@@ -1893,6 +2424,66 @@ func (m *ProcessResponse) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field More", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFrontend
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.More = bool(v != 0)
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Ready", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFrontend
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Ready = bool(v != 0)
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Progress", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFrontend
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Progress = bool(v != 0)
 		default:
 			iNdEx = preIndex
 			skippy, err := skipFrontend(dAtA[iNdEx:])
@@ -2282,6 +2873,38 @@ func (m *APIResponse) Unmarshal(dAtA []byte) error {
 			}
 			m.Error = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Warnings", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFrontend
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthFrontend
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthFrontend
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Warnings = append(m.Warnings, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipFrontend(dAtA[iNdEx:])
@@ -2792,6 +3415,286 @@ func (m *Extent) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *CachedError) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowFrontend
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: CachedError: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: CachedError: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Key", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFrontend
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthFrontend
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthFrontend
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Key = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Response", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFrontend
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthFrontend
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthFrontend
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Response == nil {
+				m.Response = &httpgrpc.HTTPResponse{}
+			}
+			if err := m.Response.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Expiry", wireType)
+			}
+			m.Expiry = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFrontend
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Expiry |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipFrontend(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthFrontend
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthFrontend
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *CachedHTTPResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowFrontend
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: CachedHTTPResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: CachedHTTPResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Key", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFrontend
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthFrontend
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthFrontend
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Key = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Response", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFrontend
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthFrontend
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthFrontend
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Response == nil {
+				m.Response = &httpgrpc.HTTPResponse{}
+			}
+			if err := m.Response.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Expiry", wireType)
+			}
+			m.Expiry = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFrontend
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Expiry |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipFrontend(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthFrontend
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthFrontend
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func skipFrontend(dAtA []byte) (n int, err error) {
 	l := len(dAtA)
 	iNdEx := 0
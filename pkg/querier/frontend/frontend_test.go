@@ -1,15 +1,20 @@
 package frontend
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io/ioutil"
 	"net"
 	"net/http"
+	"os"
+	"strings"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/cortexproject/cortex/pkg/util/flagext"
+	"github.com/cortexproject/cortex/pkg/util/validation"
 	"github.com/go-kit/kit/log"
 	otgrpc "github.com/opentracing-contrib/go-grpc"
 	"github.com/opentracing-contrib/go-stdlib/nethttp"
@@ -18,6 +23,7 @@ import (
 	"github.com/stretchr/testify/require"
 	jaeger "github.com/uber/jaeger-client-go"
 	"github.com/uber/jaeger-client-go/config"
+	"github.com/weaveworks/common/httpgrpc"
 	httpgrpc_server "github.com/weaveworks/common/httpgrpc/server"
 	"github.com/weaveworks/common/middleware"
 	"google.golang.org/grpc"
@@ -47,6 +53,80 @@ func TestFrontend(t *testing.T) {
 	testFrontend(t, handler, test)
 }
 
+func TestFrontendChunkedResponses(t *testing.T) {
+	// Larger than the default max gRPC message size the frontend accepts.
+	large := bytes.Repeat([]byte("0123456789"), 600*1024)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write(large)
+	})
+	test := func(addr string) {
+		req, err := http.NewRequest("GET", fmt.Sprintf("http://%s/", addr), nil)
+		require.NoError(t, err)
+		err = user.InjectOrgIDIntoHTTPRequest(user.InjectOrgID(context.Background(), "1"), req)
+		require.NoError(t, err)
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		require.Equal(t, 200, resp.StatusCode)
+		require.Equal(t, "text/plain", resp.Header.Get("Content-Type"))
+
+		body, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.Equal(t, large, body)
+	}
+	testFrontend(t, handler, test)
+}
+
+func TestFrontendCompression(t *testing.T) {
+	body := strings.Repeat("Hello World", 1000)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	})
+	for _, compression := range []string{"gzip", "snappy"} {
+		t.Run(compression, func(t *testing.T) {
+			test := func(addr string) {
+				req, err := http.NewRequest("GET", fmt.Sprintf("http://%s/", addr), nil)
+				require.NoError(t, err)
+				err = user.InjectOrgIDIntoHTTPRequest(user.InjectOrgID(context.Background(), "1"), req)
+				require.NoError(t, err)
+
+				resp, err := http.DefaultClient.Do(req)
+				require.NoError(t, err)
+				require.Equal(t, 200, resp.StatusCode)
+
+				b, err := ioutil.ReadAll(resp.Body)
+				require.NoError(t, err)
+				require.Equal(t, body, string(b))
+			}
+			testFrontend(t, handler, test, func(cfg *WorkerConfig) {
+				cfg.GRPCClientConfig.GRPCCompression = compression
+			})
+		})
+	}
+}
+
+func TestReceiveResponseStalled(t *testing.T) {
+	recvChan := make(chan *ProcessResponse, 1)
+	errChan := make(chan error)
+
+	// Progress keeps the request alive for longer than the stall timeout.
+	go func() {
+		for i := 0; i < 5; i++ {
+			time.Sleep(20 * time.Millisecond)
+			recvChan <- &ProcessResponse{Progress: true}
+		}
+		recvChan <- &ProcessResponse{HttpResponse: &httpgrpc.HTTPResponse{Code: 200}}
+	}()
+	resp, err := receiveResponse(context.Background(), recvChan, errChan, 50*time.Millisecond)
+	require.NoError(t, err)
+	require.Equal(t, int32(200), resp.HttpResponse.Code)
+
+	// Without it, the request fails.
+	_, err = receiveResponse(context.Background(), recvChan, errChan, 50*time.Millisecond)
+	require.Equal(t, errWorkerStalled, err)
+}
+
 func TestFrontendRetries(t *testing.T) {
 	try := int32(0)
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -124,7 +204,96 @@ func TestFrontendPropagateTrace(t *testing.T) {
 	testFrontend(t, handler, test)
 }
 
-func testFrontend(t *testing.T, handler http.Handler, test func(addr string)) {
+func TestFrontendDiscardsCancelledRequests(t *testing.T) {
+	var config Config
+	flagext.DefaultValues(&config)
+	frontend, err := New(config, log.NewNopLogger(), defaultOverrides(t))
+	require.NoError(t, err)
+
+	ctx := user.InjectOrgID(context.Background(), "1")
+	cancelledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	cancelled := &request{originalCtx: cancelledCtx, request: &ProcessRequest{}}
+	require.NoError(t, frontend.queueRequest(cancelledCtx, cancelled))
+	live := &request{originalCtx: ctx, request: &ProcessRequest{}}
+	require.NoError(t, frontend.queueRequest(ctx, live))
+
+	next, err := frontend.getNextRequest(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, live, next)
+	require.Empty(t, frontend.queues)
+}
+
+func TestFrontendWeightedQueues(t *testing.T) {
+	overridesFile, err := ioutil.TempFile("", "overrides")
+	require.NoError(t, err)
+	defer os.Remove(overridesFile.Name())
+	_, err = overridesFile.WriteString("overrides:\n  premium:\n    query_frontend_weight: 3\n")
+	require.NoError(t, err)
+	require.NoError(t, overridesFile.Close())
+
+	var limits validation.Limits
+	flagext.DefaultValues(&limits)
+	limits.PerTenantOverrideConfig = overridesFile.Name()
+	overrides, err := validation.NewOverrides(limits)
+	require.NoError(t, err)
+	defer overrides.Stop()
+
+	var config Config
+	flagext.DefaultValues(&config)
+	frontend, err := New(config, log.NewNopLogger(), overrides)
+	require.NoError(t, err)
+	frontend.queues["premium"] = make(chan *request)
+	frontend.queues["standard"] = make(chan *request)
+
+	picks := map[string]int{}
+	for i := 0; i < 10000; i++ {
+		picks[frontend.pickQueue(frontend.queues)]++
+	}
+	require.InDelta(t, 7500, picks["premium"], 500)
+	require.InDelta(t, 2500, picks["standard"], 500)
+}
+
+func TestFrontendPriorityQueues(t *testing.T) {
+	var config Config
+	flagext.DefaultValues(&config)
+	frontend, err := New(config, log.NewNopLogger(), defaultOverrides(t))
+	require.NoError(t, err)
+
+	ctx := user.InjectOrgID(context.Background(), "1")
+	lowCtx := withPriority(ctx, http.Header{PriorityHeader: []string{"low"}})
+
+	// High priority requests are served ahead of earlier low priority ones...
+	frontend.cfg.LowPriorityShare = 0
+	low := &request{originalCtx: lowCtx, request: &ProcessRequest{}}
+	require.NoError(t, frontend.queueRequest(lowCtx, low))
+	high := &request{originalCtx: ctx, request: &ProcessRequest{}}
+	require.NoError(t, frontend.queueRequest(ctx, high))
+
+	for _, expected := range []*request{high, low} {
+		next, err := frontend.getNextRequest(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, expected, next)
+	}
+	require.Empty(t, frontend.queues)
+	require.Empty(t, frontend.lowPriorityQueues)
+
+	// ...but low priority requests get their share while both are queued.
+	frontend.cfg.LowPriorityShare = 0.2
+	frontend.queues["1"] = make(chan *request)
+	frontend.lowPriorityQueues["1"] = make(chan *request)
+
+	lowPicks := 0
+	for i := 0; i < 10000; i++ {
+		if frontend.pickPriority()["1"] == frontend.lowPriorityQueues["1"] {
+			lowPicks++
+		}
+	}
+	require.InDelta(t, 2000, lowPicks, 500)
+}
+
+func testFrontend(t *testing.T, handler http.Handler, test func(addr string), workerOpts ...func(*WorkerConfig)) {
 	logger := log.NewNopLogger() //log.NewLogfmtLogger(log.NewSyncWriter(os.Stderr))
 
 	var (
@@ -132,12 +301,15 @@ func testFrontend(t *testing.T, handler http.Handler, test func(addr string)) {
 		workerConfig WorkerConfig
 	)
 	flagext.DefaultValues(&config, &workerConfig)
-	config.SplitQueriesByDay = true
+	config.SplitQueriesByInterval = day
 
 	// localhost:0 prevents firewall warnings on Mac OS X.
 	grpcListen, err := net.Listen("tcp", "localhost:0")
 	require.NoError(t, err)
 	workerConfig.Address = grpcListen.Addr().String()
+	for _, opt := range workerOpts {
+		opt(&workerConfig)
+	}
 
 	httpListen, err := net.Listen("tcp", "localhost:0")
 	require.NoError(t, err)
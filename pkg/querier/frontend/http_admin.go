@@ -0,0 +1,232 @@
+package frontend
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-kit/kit/log/level"
+	"github.com/weaveworks/common/httpgrpc"
+	"github.com/weaveworks/common/user"
+
+	"github.com/cortexproject/cortex/pkg/util"
+)
+
+const tpl = `
+<!DOCTYPE html>
+<html>
+	<head>
+		<meta charset="UTF-8">
+		<title>Cortex Query Frontend Queues</title>
+	</head>
+	<body>
+		<h1>Cortex Query Frontend Queues</h1>
+		<p>Current time: {{ .Now }}</p>
+		<form action="" method="POST">
+			<input type="hidden" name="csrf_token" value="$__CSRF_TOKEN_PLACEHOLDER__">
+			<table border="1">
+				<thead>
+					<tr>
+						<th>User</th>
+						<th>Queued Requests</th>
+						<th>Low Priority Requests</th>
+						<th>Oldest Request Age</th>
+						<th>Actions</th>
+					</tr>
+				</thead>
+				<tbody>
+					{{ range .Status.Tenants }}
+					<tr>
+						<td>{{ .UserID }}</td>
+						<td align='right'>{{ .QueuedRequests }}</td>
+						<td align='right'>{{ .LowPriorityRequests }}</td>
+						<td align='right'>{{ .OldestRequestAge }}</td>
+						<td><button name="drop" value="{{ .UserID }}" type="submit">Drop Queued Requests</button></td>
+					</tr>
+					{{ end }}
+				</tbody>
+			</table>
+		</form>
+		<h2>Connected Queriers</h2>
+		<table border="1">
+			<thead>
+				<tr>
+					<th>Address</th>
+					<th>Workers</th>
+				</tr>
+			</thead>
+			<tbody>
+				{{ range .Status.Queriers }}
+				<tr>
+					<td>{{ .Address }}</td>
+					<td align='right'>{{ .Workers }}</td>
+				</tr>
+				{{ end }}
+			</tbody>
+		</table>
+	</body>
+</html>`
+
+var tmpl *template.Template
+
+func init() {
+	tmpl = template.Must(template.New("webpage").Parse(tpl))
+}
+
+var errDropped = httpgrpc.Errorf(http.StatusTooManyRequests, "queued request dropped by an operator")
+
+// QueueStatus describes the requests queued in the frontend, and the
+// queriers connected to it to execute them.
+type QueueStatus struct {
+	Tenants  []TenantQueueStatus `json:"tenants"`
+	Queriers []QuerierStatus     `json:"queriers"`
+}
+
+// TenantQueueStatus describes a tenant's queued requests.  QueuedRequests
+// includes the LowPriorityRequests; the age is in nanoseconds in JSON.
+type TenantQueueStatus struct {
+	UserID              string        `json:"user_id"`
+	QueuedRequests      int           `json:"queued_requests"`
+	LowPriorityRequests int           `json:"low_priority_requests"`
+	OldestRequestAge    time.Duration `json:"oldest_request_age"`
+}
+
+// QuerierStatus describes a querier connected to the frontend: the number of
+// its workers pulling requests.
+type QuerierStatus struct {
+	Address string `json:"address"`
+	Workers int    `json:"workers"`
+}
+
+// queueStatus returns the current QueueStatus, tenants with the most queued
+// requests first.
+func (f *Frontend) queueStatus() QueueStatus {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	now := time.Now()
+	tenants := map[string]*TenantQueueStatus{}
+	observe := func(queues map[string]chan *request, lowPriority bool) {
+		for userID, queue := range queues {
+			t, ok := tenants[userID]
+			if !ok {
+				t = &TenantQueueStatus{UserID: userID}
+				tenants[userID] = t
+			}
+
+			// Requests are only ever queued and dequeued with the lock held, so
+			// cycling through the queue leaves it as it was.
+			for i, n := 0, len(queue); i < n; i++ {
+				req := <-queue
+				if age := now.Sub(req.enqueueTime); age > t.OldestRequestAge {
+					t.OldestRequestAge = age
+				}
+				queue <- req
+			}
+			t.QueuedRequests += len(queue)
+			if lowPriority {
+				t.LowPriorityRequests += len(queue)
+			}
+		}
+	}
+	observe(f.queues, false)
+	observe(f.lowPriorityQueues, true)
+
+	status := QueueStatus{
+		Tenants:  make([]TenantQueueStatus, 0, len(tenants)),
+		Queriers: make([]QuerierStatus, 0, len(f.workers)),
+	}
+	for _, t := range tenants {
+		status.Tenants = append(status.Tenants, *t)
+	}
+	sort.Slice(status.Tenants, func(i, j int) bool {
+		a, b := status.Tenants[i], status.Tenants[j]
+		return a.QueuedRequests > b.QueuedRequests ||
+			(a.QueuedRequests == b.QueuedRequests && a.UserID < b.UserID)
+	})
+	for address, workers := range f.workers {
+		status.Queriers = append(status.Queriers, QuerierStatus{Address: address, Workers: workers})
+	}
+	sort.Slice(status.Queriers, func(i, j int) bool {
+		return status.Queriers[i].Address < status.Queriers[j].Address
+	})
+	return status
+}
+
+// dropQueuedRequests fails all of the tenant's queued requests, and returns
+// how many there were.
+func (f *Frontend) dropQueuedRequests(userID string) int {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	dropped := 0
+	for _, queues := range []map[string]chan *request{f.queues, f.lowPriorityQueues} {
+		queue, ok := queues[userID]
+		if !ok {
+			continue
+		}
+		delete(queues, userID)
+
+		for n := len(queue); n > 0; n-- {
+			req := <-queue
+			req.queueSpan.Finish()
+			req.err <- errDropped
+			dropped++
+		}
+	}
+
+	queueLength.Add(-float64(dropped))
+	queueShare.DeleteLabelValues(userID)
+
+	// Tell close() the queues may now be empty.
+	f.cond.Broadcast()
+	return dropped
+}
+
+// QueueStatusHandler shows the requests queued per tenant and the connected
+// queriers, as JSON if asked for, and drops a tenant's queued requests when
+// POSTed the tenant as "drop".  Tenants can only drop their own requests.
+func (f *Frontend) QueueStatusHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		userID, err := user.ExtractOrgID(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		if drop := r.FormValue("drop"); drop != userID {
+			http.Error(w, fmt.Sprintf("cannot drop the requests of tenant %q", drop), http.StatusForbidden)
+			return
+		}
+		dropped := f.dropQueuedRequests(userID)
+		level.Info(util.WithContext(r.Context(), util.Logger)).Log("msg", "dropped queued requests", "user", userID, "requests", dropped)
+
+		// Implement PRG pattern to prevent double-POST and work with CSRF middleware.
+		// https://en.wikipedia.org/wiki/Post/Redirect/Get
+		http.Redirect(w, r, r.RequestURI, http.StatusFound)
+		return
+	}
+
+	status := f.queueStatus()
+
+	if encodings, found := r.Header["Accept"]; found &&
+		len(encodings) > 0 && strings.Contains(encodings[0], "json") {
+		if err := json.NewEncoder(w).Encode(status); err != nil {
+			http.Error(w, fmt.Sprintf("Error marshalling response: %v", err), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if err := tmpl.Execute(w, struct {
+		Now    time.Time
+		Status QueueStatus
+	}{
+		Now:    time.Now(),
+		Status: status,
+	}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,86 @@
+package frontend
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+
+	"github.com/cortexproject/cortex/pkg/util/flagext"
+)
+
+func TestQueueStatusHandler(t *testing.T) {
+	var config Config
+	flagext.DefaultValues(&config)
+	frontend, err := New(config, log.NewNopLogger(), defaultOverrides(t))
+	require.NoError(t, err)
+
+	queue := func(userID string, h http.Header) *request {
+		ctx := withPriority(user.InjectOrgID(context.Background(), userID), h)
+		req := &request{
+			originalCtx: ctx,
+			request:     &ProcessRequest{},
+			err:         make(chan error, 1),
+		}
+		require.NoError(t, frontend.queueRequest(ctx, req))
+		return req
+	}
+	queue("1", nil)
+	queue("2", nil)
+	dropped := []*request{
+		queue("2", nil),
+		queue("2", http.Header{PriorityHeader: []string{"low"}}),
+	}
+	frontend.registerWorker("querier-1:9095", 1)
+	frontend.registerWorker("querier-1:9095", 1)
+
+	status := frontend.queueStatus()
+	require.Len(t, status.Tenants, 2)
+	require.Equal(t, "2", status.Tenants[0].UserID)
+	require.Equal(t, 3, status.Tenants[0].QueuedRequests)
+	require.Equal(t, 1, status.Tenants[0].LowPriorityRequests)
+	require.True(t, status.Tenants[0].OldestRequestAge > 0)
+	require.Equal(t, "1", status.Tenants[1].UserID)
+	require.Equal(t, []QuerierStatus{{Address: "querier-1:9095", Workers: 2}}, status.Queriers)
+
+	// Looking at the queues leaves them as they were.
+	require.Len(t, frontend.queues["1"], 1)
+	require.Len(t, frontend.queues["2"], 2)
+	require.Len(t, frontend.lowPriorityQueues["2"], 1)
+
+	rec := httptest.NewRecorder()
+	frontend.QueueStatusHandler(rec, httptest.NewRequest("GET", "/frontend/queues", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), "querier-1:9095")
+
+	// Tenants can't drop other tenants' requests.
+	drop := func(orgID string) int {
+		req := httptest.NewRequest("POST", "/frontend/queues", strings.NewReader(url.Values{"drop": {"2"}}.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if orgID != "" {
+			req = req.WithContext(user.InjectOrgID(req.Context(), orgID))
+		}
+		rec := httptest.NewRecorder()
+		frontend.QueueStatusHandler(rec, req)
+		return rec.Code
+	}
+	require.Equal(t, http.StatusUnauthorized, drop(""))
+	require.Equal(t, http.StatusForbidden, drop("1"))
+	require.Len(t, frontend.queueStatus().Tenants, 2)
+
+	// Dropping a tenant's requests fails them, at any priority.
+	require.Equal(t, http.StatusFound, drop("2"))
+
+	for _, r := range dropped {
+		require.Equal(t, errDropped, <-r.err)
+	}
+	status = frontend.queueStatus()
+	require.Len(t, status.Tenants, 1)
+	require.Equal(t, "1", status.Tenants[0].UserID)
+}
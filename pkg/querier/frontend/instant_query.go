@@ -0,0 +1,159 @@
+package frontend
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/cortexproject/cortex/pkg/chunk/cache"
+	opentracing "github.com/opentracing/opentracing-go"
+	otlog "github.com/opentracing/opentracing-go/log"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/pkg/timestamp"
+	"github.com/weaveworks/common/user"
+)
+
+// InstantQueryRequest is a request to the Prometheus instant query API (/api/v1/query).
+type InstantQueryRequest struct {
+	Path  string
+	Time  int64
+	Query string
+}
+
+func parseInstantQueryRequest(r *http.Request) (*InstantQueryRequest, error) {
+	if err := parseForm(r); err != nil {
+		return nil, err
+	}
+
+	var result InstantQueryRequest
+	var err error
+
+	// Like Prometheus, default to evaluating the query now.
+	result.Time = int64(model.Now())
+	if t := r.FormValue("time"); t != "" {
+		result.Time, err = ParseTime(t)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result.Query = r.FormValue("query")
+	result.Path = r.URL.Path
+	return &result, nil
+}
+
+func (q InstantQueryRequest) toHTTPRequest(ctx context.Context) (*http.Request, error) {
+	params := url.Values{
+		"time":  []string{encodeTime(q.Time)},
+		"query": []string{q.Query},
+	}
+	req := encodeRequest(ctx, q.Path, params)
+	req.Header.Set("Accept", ProtobufContentType)
+	return req, nil
+}
+
+func (q InstantQueryRequest) logToSpan(ctx context.Context) {
+	if span := opentracing.SpanFromContext(ctx); span != nil {
+		span.LogFields(otlog.String("query", q.Query),
+			otlog.String("time", timestamp.Time(q.Time).String()))
+	}
+}
+
+// instantQueryHandlerFunc is like http.HandlerFunc, but for instantQueryHandler.
+type instantQueryHandlerFunc func(context.Context, *InstantQueryRequest) (*APIResponse, error)
+
+func (q instantQueryHandlerFunc) Do(ctx context.Context, req *InstantQueryRequest) (*APIResponse, error) {
+	return q(ctx, req)
+}
+
+type instantQueryHandler interface {
+	Do(context.Context, *InstantQueryRequest) (*APIResponse, error)
+}
+
+type instantQueryTerminator struct {
+	next http.RoundTripper
+}
+
+func (q instantQueryTerminator) Do(ctx context.Context, r *InstantQueryRequest) (*APIResponse, error) {
+	request, err := r.toHTTPRequest(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := user.InjectOrgIDIntoHTTPRequest(ctx, request); err != nil {
+		return nil, err
+	}
+
+	r.logToSpan(ctx)
+	queryTimingsFromContext(ctx).addAttempt()
+	response, err := q.next.RoundTrip(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	mergeResponseStats(ctx, response)
+	observeCacheControl(ctx, response)
+	return parseQueryRangeResponse(ctx, response)
+}
+
+// instantQueryCache caches the results of instant queries evaluated far
+// enough in the past that their results are not expected to change.
+// Results are stored as a single, zero-width extent.
+type instantQueryCache struct {
+	cfg   ResultsCacheConfig
+	next  instantQueryHandler
+	cache cache.Cache
+}
+
+func newInstantQueryCache(cfg ResultsCacheConfig, next instantQueryHandler) (instantQueryHandler, error) {
+	c, err := cache.New(cfg.CacheConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &instantQueryCache{
+		cfg:   cfg,
+		next:  next,
+		cache: cache.NewSnappy(c),
+	}, nil
+}
+
+func (s instantQueryCache) Do(ctx context.Context, r *InstantQueryRequest) (*APIResponse, error) {
+	userID, err := user.ExtractOrgID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	maxCacheTime := int64(model.Now().Add(-s.cfg.MaxCacheFreshness))
+	if r.Time > maxCacheTime || cacheControlFromContext(ctx).requestNoStore() {
+		queryTimingsFromContext(ctx).observeCache(0, 1)
+		return s.next.Do(ctx, r)
+	}
+
+	key := fmt.Sprintf("%s:%s:%d", userID, r.Query, r.Time)
+	if extents, ok := getExtents(ctx, s.cache, key); ok && len(extents) == 1 {
+		queryTimingsFromContext(ctx).observeCache(1, 0)
+		return extents[0].Response, nil
+	}
+	queryTimingsFromContext(ctx).observeCache(0, 1)
+
+	downstream, ctx := withDownstreamCacheControl(ctx)
+	response, err := s.next.Do(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	if downstream.responseNoStore() {
+		return response, nil
+	}
+
+	putExtents(ctx, s.cache, key, []Extent{
+		{
+			Start:    r.Time,
+			End:      r.Time,
+			Response: response,
+		},
+	})
+	return response, nil
+}
@@ -0,0 +1,110 @@
+package frontend
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+
+	"github.com/cortexproject/cortex/pkg/chunk/cache"
+	client "github.com/cortexproject/cortex/pkg/ingester/client"
+)
+
+func TestInstantQueryRequest(t *testing.T) {
+	r, err := http.NewRequest("GET", "/api/v1/query?query=sum%28container_memory_rss%29&time=1536673680", nil)
+	require.NoError(t, err)
+
+	req, err := parseInstantQueryRequest(r)
+	require.NoError(t, err)
+	require.Equal(t, &InstantQueryRequest{
+		Path:  "/api/v1/query",
+		Time:  1536673680 * 1e3,
+		Query: "sum(container_memory_rss)",
+	}, req)
+
+	rdash, err := req.toHTTPRequest(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "/api/v1/query?query=sum%28container_memory_rss%29&time=1536673680", rdash.RequestURI)
+}
+
+func TestInstantQueryResponse(t *testing.T) {
+	for _, tc := range []struct {
+		body     string
+		expected QueryRangeResponse
+	}{
+		{
+			body: `{"resultType":"vector","result":[{"metric":{"foo":"bar"},"value":[1536673680,"137"]}]}`,
+			expected: QueryRangeResponse{
+				ResultType: vector,
+				Result: []SampleStream{
+					{
+						Labels:  []client.LabelAdapter{{Name: "foo", Value: "bar"}},
+						Samples: []client.Sample{{TimestampMs: 1536673680000, Value: 137}},
+					},
+				},
+			},
+		},
+		{
+			body: `{"resultType":"scalar","result":[1536673680,"137"]}`,
+			expected: QueryRangeResponse{
+				ResultType: scalar,
+				Result: []SampleStream{
+					{Samples: []client.Sample{{TimestampMs: 1536673680000, Value: 137}}},
+				},
+			},
+		},
+	} {
+		t.Run(tc.expected.ResultType, func(t *testing.T) {
+			var resp QueryRangeResponse
+			require.NoError(t, json.Unmarshal([]byte(tc.body), &resp))
+			require.Equal(t, tc.expected, resp)
+		})
+	}
+}
+
+func TestInstantQueryCache(t *testing.T) {
+	calls := 0
+	cfg := ResultsCacheConfig{
+		CacheConfig: cache.Config{
+			Cache: cache.NewMockCache(),
+		},
+		MaxCacheFreshness: 10 * time.Minute,
+	}
+	c, err := newInstantQueryCache(cfg, instantQueryHandlerFunc(func(_ context.Context, req *InstantQueryRequest) (*APIResponse, error) {
+		calls++
+		return dummyResponse, nil
+	}))
+	require.NoError(t, err)
+
+	ctx := user.InjectOrgID(context.Background(), "1")
+
+	// Queries evaluated before the max cache freshness are cached.
+	old := &InstantQueryRequest{
+		Path:  "/api/v1/query",
+		Time:  int64(model.Now().Add(-time.Hour)),
+		Query: "foo",
+	}
+	for i := 0; i < 2; i++ {
+		resp, err := c.Do(ctx, old)
+		require.NoError(t, err)
+		require.Equal(t, dummyResponse, resp)
+	}
+	require.Equal(t, 1, calls)
+
+	// Recent queries always go downstream.
+	recent := &InstantQueryRequest{
+		Path:  "/api/v1/query",
+		Time:  int64(model.Now()),
+		Query: "foo",
+	}
+	for i := 0; i < 2; i++ {
+		resp, err := c.Do(ctx, recent)
+		require.NoError(t, err)
+		require.Equal(t, dummyResponse, resp)
+	}
+	require.Equal(t, 3, calls)
+}
@@ -2,10 +2,17 @@ package frontend
 
 import (
 	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	instr "github.com/weaveworks/common/instrument"
+
+	"github.com/cortexproject/cortex/pkg/querier/stats"
 )
 
 var queryRangeDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
@@ -15,16 +22,186 @@ var queryRangeDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
 	Buckets:   prometheus.DefBuckets,
 }, []string{"method", "status_code"})
 
-func instrument(name string) queryRangeMiddleware {
-	return queryRangeMiddlewareFunc(func(next queryRangeHandler) queryRangeHandler {
-		return queryRangeHandlerFunc(func(ctx context.Context, req *QueryRangeRequest) (*APIResponse, error) {
+var (
+	queryFetchedSeries = promauto.NewSummaryVec(prometheus.SummaryOpts{
+		Namespace: "cortex",
+		Name:      "frontend_query_fetched_series",
+		Help:      "Number of series fetched by the queriers to evaluate a query.",
+	}, []string{"user"})
+	queryFetchedChunks = promauto.NewSummaryVec(prometheus.SummaryOpts{
+		Namespace: "cortex",
+		Name:      "frontend_query_fetched_chunks",
+		Help:      "Number of chunks fetched by the queriers to evaluate a query.",
+	}, []string{"user"})
+	queryFetchedChunkBytes = promauto.NewSummaryVec(prometheus.SummaryOpts{
+		Namespace: "cortex",
+		Name:      "frontend_query_fetched_chunk_bytes",
+		Help:      "Size of the chunks fetched by the queriers to evaluate a query.",
+	}, []string{"user"})
+	queryFetchedSamples = promauto.NewSummaryVec(prometheus.SummaryOpts{
+		Namespace: "cortex",
+		Name:      "frontend_query_fetched_samples",
+		Help:      "Number of samples read by the queriers to evaluate a query.",
+	}, []string{"user"})
+	queryWallTime = promauto.NewSummaryVec(prometheus.SummaryOpts{
+		Namespace: "cortex",
+		Name:      "frontend_query_querier_wall_time_seconds",
+		Help:      "Total time spent by the queriers evaluating a query, summed over its sub-queries.",
+	}, []string{"user"})
+)
+
+func observeQueryStats(userID string, s stats.Stats) {
+	queryFetchedSeries.WithLabelValues(userID).Observe(float64(s.FetchedSeries))
+	queryFetchedChunks.WithLabelValues(userID).Observe(float64(s.FetchedChunks))
+	queryFetchedChunkBytes.WithLabelValues(userID).Observe(float64(s.FetchedChunkBytes))
+	queryFetchedSamples.WithLabelValues(userID).Observe(float64(s.FetchedSamples))
+	queryWallTime.WithLabelValues(userID).Observe(s.WallTime.Seconds())
+}
+
+// instrument times the requests going through a stage of the middleware
+// chain, in the duration histogram and in the query's timings.
+func instrument(name string) QueryRangeMiddleware {
+	return QueryRangeMiddlewareFunc(func(next QueryRangeHandler) QueryRangeHandler {
+		return QueryRangeHandlerFunc(func(ctx context.Context, req *QueryRangeRequest) (*APIResponse, error) {
 			var resp *APIResponse
+			start := time.Now()
 			err := instr.TimeRequestHistogram(ctx, name, queryRangeDuration, func(ctx context.Context) error {
 				var err error
 				resp, err = next.Do(ctx, req)
 				return err
 			})
+			queryTimingsFromContext(ctx).observe(name, time.Since(start))
 			return resp, err
 		})
 	})
 }
+
+type queryTimingsKey struct{}
+
+// queryTimings collects where the time went for a single query, and how it
+// was split, sharded and served, for the slow query log and the debugging
+// headers.  Stages run for each sub-query of a split or sharded query
+// accumulate the time taken by all of them.
+type queryTimings struct {
+	mtx      sync.Mutex
+	stages   map[string]time.Duration
+	splits   int
+	shards   int
+	attempts int
+
+	// Number of requests to the results cache fully answered from it, and
+	// those with some or none of their results cached.
+	cacheHits, cachePartials, cacheMisses int
+}
+
+func withQueryTimings(ctx context.Context) (*queryTimings, context.Context) {
+	t := &queryTimings{stages: map[string]time.Duration{}}
+	return t, context.WithValue(ctx, queryTimingsKey{}, t)
+}
+
+func queryTimingsFromContext(ctx context.Context) *queryTimings {
+	t, _ := ctx.Value(queryTimingsKey{}).(*queryTimings)
+	return t
+}
+
+func (t *queryTimings) observe(stage string, d time.Duration) {
+	if t == nil {
+		return
+	}
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	t.stages[stage] += d
+}
+
+func (t *queryTimings) addSplits(n int) {
+	if t == nil {
+		return
+	}
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	t.splits += n
+}
+
+func (t *queryTimings) addShards(n int) {
+	if t == nil {
+		return
+	}
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	t.shards += n
+}
+
+func (t *queryTimings) addAttempt() {
+	if t == nil {
+		return
+	}
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	t.attempts++
+}
+
+// observeCache records whether a request to the results cache was answered
+// from it, given how many cached extents it used and downstream requests it
+// sent.
+func (t *queryTimings) observeCache(cached, downstream int) {
+	if t == nil {
+		return
+	}
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	switch {
+	case downstream == 0:
+		t.cacheHits++
+	case cached == 0:
+		t.cacheMisses++
+	default:
+		t.cachePartials++
+	}
+}
+
+const (
+	cacheStatusHeader = "X-Cache"
+	splitsHeader      = "X-Cortex-Query-Splits"
+	shardsHeader      = "X-Cortex-Query-Shards"
+	attemptsHeader    = "X-Cortex-Query-Attempts"
+)
+
+// setHeaders sets the debugging headers, telling clients whether the query
+// was served from the results cache ("hit", "miss" or "partial", if it went
+// through it), how many sub-queries it was split and sharded into, and how
+// many requests were sent downstream for it, including retries.
+func (t *queryTimings) setHeaders(h http.Header) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	switch {
+	case t.cacheHits+t.cachePartials+t.cacheMisses == 0:
+	case t.cachePartials == 0 && t.cacheMisses == 0:
+		h.Set(cacheStatusHeader, "hit")
+	case t.cachePartials == 0 && t.cacheHits == 0:
+		h.Set(cacheStatusHeader, "miss")
+	default:
+		h.Set(cacheStatusHeader, "partial")
+	}
+	h.Set(splitsHeader, strconv.Itoa(t.splits))
+	h.Set(shardsHeader, strconv.Itoa(t.shards))
+	h.Set(attemptsHeader, strconv.Itoa(t.attempts))
+}
+
+// logFields returns the timings as key/value pairs, stages in name order.
+func (t *queryTimings) logFields() []interface{} {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	stages := make([]string, 0, len(t.stages))
+	for stage := range t.stages {
+		stages = append(stages, stage)
+	}
+	sort.Strings(stages)
+
+	fields := []interface{}{"splits", t.splits, "shards", t.shards, "attempts", t.attempts}
+	for _, stage := range stages {
+		fields = append(fields, stage+"_time", t.stages[stage])
+	}
+	return fields
+}
@@ -0,0 +1,154 @@
+package frontend
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/prometheus/prometheus/pkg/timestamp"
+	"github.com/weaveworks/common/httpgrpc"
+	"github.com/weaveworks/common/user"
+
+	"github.com/cortexproject/cortex/pkg/util/validation"
+)
+
+// limitsMiddleware creates a new QueryRangeMiddleware that rejects requests
+// exceeding the tenant's limits, before any work is done for them.
+func limitsMiddleware(limits *validation.Overrides) QueryRangeMiddleware {
+	return QueryRangeMiddlewareFunc(func(next QueryRangeHandler) QueryRangeHandler {
+		return limitsHandler{
+			next:   next,
+			limits: limits,
+		}
+	})
+}
+
+type limitsHandler struct {
+	next   QueryRangeHandler
+	limits *validation.Overrides
+}
+
+func (l limitsHandler) Do(ctx context.Context, r *QueryRangeRequest) (*APIResponse, error) {
+	userid, err := user.ExtractOrgID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkBlockedQuery(l.limits, userid, r.Query); err != nil {
+		return nil, err
+	}
+
+	maxQueryLen := l.limits.MaxQueryLength(userid)
+	queryLen := timestamp.Time(r.End).Sub(timestamp.Time(r.Start))
+	if maxQueryLen != 0 && queryLen > maxQueryLen {
+		return nil, httpgrpc.Errorf(http.StatusBadRequest, validation.ErrQueryTooLong, queryLen, maxQueryLen)
+	}
+
+	if maxPoints := l.limits.MaxPointsPerSeries(userid); maxPoints > 0 && r.Step > 0 && (r.End-r.Start)/r.Step > int64(maxPoints) {
+		if !l.limits.ClampQueryStep(userid) {
+			return nil, httpgrpc.Errorf(http.StatusBadRequest, validation.ErrTooManyPoints, maxPoints)
+		}
+		// The smallest step giving at most maxPoints points.
+		req := r.copy()
+		req.Step = (r.End - r.Start + int64(maxPoints) - 1) / int64(maxPoints)
+		r = &req
+	}
+
+	// Sub-requests of this request, however they're split and sharded, share
+	// the tenant's parallelism allowance.
+	if parallelism := l.limits.MaxQueryParallelism(userid); parallelism > 0 {
+		ctx = context.WithValue(ctx, parallelismKey, make(chan struct{}, parallelism))
+	}
+	if maxSize := l.limits.MaxResponseSizeBytes(userid); maxSize > 0 {
+		ctx = context.WithValue(ctx, responseSizeKey, &responseSize{max: int64(maxSize)})
+	}
+	return l.next.Do(ctx, r)
+}
+
+// checkBlockedQuery returns a 422 if the query matches one of the patterns
+// the tenant's queries are blocked by.
+func checkBlockedQuery(limits *validation.Overrides, userID, query string) error {
+	for _, blocked := range limits.BlockedQueries(userID) {
+		if blocked.Matches(query) {
+			reason := blocked.Reason
+			if reason == "" {
+				reason = "blocked by the operator"
+			}
+			return httpgrpc.Errorf(http.StatusUnprocessableEntity, validation.ErrQueryBlocked, blocked.Pattern, reason)
+		}
+	}
+	return nil
+}
+
+type contextKey int
+
+const (
+	parallelismKey contextKey = iota
+	responseSizeKey
+	cacheControlKey
+	priorityKey
+)
+
+// responseSize accumulates the size of the partial results gathered for a
+// request passing through the limitsMiddleware.
+type responseSize struct {
+	max  int64
+	size int64
+}
+
+// trackResponseSize adds the size of the responses to those already gathered
+// for the request, and returns a 422 once they exceed the tenant's limit.
+// Failing the sub-request fails the request, cancelling its other
+// sub-requests, before their results are merged.
+func trackResponseSize(ctx context.Context, resps ...*APIResponse) error {
+	rs, ok := ctx.Value(responseSizeKey).(*responseSize)
+	if !ok {
+		return nil
+	}
+
+	var size int64
+	for _, resp := range resps {
+		size += int64(resp.Size())
+	}
+	if atomic.AddInt64(&rs.size, size) > rs.max {
+		return httpgrpc.Errorf(http.StatusUnprocessableEntity, validation.ErrQueryResponseTooLarge, rs.max)
+	}
+	return nil
+}
+
+// limitResponseSize is a QueryRangeMiddleware that tracks the size of
+// downstream responses against the tenant's max response size.
+var limitResponseSize = QueryRangeMiddlewareFunc(func(next QueryRangeHandler) QueryRangeHandler {
+	return QueryRangeHandlerFunc(func(ctx context.Context, r *QueryRangeRequest) (*APIResponse, error) {
+		resp, err := next.Do(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		if err := trackResponseSize(ctx, resp); err != nil {
+			return nil, err
+		}
+		return resp, nil
+	})
+})
+
+// limitParallelism is a QueryRangeMiddleware that bounds the number of
+// downstream requests sent in parallel for each request passing through the
+// limitsMiddleware. It should come after the splitting, sharding and caching
+// middlewares, so only requests actually being executed count.
+var limitParallelism = QueryRangeMiddlewareFunc(func(next QueryRangeHandler) QueryRangeHandler {
+	return QueryRangeHandlerFunc(func(ctx context.Context, r *QueryRangeRequest) (*APIResponse, error) {
+		slots, ok := ctx.Value(parallelismKey).(chan struct{})
+		if !ok {
+			return next.Do(ctx, r)
+		}
+
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		defer func() { <-slots }()
+
+		return next.Do(ctx, r)
+	})
+})
@@ -0,0 +1,258 @@
+package frontend
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/httpgrpc"
+	"github.com/weaveworks/common/user"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/cortexproject/cortex/pkg/util/flagext"
+	"github.com/cortexproject/cortex/pkg/util/validation"
+)
+
+func TestBlockedQueries(t *testing.T) {
+	var limits validation.Limits
+	flagext.DefaultValues(&limits)
+	require.NoError(t, yaml.Unmarshal([]byte(`
+blocked_queries:
+- pattern: up
+- pattern: expensive_metric\{.*
+  regex: true
+  reason: it times out
+`), &limits))
+	overrides, err := validation.NewOverrides(limits)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		query       string
+		expectedErr string
+	}{
+		{query: "sum(up)"},
+		{query: "rate(expensive_metric[5m])"},
+		{query: "up", expectedErr: `the query matches the blocked query pattern "up": blocked by the operator`},
+		{query: `sum(rate(expensive_metric{job="foo"}[5m]))`, expectedErr: `the query matches the blocked query pattern "expensive_metric\\{.*": it times out`},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			handler := limitsMiddleware(overrides).Wrap(QueryRangeHandlerFunc(func(_ context.Context, req *QueryRangeRequest) (*APIResponse, error) {
+				return dummyResponse, nil
+			}))
+
+			ctx := user.InjectOrgID(context.Background(), "1")
+			_, err := handler.Do(ctx, &QueryRangeRequest{
+				End:   day.Nanoseconds() / int64(time.Millisecond),
+				Step:  15 * seconds,
+				Query: tc.query,
+			})
+			if tc.expectedErr == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			httpResp, ok := httpgrpc.HTTPResponseFromError(err)
+			require.True(t, ok)
+			require.Equal(t, int32(http.StatusUnprocessableEntity), httpResp.Code)
+			require.Equal(t, tc.expectedErr, string(httpResp.Body))
+		})
+	}
+
+	// Instant queries are blocked too.
+	roundTripper := queryRangeRoundTripper{
+		next: RoundTripperFunc(func(*http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK}, nil
+		}),
+		limits: overrides,
+	}
+	req, err := http.NewRequest("GET", "/api/v1/query?query=up", nil)
+	require.NoError(t, err)
+	_, err = roundTripper.RoundTrip(req.WithContext(user.InjectOrgID(context.Background(), "1")))
+	httpResp, ok := httpgrpc.HTTPResponseFromError(err)
+	require.True(t, ok)
+	require.Equal(t, int32(http.StatusUnprocessableEntity), httpResp.Code)
+}
+
+func TestLimitsMiddleware(t *testing.T) {
+	var limits validation.Limits
+	flagext.DefaultValues(&limits)
+	limits.MaxQueryLength = 30 * day
+	overrides, err := validation.NewOverrides(limits)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name        string
+		length      time.Duration
+		expectedErr bool
+	}{
+		{name: "within limit", length: 30 * day},
+		{name: "over limit", length: 31 * day, expectedErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			handler := limitsMiddleware(overrides).Wrap(QueryRangeHandlerFunc(func(_ context.Context, req *QueryRangeRequest) (*APIResponse, error) {
+				calls++
+				return dummyResponse, nil
+			}))
+
+			ctx := user.InjectOrgID(context.Background(), "1")
+			resp, err := handler.Do(ctx, &QueryRangeRequest{
+				Start: 0,
+				End:   int64(tc.length / time.Millisecond),
+				Step:  3600 * seconds,
+			})
+			if !tc.expectedErr {
+				require.NoError(t, err)
+				require.Equal(t, dummyResponse, resp)
+				require.Equal(t, 1, calls)
+				return
+			}
+
+			httpResp, ok := httpgrpc.HTTPResponseFromError(err)
+			require.True(t, ok)
+			require.Equal(t, int32(http.StatusBadRequest), httpResp.Code)
+			require.Equal(t, 0, calls)
+		})
+	}
+}
+
+func TestMaxPointsPerSeries(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		clamp        bool
+		step         int64
+		expectedStep int64
+		expectedErr  bool
+	}{
+		{name: "within limit", step: 10, expectedStep: 10},
+		{name: "over limit", step: 9, expectedErr: true},
+		{name: "clamped", clamp: true, step: 1, expectedStep: 10},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var limits validation.Limits
+			flagext.DefaultValues(&limits)
+			limits.MaxPointsPerSeries = 100
+			limits.ClampQueryStep = tc.clamp
+			overrides, err := validation.NewOverrides(limits)
+			require.NoError(t, err)
+
+			var step int64
+			handler := limitsMiddleware(overrides).Wrap(QueryRangeHandlerFunc(func(_ context.Context, req *QueryRangeRequest) (*APIResponse, error) {
+				step = req.Step
+				return dummyResponse, nil
+			}))
+
+			ctx := user.InjectOrgID(context.Background(), "1")
+			_, err = handler.Do(ctx, &QueryRangeRequest{
+				Start: 0,
+				End:   1000,
+				Step:  tc.step,
+			})
+			if !tc.expectedErr {
+				require.NoError(t, err)
+				require.Equal(t, tc.expectedStep, step)
+				return
+			}
+
+			httpResp, ok := httpgrpc.HTTPResponseFromError(err)
+			require.True(t, ok)
+			require.Equal(t, int32(http.StatusBadRequest), httpResp.Code)
+			require.Equal(t, "exceeded maximum resolution of 100 points per timeseries. Try decreasing the query resolution (?step=XX)", string(httpResp.Body))
+		})
+	}
+}
+
+func TestLimitParallelism(t *testing.T) {
+	var limits validation.Limits
+	flagext.DefaultValues(&limits)
+	limits.MaxQueryParallelism = 2
+	overrides, err := validation.NewOverrides(limits)
+	require.NoError(t, err)
+
+	var (
+		mtx                   sync.Mutex
+		inflight, maxInflight int
+	)
+	leaf := limitParallelism.Wrap(QueryRangeHandlerFunc(func(_ context.Context, req *QueryRangeRequest) (*APIResponse, error) {
+		mtx.Lock()
+		inflight++
+		if inflight > maxInflight {
+			maxInflight = inflight
+		}
+		mtx.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		mtx.Lock()
+		inflight--
+		mtx.Unlock()
+		return dummyResponse, nil
+	}))
+
+	// Two nested levels of fan out, each as wide as the parallelism limit,
+	// still only run two requests at once.
+	fanOut := func(next QueryRangeHandler) QueryRangeHandler {
+		return QueryRangeHandlerFunc(func(ctx context.Context, req *QueryRangeRequest) (*APIResponse, error) {
+			reqs := []*QueryRangeRequest{req, req, req}
+			_, err := doRequests(ctx, next, reqs, overrides)
+			return dummyResponse, err
+		})
+	}
+	handler := limitsMiddleware(overrides).Wrap(fanOut(fanOut(leaf)))
+
+	ctx := user.InjectOrgID(context.Background(), "1")
+	_, err = handler.Do(ctx, &QueryRangeRequest{})
+	require.NoError(t, err)
+	require.Equal(t, 2, maxInflight)
+}
+
+func TestLimitResponseSize(t *testing.T) {
+	size := dummyResponse.Size()
+	for _, tc := range []struct {
+		name    string
+		maxSize int
+		calls   int
+		err     bool
+	}{
+		{name: "no limit", maxSize: 0},
+		{name: "all responses fit", maxSize: 4 * size},
+		{name: "third response exceeds", maxSize: 3*size - 1, err: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var limits validation.Limits
+			flagext.DefaultValues(&limits)
+			limits.MaxQueryParallelism = 1
+			limits.MaxResponseSizeBytes = tc.maxSize
+			overrides, err := validation.NewOverrides(limits)
+			require.NoError(t, err)
+
+			calls := 0
+			leaf := limitResponseSize.Wrap(QueryRangeHandlerFunc(func(_ context.Context, req *QueryRangeRequest) (*APIResponse, error) {
+				calls++
+				return dummyResponse, nil
+			}))
+			handler := limitsMiddleware(overrides).Wrap(QueryRangeHandlerFunc(func(ctx context.Context, req *QueryRangeRequest) (*APIResponse, error) {
+				reqs := []*QueryRangeRequest{req, req, req, req}
+				_, err := doRequests(ctx, leaf, reqs, overrides)
+				return dummyResponse, err
+			}))
+
+			ctx := user.InjectOrgID(context.Background(), "1")
+			_, err = handler.Do(ctx, &QueryRangeRequest{})
+			if !tc.err {
+				require.NoError(t, err)
+				require.Equal(t, 4, calls)
+				return
+			}
+
+			resp, ok := httpgrpc.HTTPResponseFromError(err)
+			require.True(t, ok)
+			require.Equal(t, int32(http.StatusUnprocessableEntity), resp.Code)
+			// The remaining sub-request isn't sent once the limit is exceeded.
+			require.Equal(t, 3, calls)
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package frontend
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-kit/kit/log/level"
+	"github.com/gogo/protobuf/proto"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/pkg/timestamp"
+	"github.com/weaveworks/common/httpgrpc"
+	"github.com/weaveworks/common/user"
+
+	"github.com/cortexproject/cortex/pkg/chunk/cache"
+	"github.com/cortexproject/cortex/pkg/util"
+	"github.com/cortexproject/cortex/pkg/util/validation"
+)
+
+// isMetadataRequest returns whether the path is one of the series, label
+// names or label values endpoints.
+func isMetadataRequest(path string) bool {
+	return strings.HasSuffix(path, "/series") ||
+		strings.HasSuffix(path, "/labels") ||
+		(strings.HasSuffix(path, "/values") && strings.Contains(path, "/label/"))
+}
+
+// MetadataRequest is a request to one of the series or label endpoints.
+// Their time range is optional; Start and End are only set, and HasRange
+// true, if both were given.
+type MetadataRequest struct {
+	Path       string
+	Start, End int64
+	HasRange   bool
+	Params     url.Values
+}
+
+func parseMetadataRequest(r *http.Request) (*MetadataRequest, error) {
+	if err := parseForm(r); err != nil {
+		return nil, err
+	}
+
+	result := MetadataRequest{
+		Path:   r.URL.Path,
+		Params: r.Form,
+	}
+
+	start, end := r.FormValue("start"), r.FormValue("end")
+	if start == "" || end == "" {
+		return &result, nil
+	}
+
+	var err error
+	if result.Start, err = ParseTime(start); err != nil {
+		return nil, err
+	}
+	if result.End, err = ParseTime(end); err != nil {
+		return nil, err
+	}
+	if result.End < result.Start {
+		return nil, errEndBeforeStart
+	}
+	result.HasRange = true
+	return &result, nil
+}
+
+// cacheKey returns the key under which responses to the request are cached.
+// The params are encoded sorted by key, so equivalent requests share a key.
+func (m MetadataRequest) cacheKey(userID string) string {
+	return fmt.Sprintf("metadata:%s:%s?%s", userID, m.Path, m.Params.Encode())
+}
+
+// metadataRoundTripper applies the tenant's query limits to series and label
+// requests and, if a TTL is configured, caches their successful responses.
+// Responses are passed through as they are, so work with any of the
+// endpoints' result formats.
+type metadataRoundTripper struct {
+	next   http.RoundTripper
+	limits *validation.Overrides
+	cache  cache.Cache
+	ttl    time.Duration
+}
+
+func newMetadataRoundTripper(cfg Config, limits *validation.Overrides, next http.RoundTripper) (*metadataRoundTripper, error) {
+	m := &metadataRoundTripper{
+		next:   next,
+		limits: limits,
+		ttl:    cfg.MetadataCacheTTL,
+	}
+	if m.ttl > 0 {
+		c, err := cache.New(cfg.ResultsCacheConfig.CacheConfig)
+		if err != nil {
+			return nil, err
+		}
+		m.cache = cache.NewSnappy(c)
+	}
+	return m, nil
+}
+
+func (m metadataRoundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
+	ctx := r.Context()
+	userID, err := user.ExtractOrgID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := parseMetadataRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.HasRange {
+		maxQueryLen := m.limits.MaxQueryLength(userID)
+		queryLen := timestamp.Time(request.End).Sub(timestamp.Time(request.Start))
+		if maxQueryLen != 0 && queryLen > maxQueryLen {
+			return nil, httpgrpc.Errorf(http.StatusBadRequest, validation.ErrQueryTooLong, queryLen, maxQueryLen)
+		}
+	}
+
+	if m.cache == nil || hasNoStore(r.Header) {
+		return m.next.RoundTrip(r)
+	}
+
+	key := request.cacheKey(userID)
+	if cached, ok := m.get(ctx, key); ok {
+		return httpResponse(cached), nil
+	}
+
+	resp, err := m.next.RoundTrip(r)
+	if err != nil || resp.StatusCode != http.StatusOK || hasNoStore(resp.Header) {
+		return resp, err
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	m.put(ctx, key, httpgrpcResponse(resp, body))
+	return resp, nil
+}
+
+func (m metadataRoundTripper) get(ctx context.Context, key string) (*httpgrpc.HTTPResponse, bool) {
+	found, bufs, _ := m.cache.Fetch(ctx, []string{cache.HashKey(key)})
+	if len(found) != 1 {
+		return nil, false
+	}
+
+	var cached CachedHTTPResponse
+	if err := proto.Unmarshal(bufs[0], &cached); err != nil {
+		level.Error(util.Logger).Log("msg", "error unmarshalling cached metadata response", "err", err)
+		return nil, false
+	}
+
+	// Guard against hash collisions, and honour the TTL.
+	if cached.Key != key || cached.Expiry < int64(model.Now()) || cached.Response == nil {
+		return nil, false
+	}
+	return cached.Response, true
+}
+
+func (m metadataRoundTripper) put(ctx context.Context, key string, resp *httpgrpc.HTTPResponse) {
+	buf, err := proto.Marshal(&CachedHTTPResponse{
+		Key:      key,
+		Response: resp,
+		Expiry:   int64(model.Now().Add(m.ttl)),
+	})
+	if err != nil {
+		level.Error(util.Logger).Log("msg", "error marshalling cached metadata response", "err", err)
+		return
+	}
+
+	m.cache.Store(ctx, []string{cache.HashKey(key)}, [][]byte{buf})
+}
+
+func httpgrpcResponse(resp *http.Response, body []byte) *httpgrpc.HTTPResponse {
+	result := &httpgrpc.HTTPResponse{
+		Code: int32(resp.StatusCode),
+		Body: body,
+	}
+	for k, v := range resp.Header {
+		result.Headers = append(result.Headers, &httpgrpc.Header{Key: k, Values: v})
+	}
+	return result
+}
+
+func httpResponse(resp *httpgrpc.HTTPResponse) *http.Response {
+	result := &http.Response{
+		StatusCode: int(resp.Code),
+		Header:     http.Header{},
+		Body:       ioutil.NopCloser(bytes.NewReader(resp.Body)),
+	}
+	for _, h := range resp.Headers {
+		result.Header[h.Key] = h.Values
+	}
+	return result
+}
@@ -0,0 +1,100 @@
+package frontend
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/httpgrpc"
+	"github.com/weaveworks/common/user"
+
+	"github.com/cortexproject/cortex/pkg/chunk/cache"
+	"github.com/cortexproject/cortex/pkg/util/flagext"
+	"github.com/cortexproject/cortex/pkg/util/validation"
+)
+
+func TestIsMetadataRequest(t *testing.T) {
+	for path, expected := range map[string]bool{
+		"/api/prom/api/v1/series":              true,
+		"/api/prom/api/v1/labels":              true,
+		"/api/prom/api/v1/label/job/values":    true,
+		"/api/prom/api/v1/query_range":         false,
+		"/api/prom/api/v1/query":               false,
+		"/api/prom/api/v1/something/values":    false,
+		"/api/prom/api/v1/label/values/other/": false,
+	} {
+		require.Equal(t, expected, isMetadataRequest(path), path)
+	}
+}
+
+func TestMetadataRoundTripper(t *testing.T) {
+	var limits validation.Limits
+	flagext.DefaultValues(&limits)
+	limits.MaxQueryLength = 7 * 24 * time.Hour
+	overrides, err := validation.NewOverrides(limits)
+	require.NoError(t, err)
+
+	calls := 0
+	status := http.StatusOK
+	downstream := RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		calls++
+		return &http.Response{
+			StatusCode: status,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       ioutil.NopCloser(strings.NewReader(`{"status":"success","data":["foo"]}`)),
+		}, nil
+	})
+
+	var cfg Config
+	flagext.DefaultValues(&cfg)
+	cfg.MetadataCacheTTL = time.Minute
+	cfg.ResultsCacheConfig.CacheConfig = cache.Config{Cache: cache.NewMockCache()}
+	m, err := newMetadataRoundTripper(cfg, overrides, downstream)
+	require.NoError(t, err)
+
+	do := func(path string) (*http.Response, error) {
+		req, err := http.NewRequest("GET", path, nil)
+		require.NoError(t, err)
+		return m.RoundTrip(req.WithContext(user.InjectOrgID(context.Background(), "1")))
+	}
+
+	// Requests with the same params, in any order, are answered from the cache.
+	for _, path := range []string{
+		"/api/v1/series?match[]=up&start=0&end=3600",
+		"/api/v1/series?end=3600&match[]=up&start=0",
+	} {
+		resp, err := do(path)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+		body, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.Equal(t, `{"status":"success","data":["foo"]}`, string(body))
+	}
+	require.Equal(t, 1, calls)
+
+	// Different params aren't.
+	_, err = do("/api/v1/series?match[]=down&start=0&end=3600")
+	require.NoError(t, err)
+	require.Equal(t, 2, calls)
+
+	// Errors are never cached.
+	status = http.StatusInternalServerError
+	for i := 0; i < 2; i++ {
+		resp, err := do("/api/v1/labels")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	}
+	require.Equal(t, 4, calls)
+
+	// Requests over the max query length are rejected without going downstream.
+	_, err = do("/api/v1/series?match[]=up&start=0&end=1000000000")
+	resp, ok := httpgrpc.HTTPResponseFromError(err)
+	require.True(t, ok)
+	require.Equal(t, int32(http.StatusBadRequest), resp.Code)
+	require.Equal(t, 4, calls)
+}
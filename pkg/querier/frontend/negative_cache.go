@@ -0,0 +1,98 @@
+package frontend
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/go-kit/kit/log/level"
+	"github.com/gogo/protobuf/proto"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/weaveworks/common/httpgrpc"
+
+	"github.com/cortexproject/cortex/pkg/chunk/cache"
+	"github.com/cortexproject/cortex/pkg/util"
+)
+
+// errorCacheKeys returns the keys under which errors for r may be cached.
+func errorCacheKeys(userID string, r *QueryRangeRequest) []string {
+	return []string{
+		// Queries which don't parse are invalid whatever their time range.
+		fmt.Sprintf("error:%s:%s", userID, r.Query),
+		// Other errors, like exceeding limits, depend on the time range.
+		fmt.Sprintf("error:%s:%s:%d:%d:%d", userID, r.Query, r.Start, r.End, r.Step),
+	}
+}
+
+// errorCacheKey returns the key under which err, returned for r, should be
+// cached, and false if it shouldn't be. Only errors downstream will return
+// again for the same request are cached, never transient ones like 5xxs.
+func errorCacheKey(userID string, r *QueryRangeRequest, err error) (string, bool) {
+	resp, ok := httpgrpc.HTTPResponseFromError(err)
+	if !ok {
+		return "", false
+	}
+
+	keys := errorCacheKeys(userID, r)
+	switch resp.Code {
+	case http.StatusBadRequest:
+		// Bad requests of queries which parse, e.g. with too many points or
+		// too long a range, are only bad for their time range.
+		if _, err := promql.ParseExpr(r.Query); err != nil {
+			return keys[0], true
+		}
+		return keys[1], true
+	case http.StatusUnprocessableEntity:
+		return keys[1], true
+	default:
+		return "", false
+	}
+}
+
+// getError returns any unexpired error cached for r, or nil.
+func (s resultsCache) getError(ctx context.Context, userID string, r *QueryRangeRequest) error {
+	keys := errorCacheKeys(userID, r)
+	hashed := make([]string, 0, len(keys))
+	for _, key := range keys {
+		hashed = append(hashed, cache.HashKey(key))
+	}
+
+	_, bufs, _ := s.cache.Fetch(ctx, hashed)
+	now := int64(model.Now())
+	for _, buf := range bufs {
+		var cached CachedError
+		if err := proto.Unmarshal(buf, &cached); err != nil {
+			level.Error(util.Logger).Log("msg", "error unmarshalling cached error", "err", err)
+			continue
+		}
+
+		// Guard against hash collisions, and honour the expiry.
+		if (cached.Key != keys[0] && cached.Key != keys[1]) || cached.Expiry < now || cached.Response == nil {
+			continue
+		}
+		return httpgrpc.ErrorFromHTTPResponse(cached.Response)
+	}
+	return nil
+}
+
+// putError caches err for r for the negative cache TTL, if it is cacheable.
+func (s resultsCache) putError(ctx context.Context, userID string, r *QueryRangeRequest, err error) {
+	key, ok := errorCacheKey(userID, r, err)
+	if !ok {
+		return
+	}
+
+	resp, _ := httpgrpc.HTTPResponseFromError(err)
+	buf, err := proto.Marshal(&CachedError{
+		Key:      key,
+		Response: resp,
+		Expiry:   int64(model.Now().Add(s.cfg.NegativeCacheTTL)),
+	})
+	if err != nil {
+		level.Error(util.Logger).Log("msg", "error marshalling cached error", "err", err)
+		return
+	}
+
+	s.cache.Store(ctx, []string{cache.HashKey(key)}, [][]byte{buf})
+}
@@ -0,0 +1,35 @@
+package frontend
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// PriorityHeader is the HTTP header clients set to the priority of their
+// query.  Clients evaluating queries in the background, like rulers, should
+// set it to "low", so interactive queries from dashboards are served first.
+const PriorityHeader = "X-Cortex-Query-Priority"
+
+type priority int
+
+const (
+	highPriority priority = iota
+	lowPriority
+)
+
+// withPriority returns a context carrying the priority requested in the
+// headers; requests without the header are high priority.  The context is
+// passed to the sub-queries sent downstream, so they are queued at the same
+// priority.
+func withPriority(ctx context.Context, h http.Header) context.Context {
+	if strings.EqualFold(h.Get(PriorityHeader), "low") {
+		return context.WithValue(ctx, priorityKey, lowPriority)
+	}
+	return ctx
+}
+
+func priorityFromContext(ctx context.Context) priority {
+	p, _ := ctx.Value(priorityKey).(priority)
+	return p
+}
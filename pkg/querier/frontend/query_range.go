@@ -9,9 +9,11 @@ import (
 	"net/url"
 	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-kit/kit/log/level"
+	"github.com/gogo/protobuf/proto"
 	jsoniter "github.com/json-iterator/go"
 	opentracing "github.com/opentracing/opentracing-go"
 	otlog "github.com/opentracing/opentracing-go/log"
@@ -25,11 +27,26 @@ import (
 
 var (
 	matrix                = model.ValMatrix.String()
+	vector                = model.ValVector.String()
+	scalar                = model.ValScalar.String()
 	json                  = jsoniter.ConfigCompatibleWithStandardLibrary
 	errUnexpectedResponse = httpgrpc.Errorf(http.StatusInternalServerError, "unexpected response type")
 )
 
+// ProtobufContentType is the content type of APIResponses encoded as
+// protobuf.  The frontend asks queriers for it, rather than JSON, which is
+// expensive to encode and decode, and only encodes JSON for its clients.
+const ProtobufContentType = "application/vnd.cortex.apiresponse+protobuf"
+
+// maxGETRequestLength is the longest query string sent in GET requests; longer
+// requests are sent as POSTs, to stay within URL length limits.
+const maxGETRequestLength = 4096
+
 func parseQueryRangeRequest(r *http.Request) (*QueryRangeRequest, error) {
+	if err := parseForm(r); err != nil {
+		return nil, err
+	}
+
 	var result QueryRangeRequest
 	var err error
 	result.Start, err = ParseTime(r.FormValue("start"))
@@ -55,12 +72,6 @@ func parseQueryRangeRequest(r *http.Request) (*QueryRangeRequest, error) {
 		return nil, errNegativeStep
 	}
 
-	// For safety, limit the number of returned points per timeseries.
-	// This is sufficient for 60s resolution for a week or 1h resolution for a year.
-	if (result.End-result.Start)/result.Step > 11000 {
-		return nil, errStepTooSmall
-	}
-
 	result.Query = r.FormValue("query")
 	result.Path = r.URL.Path
 	return &result, nil
@@ -77,19 +88,56 @@ func (q QueryRangeRequest) toHTTPRequest(ctx context.Context) (*http.Request, er
 		"step":  []string{encodeDurationMs(q.Step)},
 		"query": []string{q.Query},
 	}
-	u := &url.URL{
-		Path:     q.Path,
-		RawQuery: params.Encode(),
+	req := encodeRequest(ctx, q.Path, params)
+	req.Header.Set("Accept", ProtobufContentType)
+	return req, nil
+}
+
+// parseForm parses the URL query and any form-encoded body of r, leaving the
+// body in place for the request to be passed on as is.
+func parseForm(r *http.Request) error {
+	if r.Body != nil && r.Body != http.NoBody {
+		body, err := ioutil.ReadAll(r.Body)
+		r.Body.Close()
+		if err != nil {
+			return httpgrpc.Errorf(http.StatusBadRequest, "error reading request body: %v", err)
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		defer func() {
+			r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}()
 	}
-	req := &http.Request{
-		Method:     "GET",
-		RequestURI: u.String(), // This is what the httpgrpc code looks at.
-		URL:        u,
-		Body:       http.NoBody,
-		Header:     http.Header{},
+
+	if err := r.ParseForm(); err != nil {
+		return httpgrpc.Errorf(http.StatusBadRequest, "error parsing request form: %v", err)
 	}
+	return nil
+}
 
-	return req.WithContext(ctx), nil
+// encodeRequest builds a request for path with params; a GET, unless the
+// params are too long for the URL, in which case they're POSTed as a form.
+func encodeRequest(ctx context.Context, path string, params url.Values) *http.Request {
+	encoded := params.Encode()
+	u := &url.URL{
+		Path: path,
+	}
+	req := &http.Request{
+		Method: "GET",
+		URL:    u,
+		Body:   http.NoBody,
+		Header: http.Header{},
+	}
+	if len(encoded) <= maxGETRequestLength {
+		u.RawQuery = encoded
+	} else {
+		req.Method = "POST"
+		req.Body = ioutil.NopCloser(strings.NewReader(encoded))
+		req.ContentLength = int64(len(encoded))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	req.RequestURI = u.String() // This is what the httpgrpc code looks at.
+
+	return req.WithContext(ctx)
 }
 
 func (q QueryRangeRequest) logToSpan(ctx context.Context) {
@@ -156,7 +204,14 @@ func parseQueryRangeResponse(ctx context.Context, r *http.Response) (*APIRespons
 
 	sp.LogFields(otlog.Int("bytes", len(buf)))
 
+	// Queriers which don't support protobuf ignore the Accept header.
 	var resp APIResponse
+	if r.Header.Get("Content-Type") == ProtobufContentType {
+		if err := proto.Unmarshal(buf, &resp); err != nil {
+			return nil, httpgrpc.Errorf(http.StatusInternalServerError, "error decoding response: %v", err)
+		}
+		return &resp, nil
+	}
 	if err := json.Unmarshal(buf, &resp); err != nil {
 		return nil, httpgrpc.Errorf(http.StatusInternalServerError, "error decoding response: %v", err)
 	}
@@ -189,6 +244,83 @@ func (s *SampleStream) MarshalJSON() ([]byte, error) {
 	return json.Marshal(stream)
 }
 
+// UnmarshalJSON implements json.Unmarshaler. Vector results are decoded as
+// streams of a single sample, and scalar results as a single unlabelled stream.
+func (r *QueryRangeResponse) UnmarshalJSON(data []byte) error {
+	var resp struct {
+		ResultType string              `json:"resultType"`
+		Result     jsoniter.RawMessage `json:"result"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return err
+	}
+	r.ResultType = resp.ResultType
+	r.Result = nil
+	if len(resp.Result) == 0 {
+		return nil
+	}
+
+	switch resp.ResultType {
+	case vector:
+		var v []vectorSample
+		if err := json.Unmarshal(resp.Result, &v); err != nil {
+			return err
+		}
+		r.Result = make([]SampleStream, 0, len(v))
+		for _, sample := range v {
+			r.Result = append(r.Result, SampleStream{
+				Labels:  client.FromMetricsToLabelAdapters(sample.Metric),
+				Samples: []client.Sample{sample.Value},
+			})
+		}
+	case scalar:
+		var s client.Sample
+		if err := json.Unmarshal(resp.Result, &s); err != nil {
+			return err
+		}
+		r.Result = []SampleStream{{Samples: []client.Sample{s}}}
+	default:
+		return json.Unmarshal(resp.Result, &r.Result)
+	}
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (r QueryRangeResponse) MarshalJSON() ([]byte, error) {
+	var result interface{} = r.Result
+	switch r.ResultType {
+	case vector:
+		v := make([]vectorSample, 0, len(r.Result))
+		for _, stream := range r.Result {
+			for _, sample := range stream.Samples {
+				v = append(v, vectorSample{
+					Metric: client.FromLabelAdaptersToMetric(stream.Labels),
+					Value:  sample,
+				})
+			}
+		}
+		result = v
+	case scalar:
+		if len(r.Result) != 1 || len(r.Result[0].Samples) != 1 {
+			return nil, errUnexpectedResponse
+		}
+		result = r.Result[0].Samples[0]
+	}
+
+	return json.Marshal(struct {
+		ResultType string      `json:"resultType"`
+		Result     interface{} `json:"result"`
+	}{
+		ResultType: r.ResultType,
+		Result:     result,
+	})
+}
+
+type vectorSample struct {
+	Metric model.Metric  `json:"metric"`
+	Value  client.Sample `json:"value"`
+}
+
 func (a *APIResponse) toHTTPResponse(ctx context.Context) (*http.Response, error) {
 	sp, _ := opentracing.StartSpanFromContext(ctx, "APIResponse.toHTTPResponse")
 	defer sp.Finish()
@@ -218,6 +350,7 @@ func extract(start, end int64, extent Extent) *APIResponse {
 			ResultType: extent.Response.Data.ResultType,
 			Result:     extractMatrix(start, end, extent.Response.Data.Result),
 		},
+		Warnings: extent.Response.Warnings,
 	}
 }
 
@@ -249,22 +382,102 @@ func extractSampleStream(start, end int64, stream SampleStream) (SampleStream, b
 }
 
 func mergeAPIResponses(responses []*APIResponse) (*APIResponse, error) {
-	// Merge the responses.
-	sort.Sort(byFirstTime(responses))
-
 	if len(responses) == 0 {
 		return &APIResponse{
 			Status: statusSuccess,
 		}, nil
 	}
 
-	return &APIResponse{
-		Status: statusSuccess,
-		Data: QueryRangeResponse{
-			ResultType: model.ValMatrix.String(),
-			Result:     matrixMerge(responses),
-		},
-	}, nil
+	// Responses without a result type carry no results, and can be merged
+	// with any others.
+	resultType := ""
+	for _, resp := range responses {
+		switch {
+		case resp.Data.ResultType == "":
+		case resultType == "":
+			resultType = resp.Data.ResultType
+		case resp.Data.ResultType != resultType:
+			return nil, httpgrpc.Errorf(http.StatusInternalServerError, "cannot merge %s and %s results", resultType, resp.Data.ResultType)
+		}
+	}
+
+	switch resultType {
+	case vector:
+		return &APIResponse{
+			Status: statusSuccess,
+			Data: QueryRangeResponse{
+				ResultType: vector,
+				Result:     vectorMerge(responses),
+			},
+			Warnings: mergeWarnings(responses),
+		}, nil
+
+	case "", matrix:
+		// Merge the responses.
+		sort.Sort(byFirstTime(responses))
+		return &APIResponse{
+			Status: statusSuccess,
+			Data: QueryRangeResponse{
+				ResultType: matrix,
+				Result:     matrixMerge(responses),
+			},
+			Warnings: mergeWarnings(responses),
+		}, nil
+
+	default:
+		// Scalars can't be combined; a single one is passed through as is.
+		if len(responses) == 1 {
+			return responses[0], nil
+		}
+		return nil, httpgrpc.Errorf(http.StatusInternalServerError, "cannot merge %s results", resultType)
+	}
+}
+
+// mergeWarnings returns the distinct warnings of resps, in the order they
+// first appear.
+func mergeWarnings(resps []*APIResponse) []string {
+	var warnings []string
+	seen := map[string]struct{}{}
+	for _, resp := range resps {
+		for _, w := range resp.Warnings {
+			if _, ok := seen[w]; ok {
+				continue
+			}
+			seen[w] = struct{}{}
+			warnings = append(warnings, w)
+		}
+	}
+	return warnings
+}
+
+// vectorMerge merges instant vectors, keeping the latest sample of any series
+// present in more than one.
+func vectorMerge(resps []*APIResponse) []SampleStream {
+	output := map[string]SampleStream{}
+	for _, resp := range resps {
+		for _, stream := range resp.Data.Result {
+			metric := client.FromLabelAdaptersToLabels(stream.Labels).String()
+			existing, ok := output[metric]
+			if ok && len(stream.Samples) > 0 && len(existing.Samples) > 0 &&
+				stream.Samples[0].TimestampMs <= existing.Samples[0].TimestampMs {
+				continue
+			}
+			output[metric] = stream
+		}
+	}
+
+	keys := make([]string, 0, len(output))
+	for key := range output {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	result := make([]SampleStream, 0, len(output))
+	for _, key := range keys {
+		result = append(result, output[key])
+	}
+
+	return result
 }
 
 type byFirstTime []*APIResponse
@@ -6,6 +6,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"strconv"
+	"strings"
 	"testing"
 
 	jsoniter "github.com/json-iterator/go"
@@ -80,10 +81,6 @@ func TestQueryRangeRequest(t *testing.T) {
 			url:         "api/v1/query_range?start=123&end=456&step=-1",
 			expectedErr: errNegativeStep,
 		},
-		{
-			url:         "api/v1/query_range?start=0&end=11001&step=1",
-			expectedErr: errStepTooSmall,
-		},
 	} {
 		t.Run(strconv.Itoa(i), func(t *testing.T) {
 			r, err := http.NewRequest("GET", tc.url, nil)
@@ -106,6 +103,37 @@ func TestQueryRangeRequest(t *testing.T) {
 	}
 }
 
+func TestQueryRangePostRequest(t *testing.T) {
+	form := "end=1536716898&query=sum%28container_memory_rss%29+by+%28namespace%29&start=1536673680&step=120"
+	r, err := http.NewRequest("POST", "/api/v1/query_range", strings.NewReader(form))
+	require.NoError(t, err)
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	req, err := parseQueryRangeRequest(r)
+	require.NoError(t, err)
+	require.Equal(t, parsedRequest, req)
+
+	// The body is left in place for the request to be passed on.
+	body, err := ioutil.ReadAll(r.Body)
+	require.NoError(t, err)
+	require.Equal(t, form, string(body))
+}
+
+func TestQueryRangeRequestLongQuery(t *testing.T) {
+	long := parsedRequest.copy()
+	long.Query = "sum(" + strings.Repeat("foo + ", maxGETRequestLength/6) + "foo)"
+
+	r, err := long.toHTTPRequest(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "POST", r.Method)
+	require.Equal(t, "/api/v1/query_range", r.RequestURI)
+	require.Equal(t, "application/x-www-form-urlencoded", r.Header.Get("Content-Type"))
+
+	req, err := parseQueryRangeRequest(r)
+	require.NoError(t, err)
+	require.Equal(t, &long, req)
+}
+
 func TestQueryRangeResponse(t *testing.T) {
 	for i, tc := range []struct {
 		body     string
@@ -309,3 +337,89 @@ func mustParse(t *testing.T, apiResponse string) *APIResponse {
 	require.NoError(t, json.Unmarshal([]byte(apiResponse), &resp))
 	return &resp
 }
+
+func TestMergeAPIResponsesResultTypes(t *testing.T) {
+	sample := func(ns string, ts int64, v float64) SampleStream {
+		return SampleStream{
+			Labels:  []client.LabelAdapter{{Name: "ns", Value: ns}},
+			Samples: []client.Sample{{TimestampMs: ts, Value: v}},
+		}
+	}
+	resp := func(resultType string, streams ...SampleStream) *APIResponse {
+		return &APIResponse{
+			Status: statusSuccess,
+			Data: QueryRangeResponse{
+				ResultType: resultType,
+				Result:     streams,
+			},
+		}
+	}
+
+	// Vectors are concatenated, keeping the latest sample for repeated series.
+	merged, err := mergeAPIResponses([]*APIResponse{
+		resp(vector, sample("b", 1, 1), sample("c", 1, 1)),
+		resp(vector, sample("a", 1, 2), sample("c", 2, 2)),
+		{Status: statusSuccess},
+	})
+	require.NoError(t, err)
+	require.Equal(t, resp(vector, sample("a", 1, 2), sample("b", 1, 1), sample("c", 2, 2)), merged)
+
+	// A single scalar is passed through, but scalars can't be merged.
+	scalarResp := resp(scalar, SampleStream{Samples: []client.Sample{{TimestampMs: 1, Value: 1}}})
+	merged, err = mergeAPIResponses([]*APIResponse{scalarResp})
+	require.NoError(t, err)
+	require.Equal(t, scalarResp, merged)
+
+	_, err = mergeAPIResponses([]*APIResponse{scalarResp, scalarResp})
+	require.Error(t, err)
+
+	// Nor can different result types.
+	_, err = mergeAPIResponses([]*APIResponse{resp(matrix, sample("a", 1, 1)), resp(vector, sample("a", 1, 1))})
+	require.Error(t, err)
+}
+
+func TestQueryRangeResponseWarnings(t *testing.T) {
+	body := `{"status":"success","data":{"resultType":"matrix","result":[]},"warnings":["foo","bar"]}`
+	resp, err := parseQueryRangeResponse(context.Background(), &http.Response{
+		StatusCode: 200,
+		Body:       ioutil.NopCloser(bytes.NewBuffer([]byte(body))),
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"foo", "bar"}, resp.Warnings)
+
+	// Warnings are kept, once each, when merging.
+	other := &APIResponse{
+		Status:   statusSuccess,
+		Data:     QueryRangeResponse{ResultType: matrix},
+		Warnings: []string{"bar", "baz"},
+	}
+	merged, err := mergeAPIResponses([]*APIResponse{resp, other})
+	require.NoError(t, err)
+	require.Equal(t, []string{"foo", "bar", "baz"}, merged.Warnings)
+}
+
+func TestQueryRangeResponseProtobuf(t *testing.T) {
+	// Requests to queriers ask for protobuf...
+	req, err := parsedRequest.toHTTPRequest(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, ProtobufContentType, req.Header.Get("Accept"))
+
+	// ...and protobuf responses are decoded as such, JSON ones still as JSON.
+	buf, err := parsedResponse.Marshal()
+	require.NoError(t, err)
+	resp, err := parseQueryRangeResponse(context.Background(), &http.Response{
+		StatusCode: 200,
+		Header:     http.Header{"Content-Type": []string{ProtobufContentType}},
+		Body:       ioutil.NopCloser(bytes.NewBuffer(buf)),
+	})
+	require.NoError(t, err)
+	require.Equal(t, parsedResponse, resp)
+
+	resp, err = parseQueryRangeResponse(context.Background(), &http.Response{
+		StatusCode: 200,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       ioutil.NopCloser(strings.NewReader(responseBody)),
+	})
+	require.NoError(t, err)
+	require.Equal(t, parsedResponse, resp)
+}
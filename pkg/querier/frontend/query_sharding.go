@@ -0,0 +1,194 @@
+package frontend
+
+import (
+	"context"
+	"math"
+	"sort"
+
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/promql"
+
+	client "github.com/cortexproject/cortex/pkg/ingester/client"
+	"github.com/cortexproject/cortex/pkg/querier/sharding"
+	"github.com/cortexproject/cortex/pkg/util/validation"
+)
+
+// queryShardingMiddleware creates a new QueryRangeMiddleware that splits
+// shardable aggregations into one partial query per shard of series, and
+// combines their results.
+func queryShardingMiddleware(shards int, limits *validation.Overrides) QueryRangeMiddleware {
+	return QueryRangeMiddlewareFunc(func(next QueryRangeHandler) QueryRangeHandler {
+		return instrument("query_sharding").Wrap(queryShard{
+			next:   next,
+			limits: limits,
+			shards: shards,
+		})
+	})
+}
+
+type queryShard struct {
+	next   QueryRangeHandler
+	limits *validation.Overrides
+	shards int
+}
+
+func (s queryShard) Do(ctx context.Context, r *QueryRangeRequest) (*APIResponse, error) {
+	expr, err := promql.ParseExpr(r.Query)
+	if err != nil {
+		return s.next.Do(ctx, r)
+	}
+	agg, ok := shardable(expr)
+	if !ok {
+		return s.next.Do(ctx, r)
+	}
+
+	reqs := make([]*QueryRangeRequest, 0, s.shards)
+	for i := 0; i < s.shards; i++ {
+		query, err := shardQuery(agg, sharding.Shard{Index: i, Of: s.shards})
+		if err != nil {
+			// Not every aggregation prints as a query which parses back,
+			// those are just run unsharded.
+			return s.next.Do(ctx, r)
+		}
+		req := r.copy()
+		req.Query = query
+		reqs = append(reqs, &req)
+	}
+
+	queryTimingsFromContext(ctx).addShards(len(reqs))
+	reqResps, err := doRequests(ctx, s.next, reqs, s.limits)
+	if err != nil {
+		return nil, err
+	}
+
+	resps := make([]*APIResponse, 0, len(reqResps))
+	for _, reqResp := range reqResps {
+		resps = append(resps, reqResp.resp)
+	}
+	return combineShardedResponses(agg.Op, resps), nil
+}
+
+// shardable returns the aggregation at the root of expr if the results of
+// evaluating it over disjoint subsets of series can be combined into the
+// result over all of them. This holds for sum, min, max and count, provided
+// the aggregated expression evaluates every series on its own.
+func shardable(expr promql.Expr) (*promql.AggregateExpr, bool) {
+	agg, ok := expr.(*promql.AggregateExpr)
+	if !ok || agg.Param != nil {
+		return nil, false
+	}
+	switch agg.Op {
+	case promql.ItemSum, promql.ItemMin, promql.ItemMax, promql.ItemCount:
+	default:
+		return nil, false
+	}
+
+	ok = true
+	promql.Inspect(agg.Expr, func(node promql.Node, _ []promql.Node) error {
+		switch n := node.(type) {
+		case *promql.AggregateExpr, *promql.SubqueryExpr:
+			ok = false
+		case *promql.BinaryExpr:
+			// Vector matching pairs up series which may be in different shards.
+			if n.LHS.Type() == promql.ValueTypeVector && n.RHS.Type() == promql.ValueTypeVector {
+				ok = false
+			}
+		case *promql.Call:
+			// These depend on all the series selected by their argument: the
+			// buckets of a histogram are series of their own, hashed into
+			// different shards by their le label.  vector()'s series would be
+			// in every shard.
+			switch n.Func.Name {
+			case "absent", "scalar", "histogram_quantile", "vector":
+				ok = false
+			}
+		}
+		return nil
+	})
+	return agg, ok
+}
+
+// shardQuery returns the query for agg over the series in shard.
+func shardQuery(agg *promql.AggregateExpr, shard sharding.Shard) (string, error) {
+	// Re-parse so the matchers are added to a copy of the selectors.
+	expr, err := promql.ParseExpr(agg.String())
+	if err != nil {
+		return "", err
+	}
+	promql.Inspect(expr, func(node promql.Node, _ []promql.Node) error {
+		switch n := node.(type) {
+		case *promql.VectorSelector:
+			n.LabelMatchers = append(n.LabelMatchers, shard.Matcher())
+		case *promql.MatrixSelector:
+			n.LabelMatchers = append(n.LabelMatchers, shard.Matcher())
+		}
+		return nil
+	})
+	return expr.String(), nil
+}
+
+// combineShardedResponses combines the partial results of aggregating each
+// shard with op, samples of series with the same labels at the same time
+// being combined with op too (counts are summed).
+func combineShardedResponses(op promql.ItemType, resps []*APIResponse) *APIResponse {
+	combine := func(a, b float64) float64 { return a + b }
+	switch op {
+	case promql.ItemMin:
+		combine = math.Min
+	case promql.ItemMax:
+		combine = math.Max
+	}
+
+	type series struct {
+		labels  []client.LabelAdapter
+		samples map[int64]float64
+	}
+	output := map[string]*series{}
+	for _, resp := range resps {
+		for _, stream := range resp.Data.Result {
+			key := client.FromLabelAdaptersToLabels(stream.Labels).String()
+			existing, ok := output[key]
+			if !ok {
+				existing = &series{
+					labels:  stream.Labels,
+					samples: map[int64]float64{},
+				}
+				output[key] = existing
+			}
+			for _, sample := range stream.Samples {
+				if v, ok := existing.samples[sample.TimestampMs]; ok {
+					existing.samples[sample.TimestampMs] = combine(v, sample.Value)
+				} else {
+					existing.samples[sample.TimestampMs] = sample.Value
+				}
+			}
+		}
+	}
+
+	result := make([]SampleStream, 0, len(output))
+	for _, s := range output {
+		samples := make([]client.Sample, 0, len(s.samples))
+		for ts, v := range s.samples {
+			samples = append(samples, client.Sample{TimestampMs: ts, Value: v})
+		}
+		sort.Slice(samples, func(i, j int) bool {
+			return samples[i].TimestampMs < samples[j].TimestampMs
+		})
+		result = append(result, SampleStream{
+			Labels:  s.labels,
+			Samples: samples,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return labels.Compare(client.FromLabelAdaptersToLabels(result[i].Labels), client.FromLabelAdaptersToLabels(result[j].Labels)) < 0
+	})
+
+	return &APIResponse{
+		Status: statusSuccess,
+		Data: QueryRangeResponse{
+			ResultType: matrix,
+			Result:     result,
+		},
+		Warnings: mergeWarnings(resps),
+	}
+}
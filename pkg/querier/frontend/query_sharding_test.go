@@ -0,0 +1,127 @@
+package frontend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/prometheus/promql"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+
+	client "github.com/cortexproject/cortex/pkg/ingester/client"
+	"github.com/cortexproject/cortex/pkg/querier/sharding"
+)
+
+func TestShardable(t *testing.T) {
+	for _, tc := range []struct {
+		query     string
+		shardable bool
+	}{
+		{`sum(rate(foo[1m]))`, true},
+		{`sum by (ns) (rate(foo{bar="baz"}[1m]))`, true},
+		{`max without (pod) (foo * 2)`, true},
+		{`count(label_replace(foo, "a", "$1", "b", "(.*)"))`, true},
+		{`avg(foo)`, false},
+		{`topk(5, foo)`, false},
+		{`sum(foo) / sum(bar)`, false},
+		{`sum(foo / bar)`, false},
+		{`sum(sum by (pod) (foo))`, false},
+		{`sum(max_over_time(foo[5m:1m]))`, false},
+		{`sum(absent(foo))`, false},
+		{`max(histogram_quantile(0.99, rate(foo_bucket[1m])))`, false},
+		{`sum(vector(1))`, false},
+		{`rate(foo[1m])`, false},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			expr, err := promql.ParseExpr(tc.query)
+			require.NoError(t, err)
+			_, ok := shardable(expr)
+			require.Equal(t, tc.shardable, ok)
+		})
+	}
+}
+
+func TestShardQuery(t *testing.T) {
+	expr, err := promql.ParseExpr(`sum by (ns) (rate(foo{bar="baz"}[1m]) * on() group_left() vector(2))`)
+	require.NoError(t, err)
+	agg := expr.(*promql.AggregateExpr)
+
+	query, err := shardQuery(agg, sharding.Shard{Index: 1, Of: 4})
+	require.NoError(t, err)
+	require.Equal(t, `sum by(ns) (rate(foo{__cortex_shard__="1_of_4",bar="baz"}[1m]) * on() group_left() vector(2))`, query)
+
+	// The original expression is left untouched.
+	require.Equal(t, `sum by(ns) (rate(foo{bar="baz"}[1m]) * on() group_left() vector(2))`, agg.String())
+}
+
+func TestQuerySharding(t *testing.T) {
+	series := func(ns string, samples ...client.Sample) SampleStream {
+		return SampleStream{
+			Labels:  []client.LabelAdapter{{Name: "ns", Value: ns}},
+			Samples: samples,
+		}
+	}
+
+	var queries []string
+	handler := queryShardingMiddleware(2, defaultOverrides(t)).Wrap(QueryRangeHandlerFunc(func(_ context.Context, req *QueryRangeRequest) (*APIResponse, error) {
+		queries = append(queries, req.Query)
+		return &APIResponse{
+			Status: statusSuccess,
+			Data: QueryRangeResponse{
+				ResultType: matrix,
+				Result: []SampleStream{
+					series("b", client.Sample{TimestampMs: 0, Value: 1}),
+					series("a", client.Sample{TimestampMs: 0, Value: 2}, client.Sample{TimestampMs: 15000, Value: 3}),
+				},
+			},
+		}, nil
+	}))
+
+	ctx := user.InjectOrgID(context.Background(), "1")
+	resp, err := handler.Do(ctx, &QueryRangeRequest{
+		Path:  "/api/v1/query_range",
+		Start: 0,
+		End:   15 * seconds,
+		Step:  15 * seconds,
+		Query: `sum by (ns) (foo)`,
+	})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{
+		`sum by(ns) (foo{__cortex_shard__="0_of_2"})`,
+		`sum by(ns) (foo{__cortex_shard__="1_of_2"})`,
+	}, queries)
+	require.Equal(t, &APIResponse{
+		Status: statusSuccess,
+		Data: QueryRangeResponse{
+			ResultType: matrix,
+			Result: []SampleStream{
+				series("a", client.Sample{TimestampMs: 0, Value: 4}, client.Sample{TimestampMs: 15000, Value: 6}),
+				series("b", client.Sample{TimestampMs: 0, Value: 2}),
+			},
+		},
+	}, resp)
+}
+
+func TestCombineShardedResponses(t *testing.T) {
+	resp := func(v float64) *APIResponse {
+		return &APIResponse{
+			Status: statusSuccess,
+			Data: QueryRangeResponse{
+				ResultType: matrix,
+				Result: []SampleStream{{
+					Samples: []client.Sample{{TimestampMs: 0, Value: v}},
+				}},
+			},
+		}
+	}
+
+	for op, expected := range map[promql.ItemType]float64{
+		promql.ItemSum:   6,
+		promql.ItemCount: 6,
+		promql.ItemMin:   1,
+		promql.ItemMax:   3,
+	} {
+		combined := combineShardedResponses(op, []*APIResponse{resp(1), resp(3), resp(2)})
+		require.Equal(t, expected, combined.Data.Result[0].Samples[0].Value, op.String())
+	}
+}
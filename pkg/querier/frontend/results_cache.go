@@ -22,35 +22,60 @@ import (
 type ResultsCacheConfig struct {
 	CacheConfig       cache.Config  `yaml:"cache"`
 	MaxCacheFreshness time.Duration `yaml:"max_freshness"`
+	MaxExtentsPerKey  int           `yaml:"max_extents_per_key"`
+	NegativeCacheTTL  time.Duration `yaml:"negative_cache_ttl"`
+
+	WriteBackGoroutines int `yaml:"extents_writeback_goroutines"`
+	WriteBackBuffer     int `yaml:"extents_writeback_buffer"`
 }
 
 // RegisterFlags registers flags.
 func (cfg *ResultsCacheConfig) RegisterFlags(f *flag.FlagSet) {
 	cfg.CacheConfig.RegisterFlagsWithPrefix("frontend.", "", f)
 	f.DurationVar(&cfg.MaxCacheFreshness, "frontend.max-cache-freshness", 1*time.Minute, "Most recent allowed cacheable result, to prevent caching very recent results that might still be in flux.")
+	f.DurationVar(&cfg.NegativeCacheTTL, "frontend.negative-cache-ttl", 0, "How long to cache errors for invalid queries, so broken dashboards don't hit the queriers on every refresh. 0 disables caching errors.")
+	f.IntVar(&cfg.WriteBackGoroutines, "frontend.extents-write-back-goroutines", 0, "How many goroutines to use to write merged results back to the cache, off the request path. 0 writes them before responding.")
+	f.IntVar(&cfg.WriteBackBuffer, "frontend.extents-write-back-buffer", 100, "How many results cache writes to queue for the write back goroutines; writes beyond this are dropped.")
+	f.IntVar(&cfg.MaxExtentsPerKey, "frontend.max-extents-per-key", 16, "Maximum number of disjoint extents of results cached per query; the oldest are dropped beyond this. 0 means no limit.")
 }
 
 type resultsCache struct {
-	cfg    ResultsCacheConfig
-	next   queryRangeHandler
-	cache  cache.Cache
-	limits *validation.Overrides
+	cfg      ResultsCacheConfig
+	next     QueryRangeHandler
+	cache    cache.Cache
+	limits   *validation.Overrides
+	interval time.Duration
 }
 
-func newResultsCacheMiddleware(cfg ResultsCacheConfig, limits *validation.Overrides) (queryRangeMiddleware, error) {
+// newResultsCacheMiddleware creates a new results cache middleware; cached
+// results are bucketed by the given interval, which should be the same as the
+// interval queries are split by.  The returned cache must be stopped once the
+// middleware is no longer used.
+func newResultsCacheMiddleware(cfg ResultsCacheConfig, interval time.Duration, limits *validation.Overrides) (QueryRangeMiddleware, cache.Cache, error) {
 	c, err := cache.New(cfg.CacheConfig)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return queryRangeMiddlewareFunc(func(next queryRangeHandler) queryRangeHandler {
+	// Compressing and storing large merged extents is slow enough to add to
+	// the latency of the requests, so it can be moved off the request path.
+	c = cache.NewSnappy(c)
+	if cfg.WriteBackGoroutines > 0 {
+		c = cache.NewBackground(cfg.CacheConfig.Prefix+"results-cache", cache.BackgroundConfig{
+			WriteBackGoroutines: cfg.WriteBackGoroutines,
+			WriteBackBuffer:     cfg.WriteBackBuffer,
+		}, c)
+	}
+
+	return QueryRangeMiddlewareFunc(func(next QueryRangeHandler) QueryRangeHandler {
 		return &resultsCache{
-			cfg:    cfg,
-			next:   next,
-			cache:  cache.NewSnappy(c),
-			limits: limits,
+			cfg:      cfg,
+			next:     next,
+			cache:    c,
+			limits:   limits,
+			interval: interval,
 		}
-	}), nil
+	}), c, nil
 }
 
 func (s resultsCache) Do(ctx context.Context, r *QueryRangeRequest) (*APIResponse, error) {
@@ -59,34 +84,64 @@ func (s resultsCache) Do(ctx context.Context, r *QueryRangeRequest) (*APIRespons
 		return nil, err
 	}
 
+	if cacheControlFromContext(ctx).requestNoStore() {
+		queryTimingsFromContext(ctx).observeCache(0, 1)
+		return s.next.Do(ctx, r)
+	}
+
+	if s.cfg.NegativeCacheTTL > 0 {
+		if err := s.getError(ctx, userID, r); err != nil {
+			return nil, err
+		}
+	}
+
+	response, err := s.do(ctx, userID, r)
+	if err != nil && s.cfg.NegativeCacheTTL > 0 {
+		s.putError(ctx, userID, r, err)
+	}
+	return response, err
+}
+
+func (s resultsCache) do(ctx context.Context, userID string, r *QueryRangeRequest) (*APIResponse, error) {
 	var (
-		day      = r.Start / millisecondPerDay
-		key      = fmt.Sprintf("%s:%s:%d:%d", userID, r.Query, r.Step, day)
+		key      = generateKey(userID, r, s.interval)
 		extents  []Extent
 		response *APIResponse
+		err      error
 	)
 
 	maxCacheTime := int64(model.Now().Add(-s.cfg.MaxCacheFreshness))
 	if r.Start > maxCacheTime {
+		queryTimingsFromContext(ctx).observeCache(0, 1)
 		return s.next.Do(ctx, r)
 	}
 
-	cached, ok := s.get(ctx, key)
+	downstream, ctx := withDownstreamCacheControl(ctx)
+	cached, ok := getExtents(ctx, s.cache, key)
 	if ok {
 		response, extents, err = s.handleHit(ctx, r, cached)
 	} else {
 		response, extents, err = s.handleMiss(ctx, r)
 	}
 
-	if err == nil && len(extents) > 0 {
+	if err == nil && len(extents) > 0 && !downstream.responseNoStore() {
 		extents = s.filterRecentExtents(r, extents)
-		s.put(ctx, key, extents)
+		putExtents(ctx, s.cache, key, extents)
 	}
 
 	return response, err
 }
 
+// generateKey generates a cache key based on the userID, Request and interval.
+// Extents are bucketed by the interval containing the request start, so a
+// single key never accumulates more than an interval's worth of results.
+func generateKey(userID string, r *QueryRangeRequest, interval time.Duration) string {
+	currentInterval := r.Start / int64(interval/time.Millisecond)
+	return fmt.Sprintf("%s:%s:%d:%d", userID, r.Query, r.Step, currentInterval)
+}
+
 func (s resultsCache) handleMiss(ctx context.Context, r *QueryRangeRequest) (*APIResponse, []Extent, error) {
+	queryTimingsFromContext(ctx).observeCache(0, 1)
 	response, err := s.next.Do(ctx, r)
 	if err != nil {
 		return nil, nil, err
@@ -108,7 +163,14 @@ func (s resultsCache) handleHit(ctx context.Context, r *QueryRangeRequest, exten
 		err      error
 	)
 
+	sort.Slice(extents, func(i, j int) bool {
+		return extents[i].Start < extents[j].Start
+	})
 	requests, responses := partition(r, extents)
+	queryTimingsFromContext(ctx).observeCache(len(responses), len(requests))
+	if err := trackResponseSize(ctx, responses...); err != nil {
+		return nil, nil, err
+	}
 	if len(requests) == 0 {
 		response, err := mergeAPIResponses(responses)
 		// No downstream requests so no need to write back to the cache.
@@ -128,33 +190,57 @@ func (s resultsCache) handleHit(ctx context.Context, r *QueryRangeRequest, exten
 			Response: reqResp.resp,
 		})
 	}
+
+	mergedExtents, err := mergeExtents(extents, r.Step)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Queries for a moving time range, like refreshing dashboards, mostly hit
+	// the most recent results; keep those.
+	if max := s.cfg.MaxExtentsPerKey; max > 0 && len(mergedExtents) > max {
+		mergedExtents = mergedExtents[len(mergedExtents)-max:]
+	}
+
+	response, err := mergeAPIResponses(responses)
+	return response, mergedExtents, err
+}
+
+// mergeExtents merges extents that overlap or are less than a step apart,
+// trimming the overlapping part of the later extent, and returns the result
+// sorted by start time.
+func mergeExtents(extents []Extent, step int64) ([]Extent, error) {
 	sort.Slice(extents, func(i, j int) bool {
 		return extents[i].Start < extents[j].Start
 	})
 
-	// Merge any extents - they're guaranteed not to overlap.
+	var err error
 	accumulator, mergedExtents := extents[0], make([]Extent, 0, len(extents))
 	for i := 1; i < len(extents); i++ {
-		if accumulator.End+r.Step < extents[i].Start {
+		if accumulator.End+step < extents[i].Start {
 			mergedExtents = append(mergedExtents, accumulator)
 			accumulator = extents[i]
 			continue
 		}
 
+		// Nothing to add from extents contained in the accumulator.
+		if extents[i].End <= accumulator.End {
+			continue
+		}
+
+		trimmed := extract(accumulator.End+1, extents[i].End, extents[i])
 		accumulator.End = extents[i].End
-		accumulator.Response, err = mergeAPIResponses([]*APIResponse{accumulator.Response, extents[i].Response})
+		accumulator.Response, err = mergeAPIResponses([]*APIResponse{accumulator.Response, trimmed})
 		if err != nil {
-			return nil, nil, err
+			return nil, err
 		}
-
 	}
-	mergedExtents = append(mergedExtents, accumulator)
-
-	response, err := mergeAPIResponses(responses)
-	return response, mergedExtents, err
+	return append(mergedExtents, accumulator), nil
 }
 
-// partition calculates the required requests to satisfy req given the cached data.
+// partition calculates the required requests to satisfy req given the cached
+// data, which must be sorted by start time: one for each gap between the
+// extents overlapping req, and the cached responses for the overlaps.
 func partition(req *QueryRangeRequest, extents []Extent) ([]*QueryRangeRequest, []*APIResponse) {
 	var requests []*QueryRangeRequest
 	var cachedResponses []*APIResponse
@@ -174,6 +260,11 @@ func partition(req *QueryRangeRequest, extents []Extent) ([]*QueryRangeRequest,
 			requests = append(requests, &r)
 		}
 
+		// Skip extents covered by previous ones.
+		if extent.End <= start && len(cachedResponses) > 0 {
+			continue
+		}
+
 		// Extract the overlap from the cached extent.
 		cachedResponses = append(cachedResponses, extract(start, req.End, extent))
 		start = extent.End
@@ -191,18 +282,25 @@ func partition(req *QueryRangeRequest, extents []Extent) ([]*QueryRangeRequest,
 
 func (s resultsCache) filterRecentExtents(req *QueryRangeRequest, extents []Extent) []Extent {
 	maxCacheTime := (int64(model.Now().Add(-s.cfg.MaxCacheFreshness)) / req.Step) * req.Step
-	for i := range extents {
-		// Never cache data for the latest freshness period.
-		if extents[i].End > maxCacheTime {
-			extents[i].End = maxCacheTime
-			extents[i].Response = extract(extents[i].Start, maxCacheTime, extents[i])
+	filtered := extents[:0]
+	for _, extent := range extents {
+		// Never cache data for the latest freshness period; extents falling
+		// entirely within it, e.g. those fetched for the tail of a query
+		// whose start was cached, are dropped altogether.
+		if extent.Start > maxCacheTime {
+			continue
+		}
+		if extent.End > maxCacheTime {
+			extent.End = maxCacheTime
+			extent.Response = extract(extent.Start, maxCacheTime, extent)
 		}
+		filtered = append(filtered, extent)
 	}
-	return extents
+	return filtered
 }
 
-func (s resultsCache) get(ctx context.Context, key string) ([]Extent, bool) {
-	found, bufs, _ := s.cache.Fetch(ctx, []string{cache.HashKey(key)})
+func getExtents(ctx context.Context, c cache.Cache, key string) ([]Extent, bool) {
+	found, bufs, _ := c.Fetch(ctx, []string{cache.HashKey(key)})
 	if len(found) != 1 {
 		return nil, false
 	}
@@ -226,7 +324,7 @@ func (s resultsCache) get(ctx context.Context, key string) ([]Extent, bool) {
 	return resp.Extents, true
 }
 
-func (s resultsCache) put(ctx context.Context, key string, extents []Extent) {
+func putExtents(ctx context.Context, c cache.Cache, key string, extents []Extent) {
 	buf, err := proto.Marshal(&CachedResponse{
 		Key:     key,
 		Extents: extents,
@@ -236,5 +334,5 @@ func (s resultsCache) put(ctx context.Context, key string, extents []Extent) {
 		return
 	}
 
-	s.cache.Store(ctx, []string{cache.HashKey(key)}, [][]byte{buf})
+	c.Store(ctx, []string{cache.HashKey(key)}, [][]byte{buf})
 }
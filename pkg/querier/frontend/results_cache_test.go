@@ -2,17 +2,23 @@ package frontend
 
 import (
 	"context"
+	"errors"
+	"net/http"
 	"strconv"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/httpgrpc"
 	"github.com/weaveworks/common/user"
 
 	"github.com/cortexproject/cortex/pkg/chunk/cache"
 	client "github.com/cortexproject/cortex/pkg/ingester/client"
 	"github.com/cortexproject/cortex/pkg/util/flagext"
+	"github.com/cortexproject/cortex/pkg/util/test"
 	"github.com/cortexproject/cortex/pkg/util/validation"
 )
 
@@ -147,6 +153,29 @@ func TestPartiton(t *testing.T) {
 				mkAPIResponse(160, 200, 10),
 			},
 		},
+
+		// Test overlapping and contained extents.
+		{
+			input: &QueryRangeRequest{
+				Start: 100,
+				End:   200,
+			},
+			prevCachedResponse: []Extent{
+				mkExtent(50, 150),
+				mkExtent(60, 120),
+				mkExtent(140, 170),
+			},
+			expectedRequests: []*QueryRangeRequest{
+				{
+					Start: 170,
+					End:   200,
+				},
+			},
+			expectedCachedResponse: []*APIResponse{
+				mkAPIResponse(100, 150, 10),
+				mkAPIResponse(150, 170, 10),
+			},
+		},
 	} {
 		t.Run(strconv.Itoa(i), func(t *testing.T) {
 			reqs, resps := partition(tc.input, tc.prevCachedResponse)
@@ -156,6 +185,80 @@ func TestPartiton(t *testing.T) {
 	}
 }
 
+func TestMergeExtents(t *testing.T) {
+	for i, tc := range []struct {
+		input    []Extent
+		expected []Extent
+	}{
+		// Extents less than a step apart are merged.
+		{
+			input:    []Extent{mkExtent(0, 50), mkExtent(60, 100)},
+			expected: []Extent{mkExtent(0, 100)},
+		},
+		// Extents further apart are kept separate, and sorted.
+		{
+			input:    []Extent{mkExtent(100, 150), mkExtent(0, 50)},
+			expected: []Extent{mkExtent(0, 50), mkExtent(100, 150)},
+		},
+		// Overlaps are trimmed.
+		{
+			input:    []Extent{mkExtent(0, 100), mkExtent(50, 150)},
+			expected: []Extent{mkExtent(0, 150)},
+		},
+		// Contained extents are dropped.
+		{
+			input:    []Extent{mkExtent(0, 100), mkExtent(20, 50), mkExtent(100, 120)},
+			expected: []Extent{mkExtent(0, 120)},
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			merged, err := mergeExtents(tc.input, 10)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, merged)
+		})
+	}
+}
+
+func TestResultsCacheMaxExtentsPerKey(t *testing.T) {
+	rc := resultsCache{
+		cfg: ResultsCacheConfig{
+			MaxExtentsPerKey: 2,
+		},
+		next: QueryRangeHandlerFunc(func(_ context.Context, req *QueryRangeRequest) (*APIResponse, error) {
+			return mkAPIResponse(req.Start, req.End, req.Step), nil
+		}),
+		limits: defaultOverrides(t),
+	}
+
+	ctx := user.InjectOrgID(context.Background(), "1")
+	_, extents, err := rc.handleHit(ctx, &QueryRangeRequest{Start: 200, End: 250, Step: 10}, []Extent{
+		mkExtent(0, 10),
+		mkExtent(50, 60),
+		mkExtent(100, 110),
+	})
+	require.NoError(t, err)
+	require.Equal(t, []Extent{mkExtent(100, 110), mkExtent(200, 250)}, extents)
+}
+
+func TestGenerateKey(t *testing.T) {
+	for i, tc := range []struct {
+		r        *QueryRangeRequest
+		interval time.Duration
+		expected string
+	}{
+		{&QueryRangeRequest{Query: "foo", Start: 0, Step: 10}, day, "fake:foo:10:0"},
+		{&QueryRangeRequest{Query: "foo", Start: millisecondPerDay - 1, Step: 10}, day, "fake:foo:10:0"},
+		{&QueryRangeRequest{Query: "foo", Start: millisecondPerDay, Step: 10}, day, "fake:foo:10:1"},
+		{&QueryRangeRequest{Query: "bar", Start: 3 * millisecondPerDay, Step: 15}, day, "fake:bar:15:3"},
+		{&QueryRangeRequest{Query: "foo", Start: millisecondPerDay, Step: 10}, 6 * time.Hour, "fake:foo:10:4"},
+		{&QueryRangeRequest{Query: "foo", Start: 13 * millisecondPerDay, Step: 10}, 7 * day, "fake:foo:10:1"},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			require.Equal(t, tc.expected, generateKey("fake", tc.r, tc.interval))
+		})
+	}
+}
+
 func defaultOverrides(t *testing.T) *validation.Overrides {
 	var limits validation.Limits
 	flagext.DefaultValues(&limits)
@@ -166,17 +269,18 @@ func defaultOverrides(t *testing.T) *validation.Overrides {
 
 func TestResultsCache(t *testing.T) {
 	calls := 0
-	rcm, err := newResultsCacheMiddleware(
+	rcm, _, err := newResultsCacheMiddleware(
 		ResultsCacheConfig{
 			CacheConfig: cache.Config{
 				Cache: cache.NewMockCache(),
 			},
 		},
+		day,
 		defaultOverrides(t),
 	)
 	require.NoError(t, err)
 
-	rc := rcm.Wrap(queryRangeHandlerFunc(func(_ context.Context, req *QueryRangeRequest) (*APIResponse, error) {
+	rc := rcm.Wrap(QueryRangeHandlerFunc(func(_ context.Context, req *QueryRangeRequest) (*APIResponse, error) {
 		calls++
 		return parsedResponse, nil
 	}))
@@ -200,11 +304,46 @@ func TestResultsCache(t *testing.T) {
 	require.Equal(t, 2, calls)
 }
 
+func TestResultsCacheStatusHeader(t *testing.T) {
+	rcm, _, err := newResultsCacheMiddleware(
+		ResultsCacheConfig{
+			CacheConfig: cache.Config{
+				Cache: cache.NewMockCache(),
+			},
+		},
+		day,
+		defaultOverrides(t),
+	)
+	require.NoError(t, err)
+	rc := rcm.Wrap(QueryRangeHandlerFunc(func(_ context.Context, req *QueryRangeRequest) (*APIResponse, error) {
+		return parsedResponse, nil
+	}))
+
+	partial := parsedRequest.copy()
+	partial.End += 100
+	for _, tc := range []struct {
+		req      *QueryRangeRequest
+		expected string
+	}{
+		{parsedRequest, "miss"},
+		{parsedRequest, "hit"},
+		{&partial, "partial"},
+	} {
+		timings, ctx := withQueryTimings(user.InjectOrgID(context.Background(), "1"))
+		_, err := rc.Do(ctx, tc.req)
+		require.NoError(t, err)
+
+		h := http.Header{}
+		timings.setHeaders(h)
+		require.Equal(t, tc.expected, h.Get(cacheStatusHeader))
+	}
+}
+
 func TestResultsCacheRecent(t *testing.T) {
 	var cfg ResultsCacheConfig
 	flagext.DefaultValues(&cfg)
 	cfg.CacheConfig.Cache = cache.NewMockCache()
-	rcm, err := newResultsCacheMiddleware(cfg, defaultOverrides(t))
+	rcm, _, err := newResultsCacheMiddleware(cfg, day, defaultOverrides(t))
 	require.NoError(t, err)
 
 	req := parsedRequest.copy()
@@ -212,7 +351,7 @@ func TestResultsCacheRecent(t *testing.T) {
 	req.Start = req.End - (60 * 1e3)
 
 	calls := 0
-	rc := rcm.Wrap(queryRangeHandlerFunc(func(_ context.Context, r *QueryRangeRequest) (*APIResponse, error) {
+	rc := rcm.Wrap(QueryRangeHandlerFunc(func(_ context.Context, r *QueryRangeRequest) (*APIResponse, error) {
 		calls++
 		assert.Equal(t, r, &req)
 		return parsedResponse, nil
@@ -231,3 +370,112 @@ func TestResultsCacheRecent(t *testing.T) {
 	require.Equal(t, 2, calls)
 	require.Equal(t, parsedResponse, resp)
 }
+
+func TestResultsCacheWriteBack(t *testing.T) {
+	var cfg ResultsCacheConfig
+	flagext.DefaultValues(&cfg)
+	cfg.CacheConfig.Cache = cache.NewMockCache()
+	cfg.WriteBackGoroutines = 1
+	rcm, c, err := newResultsCacheMiddleware(cfg, day, defaultOverrides(t))
+	require.NoError(t, err)
+	defer c.Stop()
+
+	var calls int32
+	rc := rcm.Wrap(QueryRangeHandlerFunc(func(_ context.Context, req *QueryRangeRequest) (*APIResponse, error) {
+		atomic.AddInt32(&calls, 1)
+		return parsedResponse, nil
+	}))
+	ctx := user.InjectOrgID(context.Background(), "1")
+	resp, err := rc.Do(ctx, parsedRequest)
+	require.NoError(t, err)
+	require.Equal(t, parsedResponse, resp)
+
+	// Once the extents have been written back, requests are served from the cache.
+	test.Poll(t, time.Second, true, func() interface{} {
+		before := atomic.LoadInt32(&calls)
+		_, err := rc.Do(ctx, parsedRequest)
+		require.NoError(t, err)
+		return atomic.LoadInt32(&calls) == before
+	})
+}
+
+func TestFilterRecentExtents(t *testing.T) {
+	rc := resultsCache{cfg: ResultsCacheConfig{MaxCacheFreshness: 10 * time.Minute}}
+	now := int64(model.Now())
+	step := int64(1000)
+	maxCacheTime := ((now - int64(10*time.Minute/time.Millisecond)) / step) * step
+	req := &QueryRangeRequest{Step: step}
+
+	mk := func(start, end int64) Extent {
+		return Extent{Start: start, End: end, Response: mkAPIResponse(start, end, step)}
+	}
+	oldExtent := mk(0, 10*step)
+	straddling := mk(maxCacheTime-step, now)
+	recent := mk(maxCacheTime+step, now)
+
+	filtered := rc.filterRecentExtents(req, []Extent{oldExtent, straddling, recent})
+	require.Len(t, filtered, 2)
+	require.Equal(t, oldExtent, filtered[0])
+	require.Equal(t, maxCacheTime-step, filtered[1].Start)
+	require.Equal(t, maxCacheTime, filtered[1].End)
+	require.Equal(t, mkAPIResponse(maxCacheTime-step, maxCacheTime, step), filtered[1].Response)
+}
+
+func TestResultsCacheNegativeCaching(t *testing.T) {
+	rcm, _, err := newResultsCacheMiddleware(
+		ResultsCacheConfig{
+			CacheConfig: cache.Config{
+				Cache: cache.NewMockCache(),
+			},
+			NegativeCacheTTL: time.Minute,
+		},
+		day,
+		defaultOverrides(t),
+	)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name   string
+		err    error
+		cached bool
+		// Whether the error is still returned for a different time range.
+		anyRange bool
+	}{
+		{name: "bad request", err: httpgrpc.Errorf(http.StatusBadRequest, "parse error"), cached: true, anyRange: true},
+		{name: "too_many_points", err: httpgrpc.Errorf(http.StatusBadRequest, "exceeded maximum resolution of 11,000 points per timeseries"), cached: true},
+		{name: "execution error", err: httpgrpc.Errorf(http.StatusUnprocessableEntity, "too many samples"), cached: true},
+		{name: "server error", err: httpgrpc.Errorf(http.StatusInternalServerError, "oops")},
+		{name: "non-HTTP error", err: errors.New("oops")},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			rc := rcm.Wrap(QueryRangeHandlerFunc(func(_ context.Context, req *QueryRangeRequest) (*APIResponse, error) {
+				calls++
+				return nil, tc.err
+			}))
+			ctx := user.InjectOrgID(context.Background(), "1")
+			req := parsedRequest.copy()
+			req.Query = tc.name
+
+			for i := 0; i < 2; i++ {
+				_, err := rc.Do(ctx, &req)
+				require.Equal(t, tc.err, err)
+			}
+			expectedCalls := 2
+			if tc.cached {
+				expectedCalls = 1
+			}
+			require.Equal(t, expectedCalls, calls)
+
+			other := req.copy()
+			other.End += 60 * 1e3
+			_, err := rc.Do(ctx, &other)
+			require.Equal(t, tc.err, err)
+			if tc.anyRange {
+				require.Equal(t, expectedCalls, calls)
+			} else {
+				require.Equal(t, expectedCalls+1, calls)
+			}
+		})
+	}
+}
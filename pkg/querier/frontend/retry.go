@@ -0,0 +1,137 @@
+package frontend
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/weaveworks/common/httpgrpc"
+	"github.com/weaveworks/common/user"
+	"golang.org/x/time/rate"
+
+	"github.com/cortexproject/cortex/pkg/util"
+)
+
+var retryBudgetExhausted = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "cortex",
+	Name:      "frontend_retry_budget_exhausted_total",
+	Help:      "Number of failed query range requests not retried because the tenant ran out of retry budget.",
+}, []string{"user"})
+
+// retryConfig configures the retry middleware.
+type retryConfig struct {
+	MaxRetries int
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	// Budget is the number of retries per second each tenant may issue, with a
+	// burst of MaxRetries; zero means unlimited.
+	Budget float64
+}
+
+// retryMiddleware retries failed query range requests with exponential backoff.
+func retryMiddleware(cfg retryConfig, log log.Logger) QueryRangeMiddleware {
+	budget := newRetryBudget(cfg.Budget, cfg.MaxRetries)
+	return QueryRangeMiddlewareFunc(func(next QueryRangeHandler) QueryRangeHandler {
+		return instrument("retry").Wrap(retry{
+			cfg:    cfg,
+			log:    log,
+			budget: budget,
+			next:   next,
+		})
+	})
+}
+
+type retry struct {
+	cfg    retryConfig
+	log    log.Logger
+	budget *retryBudget
+	next   QueryRangeHandler
+}
+
+func (r retry) Do(ctx context.Context, req *QueryRangeRequest) (*APIResponse, error) {
+	userID, err := user.ExtractOrgID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// MaxRetries bounds the number of tries below, so the backoff itself
+	// doesn't need to.
+	backoff := util.NewBackoff(ctx, util.BackoffConfig{
+		MinBackoff: r.cfg.MinBackoff,
+		MaxBackoff: r.cfg.MaxBackoff,
+	})
+
+	for tries := 1; ; tries++ {
+		resp, err := r.next.Do(ctx, req)
+		if err == nil {
+			return resp, nil
+		}
+
+		if tries >= r.cfg.MaxRetries || !retryable(ctx, err) {
+			return nil, err
+		}
+
+		if !r.budget.allow(userID) {
+			retryBudgetExhausted.WithLabelValues(userID).Inc()
+			return nil, err
+		}
+
+		level.Error(r.log).Log("msg", "error processing query range request", "try", tries, "err", err)
+		backoff.Wait()
+		if !backoff.Ongoing() {
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// retryable returns true for errors which could succeed if tried again:
+// HTTP 5xx and non-HTTP errors, unless the request has been cancelled.
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if resp, ok := httpgrpc.HTTPResponseFromError(err); ok {
+		return resp.Code/100 == 5
+	}
+	return true
+}
+
+// retryBudget limits the rate of retries each tenant can issue, so a single
+// tenant's failing queries can't multiply the load on the queriers during
+// an outage.
+type retryBudget struct {
+	limit rate.Limit
+	burst int
+
+	mtx      sync.Mutex
+	limiters map[string]*rate.Limiter
+}
+
+func newRetryBudget(limit float64, burst int) *retryBudget {
+	return &retryBudget{
+		limit:    rate.Limit(limit),
+		burst:    burst,
+		limiters: map[string]*rate.Limiter{},
+	}
+}
+
+func (b *retryBudget) allow(userID string) bool {
+	if b.limit == 0 {
+		return true
+	}
+
+	b.mtx.Lock()
+	limiter, ok := b.limiters[userID]
+	if !ok {
+		limiter = rate.NewLimiter(b.limit, b.burst)
+		b.limiters[userID] = limiter
+	}
+	b.mtx.Unlock()
+
+	return limiter.Allow()
+}
@@ -0,0 +1,122 @@
+package frontend
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/httpgrpc"
+	"github.com/weaveworks/common/user"
+)
+
+func TestRetry(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		err           error
+		expectedTries int
+		expectedErr   bool
+	}{
+		{
+			name:          "server error",
+			err:           httpgrpc.Errorf(http.StatusInternalServerError, "fail"),
+			expectedTries: 5,
+			expectedErr:   true,
+		},
+		{
+			name:          "non-HTTP error",
+			err:           errors.New("fail"),
+			expectedTries: 5,
+			expectedErr:   true,
+		},
+		{
+			name:          "client error",
+			err:           httpgrpc.Errorf(http.StatusBadRequest, "fail"),
+			expectedTries: 1,
+			expectedErr:   true,
+		},
+		{
+			name:          "success",
+			expectedTries: 1,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tries := 0
+			handler := retryMiddleware(retryConfig{
+				MaxRetries: 5,
+				MinBackoff: time.Millisecond,
+				MaxBackoff: time.Millisecond,
+			}, log.NewNopLogger()).Wrap(QueryRangeHandlerFunc(func(_ context.Context, req *QueryRangeRequest) (*APIResponse, error) {
+				tries++
+				if tc.err != nil {
+					return nil, tc.err
+				}
+				return dummyResponse, nil
+			}))
+
+			ctx := user.InjectOrgID(context.Background(), "1")
+			resp, err := handler.Do(ctx, &QueryRangeRequest{})
+			require.Equal(t, tc.expectedTries, tries)
+			if tc.expectedErr {
+				require.Equal(t, tc.err, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, dummyResponse, resp)
+		})
+	}
+}
+
+func TestRetryCancelledDuringBackoff(t *testing.T) {
+	ctx, cancel := context.WithCancel(user.InjectOrgID(context.Background(), "1"))
+	defer cancel()
+
+	tries := 0
+	handler := retryMiddleware(retryConfig{
+		MaxRetries: 5,
+		MinBackoff: time.Hour,
+		MaxBackoff: time.Hour,
+	}, log.NewNopLogger()).Wrap(QueryRangeHandlerFunc(func(_ context.Context, req *QueryRangeRequest) (*APIResponse, error) {
+		tries++
+		time.AfterFunc(10*time.Millisecond, cancel)
+		return nil, httpgrpc.Errorf(http.StatusInternalServerError, "fail")
+	}))
+
+	_, err := handler.Do(ctx, &QueryRangeRequest{})
+	require.Equal(t, context.Canceled, err)
+	require.Equal(t, 1, tries)
+}
+
+func TestRetryBudget(t *testing.T) {
+	tries := 0
+	handler := retryMiddleware(retryConfig{
+		MaxRetries: 5,
+		MinBackoff: time.Millisecond,
+		MaxBackoff: time.Millisecond,
+		Budget:     1e-9,
+	}, log.NewNopLogger()).Wrap(QueryRangeHandlerFunc(func(_ context.Context, req *QueryRangeRequest) (*APIResponse, error) {
+		tries++
+		return nil, httpgrpc.Errorf(http.StatusInternalServerError, "fail")
+	}))
+
+	// The budget's burst allows 5 retries and hardly refills; the first request
+	// uses 4 of them, leaving a single retry for the second.
+	ctx := user.InjectOrgID(context.Background(), "1")
+	_, err := handler.Do(ctx, &QueryRangeRequest{})
+	require.Error(t, err)
+	require.Equal(t, 5, tries)
+
+	tries = 0
+	_, err = handler.Do(ctx, &QueryRangeRequest{})
+	require.Error(t, err)
+	require.Equal(t, 2, tries)
+
+	// Other tenants have their own budget.
+	tries = 0
+	_, err = handler.Do(user.InjectOrgID(context.Background(), "2"), &QueryRangeRequest{})
+	require.Error(t, err)
+	require.Equal(t, 5, tries)
+}
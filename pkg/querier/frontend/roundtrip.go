@@ -19,10 +19,16 @@ import (
 	"context"
 	"net/http"
 	"strings"
+	"time"
 
-	"github.com/cortexproject/cortex/pkg/util/validation"
-	"github.com/prometheus/prometheus/pkg/timestamp"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/promql"
 
+	"github.com/cortexproject/cortex/pkg/querier/stats"
+	"github.com/cortexproject/cortex/pkg/util"
+	"github.com/cortexproject/cortex/pkg/util/validation"
 	"github.com/weaveworks/common/httpgrpc"
 	"github.com/weaveworks/common/user"
 )
@@ -30,7 +36,6 @@ import (
 var (
 	errEndBeforeStart = httpgrpc.Errorf(http.StatusBadRequest, "end timestamp must not be before start time")
 	errNegativeStep   = httpgrpc.Errorf(http.StatusBadRequest, "zero or negative query resolution step widths are not accepted. Try a positive integer")
-	errStepTooSmall   = httpgrpc.Errorf(http.StatusBadRequest, "exceeded maximum resolution of 11,000 points per timeseries. Try decreasing the query resolution (?step=XX)")
 )
 
 // RoundTripperFunc is like http.HandlerFunc, but for http.RoundTripper.
@@ -41,32 +46,39 @@ func (fn RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error)
 	return fn(req)
 }
 
-// queryRangeHandlerFunc is like http.HandlerFunc, but for queryRangeHandler.
-type queryRangeHandlerFunc func(context.Context, *QueryRangeRequest) (*APIResponse, error)
+// QueryRangeHandlerFunc is like http.HandlerFunc, but for QueryRangeHandler.
+type QueryRangeHandlerFunc func(context.Context, *QueryRangeRequest) (*APIResponse, error)
 
-func (q queryRangeHandlerFunc) Do(ctx context.Context, req *QueryRangeRequest) (*APIResponse, error) {
+// Do implements QueryRangeHandler.
+func (q QueryRangeHandlerFunc) Do(ctx context.Context, req *QueryRangeRequest) (*APIResponse, error) {
 	return q(ctx, req)
 }
 
-type queryRangeHandler interface {
+// QueryRangeHandler handles parsed query range requests, like http.Handler
+// does HTTP requests.
+type QueryRangeHandler interface {
 	Do(context.Context, *QueryRangeRequest) (*APIResponse, error)
 }
 
-// queryRangeMiddlewareFunc is like http.HandlerFunc, but for queryRangeMiddleware.
-type queryRangeMiddlewareFunc func(queryRangeHandler) queryRangeHandler
+// QueryRangeMiddlewareFunc is like http.HandlerFunc, but for QueryRangeMiddleware.
+type QueryRangeMiddlewareFunc func(QueryRangeHandler) QueryRangeHandler
 
-func (q queryRangeMiddlewareFunc) Wrap(h queryRangeHandler) queryRangeHandler {
+// Wrap implements QueryRangeMiddleware.
+func (q QueryRangeMiddlewareFunc) Wrap(h QueryRangeHandler) QueryRangeHandler {
 	return q(h)
 }
 
-type queryRangeMiddleware interface {
-	Wrap(queryRangeHandler) queryRangeHandler
+// QueryRangeMiddleware wraps a QueryRangeHandler, to handle requests before
+// and after it does.  The frontend's own query range pipeline is a chain of
+// them, which more can be added to with Config.QueryRangeMiddlewares.
+type QueryRangeMiddleware interface {
+	Wrap(QueryRangeHandler) QueryRangeHandler
 }
 
 // merge produces a middleware that applies multiple middlesware in turn;
 // ie Merge(f,g,h).Wrap(handler) == f.Wrap(g.Wrap(h.Wrap(handler)))
-func merge(middlesware ...queryRangeMiddleware) queryRangeMiddleware {
-	return queryRangeMiddlewareFunc(func(next queryRangeHandler) queryRangeHandler {
+func merge(middlesware ...QueryRangeMiddleware) QueryRangeMiddleware {
+	return QueryRangeMiddlewareFunc(func(next QueryRangeHandler) QueryRangeHandler {
 		for i := len(middlesware) - 1; i >= 0; i-- {
 			next = middlesware[i].Wrap(next)
 		}
@@ -76,38 +88,183 @@ func merge(middlesware ...queryRangeMiddleware) queryRangeMiddleware {
 
 type queryRangeRoundTripper struct {
 	next                 http.RoundTripper
-	queryRangeMiddleware queryRangeHandler
+	queryRangeMiddleware QueryRangeHandler
+	instantQueryHandler  instantQueryHandler
+	metadata             http.RoundTripper
 	limits               *validation.Overrides
+
+	log                  log.Logger
+	logQueriesLongerThan time.Duration
+	maxCacheFreshness    time.Duration
 }
 
 func (q queryRangeRoundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
-	if !strings.HasSuffix(r.URL.Path, "/query_range") {
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/query_range"):
+		return q.roundTripQueryRange(r)
+	case strings.HasSuffix(r.URL.Path, "/query"):
+		if err := q.checkBlockedInstantQuery(r); err != nil {
+			return nil, err
+		}
+		if q.instantQueryHandler == nil {
+			return q.next.RoundTrip(r)
+		}
+		return q.roundTripInstantQuery(r)
+	case q.metadata != nil && isMetadataRequest(r.URL.Path):
+		return q.metadata.RoundTrip(r)
+	default:
 		return q.next.RoundTrip(r)
 	}
+}
 
+func (q queryRangeRoundTripper) roundTripQueryRange(r *http.Request) (*http.Response, error) {
 	request, err := parseQueryRangeRequest(r)
 	if err != nil {
 		return nil, err
 	}
 	request.logToSpan(r.Context())
 
-	userid, err := user.ExtractOrgID(r.Context())
+	start := time.Now()
+	queryStats, ctx := stats.AddToContext(r.Context())
+	timings, ctx := withQueryTimings(ctx)
+	cc, ctx := withCacheControl(ctx, r.Header)
+	response, err := q.queryRangeMiddleware.Do(ctx, request)
+	q.logSlowQuery(ctx, start, queryStats, timings, err,
+		"query", request.Query,
+		"start", model.Time(request.Start).Time(),
+		"end", model.Time(request.End).Time(),
+		"step", time.Duration(request.Step)*time.Millisecond)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := statsHTTPResponse(ctx, response, queryStats)
+	if err != nil {
+		return nil, err
+	}
+	q.setCacheControl(resp, cc, request.End)
+	timings.setHeaders(resp.Header)
+	return resp, nil
+}
+
+func (q queryRangeRoundTripper) roundTripInstantQuery(r *http.Request) (*http.Response, error) {
+	request, err := parseInstantQueryRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Invalid expressions are left to the queriers to reject, and string
+	// results can't be represented in an APIResponse; pass both through.
+	expr, err := promql.ParseExpr(request.Query)
+	if err != nil || expr.Type() == promql.ValueTypeString {
+		return q.next.RoundTrip(r)
+	}
+	request.logToSpan(r.Context())
+
+	start := time.Now()
+	queryStats, ctx := stats.AddToContext(r.Context())
+	timings, ctx := withQueryTimings(ctx)
+	cc, ctx := withCacheControl(ctx, r.Header)
+	response, err := q.instantQueryHandler.Do(ctx, request)
+	q.logSlowQuery(ctx, start, queryStats, timings, err,
+		"query", request.Query,
+		"time", model.Time(request.Time).Time())
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := statsHTTPResponse(ctx, response, queryStats)
 	if err != nil {
 		return nil, err
 	}
+	q.setCacheControl(resp, cc, request.Time)
+	timings.setHeaders(resp.Header)
+	return resp, nil
+}
+
+// setCacheControl marks responses which must not be cached by clients or
+// caches in front of the frontend: those including data more recent than the
+// max cache freshness, which may still change, and those including responses
+// from downstream marked no-store.
+func (q queryRangeRoundTripper) setCacheControl(resp *http.Response, cc *cacheControl, end int64) {
+	if cc.responseNoStore() || end > int64(model.Now().Add(-q.maxCacheFreshness)) {
+		resp.Header.Set(cacheControlHeader, noStoreValue)
+	}
+}
+
+// checkBlockedInstantQuery rejects instant queries the tenant's queries are
+// blocked by; range queries are checked by the limitsMiddleware.
+func (q queryRangeRoundTripper) checkBlockedInstantQuery(r *http.Request) error {
+	if q.limits == nil {
+		return nil
+	}
+	userID, err := user.ExtractOrgID(r.Context())
+	if err != nil {
+		return err
+	}
+	if err := parseForm(r); err != nil {
+		return err
+	}
+	return checkBlockedQuery(q.limits, userID, r.FormValue("query"))
+}
+
+// logSlowQuery logs the query, with the given details of the request, if it
+// took longer than logQueriesLongerThan.
+func (q queryRangeRoundTripper) logSlowQuery(ctx context.Context, start time.Time, queryStats *stats.Stats, timings *queryTimings, err error, request ...interface{}) {
+	elapsed := time.Since(start)
+	if q.logQueriesLongerThan == 0 || elapsed <= q.logQueriesLongerThan {
+		return
+	}
 
-	maxQueryLen := q.limits.MaxQueryLength(userid)
-	queryLen := timestamp.Time(request.End).Sub(timestamp.Time(request.Start))
-	if maxQueryLen != 0 && queryLen > maxQueryLen {
-		return nil, httpgrpc.Errorf(http.StatusBadRequest, validation.ErrQueryTooLong, queryLen, maxQueryLen)
+	s := queryStats.Load()
+	fields := append([]interface{}{"msg", "slow query"}, request...)
+	fields = append(fields,
+		"time_taken", elapsed,
+		"fetched_series", s.FetchedSeries,
+		"fetched_chunks", s.FetchedChunks,
+		"fetched_chunk_bytes", s.FetchedChunkBytes,
+		"fetched_samples", s.FetchedSamples,
+		"ingester_series", s.IngesterSeries,
+		"ingester_time", s.IngesterTime,
+		"store_series", s.StoreSeries,
+		"store_time", s.StoreTime,
+	)
+	fields = append(fields, timings.logFields()...)
+	if err != nil {
+		fields = append(fields, "err", err)
 	}
+	level.Info(util.WithContext(ctx, q.log)).Log(fields...)
+}
 
-	response, err := q.queryRangeMiddleware.Do(r.Context(), request)
+// statsHTTPResponse encodes the response, reporting the stats of all the
+// sub-queries sent to queriers in its headers and in the per-tenant metrics.
+func statsHTTPResponse(ctx context.Context, response *APIResponse, queryStats *stats.Stats) (*http.Response, error) {
+	resp, err := response.toHTTPResponse(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	return response.toHTTPResponse(r.Context())
+	resp.Header.Set(stats.HeaderName, queryStats.Encode())
+	if userID, err := user.ExtractOrgID(ctx); err == nil {
+		observeQueryStats(userID, queryStats.Load())
+	}
+	return resp, nil
+}
+
+// mergeResponseStats adds the stats a querier reported for a sub-query to
+// those of the query it is part of.
+func mergeResponseStats(ctx context.Context, response *http.Response) {
+	header := response.Header.Get(stats.HeaderName)
+	if header == "" {
+		return
+	}
+
+	s, err := stats.Decode(header)
+	if err != nil {
+		level.Warn(util.WithContext(ctx, util.Logger)).Log("msg", "invalid query stats", "err", err)
+		return
+	}
+	stats.FromContext(ctx).Merge(s)
 }
 
 type queryRangeTerminator struct {
@@ -128,11 +285,14 @@ func (q queryRangeTerminator) Do(ctx context.Context, r *QueryRangeRequest) (*AP
 	}
 
 	r.logToSpan(ctx)
+	queryTimingsFromContext(ctx).addAttempt()
 	response, err := q.next.RoundTrip(request)
 	if err != nil {
 		return nil, err
 	}
 	defer response.Body.Close()
 
+	mergeResponseStats(ctx, response)
+	observeCacheControl(ctx, response)
 	return parseQueryRangeResponse(ctx, response)
 }
@@ -1,17 +1,25 @@
 package frontend
 
 import (
+	"bytes"
 	"context"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/go-kit/kit/log"
 	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/httpgrpc"
 	"github.com/weaveworks/common/middleware"
 	"github.com/weaveworks/common/user"
+
+	"github.com/cortexproject/cortex/pkg/querier/stats"
+	"github.com/cortexproject/cortex/pkg/util/flagext"
 )
 
 func TestRoundTrip(t *testing.T) {
@@ -40,7 +48,6 @@ func TestRoundTrip(t *testing.T) {
 		queryRangeMiddleware: queryRangeTerminator{
 			next: downstream,
 		},
-		limits: defaultOverrides(t),
 	}
 
 	for i, tc := range []struct {
@@ -82,3 +89,86 @@ func (s singleHostRoundTripper) RoundTrip(r *http.Request) (*http.Response, erro
 	r.URL.Host = s.host
 	return s.next.RoundTrip(r)
 }
+
+func TestQueryRangeTerminatorStats(t *testing.T) {
+	terminator := queryRangeTerminator{
+		next: RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header: http.Header{
+					stats.HeaderName: []string{"series=1;chunks=2;samples=3;wall_time=1s"},
+				},
+				Body: ioutil.NopCloser(strings.NewReader(responseBody)),
+			}, nil
+		}),
+	}
+
+	// The stats of each sub-query are added to those of the query.
+	s, ctx := stats.AddToContext(user.InjectOrgID(context.Background(), "1"))
+	for i := 0; i < 2; i++ {
+		_, err := terminator.Do(ctx, parsedRequest)
+		require.NoError(t, err)
+	}
+	require.Equal(t, stats.Stats{
+		FetchedSeries:  2,
+		FetchedChunks:  4,
+		FetchedSamples: 6,
+		WallTime:       2 * time.Second,
+	}, s.Load())
+}
+
+func TestSlowQueryLog(t *testing.T) {
+	var buf bytes.Buffer
+	roundtripper := queryRangeRoundTripper{
+		queryRangeMiddleware: splitByIntervalMiddleware(day, defaultOverrides(t)).Wrap(QueryRangeHandlerFunc(func(context.Context, *QueryRangeRequest) (*APIResponse, error) {
+			time.Sleep(time.Millisecond)
+			return nil, httpgrpc.Errorf(http.StatusInternalServerError, "boom")
+		})),
+		log:                  log.NewLogfmtLogger(&buf),
+		logQueriesLongerThan: time.Millisecond,
+	}
+
+	// Three days, so three sub-queries.
+	req, err := http.NewRequest("GET", "/api/v1/query_range?query=up&start=0&end=259200&step=60", nil)
+	require.NoError(t, err)
+	req = req.WithContext(user.InjectOrgID(context.Background(), "1"))
+
+	_, err = roundtripper.RoundTrip(req)
+	require.Error(t, err)
+
+	line := buf.String()
+	for _, field := range []string{`msg="slow query"`, "org_id=1", "query=up", "step=1m0s", "splits=3", "shards=0", "split_by_interval_time=", "err="} {
+		require.Contains(t, line, field)
+	}
+
+	// Fast queries aren't logged.
+	buf.Reset()
+	roundtripper.logQueriesLongerThan = time.Hour
+	_, err = roundtripper.RoundTrip(req)
+	require.Error(t, err)
+	require.Empty(t, buf.String())
+}
+
+func TestQueryRangeMiddlewares(t *testing.T) {
+	var queries []string
+	var config Config
+	flagext.DefaultValues(&config)
+	config.QueryRangeMiddlewares = []QueryRangeMiddleware{
+		QueryRangeMiddlewareFunc(func(next QueryRangeHandler) QueryRangeHandler {
+			return QueryRangeHandlerFunc(func(ctx context.Context, r *QueryRangeRequest) (*APIResponse, error) {
+				queries = append(queries, r.Query)
+				return nil, httpgrpc.Errorf(http.StatusForbidden, "denied")
+			})
+		}),
+	}
+	frontend, err := New(config, log.NewNopLogger(), defaultOverrides(t))
+	require.NoError(t, err)
+
+	req, err := http.NewRequest("GET", "/api/v1/query_range?query=up&start=0&end=3600&step=60", nil)
+	require.NoError(t, err)
+	_, err = frontend.roundTripper.RoundTrip(req.WithContext(user.InjectOrgID(context.Background(), "1")))
+	httpResp, ok := httpgrpc.HTTPResponseFromError(err)
+	require.True(t, ok)
+	require.Equal(t, int32(http.StatusForbidden), httpResp.Code)
+	require.Equal(t, []string{"up"}, queries)
+}
@@ -0,0 +1,196 @@
+package frontend
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/prometheus/promql"
+
+	"github.com/cortexproject/cortex/pkg/util/validation"
+	"github.com/weaveworks/common/user"
+)
+
+const millisecondPerDay = int64(24 * time.Hour / time.Millisecond)
+
+// splitByIntervalMiddleware creates a new QueryRangeMiddleware that splits requests by a given interval.
+func splitByIntervalMiddleware(interval time.Duration, limits *validation.Overrides) QueryRangeMiddleware {
+	return QueryRangeMiddlewareFunc(func(next QueryRangeHandler) QueryRangeHandler {
+		return instrument("split_by_interval").Wrap(splitByInterval{
+			next:     next,
+			limits:   limits,
+			interval: interval,
+		})
+	})
+}
+
+type splitByInterval struct {
+	next     QueryRangeHandler
+	limits   *validation.Overrides
+	interval time.Duration
+}
+
+func (s splitByInterval) Do(ctx context.Context, r *QueryRangeRequest) (*APIResponse, error) {
+	if !splittable(r.Query, s.interval) {
+		return s.next.Do(ctx, r)
+	}
+
+	// First we're going to build new requests, one for each interval, taking care
+	// to line up the boundaries with step.
+	reqs := splitQuery(r, s.interval)
+	queryTimingsFromContext(ctx).addSplits(len(reqs))
+
+	reqResps, err := doRequests(ctx, s.next, reqs, s.limits)
+	if err != nil {
+		return nil, err
+	}
+
+	resps := make([]*APIResponse, 0, len(reqResps))
+	for _, reqResp := range reqResps {
+		resps = append(resps, reqResp.resp)
+	}
+
+	return mergeAPIResponses(resps)
+}
+
+// splittable returns whether the query should be split by the interval.
+// Queries with offsets or subqueries reaching back further than the interval
+// aren't: each of their sub-queries reads data from outside its own interval,
+// so they don't line up with the interval's cached results or chunk buckets,
+// and their sub-queries together read the data many times over.  Queries
+// which fail to parse are split, and left to the queriers to reject.
+func splittable(query string, interval time.Duration) bool {
+	expr, err := promql.ParseExpr(query)
+	if err != nil {
+		return true
+	}
+	return maxOffset(expr) <= interval
+}
+
+// maxOffset returns how far back from the evaluation time any selector in
+// the expression is shifted by offsets and enclosing subqueries.  Selectors'
+// own ranges and the lookback delta aren't included, as they stay within a
+// day for all practical queries.
+func maxOffset(expr promql.Expr) time.Duration {
+	var max time.Duration
+	promql.Inspect(expr, func(node promql.Node, path []promql.Node) error {
+		var offset time.Duration
+		for _, n := range path {
+			if sq, ok := n.(*promql.SubqueryExpr); ok {
+				offset += sq.Range + sq.Offset
+			}
+		}
+
+		switch n := node.(type) {
+		case *promql.VectorSelector:
+			offset += n.Offset
+		case *promql.MatrixSelector:
+			offset += n.Offset
+		case *promql.SubqueryExpr:
+			offset += n.Range + n.Offset
+		default:
+			return nil
+		}
+		if offset > max {
+			max = offset
+		}
+		return nil
+	})
+	return max
+}
+
+func splitQuery(r *QueryRangeRequest, interval time.Duration) []*QueryRangeRequest {
+	reqs := []*QueryRangeRequest{}
+	for start := r.Start; start < r.End; start = nextIntervalBoundary(start, r.Step, interval) + r.Step {
+		end := nextIntervalBoundary(start, r.Step, interval)
+		if end+r.Step >= r.End {
+			end = r.End
+		}
+
+		reqs = append(reqs, &QueryRangeRequest{
+			Path:  r.Path,
+			Start: start,
+			End:   end,
+			Step:  r.Step,
+			Query: r.Query,
+		})
+	}
+	return reqs
+}
+
+// Round up to the step before the next interval boundary.
+func nextIntervalBoundary(t, step int64, interval time.Duration) int64 {
+	msPerInterval := int64(interval / time.Millisecond)
+	startOfNextInterval := ((t / msPerInterval) + 1) * msPerInterval
+	// ensure that target is a multiple of steps away from the start time
+	target := startOfNextInterval - ((startOfNextInterval - t) % step)
+	if target == startOfNextInterval {
+		target -= step
+	}
+	return target
+}
+
+type requestResponse struct {
+	req  *QueryRangeRequest
+	resp *APIResponse
+}
+
+func doRequests(ctx context.Context, downstream QueryRangeHandler, reqs []*QueryRangeRequest, limits *validation.Overrides) ([]requestResponse, error) {
+	userid, err := user.ExtractOrgID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// If one of the requests fail, we want to be able to cancel the rest of them.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Feed all requests to a bounded intermediate channel to limit parallelism.
+	intermediate := make(chan *QueryRangeRequest)
+	go func() {
+		for _, req := range reqs {
+			intermediate <- req
+		}
+		close(intermediate)
+	}()
+
+	respChan, errChan := make(chan requestResponse), make(chan error)
+	parallelism := limits.MaxQueryParallelism(userid)
+	if parallelism > len(reqs) {
+		parallelism = len(reqs)
+	}
+	for i := 0; i < parallelism; i++ {
+		go func() {
+			for req := range intermediate {
+				// Don't send any more requests downstream once the client has
+				// gone away or another request has failed.
+				if err := ctx.Err(); err != nil {
+					errChan <- err
+					continue
+				}
+
+				resp, err := downstream.Do(ctx, req)
+				if err != nil {
+					errChan <- err
+				} else {
+					respChan <- requestResponse{req, resp}
+				}
+			}
+		}()
+	}
+
+	resps := make([]requestResponse, 0, len(reqs))
+	var firstErr error
+	for range reqs {
+		select {
+		case resp := <-respChan:
+			resps = append(resps, resp)
+		case err := <-errChan:
+			if firstErr == nil {
+				cancel()
+				firstErr = err
+			}
+		}
+	}
+
+	return resps, firstErr
+}
@@ -7,39 +7,53 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"strconv"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"github.com/weaveworks/common/middleware"
 	"github.com/weaveworks/common/user"
+
+	"github.com/cortexproject/cortex/pkg/util/flagext"
+	"github.com/cortexproject/cortex/pkg/util/validation"
 )
 
-const seconds = 1e3 // 1e3 milliseconds per second.
+const (
+	seconds = 1e3 // 1e3 milliseconds per second.
+	day     = 24 * time.Hour
+)
 
-func TestNextDayBoundary(t *testing.T) {
+func TestNextIntervalBoundary(t *testing.T) {
 	for i, tc := range []struct {
 		in, step, out int64
+		interval      time.Duration
 	}{
 		// Smallest possible period is 1 millisecond
-		{0, 1, millisecondPerDay - 1},
+		{0, 1, millisecondPerDay - 1, day},
 		// A more standard example
-		{0, 15 * seconds, millisecondPerDay - 15*seconds},
+		{0, 15 * seconds, millisecondPerDay - 15*seconds, day},
 		// Move start time forward 1 second; end time moves the same
-		{1 * seconds, 15 * seconds, millisecondPerDay - (15-1)*seconds},
+		{1 * seconds, 15 * seconds, millisecondPerDay - (15-1)*seconds, day},
 		// Move start time forward 14 seconds; end time moves the same
-		{14 * seconds, 15 * seconds, millisecondPerDay - (15-14)*seconds},
+		{14 * seconds, 15 * seconds, millisecondPerDay - (15-14)*seconds, day},
 		// Now some examples where the period does not divide evenly into a day:
 		// 1 day modulus 35 seconds = 20 seconds
-		{0, 35 * seconds, millisecondPerDay - 20*seconds},
+		{0, 35 * seconds, millisecondPerDay - 20*seconds, day},
 		// Move start time forward 1 second; end time moves the same
-		{1 * seconds, 35 * seconds, millisecondPerDay - (20-1)*seconds},
+		{1 * seconds, 35 * seconds, millisecondPerDay - (20-1)*seconds, day},
 		// If the end time lands exactly on midnight we stop one period before that
-		{20 * seconds, 35 * seconds, millisecondPerDay - 35*seconds},
+		{20 * seconds, 35 * seconds, millisecondPerDay - 35*seconds, day},
 		// This example starts 35 seconds after the 5th one ends
-		{millisecondPerDay + 15*seconds, 35 * seconds, 2*millisecondPerDay - 5*seconds},
+		{millisecondPerDay + 15*seconds, 35 * seconds, 2*millisecondPerDay - 5*seconds, day},
+		// Intervals shorter than a day.
+		{0, 15 * seconds, 6*3600*seconds - 15*seconds, 6 * time.Hour},
+		{7 * 3600 * seconds, 15 * seconds, 12*3600*seconds - 15*seconds, 6 * time.Hour},
+		// Intervals longer than a day.
+		{0, 60 * seconds, 7*millisecondPerDay - 60*seconds, 7 * day},
 	} {
 		t.Run(strconv.Itoa(i), func(t *testing.T) {
-			require.Equal(t, tc.out, nextDayBoundary(tc.in, tc.step))
+			require.Equal(t, tc.out, nextIntervalBoundary(tc.in, tc.step, tc.interval))
 		})
 	}
 }
@@ -48,6 +62,7 @@ func TestSplitQuery(t *testing.T) {
 	for i, tc := range []struct {
 		input    *QueryRangeRequest
 		expected []*QueryRangeRequest
+		interval time.Duration
 	}{
 		{
 			input: &QueryRangeRequest{
@@ -64,6 +79,7 @@ func TestSplitQuery(t *testing.T) {
 					Query: "foo",
 				},
 			},
+			interval: day,
 		},
 		{
 			input: &QueryRangeRequest{
@@ -80,6 +96,7 @@ func TestSplitQuery(t *testing.T) {
 					Query: "foo",
 				},
 			},
+			interval: day,
 		},
 		{
 			input: &QueryRangeRequest{
@@ -102,6 +119,7 @@ func TestSplitQuery(t *testing.T) {
 					Query: "foo",
 				},
 			},
+			interval: day,
 		},
 		{
 			input: &QueryRangeRequest{
@@ -130,16 +148,46 @@ func TestSplitQuery(t *testing.T) {
 					Query: "foo",
 				},
 			},
+			interval: day,
+		},
+		{
+			input: &QueryRangeRequest{
+				Start: 2 * 3600 * seconds,
+				End:   7 * 3600 * seconds,
+				Step:  15 * seconds,
+				Query: "foo",
+			},
+			expected: []*QueryRangeRequest{
+				{
+					Start: 2 * 3600 * seconds,
+					End:   (3 * 3600 * seconds) - (15 * seconds),
+					Step:  15 * seconds,
+					Query: "foo",
+				},
+				{
+					Start: 3 * 3600 * seconds,
+					End:   (6 * 3600 * seconds) - (15 * seconds),
+					Step:  15 * seconds,
+					Query: "foo",
+				},
+				{
+					Start: 6 * 3600 * seconds,
+					End:   7 * 3600 * seconds,
+					Step:  15 * seconds,
+					Query: "foo",
+				},
+			},
+			interval: 3 * time.Hour,
 		},
 	} {
 		t.Run(strconv.Itoa(i), func(t *testing.T) {
-			days := splitQuery(tc.input)
-			require.Equal(t, tc.expected, days)
+			reqs := splitQuery(tc.input, tc.interval)
+			require.Equal(t, tc.expected, reqs)
 		})
 	}
 }
 
-func TestSplitByDay(t *testing.T) {
+func TestSplitByInterval(t *testing.T) {
 	s := httptest.NewServer(
 		middleware.AuthenticateUser.Wrap(
 			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -153,16 +201,16 @@ func TestSplitByDay(t *testing.T) {
 	require.NoError(t, err)
 
 	roundtripper := queryRangeRoundTripper{
-		queryRangeMiddleware: splitByDay{
+		queryRangeMiddleware: splitByInterval{
 			next: queryRangeTerminator{
 				next: singleHostRoundTripper{
 					host: u.Host,
 					next: http.DefaultTransport,
 				},
 			},
-			limits: defaultOverrides(t),
+			limits:   defaultOverrides(t),
+			interval: day,
 		},
-		limits: defaultOverrides(t),
 	}
 
 	mergedResponse, err := mergeAPIResponses([]*APIResponse{
@@ -199,3 +247,55 @@ func TestSplitByDay(t *testing.T) {
 		})
 	}
 }
+
+func TestDoRequestsCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(user.InjectOrgID(context.Background(), "1"))
+	calls := int32(0)
+	downstream := QueryRangeHandlerFunc(func(ctx context.Context, req *QueryRangeRequest) (*APIResponse, error) {
+		// The client goes away during the first request.
+		atomic.AddInt32(&calls, 1)
+		cancel()
+		return nil, ctx.Err()
+	})
+
+	var limits validation.Limits
+	flagext.DefaultValues(&limits)
+	limits.MaxQueryParallelism = 1
+	overrides, err := validation.NewOverrides(limits)
+	require.NoError(t, err)
+
+	reqs := splitQuery(&QueryRangeRequest{Start: 0, End: 10 * 24 * 3600 * seconds, Step: 15 * seconds}, day)
+	_, err = doRequests(ctx, downstream, reqs, overrides)
+	require.Equal(t, context.Canceled, err)
+
+	// None of the remaining requests are sent once the client has gone away.
+	require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestSplittable(t *testing.T) {
+	for query, expected := range map[string]bool{
+		`rate(foo[5m])`:                          true,
+		`foo offset 1h`:                          true,
+		`rate(foo[1h] offset 23h)`:               true,
+		`foo offset 2d`:                          false,
+		`sum(rate(foo[5m] offset 1w))`:           false,
+		`max_over_time(rate(foo[5m])[1h:1m])`:    true,
+		`max_over_time(rate(foo[5m])[2d:1h])`:    false,
+		`max_over_time(foo[5h:1m] offset 20h)`:   false,
+		`max_over_time((foo offset 20h)[5h:1m])`: false,
+		`foo{`:                                   true,
+	} {
+		require.Equal(t, expected, splittable(query, day), query)
+	}
+
+	// Unsplittable queries are passed through whole.
+	var reqs []*QueryRangeRequest
+	handler := splitByIntervalMiddleware(day, defaultOverrides(t)).Wrap(QueryRangeHandlerFunc(func(_ context.Context, req *QueryRangeRequest) (*APIResponse, error) {
+		reqs = append(reqs, req)
+		return parsedResponse, nil
+	}))
+	req := &QueryRangeRequest{Query: `foo offset 2d`, Start: 0, End: 3 * millisecondPerDay, Step: 60 * 1000}
+	_, err := handler.Do(user.InjectOrgID(context.Background(), "1"), req)
+	require.NoError(t, err)
+	require.Equal(t, []*QueryRangeRequest{req}, reqs)
+}
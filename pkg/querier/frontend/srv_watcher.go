@@ -0,0 +1,98 @@
+package frontend
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"google.golang.org/grpc/naming"
+)
+
+// srvPrefix marks frontend addresses to be looked up as DNS SRV records.
+const srvPrefix = "dnssrv+"
+
+// srvWatcher is a naming.Watcher polling the SRV records of a name, for the
+// frontends' addresses and ports.
+type srvWatcher struct {
+	name   string
+	freq   time.Duration
+	log    log.Logger
+	lookup func(ctx context.Context, name string) ([]*net.SRV, error)
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	addrs  map[string]struct{}
+	first  bool
+}
+
+func newSRVWatcher(name string, freq time.Duration, log log.Logger) *srvWatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &srvWatcher{
+		name: name,
+		freq: freq,
+		log:  log,
+		lookup: func(ctx context.Context, name string) ([]*net.SRV, error) {
+			_, srvs, err := net.DefaultResolver.LookupSRV(ctx, "", "", name)
+			return srvs, err
+		},
+
+		ctx:    ctx,
+		cancel: cancel,
+		addrs:  map[string]struct{}{},
+		first:  true,
+	}
+}
+
+// Next implements naming.Watcher.  It blocks until the set of addresses
+// changes, returning the changes.  Failed lookups keep the previous
+// addresses.
+func (w *srvWatcher) Next() ([]*naming.Update, error) {
+	for {
+		if !w.first {
+			select {
+			case <-time.After(w.freq):
+			case <-w.ctx.Done():
+				return nil, w.ctx.Err()
+			}
+		}
+		w.first = false
+
+		srvs, err := w.lookup(w.ctx, w.name)
+		if err != nil {
+			if w.ctx.Err() != nil {
+				return nil, w.ctx.Err()
+			}
+			level.Warn(w.log).Log("msg", "error looking up frontends' SRV records", "name", w.name, "err", err)
+			continue
+		}
+
+		addrs := make(map[string]struct{}, len(srvs))
+		for _, srv := range srvs {
+			addrs[net.JoinHostPort(srv.Target, fmt.Sprint(srv.Port))] = struct{}{}
+		}
+
+		var updates []*naming.Update
+		for addr := range w.addrs {
+			if _, ok := addrs[addr]; !ok {
+				updates = append(updates, &naming.Update{Op: naming.Delete, Addr: addr})
+			}
+		}
+		for addr := range addrs {
+			if _, ok := w.addrs[addr]; !ok {
+				updates = append(updates, &naming.Update{Op: naming.Add, Addr: addr})
+			}
+		}
+		w.addrs = addrs
+		if len(updates) > 0 {
+			return updates, nil
+		}
+	}
+}
+
+// Close implements naming.Watcher.
+func (w *srvWatcher) Close() {
+	w.cancel()
+}
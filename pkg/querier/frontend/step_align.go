@@ -4,18 +4,22 @@ import (
 	"context"
 )
 
-var stepAlignMiddleware = queryRangeMiddlewareFunc(func(next queryRangeHandler) queryRangeHandler {
+var stepAlignMiddleware = QueryRangeMiddlewareFunc(func(next QueryRangeHandler) QueryRangeHandler {
 	return instrument("step_align").Wrap(stepAlign{
 		next: next,
 	})
 })
 
+// stepAlign aligns the start and end of requests to a multiple of their step,
+// so repeated refreshes of the same dashboard generate the same sub-queries
+// and can be served from the results cache.
 type stepAlign struct {
-	next queryRangeHandler
+	next QueryRangeHandler
 }
 
 func (s stepAlign) Do(ctx context.Context, r *QueryRangeRequest) (*APIResponse, error) {
-	r.Start = (r.Start / r.Step) * r.Step
-	r.End = (r.End / r.Step) * r.Step
-	return s.next.Do(ctx, r)
+	aligned := r.copy()
+	aligned.Start = (r.Start / r.Step) * r.Step
+	aligned.End = (r.End / r.Step) * r.Step
+	return s.next.Do(ctx, &aligned)
 }
@@ -41,13 +41,17 @@ func TestStepAlign(t *testing.T) {
 		t.Run(strconv.Itoa(i), func(t *testing.T) {
 			var result *QueryRangeRequest
 			s := stepAlign{
-				next: queryRangeHandlerFunc(func(_ context.Context, req *QueryRangeRequest) (*APIResponse, error) {
+				next: QueryRangeHandlerFunc(func(_ context.Context, req *QueryRangeRequest) (*APIResponse, error) {
 					result = req
 					return nil, nil
 				}),
 			}
+			input := tc.input.copy()
 			s.Do(context.Background(), tc.input)
 			require.Equal(t, tc.expected, result)
+
+			// The incoming request must not be modified.
+			require.Equal(t, &input, tc.input)
 		})
 	}
 }
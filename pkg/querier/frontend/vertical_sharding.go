@@ -0,0 +1,379 @@
+package frontend
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/pkg/value"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/weaveworks/common/httpgrpc"
+
+	client "github.com/cortexproject/cortex/pkg/ingester/client"
+	"github.com/cortexproject/cortex/pkg/util/validation"
+)
+
+// sliceLabel identifies the series standing in for the results of a sliced
+// call in the rewritten query the frontend evaluates.
+const sliceLabel = "__cortex_slice__"
+
+// sliceExpr is the expression standing in for the sliced call with the given
+// id: it selects the call's results and drops their slice label, so that
+// they match those of the other sliced calls in binary operations.
+const sliceExpr = `label_replace({` + sliceLabel + `="%d"}, "` + sliceLabel + `", "", "", "")`
+
+// verticalShardingMiddleware creates a new QueryRangeMiddleware that splits
+// the range vectors of rate() and increase() calls longer than interval into
+// slices of time, each evaluated by its own sub-query, to spread the samples
+// of queries which can't be sharded by series across queriers.  The slices'
+// results are recombined and the rest of the query evaluated in the frontend,
+// so queries with selectors outside of the sliced calls are left alone.
+//
+// As each slice's increase is extrapolated to its boundaries, the results can
+// differ slightly from those of evaluating the query in one piece; the more
+// slices, the more they can differ, so the number of slices is capped at
+// maxSlices.  Queries sharded by series are left alone, as sharding is exact.
+// The rest of the query is evaluated by an engine created with opts.
+func verticalShardingMiddleware(interval time.Duration, maxSlices, shards int, opts promql.EngineOpts, limits *validation.Overrides) QueryRangeMiddleware {
+	engine := promql.NewEngine(opts)
+	return QueryRangeMiddlewareFunc(func(next QueryRangeHandler) QueryRangeHandler {
+		return instrument("vertical_sharding").Wrap(verticalShard{
+			next:      next,
+			limits:    limits,
+			engine:    engine,
+			interval:  interval,
+			maxSlices: maxSlices,
+			shards:    shards,
+		})
+	})
+}
+
+type verticalShard struct {
+	next      QueryRangeHandler
+	limits    *validation.Overrides
+	engine    *promql.Engine
+	interval  time.Duration
+	maxSlices int
+	shards    int
+}
+
+// slicedCall is a rate() or increase() call split into slices.
+type slicedCall struct {
+	rate bool
+	rng  time.Duration
+	reqs []*QueryRangeRequest
+}
+
+func (s verticalShard) Do(ctx context.Context, r *QueryRangeRequest) (*APIResponse, error) {
+	expr, err := promql.ParseExpr(r.Query)
+	if err != nil {
+		return s.next.Do(ctx, r)
+	}
+	if _, ok := shardable(expr); ok && s.shards > 1 {
+		return s.next.Do(ctx, r)
+	}
+	if hasSubquery(expr) {
+		return s.next.Do(ctx, r)
+	}
+
+	var (
+		calls      []slicedCall
+		reqs       []*QueryRangeRequest
+		rewriteErr error
+	)
+	expr = rewriteExpr(expr, func(e promql.Expr) promql.Expr {
+		call, ok := e.(*promql.Call)
+		if !ok || (call.Func.Name != "rate" && call.Func.Name != "increase") {
+			return nil
+		}
+		sel, ok := call.Args[0].(*promql.MatrixSelector)
+		if !ok || sel.Range <= s.interval {
+			return nil
+		}
+
+		sc := slicedCall{
+			rate: call.Func.Name == "rate",
+			rng:  sel.Range,
+			reqs: s.sliceRequests(r, sel),
+		}
+		calls = append(calls, sc)
+		reqs = append(reqs, sc.reqs...)
+		e, err := promql.ParseExpr(fmt.Sprintf(sliceExpr, len(calls)-1))
+		if err != nil {
+			rewriteErr = err
+			return nil
+		}
+		return e
+	})
+	if rewriteErr != nil {
+		return nil, httpgrpc.Errorf(http.StatusInternalServerError, rewriteErr.Error())
+	}
+	if len(calls) == 0 || hasUnslicedSelector(expr) {
+		return s.next.Do(ctx, r)
+	}
+
+	queryTimingsFromContext(ctx).addShards(len(reqs))
+	reqResps, err := doRequests(ctx, s.next, reqs, s.limits)
+	if err != nil {
+		return nil, err
+	}
+	resps := make(map[*QueryRangeRequest]*APIResponse, len(reqResps))
+	for _, reqResp := range reqResps {
+		resps[reqResp.req] = reqResp.resp
+	}
+
+	var (
+		series     []storage.Series
+		sliceResps []*APIResponse
+	)
+	for i, call := range calls {
+		callResps := make([]*APIResponse, 0, len(call.reqs))
+		for _, req := range call.reqs {
+			callResps = append(callResps, resps[req])
+		}
+		sliceResps = append(sliceResps, callResps...)
+
+		combined := combineShardedResponses(promql.ItemSum, callResps)
+		if call.rate {
+			for _, stream := range combined.Data.Result {
+				for j := range stream.Samples {
+					stream.Samples[j].Value /= call.rng.Seconds()
+				}
+			}
+		}
+		series = append(series, sliceSeries(combined.Data.Result, strconv.Itoa(i), r)...)
+	}
+
+	queryable := storage.QueryableFunc(func(ctx context.Context, mint, maxt int64) (storage.Querier, error) {
+		return sliceQuerier{series: series}, nil
+	})
+	qry, err := s.engine.NewRangeQuery(queryable, expr.String(), time.Unix(0, r.Start*int64(time.Millisecond)),
+		time.Unix(0, r.End*int64(time.Millisecond)), time.Duration(r.Step)*time.Millisecond)
+	if err != nil {
+		return nil, httpgrpc.Errorf(http.StatusBadRequest, err.Error())
+	}
+	defer qry.Close()
+
+	res := qry.Exec(ctx)
+	if res.Err != nil {
+		return nil, httpgrpc.Errorf(http.StatusUnprocessableEntity, res.Err.Error())
+	}
+	m, err := res.Matrix()
+	if err != nil {
+		return nil, httpgrpc.Errorf(http.StatusInternalServerError, err.Error())
+	}
+
+	resp := &APIResponse{
+		Status: statusSuccess,
+		Data: QueryRangeResponse{
+			ResultType: matrix,
+			Result:     make([]SampleStream, 0, len(m)),
+		},
+		Warnings: mergeWarnings(sliceResps),
+	}
+	for _, s := range m {
+		samples := make([]client.Sample, 0, len(s.Points))
+		for _, p := range s.Points {
+			samples = append(samples, client.Sample{TimestampMs: p.T, Value: p.V})
+		}
+		resp.Data.Result = append(resp.Data.Result, SampleStream{
+			Labels:  client.FromLabelsToLabelAdapaters(withoutLabel(s.Metric, sliceLabel)),
+			Samples: samples,
+		})
+	}
+	return resp, nil
+}
+
+func hasSubquery(expr promql.Expr) bool {
+	found := false
+	promql.Inspect(expr, func(node promql.Node, _ []promql.Node) error {
+		if _, ok := node.(*promql.SubqueryExpr); ok {
+			found = true
+		}
+		return nil
+	})
+	return found
+}
+
+// hasUnslicedSelector returns whether the rewritten expression still has
+// selectors of its own, which the frontend has no series for.
+func hasUnslicedSelector(expr promql.Expr) bool {
+	found := false
+	promql.Inspect(expr, func(node promql.Node, _ []promql.Node) error {
+		switch n := node.(type) {
+		case *promql.VectorSelector:
+			if len(n.LabelMatchers) != 1 || n.LabelMatchers[0].Name != sliceLabel {
+				found = true
+			}
+		case *promql.MatrixSelector:
+			found = true
+		}
+		return nil
+	})
+	return found
+}
+
+// sliceRequests returns the requests for the increase over each slice of sel,
+// at most maxSlices slices of about interval each.
+func (s verticalShard) sliceRequests(r *QueryRangeRequest, sel *promql.MatrixSelector) []*QueryRangeRequest {
+	n := int64(math.Ceil(float64(sel.Range) / float64(s.interval)))
+	if s.maxSlices > 0 && n > int64(s.maxSlices) {
+		n = int64(s.maxSlices)
+	}
+
+	reqs := make([]*QueryRangeRequest, 0, n)
+	rng := int64(sel.Range / time.Millisecond)
+	for i := int64(0); i < n; i++ {
+		from, to := rng*i/n, rng*(i+1)/n
+		slice := *sel
+		slice.Range = time.Duration(to-from) * time.Millisecond
+		slice.Offset = sel.Offset + time.Duration(from)*time.Millisecond
+
+		req := r.copy()
+		req.Query = "increase(" + slice.String() + ")"
+		reqs = append(reqs, &req)
+	}
+	return reqs
+}
+
+// rewriteExpr replaces the sub-expressions of expr for which replace returns
+// an expression, outermost first, and returns the resulting expression.
+func rewriteExpr(expr promql.Expr, replace func(promql.Expr) promql.Expr) promql.Expr {
+	if e := replace(expr); e != nil {
+		return e
+	}
+	switch n := expr.(type) {
+	case *promql.AggregateExpr:
+		n.Expr = rewriteExpr(n.Expr, replace)
+		if n.Param != nil {
+			n.Param = rewriteExpr(n.Param, replace)
+		}
+	case *promql.BinaryExpr:
+		n.LHS = rewriteExpr(n.LHS, replace)
+		n.RHS = rewriteExpr(n.RHS, replace)
+	case *promql.Call:
+		for i := range n.Args {
+			n.Args[i] = rewriteExpr(n.Args[i], replace)
+		}
+	case *promql.ParenExpr:
+		n.Expr = rewriteExpr(n.Expr, replace)
+	case *promql.UnaryExpr:
+		n.Expr = rewriteExpr(n.Expr, replace)
+	case *promql.SubqueryExpr:
+		n.Expr = rewriteExpr(n.Expr, replace)
+	}
+	return expr
+}
+
+// sliceSeries returns the combined results of a sliced call as series
+// labelled with the call's id, with a sample at every step of r: those
+// missing from the results are stale markers, so the engine doesn't look
+// back to previous steps for them.
+func sliceSeries(streams []SampleStream, id string, r *QueryRangeRequest) []storage.Series {
+	series := make([]storage.Series, 0, len(streams))
+	for _, stream := range streams {
+		ls := labels.NewBuilder(client.FromLabelAdaptersToLabels(stream.Labels)).Set(sliceLabel, id).Labels()
+
+		samples := make([]client.Sample, 0, (r.End-r.Start)/r.Step+1)
+		i := 0
+		for ts := r.Start; ts <= r.End; ts += r.Step {
+			for i < len(stream.Samples) && stream.Samples[i].TimestampMs < ts {
+				i++
+			}
+			v := math.Float64frombits(value.StaleNaN)
+			if i < len(stream.Samples) && stream.Samples[i].TimestampMs == ts {
+				v = stream.Samples[i].Value
+			}
+			samples = append(samples, client.Sample{TimestampMs: ts, Value: v})
+		}
+		series = append(series, sliceSeriesEntry{labels: ls, samples: samples})
+	}
+	sort.Slice(series, func(i, j int) bool {
+		return labels.Compare(series[i].Labels(), series[j].Labels()) < 0
+	})
+	return series
+}
+
+func withoutLabel(ls labels.Labels, name string) labels.Labels {
+	return labels.NewBuilder(ls).Del(name).Labels()
+}
+
+// sliceQuerier implements storage.Querier over the series of sliced calls.
+type sliceQuerier struct {
+	series []storage.Series
+}
+
+func (q sliceQuerier) Select(_ *storage.SelectParams, matchers ...*labels.Matcher) (storage.SeriesSet, storage.Warnings, error) {
+	var selected []storage.Series
+outer:
+	for _, s := range q.series {
+		for _, m := range matchers {
+			if !m.Matches(s.Labels().Get(m.Name)) {
+				continue outer
+			}
+		}
+		selected = append(selected, s)
+	}
+	return &sliceSeriesSet{cur: -1, series: selected}, nil, nil
+}
+
+func (sliceQuerier) LabelValues(string) ([]string, error) { return nil, nil }
+func (sliceQuerier) LabelNames() ([]string, error)        { return nil, nil }
+func (sliceQuerier) Close() error                         { return nil }
+
+type sliceSeriesSet struct {
+	cur    int
+	series []storage.Series
+}
+
+func (s *sliceSeriesSet) Next() bool {
+	s.cur++
+	return s.cur < len(s.series)
+}
+
+func (s *sliceSeriesSet) At() storage.Series { return s.series[s.cur] }
+func (s *sliceSeriesSet) Err() error         { return nil }
+
+type sliceSeriesEntry struct {
+	labels  labels.Labels
+	samples []client.Sample
+}
+
+func (s sliceSeriesEntry) Labels() labels.Labels { return s.labels }
+
+func (s sliceSeriesEntry) Iterator() storage.SeriesIterator {
+	return &sliceSeriesIterator{cur: -1, samples: s.samples}
+}
+
+type sliceSeriesIterator struct {
+	cur     int
+	samples []client.Sample
+}
+
+func (it *sliceSeriesIterator) Seek(t int64) bool {
+	if it.cur < 0 {
+		it.cur = 0
+	}
+	it.cur += sort.Search(len(it.samples)-it.cur, func(n int) bool {
+		return it.samples[it.cur+n].TimestampMs >= t
+	})
+	return it.cur < len(it.samples)
+}
+
+func (it *sliceSeriesIterator) At() (int64, float64) {
+	s := it.samples[it.cur]
+	return s.TimestampMs, s.Value
+}
+
+func (it *sliceSeriesIterator) Next() bool {
+	it.cur++
+	return it.cur < len(it.samples)
+}
+
+func (it *sliceSeriesIterator) Err() error { return nil }
@@ -0,0 +1,165 @@
+package frontend
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/prometheus/prometheus/pkg/value"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+
+	client "github.com/cortexproject/cortex/pkg/ingester/client"
+)
+
+func TestSliceRequests(t *testing.T) {
+	expr, err := promql.ParseExpr(`rate(foo{bar="baz"}[3d] offset 1h)`)
+	require.NoError(t, err)
+	sel := expr.(*promql.Call).Args[0].(*promql.MatrixSelector)
+
+	queries := func(s verticalShard) []string {
+		var queries []string
+		for _, req := range s.sliceRequests(parsedRequest, sel) {
+			queries = append(queries, req.Query)
+		}
+		return queries
+	}
+	require.Equal(t, []string{
+		`increase(foo{bar="baz"}[1d] offset 1h)`,
+		`increase(foo{bar="baz"}[1d] offset 25h)`,
+		`increase(foo{bar="baz"}[1d] offset 49h)`,
+	}, queries(verticalShard{interval: day}))
+
+	// Longer slices are used rather than going over the maximum.
+	require.Equal(t, []string{
+		`increase(foo{bar="baz"}[36h] offset 1h)`,
+		`increase(foo{bar="baz"}[36h] offset 37h)`,
+	}, queries(verticalShard{interval: day, maxSlices: 2}))
+}
+
+func TestVerticalSharding(t *testing.T) {
+	series := func(name string, samples ...client.Sample) SampleStream {
+		return SampleStream{
+			Labels:  []client.LabelAdapter{{Name: "name", Value: name}},
+			Samples: samples,
+		}
+	}
+	slices := map[string][]SampleStream{
+		`increase(foo[1d])`: {
+			series("a", client.Sample{TimestampMs: 0, Value: 86400}, client.Sample{TimestampMs: 60000, Value: 86400}),
+			series("b", client.Sample{TimestampMs: 0, Value: 172800}, client.Sample{TimestampMs: 60000, Value: 172800}),
+		},
+		`increase(foo[1d] offset 1d)`: {
+			series("a", client.Sample{TimestampMs: 0, Value: 86400}, client.Sample{TimestampMs: 60000, Value: 86400}),
+			series("b", client.Sample{TimestampMs: 0, Value: 172800}),
+		},
+	}
+
+	var (
+		mtx     sync.Mutex
+		queries []string
+	)
+	downstream := QueryRangeHandlerFunc(func(_ context.Context, r *QueryRangeRequest) (*APIResponse, error) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		queries = append(queries, r.Query)
+		return &APIResponse{
+			Status: statusSuccess,
+			Data: QueryRangeResponse{
+				ResultType: matrix,
+				Result:     slices[r.Query],
+			},
+		}, nil
+	})
+	handler := verticalShardingMiddleware(day, 8, 4, promql.EngineOpts{
+		Logger:        log.NewNopLogger(),
+		MaxConcurrent: 20,
+		MaxSamples:    50e6,
+		Timeout:       time.Minute,
+	}, defaultOverrides(t)).Wrap(downstream)
+
+	for _, tc := range []struct {
+		query    string
+		queries  []string
+		expected []SampleStream
+	}{
+		{
+			query:   `quantile(0.5, rate(foo[2d]))`,
+			queries: []string{`increase(foo[1d])`, `increase(foo[1d] offset 1d)`},
+			expected: []SampleStream{{
+				Labels:  []client.LabelAdapter{},
+				Samples: []client.Sample{{TimestampMs: 0, Value: 1.5}, {TimestampMs: 60000, Value: 1}},
+			}},
+		},
+		{
+			query:   `increase(foo[2d]) > 172800`,
+			queries: []string{`increase(foo[1d])`, `increase(foo[1d] offset 1d)`},
+			expected: []SampleStream{
+				series("b", client.Sample{TimestampMs: 0, Value: 345600}),
+			},
+		},
+		{
+			// The sliced calls' results match each other.
+			query:   `rate(foo[2d]) / increase(foo[2d])`,
+			queries: []string{`increase(foo[1d])`, `increase(foo[1d] offset 1d)`, `increase(foo[1d])`, `increase(foo[1d] offset 1d)`},
+			expected: []SampleStream{
+				series("a", client.Sample{TimestampMs: 0, Value: 1.0 / 172800}, client.Sample{TimestampMs: 60000, Value: 1.0 / 172800}),
+				series("b", client.Sample{TimestampMs: 0, Value: 1.0 / 172800}, client.Sample{TimestampMs: 60000, Value: 1.0 / 172800}),
+			},
+		},
+		{
+			// The frontend has no series for bar.
+			query:   `sum(rate(foo[2d])) / bar`,
+			queries: []string{`sum(rate(foo[2d])) / bar`},
+		},
+		{
+			query:   `quantile(0.5, rate(foo[1d]))`,
+			queries: []string{`quantile(0.5, rate(foo[1d]))`},
+		},
+		{
+			query:   `sum(rate(foo[2d]))`,
+			queries: []string{`sum(rate(foo[2d]))`},
+		},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			queries = nil
+			req := &QueryRangeRequest{
+				Path:  "/api/v1/query_range",
+				Start: 0,
+				End:   60000,
+				Step:  60000,
+				Query: tc.query,
+			}
+			resp, err := handler.Do(user.InjectOrgID(context.Background(), "1"), req)
+			require.NoError(t, err)
+			require.ElementsMatch(t, tc.queries, queries)
+			if tc.expected != nil {
+				require.Equal(t, tc.expected, resp.Data.Result)
+			}
+		})
+	}
+}
+
+func TestSliceSeriesIterator(t *testing.T) {
+	req := &QueryRangeRequest{Start: 0, End: 180000, Step: 60000}
+	series := sliceSeries([]SampleStream{{
+		Samples: []client.Sample{{TimestampMs: 60000, Value: 1}},
+	}}, "0", req)
+	require.Len(t, series, 1)
+	require.Equal(t, "0", series[0].Labels().Get(sliceLabel))
+
+	// Steps without results are stale.
+	it := series[0].Iterator()
+	require.True(t, it.Seek(60000))
+	ts, v := it.At()
+	require.Equal(t, int64(60000), ts)
+	require.Equal(t, 1.0, v)
+	require.True(t, it.Next())
+	_, v = it.At()
+	require.True(t, value.IsStaleNaN(v))
+	require.True(t, it.Next())
+	require.False(t, it.Next())
+}
@@ -5,12 +5,17 @@ import (
 	"flag"
 	"fmt"
 	"net/http"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/naming"
 
 	"github.com/cortexproject/cortex/pkg/util"
@@ -25,22 +30,42 @@ var (
 		MinBackoff: 50 * time.Millisecond,
 		MaxBackoff: 1 * time.Second,
 	}
+
+	workerConcurrency = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "cortex",
+		Name:      "querier_worker_concurrency",
+		Help:      "Number of requests the querier processes at once from each frontend.",
+	}, []string{"frontend"})
+	workerInflightRequests = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "cortex",
+		Name:      "querier_worker_inflight_requests",
+		Help:      "Number of requests from each frontend being processed by the querier.",
+	}, []string{"frontend"})
 )
 
 // WorkerConfig is config for a worker.
 type WorkerConfig struct {
-	Address           string
-	Parallelism       int
-	DNSLookupDuration time.Duration
+	Address             string
+	Parallelism         int
+	MatchMaxConcurrency bool
+	DNSLookupDuration   time.Duration
+	ResponseChunkSize   int
+	ProgressInterval    time.Duration
+
+	// The querier's -querier.max-concurrent, set by the querier's module.
+	MaxConcurrentRequests int `yaml:"-"`
 
 	GRPCClientConfig grpcclient.Config `yaml:"grpc_client_config"`
 }
 
 // RegisterFlags adds the flags required to config this to the given FlagSet.
 func (cfg *WorkerConfig) RegisterFlags(f *flag.FlagSet) {
-	f.StringVar(&cfg.Address, "querier.frontend-address", "", "Address of query frontend service.")
-	f.IntVar(&cfg.Parallelism, "querier.worker-parallelism", 10, "Number of simultaneous queries to process.")
+	f.StringVar(&cfg.Address, "querier.frontend-address", "", "Address of query frontend service, as host:port for DNS A lookups, or dnssrv+name for DNS SRV lookups.")
+	f.IntVar(&cfg.Parallelism, "querier.worker-parallelism", 10, "Number of simultaneous queries to process per frontend.")
+	f.BoolVar(&cfg.MatchMaxConcurrency, "querier.worker-match-max-concurrent", false, "Spread -querier.max-concurrent simultaneous queries across all of the frontends discovered, instead of processing -querier.worker-parallelism per frontend.")
 	f.DurationVar(&cfg.DNSLookupDuration, "querier.dns-lookup-period", 10*time.Second, "How often to query DNS.")
+	f.IntVar(&cfg.ResponseChunkSize, "querier.frontend-response-chunk-size", 1<<20, "Size of the chunks responses are split into when sent to frontends supporting chunked responses.")
+	f.DurationVar(&cfg.ProgressInterval, "querier.frontend-progress-interval", 10*time.Second, "How often to report progress on the request being processed to frontends which support pulling requests. 0 to disable.")
 
 	cfg.GRPCClientConfig.RegisterFlags("querier.frontend-client", f)
 }
@@ -59,6 +84,9 @@ type worker struct {
 	cancel  context.CancelFunc
 	watcher naming.Watcher
 	wg      sync.WaitGroup
+
+	// Only used by the DNS loop.
+	managers map[string]*frontendManager
 }
 
 type noopWorker struct {
@@ -73,16 +101,29 @@ func NewWorker(cfg WorkerConfig, server *server.Server, log log.Logger) (Worker,
 		return noopWorker{}, nil
 	}
 
-	resolver, err := naming.NewDNSResolverWithFreq(cfg.DNSLookupDuration)
-	if err != nil {
+	if err := cfg.GRPCClientConfig.Validate(); err != nil {
 		return nil, err
 	}
 
-	watcher, err := resolver.Resolve(cfg.Address)
-	if err != nil {
-		return nil, err
+	var watcher naming.Watcher
+	if strings.HasPrefix(cfg.Address, srvPrefix) {
+		watcher = newSRVWatcher(strings.TrimPrefix(cfg.Address, srvPrefix), cfg.DNSLookupDuration, log)
+	} else {
+		resolver, err := naming.NewDNSResolverWithFreq(cfg.DNSLookupDuration)
+		if err != nil {
+			return nil, err
+		}
+
+		watcher, err = resolver.Resolve(cfg.Address)
+		if err != nil {
+			return nil, err
+		}
 	}
 
+	return newWorker(cfg, server, log, watcher), nil
+}
+
+func newWorker(cfg WorkerConfig, server *server.Server, log log.Logger, watcher naming.Watcher) *worker {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	w := &worker{
@@ -90,13 +131,14 @@ func NewWorker(cfg WorkerConfig, server *server.Server, log log.Logger) (Worker,
 		log:    log,
 		server: server,
 
-		ctx:     ctx,
-		cancel:  cancel,
-		watcher: watcher,
+		ctx:      ctx,
+		cancel:   cancel,
+		watcher:  watcher,
+		managers: map[string]*frontendManager{},
 	}
 	w.wg.Add(1)
 	go w.watchDNSLoop()
-	return w, nil
+	return w
 }
 
 // Stop the worker.
@@ -110,10 +152,9 @@ func (w *worker) Stop() {
 func (w *worker) watchDNSLoop() {
 	defer w.wg.Done()
 
-	cancels := map[string]context.CancelFunc{}
 	defer func() {
-		for _, cancel := range cancels {
-			cancel()
+		for _, m := range w.managers {
+			m.stop()
 		}
 	}()
 
@@ -127,54 +168,176 @@ func (w *worker) watchDNSLoop() {
 		for _, update := range updates {
 			switch update.Op {
 			case naming.Add:
+				if _, ok := w.managers[update.Addr]; ok {
+					continue
+				}
 				level.Debug(w.log).Log("msg", "adding connection", "addr", update.Addr)
-				ctx, cancel := context.WithCancel(w.ctx)
-				cancels[update.Addr] = cancel
-				w.runMany(ctx, update.Addr)
+				m, err := w.newFrontendManager(update.Addr)
+				if err != nil {
+					level.Error(w.log).Log("msg", "error connecting", "addr", update.Addr, "err", err)
+					continue
+				}
+				w.managers[update.Addr] = m
 
 			case naming.Delete:
 				level.Debug(w.log).Log("msg", "removing connection", "addr", update.Addr)
-				if cancel, ok := cancels[update.Addr]; ok {
-					cancel()
+				if m, ok := w.managers[update.Addr]; ok {
+					m.stop()
+					delete(w.managers, update.Addr)
 				}
 
 			default:
 				panic("unknown op")
 			}
 		}
+
+		w.resetConcurrency()
 	}
 }
 
-// runMany starts N runOne loops for a given address.
-func (w *worker) runMany(ctx context.Context, address string) {
-	client, err := w.connect(address)
+// resetConcurrency sets the number of requests processed at once from each
+// frontend: either -querier.worker-parallelism each, or -querier.max-concurrent
+// spread as evenly as possible across them, with at least one each.
+func (w *worker) resetConcurrency() {
+	addrs := make([]string, 0, len(w.managers))
+	for addr := range w.managers {
+		addrs = append(addrs, addr)
+	}
+	sort.Strings(addrs)
+
+	if w.cfg.MatchMaxConcurrency && len(addrs) > w.cfg.MaxConcurrentRequests {
+		level.Warn(w.log).Log("msg", "more frontends than -querier.max-concurrent, processing one request at a time from each", "frontends", len(addrs), "max_concurrent", w.cfg.MaxConcurrentRequests)
+	}
+	for i, addr := range addrs {
+		concurrency := w.cfg.Parallelism
+		if w.cfg.MatchMaxConcurrency {
+			concurrency = w.cfg.MaxConcurrentRequests / len(addrs)
+			if i < w.cfg.MaxConcurrentRequests%len(addrs) {
+				concurrency++
+			}
+			if concurrency < 1 {
+				concurrency = 1
+			}
+		}
+		w.managers[addr].setConcurrency(concurrency)
+	}
+}
+
+// frontendManager runs the loops processing requests from one frontend.
+type frontendManager struct {
+	w      *worker
+	addr   string
+	conn   *grpc.ClientConn
+	client FrontendClient
+	loops  []*processLoop
+	wg     sync.WaitGroup
+}
+
+// processLoop is the state of one runOne loop, so that it can be stopped
+// between requests: stopping a loop waiting for a request cancels its stream
+// straight away, but a loop processing one only once it has sent the
+// response.
+type processLoop struct {
+	mtx      sync.Mutex
+	cancel   context.CancelFunc
+	busy     bool
+	stopping bool
+}
+
+func (l *processLoop) stop() {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	l.stopping = true
+	if !l.busy {
+		l.cancel()
+	}
+}
+
+// begin marks the loop as processing the request it received.  It returns
+// false if the loop was stopped, and its stream cancelled, meanwhile.
+func (l *processLoop) begin() bool {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	l.busy = !l.stopping
+	return l.busy
+}
+
+// end marks the loop as waiting for the next request, and cancels its stream
+// if it was stopped while processing the last one.  It returns false if so.
+func (l *processLoop) end() bool {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	l.busy = false
+	if l.stopping {
+		l.cancel()
+	}
+	return !l.stopping
+}
+
+func (w *worker) newFrontendManager(addr string) (*frontendManager, error) {
+	conn, err := w.connect(addr)
 	if err != nil {
-		level.Error(w.log).Log("msg", "error connecting", "addr", address, "err", err)
-		return
+		return nil, err
 	}
+	return &frontendManager{
+		w:      w,
+		addr:   addr,
+		conn:   conn,
+		client: NewFrontendClient(conn),
+	}, nil
+}
 
-	w.wg.Add(w.cfg.Parallelism)
-	for i := 0; i < w.cfg.Parallelism; i++ {
-		go w.runOne(ctx, client)
+// setConcurrency starts or stops runOne loops until there are n of them.
+// Loops stopped finish the request they are processing, if any, first.
+func (m *frontendManager) setConcurrency(n int) {
+	for len(m.loops) < n {
+		ctx, cancel := context.WithCancel(m.w.ctx)
+		l := &processLoop{cancel: cancel}
+		m.loops = append(m.loops, l)
+		m.wg.Add(1)
+		go m.runOne(ctx, l)
 	}
+	for len(m.loops) > n {
+		m.loops[len(m.loops)-1].stop()
+		m.loops = m.loops[:len(m.loops)-1]
+	}
+	workerConcurrency.WithLabelValues(m.addr).Set(float64(n))
+}
+
+// stop stops all of the loops, and closes the connection once they have.
+func (m *frontendManager) stop() {
+	m.setConcurrency(0)
+	workerConcurrency.DeleteLabelValues(m.addr)
+
+	m.w.wg.Add(1)
+	go func() {
+		defer m.w.wg.Done()
+		m.wg.Wait()
+		if err := m.conn.Close(); err != nil {
+			level.Warn(m.w.log).Log("msg", "error closing connection", "addr", m.addr, "err", err)
+		}
+		workerInflightRequests.DeleteLabelValues(m.addr)
+	}()
 }
 
 // runOne loops, trying to establish a stream to the frontend to begin
 // request processing.
-func (w *worker) runOne(ctx context.Context, client FrontendClient) {
-	defer w.wg.Done()
+func (m *frontendManager) runOne(ctx context.Context, l *processLoop) {
+	defer m.wg.Done()
 
+	// Only the stream carries the metadata, not the requests processed.
+	streamCtx := metadata.AppendToOutgoingContext(ctx, pullRequestsKey, "true")
 	backoff := util.NewBackoff(ctx, backoffConfig)
 	for backoff.Ongoing() {
-		c, err := client.Process(ctx)
+		c, err := m.client.Process(streamCtx)
 		if err != nil {
-			level.Error(w.log).Log("msg", "error contacting frontend", "err", err)
+			level.Error(m.w.log).Log("msg", "error contacting frontend", "addr", m.addr, "err", err)
 			backoff.Wait()
 			continue
 		}
 
-		if err := w.process(ctx, c); err != nil {
-			level.Error(w.log).Log("msg", "error processing requests", "err", err)
+		if err := m.w.process(ctx, m.addr, c, l); err != nil && ctx.Err() == nil {
+			level.Error(m.w.log).Log("msg", "error processing requests", "addr", m.addr, "err", err)
 			backoff.Wait()
 			continue
 		}
@@ -183,51 +346,146 @@ func (w *worker) runOne(ctx context.Context, client FrontendClient) {
 	}
 }
 
-// process loops processing requests on an established stream.
-func (w *worker) process(ctx context.Context, c Frontend_ProcessClient) error {
+// process loops processing requests on an established stream, until the
+// loop is stopped.  Frontends which support it are asked for each request
+// once the loop is ready for it, and told of the progress on it; others
+// send their next request straight away, which is when their header, with
+// which they would say they support it, arrives.
+func (w *worker) process(ctx context.Context, addr string, c Frontend_ProcessClient, l *processLoop) error {
+	header, err := c.Header()
+	if err != nil {
+		return err
+	}
+	pull := pullsRequests(header, true)
+
+	inflight := workerInflightRequests.WithLabelValues(addr)
 	for {
+		if pull {
+			if err := c.Send(&ProcessResponse{Ready: true}); err != nil {
+				return err
+			}
+		}
 		request, err := c.Recv()
 		if err != nil {
 			return err
 		}
+		// A request received just as the loop is stopped can't be answered
+		// on the cancelled stream; the frontend gets the stream's error.
+		if !l.begin() {
+			return ctx.Err()
+		}
+		if err := w.processRequest(ctx, inflight, c, request, pull); err != nil {
+			l.end()
+			return err
+		}
+		if !l.end() {
+			return nil
+		}
+	}
+}
 
-		response, err := w.server.Handle(ctx, request.HttpRequest)
-		if err != nil {
-			var ok bool
-			response, ok = httpgrpc.HTTPResponseFromError(err)
-			if !ok {
-				response = &httpgrpc.HTTPResponse{
-					Code: http.StatusInternalServerError,
-					Body: []byte(err.Error()),
-				}
+// processRequest handles the request and sends its response, reporting
+// progress meanwhile to frontends which pull requests.
+func (w *worker) processRequest(ctx context.Context, inflight prometheus.Gauge, c Frontend_ProcessClient, request *ProcessRequest, pull bool) error {
+	inflight.Inc()
+	stopProgress := func() {}
+	if pull && w.cfg.ProgressInterval > 0 {
+		stopProgress = reportProgress(c, w.cfg.ProgressInterval)
+	}
+	response, err := w.server.Handle(ctx, request.HttpRequest)
+	stopProgress()
+	inflight.Dec()
+	if err != nil {
+		var ok bool
+		response, ok = httpgrpc.HTTPResponseFromError(err)
+		if !ok {
+			response = &httpgrpc.HTTPResponse{
+				Code: http.StatusInternalServerError,
+				Body: []byte(err.Error()),
 			}
 		}
+	}
 
-		if len(response.Body) >= w.cfg.GRPCClientConfig.MaxSendMsgSize {
-			errMsg := fmt.Sprintf("the response is larger than the max (%d vs %d)", len(response.Body), w.cfg.GRPCClientConfig.MaxSendMsgSize)
+	if request.AcceptChunkedResponse && w.cfg.ResponseChunkSize > 0 {
+		return sendChunked(c, response, w.cfg.ResponseChunkSize)
+	}
 
-			// This makes sure the request is not retried, else a 500 is sent and we retry the large query again.
-			response = &httpgrpc.HTTPResponse{
-				Code: http.StatusRequestEntityTooLarge,
-				Body: []byte(errMsg),
+	if len(response.Body) >= w.cfg.GRPCClientConfig.MaxSendMsgSize {
+		errMsg := fmt.Sprintf("the response is larger than the max (%d vs %d)", len(response.Body), w.cfg.GRPCClientConfig.MaxSendMsgSize)
+
+		// This makes sure the request is not retried, else a 500 is sent and we retry the large query again.
+		response = &httpgrpc.HTTPResponse{
+			Code: http.StatusRequestEntityTooLarge,
+			Body: []byte(errMsg),
+		}
+		level.Error(w.log).Log("msg", "error processing query", "err", errMsg)
+	}
+
+	return c.Send(&ProcessResponse{
+		HttpResponse: response,
+	})
+}
+
+// reportProgress sends a progress message every interval until stopped,
+// which waits for a message being sent to be, so that the stream is only
+// sent to by one goroutine at a time.
+func reportProgress(c Frontend_ProcessClient, interval time.Duration) func() {
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := c.Send(&ProcessResponse{Progress: true}); err != nil {
+					return
+				}
+			case <-done:
+				return
 			}
-			level.Error(w.log).Log("msg", "error processing query", "err", errMsg)
+		}
+	}()
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
+
+// sendChunked sends the response as a series of ProcessResponses, each with a
+// body of at most chunkSize bytes.  Only the first carries the status code
+// and headers.
+func sendChunked(c Frontend_ProcessClient, response *httpgrpc.HTTPResponse, chunkSize int) error {
+	body := response.Body
+	first := true
+	for first || len(body) > 0 {
+		chunk := body
+		if len(chunk) > chunkSize {
+			chunk = chunk[:chunkSize]
+		}
+		body = body[len(chunk):]
+
+		part := &httpgrpc.HTTPResponse{Body: chunk}
+		if first {
+			part.Code = response.Code
+			part.Headers = response.Headers
+			first = false
 		}
 
 		if err := c.Send(&ProcessResponse{
-			HttpResponse: response,
+			HttpResponse: part,
+			More:         len(body) > 0,
 		}); err != nil {
 			return err
 		}
 	}
+	return nil
 }
 
-func (w *worker) connect(address string) (FrontendClient, error) {
+func (w *worker) connect(address string) (*grpc.ClientConn, error) {
 	opts := []grpc.DialOption{grpc.WithInsecure()}
 	opts = append(opts, w.cfg.GRPCClientConfig.DialOption([]grpc.UnaryClientInterceptor{middleware.ClientUserHeaderInterceptor}, nil)...)
-	conn, err := grpc.Dial(address, opts...)
-	if err != nil {
-		return nil, err
-	}
-	return NewFrontendClient(conn), nil
+	return grpc.Dial(address, opts...)
 }
@@ -0,0 +1,261 @@
+package frontend
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/httpgrpc"
+	"github.com/weaveworks/common/httpgrpc/server"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/naming"
+
+	"github.com/cortexproject/cortex/pkg/util/grpcclient"
+)
+
+type mockWatcher struct {
+	updates chan []*naming.Update
+	closed  chan struct{}
+}
+
+func newMockWatcher() *mockWatcher {
+	return &mockWatcher{
+		updates: make(chan []*naming.Update),
+		closed:  make(chan struct{}),
+	}
+}
+
+func (w *mockWatcher) Next() ([]*naming.Update, error) {
+	select {
+	case updates := <-w.updates:
+		return updates, nil
+	case <-w.closed:
+		return nil, errors.New("closed")
+	}
+}
+
+func (w *mockWatcher) Close() {
+	close(w.closed)
+}
+
+func TestWorkerConcurrency(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		cfg      WorkerConfig
+		expected [][]int
+	}{
+		{
+			name:     "parallelism per frontend",
+			cfg:      WorkerConfig{Parallelism: 2},
+			expected: [][]int{{2}, {2, 2, 2}, {2, 2}},
+		},
+		{
+			name:     "max concurrent spread across frontends",
+			cfg:      WorkerConfig{Parallelism: 2, MatchMaxConcurrency: true, MaxConcurrentRequests: 5},
+			expected: [][]int{{5}, {2, 2, 1}, {3, 2}},
+		},
+		{
+			name:     "at least one per frontend",
+			cfg:      WorkerConfig{MatchMaxConcurrency: true, MaxConcurrentRequests: 2},
+			expected: [][]int{{2}, {1, 1, 1}, {1, 1}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			watcher := newMockWatcher()
+			w := newWorker(tc.cfg, nil, log.NewNopLogger(), watcher)
+			defer w.Stop()
+
+			for i, updates := range [][]*naming.Update{
+				{{Op: naming.Add, Addr: "frontend-1:9095"}},
+				{{Op: naming.Add, Addr: "frontend-2:9095"}, {Op: naming.Add, Addr: "frontend-3:9095"}},
+				{{Op: naming.Delete, Addr: "frontend-2:9095"}},
+			} {
+				watcher.updates <- updates
+				// The loop only takes the next update once it has applied this
+				// one, and an empty one changes nothing.
+				watcher.updates <- nil
+				require.Equal(t, tc.expected[i], concurrencies(w))
+			}
+		})
+	}
+}
+
+// concurrencies returns the number of loops of each frontend, in address
+// order.
+func concurrencies(w *worker) []int {
+	var addrs []string
+	for addr := range w.managers {
+		addrs = append(addrs, addr)
+	}
+	sort.Strings(addrs)
+	var result []int
+	for _, addr := range addrs {
+		result = append(result, len(w.managers[addr].loops))
+	}
+	return result
+}
+
+// mockProcessClient is a stream from a frontend, failing like a gRPC stream
+// once its context is cancelled.
+type mockProcessClient struct {
+	grpc.ClientStream
+	ctx       context.Context
+	header    metadata.MD
+	requests  chan *ProcessRequest
+	responses chan *ProcessResponse
+}
+
+func (c *mockProcessClient) Header() (metadata.MD, error) {
+	return c.header, nil
+}
+
+func (c *mockProcessClient) Recv() (*ProcessRequest, error) {
+	select {
+	case r := <-c.requests:
+		return r, nil
+	case <-c.ctx.Done():
+		return nil, c.ctx.Err()
+	}
+}
+
+func (c *mockProcessClient) Send(r *ProcessResponse) error {
+	if err := c.ctx.Err(); err != nil {
+		return err
+	}
+	c.responses <- r
+	return nil
+}
+
+func TestWorkerStopBetweenRequests(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+		w.WriteHeader(http.StatusOK)
+	})
+	w := &worker{
+		cfg:    WorkerConfig{GRPCClientConfig: grpcclient.Config{MaxSendMsgSize: 1024}},
+		log:    log.NewNopLogger(),
+		server: server.NewServer(handler),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	l := &processLoop{cancel: cancel}
+	c := &mockProcessClient{
+		ctx:       ctx,
+		requests:  make(chan *ProcessRequest),
+		responses: make(chan *ProcessResponse, 1),
+	}
+	done := make(chan error)
+	go func() {
+		done <- w.process(ctx, "frontend", c, l)
+	}()
+
+	// A loop stopped while processing a request still sends its response,
+	// and only then stops.
+	c.requests <- &ProcessRequest{HttpRequest: &httpgrpc.HTTPRequest{Method: "GET", Url: "/"}}
+	<-started
+	l.stop()
+	require.NoError(t, ctx.Err())
+	close(release)
+	require.NoError(t, <-done)
+	resp := <-c.responses
+	require.Equal(t, int32(http.StatusOK), resp.HttpResponse.Code)
+	require.Error(t, ctx.Err())
+
+	// A loop waiting for a request stops straight away.
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	l = &processLoop{cancel: cancel}
+	c.ctx = ctx
+	go func() {
+		done <- w.process(ctx, "frontend", c, l)
+	}()
+	l.stop()
+	require.Equal(t, context.Canceled, <-done)
+}
+
+func TestWorkerPullsRequests(t *testing.T) {
+	release := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusOK)
+	})
+	w := &worker{
+		cfg: WorkerConfig{
+			ProgressInterval: time.Millisecond,
+			GRPCClientConfig: grpcclient.Config{MaxSendMsgSize: 1024},
+		},
+		log:    log.NewNopLogger(),
+		server: server.NewServer(handler),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c := &mockProcessClient{
+		ctx:       ctx,
+		header:    metadata.Pairs(pullRequestsKey, "true"),
+		requests:  make(chan *ProcessRequest),
+		responses: make(chan *ProcessResponse),
+	}
+	go w.process(ctx, "frontend", c, &processLoop{cancel: cancel})
+
+	// The worker asks for each request, and reports progress on it until
+	// it sends the response.
+	require.True(t, (<-c.responses).Ready)
+	c.requests <- &ProcessRequest{HttpRequest: &httpgrpc.HTTPRequest{Method: "GET", Url: "/"}}
+	require.True(t, (<-c.responses).Progress)
+	close(release)
+	resp := <-c.responses
+	for resp.Progress {
+		resp = <-c.responses
+	}
+	require.Equal(t, int32(http.StatusOK), resp.HttpResponse.Code)
+	require.True(t, (<-c.responses).Ready)
+}
+
+func TestSRVWatcher(t *testing.T) {
+	results := make(chan []*net.SRV, 3)
+	results <- []*net.SRV{{Target: "frontend-1.", Port: 9095}, {Target: "frontend-2.", Port: 9095}}
+	results <- nil
+	results <- []*net.SRV{{Target: "frontend-2.", Port: 9095}}
+
+	w := newSRVWatcher("_grpc._tcp.frontend", time.Millisecond, log.NewNopLogger())
+	w.lookup = func(ctx context.Context, name string) ([]*net.SRV, error) {
+		require.Equal(t, "_grpc._tcp.frontend", name)
+		select {
+		case srvs := <-results:
+			if srvs == nil {
+				return nil, errors.New("lookup failed")
+			}
+			return srvs, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	updates, err := w.Next()
+	require.NoError(t, err)
+	sort.Slice(updates, func(i, j int) bool { return updates[i].Addr < updates[j].Addr })
+	require.Equal(t, []*naming.Update{
+		{Op: naming.Add, Addr: "frontend-1.:9095"},
+		{Op: naming.Add, Addr: "frontend-2.:9095"},
+	}, updates)
+
+	// Failed lookups don't remove any frontends.
+	updates, err = w.Next()
+	require.NoError(t, err)
+	require.Equal(t, []*naming.Update{{Op: naming.Delete, Addr: "frontend-1.:9095"}}, updates)
+
+	w.Close()
+	_, err = w.Next()
+	require.Error(t, err)
+}
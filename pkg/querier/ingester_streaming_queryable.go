@@ -3,6 +3,7 @@ package querier
 import (
 	"context"
 	"sort"
+	"time"
 
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/pkg/labels"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/cortexproject/cortex/pkg/chunk"
 	"github.com/cortexproject/cortex/pkg/ingester/client"
+	"github.com/cortexproject/cortex/pkg/querier/stats"
 	"github.com/cortexproject/cortex/pkg/util/chunkcompat"
 	"github.com/weaveworks/common/user"
 )
@@ -47,10 +49,13 @@ func (i ingesterQueryable) Get(ctx context.Context, from, through model.Time, ma
 		return nil, promql.ErrStorage{Err: err}
 	}
 
+	start := time.Now()
 	results, err := i.distributor.QueryStream(ctx, from, through, matchers...)
 	if err != nil {
 		return nil, promql.ErrStorage{Err: err}
 	}
+	s := stats.FromContext(ctx)
+	s.AddIngesterTime(time.Since(start))
 
 	chunks := make([]chunk.Chunk, 0, len(results))
 	for _, result := range results {
@@ -64,6 +69,9 @@ func (i ingesterQueryable) Get(ctx context.Context, from, through model.Time, ma
 		if err != nil {
 			return nil, promql.ErrStorage{Err: err}
 		}
+		s.AddIngesterSeries(1)
+		s.AddChunks(len(cs))
+		s.AddChunkBytes(chunksSize(cs))
 		chunks = append(chunks, cs...)
 	}
 
@@ -87,10 +95,13 @@ func (q *ingesterStreamingQuerier) Select(sp *storage.SelectParams, matchers ...
 		maxt = sp.End
 	}
 
+	start := time.Now()
 	results, err := q.distributor.QueryStream(q.ctx, model.Time(mint), model.Time(maxt), matchers...)
 	if err != nil {
 		return nil, nil, promql.ErrStorage{Err: err}
 	}
+	s := stats.FromContext(q.ctx)
+	s.AddIngesterTime(time.Since(start))
 
 	serieses := make([]storage.Series, 0, len(results))
 	for _, result := range results {
@@ -103,12 +114,15 @@ func (q *ingesterStreamingQuerier) Select(sp *storage.SelectParams, matchers ...
 		if err != nil {
 			return nil, nil, promql.ErrStorage{Err: err}
 		}
+		s.AddIngesterSeries(1)
+		s.AddChunks(len(chunks))
+		s.AddChunkBytes(chunksSize(chunks))
 
 		ls := client.FromLabelAdaptersToLabels(result.Labels)
 		sort.Sort(ls)
 		series := &chunkSeries{
 			labels:            ls,
-			chunks:            chunks,
+			chunks:            dedupeChunks(chunks),
 			chunkIteratorFunc: q.chunkIteratorFunc,
 		}
 		serieses = append(serieses, series)
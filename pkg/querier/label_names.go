@@ -0,0 +1,139 @@
+package querier
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/pkg/timestamp"
+)
+
+// labelNamesConcurrency is the number of metrics whose label names are read
+// from the chunk store at once.
+const labelNamesConcurrency = 16
+
+// LabelNamesStore is the part of the chunk store label names are read from.
+type LabelNamesStore interface {
+	MetricNames(ctx context.Context, from, through model.Time) ([]string, error)
+	LabelNamesForMetricName(ctx context.Context, from, through model.Time, metricName string) ([]string, error)
+}
+
+// LabelNamesHandler answers label names requests with a start time with the
+// label names of the series in the ingesters and, within the requested time
+// range, those of the chunk store, so that the names of flushed series are
+// listed too.  The chunk store's index has the label names of each metric's
+// series, so they are looked up for each of the metrics the ingesters have
+// and the index has in the time range.  Only the v11 schema indexes the
+// metric names, the labels of metrics of older schemas are only listed while
+// the ingesters have some of their series.  All other requests, including
+// label names requests without a start time, are passed to next, which only
+// asks the ingesters.
+func LabelNamesHandler(distributor Distributor, store LabelNamesStore, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/labels") || r.FormValue("start") == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		start, err := parseQueryTime(r.FormValue("start"))
+		if err != nil {
+			writeError(w, "bad_data", http.StatusBadRequest, err)
+			return
+		}
+		end := time.Now()
+		if s := r.FormValue("end"); s != "" {
+			if end, err = parseQueryTime(s); err != nil {
+				writeError(w, "bad_data", http.StatusBadRequest, err)
+				return
+			}
+		}
+
+		ctx := r.Context()
+		names, err := distributor.LabelNames(ctx)
+		if err != nil {
+			writeQueryError(w, err)
+			return
+		}
+		metricNames, err := distributor.LabelValuesForLabelName(ctx, model.MetricNameLabel)
+		if err != nil {
+			writeQueryError(w, err)
+			return
+		}
+		from, through := model.Time(timestamp.FromTime(start)), model.Time(timestamp.FromTime(end))
+		storeMetricNames, err := store.MetricNames(ctx, from, through)
+		if err != nil {
+			writeQueryError(w, err)
+			return
+		}
+
+		storeNames, err := labelNamesFromStore(ctx, store, from, through, unique(append(metricNames, storeMetricNames...)))
+		if err != nil {
+			writeQueryError(w, err)
+			return
+		}
+
+		result := unique(append(names, storeNames...))
+		sort.Strings(result)
+		writeSuccess(w, r, result)
+	})
+}
+
+// unique returns the distinct strings of ss, in the order first seen.
+func unique(ss []string) []string {
+	seen := map[string]struct{}{}
+	result := []string{}
+	for _, s := range ss {
+		if _, ok := seen[s]; !ok {
+			seen[s] = struct{}{}
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// labelNamesFromStore returns the label names of the metrics' series in the
+// chunk store, looking up labelNamesConcurrency metrics at a time.
+func labelNamesFromStore(ctx context.Context, store LabelNamesStore, from, through model.Time, metricNames []string) ([]string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		names []string
+		err   error
+	}
+	// There's room for every result, so the lookups still running when one
+	// fails don't block once this returns.
+	queue := make(chan string)
+	results := make(chan result, len(metricNames))
+	for i := 0; i < labelNamesConcurrency && i < len(metricNames); i++ {
+		go func() {
+			for metricName := range queue {
+				names, err := store.LabelNamesForMetricName(ctx, from, through, metricName)
+				results <- result{names, err}
+			}
+		}()
+	}
+	go func() {
+		defer close(queue)
+		for _, metricName := range metricNames {
+			select {
+			case queue <- metricName:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var names []string
+	for range metricNames {
+		r := <-results
+		if r.err != nil {
+			return nil, r.err
+		}
+		names = append(names, r.names...)
+	}
+	return names, nil
+}
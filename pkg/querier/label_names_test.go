@@ -0,0 +1,154 @@
+package querier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+
+	"github.com/cortexproject/cortex/pkg/util/test"
+)
+
+type labelNamesDistributor struct {
+	mockDistributor
+	names       []string
+	metricNames []string
+}
+
+func (d *labelNamesDistributor) LabelNames(context.Context) ([]string, error) {
+	return d.names, nil
+}
+
+func (d *labelNamesDistributor) LabelValuesForLabelName(_ context.Context, name model.LabelName) ([]string, error) {
+	if name != model.MetricNameLabel {
+		return nil, nil
+	}
+	return d.metricNames, nil
+}
+
+type mockLabelNamesStore map[string][]string
+
+func (s mockLabelNamesStore) MetricNames(_ context.Context, from, through model.Time) ([]string, error) {
+	if through < 1000 {
+		return nil, nil
+	}
+	var result []string
+	for metricName := range s {
+		result = append(result, metricName)
+	}
+	return result, nil
+}
+
+func (s mockLabelNamesStore) LabelNamesForMetricName(_ context.Context, from, through model.Time, metricName string) ([]string, error) {
+	if through < 1000 {
+		return nil, nil
+	}
+	return s[metricName], nil
+}
+
+func TestLabelNamesHandler(t *testing.T) {
+	distributor := &labelNamesDistributor{
+		names:       []string{model.MetricNameLabel, "cluster"},
+		metricNames: []string{"up", "down"},
+	}
+	store := mockLabelNamesStore{
+		"up":   {model.MetricNameLabel, "cluster", "namespace"},
+		"down": {model.MetricNameLabel, "job"},
+		// Only in the chunk store.
+		"flushed": {model.MetricNameLabel, "instance"},
+	}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("next"))
+	})
+	handler := LabelNamesHandler(distributor, store, next)
+
+	for _, tc := range []struct {
+		url      string
+		code     int
+		expected string
+	}{
+		{
+			url:      `/api/v1/labels?start=0&end=1`,
+			code:     http.StatusOK,
+			expected: `{"data":["__name__","cluster","instance","job","namespace"],"status":"success"}`,
+		},
+		{
+			url:      `/api/v1/labels?start=0&end=0.5`,
+			code:     http.StatusOK,
+			expected: `{"data":["__name__","cluster"],"status":"success"}`,
+		},
+		{
+			url:      `/api/v1/labels`,
+			code:     http.StatusOK,
+			expected: `next`,
+		},
+		{
+			url:      `/api/v1/label/job/values?start=0`,
+			code:     http.StatusOK,
+			expected: `next`,
+		},
+		{
+			url:  `/api/v1/labels?start=x`,
+			code: http.StatusBadRequest,
+		},
+	} {
+		t.Run(tc.url, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tc.url, nil)
+			req = req.WithContext(user.InjectOrgID(context.Background(), "1"))
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+			require.Equal(t, tc.code, recorder.Code)
+			if tc.expected != "" {
+				require.Equal(t, tc.expected, recorder.Body.String())
+			}
+		})
+	}
+}
+
+// failingLabelNamesStore fails the lookup of m0 once the other lookups
+// running at the same time have started, which return once the request is
+// cancelled.
+type failingLabelNamesStore struct {
+	started chan struct{}
+}
+
+func (s failingLabelNamesStore) MetricNames(context.Context, model.Time, model.Time) ([]string, error) {
+	return nil, nil
+}
+
+func (s failingLabelNamesStore) LabelNamesForMetricName(ctx context.Context, _, _ model.Time, metricName string) ([]string, error) {
+	if metricName == "m0" {
+		for i := 1; i < labelNamesConcurrency; i++ {
+			<-s.started
+		}
+		return nil, errors.New("store failed")
+	}
+	s.started <- struct{}{}
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestLabelNamesFromStoreError(t *testing.T) {
+	var metricNames []string
+	for i := 0; i < 2*labelNamesConcurrency; i++ {
+		metricNames = append(metricNames, fmt.Sprintf("m%d", i))
+	}
+	store := failingLabelNamesStore{started: make(chan struct{}, len(metricNames))}
+
+	goroutines := runtime.NumGoroutine()
+	_, err := labelNamesFromStore(context.Background(), store, 0, 1000, metricNames)
+	require.EqualError(t, err, "store failed")
+
+	// The lookups still running when the error is returned don't leak.
+	test.Poll(t, time.Second, true, func() interface{} {
+		return runtime.NumGoroutine() <= goroutines
+	})
+}
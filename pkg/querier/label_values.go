@@ -0,0 +1,109 @@
+package querier
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/pkg/timestamp"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/prometheus/prometheus/storage"
+)
+
+// As in the Prometheus API, requests without a time range cover all time.
+var (
+	minTime = timestamp.FromTime(time.Unix(-62135596800, 0))
+	maxTime = timestamp.FromTime(time.Unix(253402300799, 0))
+)
+
+// LabelValuesHandler answers label values requests with match[] parameters,
+// which the Prometheus API doesn't support, with the values of the label on
+// the series matching any of the selectors, from both the ingesters and the
+// chunk store within the requested time range.  This spares clients like
+// Grafana fetching all of the label's values and filtering them themselves.
+// All other requests are passed to next.
+func LabelValuesHandler(queryable storage.Queryable, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		i := strings.LastIndex(r.URL.Path, "/label/")
+		if i < 0 || !strings.HasSuffix(r.URL.Path, "/values") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if err := r.ParseForm(); err != nil || len(r.Form["match[]"]) == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		name := strings.TrimSuffix(r.URL.Path[i+len("/label/"):], "/values")
+		if !model.LabelNameRE.MatchString(name) {
+			writeError(w, "bad_data", http.StatusBadRequest, fmt.Errorf("invalid label name: %q", name))
+			return
+		}
+
+		mint, maxt := minTime, maxTime
+		if s := r.FormValue("start"); s != "" {
+			t, err := parseQueryTime(s)
+			if err != nil {
+				writeError(w, "bad_data", http.StatusBadRequest, err)
+				return
+			}
+			mint = timestamp.FromTime(t)
+		}
+		if s := r.FormValue("end"); s != "" {
+			t, err := parseQueryTime(s)
+			if err != nil {
+				writeError(w, "bad_data", http.StatusBadRequest, err)
+				return
+			}
+			maxt = timestamp.FromTime(t)
+		}
+
+		var matcherSets [][]*labels.Matcher
+		for _, s := range r.Form["match[]"] {
+			matchers, err := promql.ParseMetricSelector(s)
+			if err != nil {
+				writeError(w, "bad_data", http.StatusBadRequest, err)
+				return
+			}
+			matcherSets = append(matcherSets, matchers)
+		}
+
+		querier, err := queryable.Querier(r.Context(), mint, maxt)
+		if err != nil {
+			writeQueryError(w, err)
+			return
+		}
+		defer querier.Close()
+
+		// Nil SelectParams only select the series' labels.
+		values := map[string]struct{}{}
+		for _, matchers := range matcherSets {
+			set, _, err := querier.Select(nil, matchers...)
+			if err != nil {
+				writeQueryError(w, err)
+				return
+			}
+			for set.Next() {
+				if v := set.At().Labels().Get(name); v != "" {
+					values[v] = struct{}{}
+				}
+			}
+			if err := set.Err(); err != nil {
+				writeQueryError(w, err)
+				return
+			}
+		}
+
+		result := make([]string, 0, len(values))
+		for v := range values {
+			result = append(result, v)
+		}
+		sort.Strings(result)
+
+		writeSuccess(w, r, result)
+	})
+}
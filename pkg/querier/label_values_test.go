@@ -0,0 +1,110 @@
+package querier
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/httpgrpc"
+	"github.com/weaveworks/common/user"
+
+	"github.com/cortexproject/cortex/pkg/chunk"
+	promchunk "github.com/cortexproject/cortex/pkg/chunk/encoding"
+	"github.com/cortexproject/cortex/pkg/prom1/storage/metric"
+	"github.com/cortexproject/cortex/pkg/util/flagext"
+	"github.com/cortexproject/cortex/pkg/util/validation"
+)
+
+func TestLabelValuesHandler(t *testing.T) {
+	var cfg Config
+	flagext.DefaultValues(&cfg)
+	cfg.metricsRegisterer = nil
+
+	c := mkChunk(t, 0, 100, time.Millisecond, promchunk.Bigchunk)
+	c.Metric = labels.Labels{{Name: model.MetricNameLabel, Value: "up"}, {Name: "cluster", Value: "x"}, {Name: "namespace", Value: "b"}}
+	distributor := &mockDistributor{
+		metrics: []metric.Metric{
+			{Metric: model.Metric{model.MetricNameLabel: "up", "cluster": "x", "namespace": "a"}},
+			{Metric: model.Metric{model.MetricNameLabel: "up", "cluster": "x"}},
+		},
+	}
+	var limits validation.Limits
+	flagext.DefaultValues(&limits)
+	limits.MaxQueryLength = 24 * time.Hour
+	overrides, err := validation.NewOverrides(limits)
+	require.NoError(t, err)
+	store := maxLengthChunkStore{mockChunkStore: mockChunkStore{chunks: []chunk.Chunk{c}}, maxLength: limits.MaxQueryLength}
+	queryable, _ := New(cfg, distributor, store, overrides)
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("next"))
+	})
+	handler := LabelValuesHandler(queryable, next)
+
+	for _, tc := range []struct {
+		url      string
+		code     int
+		expected string
+	}{
+		{
+			url:      `/api/v1/label/namespace/values?match[]=up{cluster="x"}&start=0&end=1`,
+			code:     http.StatusOK,
+			expected: `{"data":["a","b"],"status":"success"}`,
+		},
+		{
+			// Without a time range, the store is only searched within the
+			// maximum query length.
+			url:      `/api/v1/label/namespace/values?match[]=up{cluster="x"}`,
+			code:     http.StatusOK,
+			expected: `{"data":["a","b"],"status":"success"}`,
+		},
+		{
+			url:      `/api/v1/label/namespace/values?match[]=up{cluster="y"}&start=0&end=1`,
+			code:     http.StatusOK,
+			expected: `{"data":[],"status":"success"}`,
+		},
+		{
+			url:      `/api/v1/label/namespace/values`,
+			code:     http.StatusOK,
+			expected: `next`,
+		},
+		{
+			url:  `/api/v1/label/namespace/values?match[]=up{`,
+			code: http.StatusBadRequest,
+		},
+		{
+			url:  `/api/v1/label/name-space/values?match[]=up`,
+			code: http.StatusBadRequest,
+		},
+	} {
+		t.Run(tc.url, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tc.url, nil)
+			req = req.WithContext(user.InjectOrgID(context.Background(), "1"))
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+			require.Equal(t, tc.code, recorder.Code)
+			if tc.expected != "" {
+				require.Equal(t, tc.expected, recorder.Body.String())
+			}
+		})
+	}
+}
+
+// maxLengthChunkStore rejects lookups longer than maxLength, as the chunk
+// store does those longer than the maximum query length.
+type maxLengthChunkStore struct {
+	mockChunkStore
+	maxLength time.Duration
+}
+
+func (s maxLengthChunkStore) Get(ctx context.Context, from, through model.Time, matchers ...*labels.Matcher) ([]chunk.Chunk, error) {
+	if through.Sub(from) > s.maxLength {
+		return nil, httpgrpc.Errorf(http.StatusBadRequest, "query too long")
+	}
+	return s.mockChunkStore.Get(ctx, from, through, matchers...)
+}
@@ -0,0 +1,173 @@
+package querier
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/weaveworks/common/user"
+
+	"github.com/cortexproject/cortex/pkg/chunk"
+	"github.com/cortexproject/cortex/pkg/util/validation"
+)
+
+// queryLimiter counts the series and samples read by a query, across all of
+// its selectors.
+type queryLimiter struct {
+	maxSeries, maxSamples int64
+	series, samples       int64
+}
+
+func (l *queryLimiter) addSeries() error {
+	if n := atomic.AddInt64(&l.series, 1); l.maxSeries > 0 && n > l.maxSeries {
+		return fmt.Errorf(validation.ErrTooManySeriesPerQuery, l.maxSeries)
+	}
+	return nil
+}
+
+func (l *queryLimiter) addSample() error {
+	if n := atomic.AddInt64(&l.samples, 1); l.maxSamples > 0 && n > l.maxSamples {
+		return fmt.Errorf(validation.ErrTooManySamplesPerQuery, l.maxSamples)
+	}
+	return nil
+}
+
+type limitsQuerier struct {
+	next    storage.Querier
+	limiter *queryLimiter
+}
+
+// newLimitsQuerier wraps a storage.Querier, failing the query once it reads
+// more than the tenant's max_series_per_query series or max_samples_per_query
+// samples, as the engine consumes them, rather than once it has loaded them
+// all.
+func newLimitsQuerier(ctx context.Context, limits *validation.Overrides, next storage.Querier) storage.Querier {
+	userID, err := user.ExtractOrgID(ctx)
+	if err != nil {
+		return next
+	}
+	return limitsQuerier{
+		next: next,
+		limiter: &queryLimiter{
+			maxSeries:  int64(limits.MaxSeriesPerQuery(userID)),
+			maxSamples: int64(limits.MaxSamplesPerQuery(userID)),
+		},
+	}
+}
+
+func (q limitsQuerier) Select(params *storage.SelectParams, matchers ...*labels.Matcher) (storage.SeriesSet, storage.Warnings, error) {
+	set, warnings, err := q.next.Select(params, matchers...)
+	// Series requests are limited by max_series_results.
+	if err != nil || params == nil {
+		return set, warnings, err
+	}
+	return &limitsSeriesSet{
+		SeriesSet: set,
+		limiter:   q.limiter,
+	}, warnings, nil
+}
+
+func (q limitsQuerier) LabelValues(name string) ([]string, error) {
+	return q.next.LabelValues(name)
+}
+
+func (q limitsQuerier) LabelNames() ([]string, error) {
+	return q.next.LabelNames()
+}
+
+func (q limitsQuerier) Close() error {
+	return q.next.Close()
+}
+
+// Get implements ChunkStore for the chunk tar HTTP handler.
+func (q limitsQuerier) Get(ctx context.Context, from, through model.Time, matchers ...*labels.Matcher) ([]chunk.Chunk, error) {
+	store, ok := q.next.(ChunkStore)
+	if !ok {
+		return nil, fmt.Errorf("not supported")
+	}
+
+	return store.Get(ctx, from, through, matchers...)
+}
+
+type limitsSeriesSet struct {
+	storage.SeriesSet
+	limiter *queryLimiter
+	err     error
+}
+
+func (s *limitsSeriesSet) Next() bool {
+	if s.err != nil || !s.SeriesSet.Next() {
+		return false
+	}
+	s.err = s.limiter.addSeries()
+	return s.err == nil
+}
+
+func (s *limitsSeriesSet) At() storage.Series {
+	return limitsSeries{
+		Series:  s.SeriesSet.At(),
+		limiter: s.limiter,
+	}
+}
+
+func (s *limitsSeriesSet) Err() error {
+	if s.err != nil {
+		return s.err
+	}
+	return s.SeriesSet.Err()
+}
+
+type limitsSeries struct {
+	storage.Series
+	limiter *queryLimiter
+}
+
+func (s limitsSeries) Iterator() storage.SeriesIterator {
+	return &limitsIterator{
+		SeriesIterator: s.Series.Iterator(),
+		limiter:        s.limiter,
+	}
+}
+
+// limitsIterator counts samples as statsIterator does.
+type limitsIterator struct {
+	storage.SeriesIterator
+	limiter *queryLimiter
+	started bool
+	last    int64
+	err     error
+}
+
+func (it *limitsIterator) Seek(t int64) bool {
+	if it.err != nil || !it.SeriesIterator.Seek(t) {
+		return false
+	}
+	return it.count()
+}
+
+func (it *limitsIterator) Next() bool {
+	if it.err != nil || !it.SeriesIterator.Next() {
+		return false
+	}
+	return it.count()
+}
+
+func (it *limitsIterator) count() bool {
+	t, _ := it.SeriesIterator.At()
+	if it.started && t == it.last {
+		return true
+	}
+	it.started, it.last = true, t
+	it.err = it.limiter.addSample()
+	return it.err == nil
+}
+
+func (it *limitsIterator) Err() error {
+	if it.err != nil {
+		return it.err
+	}
+	return it.SeriesIterator.Err()
+}
@@ -0,0 +1,73 @@
+package querier
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+
+	"github.com/cortexproject/cortex/pkg/util/flagext"
+	"github.com/cortexproject/cortex/pkg/util/validation"
+)
+
+func TestLimitsQuerier(t *testing.T) {
+	series := []storage.Series{
+		&concreteSeries{
+			labels:  labels.FromStrings("__name__", "foo", "i", "0"),
+			samples: []model.SamplePair{{Value: 1, Timestamp: 1}, {Value: 2, Timestamp: 2}, {Value: 3, Timestamp: 3}},
+		},
+		&concreteSeries{
+			labels:  labels.FromStrings("__name__", "foo", "i", "1"),
+			samples: []model.SamplePair{{Value: 1, Timestamp: 1}},
+		},
+	}
+	ctx := user.InjectOrgID(context.Background(), "1")
+
+	// Without a tenant in the context, the querier isn't wrapped.
+	next := &mockSelectQuerier{series: series}
+	require.Equal(t, next, newLimitsQuerier(context.Background(), defaultLimits(t), next))
+
+	for _, tc := range []struct {
+		maxSeries, maxSamples int
+		err                   string
+	}{
+		{},
+		{maxSeries: 2, maxSamples: 4},
+		{maxSeries: 1, err: fmt.Sprintf(validation.ErrTooManySeriesPerQuery, 1)},
+		{maxSamples: 3, err: fmt.Sprintf(validation.ErrTooManySamplesPerQuery, 3)},
+	} {
+		t.Run(fmt.Sprintf("series=%d,samples=%d", tc.maxSeries, tc.maxSamples), func(t *testing.T) {
+			var limits validation.Limits
+			flagext.DefaultValues(&limits)
+			limits.MaxSeriesPerQuery = tc.maxSeries
+			limits.MaxSamplesPerQuery = tc.maxSamples
+			overrides, err := validation.NewOverrides(limits)
+			require.NoError(t, err)
+
+			set, _, err := newLimitsQuerier(ctx, overrides, next).Select(&storage.SelectParams{})
+			require.NoError(t, err)
+			for set.Next() {
+				it := set.At().Iterator()
+				// Seeking to the sample the iterator is already at doesn't count it twice.
+				for ok := it.Seek(0); ok; ok = it.Next() {
+					ts, _ := it.At()
+					require.True(t, it.Seek(ts))
+				}
+				if err := it.Err(); err != nil {
+					require.EqualError(t, err, tc.err)
+					return
+				}
+			}
+			if tc.err == "" {
+				require.NoError(t, set.Err())
+			} else {
+				require.EqualError(t, set.Err(), tc.err)
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package querier
 
 import (
+	"sync"
+
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/storage"
 
@@ -8,11 +10,34 @@ import (
 	"github.com/cortexproject/cortex/pkg/util"
 )
 
+// samplesPool holds the slices chunks are decoded into before being merged,
+// which aren't needed once they are.
+var samplesPool = sync.Pool{
+	New: func() interface{} { return &[]model.SamplePair{} },
+}
+
 func mergeChunks(chunks []chunk.Chunk, from, through model.Time) storage.SeriesIterator {
+	// A single chunk's samples are returned as they are, so can't be pooled.
+	if len(chunks) == 1 {
+		ss, err := chunks[0].Samples(from, through)
+		if err != nil {
+			return errIterator{err}
+		}
+		return newConcreteSeriesIterator(newConcreteSeries(nil, ss))
+	}
+
 	samples := make([][]model.SamplePair, 0, len(chunks))
+	defer func() {
+		for _, ss := range samples {
+			ss = ss[:0]
+			samplesPool.Put(&ss)
+		}
+	}()
 	for _, c := range chunks {
-		ss, err := c.Samples(from, through)
+		buf := samplesPool.Get().(*[]model.SamplePair)
+		ss, err := c.AppendSamples((*buf)[:0], from, through)
 		if err != nil {
+			samplesPool.Put(buf)
 			return errIterator{err}
 		}
 
@@ -0,0 +1,59 @@
+package querier
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/prometheus/promql"
+)
+
+type metadataEntry struct {
+	Type string `json:"type"`
+	Help string `json:"help"`
+	Unit string `json:"unit"`
+}
+
+// MetadataHandler serves the Prometheus API's /api/v1/metadata, with the
+// metadata of the metrics the ingesters received within their
+// -ingester.metadata-retain-period.  Like Prometheus, it takes an optional
+// metric to return the metadata of, and a limit on the number of metrics
+// returned.
+func MetadataHandler(distributor Distributor) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit := -1
+		if s := r.FormValue("limit"); s != "" {
+			var err error
+			if limit, err = strconv.Atoi(s); err != nil {
+				writeError(w, "bad_data", http.StatusBadRequest, err)
+				return
+			}
+		}
+		name := r.FormValue("metric")
+
+		metadata, err := distributor.MetricsMetadata(r.Context())
+		if err != nil {
+			writeQueryError(w, promql.ErrStorage{Err: err})
+			return
+		}
+
+		// The distributor returns the metadata sorted by metric.
+		result := map[string][]metadataEntry{}
+		for _, m := range metadata {
+			if name != "" && m.MetricFamilyName != name {
+				continue
+			}
+			entries, ok := result[m.MetricFamilyName]
+			if !ok && limit >= 0 && len(result) >= limit {
+				break
+			}
+			result[m.MetricFamilyName] = append(entries, metadataEntry{
+				Type: strings.ToLower(m.Type.String()),
+				Help: m.Help,
+				Unit: m.Unit,
+			})
+		}
+
+		writeSuccess(w, r, result)
+	})
+}
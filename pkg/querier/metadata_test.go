@@ -0,0 +1,61 @@
+package querier
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cortexproject/cortex/pkg/ingester/client"
+)
+
+func TestMetadataHandler(t *testing.T) {
+	handler := MetadataHandler(&mockDistributor{
+		metadata: []*client.MetricMetadata{
+			{MetricFamilyName: "bar", Type: client.GAUGE, Help: "Bar."},
+			{MetricFamilyName: "foo", Type: client.COUNTER, Help: "Foo.", Unit: "seconds"},
+			{MetricFamilyName: "foo", Type: client.COUNTER, Help: "Old foo."},
+		},
+	})
+
+	for _, tc := range []struct {
+		url      string
+		code     int
+		expected string
+	}{
+		{
+			url:      "/api/v1/metadata",
+			code:     http.StatusOK,
+			expected: `{"data":{"bar":[{"type":"gauge","help":"Bar.","unit":""}],"foo":[{"type":"counter","help":"Foo.","unit":"seconds"},{"type":"counter","help":"Old foo.","unit":""}]},"status":"success"}`,
+		},
+		{
+			url:      "/api/v1/metadata?metric=foo",
+			code:     http.StatusOK,
+			expected: `{"data":{"foo":[{"type":"counter","help":"Foo.","unit":"seconds"},{"type":"counter","help":"Old foo.","unit":""}]},"status":"success"}`,
+		},
+		{
+			url:      "/api/v1/metadata?limit=1",
+			code:     http.StatusOK,
+			expected: `{"data":{"bar":[{"type":"gauge","help":"Bar.","unit":""}]},"status":"success"}`,
+		},
+		{
+			url:      "/api/v1/metadata?metric=baz",
+			code:     http.StatusOK,
+			expected: `{"data":{},"status":"success"}`,
+		},
+		{
+			url:  "/api/v1/metadata?limit=foo",
+			code: http.StatusBadRequest,
+		},
+	} {
+		t.Run(tc.url, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest("GET", tc.url, nil))
+			require.Equal(t, tc.code, recorder.Code)
+			if tc.expected != "" {
+				require.Equal(t, tc.expected, recorder.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+package querier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kit/kit/log/level"
+	"github.com/gogo/protobuf/proto"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/prometheus/prometheus/storage"
+
+	"github.com/cortexproject/cortex/pkg/ingester/client"
+	"github.com/cortexproject/cortex/pkg/querier/frontend"
+	"github.com/cortexproject/cortex/pkg/util"
+)
+
+var (
+	errInvalidRange      = errors.New("invalid query range")
+	errInvalidDuration   = errors.New("invalid duration")
+	errUnsupportedResult = errors.New("result type not supported in protobuf responses")
+)
+
+// ProtobufHandler answers range and instant queries from clients accepting
+// protobuf, like the query frontend, with the results encoded as a
+// frontend.APIResponse, which is much cheaper to encode and decode than JSON.
+// All other requests, and requests with parameters it can't parse, are passed
+// to next, the Prometheus API, which remains the reference for the API's
+// behaviour.
+func ProtobufHandler(engine *promql.Engine, queryable storage.Queryable, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Accept"), frontend.ProtobufContentType) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		var (
+			qry promql.Query
+			err error
+		)
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/query_range"):
+			qry, err = newRangeQuery(engine, queryable, r)
+		case strings.HasSuffix(r.URL.Path, "/query"):
+			qry, err = newInstantQuery(engine, queryable, r)
+		default:
+			next.ServeHTTP(w, r)
+			return
+		}
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		defer qry.Close()
+
+		ctx := r.Context()
+		if to := r.FormValue("timeout"); to != "" {
+			timeout, err := parseQueryDuration(to)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		res := qry.Exec(ctx)
+		if res.Err != nil {
+			writeQueryError(w, res.Err)
+			return
+		}
+
+		resp, err := toAPIResponse(res)
+		if err != nil {
+			writeQueryError(w, err)
+			return
+		}
+
+		buf, err := proto.Marshal(resp)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", frontend.ProtobufContentType)
+		if _, err := w.Write(buf); err != nil {
+			level.Error(util.WithContext(ctx, util.Logger)).Log("msg", "error writing response", "err", err)
+		}
+	})
+}
+
+func newRangeQuery(engine *promql.Engine, queryable storage.Queryable, r *http.Request) (promql.Query, error) {
+	start, err := parseQueryTime(r.FormValue("start"))
+	if err != nil {
+		return nil, err
+	}
+	end, err := parseQueryTime(r.FormValue("end"))
+	if err != nil {
+		return nil, err
+	}
+	step, err := parseQueryDuration(r.FormValue("step"))
+	if err != nil {
+		return nil, err
+	}
+	// Leave the Prometheus API to reject invalid ranges and steps.
+	if end.Before(start) || step <= 0 || end.Sub(start)/step > 11000 {
+		return nil, errInvalidRange
+	}
+	return engine.NewRangeQuery(queryable, r.FormValue("query"), start, end, step)
+}
+
+func newInstantQuery(engine *promql.Engine, queryable storage.Queryable, r *http.Request) (promql.Query, error) {
+	// String results can't be represented in an APIResponse.
+	expr, err := promql.ParseExpr(r.FormValue("query"))
+	if err != nil || expr.Type() == promql.ValueTypeString {
+		return nil, errUnsupportedResult
+	}
+
+	ts := time.Now()
+	if t := r.FormValue("time"); t != "" {
+		if ts, err = parseQueryTime(t); err != nil {
+			return nil, err
+		}
+	}
+	return engine.NewInstantQuery(queryable, r.FormValue("query"), ts)
+}
+
+// toAPIResponse converts the query's result to an APIResponse, in which
+// vectors are streams of a single sample and scalars a single unlabelled
+// stream, as the frontend represents them.
+func toAPIResponse(res *promql.Result) (*frontend.APIResponse, error) {
+	resp := &frontend.APIResponse{
+		Status: "success",
+		Data: frontend.QueryRangeResponse{
+			ResultType: string(res.Value.Type()),
+		},
+	}
+	for _, w := range res.Warnings {
+		resp.Warnings = append(resp.Warnings, w.Error())
+	}
+
+	switch v := res.Value.(type) {
+	case promql.Matrix:
+		resp.Data.Result = make([]frontend.SampleStream, 0, len(v))
+		for _, series := range v {
+			samples := make([]client.Sample, 0, len(series.Points))
+			for _, p := range series.Points {
+				samples = append(samples, client.Sample{TimestampMs: p.T, Value: p.V})
+			}
+			resp.Data.Result = append(resp.Data.Result, frontend.SampleStream{
+				Labels:  client.FromLabelsToLabelAdapaters(series.Metric),
+				Samples: samples,
+			})
+		}
+	case promql.Vector:
+		resp.Data.Result = make([]frontend.SampleStream, 0, len(v))
+		for _, sample := range v {
+			resp.Data.Result = append(resp.Data.Result, frontend.SampleStream{
+				Labels:  client.FromLabelsToLabelAdapaters(sample.Metric),
+				Samples: []client.Sample{{TimestampMs: sample.T, Value: sample.V}},
+			})
+		}
+	case promql.Scalar:
+		resp.Data.Result = []frontend.SampleStream{{
+			Samples: []client.Sample{{TimestampMs: v.T, Value: v.V}},
+		}}
+	default:
+		return nil, errUnsupportedResult
+	}
+	return resp, nil
+}
+
+// writeQueryError writes the error as the Prometheus API does, so clients
+// see the same errors whichever encoding they asked for.
+func writeQueryError(w http.ResponseWriter, err error) {
+	errorType, code := "execution", http.StatusUnprocessableEntity
+	switch err.(type) {
+	case promql.ErrQueryCanceled:
+		errorType, code = "canceled", http.StatusServiceUnavailable
+	case promql.ErrQueryTimeout:
+		errorType, code = "timeout", http.StatusServiceUnavailable
+	case promql.ErrStorage:
+		errorType, code = "internal", http.StatusInternalServerError
+	}
+
+	writeError(w, errorType, code, err)
+}
+
+// writeError writes an error response in the Prometheus API's format.
+func writeError(w http.ResponseWriter, errorType string, code int, err error) {
+	b, _ := json.Marshal(map[string]string{
+		"status":    "error",
+		"errorType": errorType,
+		"error":     err.Error(),
+	})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(b)
+}
+
+// writeSuccess writes a successful Prometheus API response with the data.
+func writeSuccess(w http.ResponseWriter, r *http.Request, data interface{}) {
+	b, err := json.Marshal(map[string]interface{}{
+		"status": "success",
+		"data":   data,
+	})
+	if err != nil {
+		writeError(w, "internal", http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(b); err != nil {
+		level.Error(util.WithContext(r.Context(), util.Logger)).Log("msg", "error writing response", "err", err)
+	}
+}
+
+func parseQueryTime(s string) (time.Time, error) {
+	t, err := frontend.ParseTime(s)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return model.Time(t).Time(), nil
+}
+
+func parseQueryDuration(s string) (time.Duration, error) {
+	if d, err := strconv.ParseFloat(s, 64); err == nil {
+		ts := d * float64(time.Second)
+		if ts > float64(math.MaxInt64) || ts < float64(math.MinInt64) {
+			return 0, errInvalidDuration
+		}
+		return time.Duration(ts), nil
+	}
+	if d, err := model.ParseDuration(s); err == nil {
+		return time.Duration(d), nil
+	}
+	return 0, errInvalidDuration
+}
@@ -0,0 +1,115 @@
+package querier
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cortexproject/cortex/pkg/ingester/client"
+	"github.com/cortexproject/cortex/pkg/querier/frontend"
+	"github.com/cortexproject/cortex/pkg/util"
+)
+
+func TestProtobufHandler(t *testing.T) {
+	queryable := storage.QueryableFunc(func(ctx context.Context, mint, maxt int64) (storage.Querier, error) {
+		return mockQuerier{
+			matrix: model.Matrix{
+				{
+					Metric: model.Metric{"__name__": "foo", "bar": "baz"},
+					Values: []model.SamplePair{
+						{Timestamp: 0, Value: 1},
+						{Timestamp: 60000, Value: 2},
+					},
+				},
+			},
+		}, nil
+	})
+	engine := promql.NewEngine(promql.EngineOpts{
+		Logger:        util.Logger,
+		MaxConcurrent: 1,
+		MaxSamples:    1e6,
+		Timeout:       1 * time.Minute,
+	})
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("next"))
+	})
+	handler := ProtobufHandler(engine, queryable, next)
+
+	for _, tc := range []struct {
+		name     string
+		url      string
+		accept   string
+		expected *frontend.APIResponse
+	}{
+		{
+			name:   "range query",
+			url:    "/api/v1/query_range?query=foo&start=0&end=60&step=60",
+			accept: frontend.ProtobufContentType,
+			expected: &frontend.APIResponse{
+				Status: "success",
+				Data: frontend.QueryRangeResponse{
+					ResultType: "matrix",
+					Result: []frontend.SampleStream{{
+						Labels:  []client.LabelAdapter{{Name: "__name__", Value: "foo"}, {Name: "bar", Value: "baz"}},
+						Samples: []client.Sample{{TimestampMs: 0, Value: 1}, {TimestampMs: 60000, Value: 2}},
+					}},
+				},
+			},
+		},
+		{
+			name:   "instant query",
+			url:    "/api/v1/query?query=sum(foo)&time=60",
+			accept: frontend.ProtobufContentType,
+			expected: &frontend.APIResponse{
+				Status: "success",
+				Data: frontend.QueryRangeResponse{
+					ResultType: "vector",
+					Result: []frontend.SampleStream{{
+						Samples: []client.Sample{{TimestampMs: 60000, Value: 2}},
+					}},
+				},
+			},
+		},
+		{
+			name: "JSON requested",
+			url:  "/api/v1/query_range?query=foo&start=0&end=60&step=60",
+		},
+		{
+			name:   "string results",
+			url:    "/api/v1/query?query=\"foo\"&time=60",
+			accept: frontend.ProtobufContentType,
+		},
+		{
+			name:   "invalid parameters",
+			url:    "/api/v1/query_range?query=foo&start=60&end=0&step=60",
+			accept: frontend.ProtobufContentType,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tc.url, nil)
+			req.Header.Set("Accept", tc.accept)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			require.Equal(t, http.StatusOK, rec.Code)
+
+			if tc.expected == nil {
+				require.Equal(t, "next", rec.Body.String())
+				return
+			}
+
+			require.Equal(t, frontend.ProtobufContentType, rec.Header().Get("Content-Type"))
+			var resp frontend.APIResponse
+			require.NoError(t, proto.Unmarshal(rec.Body.Bytes(), &resp))
+			require.Equal(t, tc.expected, &resp)
+		})
+	}
+}
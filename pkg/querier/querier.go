@@ -15,17 +15,26 @@ import (
 	"github.com/cortexproject/cortex/pkg/querier/batch"
 	"github.com/cortexproject/cortex/pkg/querier/iterators"
 	"github.com/cortexproject/cortex/pkg/util"
+	"github.com/cortexproject/cortex/pkg/util/validation"
 )
 
 // Config contains the configuration require to create a querier
 type Config struct {
-	MaxConcurrent            int
-	Timeout                  time.Duration
-	Iterators                bool
-	BatchIterators           bool
-	IngesterStreaming        bool
-	MaxSamples               int
-	IngesterMaxQueryLookback time.Duration
+	MaxConcurrent              int
+	MaxConcurrentEngineQueries int
+	MaxQueuedRequests          int
+	QueueTimeout               time.Duration
+	Timeout                    time.Duration
+	ActiveQueryTrackerDir      string
+	Iterators                  bool
+	BatchIterators             bool
+	IngesterStreaming          bool
+	MaxSamples                 int
+	EnforceQueryLimits         bool
+	IngesterMaxQueryLookback   time.Duration
+	QueryStoreAfter            time.Duration
+	TenantFederation           bool
+	QueryStatsHistory          int
 
 	// The default evaluation interval for the promql engine.
 	// Needs to be configured for subqueries to work as it is the default
@@ -36,18 +45,35 @@ type Config struct {
 	metricsRegisterer prometheus.Registerer
 }
 
+// EngineMaxConcurrent returns the number of queries the PromQL engine
+// evaluates at once.
+func (cfg *Config) EngineMaxConcurrent() int {
+	if cfg.MaxConcurrentEngineQueries > 0 {
+		return cfg.MaxConcurrentEngineQueries
+	}
+	return cfg.MaxConcurrent
+}
+
 // RegisterFlags adds the flags required to config this to the given FlagSet.
 func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
-	f.IntVar(&cfg.MaxConcurrent, "querier.max-concurrent", 20, "The maximum number of concurrent queries.")
+	f.IntVar(&cfg.MaxConcurrent, "querier.max-concurrent", 20, "The maximum number of concurrent queries. Also enforced on all of the querier's query API requests, which wait in a queue for a free slot.")
+	f.IntVar(&cfg.MaxQueuedRequests, "querier.max-queued-requests", 100, "The maximum number of query API requests waiting for one of the -querier.max-concurrent slots. Requests over it are rejected with a 503.")
+	f.DurationVar(&cfg.QueueTimeout, "querier.queue-timeout", time.Minute, "The maximum time a query API request waits for one of the -querier.max-concurrent slots before being rejected with a 503. 0 means requests wait until cancelled.")
+	f.IntVar(&cfg.MaxConcurrentEngineQueries, "querier.max-concurrent-engine-queries", 0, "The maximum number of PromQL queries the engine evaluates at once; others wait for their turn. 0 means -querier.max-concurrent.")
 	f.DurationVar(&cfg.Timeout, "querier.timeout", 2*time.Minute, "The timeout for a query.")
+	f.StringVar(&cfg.ActiveQueryTrackerDir, "querier.active-query-tracker-dir", "", "Directory in which to record the queries being executed, so those running when the querier crashed are logged when it restarts. Empty disables it.")
 	if f.Lookup("promql.lookback-delta") == nil {
 		f.DurationVar(&promql.LookbackDelta, "promql.lookback-delta", promql.LookbackDelta, "Time since the last sample after which a time series is considered stale and ignored by expression evaluations.")
 	}
 	f.BoolVar(&cfg.Iterators, "querier.iterators", false, "Use iterators to execute query, as opposed to fully materialising the series in memory.")
-	f.BoolVar(&cfg.BatchIterators, "querier.batch-iterators", false, "Use batch iterators to execute query, as opposed to fully materialising the series in memory.  Takes precedent over the -querier.iterators flag.")
+	f.BoolVar(&cfg.BatchIterators, "querier.batch-iterators", true, "Use batch iterators to execute query, as opposed to fully materialising the series in memory.  Takes precedent over the -querier.iterators flag.")
 	f.BoolVar(&cfg.IngesterStreaming, "querier.ingester-streaming", false, "Use streaming RPCs to query ingester.")
 	f.IntVar(&cfg.MaxSamples, "querier.max-samples", 50e6, "Maximum number of samples a single query can load into memory.")
+	f.BoolVar(&cfg.EnforceQueryLimits, "querier.enforce-query-limits", false, "Also enforce the per-user -ingester.max-series-per-query and -ingester.max-samples-per-query limits in the querier, on all of the series and samples a query reads from the ingesters and the chunk store.")
 	f.DurationVar(&cfg.IngesterMaxQueryLookback, "querier.query-ingesters-within", 0, "Maximum lookback beyond which queries are not sent to ingester. 0 means all queries are sent to ingester.")
+	f.DurationVar(&cfg.QueryStoreAfter, "querier.query-store-after", 0, "The time after which a query has to reach back for it to be sent to the chunk store; more recent queries are only sent to the ingesters. Should be less than the ingesters' -ingester.max-chunk-age plus their flush period. 0 means all queries are sent to the store.")
+	f.IntVar(&cfg.QueryStatsHistory, "querier.query-stats-history", 100, "The number of recent queries whose stats are kept, to be served at /query_stats. 0 disables it.")
+	f.BoolVar(&cfg.TenantFederation, "querier.tenant-federation", false, "Allow queries across tenants, with their IDs separated by '|' in the org ID; each series returned has a __tenant_id__ label with its tenant.")
 	f.DurationVar(&cfg.DefaultEvaluationInterval, "querier.default-evaluation-interval", time.Minute, "The default evaluation interval or step size for subqueries.")
 	cfg.metricsRegisterer = prometheus.DefaultRegisterer
 }
@@ -59,7 +85,7 @@ type ChunkStore interface {
 }
 
 // New builds a queryable and promql engine.
-func New(cfg Config, distributor Distributor, chunkStore ChunkStore) (storage.Queryable, *promql.Engine) {
+func New(cfg Config, distributor Distributor, chunkStore ChunkStore, limits *validation.Overrides) (storage.Queryable, *promql.Engine) {
 	iteratorFunc := mergeChunks
 	if cfg.BatchIterators {
 		iteratorFunc = batch.NewChunkMergeIterator
@@ -70,26 +96,33 @@ func New(cfg Config, distributor Distributor, chunkStore ChunkStore) (storage.Qu
 	var queryable storage.Queryable
 	if cfg.IngesterStreaming {
 		dq := newIngesterStreamingQueryable(distributor, iteratorFunc)
-		queryable = newUnifiedChunkQueryable(dq, chunkStore, distributor, iteratorFunc, cfg.IngesterMaxQueryLookback)
+		queryable = newUnifiedChunkQueryable(dq, chunkStore, distributor, iteratorFunc, cfg.IngesterMaxQueryLookback, cfg.QueryStoreAfter)
 	} else {
 		cq := newChunkStoreQueryable(chunkStore, iteratorFunc)
 		dq := newDistributorQueryable(distributor)
-		queryable = NewQueryable(dq, cq, distributor, cfg.IngesterMaxQueryLookback)
+		queryable = NewQueryable(dq, cq, distributor, cfg.IngesterMaxQueryLookback, cfg.QueryStoreAfter)
 	}
 
-	lazyQueryable := storage.QueryableFunc(func(ctx context.Context, mint int64, maxt int64) (storage.Querier, error) {
+	var lazyQueryable storage.Queryable = storage.QueryableFunc(func(ctx context.Context, mint int64, maxt int64) (storage.Querier, error) {
 		querier, err := queryable.Querier(ctx, mint, maxt)
 		if err != nil {
 			return nil, err
 		}
-		return newLazyQuerier(querier), nil
+		querier = newShardedQuerier(newSeriesQuerier(ctx, mint, maxt, querier, distributor, chunkStore, limits))
+		if cfg.EnforceQueryLimits {
+			querier = newLimitsQuerier(ctx, limits, querier)
+		}
+		return newLazyQuerier(newStatsQuerier(ctx, querier)), nil
 	})
+	if cfg.TenantFederation {
+		lazyQueryable = newMergeQueryable(lazyQueryable)
+	}
 
 	promql.SetDefaultEvaluationInterval(cfg.DefaultEvaluationInterval)
 	engine := promql.NewEngine(promql.EngineOpts{
 		Logger:        util.Logger,
 		Reg:           cfg.metricsRegisterer,
-		MaxConcurrent: cfg.MaxConcurrent,
+		MaxConcurrent: cfg.EngineMaxConcurrent(),
 		MaxSamples:    cfg.MaxSamples,
 		Timeout:       cfg.Timeout,
 	})
@@ -97,23 +130,25 @@ func New(cfg Config, distributor Distributor, chunkStore ChunkStore) (storage.Qu
 }
 
 // NewQueryable creates a new Queryable for cortex.
-func NewQueryable(dq, cq storage.Queryable, distributor Distributor, ingesterMaxQueryLookback time.Duration) storage.Queryable {
+func NewQueryable(dq, cq storage.Queryable, distributor Distributor, ingesterMaxQueryLookback, queryStoreAfter time.Duration) storage.Queryable {
 	return storage.QueryableFunc(func(ctx context.Context, mint, maxt int64) (storage.Querier, error) {
-		cqr, err := cq.Querier(ctx, mint, maxt)
-		if err != nil {
-			return nil, err
-		}
-
 		q := querier{
-			queriers:    []storage.Querier{cqr},
 			distributor: distributor,
 			ctx:         ctx,
 			mint:        mint,
 			maxt:        maxt,
 		}
 
-		// Include ingester only if maxt is within ingesterMaxQueryLookback w.r.t. current time.
-		if ingesterMaxQueryLookback == 0 || maxt >= time.Now().Add(-ingesterMaxQueryLookback).UnixNano()/1e6 {
+		now := time.Now()
+		if shouldQueryStore(queryStoreAfter, now, mint) {
+			cqr, err := cq.Querier(ctx, mint, maxt)
+			if err != nil {
+				return nil, err
+			}
+			q.queriers = append(q.queriers, cqr)
+		}
+
+		if shouldQueryIngesters(ingesterMaxQueryLookback, now, maxt) {
 			dqr, err := dq.Querier(ctx, mint, maxt)
 			if err != nil {
 				return nil, err
@@ -125,6 +160,18 @@ func NewQueryable(dq, cq storage.Queryable, distributor Distributor, ingesterMax
 	})
 }
 
+// shouldQueryIngesters returns whether a query up to maxt reaches back within
+// ingesterMaxQueryLookback of now, where the ingesters may have samples.
+func shouldQueryIngesters(ingesterMaxQueryLookback time.Duration, now time.Time, maxt int64) bool {
+	return ingesterMaxQueryLookback == 0 || maxt >= now.Add(-ingesterMaxQueryLookback).UnixNano()/1e6
+}
+
+// shouldQueryStore returns whether a query from mint reaches back beyond
+// queryStoreAfter, before which the ingesters may have flushed samples.
+func shouldQueryStore(queryStoreAfter time.Duration, now time.Time, mint int64) bool {
+	return queryStoreAfter == 0 || mint <= now.Add(-queryStoreAfter).UnixNano()/1e6
+}
+
 type querier struct {
 	queriers []storage.Querier
 
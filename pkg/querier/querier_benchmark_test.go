@@ -5,6 +5,9 @@ import (
 	"testing"
 
 	"github.com/prometheus/prometheus/promql"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cortexproject/cortex/pkg/chunk"
 )
 
 var result *promql.Result
@@ -28,3 +31,28 @@ func BenchmarkChunkQueryable(b *testing.B) {
 		}
 	}
 }
+
+// BenchmarkChunkIterators compares iterating over a series replicated to
+// three ingesters, and so made of overlapping chunks, with each of the ways
+// of merging them.
+func BenchmarkChunkIterators(b *testing.B) {
+	for _, encoding := range encodings {
+		store, through := makeMockChunkStore(b, 24*30, encoding.e)
+		chunks := make([]chunk.Chunk, 0, 3*len(store.chunks))
+		for i := 0; i < 3; i++ {
+			chunks = append(chunks, store.chunks...)
+		}
+
+		for _, testcase := range testcases {
+			b.Run(fmt.Sprintf("%s/%s", encoding.name, testcase.name), func(b *testing.B) {
+				b.ReportAllocs()
+				for n := 0; n < b.N; n++ {
+					it := testcase.f(chunks, 0, through)
+					for it.Next() {
+					}
+					require.NoError(b, it.Err())
+				}
+			})
+		}
+	}
+}
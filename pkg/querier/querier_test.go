@@ -22,6 +22,7 @@ import (
 	"github.com/cortexproject/cortex/pkg/util"
 	"github.com/cortexproject/cortex/pkg/util/chunkcompat"
 	"github.com/cortexproject/cortex/pkg/util/flagext"
+	"github.com/cortexproject/cortex/pkg/util/validation"
 	"github.com/weaveworks/common/user"
 )
 
@@ -129,16 +130,20 @@ func TestQuerier(t *testing.T) {
 	for _, query := range queries {
 		for _, encoding := range encodings {
 			for _, streaming := range []bool{false, true} {
-				for _, iterators := range []bool{false, true} {
-					t.Run(fmt.Sprintf("%s/%s/streaming=%t/iterators=%t", query.query, encoding.name, streaming, iterators), func(t *testing.T) {
+				for _, iterators := range []struct {
+					name            string
+					batch, iterator bool
+				}{{"none", false, false}, {"iterators", false, true}, {"batch", true, false}} {
+					t.Run(fmt.Sprintf("%s/%s/streaming=%t/iterators=%s", query.query, encoding.name, streaming, iterators.name), func(t *testing.T) {
 						cfg.IngesterStreaming = streaming
-						cfg.Iterators = iterators
+						cfg.BatchIterators = iterators.batch
+						cfg.Iterators = iterators.iterator
 						cfg.metricsRegisterer = nil
 
 						chunkStore, through := makeMockChunkStore(t, 24, encoding.e)
 						distributor := mockDistibutorFor(t, chunkStore, through)
 
-						queryable, _ := New(cfg, distributor, chunkStore)
+						queryable, _ := New(cfg, distributor, chunkStore, defaultLimits(t))
 						testQuery(t, queryable, through, query)
 					})
 				}
@@ -206,7 +211,7 @@ func TestNoHistoricalQueryToIngester(t *testing.T) {
 				chunkStore, _ := makeMockChunkStore(t, 24, encodings[0].e)
 				distributor := &errDistributor{}
 
-				queryable, _ := New(cfg, distributor, chunkStore)
+				queryable, _ := New(cfg, distributor, chunkStore, defaultLimits(t))
 				query, err := engine.NewRangeQuery(queryable, "dummy", c.mint, c.maxt, 1*time.Minute)
 				require.NoError(t, err)
 
@@ -228,6 +233,59 @@ func TestNoHistoricalQueryToIngester(t *testing.T) {
 
 }
 
+type errChunkStore struct{}
+
+var errChunkStoreError = fmt.Errorf("errChunkStoreError")
+
+func (errChunkStore) Get(ctx context.Context, from, through model.Time, matchers ...*labels.Matcher) ([]chunk.Chunk, error) {
+	return nil, errChunkStoreError
+}
+
+func TestNoRecentQueryToStore(t *testing.T) {
+	for _, c := range []struct {
+		name            string
+		mint            time.Time
+		hitStore        bool
+		queryStoreAfter time.Duration
+	}{
+		{name: "hit-older", mint: time.Now().Add(-2 * time.Hour), hitStore: true, queryStoreAfter: time.Hour},
+		{name: "hit-disabled", mint: time.Now().Add(-10 * time.Minute), hitStore: true},
+		{name: "dont-hit-recent", mint: time.Now().Add(-10 * time.Minute), hitStore: false, queryStoreAfter: time.Hour},
+	} {
+		for _, ingesterStreaming := range []bool{true, false} {
+			t.Run(fmt.Sprintf("IngesterStreaming=%t,test=%s", ingesterStreaming, c.name), func(t *testing.T) {
+				cfg := Config{IngesterStreaming: ingesterStreaming, QueryStoreAfter: c.queryStoreAfter}
+				queryable, _ := New(cfg, &mockDistributor{}, errChunkStore{}, defaultLimits(t))
+
+				engine := promql.NewEngine(promql.EngineOpts{
+					Logger:        util.Logger,
+					MaxConcurrent: 10,
+					MaxSamples:    1e6,
+					Timeout:       1 * time.Minute,
+				})
+				query, err := engine.NewRangeQuery(queryable, "dummy", c.mint, time.Now(), 1*time.Minute)
+				require.NoError(t, err)
+				_, err = query.Exec(user.InjectOrgID(context.Background(), "0")).Matrix()
+
+				if c.hitStore {
+					require.Error(t, err)
+					require.Equal(t, errChunkStoreError.Error(), err.Error())
+				} else {
+					require.NoError(t, err)
+				}
+			})
+		}
+	}
+}
+
+func defaultLimits(t *testing.T) *validation.Overrides {
+	var limits validation.Limits
+	flagext.DefaultValues(&limits)
+	overrides, err := validation.NewOverrides(limits)
+	require.NoError(t, err)
+	return overrides
+}
+
 // mockDistibutorFor duplicates the chunks in the mockChunkStore into the mockDistributor
 // so we can test everything is dedupe correctly.
 func mockDistibutorFor(t *testing.T, cs mockChunkStore, through model.Time) *mockDistributor {
@@ -300,3 +358,9 @@ func (m *errDistributor) LabelNames(context.Context) ([]string, error) {
 func (m *errDistributor) MetricsForLabelMatchers(ctx context.Context, from, through model.Time, matchers ...*labels.Matcher) ([]metric.Metric, error) {
 	return nil, errDistributorError
 }
+func (m *errDistributor) QueryExemplars(ctx context.Context, from, through model.Time, matchersSet ...[]*labels.Matcher) (*client.ExemplarQueryResponse, error) {
+	return nil, errDistributorError
+}
+func (m *errDistributor) MetricsMetadata(ctx context.Context) ([]*client.MetricMetadata, error) {
+	return nil, errDistributorError
+}
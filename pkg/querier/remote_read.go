@@ -8,6 +8,7 @@ import (
 	"github.com/go-kit/kit/log/level"
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/storage"
+	"github.com/weaveworks/common/httpgrpc"
 )
 
 // RemoteReadHandler handles Prometheus remote read requests.
@@ -33,7 +34,7 @@ func RemoteReadHandler(q storage.Queryable) http.Handler {
 			go func(i int, qr *client.QueryRequest) {
 				from, to, matchers, err := client.FromQueryRequest(qr)
 				if err != nil {
-					errors <- err
+					errors <- httpgrpc.Errorf(http.StatusBadRequest, err.Error())
 					return
 				}
 
@@ -42,6 +43,7 @@ func RemoteReadHandler(q storage.Queryable) http.Handler {
 					errors <- err
 					return
 				}
+				defer querier.Close()
 
 				params := &storage.SelectParams{
 					Start: int64(from),
@@ -72,7 +74,13 @@ func RemoteReadHandler(q storage.Queryable) http.Handler {
 			}
 		}
 		if lastErr != nil {
-			http.Error(w, lastErr.Error(), http.StatusBadRequest)
+			// Invalid queries are the client's fault, failing to read the
+			// series ours.
+			code, msg := http.StatusInternalServerError, lastErr.Error()
+			if resp, ok := httpgrpc.HTTPResponseFromError(lastErr); ok {
+				code, msg = int(resp.Code), string(resp.Body)
+			}
+			http.Error(w, msg, code)
 			return
 		}
 
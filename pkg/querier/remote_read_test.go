@@ -3,6 +3,7 @@ package querier
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -103,3 +104,42 @@ func (m mockQuerier) LabelNames() ([]string, error) {
 func (mockQuerier) Close() error {
 	return nil
 }
+
+func TestRemoteReadHandlerErrors(t *testing.T) {
+	handler := RemoteReadHandler(storage.QueryableFunc(func(ctx context.Context, mint, maxt int64) (storage.Querier, error) {
+		return nil, errors.New("store unavailable")
+	}))
+
+	for _, tc := range []struct {
+		name         string
+		matchers     []*client.LabelMatcher
+		expectedCode int
+	}{
+		{
+			name:         "invalid matchers",
+			matchers:     []*client.LabelMatcher{{Type: client.REGEX_MATCH, Name: "foo", Value: "("}},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "storage error",
+			matchers:     []*client.LabelMatcher{{Type: client.EQUAL, Name: "foo", Value: "bar"}},
+			expectedCode: http.StatusInternalServerError,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			requestBody, err := proto.Marshal(&client.ReadRequest{
+				Queries: []*client.QueryRequest{
+					{StartTimestampMs: 0, EndTimestampMs: 10, Matchers: tc.matchers},
+				},
+			})
+			require.NoError(t, err)
+			request, err := http.NewRequest("POST", "/api/v1/read", bytes.NewReader(snappy.Encode(nil, requestBody)))
+			require.NoError(t, err)
+			request.Header.Set("X-Prometheus-Remote-Read-Version", "0.1.0")
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+			require.Equal(t, tc.expectedCode, recorder.Result().StatusCode)
+		})
+	}
+}
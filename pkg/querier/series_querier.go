@@ -0,0 +1,188 @@
+package querier
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/weaveworks/common/user"
+
+	"github.com/cortexproject/cortex/pkg/chunk"
+	"github.com/cortexproject/cortex/pkg/prom1/storage/metric"
+	"github.com/cortexproject/cortex/pkg/util"
+	"github.com/cortexproject/cortex/pkg/util/validation"
+)
+
+// chunkRefStore is implemented by chunk stores which can look up the chunks
+// of series in the index without fetching them.
+type chunkRefStore interface {
+	GetChunkRefs(ctx context.Context, from, through model.Time, matchers ...*labels.Matcher) ([][]chunk.Chunk, []*chunk.Fetcher, error)
+}
+
+// seriesQuerier answers series requests, which Prometheus makes with nil
+// SelectParams, with the union of the series in the ingesters and in the
+// chunk store over the querier's time range, rather than those in the
+// ingesters alone.  Other requests are passed on to the wrapped querier.
+type seriesQuerier struct {
+	storage.Querier
+
+	ctx         context.Context
+	mint, maxt  int64
+	distributor Distributor
+	chunkStore  ChunkStore
+	limits      *validation.Overrides
+}
+
+func newSeriesQuerier(ctx context.Context, mint, maxt int64, querier storage.Querier, distributor Distributor, chunkStore ChunkStore, limits *validation.Overrides) storage.Querier {
+	return seriesQuerier{
+		Querier:     querier,
+		ctx:         ctx,
+		mint:        mint,
+		maxt:        maxt,
+		distributor: distributor,
+		chunkStore:  chunkStore,
+		limits:      limits,
+	}
+}
+
+// Select implements storage.Querier.
+func (q seriesQuerier) Select(sp *storage.SelectParams, matchers ...*labels.Matcher) (storage.SeriesSet, storage.Warnings, error) {
+	if sp != nil {
+		return q.Querier.Select(sp, matchers...)
+	}
+
+	userID, err := user.ExtractOrgID(q.ctx)
+	if err != nil {
+		return nil, nil, promql.ErrStorage{Err: err}
+	}
+
+	type result struct {
+		metrics []metric.Metric
+		err     error
+	}
+	ingesters, store := make(chan result, 1), make(chan result, 1)
+	go func() {
+		ms, err := q.distributor.MetricsForLabelMatchers(q.ctx, model.Time(q.mint), model.Time(q.maxt), matchers...)
+		ingesters <- result{ms, err}
+	}()
+	go func() {
+		ms, err := q.storeSeries(userID, matchers)
+		store <- result{ms, err}
+	}()
+
+	maxSeries := q.limits.MaxSeriesResults(userID)
+	seen := map[model.Fingerprint]struct{}{}
+	var metrics []metric.Metric
+	for _, c := range []chan result{ingesters, store} {
+		r := <-c
+		if r.err != nil {
+			return nil, nil, promql.ErrStorage{Err: r.err}
+		}
+		for _, m := range r.metrics {
+			fp := m.Metric.Fingerprint()
+			if _, ok := seen[fp]; ok {
+				continue
+			}
+			seen[fp] = struct{}{}
+			metrics = append(metrics, m)
+		}
+		if maxSeries > 0 && len(metrics) > maxSeries {
+			return nil, nil, fmt.Errorf(validation.ErrTooManySeriesResults, maxSeries)
+		}
+	}
+	return metricsToSeriesSet(metrics), nil, nil
+}
+
+// Get implements ChunkStore for the chunk tar HTTP handler.
+func (q seriesQuerier) Get(ctx context.Context, from, through model.Time, matchers ...*labels.Matcher) ([]chunk.Chunk, error) {
+	store, ok := q.Querier.(ChunkStore)
+	if !ok {
+		return nil, fmt.Errorf("not supported")
+	}
+
+	return store.Get(ctx, from, through, matchers...)
+}
+
+// storeSeries returns the series in the chunk store matching matchers.  Where
+// the store can look up the chunks in its index, only one chunk of each
+// series is fetched, for its labels.
+//
+// Requests without a time range cover all time, which the store would reject
+// as longer than the user's maximum query length, so the range is clamped to
+// now and, with a maximum, to that much before the end.
+func (q seriesQuerier) storeSeries(userID string, matchers []*labels.Matcher) ([]metric.Metric, error) {
+	from, through := model.Time(q.mint), model.Time(q.maxt)
+	if now := model.Now(); through.After(now) {
+		through = now
+	}
+	if maxQueryLength := q.limits.MaxQueryLength(userID); maxQueryLength > 0 && through.Sub(from) > maxQueryLength {
+		from = through.Add(-maxQueryLength)
+	}
+	if through.Before(from) {
+		return nil, nil
+	}
+
+	var chunks []chunk.Chunk
+	if store, ok := q.chunkStore.(chunkRefStore); ok {
+		refs, fetchers, err := store.GetChunkRefs(q.ctx, from, through, matchers...)
+		if err != nil && err != chunk.ErrNotImplemented {
+			return nil, err
+		}
+		if err == nil {
+			for i, cs := range refs {
+				toFetch, keys := []chunk.Chunk{}, []string{}
+				seen := map[model.Fingerprint]struct{}{}
+				for _, c := range cs {
+					if _, ok := seen[c.Fingerprint]; ok {
+						continue
+					}
+					seen[c.Fingerprint] = struct{}{}
+					toFetch = append(toFetch, c)
+					keys = append(keys, c.ExternalKey())
+				}
+
+				fetched, err := fetchers[i].FetchChunks(q.ctx, toFetch, keys)
+				if err != nil {
+					return nil, err
+				}
+				chunks = append(chunks, fetched...)
+			}
+			return chunksToMetrics(chunks, matchers), nil
+		}
+		// Stores for older schemas can't look up chunk refs, so fall back to
+		// fetching all of the chunks.
+	}
+
+	chunks, err := q.chunkStore.Get(q.ctx, from, through, matchers...)
+	if err != nil {
+		return nil, err
+	}
+	return chunksToMetrics(chunks, matchers), nil
+}
+
+// chunksToMetrics returns the distinct series of chunks matching all of the
+// matchers, as the index lookups may not apply all of them.
+func chunksToMetrics(chunks []chunk.Chunk, matchers []*labels.Matcher) []metric.Metric {
+	seen := map[model.Fingerprint]struct{}{}
+	var metrics []metric.Metric
+outer:
+	for _, c := range chunks {
+		for _, m := range matchers {
+			if !m.Matches(c.Metric.Get(m.Name)) {
+				continue outer
+			}
+		}
+
+		m := util.LabelsToMetric(c.Metric)
+		fp := m.Fingerprint()
+		if _, ok := seen[fp]; ok {
+			continue
+		}
+		seen[fp] = struct{}{}
+		metrics = append(metrics, metric.Metric{Metric: m})
+	}
+	return metrics
+}
@@ -0,0 +1,108 @@
+package querier
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+
+	"github.com/cortexproject/cortex/pkg/chunk"
+	promchunk "github.com/cortexproject/cortex/pkg/chunk/encoding"
+	"github.com/cortexproject/cortex/pkg/prom1/storage/metric"
+	"github.com/cortexproject/cortex/pkg/util/flagext"
+	"github.com/cortexproject/cortex/pkg/util/validation"
+)
+
+func TestSeriesQuerier(t *testing.T) {
+	chunkFor := func(ls labels.Labels) chunk.Chunk {
+		c := mkChunk(t, 0, 100, time.Millisecond, promchunk.Bigchunk)
+		c.Metric = ls
+		return c
+	}
+	distributor := &mockDistributor{
+		metrics: []metric.Metric{
+			{Metric: model.Metric{model.MetricNameLabel: "foo", "a": "1"}},
+			{Metric: model.Metric{model.MetricNameLabel: "foo", "a": "2"}},
+		},
+	}
+	store := mockChunkStore{chunks: []chunk.Chunk{
+		// In the ingesters too.
+		chunkFor(labels.Labels{{Name: model.MetricNameLabel, Value: "foo"}, {Name: "a", Value: "2"}}),
+		chunkFor(labels.Labels{{Name: model.MetricNameLabel, Value: "foo"}, {Name: "a", Value: "3"}}),
+		chunkFor(labels.Labels{{Name: model.MetricNameLabel, Value: "foo"}, {Name: "a", Value: "3"}}),
+		// Not matching.
+		chunkFor(labels.Labels{{Name: model.MetricNameLabel, Value: "bar"}, {Name: "a", Value: "4"}}),
+	}}
+	matcher := &labels.Matcher{Type: labels.MatchEqual, Name: model.MetricNameLabel, Value: "foo"}
+	ctx := user.InjectOrgID(context.Background(), "1")
+
+	q := newSeriesQuerier(ctx, 0, 100, nil, distributor, store, defaultLimits(t))
+	set, _, err := q.Select(nil, matcher)
+	require.NoError(t, err)
+
+	var series []string
+	for set.Next() {
+		series = append(series, set.At().Labels().String())
+	}
+	require.NoError(t, set.Err())
+	require.Equal(t, []string{`{__name__="foo", a="1"}`, `{__name__="foo", a="2"}`, `{__name__="foo", a="3"}`}, series)
+
+	var limits validation.Limits
+	flagext.DefaultValues(&limits)
+	limits.MaxSeriesResults = 2
+	overrides, err := validation.NewOverrides(limits)
+	require.NoError(t, err)
+
+	q = newSeriesQuerier(ctx, 0, 100, nil, distributor, store, overrides)
+	_, _, err = q.Select(nil, matcher)
+	require.EqualError(t, err, "the series request matched more than the limit of 2 series; narrow the matchers or time range")
+}
+
+// chunkRefsStore records the time range of the lookups of the series in it.
+type chunkRefsStore struct {
+	mockChunkStore
+	refsErr       error
+	from, through model.Time
+}
+
+func (s *chunkRefsStore) Get(ctx context.Context, from, through model.Time, matchers ...*labels.Matcher) ([]chunk.Chunk, error) {
+	s.from, s.through = from, through
+	return s.mockChunkStore.Get(ctx, from, through, matchers...)
+}
+
+func (s *chunkRefsStore) GetChunkRefs(ctx context.Context, from, through model.Time, matchers ...*labels.Matcher) ([][]chunk.Chunk, []*chunk.Fetcher, error) {
+	s.from, s.through = from, through
+	return nil, nil, s.refsErr
+}
+
+func TestSeriesQuerierStoreTimeRange(t *testing.T) {
+	var limits validation.Limits
+	flagext.DefaultValues(&limits)
+	limits.MaxQueryLength = time.Hour
+	overrides, err := validation.NewOverrides(limits)
+	require.NoError(t, err)
+	matcher := &labels.Matcher{Type: labels.MatchEqual, Name: model.MetricNameLabel, Value: "foo"}
+	ctx := user.InjectOrgID(context.Background(), "1")
+
+	// Requests for all time are clamped to the maximum query length before
+	// now, and fall back to fetching the chunks of old schemas.
+	store := &chunkRefsStore{refsErr: chunk.ErrNotImplemented}
+	before := model.Now()
+	q := newSeriesQuerier(ctx, minTime, maxTime, nil, &mockDistributor{}, store, overrides)
+	_, _, err = q.Select(nil, matcher)
+	require.NoError(t, err)
+	require.False(t, store.through.Before(before))
+	require.False(t, store.through.After(model.Now()))
+	require.Equal(t, time.Hour, store.through.Sub(store.from))
+
+	// Other errors of looking up chunk refs fail the request.
+	store = &chunkRefsStore{refsErr: errors.New("index unavailable")}
+	q = newSeriesQuerier(ctx, 0, 100, nil, &mockDistributor{}, store, overrides)
+	_, _, err = q.Select(nil, matcher)
+	require.EqualError(t, err, "index unavailable")
+}
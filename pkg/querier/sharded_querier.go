@@ -0,0 +1,78 @@
+package querier
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cortexproject/cortex/pkg/chunk"
+	"github.com/cortexproject/cortex/pkg/querier/sharding"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/storage"
+)
+
+type shardedQuerier struct {
+	next storage.Querier
+}
+
+// newShardedQuerier wraps a storage.Querier, checking the shard matchers the
+// query frontend adds to sharded queries.  The matchers are passed on, for the
+// ingesters and the chunk store to only select the shard's series, and the
+// series selected are filtered too, as the chunk store can only tell which
+// shard most chunks are in from their keys.
+func newShardedQuerier(next storage.Querier) storage.Querier {
+	return shardedQuerier{next}
+}
+
+func (s shardedQuerier) Select(params *storage.SelectParams, matchers ...*labels.Matcher) (storage.SeriesSet, storage.Warnings, error) {
+	shard, _, err := sharding.FromMatchers(matchers)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	set, warnings, err := s.next.Select(params, matchers...)
+	if err != nil || shard == nil {
+		return set, warnings, err
+	}
+	return &shardedSeriesSet{
+		SeriesSet: set,
+		shard:     *shard,
+	}, warnings, nil
+}
+
+func (s shardedQuerier) LabelValues(name string) ([]string, error) {
+	return s.next.LabelValues(name)
+}
+
+func (s shardedQuerier) LabelNames() ([]string, error) {
+	return s.next.LabelNames()
+}
+
+func (s shardedQuerier) Close() error {
+	return s.next.Close()
+}
+
+// Get implements ChunkStore for the chunk tar HTTP handler.
+func (s shardedQuerier) Get(ctx context.Context, from, through model.Time, matchers ...*labels.Matcher) ([]chunk.Chunk, error) {
+	store, ok := s.next.(ChunkStore)
+	if !ok {
+		return nil, fmt.Errorf("not supported")
+	}
+
+	return store.Get(ctx, from, through, matchers...)
+}
+
+// shardedSeriesSet filters a storage.SeriesSet down to the series in a shard.
+type shardedSeriesSet struct {
+	storage.SeriesSet
+	shard sharding.Shard
+}
+
+func (s *shardedSeriesSet) Next() bool {
+	for s.SeriesSet.Next() {
+		if s.shard.Contains(s.SeriesSet.At().Labels()) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package querier
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cortexproject/cortex/pkg/querier/sharding"
+)
+
+type mockSelectQuerier struct {
+	storage.Querier
+	series   []storage.Series
+	matchers []*labels.Matcher
+}
+
+func (m *mockSelectQuerier) Select(_ *storage.SelectParams, matchers ...*labels.Matcher) (storage.SeriesSet, storage.Warnings, error) {
+	m.matchers = matchers
+	return newConcreteSeriesSet(m.series), nil, nil
+}
+
+func TestShardedQuerier(t *testing.T) {
+	var series []storage.Series
+	for i := 0; i < 20; i++ {
+		series = append(series, &concreteSeries{
+			labels:  labels.FromStrings("__name__", "foo", "i", fmt.Sprint(i)),
+			samples: []model.SamplePair{{Value: 1, Timestamp: 2}},
+		})
+	}
+	foo, err := labels.NewMatcher(labels.MatchEqual, "__name__", "foo")
+	require.NoError(t, err)
+
+	// Each series is returned for exactly one of the shards, and the shard
+	// matcher is passed down.
+	seen := map[string]int{}
+	for i := 0; i < 3; i++ {
+		next := &mockSelectQuerier{series: series}
+		shard := sharding.Shard{Index: i, Of: 3}.Matcher()
+		set, _, err := newShardedQuerier(next).Select(&storage.SelectParams{}, foo, shard)
+		require.NoError(t, err)
+		require.Equal(t, []*labels.Matcher{foo, shard}, next.matchers)
+		for set.Next() {
+			seen[set.At().Labels().String()]++
+		}
+		require.NoError(t, set.Err())
+	}
+	require.Len(t, seen, len(series))
+	for _, count := range seen {
+		require.Equal(t, 1, count)
+	}
+
+	// Without a shard matcher, all series are returned.
+	set, _, err := newShardedQuerier(&mockSelectQuerier{series: series}).Select(&storage.SelectParams{}, foo)
+	require.NoError(t, err)
+	count := 0
+	for set.Next() {
+		count++
+	}
+	require.Equal(t, len(series), count)
+}
@@ -0,0 +1,76 @@
+// Package sharding lets the query frontend split a query into partial queries
+// over disjoint subsets of series, which the queriers evaluate independently.
+package sharding
+
+import (
+	"fmt"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/pkg/labels"
+
+	"github.com/cortexproject/cortex/pkg/ingester/client"
+)
+
+// ShardLabel is the name of the pseudo-label used to select a shard of series.
+// It never matches stored labels: the ingesters and the chunk store remove it
+// from the matchers and only select the series in the shard.
+const ShardLabel = "__cortex_shard__"
+
+// maxMappedFP is the largest of the fingerprints ingesters give series whose
+// fingerprints collide, as in their mapper.
+const maxMappedFP = 1 << 20
+
+// Shard selects the series whose fingerprints are Index, modulo Of.
+type Shard struct {
+	Index, Of int
+}
+
+func (s Shard) String() string {
+	return fmt.Sprintf("%d_of_%d", s.Index, s.Of)
+}
+
+// Matcher returns the matcher selecting this shard.
+func (s Shard) Matcher() *labels.Matcher {
+	return &labels.Matcher{
+		Type:  labels.MatchEqual,
+		Name:  ShardLabel,
+		Value: s.String(),
+	}
+}
+
+// Contains returns true if the series with the given labels is in this shard.
+func (s Shard) Contains(ls labels.Labels) bool {
+	fp := client.FastFingerprint(client.FromLabelsToLabelAdapaters(ls))
+	return uint64(fp)%uint64(s.Of) == uint64(s.Index)
+}
+
+// MayContain returns false if the series whose chunks have the fingerprint
+// isn't in this shard.  Chunks are keyed by the fingerprints the ingesters
+// give their series, which are those of their labels unless they collide, so
+// chunks with mapped fingerprints may be in any shard.
+func (s Shard) MayContain(fp model.Fingerprint) bool {
+	return fp <= maxMappedFP || uint64(fp)%uint64(s.Of) == uint64(s.Index)
+}
+
+// FromMatchers returns the shard selected by matchers, if any, and the
+// remaining matchers.
+func FromMatchers(matchers []*labels.Matcher) (*Shard, []*labels.Matcher, error) {
+	var shard *Shard
+	rest := make([]*labels.Matcher, 0, len(matchers))
+	for _, m := range matchers {
+		if m.Name != ShardLabel {
+			rest = append(rest, m)
+			continue
+		}
+
+		if m.Type != labels.MatchEqual || shard != nil {
+			return nil, nil, fmt.Errorf("invalid %s matcher %s", ShardLabel, m)
+		}
+		var s Shard
+		if _, err := fmt.Sscanf(m.Value, "%d_of_%d", &s.Index, &s.Of); err != nil || s.Of <= 0 || s.Index < 0 || s.Index >= s.Of {
+			return nil, nil, fmt.Errorf("invalid %s matcher %s", ShardLabel, m)
+		}
+		shard = &s
+	}
+	return shard, rest, nil
+}
@@ -0,0 +1,56 @@
+package sharding
+
+import (
+	"testing"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cortexproject/cortex/pkg/ingester/client"
+)
+
+func TestFromMatchers(t *testing.T) {
+	foo, err := labels.NewMatcher(labels.MatchEqual, "foo", "bar")
+	require.NoError(t, err)
+
+	shard, rest, err := FromMatchers([]*labels.Matcher{foo, Shard{Index: 3, Of: 16}.Matcher()})
+	require.NoError(t, err)
+	require.Equal(t, &Shard{Index: 3, Of: 16}, shard)
+	require.Equal(t, []*labels.Matcher{foo}, rest)
+
+	shard, rest, err = FromMatchers([]*labels.Matcher{foo})
+	require.NoError(t, err)
+	require.Nil(t, shard)
+	require.Equal(t, []*labels.Matcher{foo}, rest)
+
+	for _, value := range []string{"", "16_of_16", "-1_of_16", "1_of_0", "one_of_two"} {
+		_, _, err = FromMatchers([]*labels.Matcher{{Type: labels.MatchEqual, Name: ShardLabel, Value: value}})
+		require.Error(t, err, value)
+	}
+}
+
+func TestShardContains(t *testing.T) {
+	// Every series is in exactly one shard.
+	for i := 0; i < 100; i++ {
+		ls := labels.FromStrings("__name__", "foo", "i", string(rune('a'+i%26)), "j", string(rune('a'+i/26)))
+		found := 0
+		for j := 0; j < 8; j++ {
+			if (Shard{Index: j, Of: 8}).Contains(ls) {
+				found++
+			}
+		}
+		require.Equal(t, 1, found)
+	}
+}
+
+func TestShardMayContain(t *testing.T) {
+	ls := labels.FromStrings("__name__", "foo", "i", "a")
+	fp := client.FastFingerprint(client.FromLabelsToLabelAdapaters(ls))
+	for j := 0; j < 8; j++ {
+		shard := Shard{Index: j, Of: 8}
+		require.Equal(t, shard.Contains(ls), shard.MayContain(fp))
+		// Mapped fingerprints may be in any shard.
+		require.True(t, shard.MayContain(model.Fingerprint(j+1)))
+	}
+}
@@ -0,0 +1,156 @@
+package stats
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/weaveworks/common/user"
+)
+
+// QueryLogEntry is a query the querier evaluated, with its stats.
+type QueryLogEntry struct {
+	Start time.Time
+	User  string
+	Path  string
+	Query string
+	Stats Stats
+}
+
+// QueryLog keeps the stats of the last queries evaluated, for debugging.  A
+// nil *QueryLog keeps nothing.
+type QueryLog struct {
+	mtx     sync.Mutex
+	entries []QueryLogEntry
+	next    int
+	full    bool
+}
+
+// NewQueryLog returns a QueryLog keeping the last size queries, or nil if
+// size isn't positive.
+func NewQueryLog(size int) *QueryLog {
+	if size <= 0 {
+		return nil
+	}
+	return &QueryLog{entries: make([]QueryLogEntry, size)}
+}
+
+func (l *QueryLog) add(e QueryLogEntry) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	l.entries[l.next] = e
+	l.next = (l.next + 1) % len(l.entries)
+	if l.next == 0 {
+		l.full = true
+	}
+}
+
+// Entries returns the queries kept, most recent first.
+func (l *QueryLog) Entries() []QueryLogEntry {
+	if l == nil {
+		return nil
+	}
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	n := l.next
+	if l.full {
+		n = len(l.entries)
+	}
+	result := make([]QueryLogEntry, 0, n)
+	for i := 1; i <= n; i++ {
+		result = append(result, l.entries[(l.next-i+len(l.entries))%len(l.entries)])
+	}
+	return result
+}
+
+// Wrap collects Stats for each request like Middleware, and keeps those of
+// the requests with a query in the log.
+func (l *QueryLog) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		s, ctx := AddToContext(r.Context())
+
+		// The form is parsed before the request is copied, for the query to
+		// be read from POST requests' bodies here too.
+		var query string
+		if l != nil {
+			query = r.FormValue("query")
+		}
+
+		next.ServeHTTP(&responseWriter{
+			ResponseWriter: w,
+			stats:          s,
+			start:          start,
+		}, r.WithContext(ctx))
+
+		if query != "" {
+			userID, _ := user.ExtractOrgID(r.Context())
+			l.add(QueryLogEntry{
+				Start: start,
+				User:  userID,
+				Path:  r.URL.Path,
+				Query: query,
+				Stats: s.Load(),
+			})
+		}
+	})
+}
+
+type queryLogEntryJSON struct {
+	Start             time.Time `json:"start"`
+	User              string    `json:"user"`
+	Path              string    `json:"path"`
+	Query             string    `json:"query"`
+	FetchedSeries     int64     `json:"fetched_series"`
+	FetchedChunks     int64     `json:"fetched_chunks"`
+	FetchedChunkBytes int64     `json:"fetched_chunk_bytes"`
+	FetchedSamples    int64     `json:"fetched_samples"`
+	WallTime          string    `json:"wall_time"`
+	IngesterSeries    int64     `json:"ingester_series"`
+	IngesterTime      string    `json:"ingester_time"`
+	StoreSeries       int64     `json:"store_series"`
+	StoreTime         string    `json:"store_time"`
+}
+
+// ServeHTTP serves the queries of the request's user kept as JSON, most
+// recent first.
+func (l *QueryLog) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	userID, err := user.ExtractOrgID(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	entries := l.Entries()
+	result := make([]queryLogEntryJSON, 0, len(entries))
+	for _, e := range entries {
+		if e.User != userID {
+			continue
+		}
+		result = append(result, queryLogEntryJSON{
+			Start:             e.Start,
+			User:              e.User,
+			Path:              e.Path,
+			Query:             e.Query,
+			FetchedSeries:     e.Stats.FetchedSeries,
+			FetchedChunks:     e.Stats.FetchedChunks,
+			FetchedChunkBytes: e.Stats.FetchedChunkBytes,
+			FetchedSamples:    e.Stats.FetchedSamples,
+			WallTime:          e.Stats.WallTime.String(),
+			IngesterSeries:    e.Stats.IngesterSeries,
+			IngesterTime:      e.Stats.IngesterTime.String(),
+			StoreSeries:       e.Stats.StoreSeries,
+			StoreTime:         e.Stats.StoreTime.String(),
+		})
+	}
+
+	b, err := json.Marshal(result)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(b)
+}
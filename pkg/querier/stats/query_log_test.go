@@ -0,0 +1,67 @@
+package stats
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+)
+
+func TestQueryLog(t *testing.T) {
+	require.Nil(t, NewQueryLog(0))
+
+	l := NewQueryLog(2)
+	handler := l.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		FromContext(r.Context()).AddSeries(len(r.Form.Get("query")))
+		w.Write([]byte("ok"))
+	}))
+
+	for _, query := range []string{"a", "bb", "ccc"} {
+		req := httptest.NewRequest("POST", "/api/prom/api/v1/query", strings.NewReader(url.Values{"query": {query}}.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		userID := "1"
+		if query == "bb" {
+			userID = "2"
+		}
+		req = req.WithContext(user.InjectOrgID(req.Context(), userID))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		require.Equal(t, "ok", rec.Body.String())
+	}
+	// Requests without a query aren't kept.
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/prom/api/v1/labels", nil))
+
+	// Only the last two queries are kept, most recent first.
+	entries := l.Entries()
+	require.Len(t, entries, 2)
+	for i, query := range []string{"ccc", "bb"} {
+		require.Equal(t, query, entries[i].Query)
+		require.Equal(t, "/api/prom/api/v1/query", entries[i].Path)
+		require.Equal(t, int64(len(query)), entries[i].Stats.FetchedSeries)
+		require.NotZero(t, entries[i].Stats.WallTime)
+	}
+
+	require.Equal(t, "1", entries[0].User)
+	require.Equal(t, "2", entries[1].User)
+
+	// Users are only served their own queries.
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/query_stats", nil)
+	l.ServeHTTP(rec, req.WithContext(user.InjectOrgID(req.Context(), "1")))
+	var served []map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &served))
+	require.Len(t, served, 1)
+	require.Equal(t, "ccc", served[0]["query"])
+	require.Equal(t, "1", served[0]["user"])
+	require.Equal(t, float64(3), served[0]["fetched_series"])
+
+	rec = httptest.NewRecorder()
+	l.ServeHTTP(rec, httptest.NewRequest("GET", "/query_stats", nil))
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+}
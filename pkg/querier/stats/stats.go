@@ -0,0 +1,225 @@
+package stats
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// HeaderName is the HTTP header queriers report a query's statistics in, and
+// the frontend reports the totals across all sub-queries in.
+const HeaderName = "X-Cortex-Query-Stats"
+
+type contextKey int
+
+const ctxKey = contextKey(0)
+
+// Stats about the work done evaluating a query.  All methods are safe to call
+// concurrently, and on a nil *Stats, in which case they do nothing.
+type Stats struct {
+	FetchedSeries     int64
+	FetchedChunks     int64
+	FetchedChunkBytes int64
+	FetchedSamples    int64
+	WallTime          time.Duration
+
+	// The series returned by the ingesters and the chunk store, and the time
+	// spent waiting for them.  As ingesters and the store are queried
+	// concurrently, and series can come from both, these don't add up to the
+	// totals above.
+	IngesterSeries int64
+	IngesterTime   time.Duration
+	StoreSeries    int64
+	StoreTime      time.Duration
+}
+
+// AddToContext returns a context carrying a new, empty Stats.
+func AddToContext(ctx context.Context) (*Stats, context.Context) {
+	s := &Stats{}
+	return s, context.WithValue(ctx, ctxKey, s)
+}
+
+// FromContext returns the Stats in the context, or nil if there is none.
+func FromContext(ctx context.Context) *Stats {
+	s, _ := ctx.Value(ctxKey).(*Stats)
+	return s
+}
+
+// AddSeries adds n to the number of series fetched.
+func (s *Stats) AddSeries(n int) {
+	if s != nil {
+		atomic.AddInt64(&s.FetchedSeries, int64(n))
+	}
+}
+
+// AddChunks adds n to the number of chunks fetched.
+func (s *Stats) AddChunks(n int) {
+	if s != nil {
+		atomic.AddInt64(&s.FetchedChunks, int64(n))
+	}
+}
+
+// AddChunkBytes adds n to the size of the chunks fetched.
+func (s *Stats) AddChunkBytes(n int) {
+	if s != nil {
+		atomic.AddInt64(&s.FetchedChunkBytes, int64(n))
+	}
+}
+
+// AddSamples adds n to the number of samples fetched.
+func (s *Stats) AddSamples(n int) {
+	if s != nil {
+		atomic.AddInt64(&s.FetchedSamples, int64(n))
+	}
+}
+
+// AddWallTime adds d to the time spent evaluating the query.
+func (s *Stats) AddWallTime(d time.Duration) {
+	if s != nil {
+		atomic.AddInt64((*int64)(&s.WallTime), int64(d))
+	}
+}
+
+// AddIngesterSeries adds n to the number of series returned by the ingesters.
+func (s *Stats) AddIngesterSeries(n int) {
+	if s != nil {
+		atomic.AddInt64(&s.IngesterSeries, int64(n))
+	}
+}
+
+// AddIngesterTime adds d to the time spent querying the ingesters.
+func (s *Stats) AddIngesterTime(d time.Duration) {
+	if s != nil {
+		atomic.AddInt64((*int64)(&s.IngesterTime), int64(d))
+	}
+}
+
+// AddStoreSeries adds n to the number of series returned by the chunk store.
+func (s *Stats) AddStoreSeries(n int) {
+	if s != nil {
+		atomic.AddInt64(&s.StoreSeries, int64(n))
+	}
+}
+
+// AddStoreTime adds d to the time spent querying the chunk store.
+func (s *Stats) AddStoreTime(d time.Duration) {
+	if s != nil {
+		atomic.AddInt64((*int64)(&s.StoreTime), int64(d))
+	}
+}
+
+// Merge adds the other Stats to these.
+func (s *Stats) Merge(other *Stats) {
+	if s == nil || other == nil {
+		return
+	}
+	o := other.Load()
+	s.AddSeries(int(o.FetchedSeries))
+	s.AddChunks(int(o.FetchedChunks))
+	s.AddChunkBytes(int(o.FetchedChunkBytes))
+	s.AddSamples(int(o.FetchedSamples))
+	s.AddWallTime(o.WallTime)
+	s.AddIngesterSeries(int(o.IngesterSeries))
+	s.AddIngesterTime(o.IngesterTime)
+	s.AddStoreSeries(int(o.StoreSeries))
+	s.AddStoreTime(o.StoreTime)
+}
+
+// Load returns a consistent copy of the Stats.
+func (s *Stats) Load() Stats {
+	if s == nil {
+		return Stats{}
+	}
+	return Stats{
+		FetchedSeries:     atomic.LoadInt64(&s.FetchedSeries),
+		FetchedChunks:     atomic.LoadInt64(&s.FetchedChunks),
+		FetchedChunkBytes: atomic.LoadInt64(&s.FetchedChunkBytes),
+		FetchedSamples:    atomic.LoadInt64(&s.FetchedSamples),
+		WallTime:          time.Duration(atomic.LoadInt64((*int64)(&s.WallTime))),
+		IngesterSeries:    atomic.LoadInt64(&s.IngesterSeries),
+		IngesterTime:      time.Duration(atomic.LoadInt64((*int64)(&s.IngesterTime))),
+		StoreSeries:       atomic.LoadInt64(&s.StoreSeries),
+		StoreTime:         time.Duration(atomic.LoadInt64((*int64)(&s.StoreTime))),
+	}
+}
+
+// Encode the Stats for use as the value of the HeaderName header.
+func (s *Stats) Encode() string {
+	o := s.Load()
+	return fmt.Sprintf("series=%d;chunks=%d;chunk_bytes=%d;samples=%d;wall_time=%s;ingester_series=%d;ingester_time=%s;store_series=%d;store_time=%s",
+		o.FetchedSeries, o.FetchedChunks, o.FetchedChunkBytes, o.FetchedSamples, o.WallTime,
+		o.IngesterSeries, o.IngesterTime, o.StoreSeries, o.StoreTime)
+}
+
+// Decode parses the value of a HeaderName header.  Unknown fields are ignored
+// so new ones can be added without breaking older frontends.
+func Decode(value string) (*Stats, error) {
+	s := &Stats{}
+	for _, field := range strings.Split(value, ";") {
+		parts := strings.SplitN(field, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid stats field %q", field)
+		}
+
+		var err error
+		switch parts[0] {
+		case "series":
+			s.FetchedSeries, err = strconv.ParseInt(parts[1], 10, 64)
+		case "chunks":
+			s.FetchedChunks, err = strconv.ParseInt(parts[1], 10, 64)
+		case "chunk_bytes":
+			s.FetchedChunkBytes, err = strconv.ParseInt(parts[1], 10, 64)
+		case "samples":
+			s.FetchedSamples, err = strconv.ParseInt(parts[1], 10, 64)
+		case "wall_time":
+			s.WallTime, err = time.ParseDuration(parts[1])
+		case "ingester_series":
+			s.IngesterSeries, err = strconv.ParseInt(parts[1], 10, 64)
+		case "ingester_time":
+			s.IngesterTime, err = time.ParseDuration(parts[1])
+		case "store_series":
+			s.StoreSeries, err = strconv.ParseInt(parts[1], 10, 64)
+		case "store_time":
+			s.StoreTime, err = time.ParseDuration(parts[1])
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid stats field %q: %v", field, err)
+		}
+	}
+	return s, nil
+}
+
+// Middleware collects Stats for each request and reports them in the
+// HeaderName response header.  As the header has to be written before the
+// body it is set when the response starts, which for the Prometheus API is
+// after the query has been evaluated.
+func Middleware(next http.Handler) http.Handler {
+	return (*QueryLog)(nil).Wrap(next)
+}
+
+type responseWriter struct {
+	http.ResponseWriter
+	stats       *Stats
+	start       time.Time
+	wroteHeader bool
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.stats.AddWallTime(time.Since(w.start))
+		w.Header().Set(HeaderName, w.stats.Encode())
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
@@ -0,0 +1,71 @@
+package stats
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	s := &Stats{
+		FetchedSeries:     1,
+		FetchedChunks:     2,
+		FetchedChunkBytes: 1024,
+		FetchedSamples:    3,
+		WallTime:          1500 * time.Millisecond,
+		IngesterSeries:    1,
+		IngesterTime:      time.Second,
+		StoreSeries:       1,
+		StoreTime:         250 * time.Millisecond,
+	}
+	decoded, err := Decode(s.Encode())
+	require.NoError(t, err)
+	require.Equal(t, s, decoded)
+
+	// Unknown fields are ignored, malformed ones aren't.
+	decoded, err = Decode("series=4;future=5")
+	require.NoError(t, err)
+	require.Equal(t, &Stats{FetchedSeries: 4}, decoded)
+
+	_, err = Decode("series=four")
+	require.Error(t, err)
+	_, err = Decode("series")
+	require.Error(t, err)
+}
+
+func TestMerge(t *testing.T) {
+	s := &Stats{FetchedSeries: 1, WallTime: time.Second}
+	s.Merge(&Stats{FetchedSeries: 2, FetchedChunks: 3, WallTime: time.Second})
+	s.Merge(nil)
+	require.Equal(t, Stats{FetchedSeries: 3, FetchedChunks: 3, WallTime: 2 * time.Second}, s.Load())
+
+	// Methods on nil Stats, as returned from a context without any, do nothing.
+	var none *Stats
+	none.AddSamples(1)
+	none.Merge(s)
+	require.Equal(t, Stats{}, none.Load())
+}
+
+func TestMiddleware(t *testing.T) {
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s := FromContext(r.Context())
+		s.AddSeries(1)
+		s.AddChunks(2)
+		s.AddSamples(3)
+		w.Write([]byte("ok"))
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "ok", rec.Body.String())
+
+	s, err := Decode(rec.Header().Get(HeaderName))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), s.FetchedSeries)
+	require.Equal(t, int64(2), s.FetchedChunks)
+	require.Equal(t, int64(3), s.FetchedSamples)
+}
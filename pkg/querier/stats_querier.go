@@ -0,0 +1,161 @@
+package querier
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cortexproject/cortex/pkg/chunk"
+	"github.com/cortexproject/cortex/pkg/querier/stats"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/storage"
+)
+
+type statsQuerier struct {
+	next  storage.Querier
+	stats *stats.Stats
+}
+
+// newStatsQuerier wraps a storage.Querier, counting the series and samples
+// read through it in the query's stats, if it has any.
+func newStatsQuerier(ctx context.Context, next storage.Querier) storage.Querier {
+	s := stats.FromContext(ctx)
+	if s == nil {
+		return next
+	}
+	return statsQuerier{next: next, stats: s}
+}
+
+func (s statsQuerier) Select(params *storage.SelectParams, matchers ...*labels.Matcher) (storage.SeriesSet, storage.Warnings, error) {
+	set, warnings, err := s.next.Select(params, matchers...)
+	if err != nil {
+		return set, warnings, err
+	}
+	return &statsSeriesSet{
+		SeriesSet: set,
+		stats:     s.stats,
+	}, warnings, nil
+}
+
+func (s statsQuerier) LabelValues(name string) ([]string, error) {
+	return s.next.LabelValues(name)
+}
+
+func (s statsQuerier) LabelNames() ([]string, error) {
+	return s.next.LabelNames()
+}
+
+func (s statsQuerier) Close() error {
+	return s.next.Close()
+}
+
+// Get implements ChunkStore for the chunk tar HTTP handler.
+func (s statsQuerier) Get(ctx context.Context, from, through model.Time, matchers ...*labels.Matcher) ([]chunk.Chunk, error) {
+	store, ok := s.next.(ChunkStore)
+	if !ok {
+		return nil, fmt.Errorf("not supported")
+	}
+
+	return store.Get(ctx, from, through, matchers...)
+}
+
+// storeStatsChunkStore counts the series and chunks fetched from the chunk
+// store, and the time spent fetching them, in the query's stats.
+type storeStatsChunkStore struct {
+	ChunkStore
+}
+
+func (s storeStatsChunkStore) Get(ctx context.Context, from, through model.Time, matchers ...*labels.Matcher) ([]chunk.Chunk, error) {
+	start := time.Now()
+	chunks, err := s.ChunkStore.Get(ctx, from, through, matchers...)
+	st := stats.FromContext(ctx)
+	st.AddStoreTime(time.Since(start))
+	if err != nil {
+		return nil, err
+	}
+
+	series := map[model.Fingerprint]struct{}{}
+	for _, c := range chunks {
+		series[c.Fingerprint] = struct{}{}
+	}
+	st.AddStoreSeries(len(series))
+	st.AddChunks(len(chunks))
+	st.AddChunkBytes(chunksSize(chunks))
+	return chunks, nil
+}
+
+func chunksSize(chunks []chunk.Chunk) int {
+	size := 0
+	for _, c := range chunks {
+		size += c.Data.Size()
+	}
+	return size
+}
+
+type statsSeriesSet struct {
+	storage.SeriesSet
+	stats *stats.Stats
+}
+
+func (s *statsSeriesSet) Next() bool {
+	if !s.SeriesSet.Next() {
+		return false
+	}
+	s.stats.AddSeries(1)
+	return true
+}
+
+func (s *statsSeriesSet) At() storage.Series {
+	return statsSeries{
+		Series: s.SeriesSet.At(),
+		stats:  s.stats,
+	}
+}
+
+type statsSeries struct {
+	storage.Series
+	stats *stats.Stats
+}
+
+func (s statsSeries) Iterator() storage.SeriesIterator {
+	return &statsIterator{
+		SeriesIterator: s.Series.Iterator(),
+		stats:          s.stats,
+	}
+}
+
+// statsIterator counts the samples the query engine reads.  Samples skipped
+// by a Seek are not counted, and neither are repeated Seeks which don't move
+// the iterator.
+type statsIterator struct {
+	storage.SeriesIterator
+	stats   *stats.Stats
+	started bool
+	last    int64
+}
+
+func (s *statsIterator) Seek(t int64) bool {
+	if !s.SeriesIterator.Seek(t) {
+		return false
+	}
+	s.count()
+	return true
+}
+
+func (s *statsIterator) Next() bool {
+	if !s.SeriesIterator.Next() {
+		return false
+	}
+	s.count()
+	return true
+}
+
+func (s *statsIterator) count() {
+	t, _ := s.SeriesIterator.At()
+	if s.started && t == s.last {
+		return
+	}
+	s.started, s.last = true, t
+	s.stats.AddSamples(1)
+}
@@ -0,0 +1,64 @@
+package querier
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/stretchr/testify/require"
+
+	promchunk "github.com/cortexproject/cortex/pkg/chunk/encoding"
+	"github.com/cortexproject/cortex/pkg/querier/stats"
+)
+
+func TestStatsQuerier(t *testing.T) {
+	series := []storage.Series{
+		&concreteSeries{
+			labels:  labels.FromStrings("__name__", "foo", "i", "0"),
+			samples: []model.SamplePair{{Value: 1, Timestamp: 1}, {Value: 2, Timestamp: 2}, {Value: 3, Timestamp: 3}},
+		},
+		&concreteSeries{
+			labels:  labels.FromStrings("__name__", "foo", "i", "1"),
+			samples: []model.SamplePair{{Value: 1, Timestamp: 1}},
+		},
+	}
+
+	// Without stats in the context, the querier isn't wrapped.
+	next := &mockSelectQuerier{series: series}
+	require.Equal(t, next, newStatsQuerier(context.Background(), next))
+
+	s, ctx := stats.AddToContext(context.Background())
+	set, _, err := newStatsQuerier(ctx, next).Select(&storage.SelectParams{})
+	require.NoError(t, err)
+	for set.Next() {
+		it := set.At().Iterator()
+		// Seeking to the sample the iterator is already at doesn't count it twice.
+		for ok := it.Seek(0); ok; ok = it.Next() {
+			ts, _ := it.At()
+			require.True(t, it.Seek(ts))
+		}
+	}
+	require.NoError(t, set.Err())
+
+	require.Equal(t, int64(2), s.FetchedSeries)
+	require.Equal(t, int64(4), s.FetchedSamples)
+}
+
+func TestStoreStatsChunkStore(t *testing.T) {
+	store, _ := makeMockChunkStore(t, 3, promchunk.Bigchunk)
+
+	s, ctx := stats.AddToContext(context.Background())
+	chunks, err := storeStatsChunkStore{store}.Get(ctx, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, chunks, 3)
+
+	// All the chunks are of the same series.
+	loaded := s.Load()
+	require.Equal(t, int64(1), loaded.StoreSeries)
+	require.Equal(t, int64(3), loaded.FetchedChunks)
+	require.Equal(t, int64(chunksSize(store.chunks)), loaded.FetchedChunkBytes)
+	require.NotZero(t, loaded.FetchedChunkBytes)
+	require.Zero(t, loaded.IngesterSeries)
+}
@@ -0,0 +1,81 @@
+package querier
+
+import (
+	"net/http"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/common/version"
+
+	"github.com/cortexproject/cortex/pkg/util/validation"
+)
+
+// BuildInfo is the version of Cortex served by BuildInfoHandler.
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Revision  string `json:"revision"`
+	Branch    string `json:"branch"`
+	BuildUser string `json:"buildUser"`
+	BuildDate string `json:"buildDate"`
+	GoVersion string `json:"goVersion"`
+}
+
+// NewBuildInfo returns the version this binary was built with.
+func NewBuildInfo() BuildInfo {
+	return BuildInfo{
+		Version:   version.Version,
+		Revision:  version.Revision,
+		Branch:    version.Branch,
+		BuildUser: version.BuildUser,
+		BuildDate: version.BuildDate,
+		GoVersion: version.GoVersion,
+	}
+}
+
+// BuildInfoHandler serves the Prometheus API's /api/v1/status/buildinfo, with
+// Cortex's version, which Grafana uses to detect the features supported.
+func BuildInfoHandler(info BuildInfo) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeSuccess(w, r, info)
+	})
+}
+
+type runtimeInfo struct {
+	StartTime           time.Time `json:"startTime"`
+	CWD                 string    `json:"CWD"`
+	ReloadConfigSuccess bool      `json:"reloadConfigSuccess"`
+	LastConfigTime      time.Time `json:"lastConfigTime"`
+	GoroutineCount      int       `json:"goroutineCount"`
+	GOMAXPROCS          int       `json:"GOMAXPROCS"`
+	GOGC                string    `json:"GOGC"`
+	GODEBUG             string    `json:"GODEBUG"`
+	StorageRetention    string    `json:"storageRetention"`
+}
+
+// RuntimeInfoHandler serves the Prometheus API's /api/v1/status/runtimeinfo.
+// The configuration reported as reloaded is the per-tenant overrides, and
+// the storage retention is the table manager's, 0 meaning chunks are kept
+// forever.
+func RuntimeInfoHandler(retention time.Duration, overrides *validation.Overrides) http.Handler {
+	start := time.Now()
+	cwd, err := os.Getwd()
+	if err != nil {
+		cwd = err.Error()
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastReload, successful := overrides.LastReload()
+		writeSuccess(w, r, runtimeInfo{
+			StartTime:           start,
+			CWD:                 cwd,
+			ReloadConfigSuccess: successful,
+			LastConfigTime:      lastReload,
+			GoroutineCount:      runtime.NumGoroutine(),
+			GOMAXPROCS:          runtime.GOMAXPROCS(0),
+			GOGC:                os.Getenv("GOGC"),
+			GODEBUG:             os.Getenv("GODEBUG"),
+			StorageRetention:    model.Duration(retention).String(),
+		})
+	})
+}
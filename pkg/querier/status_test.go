@@ -0,0 +1,40 @@
+package querier
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildInfoHandler(t *testing.T) {
+	rec := httptest.NewRecorder()
+	BuildInfoHandler(BuildInfo{Version: "1.2.3", Revision: "abcdef"}).ServeHTTP(rec, httptest.NewRequest("GET", "/api/prom/api/v1/status/buildinfo", nil))
+
+	var resp struct {
+		Status string
+		Data   BuildInfo
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.Equal(t, "success", resp.Status)
+	require.Equal(t, "1.2.3", resp.Data.Version)
+	require.Equal(t, "abcdef", resp.Data.Revision)
+}
+
+func TestRuntimeInfoHandler(t *testing.T) {
+	rec := httptest.NewRecorder()
+	RuntimeInfoHandler(30*24*time.Hour, defaultLimits(t)).ServeHTTP(rec, httptest.NewRequest("GET", "/api/prom/api/v1/status/runtimeinfo", nil))
+
+	var resp struct {
+		Status string
+		Data   runtimeInfo
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.Equal(t, "success", resp.Status)
+	require.Equal(t, "30d", resp.Data.StorageRetention)
+	require.True(t, resp.Data.ReloadConfigSuccess)
+	require.False(t, resp.Data.LastConfigTime.IsZero())
+	require.NotZero(t, resp.Data.GoroutineCount)
+}
@@ -0,0 +1,187 @@
+package querier
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/weaveworks/common/user"
+
+	"github.com/cortexproject/cortex/pkg/util/validation"
+)
+
+const (
+	// TenantLabel is the label identifying the tenant of each series returned
+	// by federated queries.
+	TenantLabel = "__tenant_id__"
+
+	tenantSeparator = validation.TenantSeparator
+)
+
+// newMergeQueryable wraps a storage.Queryable so that queries whose org ID
+// lists several tenants, separated by '|', query each of them and merge the
+// results, with a __tenant_id__ label to tell them apart.  Matchers on the
+// __tenant_id__ label select which of the tenants are queried.  Queries for a
+// single tenant are passed on unchanged.
+func newMergeQueryable(upstream storage.Queryable) storage.Queryable {
+	return storage.QueryableFunc(func(ctx context.Context, mint, maxt int64) (storage.Querier, error) {
+		userID, err := user.ExtractOrgID(ctx)
+		if err != nil || !strings.Contains(userID, tenantSeparator) {
+			return upstream.Querier(ctx, mint, maxt)
+		}
+
+		q := mergeQuerier{}
+		for _, tenant := range tenantIDs(userID) {
+			querier, err := upstream.Querier(user.InjectOrgID(ctx, tenant), mint, maxt)
+			if err != nil {
+				q.Close()
+				return nil, err
+			}
+			q.tenants = append(q.tenants, tenant)
+			q.queriers = append(q.queriers, querier)
+		}
+		return q, nil
+	})
+}
+
+// tenantIDs returns the distinct, non-empty tenants of a federated org ID,
+// sorted.
+func tenantIDs(userID string) []string {
+	seen := map[string]struct{}{}
+	var tenants []string
+	for _, tenant := range strings.Split(userID, tenantSeparator) {
+		if _, ok := seen[tenant]; ok || tenant == "" {
+			continue
+		}
+		seen[tenant] = struct{}{}
+		tenants = append(tenants, tenant)
+	}
+	sort.Strings(tenants)
+	return tenants
+}
+
+type mergeQuerier struct {
+	tenants  []string
+	queriers []storage.Querier
+}
+
+// Select implements storage.Querier.
+func (q mergeQuerier) Select(sp *storage.SelectParams, matchers ...*labels.Matcher) (storage.SeriesSet, storage.Warnings, error) {
+	var tenantMatchers, others []*labels.Matcher
+	for _, m := range matchers {
+		if m.Name == TenantLabel {
+			tenantMatchers = append(tenantMatchers, m)
+		} else {
+			others = append(others, m)
+		}
+	}
+
+	var sets []storage.SeriesSet
+	var warnings storage.Warnings
+	for i, tenant := range q.tenants {
+		if !matchesTenant(tenant, tenantMatchers) {
+			continue
+		}
+		set, ws, err := q.queriers[i].Select(sp, others...)
+		if err != nil {
+			return nil, nil, err
+		}
+		warnings = append(warnings, ws...)
+		sets = append(sets, &tenantSeriesSet{SeriesSet: set, tenant: tenant})
+	}
+	if len(sets) == 0 {
+		return newConcreteSeriesSet(nil), warnings, nil
+	}
+	return storage.NewMergeSeriesSet(sets, nil), warnings, nil
+}
+
+func matchesTenant(tenant string, matchers []*labels.Matcher) bool {
+	for _, m := range matchers {
+		if !m.Matches(tenant) {
+			return false
+		}
+	}
+	return true
+}
+
+// LabelValues implements storage.Querier.
+func (q mergeQuerier) LabelValues(name string) ([]string, error) {
+	if name == TenantLabel {
+		return q.tenants, nil
+	}
+	return q.mergeStrings(func(querier storage.Querier) ([]string, error) {
+		return querier.LabelValues(name)
+	})
+}
+
+// LabelNames implements storage.Querier.
+func (q mergeQuerier) LabelNames() ([]string, error) {
+	names, err := q.mergeStrings(func(querier storage.Querier) ([]string, error) {
+		return querier.LabelNames()
+	})
+	if err != nil {
+		return nil, err
+	}
+	names = append(names, TenantLabel)
+	sort.Strings(names)
+	return names, nil
+}
+
+// mergeStrings returns the sorted union of f's results for each tenant.
+func (q mergeQuerier) mergeStrings(f func(storage.Querier) ([]string, error)) ([]string, error) {
+	seen := map[string]struct{}{}
+	result := []string{}
+	for _, querier := range q.queriers {
+		values, err := f(querier)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range values {
+			if _, ok := seen[v]; !ok {
+				seen[v] = struct{}{}
+				result = append(result, v)
+			}
+		}
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// Close implements storage.Querier.
+func (q mergeQuerier) Close() error {
+	var lastErr error
+	for _, querier := range q.queriers {
+		if err := querier.Close(); err != nil {
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+// tenantSeriesSet adds the __tenant_id__ label to each of the series of a
+// tenant, replacing any it had.
+type tenantSeriesSet struct {
+	storage.SeriesSet
+	tenant string
+}
+
+func (s *tenantSeriesSet) At() storage.Series {
+	series := s.SeriesSet.At()
+	b := labels.NewBuilder(series.Labels())
+	b.Set(TenantLabel, s.tenant)
+	return tenantSeries{
+		Series: series,
+		labels: b.Labels(),
+	}
+}
+
+type tenantSeries struct {
+	storage.Series
+	labels labels.Labels
+}
+
+func (s tenantSeries) Labels() labels.Labels {
+	return s.labels
+}
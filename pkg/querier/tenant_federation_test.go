@@ -0,0 +1,90 @@
+package querier
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+)
+
+type tenantLabelsQuerier struct {
+	mockSelectQuerier
+	names []string
+}
+
+func (q *tenantLabelsQuerier) LabelNames() ([]string, error) {
+	return q.names, nil
+}
+
+func (q *tenantLabelsQuerier) Close() error {
+	return nil
+}
+
+func TestMergeQueryable(t *testing.T) {
+	queriers := map[string]*tenantLabelsQuerier{}
+	for _, tenant := range []string{"a", "b"} {
+		queriers[tenant] = &tenantLabelsQuerier{
+			mockSelectQuerier: mockSelectQuerier{series: []storage.Series{
+				&concreteSeries{
+					labels:  labels.FromStrings("__name__", "foo", "i", "0"),
+					samples: []model.SamplePair{{Value: 1, Timestamp: 1}},
+				},
+			}},
+			names: []string{"__name__", "i", tenant},
+		}
+	}
+	queryable := newMergeQueryable(storage.QueryableFunc(func(ctx context.Context, mint, maxt int64) (storage.Querier, error) {
+		userID, err := user.ExtractOrgID(ctx)
+		require.NoError(t, err)
+		return queriers[userID], nil
+	}))
+
+	// Single tenants' queries are passed on.
+	q, err := queryable.Querier(user.InjectOrgID(context.Background(), "a"), 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, queriers["a"], q)
+
+	q, err = queryable.Querier(user.InjectOrgID(context.Background(), "b|a|b"), 0, 10)
+	require.NoError(t, err)
+
+	foo, err := labels.NewMatcher(labels.MatchEqual, "__name__", "foo")
+	require.NoError(t, err)
+	set, _, err := q.Select(&storage.SelectParams{}, foo)
+	require.NoError(t, err)
+	require.Equal(t, []labels.Labels{
+		labels.FromStrings("__name__", "foo", TenantLabel, "a", "i", "0"),
+		labels.FromStrings("__name__", "foo", TenantLabel, "b", "i", "0"),
+	}, seriesSetLabels(t, set))
+
+	// Matchers on the tenant label select the tenants queried, and aren't
+	// passed on.
+	onlyB, err := labels.NewMatcher(labels.MatchEqual, TenantLabel, "b")
+	require.NoError(t, err)
+	set, _, err = q.Select(&storage.SelectParams{}, foo, onlyB)
+	require.NoError(t, err)
+	require.Equal(t, []labels.Labels{
+		labels.FromStrings("__name__", "foo", TenantLabel, "b", "i", "0"),
+	}, seriesSetLabels(t, set))
+	require.Equal(t, []*labels.Matcher{foo}, queriers["b"].matchers)
+
+	values, err := q.LabelValues(TenantLabel)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, values)
+
+	names, err := q.LabelNames()
+	require.NoError(t, err)
+	require.Equal(t, []string{"__name__", TenantLabel, "a", "b", "i"}, names)
+}
+
+func seriesSetLabels(t *testing.T, set storage.SeriesSet) []labels.Labels {
+	var result []labels.Labels
+	for set.Next() {
+		result = append(result, set.At().Labels())
+	}
+	require.NoError(t, set.Err())
+	return result
+}
@@ -11,10 +11,9 @@ import (
 	"github.com/cortexproject/cortex/pkg/chunk"
 )
 
-func newUnifiedChunkQueryable(ds, cs ChunkStore, distributor Distributor, chunkIteratorFunc chunkIteratorFunc, ingesterMaxQueryLookback time.Duration) storage.Queryable {
+func newUnifiedChunkQueryable(ds, cs ChunkStore, distributor Distributor, chunkIteratorFunc chunkIteratorFunc, ingesterMaxQueryLookback, queryStoreAfter time.Duration) storage.Queryable {
 	return storage.QueryableFunc(func(ctx context.Context, mint, maxt int64) (storage.Querier, error) {
 		ucq := &unifiedChunkQuerier{
-			stores: []ChunkStore{cs},
 			querier: querier{
 				ctx:         ctx,
 				mint:        mint,
@@ -29,8 +28,11 @@ func newUnifiedChunkQueryable(ds, cs ChunkStore, distributor Distributor, chunkI
 			},
 		}
 
-		// Include ingester only if maxt is within ingesterMaxQueryLookback w.r.t. current time.
-		if ingesterMaxQueryLookback == 0 || maxt >= time.Now().Add(-ingesterMaxQueryLookback).UnixNano()/1e6 {
+		now := time.Now()
+		if shouldQueryStore(queryStoreAfter, now, mint) {
+			ucq.stores = append(ucq.stores, storeStatsChunkStore{cs})
+		}
+		if shouldQueryIngesters(ingesterMaxQueryLookback, now, maxt) {
 			ucq.stores = append(ucq.stores, ds)
 		}
 
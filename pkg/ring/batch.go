@@ -2,7 +2,10 @@ package ring
 
 import (
 	"context"
+	"sync"
 	"sync/atomic"
+
+	"github.com/weaveworks/common/httpgrpc"
 )
 
 type batchTracker struct {
@@ -23,6 +26,14 @@ type itemTracker struct {
 	maxFailures int
 	succeeded   int32
 	failed      int32
+	// The last of the item's failures which wasn't a 4xx, if any.
+	serverErr atomic.Value
+}
+
+// trackedError wraps the errors stored in an atomic.Value, which must all
+// have the same concrete type.
+type trackedError struct {
+	err error
 }
 
 // DoBatch request against a set of keys in the ring, handling replication and
@@ -32,12 +43,20 @@ type itemTracker struct {
 // within them.
 //
 // Callback is passed the ingester to target, and the indexes of the keys
-// to send to that ingester.
+// to send to that ingester.  Cleanup is called once all the callbacks have
+// returned, which may be after DoBatch has, as it returns once a quorum of
+// ingesters got each item.
+//
+// The error returned when an item misses its quorum is the one of the
+// failure which made it miss it, unless that was a 4xx and another of the
+// item's failures wasn't: the item might be accepted if sent again then, so
+// the error mustn't make the client give up on it.
 //
 // Not implemented as a method on Ring so we can test separately.
-func DoBatch(ctx context.Context, r ReadRing, keys []uint32, callback func(IngesterDesc, []int) error) error {
+func DoBatch(ctx context.Context, r ReadRing, keys []uint32, callback func(IngesterDesc, []int) error, cleanup func()) error {
 	replicationSets, err := r.BatchGet(keys, Write)
 	if err != nil {
+		cleanup()
 		return err
 	}
 
@@ -59,16 +78,24 @@ func DoBatch(ctx context.Context, r ReadRing, keys []uint32, callback func(Inges
 
 	tracker := batchTracker{
 		rpcsPending: int32(len(itemTrackers)),
-		done:        make(chan struct{}),
-		err:         make(chan error),
+		// Buffered, so that the callbacks returning after DoBatch don't block.
+		done: make(chan struct{}, 1),
+		err:  make(chan error, 1),
 	}
 
+	var wg sync.WaitGroup
+	wg.Add(len(ingesters))
 	for _, i := range ingesters {
 		go func(i ingester) {
 			err := callback(i.desc, i.indexes)
+			wg.Done()
 			tracker.record(i.itemTrackers, err)
 		}(i)
 	}
+	go func() {
+		wg.Wait()
+		cleanup()
+	}()
 
 	select {
 	case err := <-tracker.err:
@@ -92,11 +119,19 @@ func (b *batchTracker) record(sampleTrackers []*itemTracker, err error) {
 	// goroutine will write to either channel.
 	for i := range sampleTrackers {
 		if err != nil {
+			clientErr := isClientError(err)
+			if !clientErr {
+				sampleTrackers[i].serverErr.Store(trackedError{err})
+			}
 			if atomic.AddInt32(&sampleTrackers[i].failed, 1) <= int32(sampleTrackers[i].maxFailures) {
 				continue
 			}
 			if atomic.AddInt32(&b.rpcsFailed, 1) == 1 {
-				b.err <- err
+				if serverErr, ok := sampleTrackers[i].serverErr.Load().(trackedError); ok && clientErr {
+					b.err <- serverErr.err
+				} else {
+					b.err <- err
+				}
 			}
 		} else {
 			if atomic.AddInt32(&sampleTrackers[i].succeeded, 1) != int32(sampleTrackers[i].minSuccess) {
@@ -108,3 +143,10 @@ func (b *batchTracker) record(sampleTrackers []*itemTracker, err error) {
 		}
 	}
 }
+
+// isClientError returns whether the error is a 4xx, sent by ingesters
+// rejecting items which won't ever be accepted.
+func isClientError(err error) bool {
+	resp, ok := httpgrpc.HTTPResponseFromError(err)
+	return ok && resp.Code/100 == 4
+}
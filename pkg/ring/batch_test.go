@@ -0,0 +1,49 @@
+package ring
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/weaveworks/common/httpgrpc"
+)
+
+func TestBatchTrackerRecord(t *testing.T) {
+	var (
+		clientErr = httpgrpc.Errorf(http.StatusBadRequest, "out of order sample")
+		serverErr = errors.New("connection refused")
+	)
+	for _, tc := range []struct {
+		name     string
+		errs     []error
+		expected error
+	}{
+		{"quorum", []error{nil, clientErr, nil}, nil},
+		{"client errors", []error{clientErr, nil, clientErr}, clientErr},
+		{"server errors", []error{serverErr, serverErr}, serverErr},
+		// The 4xx mustn't make the client give up on the item, which the
+		// second ingester might accept if sent again.
+		{"client error after server error", []error{serverErr, clientErr}, serverErr},
+		{"server error after client error", []error{clientErr, serverErr}, serverErr},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			item := &itemTracker{minSuccess: 2, maxFailures: 1}
+			b := batchTracker{
+				rpcsPending: 1,
+				done:        make(chan struct{}, 1),
+				err:         make(chan error, 1),
+			}
+			for _, err := range tc.errs {
+				b.record([]*itemTracker{item}, err)
+			}
+
+			select {
+			case err := <-b.err:
+				assert.Equal(t, tc.expected, err)
+			case <-b.done:
+				assert.Nil(t, tc.expected)
+			}
+		})
+	}
+}
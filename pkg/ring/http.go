@@ -33,6 +33,7 @@ const tpl = `
 						<th>Ingester</th>
 						<th>State</th>
 						<th>Address</th>
+						<th>Zone</th>
 						<th>Last Heartbeat</th>
 						<th>Tokens</th>
 						<th>Ownership</th>
@@ -45,6 +46,7 @@ const tpl = `
 						<td>{{ .ID }}</td>
 						<td>{{ .State }}</td>
 						<td>{{ .Address }}</td>
+						<td>{{ .Zone }}</td>
 						<td>{{ .Timestamp }}</td>
 						<td>{{ .Tokens }}</td>
 						<td>{{ .Ownership }}%</td>
@@ -74,7 +76,7 @@ func (r *Ring) forget(ctx context.Context, id string) error {
 		ringDesc.RemoveIngester(id)
 		return ringDesc, true, nil
 	}
-	return r.KVClient.CAS(ctx, ConsulKey, unregister)
+	return r.KVClient.CAS(ctx, r.key, unregister)
 }
 
 func (r *Ring) ServeHTTP(w http.ResponseWriter, req *http.Request) {
@@ -110,13 +112,14 @@ func (r *Ring) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 		}
 
 		ingesters = append(ingesters, struct {
-			ID, State, Address, Timestamp string
-			Tokens                        uint32
-			Ownership                     float64
+			ID, State, Address, Zone, Timestamp string
+			Tokens                              uint32
+			Ownership                           float64
 		}{
 			ID:        id,
 			State:     state,
 			Address:   ing.Addr,
+			Zone:      ing.Zone,
 			Timestamp: timestamp.String(),
 			Tokens:    tokens[id],
 			Ownership: (float64(owned[id]) / float64(math.MaxUint32)) * 100,
@@ -51,6 +51,7 @@ type LifecyclerConfig struct {
 	NormaliseTokens  bool          `yaml:"normalise_tokens,omitempty"`
 	InfNames         []string      `yaml:"interface_names"`
 	FinalSleep       time.Duration `yaml:"final_sleep"`
+	Zone             string        `yaml:"availability_zone"`
 
 	// For testing, you can override the address and ID of this ingester
 	Addr           string `yaml:"address"`
@@ -66,6 +67,12 @@ func (cfg *LifecyclerConfig) RegisterFlags(f *flag.FlagSet) {
 
 // RegisterFlagsWithPrefix adds the flags required to config this to the given FlagSet
 func (cfg *LifecyclerConfig) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
+	cfg.RegisterFlagsWithPrefixAndFinalSleep(prefix, 30*time.Second, f)
+}
+
+// RegisterFlagsWithPrefixAndFinalSleep is RegisterFlagsWithPrefix with the
+// default final sleep, for members which needn't wait as long before exiting.
+func (cfg *LifecyclerConfig) RegisterFlagsWithPrefixAndFinalSleep(prefix string, finalSleep time.Duration, f *flag.FlagSet) {
 	cfg.RingConfig.RegisterFlagsWithPrefix(prefix, f)
 
 	// In order to keep backwards compatibility all of these need to be prefixed
@@ -80,7 +87,8 @@ func (cfg *LifecyclerConfig) RegisterFlagsWithPrefix(prefix string, f *flag.Flag
 	f.DurationVar(&cfg.MinReadyDuration, prefix+"min-ready-duration", 1*time.Minute, "Minimum duration to wait before becoming ready. This is to work around race conditions with ingesters exiting and updating the ring.")
 	f.BoolVar(&cfg.ClaimOnRollout, prefix+"claim-on-rollout", false, "Send chunks to PENDING ingesters on exit.")
 	f.BoolVar(&cfg.NormaliseTokens, prefix+"normalise-tokens", false, "Store tokens in a normalised fashion to reduce allocations.")
-	f.DurationVar(&cfg.FinalSleep, prefix+"final-sleep", 30*time.Second, "Duration to sleep for before exiting, to ensure metrics are scraped.")
+	f.DurationVar(&cfg.FinalSleep, prefix+"final-sleep", finalSleep, "Duration to sleep for before exiting, to ensure metrics are scraped.")
+	f.StringVar(&cfg.Zone, prefix+"availability-zone", "", "The availability zone of the host this instance runs on, registered in the ring for zone-aware replication.")
 
 	hostname, err := os.Hostname()
 	if err != nil {
@@ -117,6 +125,7 @@ type Lifecycler struct {
 	ID       string
 	Addr     string
 	RingName string
+	RingKey  string
 
 	// We need to remember the ingester state just in case consul goes away and comes
 	// back empty.  And it changes during lifecycle of ingester.
@@ -124,6 +133,9 @@ type Lifecycler struct {
 	state    IngesterState
 	tokens   []uint32
 
+	// The number of healthy members of the ring, as of our last write to it.
+	healthyInstancesCount int
+
 	// Controls the ready-reporting
 	readyLock sync.Mutex
 	startTime time.Time
@@ -131,7 +143,7 @@ type Lifecycler struct {
 }
 
 // NewLifecycler makes and starts a new Lifecycler.
-func NewLifecycler(cfg LifecyclerConfig, flushTransferer FlushTransferer, name string) (*Lifecycler, error) {
+func NewLifecycler(cfg LifecyclerConfig, flushTransferer FlushTransferer, name, ringKey string) (*Lifecycler, error) {
 	addr := cfg.Addr
 	if addr == "" {
 		var err error
@@ -158,6 +170,7 @@ func NewLifecycler(cfg LifecyclerConfig, flushTransferer FlushTransferer, name s
 		Addr:     fmt.Sprintf("%s:%d", addr, port),
 		ID:       cfg.ID,
 		RingName: name,
+		RingKey:  ringKey,
 
 		quit:      make(chan struct{}),
 		actorChan: make(chan func()),
@@ -190,7 +203,7 @@ func (i *Lifecycler) CheckReady(ctx context.Context) error {
 		return fmt.Errorf("waiting for %v after startup", i.cfg.MinReadyDuration)
 	}
 
-	ringDesc, err := i.KVStore.Get(ctx, ConsulKey)
+	ringDesc, err := i.KVStore.Get(ctx, i.RingKey)
 	if err != nil {
 		level.Error(util.Logger).Log("msg", "error talking to consul", "err", err)
 		return fmt.Errorf("error talking to consul: %s", err)
@@ -214,6 +227,26 @@ func (i *Lifecycler) GetState() IngesterState {
 	return i.state
 }
 
+// HealthyInstancesCount returns the number of ACTIVE members of the ring
+// which were heartbeating when we last wrote to it, or 0 until we have.
+func (i *Lifecycler) HealthyInstancesCount() int {
+	i.stateMtx.Lock()
+	defer i.stateMtx.Unlock()
+	return i.healthyInstancesCount
+}
+
+func (i *Lifecycler) countHealthyInstances(ringDesc *Desc) {
+	count := 0
+	for _, ingester := range ringDesc.Ingesters {
+		if ingester.State == ACTIVE && time.Now().Sub(time.Unix(ingester.Timestamp, 0)) <= i.cfg.RingConfig.HeartbeatTimeout {
+			count++
+		}
+	}
+	i.stateMtx.Lock()
+	defer i.stateMtx.Unlock()
+	i.healthyInstancesCount = count
+}
+
 func (i *Lifecycler) setState(state IngesterState) {
 	i.stateMtx.Lock()
 	defer i.stateMtx.Unlock()
@@ -260,7 +293,7 @@ func (i *Lifecycler) ClaimTokensFor(ctx context.Context, ingesterID string) erro
 			return ringDesc, true, nil
 		}
 
-		if err := i.KVStore.CAS(ctx, ConsulKey, claimTokens); err != nil {
+		if err := i.KVStore.CAS(ctx, i.RingKey, claimTokens); err != nil {
 			level.Error(util.Logger).Log("msg", "Failed to write to consul", "err", err)
 		}
 
@@ -372,7 +405,7 @@ heartbeatLoop:
 // - add an ingester entry to the ring
 // - copies out our state and tokens if they exist
 func (i *Lifecycler) initRing(ctx context.Context) error {
-	return i.KVStore.CAS(ctx, ConsulKey, func(in interface{}) (out interface{}, retry bool, err error) {
+	return i.KVStore.CAS(ctx, i.RingKey, func(in interface{}) (out interface{}, retry bool, err error) {
 		var ringDesc *Desc
 		if in == nil {
 			ringDesc = NewDesc()
@@ -384,7 +417,7 @@ func (i *Lifecycler) initRing(ctx context.Context) error {
 		if !ok {
 			// Either we are a new ingester, or consul must have restarted
 			level.Info(util.Logger).Log("msg", "entry not found in ring, adding with no tokens")
-			ringDesc.AddIngester(i.ID, i.Addr, []uint32{}, i.GetState(), i.cfg.NormaliseTokens)
+			ringDesc.AddIngester(i.ID, i.Addr, i.cfg.Zone, []uint32{}, i.GetState(), i.cfg.NormaliseTokens)
 			return ringDesc, true, nil
 		}
 
@@ -400,7 +433,7 @@ func (i *Lifecycler) initRing(ctx context.Context) error {
 
 // autoJoin selects random tokens & moves state to ACTIVE
 func (i *Lifecycler) autoJoin(ctx context.Context) error {
-	return i.KVStore.CAS(ctx, ConsulKey, func(in interface{}) (out interface{}, retry bool, err error) {
+	return i.KVStore.CAS(ctx, i.RingKey, func(in interface{}) (out interface{}, retry bool, err error) {
 		var ringDesc *Desc
 		if in == nil {
 			ringDesc = NewDesc()
@@ -416,11 +449,12 @@ func (i *Lifecycler) autoJoin(ctx context.Context) error {
 
 		newTokens := GenerateTokens(i.cfg.NumTokens-len(myTokens), takenTokens)
 		i.setState(ACTIVE)
-		ringDesc.AddIngester(i.ID, i.Addr, newTokens, i.GetState(), i.cfg.NormaliseTokens)
+		ringDesc.AddIngester(i.ID, i.Addr, i.cfg.Zone, newTokens, i.GetState(), i.cfg.NormaliseTokens)
 
 		tokens := append(myTokens, newTokens...)
 		sort.Sort(sortableUint32(tokens))
 		i.setTokens(tokens)
+		i.countHealthyInstances(ringDesc)
 
 		return ringDesc, true, nil
 	})
@@ -429,7 +463,7 @@ func (i *Lifecycler) autoJoin(ctx context.Context) error {
 // updateConsul updates our entries in consul, heartbeating and dealing with
 // consul restarts.
 func (i *Lifecycler) updateConsul(ctx context.Context) error {
-	return i.KVStore.CAS(ctx, ConsulKey, func(in interface{}) (out interface{}, retry bool, err error) {
+	return i.KVStore.CAS(ctx, i.RingKey, func(in interface{}) (out interface{}, retry bool, err error) {
 		var ringDesc *Desc
 		if in == nil {
 			ringDesc = NewDesc()
@@ -441,13 +475,15 @@ func (i *Lifecycler) updateConsul(ctx context.Context) error {
 		if !ok {
 			// consul must have restarted
 			level.Info(util.Logger).Log("msg", "found empty ring, inserting tokens")
-			ringDesc.AddIngester(i.ID, i.Addr, i.getTokens(), i.GetState(), i.cfg.NormaliseTokens)
+			ringDesc.AddIngester(i.ID, i.Addr, i.cfg.Zone, i.getTokens(), i.GetState(), i.cfg.NormaliseTokens)
 		} else {
 			ingesterDesc.Timestamp = time.Now().Unix()
 			ingesterDesc.State = i.GetState()
 			ingesterDesc.Addr = i.Addr
+			ingesterDesc.Zone = i.cfg.Zone
 			ringDesc.Ingesters[i.ID] = ingesterDesc
 		}
+		i.countHealthyInstances(ringDesc)
 
 		return ringDesc, true, nil
 	})
@@ -462,7 +498,10 @@ func (i *Lifecycler) changeState(ctx context.Context, state IngesterState) error
 		(currState == JOINING && state == PENDING) || // triggered by TransferChunks on failure
 		(currState == JOINING && state == ACTIVE) || // triggered by TransferChunks on success
 		(currState == PENDING && state == ACTIVE) || // triggered by autoJoin
-		(currState == ACTIVE && state == LEAVING)) { // triggered by shutdown
+		(currState == ACTIVE && state == READONLY) || // triggered by the ingester's /readonly
+		(currState == READONLY && state == ACTIVE) || // triggered by the ingester's /readonly
+		(currState == ACTIVE && state == LEAVING) || // triggered by shutdown
+		(currState == READONLY && state == LEAVING)) { // triggered by shutdown
 		return fmt.Errorf("Changing ingester state from %v -> %v is disallowed", currState, state)
 	}
 
@@ -498,7 +537,7 @@ func (i *Lifecycler) processShutdown(ctx context.Context) {
 func (i *Lifecycler) unregister(ctx context.Context) error {
 	level.Debug(util.Logger).Log("msg", "unregistering member from ring")
 
-	return i.KVStore.CAS(ctx, ConsulKey, func(in interface{}) (out interface{}, retry bool, err error) {
+	return i.KVStore.CAS(ctx, i.RingKey, func(in interface{}) (out interface{}, retry bool, err error) {
 		if in == nil {
 			return nil, false, fmt.Errorf("found empty ring when trying to unregister")
 		}
@@ -61,7 +61,7 @@ func TestRingNormaliseMigration(t *testing.T) {
 	codec := ProtoCodec{Factory: ProtoDescFactory}
 	ringConfig.KVStore.Mock = NewInMemoryKVClient(codec)
 
-	r, err := New(ringConfig, "ingester")
+	r, err := New(ringConfig, "ingester", ConsulKey)
 	require.NoError(t, err)
 	defer r.Stop()
 
@@ -69,7 +69,7 @@ func TestRingNormaliseMigration(t *testing.T) {
 	lifecyclerConfig1 := testLifecyclerConfig(ringConfig, "ing1")
 
 	ft := &flushTransferer{}
-	l1, err := NewLifecycler(lifecyclerConfig1, ft, "ingester")
+	l1, err := NewLifecycler(lifecyclerConfig1, ft, "ingester", ConsulKey)
 	require.NoError(t, err)
 
 	// Check this ingester joined, is active, and has one token.
@@ -86,7 +86,7 @@ func TestRingNormaliseMigration(t *testing.T) {
 	lifecyclerConfig2.JoinAfter = 100 * time.Second
 	lifecyclerConfig2.NormaliseTokens = true
 
-	l2, err := NewLifecycler(lifecyclerConfig2, &flushTransferer{}, "ingester")
+	l2, err := NewLifecycler(lifecyclerConfig2, &flushTransferer{}, "ingester", ConsulKey)
 	require.NoError(t, err)
 
 	// This will block until l1 has successfully left the ring.
@@ -116,14 +116,14 @@ func TestRingRestart(t *testing.T) {
 	codec := ProtoCodec{Factory: ProtoDescFactory}
 	ringConfig.KVStore.Mock = NewInMemoryKVClient(codec)
 
-	r, err := New(ringConfig, "ingester")
+	r, err := New(ringConfig, "ingester", ConsulKey)
 	require.NoError(t, err)
 	defer r.Stop()
 
 	// Add an 'ingester' with normalised tokens.
 	lifecyclerConfig1 := testLifecyclerConfig(ringConfig, "ing1")
 	lifecyclerConfig1.NormaliseTokens = true
-	l1, err := NewLifecycler(lifecyclerConfig1, &nopFlushTransferer{}, "ingester")
+	l1, err := NewLifecycler(lifecyclerConfig1, &nopFlushTransferer{}, "ingester", ConsulKey)
 	require.NoError(t, err)
 
 	// Check this ingester joined, is active, and has one token.
@@ -136,7 +136,7 @@ func TestRingRestart(t *testing.T) {
 	token := l1.tokens[0]
 
 	// Add a second ingester with the same settings, so it will think it has restarted
-	l2, err := NewLifecycler(lifecyclerConfig1, &nopFlushTransferer{}, "ingester")
+	l2, err := NewLifecycler(lifecyclerConfig1, &nopFlushTransferer{}, "ingester", ConsulKey)
 	require.NoError(t, err)
 
 	// Check the new ingester picked up the same token
@@ -149,3 +149,25 @@ func TestRingRestart(t *testing.T) {
 			l2Tokens[0] == token
 	})
 }
+
+func TestLifecyclersWithDifferentRingKeys(t *testing.T) {
+	var ringConfig Config
+	flagext.DefaultValues(&ringConfig)
+	codec := ProtoCodec{Factory: ProtoDescFactory}
+	store := NewInMemoryKVClient(codec)
+	ringConfig.KVStore.Mock = store
+
+	// Members of different rings can share an ID and a store.
+	_, err := NewLifecycler(testLifecyclerConfig(ringConfig, "ing1"), &nopFlushTransferer{}, "ingester", ConsulKey)
+	require.NoError(t, err)
+	_, err = NewLifecycler(testLifecyclerConfig(ringConfig, "ing1"), &nopFlushTransferer{}, "distributor", "distributor")
+	require.NoError(t, err)
+
+	for _, key := range []string{ConsulKey, "distributor"} {
+		test.Poll(t, 1000*time.Millisecond, true, func() interface{} {
+			d, err := store.Get(context.Background(), key)
+			require.NoError(t, err)
+			return checkDenormalised(d, "ing1")
+		})
+	}
+}
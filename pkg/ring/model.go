@@ -28,7 +28,7 @@ func NewDesc() *Desc {
 }
 
 // AddIngester adds the given ingester to the ring.
-func (d *Desc) AddIngester(id, addr string, tokens []uint32, state IngesterState, normaliseTokens bool) {
+func (d *Desc) AddIngester(id, addr, zone string, tokens []uint32, state IngesterState, normaliseTokens bool) {
 	if d.Ingesters == nil {
 		d.Ingesters = map[string]IngesterDesc{}
 	}
@@ -37,6 +37,7 @@ func (d *Desc) AddIngester(id, addr string, tokens []uint32, state IngesterState
 		Addr:      addr,
 		Timestamp: time.Now().Unix(),
 		State:     state,
+		Zone:      zone,
 	}
 
 	if normaliseTokens {
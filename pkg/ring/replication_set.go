@@ -15,6 +15,18 @@ type ReplicationSet struct {
 // Do function f in parallel for all replicas in the set, erroring is we exceed
 // MaxErrors and returning early otherwise.
 func (r ReplicationSet) Do(ctx context.Context, delay time.Duration, f func(*IngesterDesc) (interface{}, error)) ([]interface{}, error) {
+	return r.do(ctx, delay, false, f)
+}
+
+// DoAll is like Do, but rather than returning as soon as enough replicas
+// have succeeded, waits for all of them to respond, still tolerating MaxErrors
+// failures.  Results then include those of every replica which could answer,
+// not just the fastest.
+func (r ReplicationSet) DoAll(ctx context.Context, f func(*IngesterDesc) (interface{}, error)) ([]interface{}, error) {
+	return r.do(ctx, 0, true, f)
+}
+
+func (r ReplicationSet) do(ctx context.Context, delay time.Duration, all bool, f func(*IngesterDesc) (interface{}, error)) ([]interface{}, error) {
 	var (
 		errs        = make(chan error, len(r.Ingesters))
 		resultsChan = make(chan interface{}, len(r.Ingesters))
@@ -53,7 +65,7 @@ func (r ReplicationSet) Do(ctx context.Context, delay time.Duration, f func(*Ing
 		numSuccess int
 		results    = make([]interface{}, 0, len(r.Ingesters))
 	)
-	for numSuccess < minSuccess {
+	for numSuccess < minSuccess || (all && numSuccess+numErrs < len(r.Ingesters)) {
 		select {
 		case err := <-errs:
 			numErrs++
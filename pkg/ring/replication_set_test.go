@@ -0,0 +1,59 @@
+package ring
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplicationSetDo(t *testing.T) {
+	set := ReplicationSet{
+		Ingesters: []IngesterDesc{{Addr: "fast1"}, {Addr: "fast2"}, {Addr: "slow"}},
+		MaxErrors: 1,
+	}
+	f := func(ing *IngesterDesc) (interface{}, error) {
+		if ing.Addr == "slow" {
+			time.Sleep(100 * time.Millisecond)
+		}
+		return ing.Addr, nil
+	}
+	addrs := func(results []interface{}) []string {
+		var result []string
+		for _, r := range results {
+			result = append(result, r.(string))
+		}
+		sort.Strings(result)
+		return result
+	}
+
+	// Do returns once a quorum has answered, DoAll waits for the slow one too.
+	results, err := set.Do(context.Background(), 0, f)
+	require.NoError(t, err)
+	require.Equal(t, []string{"fast1", "fast2"}, addrs(results))
+
+	results, err = set.DoAll(context.Background(), f)
+	require.NoError(t, err)
+	require.Equal(t, []string{"fast1", "fast2", "slow"}, addrs(results))
+
+	// DoAll still tolerates MaxErrors failures, but no more.
+	results, err = set.DoAll(context.Background(), func(ing *IngesterDesc) (interface{}, error) {
+		if ing.Addr == "fast1" {
+			return nil, errors.New("fail")
+		}
+		return f(ing)
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"fast2", "slow"}, addrs(results))
+
+	_, err = set.DoAll(context.Background(), func(ing *IngesterDesc) (interface{}, error) {
+		if ing.Addr != "slow" {
+			return nil, errors.New("fail")
+		}
+		return f(ing)
+	})
+	require.Error(t, err)
+}
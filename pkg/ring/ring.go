@@ -21,7 +21,7 @@ import (
 const (
 	unhealthy = "Unhealthy"
 
-	// ConsulKey is the key under which we store the ring in consul.
+	// ConsulKey is the key under which we store the ingesters' ring in consul.
 	ConsulKey = "ring"
 )
 
@@ -33,6 +33,7 @@ type ReadRing interface {
 	BatchGet(keys []uint32, op Operation) ([]ReplicationSet, error)
 	GetAll() (ReplicationSet, error)
 	ReplicationFactor() int
+	Subring(key uint32, n int) ReadRing
 }
 
 // Operation can be Read or Write
@@ -59,6 +60,7 @@ type Config struct {
 	KVStore           KVConfig      `yaml:"kvstore,omitempty"`
 	HeartbeatTimeout  time.Duration `yaml:"heartbeat_timeout,omitempty"`
 	ReplicationFactor int           `yaml:"replication_factor,omitempty"`
+	ZoneAwareness     bool          `yaml:"zone_awareness_enabled,omitempty"`
 }
 
 // RegisterFlags adds the flags required to config this to the given FlagSet with a specified prefix
@@ -70,13 +72,23 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 func (cfg *Config) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
 	cfg.KVStore.RegisterFlagsWithPrefix(prefix, f)
 
+	// The replication flags were the distributor's, and keep its prefix,
+	// which isn't repeated for the distributors' own ring: theirs are with
+	// its other ring flags instead.
+	replicationPrefix := prefix + "distributor."
+	if prefix == "distributor." {
+		replicationPrefix = prefix + "ring."
+	}
+
 	f.DurationVar(&cfg.HeartbeatTimeout, prefix+"ring.heartbeat-timeout", time.Minute, "The heartbeat timeout after which ingesters are skipped for reads/writes.")
-	f.IntVar(&cfg.ReplicationFactor, prefix+"distributor.replication-factor", 3, "The number of ingesters to write to and read from.")
+	f.IntVar(&cfg.ReplicationFactor, replicationPrefix+"replication-factor", 3, "The number of ingesters to write to and read from.")
+	f.BoolVar(&cfg.ZoneAwareness, replicationPrefix+"zone-awareness-enabled", false, "Place each series' replicas in ingesters of distinct availability zones.")
 }
 
 // Ring holds the information about the members of the consistent hash ring.
 type Ring struct {
 	name     string
+	key      string
 	cfg      Config
 	KVClient KVClient
 	done     chan struct{}
@@ -85,14 +97,18 @@ type Ring struct {
 	mtx      sync.RWMutex
 	ringDesc *Desc
 
+	// The subrings of ringDesc, by key and size, reset whenever it changes.
+	subringsMtx sync.Mutex
+	subrings    map[subringKey]*Ring
+
 	memberOwnershipDesc *prometheus.Desc
 	numMembersDesc      *prometheus.Desc
 	totalTokensDesc     *prometheus.Desc
 	numTokensDesc       *prometheus.Desc
 }
 
-// New creates a new Ring
-func New(cfg Config, name string) (*Ring, error) {
+// New creates a new Ring, watching the ring stored under key.
+func New(cfg Config, name, key string) (*Ring, error) {
 	if cfg.ReplicationFactor <= 0 {
 		return nil, fmt.Errorf("ReplicationFactor must be greater than zero: %d", cfg.ReplicationFactor)
 	}
@@ -104,6 +120,7 @@ func New(cfg Config, name string) (*Ring, error) {
 
 	r := &Ring{
 		name:     name,
+		key:      key,
 		cfg:      cfg,
 		KVClient: store,
 		done:     make(chan struct{}),
@@ -143,7 +160,7 @@ func (r *Ring) Stop() {
 
 func (r *Ring) loop(ctx context.Context) {
 	defer close(r.done)
-	r.KVClient.WatchKey(ctx, ConsulKey, func(value interface{}) bool {
+	r.KVClient.WatchKey(ctx, r.key, func(value interface{}) bool {
 		if value == nil {
 			level.Info(util.Logger).Log("msg", "ring doesn't exist in consul yet")
 			return true
@@ -154,6 +171,7 @@ func (r *Ring) loop(ctx context.Context) {
 		r.mtx.Lock()
 		defer r.mtx.Unlock()
 		r.ringDesc = ringDesc
+		r.subrings = nil
 		return true
 	})
 }
@@ -211,6 +229,7 @@ func (r *Ring) getInternal(key uint32, op Operation) (ReplicationSet, error) {
 		n             = r.cfg.ReplicationFactor
 		ingesters     = make([]IngesterDesc, 0, n)
 		distinctHosts = map[string]struct{}{}
+		distinctZones = map[string]struct{}{}
 		start         = r.search(key)
 		iterations    = 0
 	)
@@ -224,18 +243,27 @@ func (r *Ring) getInternal(key uint32, op Operation) (ReplicationSet, error) {
 		if _, ok := distinctHosts[token.Ingester]; ok {
 			continue
 		}
-		distinctHosts[token.Ingester] = struct{}{}
 		ingester := r.ringDesc.Ingesters[token.Ingester]
 
+		// With zone awareness, ingesters in a zone already holding a replica
+		// are skipped, so losing a zone never loses all the replicas.
+		if r.cfg.ZoneAwareness && ingester.Zone != "" {
+			if _, ok := distinctZones[ingester.Zone]; ok {
+				continue
+			}
+			distinctZones[ingester.Zone] = struct{}{}
+		}
+		distinctHosts[token.Ingester] = struct{}{}
+
 		// We do not want to Write to Ingesters that are not ACTIVE, but we do want
 		// to write the extra replica somewhere.  So we increase the size of the set
 		// of replicas for the key. This means we have to also increase the
-		// size of the replica set for read, but we can read from Leaving ingesters,
-		// so don't skip it in this case.
+		// size of the replica set for read, but we can read from Leaving and
+		// ReadOnly ingesters, so don't skip it in this case.
 		// NB dead ingester will be filtered later (by replication_strategy.go).
 		if op == Write && ingester.State != ACTIVE {
 			n++
-		} else if op == Read && (ingester.State != ACTIVE && ingester.State != LEAVING) {
+		} else if op == Read && (ingester.State != ACTIVE && ingester.State != LEAVING && ingester.State != READONLY) {
 			n++
 		}
 
@@ -283,6 +311,106 @@ func (r *Ring) GetAll() (ReplicationSet, error) {
 	}, nil
 }
 
+// HealthyInstancesCount returns the number of ACTIVE members of the ring
+// which are heartbeating.
+func (r *Ring) HealthyInstancesCount() int {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	if r.ringDesc == nil {
+		return 0
+	}
+	count := 0
+	for _, ingester := range r.ringDesc.Ingesters {
+		if r.IsHealthy(&ingester, Write) {
+			count++
+		}
+	}
+	return count
+}
+
+type subringKey struct {
+	key uint32
+	n   int
+}
+
+// Subring returns the ring of the n distinct ingesters found walking the ring
+// from key, whatever their state, so that the same key always picks the same
+// ingesters while the ring's membership doesn't change.  It returns the whole
+// ring if n isn't positive or the ring has no more than n ingesters.
+// Subrings are kept until the ring changes, as they are needed for every push
+// and query of a sharded tenant.
+func (r *Ring) Subring(key uint32, n int) ReadRing {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	if n <= 0 || r.ringDesc == nil || len(r.ringDesc.Ingesters) <= n {
+		return r
+	}
+
+	r.subringsMtx.Lock()
+	defer r.subringsMtx.Unlock()
+	if subring, ok := r.subrings[subringKey{key, n}]; ok {
+		return subring
+	}
+	subring := r.subring(key, n)
+	if r.subrings == nil {
+		r.subrings = map[subringKey]*Ring{}
+	}
+	r.subrings[subringKey{key, n}] = subring
+	return subring
+}
+
+func (r *Ring) subring(key uint32, n int) *Ring {
+	// With zone awareness, the subring takes as many ingesters from each
+	// zone, so that its keys still have a replica in every zone.
+	maxPerZone := n
+	if r.cfg.ZoneAwareness {
+		zones := map[string]struct{}{}
+		for _, ingester := range r.ringDesc.Ingesters {
+			if ingester.Zone != "" {
+				zones[ingester.Zone] = struct{}{}
+			}
+		}
+		if len(zones) > 0 {
+			maxPerZone = (n + len(zones) - 1) / len(zones)
+		}
+	}
+
+	desc := &Desc{Ingesters: make(map[string]IngesterDesc, n)}
+	perZone := map[string]int{}
+	start := r.search(key)
+	for i := 0; len(desc.Ingesters) < n && i < len(r.ringDesc.Tokens); i++ {
+		token := r.ringDesc.Tokens[(start+i)%len(r.ringDesc.Tokens)]
+		if _, ok := desc.Ingesters[token.Ingester]; ok {
+			continue
+		}
+		ingester := r.ringDesc.Ingesters[token.Ingester]
+		if ingester.Zone != "" {
+			if perZone[ingester.Zone] >= maxPerZone {
+				continue
+			}
+			perZone[ingester.Zone]++
+		}
+		desc.Ingesters[token.Ingester] = ingester
+	}
+	for _, token := range r.ringDesc.Tokens {
+		if _, ok := desc.Ingesters[token.Ingester]; ok {
+			desc.Tokens = append(desc.Tokens, token)
+		}
+	}
+
+	return &Ring{
+		name:                r.name,
+		cfg:                 r.cfg,
+		ringDesc:            desc,
+		memberOwnershipDesc: r.memberOwnershipDesc,
+		numMembersDesc:      r.numMembersDesc,
+		totalTokensDesc:     r.totalTokensDesc,
+		numTokensDesc:       r.numTokensDesc,
+	}
+}
+
 func (r *Ring) search(key uint32) int {
 	i := sort.Search(len(r.ringDesc.Tokens), func(x int) bool {
 		return r.ringDesc.Tokens[x].Token > key
@@ -352,11 +480,12 @@ func (r *Ring) Collect(ch chan<- prometheus.Metric) {
 
 	// Initialised to zero so we emit zero-metrics (instead of not emitting anything)
 	byState := map[string]int{
-		unhealthy:        0,
-		ACTIVE.String():  0,
-		LEAVING.String(): 0,
-		PENDING.String(): 0,
-		JOINING.String(): 0,
+		unhealthy:         0,
+		ACTIVE.String():   0,
+		LEAVING.String():  0,
+		PENDING.String():  0,
+		JOINING.String():  0,
+		READONLY.String(): 0,
 	}
 	for _, ingester := range r.ringDesc.Ingesters {
 		if !r.IsHealthy(&ingester, Reporting) {
@@ -33,6 +33,9 @@ const (
 	LEAVING IngesterState = 1
 	PENDING IngesterState = 2
 	JOINING IngesterState = 3
+	// READONLY ingesters are read from, but not written to, e.g. while being
+	// decommissioned.
+	READONLY IngesterState = 4
 )
 
 var IngesterState_name = map[int32]string{
@@ -40,13 +43,15 @@ var IngesterState_name = map[int32]string{
 	1: "LEAVING",
 	2: "PENDING",
 	3: "JOINING",
+	4: "READONLY",
 }
 
 var IngesterState_value = map[string]int32{
-	"ACTIVE":  0,
-	"LEAVING": 1,
-	"PENDING": 2,
-	"JOINING": 3,
+	"ACTIVE":   0,
+	"LEAVING":  1,
+	"PENDING":  2,
+	"JOINING":  3,
+	"READONLY": 4,
 }
 
 func (IngesterState) EnumDescriptor() ([]byte, []int) {
@@ -109,6 +114,7 @@ type IngesterDesc struct {
 	Timestamp int64         `protobuf:"varint,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
 	State     IngesterState `protobuf:"varint,3,opt,name=state,proto3,enum=ring.IngesterState" json:"state,omitempty"`
 	Tokens    []uint32      `protobuf:"varint,6,rep,packed,name=tokens,proto3" json:"tokens,omitempty"`
+	Zone      string        `protobuf:"bytes,7,opt,name=zone,proto3" json:"zone,omitempty"`
 }
 
 func (m *IngesterDesc) Reset()      { *m = IngesterDesc{} }
@@ -171,6 +177,13 @@ func (m *IngesterDesc) GetTokens() []uint32 {
 	return nil
 }
 
+func (m *IngesterDesc) GetZone() string {
+	if m != nil {
+		return m.Zone
+	}
+	return ""
+}
+
 type TokenDesc struct {
 	Token    uint32 `protobuf:"varint,1,opt,name=token,proto3" json:"token,omitempty"`
 	Ingester string `protobuf:"bytes,2,opt,name=ingester,proto3" json:"ingester,omitempty"`
@@ -233,34 +246,35 @@ func init() {
 func init() { proto.RegisterFile("ring.proto", fileDescriptor_26381ed67e202a6e) }
 
 var fileDescriptor_26381ed67e202a6e = []byte{
-	// 418 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x54, 0x92, 0x4d, 0x6e, 0xd3, 0x40,
-	0x14, 0xc7, 0xe7, 0xf9, 0x8b, 0xf8, 0x85, 0x14, 0x6b, 0x40, 0xc8, 0x44, 0x68, 0xb0, 0xb2, 0x32,
-	0x48, 0x75, 0xa5, 0xc0, 0x02, 0x21, 0x75, 0xd1, 0x50, 0x0b, 0x39, 0x42, 0xa1, 0x32, 0x55, 0xf7,
-	0x4e, 0x3b, 0x18, 0xab, 0xc4, 0xae, 0xec, 0x09, 0x52, 0x77, 0xdc, 0x00, 0x8e, 0xc1, 0x49, 0x50,
-	0x97, 0x59, 0x76, 0x85, 0x88, 0xb3, 0x61, 0xd9, 0x23, 0xa0, 0x19, 0xc7, 0x09, 0xd9, 0xbd, 0xdf,
-	0xfc, 0x3f, 0x66, 0x9e, 0x65, 0xc4, 0x32, 0xcb, 0xd3, 0xe0, 0xaa, 0x2c, 0x44, 0x41, 0x0d, 0x39,
-	0xf7, 0xf7, 0xd3, 0x4c, 0x7c, 0x9e, 0x4f, 0x83, 0xf3, 0x62, 0x76, 0x90, 0x16, 0x69, 0x71, 0xa0,
-	0xc4, 0xe9, 0xfc, 0x93, 0x22, 0x05, 0x6a, 0x6a, 0x42, 0x83, 0x5f, 0x80, 0xc6, 0x31, 0xaf, 0xce,
-	0xe9, 0x21, 0xda, 0x59, 0x9e, 0xf2, 0x4a, 0xf0, 0xb2, 0x72, 0xc1, 0xd3, 0xfd, 0xee, 0xf0, 0x49,
-	0xa0, 0xda, 0xa5, 0x1c, 0x44, 0xad, 0x16, 0xe6, 0xa2, 0xbc, 0x1e, 0x19, 0x37, 0xbf, 0x9f, 0x91,
-	0x78, 0x9b, 0xa0, 0xfb, 0x68, 0x89, 0xe2, 0x92, 0xe7, 0x95, 0xab, 0xa9, 0xec, 0x83, 0x26, 0x7b,
-	0x2a, 0xcf, 0x64, 0xc1, 0x3a, 0xb1, 0x36, 0xf5, 0x4f, 0x70, 0x6f, 0xb7, 0x91, 0x3a, 0xa8, 0x5f,
-	0xf2, 0x6b, 0x17, 0x3c, 0xf0, 0xed, 0x58, 0x8e, 0xd4, 0x47, 0xf3, 0x6b, 0xf2, 0x65, 0xce, 0x5d,
-	0xcd, 0x03, 0xbf, 0x3b, 0xa4, 0x4d, 0x63, 0x1b, 0x93, 0xa5, 0x71, 0x63, 0x78, 0xa3, 0xbd, 0x86,
-	0xc1, 0x77, 0xc0, 0xfb, 0xff, 0x6b, 0x94, 0xa2, 0x91, 0x5c, 0x5c, 0x94, 0xeb, 0x46, 0x35, 0xd3,
-	0xa7, 0x68, 0x8b, 0x6c, 0xc6, 0x2b, 0x91, 0xcc, 0xae, 0x54, 0xad, 0x1e, 0x6f, 0x0f, 0xe8, 0x73,
-	0x34, 0x2b, 0x91, 0x08, 0xee, 0xea, 0x1e, 0xf8, 0x7b, 0xc3, 0x87, 0xbb, 0x17, 0x7e, 0x94, 0x52,
-	0xdc, 0x38, 0xe8, 0xe3, 0xcd, 0xba, 0x96, 0xa7, 0xfb, 0xbd, 0x76, 0xaf, 0xb1, 0xd1, 0x31, 0x1c,
-	0x73, 0x6c, 0x74, 0x4c, 0xc7, 0x1a, 0x1c, 0xa2, 0xbd, 0x59, 0x9f, 0x3e, 0x42, 0x53, 0x59, 0xd4,
-	0x73, 0x7a, 0x71, 0x03, 0xb4, 0x8f, 0x9d, 0xf6, 0x13, 0xaa, 0xe7, 0xd8, 0xf1, 0x86, 0x5f, 0x8c,
-	0xb0, 0xb7, 0x73, 0x35, 0x45, 0xb4, 0x8e, 0xde, 0x9e, 0x46, 0x67, 0xa1, 0x43, 0x68, 0x17, 0xef,
-	0xbd, 0x0f, 0x8f, 0xce, 0xa2, 0xc9, 0x3b, 0x07, 0x24, 0x9c, 0x84, 0x93, 0x63, 0x09, 0x9a, 0x84,
-	0xf1, 0x87, 0x68, 0x22, 0x41, 0x1f, 0xbd, 0x5a, 0x2c, 0x19, 0xb9, 0x5d, 0x32, 0x72, 0xb7, 0x64,
-	0xf0, 0xad, 0x66, 0xf0, 0xb3, 0x66, 0x70, 0x53, 0x33, 0x58, 0xd4, 0x0c, 0xfe, 0xd4, 0x0c, 0xfe,
-	0xd6, 0x8c, 0xdc, 0xd5, 0x0c, 0x7e, 0xac, 0x18, 0x59, 0xac, 0x18, 0xb9, 0x5d, 0x31, 0x32, 0xb5,
-	0xd4, 0xaf, 0xf1, 0xf2, 0x5f, 0x00, 0x00, 0x00, 0xff, 0xff, 0x72, 0x58, 0x13, 0x3d, 0x5d, 0x02,
-	0x00, 0x00,
+	// 439 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x54, 0x52, 0x4d, 0x6b, 0x13, 0x51,
+	0x14, 0x9d, 0x9b, 0xf9, 0x68, 0xe6, 0xa6, 0xa9, 0xc3, 0x55, 0x64, 0x0c, 0xf2, 0x1c, 0xb2, 0x1a,
+	0x85, 0xa6, 0x10, 0x5d, 0x88, 0xd0, 0x45, 0x6a, 0x06, 0x49, 0x28, 0x69, 0x78, 0x96, 0x82, 0xcb,
+	0xa4, 0x7d, 0x8e, 0xa1, 0x66, 0xa6, 0xcc, 0xbc, 0x08, 0x75, 0xe5, 0x4f, 0xf0, 0x3f, 0xb8, 0xf1,
+	0x97, 0x48, 0x97, 0x59, 0x76, 0x25, 0x66, 0xb2, 0x71, 0xd9, 0x9f, 0x20, 0xef, 0x4d, 0x92, 0x9a,
+	0xdd, 0x39, 0xef, 0xdc, 0x73, 0xee, 0x07, 0x0f, 0x31, 0x9b, 0x24, 0x71, 0xeb, 0x2a, 0x4b, 0x65,
+	0x4a, 0x96, 0xc2, 0x8d, 0xfd, 0x78, 0x22, 0x3f, 0xcd, 0xc6, 0xad, 0xf3, 0x74, 0x7a, 0x10, 0xa7,
+	0x71, 0x7a, 0xa0, 0xc5, 0xf1, 0xec, 0xa3, 0x66, 0x9a, 0x68, 0x54, 0x9a, 0x9a, 0xbf, 0x00, 0xad,
+	0xae, 0xc8, 0xcf, 0xe9, 0x10, 0xdd, 0x49, 0x12, 0x8b, 0x5c, 0x8a, 0x2c, 0xf7, 0x21, 0x30, 0xc3,
+	0x5a, 0xfb, 0x49, 0x4b, 0xa7, 0x2b, 0xb9, 0xd5, 0x5b, 0x6b, 0x51, 0x22, 0xb3, 0xeb, 0x23, 0xeb,
+	0xe6, 0xf7, 0x33, 0x83, 0xdf, 0x3b, 0x68, 0x1f, 0x1d, 0x99, 0x5e, 0x8a, 0x24, 0xf7, 0x2b, 0xda,
+	0xfb, 0xa0, 0xf4, 0x9e, 0xaa, 0x37, 0x15, 0xb0, 0x72, 0xac, 0x8a, 0x1a, 0x43, 0xdc, 0xdb, 0x4e,
+	0x24, 0x0f, 0xcd, 0x4b, 0x71, 0xed, 0x43, 0x00, 0xa1, 0xcb, 0x15, 0xa4, 0x10, 0xed, 0x2f, 0xa3,
+	0xcf, 0x33, 0xe1, 0x57, 0x02, 0x08, 0x6b, 0x6d, 0x2a, 0x13, 0xd7, 0x36, 0x15, 0xca, 0xcb, 0x82,
+	0x37, 0x95, 0xd7, 0xd0, 0xfc, 0x01, 0xb8, 0xfb, 0xbf, 0x46, 0x84, 0xd6, 0xe8, 0xe2, 0x22, 0x5b,
+	0x25, 0x6a, 0x4c, 0x4f, 0xd1, 0x95, 0x93, 0xa9, 0xc8, 0xe5, 0x68, 0x7a, 0xa5, 0x63, 0x4d, 0x7e,
+	0xff, 0x40, 0xcf, 0xd1, 0xce, 0xe5, 0x48, 0x0a, 0xdf, 0x0c, 0x20, 0xdc, 0x6b, 0x3f, 0xdc, 0x6e,
+	0xf8, 0x5e, 0x49, 0xbc, 0xac, 0xa0, 0xc7, 0x9b, 0x75, 0x9d, 0xc0, 0x0c, 0xeb, 0xeb, 0xbd, 0x54,
+	0xd3, 0xaf, 0x69, 0x22, 0xfc, 0x9d, 0xb2, 0xa9, 0xc2, 0x7d, 0xab, 0x6a, 0x79, 0x76, 0xdf, 0xaa,
+	0xda, 0x9e, 0xd3, 0x3c, 0x44, 0x77, 0x73, 0x12, 0x7a, 0x84, 0xb6, 0xb6, 0xe9, 0x11, 0xeb, 0xbc,
+	0x24, 0xd4, 0xc0, 0xea, 0xfa, 0xac, 0x7a, 0x44, 0x97, 0x6f, 0xf8, 0x8b, 0x21, 0xd6, 0xb7, 0xc6,
+	0x21, 0x44, 0xa7, 0xf3, 0xf6, 0xb4, 0x77, 0x16, 0x79, 0x06, 0xd5, 0x70, 0xe7, 0x38, 0xea, 0x9c,
+	0xf5, 0x06, 0xef, 0x3c, 0x50, 0x64, 0x18, 0x0d, 0xba, 0x8a, 0x54, 0x14, 0xe9, 0x9f, 0xf4, 0x06,
+	0x8a, 0x98, 0xb4, 0x8b, 0x55, 0x1e, 0x75, 0xba, 0x27, 0x83, 0xe3, 0x0f, 0x9e, 0x75, 0xf4, 0x6a,
+	0xbe, 0x60, 0xc6, 0xed, 0x82, 0x19, 0x77, 0x0b, 0x06, 0xdf, 0x0a, 0x06, 0x3f, 0x0b, 0x06, 0x37,
+	0x05, 0x83, 0x79, 0xc1, 0xe0, 0x4f, 0xc1, 0xe0, 0x6f, 0xc1, 0x8c, 0xbb, 0x82, 0xc1, 0xf7, 0x25,
+	0x33, 0xe6, 0x4b, 0x66, 0xdc, 0x2e, 0x99, 0x31, 0x76, 0xf4, 0xe7, 0x79, 0xf9, 0x6f, 0x00, 0xa4,
+	0x88, 0x49, 0xb3, 0x7f, 0x02, 0x00, 0x00,
 }
 
 func (x IngesterState) String() string {
@@ -345,6 +359,9 @@ func (this *IngesterDesc) Equal(that interface{}) bool {
 			return false
 		}
 	}
+	if this.Zone != that1.Zone {
+		return false
+	}
 	return true
 }
 func (this *TokenDesc) Equal(that interface{}) bool {
@@ -407,12 +424,13 @@ func (this *IngesterDesc) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 8)
+	s := make([]string, 0, 9)
 	s = append(s, "&ring.IngesterDesc{")
 	s = append(s, "Addr: "+fmt.Sprintf("%#v", this.Addr)+",\n")
 	s = append(s, "Timestamp: "+fmt.Sprintf("%#v", this.Timestamp)+",\n")
 	s = append(s, "State: "+fmt.Sprintf("%#v", this.State)+",\n")
 	s = append(s, "Tokens: "+fmt.Sprintf("%#v", this.Tokens)+",\n")
+	s = append(s, "Zone: "+fmt.Sprintf("%#v", this.Zone)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -539,6 +557,12 @@ func (m *IngesterDesc) MarshalTo(dAtA []byte) (int, error) {
 		i = encodeVarintRing(dAtA, i, uint64(j2))
 		i += copy(dAtA[i:], dAtA3[:j2])
 	}
+	if len(m.Zone) > 0 {
+		dAtA[i] = 0x3a
+		i++
+		i = encodeVarintRing(dAtA, i, uint64(len(m.Zone)))
+		i += copy(dAtA[i:], m.Zone)
+	}
 	return i, nil
 }
 
@@ -627,6 +651,10 @@ func (m *IngesterDesc) Size() (n int) {
 		}
 		n += 1 + sovRing(uint64(l)) + l
 	}
+	l = len(m.Zone)
+	if l > 0 {
+		n += 1 + l + sovRing(uint64(l))
+	}
 	return n
 }
 
@@ -689,6 +717,7 @@ func (this *IngesterDesc) String() string {
 		`Timestamp:` + fmt.Sprintf("%v", this.Timestamp) + `,`,
 		`State:` + fmt.Sprintf("%v", this.State) + `,`,
 		`Tokens:` + fmt.Sprintf("%v", this.Tokens) + `,`,
+		`Zone:` + fmt.Sprintf("%v", this.Zone) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -1103,6 +1132,38 @@ func (m *IngesterDesc) Unmarshal(dAtA []byte) error {
 			} else {
 				return fmt.Errorf("proto: wrong wireType = %d for field Tokens", wireType)
 			}
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Zone", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRing
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRing
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRing
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Zone = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipRing(dAtA[iNdEx:])
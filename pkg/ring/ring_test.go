@@ -3,7 +3,13 @@ package ring
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cortexproject/cortex/pkg/util/test"
 )
 
 const (
@@ -18,7 +24,7 @@ func BenchmarkRing(b *testing.B) {
 	for i := 0; i < numIngester; i++ {
 		tokens := GenerateTokens(numTokens, takenTokens)
 		takenTokens = append(takenTokens, tokens...)
-		desc.AddIngester(fmt.Sprintf("%d", i), fmt.Sprintf("ingester%d", i), tokens, ACTIVE, false)
+		desc.AddIngester(fmt.Sprintf("%d", i), fmt.Sprintf("ingester%d", i), "", tokens, ACTIVE, false)
 	}
 	codec := ProtoCodec{Factory: ProtoDescFactory}
 	consul := NewInMemoryKVClient(codec)
@@ -33,7 +39,7 @@ func BenchmarkRing(b *testing.B) {
 			Mock: consul,
 		},
 		ReplicationFactor: 3,
-	}, "ingester")
+	}, "ingester", ConsulKey)
 	if err != nil {
 		b.Fatal(err)
 	}
@@ -45,3 +51,209 @@ func BenchmarkRing(b *testing.B) {
 		r.BatchGet(keys, Write)
 	}
 }
+
+func TestSubring(t *testing.T) {
+	desc := NewDesc()
+	takenTokens := []uint32{}
+	for i := 0; i < 10; i++ {
+		tokens := GenerateTokens(numTokens, takenTokens)
+		takenTokens = append(takenTokens, tokens...)
+		desc.AddIngester(fmt.Sprintf("%d", i), fmt.Sprintf("ingester%d", i), "", tokens, ACTIVE, false)
+	}
+	desc.Tokens = migrateRing(desc)
+	r := &Ring{
+		cfg:      Config{HeartbeatTimeout: time.Hour, ReplicationFactor: 3},
+		ringDesc: desc,
+	}
+
+	if r.Subring(1, 0) != ReadRing(r) || r.Subring(1, 10) != ReadRing(r) {
+		t.Fatal("expected the whole ring")
+	}
+
+	addrs := func(rs ReplicationSet) map[string]struct{} {
+		result := map[string]struct{}{}
+		for _, ing := range rs.Ingesters {
+			result[ing.Addr] = struct{}{}
+		}
+		return result
+	}
+	for _, key := range GenerateTokens(10, nil) {
+		all, err := r.Subring(key, 4).GetAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		shard := addrs(all)
+		if len(shard) != 4 {
+			t.Fatalf("expected 4 ingesters, got %v", shard)
+		}
+
+		again, err := r.Subring(key, 4).GetAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(shard, addrs(again)) {
+			t.Fatalf("expected the same ingesters, got %v and %v", shard, addrs(again))
+		}
+
+		rs, err := r.Subring(key, 4).Get(key+1, Write)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rs.Ingesters) != 3 {
+			t.Fatalf("expected 3 replicas, got %v", rs.Ingesters)
+		}
+		for addr := range addrs(rs) {
+			if _, ok := shard[addr]; !ok {
+				t.Fatalf("replica %s not in the shard %v", addr, shard)
+			}
+		}
+	}
+}
+
+func TestSubringKeptUntilRingChanges(t *testing.T) {
+	desc := NewDesc()
+	takenTokens := []uint32{}
+	for i := 0; i < 10; i++ {
+		tokens := GenerateTokens(numTokens, takenTokens)
+		takenTokens = append(takenTokens, tokens...)
+		desc.AddIngester(fmt.Sprintf("%d", i), fmt.Sprintf("ingester%d", i), "", tokens, ACTIVE, false)
+	}
+	codec := ProtoCodec{Factory: ProtoDescFactory}
+	consul := NewInMemoryKVClient(codec)
+	put := func() {
+		ringBytes, err := codec.Encode(desc)
+		require.NoError(t, err)
+		require.NoError(t, consul.PutBytes(context.Background(), ConsulKey, ringBytes))
+	}
+	put()
+
+	r, err := New(Config{
+		KVStore:           KVConfig{Mock: consul},
+		HeartbeatTimeout:  time.Hour,
+		ReplicationFactor: 3,
+	}, "ingester", ConsulKey)
+	require.NoError(t, err)
+
+	test.Poll(t, time.Second, true, func() interface{} {
+		return r.Subring(1, 4) != ReadRing(r)
+	})
+	subring := r.Subring(1, 4)
+	require.True(t, subring == r.Subring(1, 4), "expected the same subring")
+
+	// Once the ring changes, e.g. with an ingester's heartbeat, the subring
+	// is computed again with the ingesters' new state.
+	ing := desc.Ingesters["0"]
+	ing.Timestamp = time.Now().Add(time.Minute).Unix()
+	desc.Ingesters["0"] = ing
+	put()
+	test.Poll(t, time.Second, true, func() interface{} {
+		return r.Subring(1, 4) != subring
+	})
+}
+
+func TestZoneAwareReplication(t *testing.T) {
+	desc := NewDesc()
+	takenTokens := []uint32{}
+	for i := 0; i < 9; i++ {
+		tokens := GenerateTokens(numTokens, takenTokens)
+		takenTokens = append(takenTokens, tokens...)
+		desc.AddIngester(fmt.Sprintf("%d", i), fmt.Sprintf("ingester%d", i), fmt.Sprintf("zone%d", i%3), tokens, ACTIVE, false)
+	}
+	desc.Tokens = migrateRing(desc)
+	r := &Ring{
+		cfg:      Config{HeartbeatTimeout: time.Hour, ReplicationFactor: 3, ZoneAwareness: true},
+		ringDesc: desc,
+	}
+
+	for _, key := range GenerateTokens(100, nil) {
+		rs, err := r.Get(key, Write)
+		if err != nil {
+			t.Fatal(err)
+		}
+		zones := map[string]struct{}{}
+		for _, ing := range rs.Ingesters {
+			zones[ing.Zone] = struct{}{}
+		}
+		if len(rs.Ingesters) != 3 || len(zones) != 3 {
+			t.Fatalf("expected 3 replicas in distinct zones, got %v", rs.Ingesters)
+		}
+
+		// Subrings have ingesters in every zone too.
+		rs, err = r.Subring(key, 3).Get(key, Write)
+		if err != nil {
+			t.Fatal(err)
+		}
+		zones = map[string]struct{}{}
+		for _, ing := range rs.Ingesters {
+			zones[ing.Zone] = struct{}{}
+		}
+		if len(rs.Ingesters) != 3 || len(zones) != 3 {
+			t.Fatalf("expected 3 replicas of the subring in distinct zones, got %v", rs.Ingesters)
+		}
+	}
+
+	// Losing a whole zone leaves a quorum of replicas for every key.
+	for id, ing := range desc.Ingesters {
+		if ing.Zone == "zone0" {
+			ing.Timestamp = time.Now().Add(-2 * time.Hour).Unix()
+			desc.Ingesters[id] = ing
+		}
+	}
+	for _, key := range GenerateTokens(100, nil) {
+		rs, err := r.Get(key, Write)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rs.Ingesters) != 2 || rs.MaxErrors != 0 {
+			t.Fatalf("expected the 2 replicas outside the lost zone, got %v tolerating %d errors", rs.Ingesters, rs.MaxErrors)
+		}
+		for _, ing := range rs.Ingesters {
+			if ing.Zone == "zone0" {
+				t.Fatalf("replica in the lost zone: %v", ing)
+			}
+		}
+	}
+}
+
+func TestReadOnlyIngesters(t *testing.T) {
+	desc := NewDesc()
+	takenTokens := []uint32{}
+	for i := 0; i < 4; i++ {
+		tokens := GenerateTokens(numTokens, takenTokens)
+		takenTokens = append(takenTokens, tokens...)
+		desc.AddIngester(fmt.Sprintf("%d", i), fmt.Sprintf("ingester%d", i), "", tokens, ACTIVE, false)
+	}
+	readOnly := desc.Ingesters["0"]
+	readOnly.State = READONLY
+	desc.Ingesters["0"] = readOnly
+	desc.Tokens = migrateRing(desc)
+	r := &Ring{
+		cfg:      Config{HeartbeatTimeout: time.Hour, ReplicationFactor: 3},
+		ringDesc: desc,
+	}
+
+	for _, key := range GenerateTokens(100, nil) {
+		// Writes go to 3 other ingesters, reads of keys the read-only
+		// ingester holds also go to it.
+		rs, err := r.Get(key, Write)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rs.Ingesters) != 3 {
+			t.Fatalf("expected 3 ingesters to write to, got %v", rs.Ingesters)
+		}
+		for _, ing := range rs.Ingesters {
+			if ing.Addr == "ingester0" {
+				t.Fatalf("read-only ingester written to")
+			}
+		}
+
+		rs, err = r.Get(key, Read)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rs.Ingesters) != 3 {
+			t.Fatalf("expected 3 ingesters to read from, got %v", rs.Ingesters)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package ruler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/prometheus/client_golang/api"
+	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/prometheus/prometheus/rules"
+	"github.com/weaveworks/common/user"
+)
+
+// remoteQueryFunc returns a rules.QueryFunc evaluating rules' queries through
+// the Prometheus API at address, e.g. a query-frontend's, as the tenant of
+// the rules.
+func remoteQueryFunc(address string) (rules.QueryFunc, error) {
+	client, err := api.NewClient(api.Config{
+		Address: address,
+		RoundTripper: promhttp.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if err := user.InjectOrgIDIntoHTTPRequest(req.Context(), req); err != nil {
+				return nil, err
+			}
+			return api.DefaultRoundTripper.RoundTrip(req)
+		}),
+	})
+	if err != nil {
+		return nil, err
+	}
+	promAPI := v1.NewAPI(client)
+
+	return func(ctx context.Context, qs string, t time.Time) (promql.Vector, error) {
+		value, err := promAPI.Query(ctx, qs, t)
+		if err != nil {
+			return nil, err
+		}
+		switch v := value.(type) {
+		case model.Vector:
+			result := make(promql.Vector, 0, len(v))
+			for _, s := range v {
+				result = append(result, promql.Sample{
+					Point:  promql.Point{T: int64(s.Timestamp), V: float64(s.Value)},
+					Metric: metricToLabels(s.Metric),
+				})
+			}
+			return result, nil
+		case *model.Scalar:
+			return promql.Vector{promql.Sample{
+				Point:  promql.Point{T: int64(v.Timestamp), V: float64(v.Value)},
+				Metric: labels.Labels{},
+			}}, nil
+		default:
+			return nil, fmt.Errorf("rule result is not a vector or scalar: %s", value.Type())
+		}
+	}, nil
+}
+
+func metricToLabels(m model.Metric) labels.Labels {
+	ls := make(labels.Labels, 0, len(m))
+	for k, v := range m {
+		ls = append(ls, labels.Label{Name: string(k), Value: string(v)})
+	}
+	sort.Sort(ls)
+	return ls
+}
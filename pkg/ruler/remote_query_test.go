@@ -0,0 +1,60 @@
+package ruler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+)
+
+func TestRemoteQueryFunc(t *testing.T) {
+	var response string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, _, err := user.ExtractOrgIDFromHTTPRequest(r)
+		assert.NoError(t, err)
+		assert.Equal(t, "1", userID)
+		assert.Equal(t, "/api/prom/api/v1/query", r.URL.Path)
+		assert.Equal(t, "up", r.FormValue("query"))
+		ts, err := time.Parse(time.RFC3339Nano, r.FormValue("time"))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1000), ts.Unix())
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(response))
+	}))
+	defer ts.Close()
+
+	queryFunc, err := remoteQueryFunc(ts.URL + "/api/prom")
+	require.NoError(t, err)
+	ctx := user.InjectOrgID(context.Background(), "1")
+
+	response = `{"status":"success","data":{"resultType":"vector","result":[{"metric":{"job":"a","__name__":"up"},"value":[1000,"1"]}]}}`
+	vector, err := queryFunc(ctx, "up", time.Unix(1000, 0))
+	require.NoError(t, err)
+	require.Equal(t, promql.Vector{{
+		Point:  promql.Point{T: 1000000, V: 1},
+		Metric: labels.FromStrings("__name__", "up", "job", "a"),
+	}}, vector)
+
+	response = `{"status":"success","data":{"resultType":"scalar","result":[1000,"2"]}}`
+	vector, err = queryFunc(ctx, "up", time.Unix(1000, 0))
+	require.NoError(t, err)
+	require.Equal(t, promql.Vector{{
+		Point:  promql.Point{T: 1000000, V: 2},
+		Metric: labels.Labels{},
+	}}, vector)
+
+	response = `{"status":"success","data":{"resultType":"matrix","result":[]}}`
+	_, err = queryFunc(ctx, "up", time.Unix(1000, 0))
+	require.Error(t, err)
+
+	response = `{"status":"error","errorType":"bad_data","error":"parse error"}`
+	_, err = queryFunc(ctx, "up", time.Unix(1000, 0))
+	require.Error(t, err)
+}
@@ -61,6 +61,10 @@ type Config struct {
 	// This is used for template expansion in alerts; must be a valid URL
 	ExternalURL flagext.URLValue
 
+	// URL of the Prometheus API to evaluate rules' queries through, rather
+	// than in process.
+	QueryURL flagext.URLValue
+
 	// How frequently to evaluate rules by default.
 	EvaluationInterval time.Duration
 	NumWorkers         int
@@ -93,6 +97,7 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 
 	cfg.ExternalURL.URL, _ = url.Parse("") // Must be non-nil
 	f.Var(&cfg.ExternalURL, "ruler.external.url", "URL of alerts return path.")
+	f.Var(&cfg.QueryURL, "ruler.query-url", "URL of the Prometheus API to evaluate rules' queries through, e.g. a query-frontend's http://query-frontend/api/prom. Empty evaluates them in process, querying the ingesters and the chunk store directly.")
 	f.DurationVar(&cfg.EvaluationInterval, "ruler.evaluation-interval", 15*time.Second, "How frequently to evaluate rules")
 	f.IntVar(&cfg.NumWorkers, "ruler.num-workers", 1, "Number of rule evaluator worker routines in this process")
 	f.Var(&cfg.AlertmanagerURL, "ruler.alertmanager-url", "URL of the Alertmanager to send notifications to.")
@@ -112,8 +117,7 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 // Ruler evaluates rules.
 type Ruler struct {
 	cfg         Config
-	engine      *promql.Engine
-	queryable   storage.Queryable
+	queryFunc   rules.QueryFunc
 	pusher      Pusher
 	alertURL    *url.URL
 	notifierCfg *config.Config
@@ -140,10 +144,17 @@ func NewRuler(cfg Config, engine *promql.Engine, queryable storage.Queryable, d
 		return nil, err
 	}
 
+	queryFunc := rules.EngineQueryFunc(engine, queryable)
+	if cfg.QueryURL.URL != nil && cfg.QueryURL.String() != "" {
+		queryFunc, err = remoteQueryFunc(cfg.QueryURL.String())
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	ruler := &Ruler{
 		cfg:         cfg,
-		engine:      engine,
-		queryable:   queryable,
+		queryFunc:   queryFunc,
 		pusher:      d,
 		alertURL:    cfg.ExternalURL.URL,
 		notifierCfg: ncfg,
@@ -156,12 +167,12 @@ func NewRuler(cfg Config, engine *promql.Engine, queryable storage.Queryable, d
 	// If sharding is enabled, create/join a ring to distribute tokens to
 	// the ruler
 	if cfg.EnableSharding {
-		ruler.lifecycler, err = ring.NewLifecycler(cfg.LifecyclerConfig, ruler, "ruler")
+		ruler.lifecycler, err = ring.NewLifecycler(cfg.LifecyclerConfig, ruler, "ruler", ring.ConsulKey)
 		if err != nil {
 			return nil, err
 		}
 
-		ruler.ring, err = ring.New(cfg.LifecyclerConfig.RingConfig, "ruler")
+		ruler.ring, err = ring.New(cfg.LifecyclerConfig.RingConfig, "ruler", ring.ConsulKey)
 		if err != nil {
 			return nil, err
 		}
@@ -216,7 +227,7 @@ func (r *Ruler) newGroup(userID string, groupName string, rls []rules.Rule) (*gr
 	}
 	opts := &rules.ManagerOptions{
 		Appendable:  appendable,
-		QueryFunc:   rules.EngineQueryFunc(r.engine, r.queryable),
+		QueryFunc:   r.queryFunc,
 		Context:     context.Background(),
 		ExternalURL: r.alertURL,
 		NotifyFunc:  sendAlerts(notifier, r.alertURL.String()),
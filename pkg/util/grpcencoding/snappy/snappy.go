@@ -0,0 +1,77 @@
+// Package snappy implements and registers a snappy compressor for gRPC.
+// Snappy compresses the JSON query responses sent from queriers to the
+// frontend nearly as well as gzip, at a fraction of the CPU cost.
+package snappy
+
+import (
+	"io"
+	"io/ioutil"
+	"sync"
+
+	"github.com/golang/snappy"
+	"google.golang.org/grpc/encoding"
+)
+
+// Name is the name registered for the snappy compressor.
+const Name = "snappy"
+
+func init() {
+	encoding.RegisterCompressor(newCompressor())
+}
+
+type compressor struct {
+	writersPool sync.Pool
+	readersPool sync.Pool
+}
+
+func newCompressor() *compressor {
+	c := &compressor{}
+	c.writersPool.New = func() interface{} {
+		return &writer{Writer: snappy.NewBufferedWriter(ioutil.Discard), pool: &c.writersPool}
+	}
+	c.readersPool.New = func() interface{} {
+		return &reader{Reader: snappy.NewReader(nil), pool: &c.readersPool}
+	}
+	return c
+}
+
+func (c *compressor) Name() string {
+	return Name
+}
+
+func (c *compressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	wr := c.writersPool.Get().(*writer)
+	wr.Reset(w)
+	return wr, nil
+}
+
+func (c *compressor) Decompress(r io.Reader) (io.Reader, error) {
+	rd := c.readersPool.Get().(*reader)
+	rd.Reset(r)
+	return rd, nil
+}
+
+type writer struct {
+	*snappy.Writer
+	pool *sync.Pool
+}
+
+// Close flushes the writer and returns it to the pool.
+func (w *writer) Close() error {
+	defer w.pool.Put(w)
+	return w.Writer.Close()
+}
+
+type reader struct {
+	*snappy.Reader
+	pool *sync.Pool
+}
+
+// Read returns the reader to the pool once the message has been fully read.
+func (r *reader) Read(p []byte) (n int, err error) {
+	n, err = r.Reader.Read(p)
+	if err == io.EOF {
+		r.pool.Put(r)
+	}
+	return n, err
+}
@@ -0,0 +1,67 @@
+package snappy
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnappy(t *testing.T) {
+	c := newCompressor()
+
+	for _, tc := range []string{
+		"",
+		"hello world",
+		strings.Repeat(`{"metric":{"foo":"bar"},"values":[[1536673680,"137"]]}`, 10000),
+	} {
+		// Run twice so the second round uses pooled writers and readers.
+		for i := 0; i < 2; i++ {
+			var buf bytes.Buffer
+			w, err := c.Compress(&buf)
+			require.NoError(t, err)
+			_, err = io.WriteString(w, tc)
+			require.NoError(t, err)
+			require.NoError(t, w.Close())
+
+			r, err := c.Decompress(&buf)
+			require.NoError(t, err)
+			out, err := ioutil.ReadAll(r)
+			require.NoError(t, err)
+			require.Equal(t, tc, string(out))
+		}
+	}
+}
+
+func BenchmarkSnappyCompress(b *testing.B) {
+	data := []byte(strings.Repeat(`{"metric":{"foo":"bar"},"values":[[1536673680,"137"]]}`, 10000))
+	c := newCompressor()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		w, _ := c.Compress(ioutil.Discard)
+		w.Write(data)
+		w.Close()
+	}
+}
+
+func BenchmarkSnappyDecompress(b *testing.B) {
+	data := []byte(strings.Repeat(`{"metric":{"foo":"bar"},"values":[[1536673680,"137"]]}`, 10000))
+	c := newCompressor()
+	var buf bytes.Buffer
+	w, _ := c.Compress(&buf)
+	w.Write(data)
+	w.Close()
+	compressed := buf.Bytes()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r, _ := c.Decompress(bytes.NewReader(compressed))
+		io.Copy(ioutil.Discard, r)
+	}
+}
@@ -1,6 +1,8 @@
 package util
 
 import (
+	"strings"
+
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/pkg/labels"
 )
@@ -14,3 +16,32 @@ func LabelsToMetric(ls labels.Labels) model.Metric {
 	}
 	return m
 }
+
+// SanitizeLabelName replaces the characters which aren't valid in label
+// names with underscores, prefixing names starting with a digit with "key_".
+func SanitizeLabelName(name string) string {
+	name = sanitize(name, false)
+	if name != "" && name[0] >= '0' && name[0] <= '9' {
+		name = "key_" + name
+	}
+	return name
+}
+
+// SanitizeMetricName replaces the characters which aren't valid in metric
+// names with underscores, prefixing names starting with a digit with one.
+func SanitizeMetricName(name string) string {
+	name = sanitize(name, true)
+	if name != "" && name[0] >= '0' && name[0] <= '9' {
+		name = "_" + name
+	}
+	return name
+}
+
+func sanitize(name string, allowColons bool) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' || (allowColons && r == ':') {
+			return r
+		}
+		return '_'
+	}, name)
+}
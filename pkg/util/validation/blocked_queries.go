@@ -0,0 +1,49 @@
+package validation
+
+import (
+	"regexp"
+)
+
+// BlockedQuery is a pattern of PromQL queries the query frontend rejects for
+// a tenant; either the exact text of the query, or a regex matching any part
+// of it.
+type BlockedQuery struct {
+	Pattern string `yaml:"pattern"`
+	Regex   bool   `yaml:"regex"`
+	// Reason is included in the error returned for blocked queries.
+	Reason string `yaml:"reason"`
+
+	regex *regexp.Regexp
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface, compiling regex
+// patterns so invalid ones are rejected when the limits are loaded.
+func (b *BlockedQuery) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type plain BlockedQuery
+	if err := unmarshal((*plain)(b)); err != nil {
+		return err
+	}
+	if !b.Regex {
+		return nil
+	}
+
+	var err error
+	b.regex, err = regexp.Compile(b.Pattern)
+	return err
+}
+
+// Matches returns whether the query is blocked by this pattern.
+func (b BlockedQuery) Matches(query string) bool {
+	if !b.Regex {
+		return query == b.Pattern
+	}
+	re := b.regex
+	if re == nil {
+		// Not loaded from YAML, so not compiled yet.
+		var err error
+		if re, err = regexp.Compile(b.Pattern); err != nil {
+			return false
+		}
+	}
+	return re.MatchString(query)
+}
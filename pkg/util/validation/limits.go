@@ -2,37 +2,68 @@ package validation
 
 import (
 	"flag"
+	"fmt"
 	"time"
+
+	"github.com/prometheus/prometheus/pkg/relabel"
+
+	"github.com/cortexproject/cortex/pkg/util/flagext"
+)
+
+// The duplicate sample policies, what ingesters do with a sample with the
+// same timestamp as, but a different value from, its series' last sample.
+const (
+	DuplicateSampleReject    = "reject"
+	DuplicateSampleKeepFirst = "keep-first"
+	DuplicateSampleKeepLast  = "keep-last"
 )
 
 // Limits describe all the limits for users; can be used to describe global default
 // limits via flags, or per-user limits via yaml config.
 type Limits struct {
 	// Distributor enforced limits.
-	IngestionRate          float64       `yaml:"ingestion_rate"`
-	IngestionBurstSize     int           `yaml:"ingestion_burst_size"`
-	AcceptHASamples        bool          `yaml:"accept_ha_samples"`
-	HAClusterLabel         string        `yaml:"ha_cluster_label"`
-	HAReplicaLabel         string        `yaml:"ha_replica_label"`
-	MaxLabelNameLength     int           `yaml:"max_label_name_length"`
-	MaxLabelValueLength    int           `yaml:"max_label_value_length"`
-	MaxLabelNamesPerSeries int           `yaml:"max_label_names_per_series"`
-	RejectOldSamples       bool          `yaml:"reject_old_samples"`
-	RejectOldSamplesMaxAge time.Duration `yaml:"reject_old_samples_max_age"`
-	CreationGracePeriod    time.Duration `yaml:"creation_grace_period"`
-	EnforceMetricName      bool          `yaml:"enforce_metric_name"`
+	IngestionRate            float64       `yaml:"ingestion_rate"`
+	IngestionBurstSize       int           `yaml:"ingestion_burst_size"`
+	AcceptHASamples          bool          `yaml:"accept_ha_samples"`
+	HAClusterLabel           string        `yaml:"ha_cluster_label"`
+	HAReplicaLabel           string        `yaml:"ha_replica_label"`
+	MaxLabelNameLength       int           `yaml:"max_label_name_length"`
+	MaxLabelValueLength      int           `yaml:"max_label_value_length"`
+	MaxLabelNamesPerSeries   int           `yaml:"max_label_names_per_series"`
+	RejectOldSamples         bool          `yaml:"reject_old_samples"`
+	RejectOldSamplesMaxAge   time.Duration `yaml:"reject_old_samples_max_age"`
+	CreationGracePeriod      time.Duration `yaml:"creation_grace_period"`
+	EnforceMetricName        bool          `yaml:"enforce_metric_name"`
+	MaxMetadataLength        int           `yaml:"max_metadata_length"`
+	IngestionTenantShardSize int           `yaml:"ingestion_tenant_shard_size"`
+
+	MetricRelabelConfigs []*relabel.Config `yaml:"metric_relabel_configs"`
+	DropLabels           flagext.Strings   `yaml:"drop_labels"`
 
 	// Ingester enforced limits.
-	MaxSeriesPerQuery  int `yaml:"max_series_per_query"`
-	MaxSamplesPerQuery int `yaml:"max_samples_per_query"`
-	MaxSeriesPerUser   int `yaml:"max_series_per_user"`
-	MaxSeriesPerMetric int `yaml:"max_series_per_metric"`
+	IngesterIngestionRateFactor float64 `yaml:"ingester_ingestion_rate_factor"`
+	MaxSeriesPerQuery           int     `yaml:"max_series_per_query"`
+	MaxSamplesPerQuery          int     `yaml:"max_samples_per_query"`
+	MaxSeriesPerUser            int     `yaml:"max_series_per_user"`
+	MaxSeriesPerMetric          int     `yaml:"max_series_per_metric"`
+	MaxExemplars                int     `yaml:"max_exemplars"`
+	MaxMetadataPerUser          int     `yaml:"max_metadata_per_user"`
+	MaxMetadataPerMetric        int     `yaml:"max_metadata_per_metric"`
+	DuplicateSamplePolicy       string  `yaml:"duplicate_sample_policy"`
 
 	// Querier enforced limits.
 	MaxChunksPerQuery   int           `yaml:"max_chunks_per_query"`
 	MaxQueryLength      time.Duration `yaml:"max_query_length"`
 	MaxQueryParallelism int           `yaml:"max_query_parallelism"`
 	CardinalityLimit    int           `yaml:"cardinality_limit"`
+	MaxSeriesResults    int           `yaml:"max_series_results"`
+
+	// Query frontend enforced limits.
+	QueryFrontendWeight  float64        `yaml:"query_frontend_weight"`
+	MaxResponseSizeBytes int            `yaml:"max_response_size_bytes"`
+	MaxPointsPerSeries   int            `yaml:"max_points_per_series"`
+	ClampQueryStep       bool           `yaml:"clamp_query_step"`
+	BlockedQueries       []BlockedQuery `yaml:"blocked_queries"`
 
 	// Config for overrides, convenient if it goes here.
 	PerTenantOverrideConfig string        `yaml:"per_tenant_override_config"`
@@ -49,25 +80,49 @@ func (l *Limits) RegisterFlags(f *flag.FlagSet) {
 	f.IntVar(&l.MaxLabelNameLength, "validation.max-length-label-name", 1024, "Maximum length accepted for label names")
 	f.IntVar(&l.MaxLabelValueLength, "validation.max-length-label-value", 2048, "Maximum length accepted for label value. This setting also applies to the metric name")
 	f.IntVar(&l.MaxLabelNamesPerSeries, "validation.max-label-names-per-series", 30, "Maximum number of label names per series.")
-	f.BoolVar(&l.RejectOldSamples, "validation.reject-old-samples", false, "Reject old samples.")
-	f.DurationVar(&l.RejectOldSamplesMaxAge, "validation.reject-old-samples.max-age", 14*24*time.Hour, "Maximum accepted sample age before rejecting.")
-	f.DurationVar(&l.CreationGracePeriod, "validation.create-grace-period", 10*time.Minute, "Duration which table will be created/deleted before/after it's needed; we won't accept sample from before this time.")
+	f.BoolVar(&l.RejectOldSamples, "validation.reject-old-samples", false, "Reject samples older than -validation.reject-old-samples.max-age.")
+	f.DurationVar(&l.RejectOldSamplesMaxAge, "validation.reject-old-samples.max-age", 14*24*time.Hour, "Maximum accepted sample age before rejecting, with -validation.reject-old-samples.")
+	f.DurationVar(&l.CreationGracePeriod, "validation.create-grace-period", 10*time.Minute, "Maximum accepted time samples can be in the future. It should be no more than -dynamodb.periodic-table.grace-period, so that samples are never written to index tables which don't exist yet.")
 	f.BoolVar(&l.EnforceMetricName, "validation.enforce-metric-name", true, "Enforce every sample has a metric name.")
+	f.IntVar(&l.MaxMetadataLength, "validation.max-metadata-length", 1024, "Maximum length accepted for the metric family name, help and unit of metric metadata.")
+	f.IntVar(&l.IngestionTenantShardSize, "distributor.ingestion-tenant-shard-size", 0, "Number of ingesters each user's series are sharded over, chosen by hashing the user ID onto the ring, bounding the ingesters a user's cardinality explosion can overload and the ingesters its queries are sent to. 0 shards users over all the ingesters.")
+	f.Var(&l.DropLabels, "distributor.drop-label", "Label to remove from each user's series before they are sharded and stored, e.g. one churning without adding information, such as pod_template_hash. Can be repeated.")
 
+	f.Float64Var(&l.IngesterIngestionRateFactor, "ingester.ingestion-rate-limit-factor", 0, "Multiple of its share of the user's ingestion rate limit each ingester enforces as a backstop to the distributors': the limit times the replication factor, divided by the number of healthy ingesters (or -distributor.ingestion-tenant-shard-size). 0 to disable.")
 	f.IntVar(&l.MaxSeriesPerQuery, "ingester.max-series-per-query", 100000, "The maximum number of series that a query can return.")
 	f.IntVar(&l.MaxSamplesPerQuery, "ingester.max-samples-per-query", 1000000, "The maximum number of samples that a query can return.")
 	f.IntVar(&l.MaxSeriesPerUser, "ingester.max-series-per-user", 5000000, "Maximum number of active series per user.")
 	f.IntVar(&l.MaxSeriesPerMetric, "ingester.max-series-per-metric", 50000, "Maximum number of active series per metric name.")
+	f.IntVar(&l.MaxExemplars, "ingester.max-exemplars", 0, "Maximum number of exemplars each ingester keeps in memory per user, across all series; the oldest are dropped to make room for new ones. 0 disables exemplar storage.")
+	f.IntVar(&l.MaxMetadataPerUser, "ingester.max-metadata-per-user", 8000, "Maximum number of metadata entries (distinct help, type and unit per metric) each ingester keeps per user. 0 disables metadata storage.")
+	f.IntVar(&l.MaxMetadataPerMetric, "ingester.max-metadata-per-metric", 10, "Maximum number of distinct metadata entries each ingester keeps per metric.")
+	f.StringVar(&l.DuplicateSamplePolicy, "ingester.duplicate-sample-policy", DuplicateSampleReject, "What ingesters do with a sample with the same timestamp as, but a different value from, its series' last sample: reject it, keep-first to drop it, or keep-last to replace the last sample by it. The blocks storage rejects them with keep-last.")
 
 	f.IntVar(&l.MaxChunksPerQuery, "store.query-chunk-limit", 2e6, "Maximum number of chunks that can be fetched in a single query.")
-	f.DurationVar(&l.MaxQueryLength, "store.max-query-length", 0, "Limit to length of chunk store queries, 0 to disable.")
-	f.IntVar(&l.MaxQueryParallelism, "querier.max-query-parallelism", 14, "Maximum number of queries will be scheduled in parallel by the frontend.")
+	f.DurationVar(&l.MaxQueryLength, "store.max-query-length", 0, "Limit to length of chunk store queries, 0 to disable. Also enforced on query range requests by the query frontend.")
+	f.IntVar(&l.MaxQueryParallelism, "querier.max-query-parallelism", 14, "Maximum number of queries will be scheduled in parallel by the frontend. This applies to all the sub-queries a query is split and sharded into.")
+	f.Float64Var(&l.QueryFrontendWeight, "frontend.tenant-weight", 1, "Weight of the tenant's queue in the query frontend; tenants with queued queries are given querier capacity in proportion to their weight.")
+	f.IntVar(&l.MaxResponseSizeBytes, "frontend.max-response-size-bytes", 0, "Maximum size of the results the query frontend gathers for a query, including those from the results cache; queries exceeding it fail with a 422. 0 means no limit.")
+	f.IntVar(&l.MaxPointsPerSeries, "frontend.max-points-per-series", 11000, "Maximum number of points per series a query range request may ask for, (end-start)/step; requests over it fail with a 400, unless -frontend.clamp-query-step is set. 0 means no limit.")
+	f.BoolVar(&l.ClampQueryStep, "frontend.clamp-query-step", false, "Increase the step of query range requests exceeding -frontend.max-points-per-series to the smallest one within it, instead of rejecting them.")
 	f.IntVar(&l.CardinalityLimit, "store.cardinality-limit", 1e5, "Cardinality limit for index queries.")
+	f.IntVar(&l.MaxSeriesResults, "querier.max-series-results", 0, "Maximum number of series a series request (/api/v1/series) may return, after merging those from the ingesters and the chunk store. 0 means no limit.")
 
 	f.StringVar(&l.PerTenantOverrideConfig, "limits.per-user-override-config", "", "File name of per-user overrides.")
 	f.DurationVar(&l.PerTenantOverridePeriod, "limits.per-user-override-period", 10*time.Second, "Period with this to reload the overrides.")
 }
 
+// Validate returns an error if the limits are invalid.
+func (l *Limits) Validate() error {
+	// Limits which aren't set from flags, as in tests, reject duplicates.
+	switch l.DuplicateSamplePolicy {
+	case "", DuplicateSampleReject, DuplicateSampleKeepFirst, DuplicateSampleKeepLast:
+	default:
+		return fmt.Errorf("unknown duplicate sample policy %q, use %s, %s or %s", l.DuplicateSamplePolicy, DuplicateSampleReject, DuplicateSampleKeepFirst, DuplicateSampleKeepLast)
+	}
+	return nil
+}
+
 // UnmarshalYAML implements the yaml.Unmarshaler interface.
 func (l *Limits) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	// We want to set c to the defaults and then overwrite it with the input.
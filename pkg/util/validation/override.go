@@ -1,6 +1,7 @@
 package validation
 
 import (
+	"fmt"
 	"os"
 	"sync"
 	"time"
@@ -8,6 +9,7 @@ import (
 	"github.com/go-kit/kit/log/level"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/prometheus/pkg/relabel"
 	yaml "gopkg.in/yaml.v2"
 
 	"github.com/cortexproject/cortex/pkg/util"
@@ -35,6 +37,11 @@ type Overrides struct {
 	overridesMtx sync.RWMutex
 	overrides    map[string]*Limits
 	quit         chan struct{}
+
+	// When the overrides were last reloaded, and whether the last attempt
+	// was successful.
+	lastReload           time.Time
+	lastReloadSuccessful bool
 }
 
 // NewOverrides makes a new Overrides.
@@ -42,14 +49,19 @@ type Overrides struct {
 // are defaulted to those values.  As such, the last call to NewOverrides will
 // become the new global defaults.
 func NewOverrides(defaults Limits) (*Overrides, error) {
+	if err := defaults.Validate(); err != nil {
+		return nil, err
+	}
 	defaultLimits = defaults
 
 	if defaults.PerTenantOverrideConfig == "" {
 		level.Info(util.Logger).Log("msg", "per-tenant overides disabled")
 		return &Overrides{
-			Defaults:  defaults,
-			overrides: map[string]*Limits{},
-			quit:      make(chan struct{}),
+			Defaults:             defaults,
+			overrides:            map[string]*Limits{},
+			quit:                 make(chan struct{}),
+			lastReload:           time.Now(),
+			lastReloadSuccessful: true,
 		}, nil
 	}
 
@@ -59,9 +71,11 @@ func NewOverrides(defaults Limits) (*Overrides, error) {
 	}
 
 	o := &Overrides{
-		Defaults:  defaults,
-		overrides: overrides,
-		quit:      make(chan struct{}),
+		Defaults:             defaults,
+		overrides:            overrides,
+		quit:                 make(chan struct{}),
+		lastReload:           time.Now(),
+		lastReloadSuccessful: true,
 	}
 
 	go o.loop()
@@ -79,12 +93,17 @@ func (o *Overrides) loop() {
 			if err != nil {
 				overridesReloadSuccess.Set(0)
 				level.Error(util.Logger).Log("msg", "failed to reload overrides", "err", err)
+				o.overridesMtx.Lock()
+				o.lastReloadSuccessful = false
+				o.overridesMtx.Unlock()
 				continue
 			}
 			overridesReloadSuccess.Set(1)
 
 			o.overridesMtx.Lock()
 			o.overrides = overrides
+			o.lastReload = time.Now()
+			o.lastReloadSuccessful = true
 			o.overridesMtx.Unlock()
 		case <-o.quit:
 			return
@@ -92,6 +111,14 @@ func (o *Overrides) loop() {
 	}
 }
 
+// LastReload returns when the overrides were last loaded, and whether the
+// last attempt to reload them was successful.
+func (o *Overrides) LastReload() (time.Time, bool) {
+	o.overridesMtx.RLock()
+	defer o.overridesMtx.RUnlock()
+	return o.lastReload, o.lastReloadSuccessful
+}
+
 // Stop background reloading of overrides.
 func (o *Overrides) Stop() {
 	close(o.quit)
@@ -112,6 +139,11 @@ func loadOverrides(filename string) (map[string]*Limits, error) {
 	if err := decoder.Decode(&overrides); err != nil {
 		return nil, err
 	}
+	for userID, limits := range overrides.Overrides {
+		if err := limits.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid overrides for user %s: %v", userID, err)
+		}
+	}
 
 	return overrides.Overrides, nil
 }
@@ -201,6 +233,14 @@ func (o *Overrides) HAClusterLabel(userID string) string {
 	})
 }
 
+// IngestionTenantShardSize returns the number of ingesters the user's series
+// are sharded over.
+func (o *Overrides) IngestionTenantShardSize(userID string) int {
+	return o.getInt(userID, func(l *Limits) int {
+		return l.IngestionTenantShardSize
+	})
+}
+
 // MaxLabelNameLength returns maximum length a label name can be.
 func (o *Overrides) MaxLabelNameLength(userID string) int {
 	return o.getInt(userID, func(l *Limits) int {
@@ -267,6 +307,14 @@ func (o *Overrides) MaxSeriesPerUser(userID string) int {
 	})
 }
 
+// IngesterIngestionRateFactor returns the multiple of their share of the
+// ingestion rate limit the ingesters enforce per user.
+func (o *Overrides) IngesterIngestionRateFactor(userID string) float64 {
+	return o.getFloat(userID, func(l *Limits) float64 {
+		return l.IngesterIngestionRateFactor
+	})
+}
+
 // MaxSeriesPerMetric returns the maximum number of series allowed per metric.
 func (o *Overrides) MaxSeriesPerMetric(userID string) int {
 	return o.getInt(userID, func(l *Limits) int {
@@ -274,6 +322,45 @@ func (o *Overrides) MaxSeriesPerMetric(userID string) int {
 	})
 }
 
+// MaxExemplars returns the number of exemplars ingesters keep per user.
+func (o *Overrides) MaxExemplars(userID string) int {
+	return o.getInt(userID, func(l *Limits) int {
+		return l.MaxExemplars
+	})
+}
+
+// MaxMetadataPerUser returns the number of metadata entries ingesters keep
+// per user.
+func (o *Overrides) MaxMetadataPerUser(userID string) int {
+	return o.getInt(userID, func(l *Limits) int {
+		return l.MaxMetadataPerUser
+	})
+}
+
+// MaxMetadataPerMetric returns the number of metadata entries ingesters keep
+// per metric.
+func (o *Overrides) MaxMetadataPerMetric(userID string) int {
+	return o.getInt(userID, func(l *Limits) int {
+		return l.MaxMetadataPerMetric
+	})
+}
+
+// DuplicateSamplePolicy returns what ingesters do with samples with the same
+// timestamp as, but a different value from, their series' last sample.
+func (o *Overrides) DuplicateSamplePolicy(userID string) string {
+	return o.getString(userID, func(l *Limits) string {
+		return l.DuplicateSamplePolicy
+	})
+}
+
+// MaxMetadataLength returns the maximum length of the metric family name,
+// help and unit of metric metadata.
+func (o *Overrides) MaxMetadataLength(userID string) int {
+	return o.getInt(userID, func(l *Limits) int {
+		return l.MaxMetadataLength
+	})
+}
+
 // MaxChunksPerQuery returns the maximum number of chunks allowed per query.
 func (o *Overrides) MaxChunksPerQuery(userID string) int {
 	return o.getInt(userID, func(l *Limits) int {
@@ -296,6 +383,80 @@ func (o *Overrides) MaxQueryParallelism(userID string) int {
 	})
 }
 
+// QueryFrontendWeight returns the weight of the tenant's queue in the frontend.
+func (o *Overrides) QueryFrontendWeight(userID string) float64 {
+	return o.getFloat(userID, func(l *Limits) float64 {
+		return l.QueryFrontendWeight
+	})
+}
+
+// MaxSeriesResults returns the limit on the number of series a series
+// request may return.
+func (o *Overrides) MaxSeriesResults(userID string) int {
+	return o.getInt(userID, func(l *Limits) int {
+		return l.MaxSeriesResults
+	})
+}
+
+// MaxResponseSizeBytes returns the limit on the size of the results the
+// frontend gathers for a query.
+func (o *Overrides) MaxResponseSizeBytes(userID string) int {
+	return o.getInt(userID, func(l *Limits) int {
+		return l.MaxResponseSizeBytes
+	})
+}
+
+// MaxPointsPerSeries returns the limit on the number of points per series of
+// query range requests.
+func (o *Overrides) MaxPointsPerSeries(userID string) int {
+	return o.getInt(userID, func(l *Limits) int {
+		return l.MaxPointsPerSeries
+	})
+}
+
+// ClampQueryStep returns whether the frontend increases the step of query
+// range requests over the points limit rather than rejecting them.
+func (o *Overrides) ClampQueryStep(userID string) bool {
+	return o.getBool(userID, func(l *Limits) bool {
+		return l.ClampQueryStep
+	})
+}
+
+// MetricRelabelConfigs returns the relabel configs the distributor applies to
+// the user's series.
+func (o *Overrides) MetricRelabelConfigs(userID string) []*relabel.Config {
+	o.overridesMtx.RLock()
+	defer o.overridesMtx.RUnlock()
+	override, ok := o.overrides[userID]
+	if !ok {
+		return o.Defaults.MetricRelabelConfigs
+	}
+	return override.MetricRelabelConfigs
+}
+
+// DropLabels returns the names of the labels the distributor removes from the
+// user's series.
+func (o *Overrides) DropLabels(userID string) []string {
+	o.overridesMtx.RLock()
+	defer o.overridesMtx.RUnlock()
+	override, ok := o.overrides[userID]
+	if !ok {
+		return o.Defaults.DropLabels
+	}
+	return override.DropLabels
+}
+
+// BlockedQueries returns the patterns of queries the frontend rejects.
+func (o *Overrides) BlockedQueries(userID string) []BlockedQuery {
+	o.overridesMtx.RLock()
+	defer o.overridesMtx.RUnlock()
+	override, ok := o.overrides[userID]
+	if !ok {
+		return o.Defaults.BlockedQueries
+	}
+	return override.BlockedQueries
+}
+
 // EnforceMetricName whether to enforce the presence of a metric name.
 func (o *Overrides) EnforceMetricName(userID string) bool {
 	return o.getBool(userID, func(l *Limits) bool {
@@ -2,6 +2,8 @@ package validation
 
 import (
 	"net/http"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/cortexproject/cortex/pkg/ingester/client"
 	"github.com/cortexproject/cortex/pkg/util/extract"
@@ -13,28 +15,79 @@ import (
 const (
 	discardReasonLabel = "reason"
 
-	errMissingMetricName = "sample missing metric name"
-	errInvalidMetricName = "sample invalid metric name: %.200q"
-	errInvalidLabel      = "sample invalid label: %.200q metric %.200q"
-	errLabelNameTooLong  = "label name too long: %.200q metric %.200q"
-	errLabelValueTooLong = "label value too long: %.200q metric %.200q"
-	errTooManyLabels     = "sample for '%s' has %d label names; limit %d"
-	errTooOld            = "sample for '%s' has timestamp too old: %d"
-	errTooNew            = "sample for '%s' has timestamp too new: %d"
+	errMissingMetricName         = "sample missing metric name: %.200q"
+	errInvalidMetricName         = "sample invalid metric name: %.200q"
+	errInvalidLabel              = "sample invalid label: %.200q metric %.200q"
+	errLabelNameTooLong          = "label name too long (limit %d): %.200q metric %.200q"
+	errLabelValueTooLong         = "label value too long (limit %d): %.200q metric %.200q"
+	errTooManyLabels             = "sample for '%s' has %d label names; limit %d"
+	errTooOld                    = "sample for '%s' has timestamp too old: %d, older than the reject_old_samples_max_age of %s"
+	errTooNew                    = "sample for '%s' has timestamp too new: %d, further in the future than the creation_grace_period of %s"
+	errExemplarNoLabels          = "exemplar for '%s' has no labels"
+	errExemplarTooLong           = "exemplar for '%s' has labels longer than %d characters: %.200q"
+	errExemplarTooOld            = "exemplar for '%s' has timestamp too old: %d, older than the reject_old_samples_max_age of %s"
+	errExemplarTooNew            = "exemplar for '%s' has timestamp too new: %d, further in the future than the creation_grace_period of %s"
+	errMetadataMissingMetricName = "metadata missing metric name"
+	errMetadataTooLong           = "metadata '%s' too long: %.200q metric %.200q"
+	errInvalidTenantID           = "tenant ID %.200q contains %q, which separates the tenants of federated queries"
+
+	// TenantSeparator separates the tenants listed in the org IDs of
+	// federated queries, so it can't be part of a tenant's ID.
+	TenantSeparator = "|"
+
+	// ExemplarMaxLabelSetLength is the maximum combined length of the names
+	// and values of an exemplar's labels, as in Prometheus.
+	ExemplarMaxLabelSetLength = 128
 
 	// ErrQueryTooLong is used in chunk store and query frontend.
 	ErrQueryTooLong = "invalid query, length > limit (%s > %s)"
 
+	// ErrQueryResponseTooLarge is used in the query frontend.
+	ErrQueryResponseTooLarge = "the query response is larger than the limit (%d bytes); reduce the time range or number of series queried"
+
+	// ErrTooManyPoints is used in the query frontend.
+	ErrTooManyPoints = "exceeded maximum resolution of %d points per timeseries. Try decreasing the query resolution (?step=XX)"
+
+	// ErrTooManySeriesPerQuery is used in the querier.
+	ErrTooManySeriesPerQuery = "the query exceeded the maximum number of series per query (limit: %d); narrow the selectors or reduce the time range"
+
+	// ErrTooManySamplesPerQuery is used in the querier.
+	ErrTooManySamplesPerQuery = "the query exceeded the maximum number of samples per query (limit: %d); reduce the time range or the number of series queried"
+
+	// ErrTooManySeriesResults is used in the querier.
+	ErrTooManySeriesResults = "the series request matched more than the limit of %d series; narrow the matchers or time range"
+
+	// ErrQueryBlocked is used in the query frontend.
+	ErrQueryBlocked = "the query matches the blocked query pattern %q: %s"
+
 	greaterThanMaxSampleAge = "greater_than_max_sample_age"
 	maxLabelNamesPerSeries  = "max_label_names_per_series"
 	tooFarInFuture          = "too_far_in_future"
 	invalidLabel            = "label_invalid"
 	labelNameTooLong        = "label_name_too_long"
 	labelValueTooLong       = "label_value_too_long"
+	missingMetricName       = "missing_metric_name"
+	invalidMetricName       = "metric_name_invalid"
+	metadataTooLong         = "metadata_too_long"
+	nativeHistogram         = "native_histogram"
 
 	// RateLimited is one of the values for the reason to discard samples.
 	// Declared here to avoid duplication in ingester and distributor.
 	RateLimited = "rate_limited"
+
+	// RelabelDropped is the reason to discard the samples of series dropped
+	// by the tenant's metric_relabel_configs.
+	RelabelDropped = "relabel_dropped"
+)
+
+// DiscardedMetadata is a metric of the number of discarded metric metadata
+// entries, by reason.
+var DiscardedMetadata = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "cortex_discarded_metadata_total",
+		Help: "The total number of metadata entries that were discarded.",
+	},
+	[]string{discardReasonLabel, "user"},
 )
 
 // DiscardedSamples is a metric of the number of discarded samples, by reason.
@@ -48,20 +101,83 @@ var DiscardedSamples = prometheus.NewCounterVec(
 
 func init() {
 	prometheus.MustRegister(DiscardedSamples)
+	prometheus.MustRegister(DiscardedMetadata)
 }
 
 // ValidateSample returns an err if the sample is invalid.
 func (cfg *Overrides) ValidateSample(userID string, metricName string, s client.Sample) error {
 	if cfg.RejectOldSamples(userID) && model.Time(s.TimestampMs) < model.Now().Add(-cfg.RejectOldSamplesMaxAge(userID)) {
 		DiscardedSamples.WithLabelValues(greaterThanMaxSampleAge, userID).Inc()
-		return httpgrpc.Errorf(http.StatusBadRequest, errTooOld, metricName, model.Time(s.TimestampMs))
+		return httpgrpc.Errorf(http.StatusBadRequest, errTooOld, metricName, model.Time(s.TimestampMs), cfg.RejectOldSamplesMaxAge(userID))
 	}
 
 	if model.Time(s.TimestampMs) > model.Now().Add(cfg.CreationGracePeriod(userID)) {
 		DiscardedSamples.WithLabelValues(tooFarInFuture, userID).Inc()
-		return httpgrpc.Errorf(http.StatusBadRequest, errTooNew, metricName, model.Time(s.TimestampMs))
+		return httpgrpc.Errorf(http.StatusBadRequest, errTooNew, metricName, model.Time(s.TimestampMs), cfg.CreationGracePeriod(userID))
+	}
+
+	return nil
+}
+
+// DiscardHistograms counts the native histogram samples of a series as
+// discarded, as they can't be stored until the chunk encodings and the query
+// engine can hold them.  The rest of the series is still ingested.
+func (cfg *Overrides) DiscardHistograms(userID string, hs []client.Histogram) {
+	if len(hs) > 0 {
+		DiscardedSamples.WithLabelValues(nativeHistogram, userID).Add(float64(len(hs)))
+	}
+}
+
+// ValidateExemplar returns an err if the exemplar is invalid.
+func (cfg *Overrides) ValidateExemplar(userID string, metricName string, e client.Exemplar) error {
+	if len(e.Labels) == 0 {
+		return httpgrpc.Errorf(http.StatusBadRequest, errExemplarNoLabels, metricName)
+	}
+
+	length := 0
+	for _, l := range e.Labels {
+		length += utf8.RuneCountInString(l.Name) + utf8.RuneCountInString(l.Value)
+	}
+	if length > ExemplarMaxLabelSetLength {
+		return httpgrpc.Errorf(http.StatusBadRequest, errExemplarTooLong, metricName, ExemplarMaxLabelSetLength, client.FromLabelAdaptersToMetric(e.Labels).String())
+	}
+
+	if cfg.RejectOldSamples(userID) && model.Time(e.TimestampMs) < model.Now().Add(-cfg.RejectOldSamplesMaxAge(userID)) {
+		return httpgrpc.Errorf(http.StatusBadRequest, errExemplarTooOld, metricName, model.Time(e.TimestampMs), cfg.RejectOldSamplesMaxAge(userID))
+	}
+	if model.Time(e.TimestampMs) > model.Now().Add(cfg.CreationGracePeriod(userID)) {
+		return httpgrpc.Errorf(http.StatusBadRequest, errExemplarTooNew, metricName, model.Time(e.TimestampMs), cfg.CreationGracePeriod(userID))
+	}
+	return nil
+}
+
+// ValidateMetadata returns an err if the metric metadata is invalid.
+func (cfg *Overrides) ValidateMetadata(userID string, m *client.MetricMetadata) error {
+	if m.MetricFamilyName == "" {
+		DiscardedMetadata.WithLabelValues(missingMetricName, userID).Inc()
+		return httpgrpc.Errorf(http.StatusBadRequest, errMetadataMissingMetricName)
 	}
 
+	maxLength := cfg.MaxMetadataLength(userID)
+	for _, f := range []struct{ name, value string }{
+		{"metric family name", m.MetricFamilyName},
+		{"help", m.Help},
+		{"unit", m.Unit},
+	} {
+		if len(f.value) > maxLength {
+			DiscardedMetadata.WithLabelValues(metadataTooLong, userID).Inc()
+			return httpgrpc.Errorf(http.StatusBadRequest, errMetadataTooLong, f.name, f.value, m.MetricFamilyName)
+		}
+	}
+	return nil
+}
+
+// ValidateTenantID returns an error if samples can't be written for the
+// tenant ID, as queriers would take it for several tenants.
+func ValidateTenantID(userID string) error {
+	if strings.Contains(userID, TenantSeparator) {
+		return httpgrpc.Errorf(http.StatusBadRequest, errInvalidTenantID, userID, TenantSeparator)
+	}
 	return nil
 }
 
@@ -70,10 +186,12 @@ func (cfg *Overrides) ValidateLabels(userID string, ls []client.LabelAdapter) er
 	metricName, err := extract.MetricNameFromLabelAdapters(ls)
 	if cfg.EnforceMetricName(userID) {
 		if err != nil {
-			return httpgrpc.Errorf(http.StatusBadRequest, errMissingMetricName)
+			DiscardedSamples.WithLabelValues(missingMetricName, userID).Inc()
+			return httpgrpc.Errorf(http.StatusBadRequest, errMissingMetricName, client.FromLabelAdaptersToMetric(ls).String())
 		}
 
 		if !model.IsValidMetricName(model.LabelValue(metricName)) {
+			DiscardedSamples.WithLabelValues(invalidMetricName, userID).Inc()
 			return httpgrpc.Errorf(http.StatusBadRequest, errInvalidMetricName, metricName)
 		}
 	}
@@ -89,23 +207,23 @@ func (cfg *Overrides) ValidateLabels(userID string, ls []client.LabelAdapter) er
 	for _, l := range ls {
 		var errTemplate string
 		var reason string
-		var cause interface{}
+		var args []interface{}
 		if !model.LabelName(l.Name).IsValid() {
 			reason = invalidLabel
 			errTemplate = errInvalidLabel
-			cause = l.Name
+			args = []interface{}{l.Name}
 		} else if len(l.Name) > maxLabelNameLength {
 			reason = labelNameTooLong
 			errTemplate = errLabelNameTooLong
-			cause = l.Name
+			args = []interface{}{maxLabelNameLength, l.Name}
 		} else if len(l.Value) > maxLabelValueLength {
 			reason = labelValueTooLong
 			errTemplate = errLabelValueTooLong
-			cause = l.Value
+			args = []interface{}{maxLabelValueLength, l.Value}
 		}
 		if errTemplate != "" {
 			DiscardedSamples.WithLabelValues(reason, userID).Inc()
-			return httpgrpc.Errorf(http.StatusBadRequest, errTemplate, cause, client.FromLabelAdaptersToMetric(ls).String())
+			return httpgrpc.Errorf(http.StatusBadRequest, errTemplate, append(args, client.FromLabelAdaptersToMetric(ls).String())...)
 		}
 	}
 	return nil
@@ -1,7 +1,9 @@
 package validation
 
 import (
+	"io/ioutil"
 	"net/http"
+	"os"
 	"testing"
 
 	"github.com/prometheus/common/model"
@@ -29,7 +31,7 @@ func TestValidateLabels(t *testing.T) {
 	}{
 		{
 			map[model.LabelName]model.LabelValue{},
-			httpgrpc.Errorf(http.StatusBadRequest, errMissingMetricName),
+			httpgrpc.Errorf(http.StatusBadRequest, errMissingMetricName, "{}"),
 		},
 		{
 			map[model.LabelName]model.LabelValue{model.MetricNameLabel: " "},
@@ -39,17 +41,21 @@ func TestValidateLabels(t *testing.T) {
 			map[model.LabelName]model.LabelValue{model.MetricNameLabel: "valid", "foo ": "bar"},
 			httpgrpc.Errorf(http.StatusBadRequest, errInvalidLabel, "foo ", `valid{foo ="bar"}`),
 		},
+		{
+			map[model.LabelName]model.LabelValue{"foo": "bar"},
+			httpgrpc.Errorf(http.StatusBadRequest, errMissingMetricName, `{foo="bar"}`),
+		},
 		{
 			map[model.LabelName]model.LabelValue{model.MetricNameLabel: "valid"},
 			nil,
 		},
 		{
 			map[model.LabelName]model.LabelValue{model.MetricNameLabel: "badLabelName", "this_is_a_really_really_long_name_that_should_cause_an_error": "test_value_please_ignore"},
-			httpgrpc.Errorf(http.StatusBadRequest, errLabelNameTooLong, "this_is_a_really_really_long_name_that_should_cause_an_error", `badLabelName{this_is_a_really_really_long_name_that_should_cause_an_error="test_value_please_ignore"}`),
+			httpgrpc.Errorf(http.StatusBadRequest, errLabelNameTooLong, 25, "this_is_a_really_really_long_name_that_should_cause_an_error", `badLabelName{this_is_a_really_really_long_name_that_should_cause_an_error="test_value_please_ignore"}`),
 		},
 		{
 			map[model.LabelName]model.LabelValue{model.MetricNameLabel: "badLabelValue", "much_shorter_name": "test_value_please_ignore_no_really_nothing_to_see_here"},
-			httpgrpc.Errorf(http.StatusBadRequest, errLabelValueTooLong, "test_value_please_ignore_no_really_nothing_to_see_here", `badLabelValue{much_shorter_name="test_value_please_ignore_no_really_nothing_to_see_here"}`),
+			httpgrpc.Errorf(http.StatusBadRequest, errLabelValueTooLong, 25, "test_value_please_ignore_no_really_nothing_to_see_here", `badLabelValue{much_shorter_name="test_value_please_ignore_no_really_nothing_to_see_here"}`),
 		},
 		{
 			map[model.LabelName]model.LabelValue{model.MetricNameLabel: "foo", "bar": "baz", "blip": "blop"},
@@ -61,3 +67,24 @@ func TestValidateLabels(t *testing.T) {
 		assert.Equal(t, c.err, err, "wrong error")
 	}
 }
+
+func TestDuplicateSamplePolicyValidation(t *testing.T) {
+	var cfg Limits
+	flagext.DefaultValues(&cfg)
+	cfg.DuplicateSamplePolicy = "keep-newest"
+	_, err := NewOverrides(cfg)
+	require.EqualError(t, err, `unknown duplicate sample policy "keep-newest", use reject, keep-first or keep-last`)
+
+	// Overrides are validated too.
+	f, err := ioutil.TempFile("", "overrides")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString("overrides:\n  user1:\n    duplicate_sample_policy: keep-newest\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	flagext.DefaultValues(&cfg)
+	cfg.PerTenantOverrideConfig = f.Name()
+	_, err = NewOverrides(cfg)
+	require.EqualError(t, err, `invalid overrides for user user1: unknown duplicate sample policy "keep-newest", use reject, keep-first or keep-last`)
+}